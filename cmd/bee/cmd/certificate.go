@@ -0,0 +1,110 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/membership"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameCAKeyFile = "ca-key-file"
+	optionNameOverlay   = "overlay"
+	optionNameExpiry    = "expiry"
+)
+
+func (c *command) initCertificateCmd() {
+	cmd := &cobra.Command{
+		Use:   "certificate",
+		Short: "Manage the private network membership certificates",
+	}
+
+	certificateIssueCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func certificateIssueCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "issue",
+		Short: "Issues a private network membership certificate",
+		Long: `Issues a private network membership certificate for the overlay address.
+
+The certificate is signed with the key of the certificate authority (CA) read
+from the file as a hex encoded secp256k1 private key. The ethereum address of
+the CA, printed to the standard error, is configured on the members with the
+--network-ca option and the certificate with the --network-certificate option.
+
+The memberships are revoked by uploading the list of the revoked overlay
+addresses, one per line, and updating the sequence feed of the CA with the
+topic "swarm-membership-revocations" to refer to it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyFile, err := cmd.Flags().GetString(optionNameCAKeyFile)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+				return fmt.Errorf("read ca key: %w", err)
+			}
+			keyBytes, err := hex.DecodeString(strings.TrimSpace(string(data)))
+			if err != nil {
+				return fmt.Errorf("decode ca key: %w", err)
+			}
+			key, err := crypto.DecodeSecp256k1PrivateKey(keyBytes)
+			if err != nil {
+				return fmt.Errorf("decode ca key: %w", err)
+			}
+
+			o, err := cmd.Flags().GetString(optionNameOverlay)
+			if err != nil {
+				return err
+			}
+			overlay, err := swarm.ParseHexAddress(o)
+			if err != nil || len(overlay.Bytes()) != swarm.HashSize {
+				return fmt.Errorf("invalid overlay address %q", o)
+			}
+
+			expiry, err := cmd.Flags().GetDuration(optionNameExpiry)
+			if err != nil {
+				return err
+			}
+
+			signer := crypto.NewDefaultSigner(key)
+			ca, err := signer.EthereumAddress()
+			if err != nil {
+				return err
+			}
+			cert, err := membership.Issue(signer, overlay, time.Now().Add(expiry))
+			if err != nil {
+				return err
+			}
+			b, err := cert.MarshalBinary()
+			if err != nil {
+				return err
+			}
+
+			cmd.PrintErrf("certificate authority %s, expiry %s\n", ca, cert.Expiry.UTC().Format(time.RFC3339))
+			cmd.Println(hex.EncodeToString(b))
+			return nil
+		},
+	}
+
+	c.Flags().String(optionNameCAKeyFile, "", "file with the hex encoded private key of the certificate authority")
+	c.Flags().String(optionNameOverlay, "", "overlay address of the member")
+	c.Flags().Duration(optionNameExpiry, 365*24*time.Hour, "validity period of the certificate")
+	_ = c.MarkFlagRequired(optionNameCAKeyFile)
+	_ = c.MarkFlagRequired(optionNameOverlay)
+
+	cmd.AddCommand(c)
+}
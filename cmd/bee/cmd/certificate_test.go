@@ -0,0 +1,57 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/cmd/bee/cmd"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/membership"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestCertificateIssue(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := crypto.EncodeSecp256k1PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "ca.key")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(keyBytes)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ca, err := crypto.NewDefaultSigner(key).EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlay := swarm.RandAddress(t)
+
+	var buf bytes.Buffer
+	err = newCommand(t, cmd.WithArgs("certificate", "issue", "--ca-key-file", keyFile, "--overlay", overlay.String()), cmd.WithOutput(&buf)).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, err := hex.DecodeString(strings.TrimSpace(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := membership.New(ca, log.Noop).Verify(overlay, certificate); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,7 +17,12 @@ import (
 	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/node"
+	"github.com/ethersphere/bee/v2/pkg/pricer"
+	resolvercache "github.com/ethersphere/bee/v2/pkg/resolver/cache"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -48,23 +54,53 @@ const (
 	optionNamePaymentTolerance             = "payment-tolerance-percent"
 	optionNamePaymentEarly                 = "payment-early-percent"
 	optionNameResolverEndpoints            = "resolver-options"
+	optionNameResolverCacheTTL             = "resolver-cache-ttl"
+	optionNameResolverCacheNegativeTTL     = "resolver-cache-negative-ttl"
+	optionNameResolverCacheCapacity        = "resolver-cache-capacity"
+	optionNameResolverFeeds                = "resolver-feeds"
+	optionNameResolverDNSLinkEnable        = "resolver-dnslink-enable"
 	optionNameBootnodeMode                 = "bootnode-mode"
 	optionNameClefSignerEnable             = "clef-signer-enable"
 	optionNameClefSignerEndpoint           = "clef-signer-endpoint"
 	optionNameClefSignerEthereumAddress    = "clef-signer-ethereum-address"
+	optionNameExternalSignerEnable         = "external-signer-enable"
+	optionNameExternalSignerEndpoint       = "external-signer-endpoint"
 	optionNameSwapEndpoint                 = "swap-endpoint" // deprecated: use rpc endpoint instead
 	optionNameBlockchainRpcEndpoint        = "blockchain-rpc-endpoint"
+	optionNameBlockchainRpcQuorum          = "blockchain-rpc-quorum"
+	optionNameTransactionCheckInterval     = "transaction-check-interval"
+	optionNameTransactionBump              = "transaction-bump"
+	optionNameTransactionBumpDeadline      = "transaction-bump-deadline"
+	optionNameTransactionFillNonceGaps     = "transaction-fill-nonce-gaps"
+	optionNameTransactionMaxGasFeeCap      = "transaction-max-gas-fee-cap"
 	optionNameSwapFactoryAddress           = "swap-factory-address"
 	optionNameSwapInitialDeposit           = "swap-initial-deposit"
 	optionNameSwapEnable                   = "swap-enable"
 	optionNameChequebookEnable             = "chequebook-enable"
 	optionNameSwapDeploymentGasPrice       = "swap-deployment-gas-price"
+	optionNameAccountingLedgerEnable       = "accounting-ledger-enable"
+	optionNameAccountingLedgerRetention    = "accounting-ledger-retention"
+	optionNameCashoutCheckInterval         = "cashout-check-interval"
+	optionNameCashoutThreshold             = "cashout-threshold"
+	optionNameCashoutMaxGasCostPercent     = "cashout-max-gas-cost-percent"
+	optionNameCashoutTokenPrice            = "cashout-token-price"
+	optionNameCashoutBatchWindow           = "cashout-batch-window"
+	optionNameCashoutMinNativeBalance      = "cashout-min-native-balance"
+	optionNameCashoutPendingTimeout        = "cashout-pending-timeout"
+	optionNameDynamicPricingEnable         = "dynamic-pricing-enable"
+	optionNameDynamicPricingMinPrice       = "dynamic-pricing-min-price"
+	optionNameDynamicPricingMaxPrice       = "dynamic-pricing-max-price"
+	optionNameDynamicPricingMaxChange      = "dynamic-pricing-max-change"
+	optionNameDynamicPricingInterval       = "dynamic-pricing-interval"
+	optionNameDynamicPricingQueueCapacity  = "dynamic-pricing-queue-capacity"
+	optionNameDynamicPricingBandwidth      = "dynamic-pricing-bandwidth"
 	optionNameFullNode                     = "full-node"
 	optionNamePostageContractAddress       = "postage-stamp-address"
 	optionNamePostageContractStartBlock    = "postage-stamp-start-block"
 	optionNamePriceOracleAddress           = "price-oracle-address"
 	optionNameRedistributionAddress        = "redistribution-address"
 	optionNameStakingAddress               = "staking-address"
+	optionNameStakingWebhookURL            = "staking-webhook-url"
 	optionNameBlockTime                    = "block-time"
 	optionWarmUpTime                       = "warmup-time"
 	optionNameMainNet                      = "mainnet"
@@ -82,8 +118,20 @@ const (
 	optionNameTargetNeighborhood           = "target-neighborhood"
 	optionNameNeighborhoodSuggester        = "neighborhood-suggester"
 	optionNameWhitelistedWithdrawalAddress = "withdrawal-addresses-whitelist"
+	optionNameWalletDailyCaps              = "wallet-daily-caps"
+	optionNameWalletApprovalToken          = "wallet-approval-token"
+	optionNameWalletApprovalTimeout        = "wallet-approval-timeout"
 	optionNameTransactionDebugMode         = "transaction-debug-mode"
 	optionReserveMinimumRadius             = "reserve-minimum-radius"
+	optionNameTenantTokens                 = "tenant-tokens"
+	optionNameDBEncryptionEnable           = "db-encryption-enable"
+	optionNamePssAckBatch                  = "pss-ack-batch"
+	optionNameNetworkCA                    = "network-ca"
+	optionNameNetworkCertificate           = "network-certificate"
+	optionNameUpstreamPeers                = "upstream-peers"
+	optionNameP2PUploadRate                = "p2p-upload-rate"
+	optionNameP2PDownloadRate              = "p2p-download-rate"
+	optionNameP2PProtocolTraffic           = "p2p-protocol-traffic"
 )
 
 // nolint:gochecknoinits
@@ -151,6 +199,10 @@ func newCommand(opts ...option) (c *command, err error) {
 
 	c.initVersionCmd()
 	c.initDBCmd()
+	c.initSignerCmd()
+	c.initKeysCmd()
+	c.initCertificateCmd()
+	c.initPostageCmd()
 	if err := c.initSplitCmd(); err != nil {
 		return nil, err
 	}
@@ -246,7 +298,7 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
 	cmd.Flags().String(optionNameAPIAddr, "127.0.0.1:1633", "HTTP API listen address")
 	cmd.Flags().String(optionNameP2PAddr, ":1634", "P2P listen address")
-	cmd.Flags().String(optionNameNATAddr, "", "NAT exposed address")
+	cmd.Flags().StringSlice(optionNameNATAddr, nil, "NAT exposed addresses, domain names are re-resolved periodically")
 	cmd.Flags().Bool(optionNameP2PWSEnable, false, "enable P2P WebSocket transport")
 	cmd.Flags().StringSlice(optionNameBootnodes, []string{""}, "initial nodes to connect to")
 	cmd.Flags().Uint64(optionNameNetworkID, chaincfg.Mainnet.NetworkID, "ID of the Swarm network")
@@ -262,15 +314,44 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().Int64(optionNamePaymentTolerance, 25, "excess debt above payment threshold in percentages where you disconnect from your peer")
 	cmd.Flags().Int64(optionNamePaymentEarly, 50, "percentage below the peers payment threshold when we initiate settlement")
 	cmd.Flags().StringSlice(optionNameResolverEndpoints, []string{}, "ENS compatible API endpoint for a TLD and with contract address, can be repeated, format [tld:][contract-addr@]url")
+	cmd.Flags().Duration(optionNameResolverCacheTTL, 5*time.Minute, "time for which the resolved names are cached, zero disables the cache")
+	cmd.Flags().Duration(optionNameResolverCacheNegativeTTL, time.Minute, "time for which the names that were not found are cached, zero disables the negative caching")
+	cmd.Flags().Int(optionNameResolverCacheCapacity, 10_000, "maximal number of the cached names")
+	cmd.Flags().StringSlice(optionNameResolverFeeds, []string{}, "swarm feed with the name mapping for a TLD, can be repeated, format [tld:]owner@topic")
+	cmd.Flags().Bool(optionNameResolverDNSLinkEnable, false, "resolve the names with their DNSLink TXT records")
 	cmd.Flags().Bool(optionNameBootnodeMode, false, "cause the node to always accept incoming connections")
 	cmd.Flags().Bool(optionNameClefSignerEnable, false, "enable clef signer")
 	cmd.Flags().String(optionNameClefSignerEndpoint, "", "clef signer endpoint")
 	cmd.Flags().String(optionNameClefSignerEthereumAddress, "", "blockchain address to use from clef signer")
+	cmd.Flags().Bool(optionNameExternalSignerEnable, false, "use all node keys from an external signer process")
+	cmd.Flags().String(optionNameExternalSignerEndpoint, "", "path of the unix socket of the external signer")
 	cmd.Flags().String(optionNameSwapEndpoint, "", "swap blockchain endpoint") // deprecated: use rpc endpoint instead
-	cmd.Flags().String(optionNameBlockchainRpcEndpoint, "", "rpc blockchain endpoint")
+	cmd.Flags().StringSlice(optionNameBlockchainRpcEndpoint, nil, "rpc blockchain endpoints, the first reachable one is preferred")
+	cmd.Flags().Int(optionNameBlockchainRpcQuorum, 0, "number of rpc blockchain endpoints cross-checked for block numbers, logs and receipts")
+	cmd.Flags().Duration(optionNameTransactionCheckInterval, 30*time.Second, "interval of the checks for stuck transactions and nonce gaps, zero disables them")
+	cmd.Flags().Bool(optionNameTransactionBump, false, "replace the stuck transactions with higher fees, requires the maximal gas fee cap")
+	cmd.Flags().StringSlice(optionNameTransactionBumpDeadline, nil, "pending time after which a transaction is replaced with higher fees by priority class, in the form class:duration")
+	cmd.Flags().Bool(optionNameTransactionFillNonceGaps, false, "fill the nonce gaps without a known transaction with empty transfers, requires the maximal gas fee cap")
+	cmd.Flags().String(optionNameTransactionMaxGasFeeCap, "", "maximal gas fee cap in wei of the replaced transactions and the nonce gap fillers")
 	cmd.Flags().String(optionNameSwapFactoryAddress, "", "swap factory addresses")
 	cmd.Flags().String(optionNameSwapInitialDeposit, "0", "initial deposit if deploying a new chequebook")
 	cmd.Flags().Bool(optionNameSwapEnable, false, "enable swap")
+	cmd.Flags().Bool(optionNameAccountingLedgerEnable, true, "keep a history of the accounting events of the peers")
+	cmd.Flags().Duration(optionNameAccountingLedgerRetention, 24*time.Hour, "time the accounting ledger entries are kept before they are compacted into hourly rollups")
+	cmd.Flags().Duration(optionNameCashoutCheckInterval, 15*time.Minute, "interval of the checks for cheques to cash out automatically")
+	cmd.Flags().String(optionNameCashoutThreshold, "", "uncashed amount in PLUR from which the cheques of a peer are cashed out automatically")
+	cmd.Flags().Float64(optionNameCashoutMaxGasCostPercent, 0, "gas cost in percents of the uncashed value up to which the cheques are cashed out automatically")
+	cmd.Flags().Float64(optionNameCashoutTokenPrice, 0, "price of one BZZ in native tokens, required by the gas cost cashout rule")
+	cmd.Flags().Duration(optionNameCashoutBatchWindow, 10*time.Minute, "time the automatic cashouts wait for further eligible cheques")
+	cmd.Flags().String(optionNameCashoutMinNativeBalance, "", "native balance in wei below which the automatic cashouts are paused")
+	cmd.Flags().Duration(optionNameCashoutPendingTimeout, time.Hour, "time after which a pending cashout no longer blocks the cashouts of the peer, 0 disables the expiry")
+	cmd.Flags().Bool(optionNameDynamicPricingEnable, false, "adjust the price of the retrieval and pushsync requests to the local load")
+	cmd.Flags().Uint64(optionNameDynamicPricingMinPrice, 0, "minimal price per proximity order, 0 is the base price")
+	cmd.Flags().Uint64(optionNameDynamicPricingMaxPrice, 0, "maximal price per proximity order, 0 is four times the base price")
+	cmd.Flags().Float64(optionNameDynamicPricingMaxChange, 0.1, "maximal relative change of the price per adjustment")
+	cmd.Flags().Duration(optionNameDynamicPricingInterval, time.Minute, "interval of the price adjustments")
+	cmd.Flags().Int64(optionNameDynamicPricingQueueCapacity, 100, "number of the concurrently served retrieval requests at full load")
+	cmd.Flags().Int64(optionNameDynamicPricingBandwidth, 0, "upload bandwidth in bytes per second at full load when the upload is not limited, 0 ignores the bandwidth")
 	cmd.Flags().Bool(optionNameChequebookEnable, true, "enable chequebook")
 	cmd.Flags().Bool(optionNameFullNode, false, "cause the node to start in full mode")
 	cmd.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address")
@@ -278,6 +359,7 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNamePriceOracleAddress, "", "price oracle contract address")
 	cmd.Flags().String(optionNameRedistributionAddress, "", "redistribution contract address")
 	cmd.Flags().String(optionNameStakingAddress, "", "staking contract address")
+	cmd.Flags().String(optionNameStakingWebhookURL, "", "URL that the alerts of the stake freezes and slashes are posted to")
 	cmd.Flags().Uint64(optionNameBlockTime, 15, "chain block time")
 	cmd.Flags().String(optionNameSwapDeploymentGasPrice, "", "gas price in wei to use for deployment and funding")
 	cmd.Flags().Duration(optionWarmUpTime, time.Minute*5, "time to warmup the node before some major protocols can be kicked off")
@@ -293,9 +375,21 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().Uint64(optionNameStateStoreCacheCapacity, 100_000, "lru memory caching capacity in number of statestore entries")
 	cmd.Flags().String(optionNameTargetNeighborhood, "", "neighborhood to target in binary format (ex: 111111001) for mining the initial overlay")
 	cmd.Flags().String(optionNameNeighborhoodSuggester, "https://api.swarmscan.io/v1/network/neighborhoods/suggestion", "suggester for target neighborhood")
-	cmd.Flags().StringSlice(optionNameWhitelistedWithdrawalAddress, []string{}, "withdrawal target addresses")
+	cmd.Flags().StringSlice(optionNameWhitelistedWithdrawalAddress, []string{}, "withdrawal target addresses, can be repeated, format address or name=address")
+	cmd.Flags().StringSlice(optionNameWalletDailyCaps, []string{}, "caps of the withdrawals over the last 24 hours, can be repeated, format coin=amount with the coin BZZ or NativeToken")
+	cmd.Flags().String(optionNameWalletApprovalToken, "", "token that approves the requested withdrawals, enables the two-step withdrawals when set")
+	cmd.Flags().Duration(optionNameWalletApprovalTimeout, 24*time.Hour, "time after which the withdrawals that were not approved expire")
 	cmd.Flags().Bool(optionNameTransactionDebugMode, false, "skips the gas estimate step for contract transactions")
 	cmd.Flags().Uint(optionReserveMinimumRadius, 0, "minimum radius storage treshold")
+	cmd.Flags().StringSlice(optionNameTenantTokens, []string{}, "API bearer tokens attributed to upload accounting tenants, can be repeated, format token=tenant")
+	cmd.Flags().Bool(optionNameDBEncryptionEnable, false, "encrypt the localstore chunk data and sensitive statestore entries at rest")
+	cmd.Flags().String(optionNamePssAckBatch, "", "postage batch id used to stamp the acknowledgements of the received pss messages")
+	cmd.Flags().String(optionNameNetworkCA, "", "ethereum address of the certificate authority of the private network, enables the permissioned mode")
+	cmd.Flags().String(optionNameNetworkCertificate, "", "hex encoded private network membership certificate of the node issued by the certificate authority")
+	cmd.Flags().StringSlice(optionNameUpstreamPeers, []string{}, "multiaddresses of the preferred full nodes that serve the requests of a light node")
+	cmd.Flags().Int64(optionNameP2PUploadRate, 0, "total P2P upload bandwidth limit in bytes per second, 0 means unlimited")
+	cmd.Flags().Int64(optionNameP2PDownloadRate, 0, "total P2P download bandwidth limit in bytes per second, 0 means unlimited")
+	cmd.Flags().StringSlice(optionNameP2PProtocolTraffic, []string{}, "per protocol bandwidth budgets as protocol:upload-rate:download-rate[:high|normal|low]")
 }
 
 func newLogger(cmd *cobra.Command, verbosity string) (log.Logger, error) {
@@ -350,3 +444,171 @@ func (c *command) CheckUnknownParams(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// blockchainRpcEndpoints returns the configured rpc blockchain endpoints.
+// The deprecated swap endpoint replaces them if it is set.
+func (c *command) blockchainRpcEndpoints() []string {
+	if swapEndpoint := c.config.GetString(optionNameSwapEndpoint); swapEndpoint != "" {
+		return []string{swapEndpoint}
+	}
+	var endpoints []string
+	for _, e := range c.config.GetStringSlice(optionNameBlockchainRpcEndpoint) {
+		if e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// dynamicPricingOptions returns the guardrails of the dynamic pricing. The
+// zero prices are set relative to the base price by the node.
+func (c *command) dynamicPricingOptions() (pricer.DynamicOptions, error) {
+	o := pricer.DefaultDynamicOptions(0)
+	o.MinPrice = c.config.GetUint64(optionNameDynamicPricingMinPrice)
+	o.MaxPrice = c.config.GetUint64(optionNameDynamicPricingMaxPrice)
+	if o.MinPrice > 0 && o.MaxPrice > 0 && o.MinPrice > o.MaxPrice {
+		return pricer.DynamicOptions{}, fmt.Errorf("%s is above %s", optionNameDynamicPricingMinPrice, optionNameDynamicPricingMaxPrice)
+	}
+	o.MaxChange = c.config.GetFloat64(optionNameDynamicPricingMaxChange)
+	if o.MaxChange <= 0 || o.MaxChange >= 1 {
+		return pricer.DynamicOptions{}, fmt.Errorf("invalid %s %v", optionNameDynamicPricingMaxChange, o.MaxChange)
+	}
+	o.Interval = c.config.GetDuration(optionNameDynamicPricingInterval)
+	if o.Interval <= 0 {
+		return pricer.DynamicOptions{}, fmt.Errorf("invalid %s %v", optionNameDynamicPricingInterval, o.Interval)
+	}
+	return o, nil
+}
+
+// cashoutOptions returns the policy of the automatic cheque cashouts.
+func (c *command) cashoutOptions() (autocashout.Options, error) {
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = c.config.GetDuration(optionNameCashoutCheckInterval)
+	o.BatchWindow = c.config.GetDuration(optionNameCashoutBatchWindow)
+	o.PendingTimeout = c.config.GetDuration(optionNameCashoutPendingTimeout)
+	if o.PendingTimeout < 0 {
+		return autocashout.Options{}, fmt.Errorf("invalid %s %v", optionNameCashoutPendingTimeout, o.PendingTimeout)
+	}
+	o.MaxGasCostPercent = c.config.GetFloat64(optionNameCashoutMaxGasCostPercent)
+	if o.MaxGasCostPercent < 0 || o.MaxGasCostPercent > 100 {
+		return autocashout.Options{}, fmt.Errorf("invalid %s %v", optionNameCashoutMaxGasCostPercent, o.MaxGasCostPercent)
+	}
+	if v := c.config.GetFloat64(optionNameCashoutTokenPrice); v < 0 {
+		return autocashout.Options{}, fmt.Errorf("invalid %s %v", optionNameCashoutTokenPrice, v)
+	} else if v > 0 {
+		o.TokenPrice = big.NewFloat(v)
+	}
+	if o.MaxGasCostPercent > 0 && o.TokenPrice == nil {
+		return autocashout.Options{}, fmt.Errorf("%s is required by %s", optionNameCashoutTokenPrice, optionNameCashoutMaxGasCostPercent)
+	}
+	for name, amount := range map[string]**big.Int{
+		optionNameCashoutThreshold:        &o.Threshold,
+		optionNameCashoutMinNativeBalance: &o.MinNativeBalance,
+	} {
+		v := c.config.GetString(name)
+		if v == "" {
+			continue
+		}
+		a, ok := new(big.Int).SetString(v, 10)
+		if !ok || a.Sign() < 0 {
+			return autocashout.Options{}, fmt.Errorf("invalid %s %q", name, v)
+		}
+		*amount = a
+	}
+	return o, nil
+}
+
+// resolverCacheOptions returns the parameters of the resolver cache.
+func (c *command) resolverCacheOptions() (resolvercache.Options, error) {
+	o := resolvercache.Options{
+		TTL:         c.config.GetDuration(optionNameResolverCacheTTL),
+		NegativeTTL: c.config.GetDuration(optionNameResolverCacheNegativeTTL),
+		Capacity:    c.config.GetInt(optionNameResolverCacheCapacity),
+	}
+	if o.TTL < 0 {
+		return resolvercache.Options{}, fmt.Errorf("invalid %s %v", optionNameResolverCacheTTL, o.TTL)
+	}
+	if o.NegativeTTL < 0 {
+		return resolvercache.Options{}, fmt.Errorf("invalid %s %v", optionNameResolverCacheNegativeTTL, o.NegativeTTL)
+	}
+	if o.TTL > 0 && o.Capacity <= 0 {
+		return resolvercache.Options{}, fmt.Errorf("invalid %s %d", optionNameResolverCacheCapacity, o.Capacity)
+	}
+	return o, nil
+}
+
+// walletOptions returns the policy of the withdrawals from the node.
+func (c *command) walletOptions() (wallet.Options, error) {
+	o := wallet.Options{
+		DailyCaps:       make(map[string]*big.Int),
+		ApprovalToken:   c.config.GetString(optionNameWalletApprovalToken),
+		ApprovalTimeout: c.config.GetDuration(optionNameWalletApprovalTimeout),
+	}
+	names := make(map[string]bool)
+	for _, v := range c.config.GetStringSlice(optionNameWhitelistedWithdrawalAddress) {
+		if v == "" {
+			continue
+		}
+		a, err := wallet.ParseAddress(v)
+		if err != nil {
+			return wallet.Options{}, fmt.Errorf("invalid %s: %w", optionNameWhitelistedWithdrawalAddress, err)
+		}
+		if names[a.Name] {
+			return wallet.Options{}, fmt.Errorf("invalid %s: duplicate name %q", optionNameWhitelistedWithdrawalAddress, a.Name)
+		}
+		names[a.Name] = true
+		o.Addresses = append(o.Addresses, a)
+	}
+	for _, v := range c.config.GetStringSlice(optionNameWalletDailyCaps) {
+		if v == "" {
+			continue
+		}
+		coin, amount, _ := strings.Cut(v, "=")
+		switch {
+		case strings.EqualFold(coin, wallet.CoinBZZ):
+			coin = wallet.CoinBZZ
+		case strings.EqualFold(coin, wallet.CoinNativeToken):
+			coin = wallet.CoinNativeToken
+		default:
+			return wallet.Options{}, fmt.Errorf("invalid %s %q: unknown coin", optionNameWalletDailyCaps, v)
+		}
+		a, ok := new(big.Int).SetString(amount, 10)
+		if !ok || a.Sign() < 0 {
+			return wallet.Options{}, fmt.Errorf("invalid %s %q", optionNameWalletDailyCaps, v)
+		}
+		o.DailyCaps[coin] = a
+	}
+	if o.ApprovalTimeout < 0 {
+		return wallet.Options{}, fmt.Errorf("invalid %s %v", optionNameWalletApprovalTimeout, o.ApprovalTimeout)
+	}
+	return o, nil
+}
+
+// transactionOptions returns the replacement policies of the transactions.
+func (c *command) transactionOptions() (transaction.Options, error) {
+	o := transaction.DefaultOptions()
+	o.CheckInterval = c.config.GetDuration(optionNameTransactionCheckInterval)
+	o.Bump = c.config.GetBool(optionNameTransactionBump)
+	o.FillNonceGaps = c.config.GetBool(optionNameTransactionFillNonceGaps)
+	for _, v := range c.config.GetStringSlice(optionNameTransactionBumpDeadline) {
+		if v == "" {
+			continue
+		}
+		p, d, err := transaction.ParseBumpDeadline(v)
+		if err != nil {
+			return transaction.Options{}, err
+		}
+		o.BumpDeadlines[p] = d
+	}
+	if v := c.config.GetString(optionNameTransactionMaxGasFeeCap); v != "" {
+		maxGasFeeCap, ok := new(big.Int).SetString(v, 10)
+		if !ok || maxGasFeeCap.Sign() <= 0 {
+			return transaction.Options{}, fmt.Errorf("invalid %s %q", optionNameTransactionMaxGasFeeCap, v)
+		}
+		o.MaxGasFeeCap = maxGasFeeCap
+	}
+	if err := o.Validate(); err != nil {
+		return transaction.Options{}, fmt.Errorf("%s: %w", optionNameTransactionMaxGasFeeCap, err)
+	}
+	return o, nil
+}
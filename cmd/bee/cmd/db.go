@@ -18,6 +18,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
 	"github.com/ethersphere/bee/v2/pkg/node"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/puller"
@@ -50,10 +52,136 @@ func (c *command) initDBCmd() {
 	dbValidateCmd(cmd)
 	dbValidatePinsCmd(cmd)
 	dbRepairReserve(cmd)
+	dbEncryptCmd(cmd)
 
 	c.root.AddCommand(cmd)
 }
 
+// dbEncryptionKey returns the key of an encrypted localstore in the data
+// directory, or nil if the localstore is not encrypted. The keystore is
+// unlocked with the password from the command flags.
+func dbEncryptionKey(cmd *cobra.Command, dataDir string) ([]byte, error) {
+	encrypted, err := storer.IsEncrypted(path.Join(dataDir, ioutil.DataPathLocalstore))
+	if err != nil {
+		return nil, fmt.Errorf("check localstore encryption: %w", err)
+	}
+	if !encrypted {
+		return nil, nil
+	}
+	return dbKeystoreKey(cmd, dataDir)
+}
+
+// dbKeystoreKey unlocks the localstore encryption key from the keystore in the data directory.
+func dbKeystoreKey(cmd *cobra.Command, dataDir string) ([]byte, error) {
+	password, err := cmd.Flags().GetString(optionNamePassword)
+	if err != nil {
+		return nil, fmt.Errorf("get password: %w", err)
+	}
+	if password == "" {
+		pf, err := cmd.Flags().GetString(optionNamePasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("get password-file: %w", err)
+		}
+		if pf == "" {
+			return nil, errors.New("localstore is encrypted: password or password-file required")
+		}
+		b, err := os.ReadFile(pf)
+		if err != nil {
+			return nil, err
+		}
+		password = string(bytes.Trim(b, "\n"))
+	}
+
+	keystore := filekeystore.New(filepath.Join(dataDir, "keys"))
+
+	// make sure the password unlocks the existing keys
+	// before a new localstore key is created with it
+	exists, err := keystore.Exists(libp2pPKFilename)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if _, _, err := keystore.Key(libp2pPKFilename, password, crypto.EDGSecp256_R1); err != nil {
+			return nil, fmt.Errorf("libp2p v2 key: %w", err)
+		}
+	}
+
+	return localstoreEncryptionKey(keystore, password)
+}
+
+func dbEncryptionFlags(c *cobra.Command) {
+	c.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	c.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+}
+
+func dbEncryptCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypts the localstore chunk data and sensitive statestore entries at rest.",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			key, err := dbKeystoreKey(cmd, dataDir)
+			if err != nil {
+				return err
+			}
+
+			logger.Warning("Encryption rewrites all chunk data of the localstore. If the process is stopped for any reason, the localstore may become corrupted.")
+			logger.Warning("It is highly advised to back up the data directory before the encryption.")
+			logger.Warning("After encryption finishes, the node must be started with the --db-encryption-enable option.")
+			logger.Warning("you have another 10 seconds to change your mind and kill this process with CTRL-C...")
+			time.Sleep(10 * time.Second)
+			logger.Warning("proceeding with database encryption...")
+
+			localstorePath := path.Join(dataDir, ioutil.DataPathLocalstore)
+
+			err = storer.Encrypt(context.Background(), localstorePath, &storer.Options{
+				Logger:          logger,
+				RadiusSetter:    noopRadiusSetter{},
+				Batchstore:      new(postage.NoOpBatchStore),
+				ReserveCapacity: node.ReserveCapacity,
+				EncryptionKey:   key,
+			})
+			if err != nil && !errors.Is(err, storer.ErrAlreadyEncrypted) {
+				return fmt.Errorf("localstore: %w", err)
+			}
+
+			stateStore, _, err := node.InitStateStore(logger, dataDir, 1000)
+			if err != nil {
+				return fmt.Errorf("new statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			if _, err := node.EncryptStateStore(stateStore, key); err != nil {
+				return fmt.Errorf("statestore: %w", err)
+			}
+
+			logger.Info("database encryption finished")
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	dbEncryptionFlags(c)
+	cmd.AddCommand(c)
+}
+
 func dbInfoCmd(cmd *cobra.Command) {
 	c := &cobra.Command{
 		Use:   "info",
@@ -153,6 +281,11 @@ func dbCompactCmd(cmd *cobra.Command) {
 				return fmt.Errorf("get validation: %w", err)
 			}
 
+			key, err := dbEncryptionKey(cmd, dataDir)
+			if err != nil {
+				return err
+			}
+
 			logger.Warning("Compaction is a destructive process. If the process is stopped for any reason, the localstore may become corrupted.")
 			logger.Warning("It is highly advised to perform the compaction on a copy of the localstore.")
 			logger.Warning("After compaction finishes, the data directory may be replaced with the compacted version.")
@@ -167,6 +300,7 @@ func dbCompactCmd(cmd *cobra.Command) {
 				RadiusSetter:    noopRadiusSetter{},
 				Batchstore:      new(postage.NoOpBatchStore),
 				ReserveCapacity: node.ReserveCapacity,
+				EncryptionKey:   key,
 			}, validation)
 			if err != nil {
 				return fmt.Errorf("localstore: %w", err)
@@ -178,6 +312,7 @@ func dbCompactCmd(cmd *cobra.Command) {
 	c.Flags().String(optionNameDataDir, "", "data directory")
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
 	c.Flags().Bool(optionNameValidation, false, "run chunk validation checks before and after the compaction")
+	dbEncryptionFlags(c)
 	c.Flags().Duration(optionNameSleepAfter, time.Duration(0), "time to sleep after the operation finished")
 	cmd.AddCommand(c)
 }
@@ -217,11 +352,17 @@ func dbValidatePinsCmd(cmd *cobra.Command) {
 
 			localstorePath := path.Join(dataDir, ioutil.DataPathLocalstore)
 
+			key, err := dbEncryptionKey(cmd, dataDir)
+			if err != nil {
+				return err
+			}
+
 			err = storer.ValidatePinCollectionChunks(context.Background(), localstorePath, providedPin, outputLoc, &storer.Options{
 				Logger:          logger,
 				RadiusSetter:    noopRadiusSetter{},
 				Batchstore:      new(postage.NoOpBatchStore),
 				ReserveCapacity: node.ReserveCapacity,
+				EncryptionKey:   key,
 			})
 			if err != nil {
 				return fmt.Errorf("localstore: %w", err)
@@ -234,6 +375,7 @@ func dbValidatePinsCmd(cmd *cobra.Command) {
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
 	c.Flags().String(optionNameCollectionPin, "", "only validate given pin")
 	c.Flags().String(optionNameOutputLocation, "", "location and name of the output file")
+	dbEncryptionFlags(c)
 	cmd.AddCommand(c)
 }
 
@@ -343,11 +485,17 @@ func dbValidateCmd(cmd *cobra.Command) {
 
 			localstorePath := path.Join(dataDir, ioutil.DataPathLocalstore)
 
+			key, err := dbEncryptionKey(cmd, dataDir)
+			if err != nil {
+				return err
+			}
+
 			err = storer.ValidateRetrievalIndex(context.Background(), localstorePath, &storer.Options{
 				Logger:          logger,
 				RadiusSetter:    noopRadiusSetter{},
 				Batchstore:      new(postage.NoOpBatchStore),
 				ReserveCapacity: node.ReserveCapacity,
+				EncryptionKey:   key,
 			})
 			if err != nil {
 				return fmt.Errorf("localstore: %w", err)
@@ -358,6 +506,7 @@ func dbValidateCmd(cmd *cobra.Command) {
 	}
 	c.Flags().String(optionNameDataDir, "", "data directory")
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	dbEncryptionFlags(c)
 	cmd.AddCommand(c)
 }
 
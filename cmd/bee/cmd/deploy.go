@@ -34,12 +34,7 @@ func (c *command) initDeployCmd() error {
 			dataDir := c.config.GetString(optionNameDataDir)
 			factoryAddress := c.config.GetString(optionNameSwapFactoryAddress)
 			swapInitialDeposit := c.config.GetString(optionNameSwapInitialDeposit)
-			swapEndpoint := c.config.GetString(optionNameSwapEndpoint)
-			blockchainRpcEndpoint := c.config.GetString(optionNameBlockchainRpcEndpoint)
 			deployGasPrice := c.config.GetString(optionNameSwapDeploymentGasPrice)
-			if swapEndpoint != "" {
-				blockchainRpcEndpoint = swapEndpoint
-			}
 			stateStore, _, err := node.InitStateStore(logger, dataDir, 1000)
 			if err != nil {
 				return err
@@ -53,13 +48,20 @@ func (c *command) initDeployCmd() error {
 			}
 			signer := signerConfig.signer
 
+			txOptions, err := c.transactionOptions()
+			if err != nil {
+				return err
+			}
+
 			ctx := cmd.Context()
 
 			swapBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err := node.InitChain(
 				ctx,
 				logger,
 				stateStore,
-				blockchainRpcEndpoint,
+				c.blockchainRpcEndpoints(),
+				c.config.GetInt(optionNameBlockchainRpcQuorum),
+				txOptions,
 				0,
 				signer,
 				blocktime,
@@ -0,0 +1,192 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+	"github.com/spf13/cobra"
+)
+
+// pssKeyRing returns the ring of the rotated pss key.
+func pssKeyRing(ks keystore.Service) *keystore.Ring {
+	return keystore.NewRing(ks, "pss", "pss", crypto.EDGSecp256_K1)
+}
+
+// actKeyRing returns the ring of the rotated ACT session key. Its first
+// version is the swarm key, which is used for the ACT until the first
+// rotation, so that the overlay address does not change with the rotation.
+func actKeyRing(ks keystore.Service) *keystore.Ring {
+	return keystore.NewRing(ks, "act", "swarm", crypto.EDGSecp256_K1)
+}
+
+func (c *command) initKeysCmd() {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the rotatable pss and ACT keys",
+	}
+
+	keysListCmd(cmd)
+	keysRotateCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func keysListCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "Lists all versions of the pss and ACT keys",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ks, password, err := keysKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			rings := []struct {
+				name string
+				ring *keystore.Ring
+			}{
+				{name: "pss", ring: pssKeyRing(ks)},
+				{name: "act", ring: actKeyRing(ks)},
+			}
+			for _, r := range rings {
+				if r.name == "act" {
+					if err := requireSwarmKey(ks); err != nil {
+						return err
+					}
+				}
+				keys, err := r.ring.Keys(password)
+				if err != nil {
+					return fmt.Errorf("%s key: %w", r.name, err)
+				}
+				for i, k := range keys {
+					current := ""
+					if i == 0 {
+						current = " (current)"
+					}
+					cmd.Printf("%s\t%d\t%s%s\n", r.name, k.Version, hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&k.Key.PublicKey)), current)
+				}
+			}
+			return nil
+		},
+	}
+	keysFlags(c)
+	cmd.AddCommand(c)
+}
+
+func keysRotateCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "rotate (pss|act)",
+		Short: "Rotates the pss or ACT key",
+		Long: `Rotates the pss or ACT key while the node is stopped.
+
+The new key is used from the next start of the node and is published with its
+version on the addresses endpoint. The previous versions are kept, so that the
+messages sent to them and the content shared with them remain accessible. The
+ACT histories published with a previous key can be re-encrypted for the new key
+with the grantee rekey endpoint. The overlay address of the node does not change.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ks, password, err := keysKeystore(cmd)
+			if err != nil {
+				return err
+			}
+
+			var ring *keystore.Ring
+			switch args[0] {
+			case "pss":
+				ring = pssKeyRing(ks)
+			case "act":
+				if err := requireSwarmKey(ks); err != nil {
+					return err
+				}
+				ring = actKeyRing(ks)
+			default:
+				return fmt.Errorf("unknown key %q", args[0])
+			}
+
+			k, err := ring.Rotate(password)
+			if err != nil {
+				return fmt.Errorf("rotate %s key: %w", args[0], err)
+			}
+			cmd.Printf("rotated %s key to version %d with public key %s\n", args[0], k.Version, hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&k.Key.PublicKey)))
+			return nil
+		},
+	}
+	keysFlags(c)
+	cmd.AddCommand(c)
+}
+
+func keysFlags(c *cobra.Command) {
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	c.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+}
+
+// keysKeystore returns the keystore in the data directory and the password
+// from the command flags, after making sure that it unlocks the keystore.
+func keysKeystore(cmd *cobra.Command) (keystore.Service, string, error) {
+	dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("get data-dir: %w", err)
+	}
+	if dataDir == "" {
+		return nil, "", errors.New("no data-dir provided")
+	}
+
+	password, err := cmd.Flags().GetString(optionNamePassword)
+	if err != nil {
+		return nil, "", fmt.Errorf("get password: %w", err)
+	}
+	if password == "" {
+		pf, err := cmd.Flags().GetString(optionNamePasswordFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("get password-file: %w", err)
+		}
+		if pf == "" {
+			return nil, "", errors.New("password or password-file required")
+		}
+		b, err := os.ReadFile(pf)
+		if err != nil {
+			return nil, "", err
+		}
+		password = string(bytes.Trim(b, "\n"))
+	}
+
+	ks := filekeystore.New(filepath.Join(dataDir, "keys"))
+	exists, err := ks.Exists(libp2pPKFilename)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		return nil, "", errors.New("no keys found in the data directory")
+	}
+	if _, _, err := ks.Key(libp2pPKFilename, password, crypto.EDGSecp256_R1); err != nil {
+		return nil, "", fmt.Errorf("libp2p v2 key: %w", err)
+	}
+
+	return ks, password, nil
+}
+
+// requireSwarmKey returns an error if the swarm key, which is the first
+// version of the ACT key, is not in the keystore, as with the clef signer.
+func requireSwarmKey(ks keystore.Service) error {
+	exists, err := ks.Exists("swarm")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("act key: swarm key not found in the keystore")
+	}
+	return nil
+}
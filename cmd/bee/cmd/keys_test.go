@@ -0,0 +1,59 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/cmd/bee/cmd"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+)
+
+func TestKeysRotate(t *testing.T) {
+	t.Parallel()
+
+	const password = "secret"
+
+	dataDir := t.TempDir()
+	ks := filekeystore.New(filepath.Join(dataDir, "keys"))
+	if _, _, err := ks.Key("libp2p_v2", password, crypto.EDGSecp256_R1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ks.Key("swarm", password, crypto.EDGSecp256_K1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"pss", "act", "act"} {
+		err := newCommand(t, cmd.WithArgs("keys", "rotate", key, "--data-dir", dataDir, "--password", password)).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := newCommand(t, cmd.WithArgs("keys", "rotate", "pss", "--data-dir", dataDir, "--password", "invalid")).Execute()
+	if err == nil {
+		t.Fatal("expected error with an invalid password")
+	}
+
+	var buf bytes.Buffer
+	err = newCommand(t, cmd.WithArgs("keys", "list", "--data-dir", dataDir, "--password", password), cmd.WithOutput(&buf)).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		versions = append(versions, fields[0]+fields[1])
+	}
+	want := []string{"pss1", "pss0", "act2", "act1", "act0"}
+	if strings.Join(versions, ",") != strings.Join(want, ",") {
+		t.Fatalf("got versions %v, want %v", versions, want)
+	}
+}
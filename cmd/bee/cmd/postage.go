@@ -0,0 +1,237 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage/snapshot"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionNameSnapshotRpcEndpoint = "blockchain-rpc-endpoint"
+	optionNameSnapshotFrom        = "from-block"
+	optionNameSnapshotTo          = "to-block"
+	optionNameSnapshotFile        = "file"
+	optionNameSnapshotReference   = "reference"
+)
+
+func (c *command) initPostageCmd() {
+	cmd := &cobra.Command{
+		Use:   "postage",
+		Short: "Postage stamp utilities",
+	}
+
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Create and verify the postage snapshots that the nodes bootstrap from",
+	}
+	postageSnapshotCreate(snapshotCmd)
+	postageSnapshotVerify(snapshotCmd)
+
+	cmd.AddCommand(snapshotCmd)
+	c.root.AddCommand(cmd)
+}
+
+func postageSnapshotCreate(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "create",
+		Short: "Create a compressed postage snapshot from the postage stamp contract events",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := postageSnapshotLogger(cmd)
+			if err != nil {
+				return err
+			}
+			fileName, err := cmd.Flags().GetString(optionNameSnapshotFile)
+			if err != nil {
+				return fmt.Errorf("get file name: %w", err)
+			}
+			from, err := cmd.Flags().GetUint64(optionNameSnapshotFrom)
+			if err != nil {
+				return fmt.Errorf("get from block: %w", err)
+			}
+			to, err := cmd.Flags().GetUint64(optionNameSnapshotTo)
+			if err != nil {
+				return fmt.Errorf("get to block: %w", err)
+			}
+
+			ctx := cmd.Context()
+			backend, contract, startBlock, err := postageSnapshotBackend(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			defer backend.Close()
+
+			if !cmd.Flags().Changed(optionNameSnapshotFrom) {
+				from = startBlock
+			}
+			if to == 0 {
+				if to, err = backend.BlockNumber(ctx); err != nil {
+					return fmt.Errorf("get block number: %w", err)
+				}
+			}
+
+			logger.Info("creating postage snapshot", "from_block", from, "to_block", to)
+			s, err := snapshot.Create(ctx, backend, contract, from, to)
+			if err != nil {
+				return fmt.Errorf("create snapshot: %w", err)
+			}
+
+			f, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+			if err != nil {
+				return fmt.Errorf("open snapshot file: %w", err)
+			}
+			defer f.Close()
+
+			ref, err := snapshot.Write(f, s)
+			if err != nil {
+				return fmt.Errorf("write snapshot: %w", err)
+			}
+			state, err := snapshot.State(s, contract)
+			if err != nil {
+				return fmt.Errorf("snapshot state: %w", err)
+			}
+
+			logger.Info("postage snapshot created", "file", fileName, "events", len(s.Events), "reference", ref, "state", state)
+			return nil
+		},
+	}
+
+	c.Flags().String(optionNameSnapshotRpcEndpoint, "", "rpc blockchain endpoint")
+	c.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address (default is the address on the chain of the endpoint)")
+	c.Flags().Uint64(optionNameSnapshotFrom, 0, "first block of the snapshot (default is the postage stamp contract deployment block)")
+	c.Flags().Uint64(optionNameSnapshotTo, 0, "last block of the snapshot (default is the latest block)")
+	c.Flags().String(optionNameSnapshotFile, "", "output snapshot file")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	_ = c.MarkFlagRequired(optionNameSnapshotRpcEndpoint)
+	_ = c.MarkFlagRequired(optionNameSnapshotFile)
+
+	cmd.AddCommand(c)
+}
+
+func postageSnapshotVerify(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify that a postage snapshot yields the same batch store state as the chain",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := postageSnapshotLogger(cmd)
+			if err != nil {
+				return err
+			}
+			fileName, err := cmd.Flags().GetString(optionNameSnapshotFile)
+			if err != nil {
+				return fmt.Errorf("get file name: %w", err)
+			}
+			reference, err := cmd.Flags().GetString(optionNameSnapshotReference)
+			if err != nil {
+				return fmt.Errorf("get reference: %w", err)
+			}
+
+			f, err := os.Open(fileName)
+			if err != nil {
+				return fmt.Errorf("open snapshot file: %w", err)
+			}
+			defer f.Close()
+
+			s, ref, err := snapshot.Read(f)
+			if err != nil {
+				return err
+			}
+			if reference != "" {
+				want, err := swarm.ParseHexAddress(reference)
+				if err != nil {
+					return fmt.Errorf("parse reference: %w", err)
+				}
+				if !want.Equal(ref) {
+					return fmt.Errorf("snapshot reference %s does not match %s", ref, want)
+				}
+			}
+
+			ctx := cmd.Context()
+			backend, contract, _, err := postageSnapshotBackend(ctx, cmd)
+			if err != nil {
+				return err
+			}
+			defer backend.Close()
+
+			logger.Info("verifying postage snapshot", "from_block", s.FirstBlockNumber, "to_block", s.LastBlockNumber, "events", len(s.Events), "reference", ref)
+			if err := snapshot.Verify(ctx, backend, contract, s); err != nil {
+				return fmt.Errorf("verify snapshot: %w", err)
+			}
+
+			logger.Info("postage snapshot verified", "reference", ref)
+			return nil
+		},
+	}
+
+	c.Flags().String(optionNameSnapshotRpcEndpoint, "", "rpc blockchain endpoint")
+	c.Flags().String(optionNamePostageContractAddress, "", "postage stamp contract address (default is the address on the chain of the endpoint)")
+	c.Flags().String(optionNameSnapshotFile, "", "snapshot file, compressed or as downloaded from swarm")
+	c.Flags().String(optionNameSnapshotReference, "", "expected swarm reference of the snapshot")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	_ = c.MarkFlagRequired(optionNameSnapshotRpcEndpoint)
+	_ = c.MarkFlagRequired(optionNameSnapshotFile)
+
+	cmd.AddCommand(c)
+}
+
+func postageSnapshotLogger(cmd *cobra.Command) (log.Logger, error) {
+	v, err := cmd.Flags().GetString(optionNameVerbosity)
+	if err != nil {
+		return nil, fmt.Errorf("get verbosity: %w", err)
+	}
+	logger, err := newLogger(cmd, strings.ToLower(v))
+	if err != nil {
+		return nil, fmt.Errorf("new logger: %w", err)
+	}
+	return logger, nil
+}
+
+// postageSnapshotBackend connects to the chain backend and returns the
+// postage stamp contract of its chain with the block it was deployed at.
+func postageSnapshotBackend(ctx context.Context, cmd *cobra.Command) (*ethclient.Client, snapshot.Contract, uint64, error) {
+	endpoint, err := cmd.Flags().GetString(optionNameSnapshotRpcEndpoint)
+	if err != nil {
+		return nil, snapshot.Contract{}, 0, fmt.Errorf("get rpc endpoint: %w", err)
+	}
+
+	backend, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, snapshot.Contract{}, 0, fmt.Errorf("dial rpc endpoint: %w", err)
+	}
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		backend.Close()
+		return nil, snapshot.Contract{}, 0, fmt.Errorf("get chain id: %w", err)
+	}
+	cfg, found := chaincfg.GetByChainID(chainID.Int64())
+	if address, _ := cmd.Flags().GetString(optionNamePostageContractAddress); address != "" {
+		if !common.IsHexAddress(address) {
+			backend.Close()
+			return nil, snapshot.Contract{}, 0, fmt.Errorf("invalid postage stamp address %q", address)
+		}
+		cfg.PostageStampAddress = common.HexToAddress(address)
+	} else if !found {
+		backend.Close()
+		return nil, snapshot.Contract{}, 0, fmt.Errorf("unknown chain id %d, postage stamp address is required", chainID)
+	}
+
+	contract := snapshot.Contract{
+		Address: cfg.PostageStampAddress,
+		ABI:     abiutil.MustParseABI(cfg.PostageStampABI),
+	}
+	return backend, contract, cfg.PostageStampStartBlock, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto/remote"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initSignerCmd() {
+	cmd := &cobra.Command{
+		Use:   "signer",
+		Short: "Run an external signer holding the node keys",
+		Long: `Run an external signer holding the node keys.
+
+The signer decrypts the keys from the keystore in the data directory and
+serves the signing and key agreement operations on the unix socket, which
+only the user running the signer is allowed to connect to. Start the
+node with the --external-signer-enable and --external-signer-endpoint options
+to use the keys without holding them in the node process.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %w", err)
+			}
+			logger, err := newLogger(cmd, strings.ToLower(v))
+			if err != nil {
+				return fmt.Errorf("new logger: %w", err)
+			}
+
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %w", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+			endpoint, err := cmd.Flags().GetString(optionNameExternalSignerEndpoint)
+			if err != nil {
+				return fmt.Errorf("get endpoint: %w", err)
+			}
+			if endpoint == "" {
+				return errors.New("no endpoint provided")
+			}
+
+			password, err := cmd.Flags().GetString(optionNamePassword)
+			if err != nil {
+				return fmt.Errorf("get password: %w", err)
+			}
+			if password == "" {
+				pf, err := cmd.Flags().GetString(optionNamePasswordFile)
+				if err != nil {
+					return fmt.Errorf("get password-file: %w", err)
+				}
+				if pf != "" {
+					b, err := os.ReadFile(pf)
+					if err != nil {
+						return err
+					}
+					password = string(bytes.Trim(b, "\n"))
+				} else {
+					password, err = terminalPromptPassword(cmd, c.passwordReader, "Password")
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			socket, err := remote.SocketPath(endpoint)
+			if err != nil {
+				return err
+			}
+			// remove the socket left behind by a previous run
+			if fi, err := os.Stat(socket); err == nil && fi.Mode()&fs.ModeSocket != 0 {
+				if err := os.Remove(socket); err != nil {
+					return err
+				}
+			}
+
+			// the socket is accessible only by the owner of the node, who
+			// is the only one allowed to use the keys
+			l, err := remote.Listen(endpoint)
+			if err != nil {
+				return fmt.Errorf("listen: %w", err)
+			}
+
+			srv := remote.NewServer(filekeystore.New(filepath.Join(dataDir, "keys")), password)
+
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigs
+				logger.Info("shutting down signer")
+				_ = l.Close()
+			}()
+
+			logger.Info("signer listening", "endpoint", endpoint)
+			return srv.Serve(l)
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, "", "data directory")
+	cmd.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+	cmd.Flags().String(optionNameExternalSignerEndpoint, "", "path of the unix socket to listen on")
+
+	c.root.AddCommand(cmd)
+}
@@ -28,15 +28,19 @@ import (
 	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/crypto/clef"
+	"github.com/ethersphere/bee/v2/pkg/crypto/remote"
 	"github.com/ethersphere/bee/v2/pkg/keystore"
 	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
 	memkeystore "github.com/ethersphere/bee/v2/pkg/keystore/mem"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/node"
+	"github.com/ethersphere/bee/v2/pkg/pss"
 	"github.com/ethersphere/bee/v2/pkg/resolver/multiresolver"
 	"github.com/ethersphere/bee/v2/pkg/spinlock"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/kardianos/service"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/spf13/cobra"
 )
 
@@ -214,6 +218,16 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		}
 	}
 
+	resolverFeedCfgs, err := multiresolver.ParseFeedStrings(c.config.GetStringSlice(optionNameResolverFeeds))
+	if err != nil {
+		return nil, err
+	}
+
+	resolverCacheOptions, err := c.resolverCacheOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	signerConfig, err := c.configureSigner(cmd, logger)
 	if err != nil {
 		return nil, err
@@ -263,6 +277,33 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		tracingEndpoint = strings.Join([]string{c.config.GetString(optionNameTracingHost), c.config.GetString(optionNameTracingPort)}, ":")
 	}
 
+	var natAddrs []string
+	for _, a := range c.config.GetStringSlice(optionNameNATAddr) {
+		if a != "" {
+			natAddrs = append(natAddrs, a)
+		}
+	}
+
+	txOptions, err := c.transactionOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	cashoutOptions, err := c.cashoutOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicPricingOptions, err := c.dynamicPricingOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	walletOptions, err := c.walletOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	staticNodesOpt := c.config.GetStringSlice(optionNameStaticNodes)
 	staticNodes := make([]swarm.Address, 0, len(staticNodesOpt))
 	for _, p := range staticNodesOpt {
@@ -277,10 +318,38 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		return nil, errors.New("static nodes can only be configured on bootnodes")
 	}
 
-	swapEndpoint := c.config.GetString(optionNameSwapEndpoint)
-	blockchainRpcEndpoint := c.config.GetString(optionNameBlockchainRpcEndpoint)
-	if swapEndpoint != "" {
-		blockchainRpcEndpoint = swapEndpoint
+	tenantTokensOpt := c.config.GetStringSlice(optionNameTenantTokens)
+	tenantTokens := make(map[string]string, len(tenantTokensOpt))
+	for _, v := range tenantTokensOpt {
+		token, label, ok := strings.Cut(v, "=")
+		if !ok || token == "" || !tenant.ValidLabel(label) {
+			return nil, fmt.Errorf("invalid tenant token %q, expected format token=tenant", v)
+		}
+		tenantTokens[token] = label
+	}
+
+	var pssAckBatchID []byte
+	if v := c.config.GetString(optionNamePssAckBatch); v != "" {
+		pssAckBatchID, err = hex.DecodeString(v)
+		if err != nil || len(pssAckBatchID) != 32 {
+			return nil, fmt.Errorf("invalid pss ack batch id %q", v)
+		}
+	}
+
+	var (
+		networkCA          *common.Address
+		networkCertificate []byte
+	)
+	if v := c.config.GetString(optionNameNetworkCA); v != "" {
+		if !common.IsHexAddress(v) {
+			return nil, fmt.Errorf("invalid network ca address %q", v)
+		}
+		ca := common.HexToAddress(v)
+		networkCA = &ca
+		networkCertificate, err = hex.DecodeString(c.config.GetString(optionNameNetworkCertificate))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network certificate: %w", err)
+		}
 	}
 
 	var neighborhoodSuggester string
@@ -297,7 +366,7 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		DBDisableSeeksCompaction:      c.config.GetBool(optionNameDBDisableSeeksCompaction),
 		APIAddr:                       c.config.GetString(optionNameAPIAddr),
 		Addr:                          c.config.GetString(optionNameP2PAddr),
-		NATAddr:                       c.config.GetString(optionNameNATAddr),
+		NATAddrs:                      natAddrs,
 		EnableWS:                      c.config.GetBool(optionNameP2PWSEnable),
 		WelcomeMessage:                c.config.GetString(optionWelcomeMessage),
 		Bootnodes:                     networkConfig.bootNodes,
@@ -310,8 +379,20 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		PaymentTolerance:              c.config.GetInt64(optionNamePaymentTolerance),
 		PaymentEarly:                  c.config.GetInt64(optionNamePaymentEarly),
 		ResolverConnectionCfgs:        resolverCfgs,
+		ResolverFeedCfgs:              resolverFeedCfgs,
+		ResolverDNSLink:               c.config.GetBool(optionNameResolverDNSLinkEnable),
+		ResolverCacheOptions:          resolverCacheOptions,
 		BootnodeMode:                  bootNode,
-		BlockchainRpcEndpoint:         blockchainRpcEndpoint,
+		BlockchainRpcEndpoints:        c.blockchainRpcEndpoints(),
+		BlockchainRpcQuorum:           c.config.GetInt(optionNameBlockchainRpcQuorum),
+		TransactionOptions:            txOptions,
+		CashoutOptions:                cashoutOptions,
+		AccountingLedgerEnable:        c.config.GetBool(optionNameAccountingLedgerEnable),
+		AccountingLedgerRetention:     c.config.GetDuration(optionNameAccountingLedgerRetention),
+		DynamicPricingEnable:          c.config.GetBool(optionNameDynamicPricingEnable),
+		DynamicPricingOptions:         dynamicPricingOptions,
+		DynamicPricingQueueCapacity:   c.config.GetInt64(optionNameDynamicPricingQueueCapacity),
+		DynamicPricingBandwidth:       c.config.GetInt64(optionNameDynamicPricingBandwidth),
 		SwapFactoryAddress:            c.config.GetString(optionNameSwapFactoryAddress),
 		SwapInitialDeposit:            c.config.GetString(optionNameSwapInitialDeposit),
 		SwapEnable:                    c.config.GetBool(optionNameSwapEnable),
@@ -322,6 +403,7 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		PriceOracleAddress:            c.config.GetString(optionNamePriceOracleAddress),
 		RedistributionContractAddress: c.config.GetString(optionNameRedistributionAddress),
 		StakingContractAddress:        c.config.GetString(optionNameStakingAddress),
+		StakingWebhookURL:             c.config.GetString(optionNameStakingWebhookURL),
 		BlockTime:                     networkConfig.blockTime,
 		DeployGasPrice:                c.config.GetString(optionNameSwapDeploymentGasPrice),
 		WarmupTime:                    c.config.GetDuration(optionWarmUpTime),
@@ -331,15 +413,32 @@ func buildBeeNode(ctx context.Context, c *command, cmd *cobra.Command, logger lo
 		BlockProfile:                  c.config.GetBool(optionNamePProfBlock),
 		MutexProfile:                  c.config.GetBool(optionNamePProfMutex),
 		StaticNodes:                   staticNodes,
+		UpstreamPeers:                 c.config.GetStringSlice(optionNameUpstreamPeers),
+		P2PUploadRate:                 c.config.GetInt64(optionNameP2PUploadRate),
+		P2PDownloadRate:               c.config.GetInt64(optionNameP2PDownloadRate),
+		P2PProtocolTraffic:            c.config.GetStringSlice(optionNameP2PProtocolTraffic),
 		AllowPrivateCIDRs:             c.config.GetBool(optionNameAllowPrivateCIDRs),
 		UsePostageSnapshot:            c.config.GetBool(optionNameUsePostageSnapshot),
 		EnableStorageIncentives:       c.config.GetBool(optionNameStorageIncentivesEnable),
 		StatestoreCacheCapacity:       c.config.GetUint64(optionNameStateStoreCacheCapacity),
 		TargetNeighborhood:            c.config.GetString(optionNameTargetNeighborhood),
 		NeighborhoodSuggester:         neighborhoodSuggester,
-		WhitelistedWithdrawalAddress:  c.config.GetStringSlice(optionNameWhitelistedWithdrawalAddress),
+		WalletOptions:                 walletOptions,
 		TrxDebugMode:                  c.config.GetBool(optionNameTransactionDebugMode),
 		ReserveMinimumRadius:          c.config.GetUint(optionReserveMinimumRadius),
+		TenantTokens:                  tenantTokens,
+		LocalstoreEncryptionKey:       signerConfig.localstoreKey,
+		PssAckBatchID:                 pssAckBatchID,
+		NetworkCA:                     networkCA,
+		NetworkCertificate:            networkCertificate,
+		Libp2pIdentity:                signerConfig.libp2pIdentity,
+		PssDH:                         signerConfig.pssDH,
+		PssPublicKey:                  signerConfig.pssPublicKey,
+		PssPreviousKeys:               signerConfig.pssPreviousKeys,
+		PssKeyVersion:                 signerConfig.pssKeyVersion,
+		ActPublicKey:                  signerConfig.actPublicKey,
+		ActPreviousSessions:           signerConfig.actPreviousSessions,
+		ActKeyVersion:                 signerConfig.actKeyVersion,
 	})
 
 	return b, err
@@ -366,7 +465,17 @@ type signerConfig struct {
 	publicKey        *ecdsa.PublicKey
 	libp2pPrivateKey *ecdsa.PrivateKey
 	pssPrivateKey    *ecdsa.PrivateKey
+	localstoreKey    []byte
+	libp2pIdentity   libp2pcrypto.PrivKey
+	pssDH            crypto.DH
+	pssPublicKey     *ecdsa.PublicKey
 	session          accesscontrol.Session
+	// previous versions of the rotated keys
+	pssPreviousKeys     []pss.RotatedKey
+	pssKeyVersion       uint32
+	actPublicKey        *ecdsa.PublicKey
+	actPreviousSessions []accesscontrol.RotatedSession
+	actKeyVersion       uint32
 }
 
 func waitForClef(logger log.Logger, maxRetries uint64, endpoint string) (externalSigner *external.ExternalSigner, err error) {
@@ -386,6 +495,10 @@ func waitForClef(logger log.Logger, maxRetries uint64, endpoint string) (externa
 }
 
 func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config *signerConfig, err error) {
+	if c.config.GetBool(optionNameExternalSignerEnable) {
+		return c.configureExternalSigner(logger)
+	}
+
 	var keystore keystore.Service
 	if c.config.GetString(optionNameDataDir) == "" {
 		keystore = memkeystore.New()
@@ -398,6 +511,9 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 	var password string
 	var publicKey *ecdsa.PublicKey
 	var session accesscontrol.Session
+	var actPublicKey *ecdsa.PublicKey
+	var actPreviousSessions []accesscontrol.RotatedSession
+	var actKeyVersion uint32
 	if p := c.config.GetString(optionNamePassword); p != "" {
 		password = p
 	} else if pf := c.config.GetString(optionNamePasswordFile); pf != "" {
@@ -471,6 +587,29 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 		signer = crypto.NewDefaultSigner(swarmPrivateKey)
 		publicKey = &swarmPrivateKey.PublicKey
 		session = accesscontrol.NewDefaultSession(swarmPrivateKey)
+
+		// the swarm key is the first version of the act key, so the
+		// ring is only unlocked if the act key has been rotated
+		actRing := actKeyRing(keystore)
+		actKeyVersion, err = actRing.Version()
+		if err != nil {
+			return nil, fmt.Errorf("act key: %w", err)
+		}
+		if actKeyVersion > 0 {
+			actKeys, err := actRing.Keys(password)
+			if err != nil {
+				return nil, fmt.Errorf("act key: %w", err)
+			}
+			session = accesscontrol.NewDefaultSession(actKeys[0].Key)
+			actPublicKey = &actKeys[0].Key.PublicKey
+			for _, k := range actKeys[1:] {
+				actPreviousSessions = append(actPreviousSessions, accesscontrol.RotatedSession{
+					Session:   accesscontrol.NewDefaultSession(k.Key),
+					PublicKey: &k.Key.PublicKey,
+				})
+			}
+			logger.Info("act public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(actPublicKey)), "version", actKeyVersion)
+		}
 	}
 
 	logger.Info("swarm public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(publicKey)))
@@ -485,17 +624,29 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 		logger.Debug("using existing libp2p key")
 	}
 
-	pssPrivateKey, created, err := keystore.Key("pss", password, crypto.EDGSecp256_K1)
+	pssKeys, err := pssKeyRing(keystore).Keys(password)
 	if err != nil {
 		return nil, fmt.Errorf("pss key: %w", err)
 	}
-	if created {
-		logger.Debug("new pss key created")
-	} else {
-		logger.Debug("using existing pss key")
+	pssPrivateKey := pssKeys[0].Key
+	var pssPreviousKeys []pss.RotatedKey
+	for _, k := range pssKeys[1:] {
+		pssPreviousKeys = append(pssPreviousKeys, pss.RotatedKey{
+			DH:        crypto.NewDH(k.Key),
+			PublicKey: &k.Key.PublicKey,
+		})
 	}
 
-	logger.Info("pss public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&pssPrivateKey.PublicKey)))
+	logger.Info("pss public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&pssPrivateKey.PublicKey)), "version", pssKeys[0].Version)
+
+	var localstoreKey []byte
+	if c.config.GetBool(optionNameDBEncryptionEnable) {
+		localstoreKey, err = localstoreEncryptionKey(keystore, password)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("localstore encryption at rest enabled")
+	}
 
 	// postinst and post scripts inside packaging/{deb,rpm} depend and parse on this log output
 	overlayEthAddress, err := signer.EthereumAddress()
@@ -509,10 +660,94 @@ func (c *command) configureSigner(cmd *cobra.Command, logger log.Logger) (config
 		publicKey:        publicKey,
 		libp2pPrivateKey: libp2pPrivateKey,
 		pssPrivateKey:    pssPrivateKey,
+		localstoreKey:    localstoreKey,
 		session:          session,
+
+		pssPreviousKeys:     pssPreviousKeys,
+		pssKeyVersion:       pssKeys[0].Version,
+		actPublicKey:        actPublicKey,
+		actPreviousSessions: actPreviousSessions,
+		actKeyVersion:       actKeyVersion,
 	}, nil
 }
 
+// configureExternalSigner configures all node keys to be used through an
+// external signer process, so that no private key is held by the node.
+func (c *command) configureExternalSigner(logger log.Logger) (*signerConfig, error) {
+	if c.config.GetBool(optionNameClefSignerEnable) {
+		return nil, errors.New("clef signer and external signer can not be enabled at the same time")
+	}
+	if c.config.GetBool(optionNameDBEncryptionEnable) {
+		return nil, errors.New("localstore encryption is not supported with the external signer")
+	}
+
+	endpoint := c.config.GetString(optionNameExternalSignerEndpoint)
+	if endpoint == "" {
+		return nil, errors.New("external signer endpoint not provided")
+	}
+	client, err := remote.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := client.Signer("swarm")
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	swarmDH, err := client.DH("swarm")
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+
+	logger.Info("swarm public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(publicKey)))
+
+	libp2pIdentity, err := client.Identity(libp2pPKFilename)
+	if err != nil {
+		return nil, fmt.Errorf("libp2p v2 key: %w", err)
+	}
+
+	pssDH, err := client.DH("pss")
+	if err != nil {
+		return nil, fmt.Errorf("pss key: %w", err)
+	}
+	pssPublicKey, err := client.PublicKey("pss", remote.Secp256k1)
+	if err != nil {
+		return nil, fmt.Errorf("pss key: %w", err)
+	}
+
+	logger.Info("pss public key", "public_key", hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(pssPublicKey)))
+
+	// postinst and post scripts inside packaging/{deb,rpm} depend and parse on this log output
+	overlayEthAddress, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("using ethereum address", "address", overlayEthAddress)
+
+	return &signerConfig{
+		signer:         signer,
+		publicKey:      publicKey,
+		session:        accesscontrol.NewDHSession(swarmDH),
+		libp2pIdentity: libp2pIdentity,
+		pssDH:          pssDH,
+		pssPublicKey:   pssPublicKey,
+	}, nil
+}
+
+// localstoreEncryptionKey returns the key used to encrypt the localstore at
+// rest. The key is kept in the keystore protected by the node password.
+func localstoreEncryptionKey(keystore keystore.Service, password string) ([]byte, error) {
+	pk, _, err := keystore.Key("localstore", password, crypto.EDGSecp256_K1)
+	if err != nil {
+		return nil, fmt.Errorf("localstore key: %w", err)
+	}
+	return crypto.EncodeSecp256k1PrivateKey(pk)
+}
+
 type networkConfig struct {
 	bootNodes []string
 	blockTime time.Duration
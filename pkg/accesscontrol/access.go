@@ -42,6 +42,7 @@ type Control interface {
 // ActLogic represents the access control logic.
 type ActLogic struct {
 	Session
+	previous []RotatedSession
 }
 
 var _ Control = (*ActLogic)(nil)
@@ -79,7 +80,12 @@ func (al ActLogic) AddGrantee(ctx context.Context, storage kvs.KeyValueStore, pu
 		}
 	}
 
-	lookupKey, accessKeyDecryptionKey, err := al.getKeys(granteePubKey)
+	return al.addAccessKey(ctx, storage, granteePubKey, accessKey)
+}
+
+// addAccessKey adds the access key encrypted for the grantee to the ACT.
+func (al ActLogic) addAccessKey(ctx context.Context, storage kvs.KeyValueStore, granteePubKey *ecdsa.PublicKey, accessKey encryption.Key) error {
+	lookupKey, accessKeyDecryptionKey, err := getKeys(al.Session, granteePubKey)
 	if err != nil {
 		return err
 	}
@@ -101,8 +107,18 @@ func (al ActLogic) AddGrantee(ctx context.Context, storage kvs.KeyValueStore, pu
 }
 
 // Will return the access key for a publisher (public key).
+// The previous keys of the session are tried if the current key has no access.
 func (al *ActLogic) getAccessKey(ctx context.Context, storage kvs.KeyValueStore, publisherPubKey *ecdsa.PublicKey) ([]byte, error) {
-	publisherLookupKey, publisherAKDecryptionKey, err := al.getKeys(publisherPubKey)
+	accessKey, err := accessKeyWith(ctx, al.Session, storage, publisherPubKey)
+	for i := 0; errors.Is(err, ErrNotFound) && i < len(al.previous); i++ {
+		accessKey, err = accessKeyWith(ctx, al.previous[i], storage, publisherPubKey)
+	}
+	return accessKey, err
+}
+
+// accessKeyWith returns the access key for a publisher using the keys derived by the session.
+func accessKeyWith(ctx context.Context, session Session, storage kvs.KeyValueStore, publisherPubKey *ecdsa.PublicKey) ([]byte, error) {
+	publisherLookupKey, publisherAKDecryptionKey, err := getKeys(session, publisherPubKey)
 	if err != nil {
 		return nil, err
 	}
@@ -126,10 +142,24 @@ func (al *ActLogic) getAccessKey(ctx context.Context, storage kvs.KeyValueStore,
 	return accessKey, nil
 }
 
+// publishedWith returns the previous session whose key published the ACT.
+func (al *ActLogic) publishedWith(ctx context.Context, storage kvs.KeyValueStore) (RotatedSession, error) {
+	for _, previous := range al.previous {
+		_, err := accessKeyWith(ctx, previous.Session, storage, previous.PublicKey)
+		if err == nil {
+			return previous, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return RotatedSession{}, err
+		}
+	}
+	return RotatedSession{}, ErrNoPreviousKey
+}
+
 // Generate lookup key and access key decryption key for a given public key.
-func (al *ActLogic) getKeys(publicKey *ecdsa.PublicKey) ([]byte, []byte, error) {
+func getKeys(session Session, publicKey *ecdsa.PublicKey) ([]byte, []byte, error) {
 	nonces := [][]byte{zeroByteArray, oneByteArray}
-	keys, err := al.Session.Key(publicKey, nonces)
+	keys, err := session.Key(publicKey, nonces)
 	if len(keys) != len(nonces) {
 		return nil, nil, err
 	}
@@ -152,9 +182,12 @@ func (al ActLogic) DecryptRef(ctx context.Context, storage kvs.KeyValueStore, en
 	return swarm.NewAddress(ref), nil
 }
 
-// NewLogic creates a new ACT Logic from a session.
-func NewLogic(s Session) ActLogic {
+// NewLogic creates a new ACT Logic from a session. The sessions of the
+// previous versions of a rotated key are used for looking up the access
+// keys that were granted to them.
+func NewLogic(s Session, previous ...RotatedSession) ActLogic {
 	return ActLogic{
-		Session: s,
+		Session:  s,
+		previous: previous,
 	}
 }
@@ -9,8 +9,10 @@ package accesscontrol
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol/kvs"
@@ -37,9 +39,17 @@ type Controller interface {
 	DownloadHandler(ctx context.Context, ls file.LoadSaver, encryptedRef swarm.Address, publisher *ecdsa.PublicKey, historyRef swarm.Address, timestamp int64) (swarm.Address, error)
 	// UploadHandler encrypts the reference and stores it in the history as the latest update.
 	UploadHandler(ctx context.Context, ls file.LoadSaver, reference swarm.Address, publisher *ecdsa.PublicKey, historyRef swarm.Address) (swarm.Address, swarm.Address, swarm.Address, error)
+	// Rekey re-encrypts the history published with a previous key for the current key and returns the new history reference.
+	Rekey(ctx context.Context, ls file.LoadSaver, historyRef swarm.Address, publisher *ecdsa.PublicKey) (swarm.Address, error)
 	io.Closer
 }
 
+// encryptedGranteeListRefKey is the history entry metadata key of the encrypted grantee list reference.
+const encryptedGranteeListRefKey = "encryptedglref"
+
+// ErrNoPreviousKey is returned when a history to be re-keyed was not published with a previous key.
+var ErrNoPreviousKey = errors.New("history is not published with a previous key")
+
 // ControllerStruct represents a controller for access control logic.
 type ControllerStruct struct {
 	access ActLogic
@@ -165,7 +175,7 @@ func (c *ControllerStruct) UpdateHandler(
 		}
 	}
 
-	mtdt := map[string]string{"encryptedglref": egranteeRef.String()}
+	mtdt := map[string]string{encryptedGranteeListRefKey: egranteeRef.String()}
 	hRef, actRef, err := c.saveHistoryAndAct(ctx, history, &mtdt, act)
 	if err != nil {
 		return swarm.ZeroAddress, swarm.ZeroAddress, swarm.ZeroAddress, swarm.ZeroAddress, err
@@ -174,6 +184,93 @@ func (c *ControllerStruct) UpdateHandler(
 	return granteeRef, egranteeRef, hRef, actRef, nil
 }
 
+// Rekey re-encrypts every entry of a history that was published with a
+// previous key of the session for the current key, which is the given
+// publisher. The entries keep their timestamps and access keys, so that the
+// encrypted references remain valid with the new publisher.
+func (c *ControllerStruct) Rekey(ctx context.Context, ls file.LoadSaver, historyRef swarm.Address, publisher *ecdsa.PublicKey) (swarm.Address, error) {
+	history, err := NewHistoryReference(ls, historyRef)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	entries, err := history.Entries(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if len(entries) == 0 {
+		return swarm.ZeroAddress, ErrNotFound
+	}
+
+	latest, err := kvs.NewReference(ls, entries[0].Reference())
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	previous, err := c.access.publishedWith(ctx, latest)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	rekeyed, err := NewHistory(ls)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	for _, entry := range entries {
+		act, err := kvs.NewReference(ls, entry.Reference())
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		accessKey, err := accessKeyWith(ctx, previous.Session, act, previous.PublicKey)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+
+		newAct, err := kvs.New(ls)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		if err := c.access.addAccessKey(ctx, newAct, publisher, accessKey); err != nil {
+			return swarm.ZeroAddress, err
+		}
+
+		mtdt := maps.Clone(entry.Metadata())
+		if eglref, ok := mtdt[encryptedGranteeListRefKey]; ok {
+			encryptedglRef, err := swarm.ParseHexAddress(eglref)
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			granteeRef, err := decryptRef(previous.Session, previous.PublicKey, encryptedglRef)
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			gl, err := NewGranteeListReference(ctx, ls, granteeRef)
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			for _, grantee := range gl.Get() {
+				if err := c.access.addAccessKey(ctx, newAct, grantee, accessKey); err != nil {
+					return swarm.ZeroAddress, err
+				}
+			}
+			egranteeRef, err := c.encryptRefForPublisher(publisher, granteeRef)
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			mtdt[encryptedGranteeListRefKey] = egranteeRef.String()
+		}
+
+		actRef, err := newAct.Save(ctx)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		timestamp := entry.Timestamp
+		if err := rekeyed.Add(ctx, actRef, &timestamp, &mtdt); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+
+	return rekeyed.Store(ctx)
+}
+
 // Get returns the list of grantees for the given publisher.
 // The list is accessible only by the publisher.
 func (c *ControllerStruct) Get(ctx context.Context, ls file.LoadSaver, publisher *ecdsa.PublicKey, encryptedglRef swarm.Address) ([]*ecdsa.PublicKey, error) {
@@ -261,7 +358,15 @@ func (c *ControllerStruct) getGranteeList(ctx context.Context, ls file.LoadSaver
 }
 
 func (c *ControllerStruct) encryptRefForPublisher(publisherPubKey *ecdsa.PublicKey, ref swarm.Address) (swarm.Address, error) {
-	keys, err := c.access.Session.Key(publisherPubKey, [][]byte{oneByteArray})
+	return encryptRef(c.access.Session, publisherPubKey, ref)
+}
+
+func (c *ControllerStruct) decryptRefForPublisher(publisherPubKey *ecdsa.PublicKey, encryptedRef swarm.Address) (swarm.Address, error) {
+	return decryptRef(c.access.Session, publisherPubKey, encryptedRef)
+}
+
+func encryptRef(session Session, publisherPubKey *ecdsa.PublicKey, ref swarm.Address) (swarm.Address, error) {
+	keys, err := session.Key(publisherPubKey, [][]byte{oneByteArray})
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
@@ -274,8 +379,8 @@ func (c *ControllerStruct) encryptRefForPublisher(publisherPubKey *ecdsa.PublicK
 	return swarm.NewAddress(encryptedRef), nil
 }
 
-func (c *ControllerStruct) decryptRefForPublisher(publisherPubKey *ecdsa.PublicKey, encryptedRef swarm.Address) (swarm.Address, error) {
-	keys, err := c.access.Session.Key(publisherPubKey, [][]byte{oneByteArray})
+func decryptRef(session Session, publisherPubKey *ecdsa.PublicKey, encryptedRef swarm.Address) (swarm.Address, error) {
+	keys, err := session.Key(publisherPubKey, [][]byte{oneByteArray})
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
@@ -333,3 +333,60 @@ func TestController_Get(t *testing.T) {
 		assert.Nil(t, grantees)
 	})
 }
+
+func TestController_Rekey(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	previous := getPrivKey(1)
+	current := getPrivKey(0)
+	grantee := getPrivKey(2)
+	ls := createLs()
+	gls := loadsave.New(mockStorer.ChunkStore(), mockStorer.Cache(), requestPipelineFactory(context.Background(), mockStorer.Cache(), true, redundancy.NONE))
+
+	previousCtrl := accesscontrol.NewController(accesscontrol.NewLogic(accesscontrol.NewDefaultSession(previous)))
+	c := accesscontrol.NewController(accesscontrol.NewLogic(
+		accesscontrol.NewDefaultSession(current),
+		accesscontrol.RotatedSession{Session: accesscontrol.NewDefaultSession(previous), PublicKey: &previous.PublicKey},
+	))
+	granteeCtrl := accesscontrol.NewController(accesscontrol.NewLogic(accesscontrol.NewDefaultSession(grantee)))
+
+	ref := swarm.RandAddress(t)
+	_, hRef, encRef, err := previousCtrl.UploadHandler(ctx, ls, ref, &previous.PublicKey, swarm.ZeroAddress)
+	require.NoError(t, err)
+	uploadTS := time.Now().Unix()
+
+	// Need to wait a second so that a new history mantaray fork is created for the update
+	time.Sleep(1 * time.Second)
+	_, _, hRef, _, err = previousCtrl.UpdateHandler(ctx, ls, gls, swarm.ZeroAddress, hRef, &previous.PublicKey, []*ecdsa.PublicKey{&grantee.PublicKey}, nil)
+	require.NoError(t, err)
+
+	// the previous key still gives access to the history before the rekey
+	decRef, err := c.DownloadHandler(ctx, ls, encRef, &previous.PublicKey, hRef, time.Now().Unix())
+	require.NoError(t, err)
+	assert.Equal(t, ref, decRef)
+
+	rekeyedRef, err := c.Rekey(ctx, ls, hRef, &current.PublicKey)
+	require.NoError(t, err)
+
+	// the encrypted reference remains valid with the current key as the publisher
+	decRef, err = granteeCtrl.DownloadHandler(ctx, ls, encRef, &current.PublicKey, rekeyedRef, time.Now().Unix())
+	require.NoError(t, err)
+	assert.Equal(t, ref, decRef)
+	decRef, err = c.DownloadHandler(ctx, ls, encRef, &current.PublicKey, rekeyedRef, uploadTS)
+	require.NoError(t, err)
+	assert.Equal(t, ref, decRef)
+
+	// the grantee list is accessible with the current key
+	h, err := accesscontrol.NewHistoryReference(ls, rekeyedRef)
+	require.NoError(t, err)
+	entry, err := h.Lookup(ctx, time.Now().Unix())
+	require.NoError(t, err)
+	eglRef, err := swarm.ParseHexAddress(entry.Metadata()["encryptedglref"])
+	require.NoError(t, err)
+	grantees, err := c.Get(ctx, ls, &current.PublicKey, eglRef)
+	require.NoError(t, err)
+	assert.Equal(t, []*ecdsa.PublicKey{&grantee.PublicKey}, grantees)
+
+	_, err = c.Rekey(ctx, ls, rekeyedRef, &current.PublicKey)
+	assert.ErrorIs(t, err, accesscontrol.ErrNoPreviousKey)
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -173,6 +174,46 @@ func (h *HistoryStruct) lookupNode(ctx context.Context, searchedTimestamp int64)
 	return nil, nil
 }
 
+// HistoryEntry is an entry of the history together with its timestamp.
+type HistoryEntry struct {
+	manifest.Entry
+	Timestamp int64
+}
+
+// Entries returns all entries of the history, the latest first.
+func (h *HistoryStruct) Entries(ctx context.Context) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	walker := func(pathTimestamp []byte, currNode *mantaray.Node, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if currNode.IsValueType() && len(currNode.Entry()) > 0 && len(pathTimestamp) > 0 {
+			reversedTimestamp, err := bytesToInt64(pathTimestamp)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, HistoryEntry{
+				Entry:     manifest.NewEntry(swarm.NewAddress(currNode.Entry()), currNode.Metadata()),
+				Timestamp: math.MaxInt64 - reversedTimestamp,
+			})
+		}
+
+		return nil
+	}
+
+	rootNode := h.manifest.Root()
+	if err := rootNode.WalkNode(ctx, []byte{}, h.ls, walker); err != nil {
+		return nil, fmt.Errorf("history walk error: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Timestamp > entries[j].Timestamp
+	})
+
+	return entries, nil
+}
+
 // Store stores the history to the underlying storage and returns the reference.
 func (h *HistoryStruct) Store(ctx context.Context) (swarm.Address, error) {
 	return h.manifest.Store(ctx)
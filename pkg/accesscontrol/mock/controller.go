@@ -170,6 +170,13 @@ func (m *mockController) UpdateHandler(_ context.Context, ls file.LoadSaver, gls
 	return glRef, eglRef, historyRef, actref, nil
 }
 
+func (m *mockController) Rekey(_ context.Context, _ file.LoadSaver, historyRef swarm.Address, _ *ecdsa.PublicKey) (swarm.Address, error) {
+	if historyRef.Equal(swarm.EmptyAddress) {
+		return swarm.ZeroAddress, accesscontrol.ErrNotFound
+	}
+	return swarm.ParseHexAddress("67bdf80a9bbea8eca9c8480e43fdceb485d2d74d5708e45144b8c4adacd13d9c")
+}
+
 func (m *mockController) Get(ctx context.Context, ls file.LoadSaver, publisher *ecdsa.PublicKey, encryptedglref swarm.Address) ([]*ecdsa.PublicKey, error) {
 	if m.publisher == "" {
 		return nil, fmt.Errorf("granteelist not found")
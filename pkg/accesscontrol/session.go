@@ -25,6 +25,13 @@ type Session interface {
 	Key(publicKey *ecdsa.PublicKey, nonces [][]byte) ([][]byte, error)
 }
 
+// RotatedSession is the session of a previous version of a rotated key
+// together with the public key of that version.
+type RotatedSession struct {
+	Session
+	PublicKey *ecdsa.PublicKey
+}
+
 var _ Session = (*SessionStruct)(nil)
 
 // SessionStruct represents a session with an access control key.
@@ -64,3 +71,55 @@ func NewDefaultSession(key *ecdsa.PrivateKey) *SessionStruct {
 		key: key,
 	}
 }
+
+var _ Session = (*dhSession)(nil)
+
+// dhSession derives the keys using a shared key generation, which allows the
+// private key to be kept outside of the process, e.g. by an external signer.
+type dhSession struct {
+	dh crypto.DH
+}
+
+// NewDHSession creates a new session deriving the keys with the given shared key generation.
+// The derived keys are the same as of the default session for the same private key, except
+// when no nonces are given: then the shared secret is returned hashed, as the raw secret
+// never leaves the shared key generation.
+func NewDHSession(dh crypto.DH) Session {
+	return &dhSession{dh: dh}
+}
+
+// Key returns a derived key for each nonce.
+func (s *dhSession) Key(publicKey *ecdsa.PublicKey, nonces [][]byte) ([][]byte, error) {
+	if publicKey == nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	if len(nonces) == 0 {
+		key, err := s.dh.SharedKey(publicKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{key}, nil
+	}
+
+	// the keys for all nonces are generated at once when the shared key
+	// generation supports it, e.g. with a single request to an external signer
+	if b, ok := s.dh.(crypto.BatchDH); ok {
+		keys, err := b.SharedKeys(publicKey, nonces)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shared keys: %w", err)
+		}
+		return keys, nil
+	}
+
+	keys := make([][]byte, 0, len(nonces))
+	for _, nonce := range nonces {
+		key, err := s.dh.SharedKey(publicKey, nonce)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shared key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
@@ -9,6 +9,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"testing"
@@ -144,3 +145,68 @@ func TestSessionKeyFromKeystore(t *testing.T) {
 		assert.FailNowf(t, fmt.Sprintf("shared secrets do not match %s, %s", hex.EncodeToString(keys1[0]), hex.EncodeToString(keys2[0])), "")
 	}
 }
+
+func TestDHSessionKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	assertNoError(t, "key GenerateSecp256k1Key", err)
+	other, err := crypto.GenerateSecp256k1Key()
+	assertNoError(t, "other GenerateSecp256k1Key", err)
+
+	nonces := [][]byte{{1}, {2}}
+
+	want, err := accesscontrol.NewDefaultSession(key).Key(&other.PublicKey, nonces)
+	assertNoError(t, "default session key", err)
+	got, err := accesscontrol.NewDHSession(crypto.NewDH(key)).Key(&other.PublicKey, nonces)
+	assertNoError(t, "dh session key", err)
+
+	assert.Equal(t, want, got)
+
+	_, err = accesscontrol.NewDHSession(crypto.NewDH(key)).Key(nil, nonces)
+	assert.ErrorIs(t, err, accesscontrol.ErrInvalidPublicKey)
+}
+
+// batchDH generates the shared keys of all salts at once and fails the
+// generation of a single key.
+type batchDH struct {
+	dh    crypto.DH
+	calls int
+}
+
+func (b *batchDH) SharedKey(*ecdsa.PublicKey, []byte) ([]byte, error) {
+	return nil, errors.New("single shared key")
+}
+
+func (b *batchDH) SharedKeys(pub *ecdsa.PublicKey, salts [][]byte) ([][]byte, error) {
+	b.calls++
+	keys := make([][]byte, 0, len(salts))
+	for _, salt := range salts {
+		key, err := b.dh.SharedKey(pub, salt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func TestDHSessionBatchKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	assertNoError(t, "key GenerateSecp256k1Key", err)
+	other, err := crypto.GenerateSecp256k1Key()
+	assertNoError(t, "other GenerateSecp256k1Key", err)
+
+	nonces := [][]byte{{1}, {2}, {3}}
+
+	want, err := accesscontrol.NewDefaultSession(key).Key(&other.PublicKey, nonces)
+	assertNoError(t, "default session key", err)
+	dh := &batchDH{dh: crypto.NewDH(key)}
+	got, err := accesscontrol.NewDHSession(dh).Key(&other.PublicKey, nonces)
+	assertNoError(t, "dh session key", err)
+
+	assert.Equal(t, want, got)
+	assert.Equal(t, 1, dh.calls)
+}
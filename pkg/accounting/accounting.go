@@ -15,9 +15,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/pricing"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
@@ -168,6 +170,10 @@ type Accounting struct {
 	payFunction PayFunc
 	// function used for time settlement
 	refreshFunction RefreshFunc
+	// history of the accounting events, nil if disabled
+	ledger *ledger.Ledger
+	// recorder of the misbehaving peers, nil if disabled
+	reputation reputation.Recorder
 	// allowance based on time used in pseudo settle
 	refreshRate      *big.Int
 	lightRefreshRate *big.Int
@@ -360,6 +366,7 @@ func (c *creditAction) Apply() error {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
 
+	c.accounting.record(c.peer, ledger.KindCredit, c.price)
 	c.accounting.metrics.TotalCreditedAmount.Add(float64(c.price.Int64()))
 	c.accounting.metrics.CreditEventsCount.Inc()
 
@@ -1130,6 +1137,7 @@ func (a *Accounting) NotifyRefreshmentSent(peer swarm.Address, attemptedAmount,
 		// if refreshment failed with connected peer, blocklist
 		if !errors.Is(receivedError, p2p.ErrPeerNotFound) {
 			a.metrics.AccountingDisconnectsEnforceRefreshCount.Inc()
+			a.flag(peer)
 			_ = a.blocklist(peer, 1, "failed to refresh")
 		}
 		a.logger.Error(receivedError, "notifyrefreshmentsent failed to refresh")
@@ -1180,6 +1188,7 @@ func (a *Accounting) NotifyRefreshmentSent(peer swarm.Address, attemptedAmount,
 		a.logger.Error(err, "notifyrefreshmentsent failed to persist balance")
 		return
 	}
+	a.record(peer, ledger.KindRefreshmentSent, amount)
 
 	// update originated balance
 	err = a.decreaseOriginatedBalanceTo(peer, newBalance)
@@ -1220,6 +1229,7 @@ func (a *Accounting) NotifyRefreshmentReceived(peer swarm.Address, amount *big.I
 	if err != nil {
 		return fmt.Errorf("failed to persist balance: %w", err)
 	}
+	a.record(peer, ledger.KindRefreshmentReceived, amount)
 
 	accountingPeer.refreshReceivedTimestamp = timestamp
 
@@ -1346,6 +1356,7 @@ func (d *debitAction) Apply() error {
 	}
 
 	d.applied = true
+	a.record(d.peer, ledger.KindDebit, d.price)
 	d.accountingPeer.shadowReservedBalance = new(big.Int).Sub(d.accountingPeer.shadowReservedBalance, d.price)
 
 	tot, _ := big.NewFloat(0).SetInt(d.price).Float64()
@@ -1370,6 +1381,7 @@ func (d *debitAction) Apply() error {
 	if nextBalance.Cmp(disconnectLimit) >= 0 {
 		// peer too much in debt
 		a.metrics.AccountingDisconnectsOverdrawCount.Inc()
+		a.flag(d.peer)
 
 		disconnectFor, err := a.blocklistUntil(d.peer, 1)
 		if err != nil {
@@ -1396,6 +1408,7 @@ func (d *debitAction) Cleanup() {
 	d.accountingPeer.ghostBalance = new(big.Int).Add(d.accountingPeer.ghostBalance, d.price)
 	if d.accountingPeer.ghostBalance.Cmp(d.accountingPeer.disconnectLimit) > 0 {
 		a.metrics.AccountingDisconnectsGhostOverdrawCount.Inc()
+		a.flag(d.peer)
 		_ = a.blocklist(d.peer, 1, "ghost overdraw")
 	}
 }
@@ -1458,6 +1471,16 @@ func (a *Accounting) Connect(peer swarm.Address, fullNode bool) {
 	accountingPeer.thresholdGrowAt.Set(thresholdGrowStep)
 	accountingPeer.disconnectLimit.Set(disconnectLimit)
 
+	if a.ledger != nil {
+		compensated, err := a.CompensatedBalance(peer)
+		if err != nil && !errors.Is(err, ErrPeerNoBalance) {
+			a.logger.Error(err, "failed to load compensated balance")
+		}
+		if err == nil {
+			a.record(peer, ledger.KindReset, compensated)
+		}
+	}
+
 	err := a.store.Put(peerBalanceKey(peer), zero)
 	if err != nil {
 		a.logger.Error(err, "failed to persist balance")
@@ -1536,10 +1559,34 @@ func (a *Accounting) Disconnect(peer swarm.Address) {
 	}
 }
 
+// SetReputation makes the accounting flag the peers that overdraw their
+// debt or fail the refreshments.
+func (a *Accounting) SetReputation(r reputation.Recorder) {
+	a.reputation = r
+}
+
+func (a *Accounting) flag(peer swarm.Address) {
+	if a.reputation != nil {
+		a.reputation.Record(peer, reputation.SignalFlag)
+	}
+}
+
 func (a *Accounting) SetRefreshFunc(f RefreshFunc) {
 	a.refreshFunction = f
 }
 
+// SetLedger sets the ledger the accounting events are recorded in.
+func (a *Accounting) SetLedger(l *ledger.Ledger) {
+	a.ledger = l
+}
+
+// record records the accounting event in the ledger if it is enabled.
+func (a *Accounting) record(peer swarm.Address, kind ledger.Kind, amount *big.Int) {
+	if a.ledger != nil {
+		a.ledger.Record(peer, kind, amount, nil)
+	}
+}
+
 func (a *Accounting) SetPayFunc(f PayFunc) {
 	a.payFunction = f
 }
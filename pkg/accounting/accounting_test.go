@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/v2/pkg/p2p/mock"
@@ -111,6 +112,72 @@ func TestMutex(t *testing.T) {
 	})
 }
 
+// TestAccountingLedger verifies that the accounting events are recorded in the
+// ledger so that it reconciles with the balances.
+func TestAccountingLedger(t *testing.T) {
+	t.Parallel()
+
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc, err := accounting.NewAccounting(testPaymentThreshold, testPaymentTolerance, testPaymentEarly, log.Noop, store, &pricingMock{}, big.NewInt(testRefreshRate), testLightFactor, p2pmock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ledger.New(log.Noop, store, ledger.Options{})
+	defer l.Close()
+	acc.SetLedger(l)
+
+	peer := swarm.MustParseHexAddress("00112233")
+	acc.Connect(peer, true)
+
+	apply := func(action accounting.Action, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := action.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		action.Cleanup()
+	}
+	apply(acc.PrepareDebit(context.Background(), peer, 100))
+	apply(acc.PrepareCredit(context.Background(), peer, 40, true))
+	if err := acc.NotifyRefreshmentReceived(peer, big.NewInt(30), time.Now().Unix()); err != nil {
+		t.Fatal(err)
+	}
+	acc.Connect(peer, true)
+	apply(acc.PrepareDebit(context.Background(), peer, 10))
+
+	r, err := l.Reconcile(acc, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Peers != 1 || len(r.Discrepancies) != 0 {
+		t.Fatalf("got reconciliation %+v", r)
+	}
+
+	entries, err := l.Export(peer, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts := make(map[ledger.Kind]int64)
+	for _, e := range entries {
+		amounts[e.Kind] += e.Amount.Int64()
+	}
+	want := map[ledger.Kind]int64{
+		ledger.KindDebit:               110,
+		ledger.KindCredit:              40,
+		ledger.KindRefreshmentReceived: 30,
+		ledger.KindReset:               30,
+	}
+	for kind, amount := range want {
+		if amounts[kind] != amount {
+			t.Fatalf("got %s amount %d, want %d", kind, amounts[kind], amount)
+		}
+	}
+}
+
 // TestAccountingAddBalance does several accounting actions and verifies the balance after each steep
 func TestAccountingAddBalance(t *testing.T) {
 	t.Parallel()
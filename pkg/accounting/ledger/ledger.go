@@ -0,0 +1,657 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ledger keeps an append-only history of the accounting events of the
+// peers, compacts it into hourly rollups and reconciles it with the balances
+// and the settlements.
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "ledger"
+
+const (
+	entryKeyPrefix  = "accounting_ledger_entry_"
+	rollupKeyPrefix = "accounting_ledger_rollup_"
+	totalsKeyPrefix = "accounting_ledger_totals_"
+)
+
+// Kind is the kind of an accounting event.
+type Kind string
+
+const (
+	KindDebit               Kind = "debit"                // the peer used our services
+	KindCredit              Kind = "credit"               // we used the services of the peer
+	KindPaymentSent         Kind = "payment-sent"         // swap payment to the peer
+	KindPaymentReceived     Kind = "payment-received"     // swap payment from the peer
+	KindRefreshmentSent     Kind = "refreshment-sent"     // time based settlement to the peer
+	KindRefreshmentReceived Kind = "refreshment-received" // time based settlement from the peer
+	KindReset               Kind = "reset"                // the balance dropped when the peer reconnected
+)
+
+// balanceChange returns the change of the compensated balance of the peer
+// caused by an event.
+func balanceChange(kind Kind, amount *big.Int) *big.Int {
+	switch kind {
+	case KindDebit, KindPaymentSent, KindRefreshmentSent:
+		return new(big.Int).Set(amount)
+	default:
+		return new(big.Int).Neg(amount)
+	}
+}
+
+// Entry is a record of the ledger. The events of the same kind of a peer are
+// recorded as a single entry per flush interval, the entries older than the
+// retention period are compacted into hourly rollups.
+type Entry struct {
+	Peer   swarm.Address `json:"peer"`
+	Kind   Kind          `json:"kind"`
+	Amount *big.Int      `json:"amount"`          // in accounting units
+	Value  *big.Int      `json:"value,omitempty"` // in PLUR, only for the swap payments
+	Count  uint64        `json:"count"`
+	Time   time.Time     `json:"time"`
+	Rollup bool          `json:"rollup"` // the entry is the hourly rollup starting at its time
+}
+
+func (e *Entry) add(amount, value *big.Int, count uint64) {
+	e.Amount.Add(e.Amount, amount)
+	if value != nil {
+		if e.Value == nil {
+			e.Value = new(big.Int)
+		}
+		e.Value.Add(e.Value, value)
+	}
+	e.Count += count
+}
+
+// Totals are the sums of the ledger of a peer together with the values at the
+// time the peer was first seen by the ledger.
+type Totals struct {
+	OpeningBalance     *big.Int          `json:"openingBalance"`
+	OpeningSettlements map[Kind]*big.Int `json:"openingSettlements"`
+	Amounts            map[Kind]*big.Int `json:"amounts"`
+	Values             map[Kind]*big.Int `json:"values"`
+}
+
+func newTotals() *Totals {
+	return &Totals{
+		OpeningBalance:     new(big.Int),
+		OpeningSettlements: make(map[Kind]*big.Int),
+		Amounts:            make(map[Kind]*big.Int),
+		Values:             make(map[Kind]*big.Int),
+	}
+}
+
+// Balance returns the compensated balance of the peer according to the ledger.
+func (t *Totals) Balance() *big.Int {
+	balance := new(big.Int).Set(t.OpeningBalance)
+	for kind, amount := range t.Amounts {
+		balance.Add(balance, balanceChange(kind, amount))
+	}
+	return balance
+}
+
+// Options are the ledger options.
+type Options struct {
+	FlushInterval time.Duration // how long the events are aggregated in memory
+	Retention     time.Duration // how long the entries are kept before the compaction
+}
+
+// DefaultOptions returns the default ledger options.
+func DefaultOptions() Options {
+	return Options{
+		FlushInterval: 10 * time.Second,
+		Retention:     24 * time.Hour,
+	}
+}
+
+type pendingKey struct {
+	peer string
+	kind Kind
+}
+
+// Ledger records the accounting events of the peers.
+type Ledger struct {
+	logger  log.Logger
+	store   storage.StateStorer
+	options Options
+	timeNow func() time.Time
+
+	mu      sync.Mutex // guards pending
+	pending map[pendingKey]*Entry
+
+	storeMu sync.Mutex // serializes the writes to the store
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates the ledger and starts flushing and compacting it.
+func New(logger log.Logger, store storage.StateStorer, o Options) *Ledger {
+	l := &Ledger{
+		logger:  logger.WithName(loggerName).Register(),
+		store:   store,
+		options: o,
+		timeNow: time.Now,
+		pending: make(map[pendingKey]*Entry),
+		quit:    make(chan struct{}),
+	}
+	if o.FlushInterval > 0 {
+		l.wg.Add(1)
+		go l.manage()
+	}
+	return l
+}
+
+func (l *Ledger) manage() {
+	defer l.wg.Done()
+
+	flush := time.NewTicker(l.options.FlushInterval)
+	defer flush.Stop()
+	compact := time.NewTicker(time.Hour)
+	defer compact.Stop()
+
+	for {
+		select {
+		case <-l.quit:
+			return
+		case <-flush.C:
+			if err := l.Flush(); err != nil {
+				l.logger.Error(err, "flush ledger")
+			}
+		case <-compact.C:
+			if err := l.Compact(); err != nil {
+				l.logger.Error(err, "compact ledger")
+			}
+		}
+	}
+}
+
+// Record records an accounting event of the peer. The value is the amount of
+// the swap payments in PLUR and nil otherwise.
+func (l *Ledger) Record(peer swarm.Address, kind Kind, amount, value *big.Int) {
+	if amount == nil || amount.Sign() == 0 && value == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := pendingKey{peer: peer.ByteString(), kind: kind}
+	e, ok := l.pending[key]
+	if !ok {
+		e = &Entry{Peer: peer, Kind: kind, Amount: new(big.Int), Time: l.timeNow()}
+		l.pending[key] = e
+	}
+	e.add(amount, value, 1)
+}
+
+// Flush writes the recorded events to the store. The entries that could not
+// be written are kept for the next flush.
+func (l *Ledger) Flush() error {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = make(map[pendingKey]*Entry)
+	l.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	l.storeMu.Lock()
+	defer l.storeMu.Unlock()
+
+	for key, e := range pending {
+		if err := l.write(e); err != nil {
+			l.requeue(pending)
+			return err
+		}
+		delete(pending, key)
+	}
+	return nil
+}
+
+// write stores the entry and adds it to the totals of its peer. The key of
+// the entry does not change when it is requeued, so a retry overwrites the
+// entry that was stored without the totals.
+func (l *Ledger) write(e *Entry) error {
+	t, err := l.totals(e.Peer)
+	if err != nil {
+		return err
+	}
+	if err := l.store.Put(entryKey(e), e); err != nil {
+		return fmt.Errorf("put entry: %w", err)
+	}
+	addTo(t.Amounts, e.Kind, e.Amount)
+	if e.Value != nil {
+		addTo(t.Values, e.Kind, e.Value)
+	}
+	if err := l.store.Put(totalsKey(e.Peer), t); err != nil {
+		return fmt.Errorf("put totals: %w", err)
+	}
+	return nil
+}
+
+// requeue returns the entries that were not written to the pending ones. The
+// events recorded since the flush are merged into the requeued entries.
+func (l *Ledger) requeue(entries map[pendingKey]*Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, e := range entries {
+		if p, ok := l.pending[key]; ok {
+			e.add(p.Amount, p.Value, p.Count)
+		}
+		l.pending[key] = e
+	}
+}
+
+// Compact folds the entries older than the retention period into the hourly
+// rollups.
+func (l *Ledger) Compact() error {
+	cutoff := l.timeNow().Add(-l.options.Retention)
+
+	l.storeMu.Lock()
+	defer l.storeMu.Unlock()
+
+	var (
+		compacted []string
+		entries   []Entry
+	)
+	err := l.store.Iterate(entryKeyPrefix, func(key, value []byte) (bool, error) {
+		var e Entry
+		if err := json.Unmarshal(value, &e); err != nil {
+			return true, fmt.Errorf("unmarshal entry: %w", err)
+		}
+		if e.Time.Before(cutoff) {
+			compacted = append(compacted, string(key))
+			entries = append(entries, e)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rollups := make(map[string]*Entry)
+	for _, e := range entries {
+		r := &Entry{Peer: e.Peer, Kind: e.Kind, Time: e.Time.Truncate(time.Hour).UTC(), Rollup: true}
+		k := rollupKey(r)
+		if existing, ok := rollups[k]; ok {
+			r = existing
+		} else {
+			switch err := l.store.Get(k, r); {
+			case errors.Is(err, storage.ErrNotFound):
+				r.Amount = new(big.Int)
+			case err != nil:
+				return fmt.Errorf("get rollup: %w", err)
+			}
+			rollups[k] = r
+		}
+		r.add(e.Amount, e.Value, e.Count)
+	}
+
+	for k, r := range rollups {
+		if err := l.store.Put(k, r); err != nil {
+			return fmt.Errorf("put rollup: %w", err)
+		}
+	}
+	for _, k := range compacted {
+		if err := l.store.Delete(k); err != nil {
+			return fmt.Errorf("delete entry: %w", err)
+		}
+	}
+	if len(compacted) > 0 {
+		l.logger.Debug("ledger compacted", "entries", len(compacted), "rollups", len(rollups))
+	}
+	return nil
+}
+
+// Export returns the entries and the rollups of the peer, or of all peers if
+// the peer is the zero address, in the time range, sorted by time. The zero
+// time does not limit the range.
+func (l *Ledger) Export(peer swarm.Address, from, to time.Time) ([]Entry, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	l.storeMu.Lock()
+	defer l.storeMu.Unlock()
+
+	var entries []Entry
+	for _, prefix := range []string{entryKeyPrefix, rollupKeyPrefix} {
+		if !peer.IsZero() {
+			prefix += peer.String() + "_"
+		}
+		err := l.store.Iterate(prefix, func(_, value []byte) (bool, error) {
+			var e Entry
+			if err := json.Unmarshal(value, &e); err != nil {
+				return true, fmt.Errorf("unmarshal entry: %w", err)
+			}
+			if !from.IsZero() && e.Time.Before(from) || !to.IsZero() && !e.Time.Before(to) {
+				return false, nil
+			}
+			entries = append(entries, e)
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		return entries[i].Kind < entries[j].Kind
+	})
+	return entries, nil
+}
+
+// BalancePoint is the compensated balance of a peer at the end of an hour.
+type BalancePoint struct {
+	Time    time.Time `json:"time"`
+	Change  *big.Int  `json:"change"`
+	Balance *big.Int  `json:"balance"`
+}
+
+// Timeline returns the hourly balances of the peer in the time range for the
+// hours with recorded events.
+func (l *Ledger) Timeline(peer swarm.Address, from, to time.Time) ([]BalancePoint, error) {
+	entries, err := l.Export(peer, time.Time{}, to)
+	if err != nil {
+		return nil, err
+	}
+
+	l.storeMu.Lock()
+	t, err := l.totals(peer)
+	l.storeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		points  []BalancePoint
+		balance = new(big.Int).Set(t.OpeningBalance)
+	)
+	for _, e := range entries {
+		change := balanceChange(e.Kind, e.Amount)
+		balance.Add(balance, change)
+
+		hour := e.Time.Truncate(time.Hour).UTC()
+		if !from.IsZero() && hour.Before(from.Truncate(time.Hour)) {
+			continue
+		}
+		if n := len(points); n > 0 && points[n-1].Time.Equal(hour) {
+			points[n-1].Change.Add(points[n-1].Change, change)
+			points[n-1].Balance.Set(balance)
+			continue
+		}
+		points = append(points, BalancePoint{Time: hour, Change: change, Balance: new(big.Int).Set(balance)})
+	}
+	return points, nil
+}
+
+// BalanceSource provides the current balances of the peers.
+type BalanceSource interface {
+	CompensatedBalances() (map[string]*big.Int, error)
+}
+
+// SettlementSource provides the current settlement totals of the peers.
+type SettlementSource interface {
+	SettlementsSent() (map[string]*big.Int, error)
+	SettlementsReceived() (map[string]*big.Int, error)
+}
+
+// Open records the current balances and settlement totals of the peers that
+// are not yet known to the ledger, so that they can be reconciled later. The
+// payments or the refreshments may be nil if the settlement is disabled.
+func (l *Ledger) Open(balances BalanceSource, payments, refreshments SettlementSource) error {
+	current, err := l.current(balances, payments, refreshments)
+	if err != nil {
+		return err
+	}
+
+	l.storeMu.Lock()
+	defer l.storeMu.Unlock()
+
+	for peer, c := range current {
+		key := totalsKeyPrefix + peer
+		switch err := l.store.Get(key, new(Totals)); {
+		case err == nil:
+			continue
+		case !errors.Is(err, storage.ErrNotFound):
+			return fmt.Errorf("get totals: %w", err)
+		}
+
+		t := newTotals()
+		t.OpeningBalance.Set(c.balance)
+		for kind, amount := range c.settlements {
+			t.OpeningSettlements[kind] = new(big.Int).Set(amount)
+		}
+		if err := l.store.Put(key, t); err != nil {
+			return fmt.Errorf("put totals: %w", err)
+		}
+	}
+	return nil
+}
+
+// Discrepancy is a difference between the ledger and the current values.
+type Discrepancy struct {
+	Peer   swarm.Address `json:"peer"`
+	Field  string        `json:"field"` // balance or the kind of the settlement
+	Ledger *big.Int      `json:"ledger"`
+	Actual *big.Int      `json:"actual"`
+}
+
+// Reconciliation is the result of the comparison of the ledger with the
+// current balances and settlement totals.
+type Reconciliation struct {
+	Peers         int           `json:"peers"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Reconcile compares the totals of the ledger with the current balances and
+// settlement totals of the peers. The payments are compared by their values
+// as the swap settlements are in PLUR. The payments or the refreshments may be
+// nil if the settlement is disabled.
+func (l *Ledger) Reconcile(balances BalanceSource, payments, refreshments SettlementSource) (*Reconciliation, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	current, err := l.current(balances, payments, refreshments)
+	if err != nil {
+		return nil, err
+	}
+
+	l.storeMu.Lock()
+	totals := make(map[string]*Totals)
+	err = l.store.Iterate(totalsKeyPrefix, func(key, value []byte) (bool, error) {
+		t := newTotals()
+		if err := json.Unmarshal(value, t); err != nil {
+			return true, fmt.Errorf("unmarshal totals: %w", err)
+		}
+		totals[strings.TrimPrefix(string(key), totalsKeyPrefix)] = t
+		return false, nil
+	})
+	l.storeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]struct{})
+	for peer := range totals {
+		peers[peer] = struct{}{}
+	}
+	for peer := range current {
+		peers[peer] = struct{}{}
+	}
+
+	r := &Reconciliation{Peers: len(peers), Discrepancies: []Discrepancy{}}
+	for p := range peers {
+		peer, err := swarm.ParseHexAddress(p)
+		if err != nil {
+			return nil, fmt.Errorf("parse peer address: %w", err)
+		}
+		t, ok := totals[p]
+		if !ok {
+			t = newTotals()
+		}
+		c, ok := current[p]
+		if !ok {
+			c = newCurrentValues()
+		}
+
+		if b := t.Balance(); b.Cmp(c.balance) != 0 {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{Peer: peer, Field: "balance", Ledger: b, Actual: c.balance})
+		}
+		for _, kind := range []Kind{KindPaymentSent, KindPaymentReceived, KindRefreshmentSent, KindRefreshmentReceived} {
+			if kind == KindPaymentSent || kind == KindPaymentReceived {
+				if payments == nil {
+					continue
+				}
+			} else if refreshments == nil {
+				continue
+			}
+
+			recorded := t.Amounts[kind]
+			if kind == KindPaymentSent || kind == KindPaymentReceived {
+				recorded = t.Values[kind]
+			}
+			expected := new(big.Int)
+			if v := t.OpeningSettlements[kind]; v != nil {
+				expected.Add(expected, v)
+			}
+			if recorded != nil {
+				expected.Add(expected, recorded)
+			}
+			actual := c.settlements[kind]
+			if actual == nil {
+				actual = new(big.Int)
+			}
+			if expected.Cmp(actual) != 0 {
+				r.Discrepancies = append(r.Discrepancies, Discrepancy{Peer: peer, Field: string(kind), Ledger: expected, Actual: actual})
+			}
+		}
+	}
+
+	sort.Slice(r.Discrepancies, func(i, j int) bool {
+		if c := strings.Compare(r.Discrepancies[i].Peer.String(), r.Discrepancies[j].Peer.String()); c != 0 {
+			return c < 0
+		}
+		return r.Discrepancies[i].Field < r.Discrepancies[j].Field
+	})
+	return r, nil
+}
+
+// Close flushes the recorded events and stops the ledger.
+func (l *Ledger) Close() error {
+	close(l.quit)
+	l.wg.Wait()
+	return l.Flush()
+}
+
+type currentValues struct {
+	balance     *big.Int
+	settlements map[Kind]*big.Int
+}
+
+func newCurrentValues() *currentValues {
+	return &currentValues{balance: new(big.Int), settlements: make(map[Kind]*big.Int)}
+}
+
+// current collects the current balances and settlement totals by peer.
+func (l *Ledger) current(balances BalanceSource, payments, refreshments SettlementSource) (map[string]*currentValues, error) {
+	current := make(map[string]*currentValues)
+	get := func(peer string) *currentValues {
+		c, ok := current[peer]
+		if !ok {
+			c = newCurrentValues()
+			current[peer] = c
+		}
+		return c
+	}
+
+	bs, err := balances.CompensatedBalances()
+	if err != nil {
+		return nil, fmt.Errorf("balances: %w", err)
+	}
+	for peer, b := range bs {
+		get(peer).balance.Set(b)
+	}
+
+	for _, s := range []struct {
+		source         SettlementSource
+		sent, received Kind
+	}{
+		{payments, KindPaymentSent, KindPaymentReceived},
+		{refreshments, KindRefreshmentSent, KindRefreshmentReceived},
+	} {
+		if s.source == nil {
+			continue
+		}
+		sent, err := s.source.SettlementsSent()
+		if err != nil {
+			return nil, fmt.Errorf("settlements sent: %w", err)
+		}
+		for peer, v := range sent {
+			get(peer).settlements[s.sent] = v
+		}
+		received, err := s.source.SettlementsReceived()
+		if err != nil {
+			return nil, fmt.Errorf("settlements received: %w", err)
+		}
+		for peer, v := range received {
+			get(peer).settlements[s.received] = v
+		}
+	}
+	return current, nil
+}
+
+// totals returns the totals of the peer. The storeMu must be held when called.
+func (l *Ledger) totals(peer swarm.Address) (*Totals, error) {
+	t := newTotals()
+	switch err := l.store.Get(totalsKey(peer), t); {
+	case errors.Is(err, storage.ErrNotFound):
+		return newTotals(), nil
+	case err != nil:
+		return nil, fmt.Errorf("get totals: %w", err)
+	}
+	return t, nil
+}
+
+func addTo(m map[Kind]*big.Int, kind Kind, amount *big.Int) {
+	if v, ok := m[kind]; ok {
+		v.Add(v, amount)
+		return
+	}
+	m[kind] = new(big.Int).Set(amount)
+}
+
+func entryKey(e *Entry) string {
+	return fmt.Sprintf("%s%s_%020d_%s", entryKeyPrefix, e.Peer, e.Time.UnixNano(), e.Kind)
+}
+
+func rollupKey(r *Entry) string {
+	return fmt.Sprintf("%s%s_%020d_%s", rollupKeyPrefix, r.Peer, r.Time.Unix(), r.Kind)
+}
+
+func totalsKey(peer swarm.Address) string {
+	return totalsKeyPrefix + peer.String()
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ledger_test
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	peerA = swarm.MustParseHexAddress("aa")
+	peerB = swarm.MustParseHexAddress("bb")
+	start = time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+)
+
+func newTestLedger(t *testing.T, now *time.Time) *ledger.Ledger {
+	t.Helper()
+
+	l := ledger.New(log.Noop, statestore.NewStateStore(), ledger.Options{Retention: 24 * time.Hour})
+	l.SetTimeNow(func() time.Time { return *now })
+	t.Cleanup(func() {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return l
+}
+
+func TestExportAndCompact(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	l := newTestLedger(t, &now)
+
+	// the events of a peer within a flush interval make a single entry
+	l.Record(peerA, ledger.KindDebit, big.NewInt(10), nil)
+	l.Record(peerA, ledger.KindDebit, big.NewInt(5), nil)
+	l.Record(peerB, ledger.KindCredit, big.NewInt(7), nil)
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	now = start.Add(30 * time.Minute)
+	l.Record(peerA, ledger.KindPaymentReceived, big.NewInt(12), big.NewInt(1200))
+	now = start.Add(2 * time.Hour)
+	l.Record(peerA, ledger.KindDebit, big.NewInt(3), nil)
+
+	entries, err := l.Export(peerA, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want %d", len(entries), 3)
+	}
+	if e := entries[0]; e.Kind != ledger.KindDebit || e.Amount.Int64() != 15 || e.Count != 2 || e.Rollup {
+		t.Fatalf("got entry %+v", e)
+	}
+	if e := entries[1]; e.Kind != ledger.KindPaymentReceived || e.Value.Int64() != 1200 {
+		t.Fatalf("got entry %+v", e)
+	}
+
+	all, err := l.Export(swarm.ZeroAddress, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d entries of all peers in the range, want %d", len(all), 3)
+	}
+
+	// the entries of the first hour fall out of the retention period
+	now = start.Add(25 * time.Hour)
+	if err := l.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = l.Export(peerA, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries after the compaction, want %d", len(entries), 3)
+	}
+	hour := start.Truncate(time.Hour)
+	if e := entries[0]; !e.Rollup || !e.Time.Equal(hour) || e.Kind != ledger.KindDebit || e.Amount.Int64() != 15 {
+		t.Fatalf("got rollup %+v", e)
+	}
+	if e := entries[1]; !e.Rollup || !e.Time.Equal(hour) || e.Kind != ledger.KindPaymentReceived || e.Value.Int64() != 1200 {
+		t.Fatalf("got rollup %+v", e)
+	}
+	if e := entries[2]; e.Rollup || e.Amount.Int64() != 3 {
+		t.Fatalf("got entry %+v", e)
+	}
+
+	timeline, err := l.Timeline(peerA, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline) != 2 {
+		t.Fatalf("got %d timeline points, want %d", len(timeline), 2)
+	}
+	if p := timeline[0]; !p.Time.Equal(hour) || p.Change.Int64() != 3 || p.Balance.Int64() != 3 {
+		t.Fatalf("got point %+v", p)
+	}
+	if p := timeline[1]; p.Change.Int64() != 3 || p.Balance.Int64() != 6 {
+		t.Fatalf("got point %+v", p)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	l := newTestLedger(t, &now)
+
+	balances := testBalances{peerA.String(): big.NewInt(100)}
+	payments := &testSettlements{
+		sent:     map[string]*big.Int{peerA.String(): big.NewInt(5000)},
+		received: map[string]*big.Int{},
+	}
+	refreshments := &testSettlements{
+		sent:     map[string]*big.Int{},
+		received: map[string]*big.Int{peerA.String(): big.NewInt(40)},
+	}
+
+	if err := l.Open(balances, payments, refreshments); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Record(peerA, ledger.KindDebit, big.NewInt(50), nil)
+	l.Record(peerA, ledger.KindRefreshmentReceived, big.NewInt(20), nil)
+	l.Record(peerA, ledger.KindPaymentSent, big.NewInt(10), big.NewInt(1000))
+	l.Record(peerB, ledger.KindCredit, big.NewInt(30), nil)
+	balances[peerA.String()] = big.NewInt(140)
+	balances[peerB.String()] = big.NewInt(-30)
+	payments.sent[peerA.String()] = big.NewInt(6000)
+	refreshments.received[peerA.String()] = big.NewInt(60)
+
+	r, err := l.Reconcile(balances, payments, refreshments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Peers != 2 || len(r.Discrepancies) != 0 {
+		t.Fatalf("got reconciliation %+v", r)
+	}
+
+	// a debit that was not recorded in the ledger
+	balances[peerB.String()] = big.NewInt(-20)
+	r, err = l.Reconcile(balances, payments, refreshments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Discrepancies) != 1 {
+		t.Fatalf("got %d discrepancies, want %d", len(r.Discrepancies), 1)
+	}
+	if d := r.Discrepancies[0]; !d.Peer.Equal(peerB) || d.Field != "balance" || d.Ledger.Int64() != -30 || d.Actual.Int64() != -20 {
+		t.Fatalf("got discrepancy %+v", d)
+	}
+}
+
+// TestFlushRetry tests that the entries that could not be written are
+// written by the next flush, together with the events recorded since.
+func TestFlushRetry(t *testing.T) {
+	t.Parallel()
+
+	store := &failingStore{StateStorer: statestore.NewStateStore()}
+	l := ledger.New(log.Noop, store, ledger.Options{Retention: 24 * time.Hour})
+	l.SetTimeNow(func() time.Time { return start })
+	t.Cleanup(func() { _ = l.Close() })
+
+	l.Record(peerA, ledger.KindDebit, big.NewInt(10), nil)
+	store.fail.Store(true)
+	if err := l.Flush(); err == nil {
+		t.Fatal("expected flush error")
+	}
+	store.fail.Store(false)
+
+	l.Record(peerA, ledger.KindDebit, big.NewInt(5), nil)
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := l.Export(peerA, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Amount.Int64() != 15 || entries[0].Count != 2 {
+		t.Fatalf("got entries %+v", entries)
+	}
+
+	r, err := l.Reconcile(testBalances{peerA.String(): big.NewInt(15)}, &testSettlements{}, &testSettlements{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Discrepancies) != 0 {
+		t.Fatalf("got discrepancies %+v", r.Discrepancies)
+	}
+}
+
+// failingStore fails to store the totals of the peers when fail is set, after
+// the entry was stored.
+type failingStore struct {
+	storage.StateStorer
+	fail atomic.Bool
+}
+
+func (s *failingStore) Put(key string, i interface{}) error {
+	if s.fail.Load() && strings.HasPrefix(key, "accounting_ledger_totals_") {
+		return errors.New("put failed")
+	}
+	return s.StateStorer.Put(key, i)
+}
+
+type testBalances map[string]*big.Int
+
+func (b testBalances) CompensatedBalances() (map[string]*big.Int, error) {
+	return b, nil
+}
+
+type testSettlements struct {
+	sent, received map[string]*big.Int
+}
+
+func (s *testSettlements) SettlementsSent() (map[string]*big.Int, error) {
+	return s.sent, nil
+}
+
+func (s *testSettlements) SettlementsReceived() (map[string]*big.Int, error) {
+	return s.received, nil
+}
@@ -77,6 +77,12 @@ type GranteesPostResponse struct {
 	HistoryReference swarm.Address `json:"historyref"`
 }
 
+// GranteesRekeyResponse represents the response structure for re-keying a history.
+type GranteesRekeyResponse struct {
+	// HistoryReference represents the reference to the re-keyed history.
+	HistoryReference swarm.Address `json:"historyref"`
+}
+
 // GranteesPatch represents a structure for modifying the list of grantees.
 type GranteesPatch struct {
 	// Addlist is a list of ecdsa.PublicKeys to be added to a grantee list.
@@ -159,7 +165,7 @@ func (s *Service) actEncryptionHandler(
 	reference swarm.Address,
 	historyRootHash swarm.Address,
 ) (swarm.Address, error) {
-	publisherPublicKey := &s.publicKey
+	publisherPublicKey := &s.actPublicKey
 	ls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, false, redundancy.NONE))
 	storageReference, historyReference, encryptedReference, err := s.accesscontrol.UploadHandler(ctx, ls, reference, publisherPublicKey, historyRootHash)
 	if err != nil {
@@ -205,7 +211,7 @@ func (s *Service) actListGranteesHandler(w http.ResponseWriter, r *http.Request)
 	if headers.Cache != nil {
 		cache = *headers.Cache
 	}
-	publisher := &s.publicKey
+	publisher := &s.actPublicKey
 	ls := loadsave.NewReadonly(s.storer.Download(cache))
 	grantees, err := s.accesscontrol.Get(r.Context(), ls, publisher, paths.GranteesAddress)
 	if err != nil {
@@ -345,7 +351,7 @@ func (s *Service) actGrantRevokeHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	granteeref := paths.GranteesAddress
-	publisher := &s.publicKey
+	publisher := &s.actPublicKey
 	ls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, false, redundancy.NONE))
 	gls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, granteeListEncrypt, redundancy.NONE))
 	granteeref, encryptedglref, historyref, actref, err := s.accesscontrol.UpdateHandler(ctx, ls, gls, granteeref, historyAddress, publisher, grantees.Addlist, grantees.Revokelist)
@@ -499,7 +505,7 @@ func (s *Service) actCreateGranteesHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	publisher := &s.publicKey
+	publisher := &s.actPublicKey
 	ls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, false, redundancy.NONE))
 	gls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, granteeListEncrypt, redundancy.NONE))
 	granteeref, encryptedglref, historyref, actref, err := s.accesscontrol.UpdateHandler(ctx, ls, gls, swarm.ZeroAddress, historyAddress, publisher, list, nil)
@@ -563,3 +569,98 @@ func parseKeys(list []string) ([]*ecdsa.PublicKey, error) {
 
 	return parsedList, nil
 }
+
+// actRekeyHandler re-encrypts a history that was published with a previous
+// version of the rotated ACT key for the current key.
+func (s *Service) actRekeyHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("act_rekey_handler").Build()
+
+	headers := struct {
+		BatchID        []byte         `map:"Swarm-Postage-Batch-Id" validate:"required"`
+		SwarmTag       uint64         `map:"Swarm-Tag"`
+		Pin            bool           `map:"Swarm-Pin"`
+		Deferred       *bool          `map:"Swarm-Deferred-Upload"`
+		HistoryAddress *swarm.Address `map:"Swarm-Act-History-Address" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.Header, &headers); response != nil {
+		response("invalid header params", logger, w)
+		return
+	}
+
+	var (
+		tag      uint64
+		err      error
+		deferred = defaultUploadMethod(headers.Deferred)
+	)
+
+	if deferred || headers.Pin {
+		tag, err = s.getOrCreateSessionID(headers.SwarmTag)
+		if err != nil {
+			logger.Debug("get or create tag failed", "error", err)
+			logger.Error(nil, "get or create tag failed")
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				jsonhttp.NotFound(w, "tag not found")
+			default:
+				jsonhttp.InternalServerError(w, "cannot get or create tag")
+			}
+			return
+		}
+	}
+
+	ctx := r.Context()
+	putter, err := s.newStamperPutter(ctx, putterOptions{
+		BatchID:  headers.BatchID,
+		TagID:    tag,
+		Pin:      headers.Pin,
+		Deferred: deferred,
+	})
+	if err != nil {
+		logger.Debug("putter failed", "error", err)
+		logger.Error(nil, "putter failed")
+		switch {
+		case errors.Is(err, errBatchUnusable) || errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.UnprocessableEntity(w, "batch not usable yet or does not exist")
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.NotFound(w, "batch with id not found")
+		case errors.Is(err, errInvalidPostageBatch):
+			jsonhttp.BadRequest(w, "invalid batch id")
+		case errors.Is(err, errUnsupportedDevNodeOperation):
+			jsonhttp.BadRequest(w, errUnsupportedDevNodeOperation)
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	publisher := &s.actPublicKey
+	ls := loadsave.New(s.storer.Download(true), s.storer.Cache(), requestPipelineFactory(ctx, putter, false, redundancy.NONE))
+	historyref, err := s.accesscontrol.Rekey(ctx, ls, *headers.HistoryAddress, publisher)
+	if err != nil {
+		logger.Debug("failed to rekey history", "error", err)
+		logger.Error(nil, "failed to rekey history")
+		switch {
+		case errors.Is(err, accesscontrol.ErrNotFound):
+			jsonhttp.NotFound(w, "act or history entry not found")
+		case errors.Is(err, accesscontrol.ErrNoPreviousKey):
+			jsonhttp.BadRequest(w, "history is not published with a previous key")
+		case errors.Is(err, accesscontrol.ErrUnexpectedType):
+			jsonhttp.BadRequest(w, "failed to load history")
+		default:
+			jsonhttp.InternalServerError(w, "failed to rekey history")
+		}
+		return
+	}
+
+	err = putter.Done(historyref)
+	if err != nil {
+		logger.Debug("done split history failed", "error", err)
+		logger.Error(nil, "done split history failed")
+		jsonhttp.InternalServerError(w, "done split history failed")
+		return
+	}
+
+	jsonhttp.OK(w, GranteesRekeyResponse{
+		HistoryReference: historyref,
+	})
+}
@@ -991,6 +991,25 @@ func TestAccessLogicGrantees(t *testing.T) {
 			jsonhttptest.WithJSONRequestBody(body),
 		)
 	})
+	t.Run("rekey-history", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/grantee/rekey", http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.SwarmActHistoryAddressHeader, addr.String()),
+			jsonhttptest.WithExpectedJSONResponse(api.GranteesRekeyResponse{
+				HistoryReference: swarm.MustParseHexAddress("67bdf80a9bbea8eca9c8480e43fdceb485d2d74d5708e45144b8c4adacd13d9c"),
+			}),
+		)
+	})
+	t.Run("rekey-wrong-history", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/grantee/rekey", http.StatusNotFound,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.SwarmActHistoryAddressHeader, swarm.EmptyAddress.String()),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "act or history entry not found",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
 
 	t.Run("create-granteelist", func(t *testing.T) {
 		body := api.GranteesPostRequest{
@@ -28,11 +28,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
 	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
+	"github.com/ethersphere/bee/v2/pkg/crawler"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/feeds"
 	"github.com/ethersphere/bee/v2/pkg/file/pipeline"
 	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
@@ -40,11 +43,13 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
 	"github.com/ethersphere/bee/v2/pkg/resolver"
 	"github.com/ethersphere/bee/v2/pkg/resolver/client/ens"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
 	"github.com/ethersphere/bee/v2/pkg/settlement"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/erc20"
 	"github.com/ethersphere/bee/v2/pkg/status"
@@ -54,10 +59,12 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
 	storer "github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/ethersphere/bee/v2/pkg/topology"
 	"github.com/ethersphere/bee/v2/pkg/topology/lightnode"
 	"github.com/ethersphere/bee/v2/pkg/tracing"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/hashicorp/go-multierror"
@@ -89,6 +96,8 @@ const (
 	SwarmActTimestampHeader           = "Swarm-Act-Timestamp"
 	SwarmActPublisherHeader           = "Swarm-Act-Publisher"
 	SwarmActHistoryAddressHeader      = "Swarm-Act-History-Address"
+	SwarmTenantHeader                 = "Swarm-Tenant"
+	SwarmApprovalTokenHeader          = "Swarm-Approval-Token"
 
 	ImmutableHeader = "Immutable"
 	GasPriceHeader  = "Gas-Price"
@@ -148,6 +157,9 @@ type Service struct {
 	storer          Storer
 	resolver        resolver.Interface
 	pss             pss.Interface
+	pssMailbox      *mailbox.Service
+	crawler         *crawler.Crawler
+	gsoc            gsoc.Listener
 	steward         steward.Interface
 	logger          log.Logger
 	loggerV1        log.Logger
@@ -160,6 +172,7 @@ type Service struct {
 	probe           *Probe
 	metricsRegistry *prometheus.Registry
 	stakingContract staking.Contract
+	stakingWatcher  *staking.Watcher
 	Options
 
 	http.Handler
@@ -173,6 +186,9 @@ type Service struct {
 	overlay           *swarm.Address
 	publicKey         ecdsa.PublicKey
 	pssPublicKey      ecdsa.PublicKey
+	actPublicKey      ecdsa.PublicKey
+	pssKeyVersion     uint32
+	actKeyVersion     uint32
 	ethereumAddress   common.Address
 	chequebookEnabled bool
 	swapEnabled       bool
@@ -180,6 +196,7 @@ type Service struct {
 	topologyDriver topology.Driver
 	p2p            p2p.DebugService
 	accounting     accounting.Interface
+	ledger         *ledger.Ledger
 	chequebook     chequebook.Service
 	pseudosettle   settlement.Interface
 	pingpong       pingpong.Interface
@@ -191,6 +208,7 @@ type Service struct {
 	syncStatus func() (bool, error)
 
 	swap        swap.Interface
+	cashouts    autocashout.Interface
 	transaction transaction.Service
 	lightNodes  *lightnode.Container
 	blockTime   time.Duration
@@ -206,6 +224,7 @@ type Service struct {
 	chainID      int64
 
 	whitelistedWithdrawalAddress []common.Address
+	wallet                       *wallet.Service
 
 	preMapHooks map[string]func(v string) (string, error)
 	validate    *validator.Validate
@@ -213,6 +232,8 @@ type Service struct {
 	redistributionAgent *storageincentives.Agent
 
 	statusService *status.Service
+
+	tenants *tenant.Service
 }
 
 func (s *Service) SetP2P(p2p p2p.DebugService) {
@@ -227,6 +248,16 @@ func (s *Service) SetSwarmAddress(addr *swarm.Address) {
 	}
 }
 
+// SetRotatedKeys sets the current ACT publisher key and the versions of
+// the rotated pss and ACT keys.
+func (s *Service) SetRotatedKeys(actPublicKey ecdsa.PublicKey, actKeyVersion, pssKeyVersion uint32) {
+	if s != nil {
+		s.actPublicKey = actPublicKey
+		s.actKeyVersion = actKeyVersion
+		s.pssKeyVersion = pssKeyVersion
+	}
+}
+
 func (s *Service) SetRedistributionAgent(redistributionAgent *storageincentives.Agent) {
 	if s != nil {
 		s.redistributionAgent = redistributionAgent
@@ -243,22 +274,30 @@ type ExtraOptions struct {
 	TopologyDriver  topology.Driver
 	LightNodes      *lightnode.Container
 	Accounting      accounting.Interface
+	Ledger          *ledger.Ledger
 	Pseudosettle    settlement.Interface
 	Swap            swap.Interface
+	Cashouts        autocashout.Interface
 	Chequebook      chequebook.Service
 	BlockTime       time.Duration
 	Storer          Storer
 	Resolver        resolver.Interface
 	Pss             pss.Interface
+	PssMailbox      *mailbox.Service
+	Crawler         *crawler.Crawler
+	Gsoc            gsoc.Listener
 	FeedFactory     feeds.Factory
 	Post            postage.Service
 	AccessControl   accesscontrol.Controller
 	PostageContract postagecontract.Interface
 	Staking         staking.Contract
+	StakingWatcher  *staking.Watcher
 	Steward         steward.Interface
 	SyncStatus      func() (bool, error)
 	NodeStatus      *status.Service
 	PinIntegrity    PinIntegrity
+	Tenants         *tenant.Service
+	Wallet          *wallet.Service
 }
 
 func New(
@@ -285,6 +324,7 @@ func New(
 	s.swapEnabled = swapEnabled
 	s.publicKey = publicKey
 	s.pssPublicKey = pssPublicKey
+	s.actPublicKey = publicKey
 	s.ethereumAddress = ethereumAddress
 	s.transaction = transaction
 	s.batchStore = batchStore
@@ -333,18 +373,24 @@ func (s *Service) Configure(signer crypto.Signer, tracer *tracing.Tracer, o Opti
 	s.storer = e.Storer
 	s.resolver = e.Resolver
 	s.pss = e.Pss
+	s.pssMailbox = e.PssMailbox
+	s.crawler = e.Crawler
+	s.gsoc = e.Gsoc
 	s.feedFactory = e.FeedFactory
 	s.post = e.Post
 	s.accesscontrol = e.AccessControl
 	s.postageContract = e.PostageContract
 	s.steward = e.Steward
 	s.stakingContract = e.Staking
+	s.stakingWatcher = e.StakingWatcher
 
 	s.pingpong = e.Pingpong
 	s.topologyDriver = e.TopologyDriver
 	s.accounting = e.Accounting
+	s.ledger = e.Ledger
 	s.chequebook = e.Chequebook
 	s.swap = e.Swap
+	s.cashouts = e.Cashouts
 	s.lightNodes = e.LightNodes
 	s.pseudosettle = e.Pseudosettle
 	s.blockTime = e.BlockTime
@@ -372,6 +418,8 @@ func (s *Service) Configure(signer crypto.Signer, tracer *tracing.Tracer, o Opti
 	}
 
 	s.pinIntegrity = e.PinIntegrity
+	s.tenants = e.Tenants
+	s.wallet = e.Wallet
 }
 
 func (s *Service) SetProbe(probe *Probe) {
@@ -520,7 +568,7 @@ func (s *Service) corsHandler(h http.Handler) http.Handler {
 	allowedHeaders := []string{
 		"User-Agent", "Accept", "X-Requested-With", "Access-Control-Request-Headers", "Access-Control-Request-Method", "Accept-Ranges", "Content-Encoding",
 		AuthorizationHeader, AcceptEncodingHeader, ContentTypeHeader, ContentDispositionHeader, RangeHeader, OriginHeader,
-		SwarmTagHeader, SwarmPinHeader, SwarmEncryptHeader, SwarmIndexDocumentHeader, SwarmErrorDocumentHeader, SwarmCollectionHeader, SwarmPostageBatchIdHeader, SwarmPostageStampHeader, SwarmDeferredUploadHeader, SwarmRedundancyLevelHeader, SwarmRedundancyStrategyHeader, SwarmRedundancyFallbackModeHeader, SwarmChunkRetrievalTimeoutHeader, SwarmLookAheadBufferSizeHeader, SwarmFeedIndexHeader, SwarmFeedIndexNextHeader, GasPriceHeader, GasLimitHeader, ImmutableHeader,
+		SwarmTagHeader, SwarmPinHeader, SwarmEncryptHeader, SwarmIndexDocumentHeader, SwarmErrorDocumentHeader, SwarmCollectionHeader, SwarmPostageBatchIdHeader, SwarmPostageStampHeader, SwarmDeferredUploadHeader, SwarmRedundancyLevelHeader, SwarmRedundancyStrategyHeader, SwarmRedundancyFallbackModeHeader, SwarmChunkRetrievalTimeoutHeader, SwarmLookAheadBufferSizeHeader, SwarmFeedIndexHeader, SwarmFeedIndexNextHeader, SwarmTenantHeader, SwarmApprovalTokenHeader, GasPriceHeader, GasLimitHeader, ImmutableHeader,
 	}
 	allowedHeadersStr := strings.Join(allowedHeaders, ", ")
 
@@ -675,6 +723,10 @@ type putterSessionWrapper struct {
 	storer.PutterSession
 	stamper postage.Stamper
 	save    func() error
+	tenant  string
+	tenants *tenant.Service
+	usage   *tenant.Session
+	pin     bool
 }
 
 func (p *putterSessionWrapper) Put(ctx context.Context, chunk swarm.Chunk) error {
@@ -682,11 +734,27 @@ func (p *putterSessionWrapper) Put(ctx context.Context, chunk swarm.Chunk) error
 	if err != nil {
 		return err
 	}
-	return p.PutterSession.Put(ctx, chunk.WithStamp(stamp))
+	if err := p.PutterSession.Put(ctx, chunk.WithStamp(stamp)); err != nil {
+		return err
+	}
+	if p.usage != nil {
+		p.usage.RecordChunk(stamp.BatchID(), chunk.Address())
+	}
+	return nil
 }
 
 func (p *putterSessionWrapper) Done(ref swarm.Address) error {
-	return errors.Join(p.PutterSession.Done(ref), p.save())
+	if err := p.PutterSession.Done(ref); err != nil {
+		return errors.Join(err, p.save())
+	}
+	var errTenant error
+	if p.tenants != nil {
+		errTenant = p.usage.Commit()
+		if p.pin {
+			errTenant = errors.Join(errTenant, p.tenants.RecordPin(p.tenant, ref))
+		}
+	}
+	return errors.Join(p.save(), errTenant)
 }
 
 func (p *putterSessionWrapper) Cleanup() error {
@@ -732,10 +800,19 @@ func (s *Service) newStamperPutter(ctx context.Context, opts putterOptions) (sto
 		return nil, fmt.Errorf("failed creating session: %w", err)
 	}
 
+	label := tenant.GetFromContext(ctx)
+	if err := s.recordTenantTag(label, opts.TagID); err != nil {
+		return nil, fmt.Errorf("tenant record tag: %w", err)
+	}
+
 	return &putterSessionWrapper{
 		PutterSession: session,
 		stamper:       stamper,
 		save:          save,
+		tenant:        label,
+		tenants:       s.tenants,
+		usage:         s.tenantSession(label),
+		pin:           opts.Pin,
 	}, nil
 }
 
@@ -761,10 +838,19 @@ func (s *Service) newStampedPutter(ctx context.Context, opts putterOptions, stam
 
 	stamper := postage.NewPresignedStamper(stamp, storedBatch.Owner)
 
+	label := tenant.GetFromContext(ctx)
+	if err := s.recordTenantTag(label, opts.TagID); err != nil {
+		return nil, fmt.Errorf("tenant record tag: %w", err)
+	}
+
 	return &putterSessionWrapper{
 		PutterSession: session,
 		stamper:       stamper,
 		save:          func() error { return nil },
+		tenant:        label,
+		tenants:       s.tenants,
+		usage:         s.tenantSession(label),
+		pin:           opts.Pin,
 	}, nil
 }
 
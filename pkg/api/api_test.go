@@ -25,13 +25,16 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
 	mockac "github.com/ethersphere/bee/v2/pkg/accesscontrol/mock"
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
 	accountingmock "github.com/ethersphere/bee/v2/pkg/accounting/mock"
 	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/crawler"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/feeds"
 	"github.com/ethersphere/bee/v2/pkg/file/pipeline"
 	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
 	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	p2pmock "github.com/ethersphere/bee/v2/pkg/p2p/mock"
@@ -42,10 +45,12 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
 	contractMock "github.com/ethersphere/bee/v2/pkg/postage/postagecontract/mock"
 	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
 	"github.com/ethersphere/bee/v2/pkg/pusher"
 	"github.com/ethersphere/bee/v2/pkg/resolver"
 	resolverMock "github.com/ethersphere/bee/v2/pkg/resolver/mock"
 	"github.com/ethersphere/bee/v2/pkg/settlement/pseudosettle"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	chequebookmock "github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook/mock"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/erc20"
 	erc20mock "github.com/ethersphere/bee/v2/pkg/settlement/swap/erc20/mock"
@@ -63,6 +68,7 @@ import (
 	mock2 "github.com/ethersphere/bee/v2/pkg/storageincentives/staking/mock"
 	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/ethersphere/bee/v2/pkg/topology/lightnode"
 	topologymock "github.com/ethersphere/bee/v2/pkg/topology/mock"
 	"github.com/ethersphere/bee/v2/pkg/tracing"
@@ -70,6 +76,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
 	transactionmock "github.com/ethersphere/bee/v2/pkg/transaction/mock"
 	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/gorilla/websocket"
 	"resenje.org/web"
 )
@@ -93,6 +100,9 @@ type testServerOptions struct {
 	StateStorer        storage.StateStorer
 	Resolver           resolver.Interface
 	Pss                pss.Interface
+	PssMailbox         *mailbox.Service
+	Crawler            *crawler.Crawler
+	Gsoc               gsoc.Listener
 	WsPath             string
 	WsPingPeriod       time.Duration
 	Logger             log.Logger
@@ -101,6 +111,7 @@ type testServerOptions struct {
 	CORSAllowedOrigins []string
 	PostageContract    postagecontract.Interface
 	StakingContract    staking.Contract
+	StakingWatcher     *staking.Watcher
 	Post               postage.Service
 	AccessControl      accesscontrol.Controller
 	Steward            steward.Interface
@@ -117,8 +128,10 @@ type testServerOptions struct {
 	Pingpong        pingpong.Interface
 	TopologyOpts    []topologymock.Option
 	AccountingOpts  []accountingmock.Option
+	Ledger          *ledger.Ledger
 	ChequebookOpts  []chequebookmock.Option
 	SwapOpts        []swapmock.Option
+	Cashouts        autocashout.Interface
 	TransactionOpts []transactionmock.Option
 
 	BatchStore postage.Storer
@@ -131,6 +144,8 @@ type testServerOptions struct {
 	NodeStatus          *status.Service
 	PinIntegrity        api.PinIntegrity
 	WhitelistedAddr     string
+	Tenants             *tenant.Service
+	Wallet              *wallet.Service
 }
 
 func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.Conn, string, *chanStorer) {
@@ -182,15 +197,20 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 	var extraOpts = api.ExtraOptions{
 		TopologyDriver:  topologyDriver,
 		Accounting:      acc,
+		Ledger:          o.Ledger,
 		Pseudosettle:    recipient,
 		LightNodes:      ln,
 		Swap:            settlement,
+		Cashouts:        o.Cashouts,
 		Chequebook:      chequebook,
 		Pingpong:        o.Pingpong,
 		BlockTime:       o.BlockTime,
 		Storer:          o.Storer,
 		Resolver:        o.Resolver,
 		Pss:             o.Pss,
+		PssMailbox:      o.PssMailbox,
+		Crawler:         o.Crawler,
+		Gsoc:            o.Gsoc,
 		FeedFactory:     o.Feeds,
 		Post:            o.Post,
 		AccessControl:   o.AccessControl,
@@ -198,8 +218,11 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 		Steward:         o.Steward,
 		SyncStatus:      o.SyncStatus,
 		Staking:         o.StakingContract,
+		StakingWatcher:  o.StakingWatcher,
 		NodeStatus:      o.NodeStatus,
 		PinIntegrity:    o.PinIntegrity,
+		Tenants:         o.Tenants,
+		Wallet:          o.Wallet,
 	}
 
 	// By default bee mode is set to full mode.
@@ -0,0 +1,100 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+type censusPeer struct {
+	Overlay       swarm.Address `json:"overlay"`
+	Underlays     []string      `json:"underlays"`
+	UserAgent     string        `json:"userAgent"`
+	Version       string        `json:"version"`
+	FullNode      bool          `json:"fullNode"`
+	Reachable     bool          `json:"reachable"`
+	StorageRadius uint8         `json:"storageRadius"`
+	Latency       string        `json:"latency"`
+	Online        bool          `json:"online"`
+	FirstSeen     time.Time     `json:"firstSeen"`
+	LastSeen      time.Time     `json:"lastSeen"`
+	LastVisit     time.Time     `json:"lastVisit"`
+	Failures      int           `json:"failures"`
+}
+
+type censusSummary struct {
+	Peers           int            `json:"peers"`
+	Online          int            `json:"online"`
+	FullNodes       int            `json:"fullNodes"`
+	LightNodes      int            `json:"lightNodes"`
+	Reachable       int            `json:"reachable"`
+	Versions        map[string]int `json:"versions"`
+	StorageRadiuses map[uint8]int  `json:"storageRadiuses"`
+}
+
+type censusResponse struct {
+	Summary censusSummary `json:"summary"`
+	Peers   []censusPeer  `json:"peers"`
+}
+
+// censusHandler returns the census of the network kept by the crawler
+// of the bootnode, optionally limited to the online peers.
+func (s *Service) censusHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_census").Build()
+
+	queries := struct {
+		Online bool `map:"online"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	if s.crawler == nil {
+		jsonhttp.ServiceUnavailable(w, "census available only in bootnode mode")
+		return
+	}
+
+	census := s.crawler.Census()
+
+	resp := censusResponse{
+		Summary: censusSummary{
+			Peers:           census.Summary.Peers,
+			Online:          census.Summary.Online,
+			FullNodes:       census.Summary.FullNodes,
+			LightNodes:      census.Summary.LightNodes,
+			Reachable:       census.Summary.Reachable,
+			Versions:        census.Summary.Versions,
+			StorageRadiuses: census.Summary.StorageRadiuses,
+		},
+		Peers: make([]censusPeer, 0, len(census.Peers)),
+	}
+	for _, p := range census.Peers {
+		if queries.Online && !p.Online {
+			continue
+		}
+		resp.Peers = append(resp.Peers, censusPeer{
+			Overlay:       p.Overlay,
+			Underlays:     p.Underlays,
+			UserAgent:     p.UserAgent,
+			Version:       p.Version,
+			FullNode:      p.FullNode,
+			Reachable:     p.Reachable,
+			StorageRadius: p.StorageRadius,
+			Latency:       p.Latency.String(),
+			Online:        p.Online,
+			FirstSeen:     p.FirstSeen,
+			LastSeen:      p.LastSeen,
+			LastVisit:     p.LastVisit,
+			Failures:      p.Failures,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/crawler"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	pingpongmock "github.com/ethersphere/bee/v2/pkg/pingpong/mock"
+	statestoremock "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/status"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestCensus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{})
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/census", http.StatusServiceUnavailable,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusServiceUnavailable,
+				Message: "census available only in bootnode mode",
+			}),
+		)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		peer := swarm.RandAddress(t)
+		c := crawler.New(
+			addressbook.New(statestoremock.NewStateStore()),
+			censusNetwork{peers: []p2p.Peer{{Address: peer, FullNode: true}}},
+			nil,
+			pingpongmock.New(func(context.Context, swarm.Address, ...string) (time.Duration, error) {
+				return time.Millisecond, nil
+			}),
+			censusStatus{},
+			log.Noop,
+			crawler.Options{},
+		)
+		c.Start()
+		t.Cleanup(func() { _ = c.Close() })
+
+		deadline := time.Now().Add(5 * time.Second)
+		for len(c.Census().Peers) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("census not populated")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{Crawler: c})
+
+		var body []byte
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/census?online=true", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+		)
+
+		var resp struct {
+			Summary struct {
+				Peers           int            `json:"peers"`
+				Online          int            `json:"online"`
+				FullNodes       int            `json:"fullNodes"`
+				StorageRadiuses map[string]int `json:"storageRadiuses"`
+			} `json:"summary"`
+			Peers []struct {
+				Overlay       swarm.Address `json:"overlay"`
+				StorageRadius uint8         `json:"storageRadius"`
+				Online        bool          `json:"online"`
+			} `json:"peers"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Summary.Peers != 1 || resp.Summary.Online != 1 || resp.Summary.FullNodes != 1 || resp.Summary.StorageRadiuses["8"] != 1 {
+			t.Fatalf("unexpected summary %+v", resp.Summary)
+		}
+		if len(resp.Peers) != 1 || !resp.Peers[0].Overlay.Equal(peer) || !resp.Peers[0].Online || resp.Peers[0].StorageRadius != 8 {
+			t.Fatalf("unexpected peers %+v", resp.Peers)
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		t.Parallel()
+
+		testServer, _, _, _ := newTestServer(t, testServerOptions{})
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/census?online=maybe", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "online",
+						Error: "invalid syntax",
+					},
+				},
+			}),
+		)
+	})
+}
+
+type censusNetwork struct {
+	peers []p2p.Peer
+}
+
+func (n censusNetwork) Connect(context.Context, ma.Multiaddr) (*bzz.Address, error) {
+	return nil, p2p.ErrPeerNotFound
+}
+
+func (n censusNetwork) Disconnect(swarm.Address, string) error { return nil }
+
+func (n censusNetwork) Peers() []p2p.Peer { return n.peers }
+
+type censusStatus struct{}
+
+func (censusStatus) PeerSnapshot(context.Context, swarm.Address) (*status.Snapshot, error) {
+	return &status.Snapshot{BeeMode: "full", StorageRadius: 8}, nil
+}
@@ -8,15 +8,19 @@ import (
 	"errors"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
 
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/gorilla/mux"
 )
 
@@ -32,6 +36,7 @@ const (
 	errCannotCashStatus            = "cannot get cashout status"
 	errNoCashout                   = "no prior cashout"
 	errNoCheque                    = "no prior cheque"
+	errCannotCashoutHistory        = "cannot get cashout history"
 )
 
 type chequebookBalanceResponse struct {
@@ -241,7 +246,17 @@ func (s *Service) swapCashoutHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer s.cashOutChequeSem.Release(1)
 
-	txHash, err := s.swap.CashCheque(r.Context(), paths.Peer)
+	cashCheque := s.swap.CashCheque
+	if s.cashouts != nil {
+		cashCheque = s.cashouts.CashCheque
+	}
+	txHash, err := cashCheque(r.Context(), paths.Peer)
+	if errors.Is(err, autocashout.ErrCashoutInProgress) {
+		logger.Debug("cash cheque failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "cash cheque failed", "peer_address", paths.Peer)
+		jsonhttp.TooManyRequests(w, "cashout of the peer in progress")
+		return
+	}
 	if errors.Is(err, postagecontract.ErrChainDisabled) {
 		logger.Debug("cash cheque failed", "peer_address", paths.Peer, "error", err)
 		logger.Error(nil, "cash cheque failed", "peer_address", paths.Peer)
@@ -337,6 +352,74 @@ func (s *Service) swapCashoutStatusHandler(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+type swapCashoutRecord struct {
+	Peer            swarm.Address  `json:"peer"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	Amount          *bigint.BigInt `json:"amount"`
+	Trigger         string         `json:"trigger"`
+	GasPrice        *bigint.BigInt `json:"gasPrice"`
+	Fee             *bigint.BigInt `json:"fee"`
+	Status          string         `json:"status"`
+	Created         time.Time      `json:"created"`
+}
+
+type swapCashoutsResponse struct {
+	Automatic   bool                `json:"automatic"`
+	Paused      bool                `json:"paused"`
+	PauseReason string              `json:"pauseReason,omitempty"`
+	Eligible    []swarm.Address     `json:"eligible"`
+	BatchDue    *time.Time          `json:"batchDue,omitempty"`
+	Cashouts    []swapCashoutRecord `json:"cashouts"`
+}
+
+func (s *Service) swapCashoutsHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_chequebook_cashouts").Build()
+
+	response := swapCashoutsResponse{
+		Eligible: []swarm.Address{},
+		Cashouts: []swapCashoutRecord{},
+	}
+	if s.cashouts == nil {
+		jsonhttp.OK(w, response)
+		return
+	}
+
+	history, err := s.cashouts.History()
+	if err != nil {
+		logger.Debug("get cashout history failed", "error", err)
+		logger.Error(nil, "get cashout history failed")
+		jsonhttp.InternalServerError(w, errCannotCashoutHistory)
+		return
+	}
+
+	status := s.cashouts.Status()
+	response.Automatic = status.Automatic
+	response.Paused = status.Paused
+	response.PauseReason = status.PauseReason
+	response.Eligible = append(response.Eligible, status.Eligible...)
+	if !status.BatchDue.IsZero() {
+		response.BatchDue = &status.BatchDue
+	}
+	for _, c := range history {
+		var fee *bigint.BigInt
+		if c.Fee != nil {
+			fee = bigint.Wrap(c.Fee)
+		}
+		response.Cashouts = append(response.Cashouts, swapCashoutRecord{
+			Peer:            c.Peer,
+			TransactionHash: c.TxHash,
+			Amount:          bigint.Wrap(c.Amount),
+			Trigger:         string(c.Trigger),
+			GasPrice:        bigint.Wrap(c.GasPrice),
+			Fee:             fee,
+			Status:          c.Status,
+			Created:         c.Created,
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
 type chequebookTxResponse struct {
 	TransactionHash common.Hash `json:"transactionHash"`
 }
@@ -352,21 +435,46 @@ func (s *Service) chequebookWithdrawHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	txHash, err := s.chequebook.Withdraw(r.Context(), queries.Amount)
+	if s.wallet == nil {
+		txHash, err := s.chequebookWithdraw(w, r, logger, queries.Amount)
+		if err != nil {
+			return
+		}
+		jsonhttp.OK(w, chequebookTxResponse{TransactionHash: txHash})
+		return
+	}
+
+	// the chequebook withdrawals go to the node wallet and count against
+	// the daily cap of BZZ
+	to := wallet.Address{Name: "node", Address: s.ethereumAddress}
+	wd, ok := s.requestWithdrawal(w, logger, wallet.KindChequebookWithdraw, wallet.CoinBZZ, to, queries.Amount)
+	if !ok {
+		return
+	}
+	if wd.Status == wallet.StatusPending {
+		jsonhttp.Accepted(w, newWalletWithdrawalResponse(wd))
+		return
+	}
+	s.sendWithdrawal(w, r, logger, wd)
+}
+
+// chequebookWithdraw withdraws the amount from the chequebook to the node
+// wallet. The failures are responded to and returned.
+func (s *Service) chequebookWithdraw(w http.ResponseWriter, r *http.Request, logger log.Logger, amount *big.Int) (common.Hash, error) {
+	txHash, err := s.chequebook.Withdraw(r.Context(), amount)
 	if errors.Is(err, chequebook.ErrInsufficientFunds) {
 		logger.Debug("withdraw failed", "error", err)
 		logger.Error(nil, "withdraw failed")
 		jsonhttp.BadRequest(w, errChequebookInsufficientFunds)
-		return
+		return common.Hash{}, err
 	}
 	if err != nil {
 		logger.Debug("withdraw failed", "error", err)
 		logger.Error(nil, "withdraw failed")
 		jsonhttp.InternalServerError(w, errChequebookNoWithdraw)
-		return
+		return common.Hash{}, err
 	}
-
-	jsonhttp.OK(w, chequebookTxResponse{TransactionHash: txHash})
+	return txHash, nil
 }
 
 func (s *Service) chequebookDepositHandler(w http.ResponseWriter, r *http.Request) {
@@ -394,5 +502,6 @@ func (s *Service) chequebookDepositHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	s.recordTransfer(logger, wallet.KindChequebookDeposit, s.chequebook.Address(), queries.Amount, txHash, "")
 	jsonhttp.OK(w, chequebookTxResponse{TransactionHash: txHash})
 }
@@ -17,10 +17,15 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook/mock"
 	swapmock "github.com/ethersphere/bee/v2/pkg/settlement/swap/mock"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
 
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
@@ -796,3 +801,49 @@ func LastChequesEqual(a, b *api.ChequebookLastChequesResponse) bool {
 
 	return true
 }
+
+func TestChequebookCashouts(t *testing.T) {
+	t.Parallel()
+
+	addr := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	txHash := common.HexToHash("0xffff")
+
+	swap := swapmock.New(
+		swapmock.WithCashoutStatusFunc(func(context.Context, swarm.Address) (*chequebook.CashoutStatus, error) {
+			return &chequebook.CashoutStatus{UncashedAmount: big.NewInt(100)}, nil
+		}),
+		swapmock.WithCashChequeFunc(func(context.Context, swarm.Address) (common.Hash, error) {
+			return txHash, nil
+		}),
+	)
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	cashouts := autocashout.New(log.Noop, statestore.NewStateStore(), swap, backendmock.New(), common.Address{}, o)
+	testutil.CleanupCloser(t, cashouts)
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		Cashouts: cashouts,
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodPost, "/chequebook/cashout/"+addr.String(), http.StatusOK,
+		jsonhttptest.WithRequestHeader(api.GasPriceHeader, "10000"),
+		jsonhttptest.WithExpectedJSONResponse(api.SwapCashoutResponse{TransactionHash: txHash.String()}),
+	)
+
+	var got api.SwapCashoutsResponse
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/chequebook/cashouts", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&got),
+	)
+
+	if got.Automatic || got.Paused || len(got.Eligible) != 0 || got.BatchDue != nil {
+		t.Fatalf("got status %+v", got)
+	}
+	if len(got.Cashouts) != 1 {
+		t.Fatalf("got %d cashouts, want %d", len(got.Cashouts), 1)
+	}
+	c := got.Cashouts[0]
+	if !c.Peer.Equal(addr) || c.TransactionHash != txHash || c.Trigger != "manual" || c.Status != "pending" ||
+		c.Amount.Cmp(big.NewInt(100)) != 0 || c.GasPrice.Cmp(big.NewInt(10000)) != 0 || c.Fee != nil {
+		t.Fatalf("got cashout %+v", c)
+	}
+}
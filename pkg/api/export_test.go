@@ -10,14 +10,24 @@ import (
 )
 
 type (
-	BytesPostResponse     = bytesPostResponse
-	ChunkAddressResponse  = chunkAddressResponse
-	SocPostResponse       = socPostResponse
-	FeedReferenceResponse = feedReferenceResponse
-	BzzUploadResponse     = bzzUploadResponse
-	TagRequest            = tagRequest
-	ListTagsResponse      = listTagsResponse
-	IsRetrievableResponse = isRetrievableResponse
+	BytesPostResponse      = bytesPostResponse
+	ChunkAddressResponse   = chunkAddressResponse
+	SocPostResponse        = socPostResponse
+	FeedReferenceResponse  = feedReferenceResponse
+	BzzUploadResponse      = bzzUploadResponse
+	TagRequest             = tagRequest
+	ListTagsResponse       = listTagsResponse
+	IsRetrievableResponse  = isRetrievableResponse
+	RepairResponse         = repairResponse
+	RepairNodeResponse     = repairNodeResponse
+	TenantUsageResponse    = tenantUsageResponse
+	TenantsResponse        = tenantsResponse
+	ReverseResolveResponse = reverseResolveResponse
+	PssSendResponse        = pssSendResponse
+	PssMailboxResponse     = pssMailboxResponse
+	PssMailboxMessage      = pssMailboxMessage
+	TrafficResponse        = trafficResponse
+	ProtocolTraffic        = protocolTrafficResponse
 )
 
 var (
@@ -70,6 +80,9 @@ type (
 	BalancesResponse                  = balancesResponse
 	PeerDataResponse                  = peerDataResponse
 	PeerData                          = peerData
+	LedgerResponse                    = ledgerResponse
+	LedgerTimelineResponse            = ledgerTimelineResponse
+	LedgerReconciliationResponse      = ledgerReconciliationResponse
 	BalanceResponse                   = balanceResponse
 	SettlementResponse                = settlementResponse
 	SettlementsResponse               = settlementsResponse
@@ -79,11 +92,21 @@ type (
 	ChequebookLastChequesResponse     = chequebookLastChequesResponse
 	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
 	ChequebookTxResponse              = chequebookTxResponse
+	WalletAddressResponse             = walletAddressResponse
+	WalletAddressesResponse           = walletAddressesResponse
+	WalletWithdrawalResponse          = walletWithdrawalResponse
+	WalletWithdrawalsResponse         = walletWithdrawalsResponse
+	WalletAuditResponse               = walletAuditResponse
 	SwapCashoutResponse               = swapCashoutResponse
 	SwapCashoutStatusResponse         = swapCashoutStatusResponse
 	SwapCashoutStatusResult           = swapCashoutStatusResult
+	SwapCashoutsResponse              = swapCashoutsResponse
+	SwapCashoutRecord                 = swapCashoutRecord
+	StakeHistoryResponse              = stakeHistoryResponse
+	StakeHistoryEvent                 = stakeHistoryEvent
 	TransactionInfo                   = transactionInfo
 	TransactionPendingList            = transactionPendingList
+	QueuedTransactionInfo             = queuedTransactionInfo
 	TransactionHashResponse           = transactionHashResponse
 	TagResponse                       = tagResponse
 	ReserveStateResponse              = reserveStateResponse
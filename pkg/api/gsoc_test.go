@@ -0,0 +1,85 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	soctesting "github.com/ethersphere/bee/v2/pkg/soc/testing"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+func TestGsocWebsocketSingleHandler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		payload  = []byte("graffiti")
+		sch      = soctesting.GenerateMockSOC(t, payload)
+		listener = gsoc.New(log.Noop)
+		respC    = make(chan error, 1)
+	)
+	testutil.CleanupCloser(t, listener)
+
+	_, cl, _, _ := newTestServer(t, testServerOptions{
+		Gsoc:         listener,
+		WsPath:       "/gsoc/subscribe/" + sch.Address().String(),
+		Storer:       mockstorer.New(),
+		Logger:       log.Noop,
+		WsPingPeriod: 10 * time.Second,
+	})
+
+	if err := cl.SetReadDeadline(time.Now().Add(longTimeout)); err != nil {
+		t.Fatal(err)
+	}
+	cl.SetReadLimit(swarm.ChunkSize)
+
+	// the subscription is registered asynchronously after the upgrade
+	s, err := soc.FromChunk(sch.Chunk())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for i := 0; i < 10; i++ {
+			listener.Handle(s)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	go expectMessage(t, cl, respC, payload)
+	if err := <-respC; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGsocWebsocketInvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		Gsoc: gsoc.New(log.Noop),
+	})
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/gsoc/subscribe/123G", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid path params",
+			Reasons: []jsonhttp.Reason{
+				{
+					Field: "address",
+					Error: api.HexInvalidByteError('G').Error(),
+				},
+			},
+		}),
+	)
+}
@@ -0,0 +1,226 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
+	"github.com/ethersphere/bee/v2/pkg/bigint"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	errLedgerDisabled  = "accounting ledger disabled"
+	errLedgerExport    = "cannot export accounting ledger"
+	errLedgerTimeline  = "cannot get balance timeline"
+	errLedgerReconcile = "cannot reconcile accounting ledger"
+)
+
+type ledgerEntryResponse struct {
+	Peer   swarm.Address  `json:"peer"`
+	Kind   string         `json:"kind"`
+	Amount *bigint.BigInt `json:"amount"`
+	Value  *bigint.BigInt `json:"value,omitempty"`
+	Count  uint64         `json:"count"`
+	Time   time.Time      `json:"time"`
+	Rollup bool           `json:"rollup"`
+}
+
+type ledgerResponse struct {
+	Entries []ledgerEntryResponse `json:"entries"`
+}
+
+type ledgerBalancePointResponse struct {
+	Time    time.Time      `json:"time"`
+	Change  *bigint.BigInt `json:"change"`
+	Balance *bigint.BigInt `json:"balance"`
+}
+
+type ledgerTimelineResponse struct {
+	Peer     swarm.Address                `json:"peer"`
+	Timeline []ledgerBalancePointResponse `json:"timeline"`
+}
+
+type ledgerDiscrepancyResponse struct {
+	Peer   swarm.Address  `json:"peer"`
+	Field  string         `json:"field"`
+	Ledger *bigint.BigInt `json:"ledger"`
+	Actual *bigint.BigInt `json:"actual"`
+}
+
+type ledgerReconciliationResponse struct {
+	Peers         int                         `json:"peers"`
+	Discrepancies []ledgerDiscrepancyResponse `json:"discrepancies"`
+}
+
+// ledgerTimeRange returns the time range of the from and to unix timestamps,
+// where zero does not limit the range.
+func ledgerTimeRange(from, to int64) (time.Time, time.Time) {
+	var f, t time.Time
+	if from > 0 {
+		f = time.Unix(from, 0)
+	}
+	if to > 0 {
+		t = time.Unix(to, 0)
+	}
+	return f, t
+}
+
+func (s *Service) ledgerExportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_accounting_ledger").Build()
+
+	queries := struct {
+		Peer   swarm.Address `map:"peer"`
+		From   int64         `map:"from"`
+		To     int64         `map:"to"`
+		Format string        `map:"format" validate:"omitempty,oneof=json csv"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	if s.ledger == nil {
+		jsonhttp.ServiceUnavailable(w, errLedgerDisabled)
+		return
+	}
+
+	from, to := ledgerTimeRange(queries.From, queries.To)
+	entries, err := s.ledger.Export(queries.Peer, from, to)
+	if err != nil {
+		logger.Debug("export failed", "error", err)
+		logger.Error(nil, "export failed")
+		jsonhttp.InternalServerError(w, errLedgerExport)
+		return
+	}
+
+	if queries.Format == "csv" {
+		w.Header().Set(ContentTypeHeader, "text/csv")
+		w.Header().Set(ContentDispositionHeader, `attachment; filename="ledger.csv"`)
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"time", "peer", "kind", "amount", "value", "count", "rollup"})
+		for _, e := range entries {
+			value := ""
+			if e.Value != nil {
+				value = e.Value.String()
+			}
+			_ = cw.Write([]string{
+				e.Time.UTC().Format(time.RFC3339Nano),
+				e.Peer.String(),
+				string(e.Kind),
+				e.Amount.String(),
+				value,
+				strconv.FormatUint(e.Count, 10),
+				strconv.FormatBool(e.Rollup),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logger.Debug("write csv failed", "error", err)
+		}
+		return
+	}
+
+	resp := ledgerResponse{Entries: make([]ledgerEntryResponse, 0, len(entries))}
+	for _, e := range entries {
+		var value *bigint.BigInt
+		if e.Value != nil {
+			value = bigint.Wrap(e.Value)
+		}
+		resp.Entries = append(resp.Entries, ledgerEntryResponse{
+			Peer:   e.Peer,
+			Kind:   string(e.Kind),
+			Amount: bigint.Wrap(e.Amount),
+			Value:  value,
+			Count:  e.Count,
+			Time:   e.Time,
+			Rollup: e.Rollup,
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) ledgerTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_accounting_ledger_timeline").Build()
+
+	paths := struct {
+		Peer swarm.Address `map:"peer" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		From int64 `map:"from"`
+		To   int64 `map:"to"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	if s.ledger == nil {
+		jsonhttp.ServiceUnavailable(w, errLedgerDisabled)
+		return
+	}
+
+	from, to := ledgerTimeRange(queries.From, queries.To)
+	points, err := s.ledger.Timeline(paths.Peer, from, to)
+	if err != nil {
+		logger.Debug("timeline failed", "peer_address", paths.Peer, "error", err)
+		logger.Error(nil, "timeline failed", "peer_address", paths.Peer)
+		jsonhttp.InternalServerError(w, errLedgerTimeline)
+		return
+	}
+
+	resp := ledgerTimelineResponse{Peer: paths.Peer, Timeline: make([]ledgerBalancePointResponse, 0, len(points))}
+	for _, p := range points {
+		resp.Timeline = append(resp.Timeline, ledgerBalancePointResponse{
+			Time:    p.Time,
+			Change:  bigint.Wrap(p.Change),
+			Balance: bigint.Wrap(p.Balance),
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) ledgerReconciliationHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_accounting_ledger_reconciliation").Build()
+
+	if s.ledger == nil {
+		jsonhttp.ServiceUnavailable(w, errLedgerDisabled)
+		return
+	}
+
+	var payments ledger.SettlementSource
+	if s.swapEnabled {
+		payments = s.swap
+	}
+	r, err := s.ledger.Reconcile(s.accounting, payments, s.pseudosettle)
+	if err != nil {
+		logger.Debug("reconciliation failed", "error", err)
+		logger.Error(nil, "reconciliation failed")
+		jsonhttp.InternalServerError(w, errLedgerReconcile)
+		return
+	}
+
+	resp := ledgerReconciliationResponse{Peers: r.Peers, Discrepancies: make([]ledgerDiscrepancyResponse, 0, len(r.Discrepancies))}
+	for _, d := range r.Discrepancies {
+		resp.Discrepancies = append(resp.Discrepancies, ledgerDiscrepancyResponse{
+			Peer:   d.Peer,
+			Field:  d.Field,
+			Ledger: bigint.Wrap(d.Ledger),
+			Actual: bigint.Wrap(d.Actual),
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
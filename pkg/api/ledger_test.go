@@ -0,0 +1,142 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
+	accountingmock "github.com/ethersphere/bee/v2/pkg/accounting/mock"
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	swapmock "github.com/ethersphere/bee/v2/pkg/settlement/swap/mock"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestAccountingLedger(t *testing.T) {
+	t.Parallel()
+
+	peer := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+
+	l := ledger.New(log.Noop, statestore.NewStateStore(), ledger.Options{})
+	t.Cleanup(func() { _ = l.Close() })
+	l.Record(peer, ledger.KindDebit, big.NewInt(100), nil)
+	l.Record(peer, ledger.KindPaymentReceived, big.NewInt(40), big.NewInt(4000))
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		Ledger: l,
+		AccountingOpts: []accountingmock.Option{
+			accountingmock.WithCompensatedBalancesFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{peer.String(): big.NewInt(60)}, nil
+			}),
+		},
+		SwapOpts: []swapmock.Option{
+			swapmock.WithSettlementsSentFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{}, nil
+			}),
+			swapmock.WithSettlementsRecvFunc(func() (map[string]*big.Int, error) {
+				return map[string]*big.Int{peer.String(): big.NewInt(5000)}, nil
+			}),
+		},
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		var got api.LedgerResponse
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger?peer="+peer.String(), http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&got),
+		)
+		if len(got.Entries) != 2 {
+			t.Fatalf("got %d entries, want %d", len(got.Entries), 2)
+		}
+		for _, e := range got.Entries {
+			if !e.Peer.Equal(peer) || e.Count != 1 {
+				t.Fatalf("got entry %+v", e)
+			}
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+
+		var body []byte
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger?format=csv", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&body),
+			jsonhttptest.WithExpectedResponseHeader(api.ContentTypeHeader, "text/csv"),
+		)
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[0][2] != "kind" {
+			t.Fatalf("got csv records %v", records)
+		}
+		for _, r := range records[1:] {
+			if r[1] != peer.String() || (r[2] == string(ledger.KindPaymentReceived)) != (r[4] == "4000") {
+				t.Fatalf("got csv record %v", r)
+			}
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		t.Parallel()
+
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger?format=xml", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "format",
+						Error: "want oneof:json csv",
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("timeline", func(t *testing.T) {
+		t.Parallel()
+
+		var got api.LedgerTimelineResponse
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger/"+peer.String()+"/timeline", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&got),
+		)
+		if len(got.Timeline) != 1 || got.Timeline[0].Balance.Cmp(big.NewInt(60)) != 0 {
+			t.Fatalf("got timeline %+v", got.Timeline)
+		}
+	})
+
+	t.Run("reconciliation", func(t *testing.T) {
+		t.Parallel()
+
+		var got api.LedgerReconciliationResponse
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger/reconciliation", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&got),
+		)
+		if got.Peers != 1 || len(got.Discrepancies) != 1 {
+			t.Fatalf("got reconciliation %+v", got)
+		}
+		if d := got.Discrepancies[0]; d.Field != string(ledger.KindPaymentReceived) || d.Ledger.Cmp(big.NewInt(4000)) != 0 || d.Actual.Cmp(big.NewInt(5000)) != 0 {
+			t.Fatalf("got discrepancy %+v", d)
+		}
+	})
+}
+
+func TestAccountingLedgerDisabled(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/accounting/ledger", http.StatusServiceUnavailable)
+}
@@ -16,11 +16,14 @@ import (
 )
 
 type addressesResponse struct {
-	Overlay      *swarm.Address        `json:"overlay"`
-	Underlay     []multiaddr.Multiaddr `json:"underlay"`
-	Ethereum     common.Address        `json:"ethereum"`
-	PublicKey    string                `json:"publicKey"`
-	PSSPublicKey string                `json:"pssPublicKey"`
+	Overlay             *swarm.Address        `json:"overlay"`
+	Underlay            []multiaddr.Multiaddr `json:"underlay"`
+	Ethereum            common.Address        `json:"ethereum"`
+	PublicKey           string                `json:"publicKey"`
+	PSSPublicKey        string                `json:"pssPublicKey"`
+	PSSPublicKeyVersion uint32                `json:"pssPublicKeyVersion"`
+	ACTPublicKey        string                `json:"actPublicKey"`
+	ACTPublicKeyVersion uint32                `json:"actPublicKeyVersion"`
 }
 
 func (s *Service) addressesHandler(w http.ResponseWriter, _ *http.Request) {
@@ -40,10 +43,13 @@ func (s *Service) addressesHandler(w http.ResponseWriter, _ *http.Request) {
 		underlay = u
 	}
 	jsonhttp.OK(w, addressesResponse{
-		Overlay:      s.overlay,
-		Underlay:     underlay,
-		Ethereum:     s.ethereumAddress,
-		PublicKey:    hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.publicKey)),
-		PSSPublicKey: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.pssPublicKey)),
+		Overlay:             s.overlay,
+		Underlay:            underlay,
+		Ethereum:            s.ethereumAddress,
+		PublicKey:           hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.publicKey)),
+		PSSPublicKey:        hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.pssPublicKey)),
+		PSSPublicKeyVersion: s.pssKeyVersion,
+		ACTPublicKey:        hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.actPublicKey)),
+		ACTPublicKeyVersion: s.actKeyVersion,
 	})
 }
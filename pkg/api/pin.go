@@ -14,6 +14,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	storer "github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/ethersphere/bee/v2/pkg/traversal"
 	"github.com/gorilla/mux"
 	"golang.org/x/sync/semaphore"
@@ -114,6 +115,13 @@ func (s *Service) pinRootHash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.recordTenantPin(tenant.GetFromContext(r.Context()), paths.Reference); err != nil {
+		logger.Debug("record tenant pin failed", "chunk_address", paths.Reference, "error", err)
+		logger.Error(nil, "record tenant pin failed")
+		jsonhttp.InternalServerError(w, "pin collection failed")
+		return
+	}
+
 	jsonhttp.Created(w, nil)
 }
 
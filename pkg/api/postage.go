@@ -19,6 +19,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/tracing"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/gorilla/mux"
 )
 
@@ -132,6 +133,7 @@ func (s *Service) postageCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.recordTransfer(logger, wallet.KindPostagePurchase, s.postageContract.Address(), batchTotal(paths.Amount, paths.Depth), txHash, hex.EncodeToString(batchID))
 	jsonhttp.Created(w, &postageCreateResponse{
 		BatchID: batchID,
 		TxHash:  txHash.String(),
@@ -483,12 +485,27 @@ func (s *Service) postageTopUpHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.wallet != nil {
+		total := paths.Amount
+		if batch, err := s.batchStore.Get(paths.BatchID); err == nil {
+			total = batchTotal(paths.Amount, batch.Depth)
+		} else {
+			logger.Debug("topup batch: batch depth unknown, the audit log records the amount per chunk", "batch_id", hexBatchID, "error", err)
+		}
+		s.recordTransfer(logger, wallet.KindPostageTopUp, s.postageContract.Address(), total, txHash, hexBatchID)
+	}
 	jsonhttp.Accepted(w, &postageCreateResponse{
 		BatchID: paths.BatchID,
 		TxHash:  txHash.String(),
 	})
 }
 
+// batchTotal returns the total amount paid for the batch of the depth with
+// the amount per chunk.
+func batchTotal(amount *big.Int, depth uint8) *big.Int {
+	return new(big.Int).Lsh(amount, uint(depth))
+}
+
 func (s *Service) postageDiluteHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("patch_stamp_dilute").Build()
 
@@ -24,10 +24,15 @@ import (
 )
 
 const (
-	writeDeadline   = 4 * time.Second // write deadline. should be smaller than the shutdown timeout on api close
-	targetMaxLength = 3               // max target length in bytes, in order to prevent grieving by excess computation
+	writeDeadline     = 4 * time.Second     // write deadline. should be smaller than the shutdown timeout on api close
+	targetMaxLength   = pss.MaxTargetLength // max target length in bytes, in order to prevent grieving by excess computation
+	defaultAckTimeout = 30 * time.Second    // time to wait for the acknowledgement of a message if the request does not set it
 )
 
+type pssSendResponse struct {
+	MessageID string `json:"messageId"`
+}
+
 func (s *Service) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("post_pss_send").Build()
 
@@ -54,12 +59,31 @@ func (s *Service) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	queries := struct {
-		Recipient *ecdsa.PublicKey `map:"recipient,omitempty"`
+		Recipient  *ecdsa.PublicKey `map:"recipient,omitempty"`
+		Ack        bool             `map:"ack"`
+		AckTimeout string           `map:"ackTimeout"`
 	}{}
 	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
 		response("invalid query params", logger, w)
 		return
 	}
+	ackTimeout := defaultAckTimeout
+	if queries.AckTimeout != "" {
+		d, err := time.ParseDuration(queries.AckTimeout)
+		if err != nil || d <= 0 {
+			logger.Debug("invalid ack timeout", "ack_timeout", queries.AckTimeout, "error", err)
+			jsonhttp.BadRequest(w, jsonhttp.StatusResponse{
+				Message: "invalid query params",
+				Code:    http.StatusBadRequest,
+				Reasons: []jsonhttp.Reason{{
+					Field: "ackTimeout",
+					Error: "invalid duration",
+				}},
+			})
+			return
+		}
+		ackTimeout = d
+	}
 	if queries.Recipient == nil {
 		queries.Recipient = &(crypto.Secp256k1PrivateKeyFromBytes(topic[:])).PublicKey
 	}
@@ -96,8 +120,26 @@ func (s *Service) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 
 	stamper := postage.NewStamper(s.stamperStore, i, s.signer)
 
-	err = s.pss.Send(r.Context(), topic, payload, stamper, queries.Recipient, targets)
-	if err != nil {
+	if queries.Ack && (s.overlay == nil || len(s.overlay.Bytes()) < len(targets[0])) {
+		logger.Debug("acknowledgement requested without overlay address")
+		jsonhttp.ServiceUnavailable(w, "pss acknowledgements unavailable")
+		return
+	}
+
+	var id pss.MessageID
+	if queries.Ack {
+		// the acknowledgement is addressed to the neighbourhood of this node
+		// with the targets of the same length as the ones of the message
+		replyTo := pss.ReplyTo{
+			PublicKey: &s.pssPublicKey,
+			Targets:   pss.Targets{pss.Target(s.overlay.Bytes()[:len(targets[0])])},
+		}
+		id, err = s.pss.SendAndWait(r.Context(), topic, payload, stamper, queries.Recipient, targets, replyTo, ackTimeout)
+	} else {
+		err = s.pss.Send(r.Context(), topic, payload, stamper, queries.Recipient, targets)
+	}
+	ackTimedOut := errors.Is(err, pss.ErrAckTimeout)
+	if err != nil && !ackTimedOut {
 		logger.Debug("send payload failed", "topic", paths.Topic, "error", err)
 		logger.Error(nil, "send payload failed")
 		switch {
@@ -116,7 +158,16 @@ func (s *Service) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonhttp.Created(w, nil)
+	if !queries.Ack {
+		jsonhttp.Created(w, nil)
+		return
+	}
+	if ackTimedOut {
+		logger.Debug("acknowledgement timeout", "topic", paths.Topic, "message_id", id)
+		jsonhttp.GatewayTimeout(w, "pss acknowledgement timeout")
+		return
+	}
+	jsonhttp.Created(w, pssSendResponse{MessageID: id.String()})
 }
 
 func (s *Service) pssWsHandler(w http.ResponseWriter, r *http.Request) {
@@ -145,16 +196,20 @@ func (s *Service) pssWsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.wsWg.Add(1)
-	go s.pumpWs(conn, paths.Topic)
+	go s.pumpWs(conn, "pss", func(deliver func(context.Context, []byte)) func() {
+		return s.pss.Register(pss.NewTopic(paths.Topic), deliver)
+	})
 }
 
-func (s *Service) pumpWs(conn *websocket.Conn, t string) {
+// pumpWs writes the messages that the subscribe function delivers to the
+// websocket connection until the client is gone or the service is shut down.
+// The name of the subscription is used in the log messages.
+func (s *Service) pumpWs(conn *websocket.Conn, name string, subscribe func(deliver func(context.Context, []byte)) (cleanup func())) {
 	defer s.wsWg.Done()
 
 	var (
 		dataC  = make(chan []byte)
 		gone   = make(chan struct{})
-		topic  = pss.NewTopic(t)
 		ticker = time.NewTicker(s.WsPingPeriod)
 		err    error
 	)
@@ -162,22 +217,22 @@ func (s *Service) pumpWs(conn *websocket.Conn, t string) {
 		ticker.Stop()
 		_ = conn.Close()
 	}()
-	cleanup := s.pss.Register(topic, func(ctx context.Context, m []byte) {
+	cleanup := subscribe(func(ctx context.Context, m []byte) {
 		select {
 		case dataC <- m:
 		case <-ctx.Done():
-			return
+			pss.Reject(ctx)
 		case <-gone:
-			return
+			pss.Reject(ctx)
 		case <-s.quit:
-			return
+			pss.Reject(ctx)
 		}
 	})
 
 	defer cleanup()
 
 	conn.SetCloseHandler(func(code int, text string) error {
-		s.logger.Debug("pss ws: client gone", "code", code, "message", text)
+		s.logger.Debug(name+" ws: client gone", "code", code, "message", text)
 		close(gone)
 		return nil
 	})
@@ -187,13 +242,13 @@ func (s *Service) pumpWs(conn *websocket.Conn, t string) {
 		case b := <-dataC:
 			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
 			if err != nil {
-				s.logger.Debug("pss ws: set write deadline failed", "error", err)
+				s.logger.Debug(name+" ws: set write deadline failed", "error", err)
 				return
 			}
 
 			err = conn.WriteMessage(websocket.BinaryMessage, b)
 			if err != nil {
-				s.logger.Debug("pss ws: write message failed", "error", err)
+				s.logger.Debug(name+" ws: write message failed", "error", err)
 				return
 			}
 
@@ -201,12 +256,12 @@ func (s *Service) pumpWs(conn *websocket.Conn, t string) {
 			// shutdown
 			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
 			if err != nil {
-				s.logger.Debug("pss ws: set write deadline failed", "error", err)
+				s.logger.Debug(name+" ws: set write deadline failed", "error", err)
 				return
 			}
 			err = conn.WriteMessage(websocket.CloseMessage, []byte{})
 			if err != nil {
-				s.logger.Debug("pss ws: write close message failed", "error", err)
+				s.logger.Debug(name+" ws: write close message failed", "error", err)
 			}
 			return
 		case <-gone:
@@ -215,7 +270,7 @@ func (s *Service) pumpWs(conn *websocket.Conn, t string) {
 		case <-ticker.C:
 			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
 			if err != nil {
-				s.logger.Debug("pss ws: set write deadline failed", "error", err)
+				s.logger.Debug(name+" ws: set write deadline failed", "error", err)
 				return
 			}
 			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -0,0 +1,225 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type pssMailboxPostResponse struct {
+	Reference swarm.Address `json:"reference"`
+	Index     uint64        `json:"index"`
+}
+
+type pssMailboxMessage struct {
+	Index     uint64 `json:"index"`
+	Timestamp uint64 `json:"timestamp"`
+	Payload   []byte `json:"payload"`
+	Recipient string `json:"recipient"`
+}
+
+type pssMailboxResponse struct {
+	Messages []pssMailboxMessage `json:"messages"`
+}
+
+// pssMailboxPostHandler appends the message to the mailbox of the recipient.
+func (s *Service) pssMailboxPostHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_pss_mailbox").Build()
+
+	paths := struct {
+		Topic string `map:"topic" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		Recipient *ecdsa.PublicKey `map:"recipient" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	headers := struct {
+		BatchID []byte `map:"Swarm-Postage-Batch-Id" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.Header, &headers); response != nil {
+		response("invalid header params", logger, w)
+		return
+	}
+
+	if s.pssMailbox == nil {
+		jsonhttp.ServiceUnavailable(w, "pss mailbox unavailable")
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		if jsonhttp.HandleBodyReadError(err, w) {
+			return
+		}
+		logger.Debug("read body failed", "error", err)
+		logger.Error(nil, "read body failed")
+		jsonhttp.InternalServerError(w, "pss mailbox send failed")
+		return
+	}
+	if len(payload) > pss.MaxPayloadSize {
+		jsonhttp.RequestEntityTooLarge(w, "payload too large")
+		return
+	}
+
+	putter, err := s.newStamperPutter(r.Context(), putterOptions{
+		BatchID: headers.BatchID,
+	})
+	if err != nil {
+		logger.Debug("get putter failed", "error", err)
+		logger.Error(nil, "get putter failed")
+		switch {
+		case errors.Is(err, errBatchUnusable) || errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.UnprocessableEntity(w, "batch not usable yet or does not exist")
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.NotFound(w, "batch with id not found")
+		case errors.Is(err, errInvalidPostageBatch):
+			jsonhttp.BadRequest(w, "invalid batch id")
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	ow := &cleanupOnErrWriter{
+		ResponseWriter: w,
+		onErr:          putter.Cleanup,
+		logger:         logger,
+	}
+
+	reference, index, err := s.pssMailbox.Send(r.Context(), putter, pss.NewTopic(paths.Topic), queries.Recipient, payload)
+	if err != nil {
+		logger.Debug("mailbox send failed", "topic", paths.Topic, "error", err)
+		logger.Error(nil, "mailbox send failed")
+		switch {
+		case errors.Is(err, postage.ErrBucketFull):
+			jsonhttp.PaymentRequired(ow, "batch is overissued")
+		default:
+			jsonhttp.InternalServerError(ow, "pss mailbox send failed")
+		}
+		return
+	}
+
+	if err = putter.Done(reference); err != nil {
+		logger.Debug("done split failed", "error", err)
+		logger.Error(nil, "done split failed")
+		jsonhttp.InternalServerError(ow, "done split failed")
+		return
+	}
+
+	jsonhttp.Created(w, pssMailboxPostResponse{Reference: reference, Index: index})
+}
+
+// pssMailboxGetHandler drains the messages of the sender in the mailbox of
+// the node on the topic.
+func (s *Service) pssMailboxGetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_pss_mailbox").Build()
+
+	paths := struct {
+		Topic string `map:"topic" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		Sender *ecdsa.PublicKey `map:"sender" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	if s.pssMailbox == nil {
+		jsonhttp.ServiceUnavailable(w, "pss mailbox unavailable")
+		return
+	}
+
+	messages, err := s.pssMailbox.Drain(r.Context(), pss.NewTopic(paths.Topic), queries.Sender)
+	if err != nil {
+		logger.Debug("mailbox drain failed", "topic", paths.Topic, "error", err)
+		logger.Error(nil, "mailbox drain failed")
+		jsonhttp.InternalServerError(w, "pss mailbox drain failed")
+		return
+	}
+
+	resp := pssMailboxResponse{Messages: make([]pssMailboxMessage, 0, len(messages))}
+	for _, m := range messages {
+		resp.Messages = append(resp.Messages, pssMailboxMessage{
+			Index:     m.Index,
+			Timestamp: m.Timestamp,
+			Payload:   m.Payload,
+			Recipient: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(m.Recipient)),
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// pssMailboxAckHandler acknowledges the messages of the sender in the mailbox
+// of the node on the topic up to and including the given index.
+func (s *Service) pssMailboxAckHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_pss_mailbox_ack").Build()
+
+	paths := struct {
+		Topic string `map:"topic" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	queries := struct {
+		Sender    *ecdsa.PublicKey `map:"sender" validate:"required"`
+		Index     *uint64          `map:"index" validate:"required"`
+		Recipient *ecdsa.PublicKey `map:"recipient"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	if s.pssMailbox == nil {
+		jsonhttp.ServiceUnavailable(w, "pss mailbox unavailable")
+		return
+	}
+
+	err := s.pssMailbox.Ack(r.Context(), pss.NewTopic(paths.Topic), queries.Sender, queries.Recipient, *queries.Index)
+	if err != nil {
+		logger.Debug("mailbox ack failed", "topic", paths.Topic, "index", *queries.Index, "error", err)
+		logger.Error(nil, "mailbox ack failed")
+		switch {
+		case errors.Is(err, mailbox.ErrUnknownIndex):
+			jsonhttp.NotFound(w, "message not found")
+		case errors.Is(err, mailbox.ErrUnknownRecipient):
+			jsonhttp.NotFound(w, "recipient key not found")
+		default:
+			jsonhttp.InternalServerError(w, "pss mailbox ack failed")
+		}
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
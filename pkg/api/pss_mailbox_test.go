@@ -0,0 +1,157 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	mockpost "github.com/ethersphere/bee/v2/pkg/postage/mock"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+)
+
+// nolint:paralleltest
+func TestPssMailbox(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		chunkStore = inmemchunkstore.New()
+		storer     = mockstorer.NewWithChunkStore(chunkStore)
+		mb         = mailbox.New(chunkStore, statestore.NewStateStore(), &key.PublicKey, crypto.NewDH(key), log.Noop)
+		sender     = mailbox.New(chunkStore, statestore.NewStateStore(), &recipientKey.PublicKey, crypto.NewDH(recipientKey), log.Noop)
+		recipient  = hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&recipientKey.PublicKey))
+		payload    = []byte("mailbox message")
+	)
+	client, _, _, chanStorer := newTestServer(t, testServerOptions{
+		Storer:       storer,
+		PssMailbox:   mb,
+		Post:         mockpost.New(mockpost.WithAcceptAll()),
+		DirectUpload: true,
+	})
+
+	t.Run("send", func(t *testing.T) {
+		var resp struct {
+			Reference string `json:"reference"`
+			Index     uint64 `json:"index"`
+		}
+		jsonhttptest.Request(t, client, http.MethodPost, "/pss/mailbox/testtopic?recipient="+recipient, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		feed, _, err := mailbox.Feed(crypto.NewDH(recipientKey), &key.PublicKey, &recipientKey.PublicKey, pss.NewTopic("testtopic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		feedUpdate, err := feed.Update(sequence.NewIndex(0)).Address()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Reference != feedUpdate.String() || resp.Index != 0 {
+			t.Fatalf("got reference %s at index %d, want %s at index 0", resp.Reference, resp.Index, feedUpdate)
+		}
+		if !chanStorer.Has(feedUpdate) {
+			t.Fatal("mailbox message was not pushed")
+		}
+	})
+
+	t.Run("send without recipient", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/pss/mailbox/testtopic", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "recipient",
+						Error: "want required:",
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		topic := pss.NewTopic("testtopic")
+		for i := 0; i < 2; i++ {
+			if _, _, err := sender.Send(context.Background(), chunkStore, topic, &key.PublicKey, payload); err != nil {
+				t.Fatal(err)
+			}
+		}
+		drainPath := "/pss/mailbox/testtopic?sender=" + recipient
+		ackPath := "/pss/mailbox/testtopic/ack?sender=" + recipient + "&index="
+
+		for i := 0; i < 2; i++ {
+			var resp api.PssMailboxResponse
+			jsonhttptest.Request(t, client, http.MethodGet, drainPath, http.StatusOK,
+				jsonhttptest.WithUnmarshalJSONResponse(&resp),
+			)
+			if len(resp.Messages) != 2 {
+				t.Fatalf("got %d messages, want 2", len(resp.Messages))
+			}
+			for i, m := range resp.Messages {
+				if m.Index != uint64(i) || !bytes.Equal(m.Payload, payload) || m.Recipient != hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&key.PublicKey)) {
+					t.Fatalf("unexpected message %d: %+v", i, m)
+				}
+			}
+		}
+
+		jsonhttptest.Request(t, client, http.MethodPost, ackPath+"2", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "message not found",
+			}),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, ackPath+"1&recipient="+recipient, http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "recipient key not found",
+			}),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, ackPath+"1", http.StatusOK)
+
+		jsonhttptest.Request(t, client, http.MethodGet, drainPath, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.PssMailboxResponse{
+				Messages: []api.PssMailboxMessage{},
+			}),
+		)
+	})
+
+	t.Run("drain without sender", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/mailbox/testtopic", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "sender",
+						Error: "want required:",
+					},
+				},
+			}),
+		)
+	})
+}
@@ -185,9 +185,10 @@ func TestPssSend(t *testing.T) {
 		mp              = mockpost.New(mockpost.WithIssuer(postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 11, 10, 1000, true)))
 		p               = newMockPss(sendFn)
 		client, _, _, _ = newTestServer(t, testServerOptions{
-			Pss:    p,
-			Storer: mockstorer.New(),
-			Post:   mp,
+			Pss:     p,
+			Storer:  mockstorer.New(),
+			Post:    mp,
+			Overlay: swarm.RandAddress(t),
 		})
 
 		recipient = hex.EncodeToString(publicKeyBytes)
@@ -255,6 +256,37 @@ func TestPssSend(t *testing.T) {
 		}
 	})
 
+	t.Run("ack", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/pss/send/testtopic/12?ack=true&ackTimeout=5s&recipient="+recipient, http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedJSONResponse(api.PssSendResponse{
+				MessageID: mockMessageID.String(),
+			}),
+		)
+		waitDone(t, &mtx, &done)
+		if !bytes.Equal(receivedBytes, payload) {
+			t.Fatalf("payload mismatch. want %v got %v", payload, receivedBytes)
+		}
+	})
+
+	t.Run("bad request - invalid ack timeout", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/pss/send/testtopic/12?ack=true&ackTimeout=soon", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "ackTimeout",
+						Error: "invalid duration",
+					},
+				},
+			}),
+		)
+	})
+
 	t.Run("without recipient", func(t *testing.T) {
 		jsonhttptest.Request(t, client, http.MethodPost, "/pss/send/testtopic/12", http.StatusCreated,
 			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
@@ -451,6 +483,8 @@ type mpss struct {
 	f pssSendFn
 }
 
+var mockMessageID = pss.MessageID{1, 2, 3}
+
 func newMockPss(f pssSendFn) *mpss {
 	return &mpss{f}
 }
@@ -464,6 +498,19 @@ func (m *mpss) Send(ctx context.Context, topic pss.Topic, payload []byte, _ post
 	return m.f(ctx, targets, chunk)
 }
 
+// SendEnvelope sends the payload in the given envelope.
+func (m *mpss) SendEnvelope(_ context.Context, _ pss.Topic, _ pss.Envelope, _ []byte, _ postage.Stamper, _ *ecdsa.PublicKey, _ pss.Targets) error {
+	panic("not implemented") // TODO: Implement
+}
+
+// SendAndWait sends the payload and returns as if it was acknowledged.
+func (m *mpss) SendAndWait(ctx context.Context, topic pss.Topic, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets pss.Targets, _ pss.ReplyTo, _ time.Duration) (pss.MessageID, error) {
+	if err := m.Send(ctx, topic, payload, stamper, recipient, targets); err != nil {
+		return pss.MessageID{}, err
+	}
+	return mockMessageID, nil
+}
+
 // Register a Handler for a given Topic.
 func (m *mpss) Register(_ pss.Topic, _ pss.Handler) func() {
 	panic("not implemented") // TODO: Implement
@@ -478,6 +525,10 @@ func (m *mpss) SetPushSyncer(pushSyncer pushsync.PushSyncer) {
 	panic("not implemented") // TODO: Implement
 }
 
+func (m *mpss) SetAckStamper(_ pss.AckStamperFunc) {
+	panic("not implemented") // TODO: Implement
+}
+
 func (m *mpss) Close() error {
 	panic("not implemented") // TODO: Implement
 }
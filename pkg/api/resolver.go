@@ -0,0 +1,52 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type reverseResolveResponse struct {
+	Address swarm.Address `json:"address"`
+	Names   []string      `json:"names"`
+}
+
+func (s *Service) reverseResolveHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_resolver_reverse").Build()
+
+	paths := struct {
+		Address swarm.Address `map:"address" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	rr, ok := s.resolver.(resolver.ReverseResolver)
+	if !ok {
+		jsonhttp.NotImplemented(w, "reverse resolution not supported")
+		return
+	}
+
+	names, err := rr.ReverseResolve(paths.Address)
+	if errors.Is(err, resolver.ErrNotFound) {
+		jsonhttp.NotFound(w, "no names found")
+		return
+	}
+	if err != nil {
+		logger.Debug("reverse resolution failed", "address", paths.Address, "error", err)
+		logger.Error(nil, "reverse resolution failed")
+		jsonhttp.InternalServerError(w, "reverse resolution failed")
+		return
+	}
+
+	jsonhttp.OK(w, reverseResolveResponse{Address: paths.Address, Names: names})
+}
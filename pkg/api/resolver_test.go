@@ -0,0 +1,62 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	resolverMock "github.com/ethersphere/bee/v2/pkg/resolver/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestReverseResolve(t *testing.T) {
+	t.Parallel()
+
+	addr := swarm.MustParseHexAddress("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		Resolver: resolverMock.NewResolver(
+			resolverMock.WithReverseResolveFunc(func(a swarm.Address) ([]string, error) {
+				if a.Equal(addr) {
+					return []string{"site.eth", "site.swarm"}, nil
+				}
+				return nil, resolver.ErrNotFound
+			}),
+		),
+	})
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/resolver/reverse/"+addr.String(), http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.ReverseResolveResponse{
+			Address: addr,
+			Names:   []string{"site.eth", "site.swarm"},
+		}),
+	)
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/resolver/reverse/"+swarm.RandAddress(t).String(), http.StatusNotFound,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "no names found",
+			Code:    http.StatusNotFound,
+		}),
+	)
+
+	t.Run("not supported", func(t *testing.T) {
+		t.Parallel()
+
+		// embedding the interface hides the ReverseResolve method of the mock
+		client, _, _, _ := newTestServer(t, testServerOptions{
+			Resolver: struct{ resolver.Interface }{resolverMock.NewResolver()},
+		})
+		jsonhttptest.Request(t, client, http.MethodGet, "/resolver/reverse/"+addr.String(), http.StatusNotImplemented,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "reverse resolution not supported",
+				Code:    http.StatusNotImplemented,
+			}),
+		)
+	})
+}
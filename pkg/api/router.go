@@ -99,6 +99,7 @@ func (s *Service) MountAPI() {
 		s.responseCodeMetricsHandler,
 		s.pageviewMetricsHandler,
 		s.corsHandler,
+		s.tenantContextHandler,
 		web.FinalHandler(s.router),
 	)
 }
@@ -275,6 +276,12 @@ func (s *Service) mountAPI() {
 		),
 	})
 
+	handle("/grantee/rekey", jsonhttp.MethodHandler{
+		"POST": web.ChainHandlers(
+			web.FinalHandlerFunc(s.actRekeyHandler),
+		),
+	})
+
 	handle("/grantee/{address}", jsonhttp.MethodHandler{
 		"GET": web.ChainHandlers(
 			web.FinalHandlerFunc(s.actListGranteesHandler),
@@ -312,10 +319,30 @@ func (s *Service) mountAPI() {
 		})),
 	)
 
+	handle("/pss/mailbox/{topic}", web.ChainHandlers(
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pssMailboxGetHandler),
+			"POST": web.ChainHandlers(
+				jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkSize),
+				web.FinalHandlerFunc(s.pssMailboxPostHandler),
+			),
+		})),
+	)
+
+	handle("/pss/mailbox/{topic}/ack", web.ChainHandlers(
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.pssMailboxAckHandler),
+		})),
+	)
+
 	handle("/pss/subscribe/{topic}", web.ChainHandlers(
 		web.FinalHandlerFunc(s.pssWsHandler),
 	))
 
+	handle("/gsoc/subscribe/{address}", web.ChainHandlers(
+		web.FinalHandlerFunc(s.gsocWsHandler),
+	))
+
 	handle("/tags", web.ChainHandlers(
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.listTagsHandler),
@@ -366,6 +393,12 @@ func (s *Service) mountAPI() {
 		),
 	})
 
+	handle("/stewardship/{address}/repair", jsonhttp.MethodHandler{
+		"POST": web.ChainHandlers(
+			web.FinalHandlerFunc(s.stewardshipRepairHandler),
+		),
+	})
+
 	handle("/readiness", web.ChainHandlers(
 		httpaccess.NewHTTPAccessSuppressLogHandler(),
 		web.FinalHandlerFunc(s.readinessHandler),
@@ -426,6 +459,18 @@ func (s *Service) mountBusinessDebug() {
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
 
+	handle("/topology/history", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.topologyHistoryHandler),
+	})
+
+	handle("/traffic", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.trafficHandler),
+	})
+
+	handle("/census", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.censusHandler),
+	})
+
 	handle("/welcome-message", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.getWelcomeMessageHandler),
 		"POST": web.ChainHandlers(
@@ -478,6 +523,10 @@ func (s *Service) mountBusinessDebug() {
 				web.FinalHandlerFunc(s.swapCashoutHandler),
 			),
 		})
+
+		handle("/chequebook/cashouts", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.swapCashoutsHandler),
+		})
 	}
 
 	if s.chequebookEnabled {
@@ -516,6 +565,31 @@ func (s *Service) mountBusinessDebug() {
 		}
 	}
 
+	if s.wallet != nil {
+		handle("/wallet/addresses", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.walletAddressesHandler),
+		})
+
+		handle("/wallet/withdrawals", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.walletWithdrawalsHandler),
+		})
+
+		handle("/wallet/withdrawals/{id}", jsonhttp.MethodHandler{
+			"DELETE": http.HandlerFunc(s.walletRejectHandler),
+		})
+
+		handle("/wallet/withdrawals/{id}/approve", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				s.gasConfigMiddleware("wallet withdrawal approve"),
+				web.FinalHandlerFunc(s.walletApproveHandler),
+			),
+		})
+
+		handle("/wallet/audit", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.walletAuditHandler),
+		})
+	}
+
 	handle("/stamps", web.ChainHandlers(
 		s.postageSyncStatusCheckHandler,
 		web.FinalHandler(jsonhttp.MethodHandler{
@@ -574,6 +648,32 @@ func (s *Service) mountBusinessDebug() {
 		"GET": http.HandlerFunc(s.accountingInfoHandler),
 	})
 
+	handle("/accounting/ledger", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.ledgerExportHandler),
+	})
+
+	handle("/accounting/ledger/reconciliation", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.ledgerReconciliationHandler),
+	})
+
+	handle("/accounting/ledger/{peer}/timeline", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.ledgerTimelineHandler),
+	})
+
+	handle("/resolver/reverse/{address}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.reverseResolveHandler),
+	})
+
+	if s.tenants != nil {
+		handle("/tenants", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.tenantsGetHandler),
+		})
+
+		handle("/tenants/{tenant}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.tenantGetHandler),
+		})
+	}
+
 	handle("/readiness", web.ChainHandlers(
 		httpaccess.NewHTTPAccessSuppressLogHandler(),
 		web.FinalHandlerFunc(s.readinessHandler),
@@ -593,6 +693,18 @@ func (s *Service) mountBusinessDebug() {
 		})),
 	)
 
+	handle("/stake/withdrawable/{amount}", web.ChainHandlers(
+		s.stakingAccessHandler,
+		s.gasConfigMiddleware("withdraw stake"),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"DELETE": http.HandlerFunc(s.withdrawStakeAmountHandler),
+		})),
+	)
+
+	handle("/stake/history", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.stakeHistoryHandler),
+	})
+
 	handle("/stake/{amount}", web.ChainHandlers(
 		s.stakingAccessHandler,
 		s.gasConfigMiddleware("deposit stake"),
@@ -8,11 +8,15 @@ import (
 	"errors"
 	"math/big"
 	"net/http"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/gorilla/mux"
 )
 
@@ -73,6 +77,7 @@ func (s *Service) stakingDepositHandler(w http.ResponseWriter, r *http.Request)
 		jsonhttp.InternalServerError(w, "cannot stake")
 		return
 	}
+	s.recordTransfer(logger, wallet.KindStakeDeposit, s.stakingContract.Address(), paths.Amount, txHash, "")
 	jsonhttp.OK(w, stakeTransactionReponse{
 		TxHash: txHash.String(),
 	})
@@ -126,6 +131,43 @@ func (s *Service) withdrawStakeHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, stakeTransactionReponse{TxHash: txHash.String()})
 }
 
+func (s *Service) withdrawStakeAmountHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("withdraw_stake_amount").Build()
+
+	paths := struct {
+		Amount *big.Int `map:"amount" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	txHash, err := s.stakingContract.WithdrawStakeAmount(r.Context(), paths.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, staking.ErrInvalidWithdrawAmount):
+			jsonhttp.BadRequest(w, "invalid withdraw amount")
+			return
+		case errors.Is(err, staking.ErrInsufficientStake):
+			logger.Debug("insufficient stake", "overlayAddr", s.overlay, "error", err)
+			logger.Error(nil, "insufficient stake")
+			jsonhttp.BadRequest(w, "insufficient stake to withdraw")
+			return
+		case errors.Is(err, staking.ErrWithdrawAmountTooHigh):
+			logger.Debug("withdraw amount too high", "amount", paths.Amount, "error", err)
+			logger.Error(nil, "withdraw amount too high")
+			jsonhttp.BadRequest(w, "withdraw amount exceeds the withdrawable stake")
+			return
+		}
+		logger.Debug("withdraw stake failed", "amount", paths.Amount, "tx", txHash, "error", err)
+		logger.Error(nil, "withdraw stake failed")
+		jsonhttp.InternalServerError(w, "cannot withdraw stake")
+		return
+	}
+
+	jsonhttp.OK(w, stakeTransactionReponse{TxHash: txHash.String()})
+}
+
 func (s *Service) migrateStakeHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("migrate_stake").Build()
 
@@ -151,3 +193,71 @@ func (s *Service) migrateStakeHandler(w http.ResponseWriter, r *http.Request) {
 
 	jsonhttp.OK(w, stakeTransactionReponse{TxHash: txHash.String()})
 }
+
+type stakeHistoryEvent struct {
+	Kind            string         `json:"kind"`
+	Block           uint64         `json:"block"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	Overlay         swarm.Address  `json:"overlay"`
+	PreviousOverlay swarm.Address  `json:"previousOverlay"`
+	CommittedStake  *bigint.BigInt `json:"committedStake,omitempty"`
+	PotentialStake  *bigint.BigInt `json:"potentialStake,omitempty"`
+	Amount          *bigint.BigInt `json:"amount,omitempty"`
+	FrozenUntil     uint64         `json:"frozenUntil,omitempty"`
+	Created         time.Time      `json:"created"`
+}
+
+type stakeHistoryResponse struct {
+	Frozen      bool                `json:"frozen"`
+	FrozenUntil uint64              `json:"frozenUntil"`
+	Block       uint64              `json:"block"`
+	Events      []stakeHistoryEvent `json:"events"`
+}
+
+func (s *Service) stakeHistoryHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_stake_history").Build()
+
+	response := stakeHistoryResponse{Events: []stakeHistoryEvent{}}
+	if s.stakingWatcher == nil {
+		jsonhttp.OK(w, response)
+		return
+	}
+
+	history, err := s.stakingWatcher.History()
+	if err != nil {
+		logger.Debug("get stake history failed", "error", err)
+		logger.Error(nil, "get stake history failed")
+		jsonhttp.InternalServerError(w, "get stake history failed")
+		return
+	}
+
+	status := s.stakingWatcher.FreezeStatus()
+	response.Frozen = status.Frozen
+	response.FrozenUntil = status.FrozenUntil
+	response.Block = status.Block
+	for _, e := range history {
+		response.Events = append(response.Events, stakeHistoryEvent{
+			Kind:            string(e.Kind),
+			Block:           e.Block,
+			TransactionHash: e.TxHash,
+			Overlay:         e.Overlay,
+			PreviousOverlay: e.PreviousOverlay,
+			CommittedStake:  wrapBigInt(e.CommittedStake),
+			PotentialStake:  wrapBigInt(e.PotentialStake),
+			Amount:          wrapBigInt(e.Amount),
+			FrozenUntil:     e.FrozenUntil,
+			Created:         e.Created,
+		})
+	}
+
+	jsonhttp.OK(w, response)
+}
+
+// wrapBigInt wraps the optional amount, which is omitted from the response
+// when it is not set.
+func wrapBigInt(i *big.Int) *bigint.BigInt {
+	if i == nil {
+		return nil
+	}
+	return bigint.Wrap(i)
+}
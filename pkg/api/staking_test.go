@@ -15,11 +15,17 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 
 	"github.com/ethersphere/bee/v2/pkg/api"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
 	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
 	stakingContractMock "github.com/ethersphere/bee/v2/pkg/storageincentives/staking/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
 )
 
 func TestDepositStake(t *testing.T) {
@@ -267,6 +273,71 @@ func TestWithdrawStake(t *testing.T) {
 	})
 }
 
+func TestWithdrawStakeAmount(t *testing.T) {
+	t.Parallel()
+
+	txHash := common.HexToHash("0x1234")
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		contract := stakingContractMock.New(
+			stakingContractMock.WithWithdrawStakeAmount(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+				if amount.Cmp(big.NewInt(100)) != 0 {
+					t.Fatalf("got amount %d, want %d", amount, 100)
+				}
+				return txHash, nil
+			}),
+		)
+		ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: contract})
+		jsonhttptest.Request(t, ts, http.MethodDelete, "/stake/withdrawable/100", http.StatusOK, jsonhttptest.WithExpectedJSONResponse(
+			&api.StakeTransactionReponse{TxHash: txHash.String()}))
+	})
+
+	for _, tc := range []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{name: "invalid amount", err: staking.ErrInvalidWithdrawAmount, message: "invalid withdraw amount"},
+		{name: "insufficient stake", err: staking.ErrInsufficientStake, message: "insufficient stake to withdraw"},
+		{name: "amount too high", err: staking.ErrWithdrawAmountTooHigh, message: "withdraw amount exceeds the withdrawable stake"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			contract := stakingContractMock.New(
+				stakingContractMock.WithWithdrawStakeAmount(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+					return common.Hash{}, tc.err
+				}),
+			)
+			ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: contract})
+			jsonhttptest.Request(t, ts, http.MethodDelete, "/stake/withdrawable/100", http.StatusBadRequest,
+				jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{Code: http.StatusBadRequest, Message: tc.message}))
+		})
+	}
+
+	t.Run("invalid path", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: stakingContractMock.New()})
+		jsonhttptest.Request(t, ts, http.MethodDelete, "/stake/withdrawable/abc", http.StatusBadRequest)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		t.Parallel()
+
+		contract := stakingContractMock.New(
+			stakingContractMock.WithWithdrawStakeAmount(func(ctx context.Context, amount *big.Int) (common.Hash, error) {
+				return txHash, fmt.Errorf("some error")
+			}),
+		)
+		ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: contract})
+		jsonhttptest.Request(t, ts, http.MethodDelete, "/stake/withdrawable/100", http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{Code: http.StatusInternalServerError, Message: "cannot withdraw stake"}))
+	})
+}
+
 func TestMigrateStake(t *testing.T) {
 	t.Parallel()
 
@@ -332,3 +403,62 @@ func TestMigrateStake(t *testing.T) {
 		)
 	})
 }
+
+func TestStakeHistory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		nonce := common.HexToHash("0x1234")
+		previous := swarm.RandAddress(t)
+		overlay := swarm.RandAddress(t)
+
+		contract := stakingContractMock.New(
+			stakingContractMock.WithGetStake(func(context.Context) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+			stakingContractMock.WithIsFrozen(func(context.Context, uint64) (bool, error) {
+				return false, nil
+			}),
+		)
+		backend := backendmock.New(backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+			return 100, nil
+		}))
+		watcher, err := staking.NewWatcher(log.Noop, statestore.NewStateStore(), backend, contract, common.Address{}, abiutil.MustParseABI(chaincfg.Testnet.StakingABI), common.Address{}, swarm.ZeroAddress, staking.WatcherOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = watcher.Close() })
+
+		if _, err := watcher.MigrateOverlay(context.Background(), previous, overlay, nonce); err != nil {
+			t.Fatal(err)
+		}
+		history, err := watcher.History()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: contract, StakingWatcher: watcher})
+		jsonhttptest.Request(t, ts, http.MethodGet, "/stake/history", http.StatusOK, jsonhttptest.WithExpectedJSONResponse(
+			&api.StakeHistoryResponse{
+				Events: []api.StakeHistoryEvent{{
+					Kind:            string(staking.EventOverlayMigrated),
+					Block:           100,
+					TransactionHash: nonce,
+					Overlay:         overlay,
+					PreviousOverlay: previous,
+					PotentialStake:  bigint.Wrap(big.NewInt(10)),
+					Created:         history[0].Created,
+				}},
+			}))
+	})
+
+	t.Run("without watcher", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _, _, _ := newTestServer(t, testServerOptions{StakingContract: stakingContractMock.New()})
+		jsonhttptest.Request(t, ts, http.MethodGet, "/stake/history", http.StatusOK, jsonhttptest.WithExpectedJSONResponse(
+			&api.StakeHistoryResponse{Events: []api.StakeHistoryEvent{}}))
+	})
+}
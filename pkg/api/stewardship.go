@@ -8,6 +8,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	storage "github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
@@ -102,3 +103,97 @@ func (s *Service) stewardshipGetHandler(w http.ResponseWriter, r *http.Request)
 		IsRetrievable: res,
 	})
 }
+
+type repairNodeResponse struct {
+	Address         swarm.Address    `json:"address"`
+	RedundancyLevel redundancy.Level `json:"redundancyLevel"`
+	Shards          int              `json:"shards"`
+	Parities        int              `json:"parities"`
+	MissingShards   int              `json:"missingShards"`
+	MissingParities int              `json:"missingParities"`
+	Repaired        int              `json:"repaired"`
+	Healthy         bool             `json:"healthy"`
+}
+
+type repairResponse struct {
+	Healthy  bool                 `json:"healthy"`
+	Missing  int                  `json:"missing"`
+	Repaired int                  `json:"repaired"`
+	Nodes    []repairNodeResponse `json:"nodes"`
+}
+
+// stewardshipRepairHandler rebuilds the missing chunks of the content on the
+// given address from its erasure code, re-uploads only those and reports the
+// health of every intermediate chunk.
+func (s *Service) stewardshipRepairHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_stewardship_repair").Build()
+
+	paths := struct {
+		Address swarm.Address `map:"address,resolve" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	headers := struct {
+		BatchID []byte           `map:"Swarm-Postage-Batch-Id" validate:"required"`
+		RLevel  redundancy.Level `map:"Swarm-Redundancy-Level"`
+	}{}
+	if response := s.mapStructure(r.Header, &headers); response != nil {
+		response("invalid header params", logger, w)
+		return
+	}
+
+	stamper, save, err := s.getStamper(headers.BatchID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errBatchUnusable) || errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.UnprocessableEntity(w, "batch not usable yet or does not exist")
+		case errors.Is(err, postage.ErrNotFound) || errors.Is(err, storage.ErrNotFound):
+			jsonhttp.NotFound(w, "batch with id not found")
+		case errors.Is(err, errInvalidPostageBatch):
+			jsonhttp.BadRequest(w, "invalid batch id")
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	// the redundancy level is used to look for the replicas of missing root chunks
+	ctx := redundancy.SetLevelInContext(r.Context(), headers.RLevel)
+	report, err := s.steward.Repair(ctx, paths.Address, stamper)
+	if err != nil {
+		logger.Debug("repair failed", "chunk_address", paths.Address, "error", err)
+		logger.Error(nil, "repair failed")
+		jsonhttp.InternalServerError(w, "repair failed")
+		return
+	}
+
+	if err = save(); err != nil {
+		logger.Debug("unable to save stamper data", "batchID", headers.BatchID, "error", err)
+		logger.Error(nil, "unable to save stamper data")
+		jsonhttp.InternalServerError(w, "unable to save stamper data")
+		return
+	}
+
+	nodes := make([]repairNodeResponse, 0, len(report.Nodes))
+	for _, n := range report.Nodes {
+		nodes = append(nodes, repairNodeResponse{
+			Address:         n.Address,
+			RedundancyLevel: n.Level,
+			Shards:          n.Shards,
+			Parities:        n.Parities,
+			MissingShards:   n.MissingShards,
+			MissingParities: n.MissingParities,
+			Repaired:        n.Repaired,
+			Healthy:         n.Healthy(),
+		})
+	}
+	jsonhttp.OK(w, repairResponse{
+		Healthy:  report.Healthy(),
+		Missing:  report.Missing,
+		Repaired: report.Repaired,
+		Nodes:    nodes,
+	})
+}
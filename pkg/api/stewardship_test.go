@@ -58,6 +58,20 @@ func TestStewardship(t *testing.T) {
 			}),
 		)
 	})
+
+	t.Run("repair", func(t *testing.T) {
+		repairAddr := swarm.NewAddress([]byte{31: 129})
+		jsonhttptest.Request(t, client, http.MethodPost, "/v1/stewardship/"+repairAddr.String()+"/repair", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.RepairResponse{
+				Healthy: true,
+				Nodes:   []api.RepairNodeResponse{},
+			}),
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, "aa"),
+		)
+		if !stewardMock.LastAddress().Equal(repairAddr) {
+			t.Fatalf("\nhave address: %q\nwant address: %q", stewardMock.LastAddress().String(), repairAddr.String())
+		}
+	})
 }
 
 func TestStewardshipInvalidInputs(t *testing.T) {
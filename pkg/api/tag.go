@@ -15,6 +15,7 @@ import (
 	storage "github.com/ethersphere/bee/v2/pkg/storage"
 	storer "github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/gorilla/mux"
 )
 
@@ -60,6 +61,14 @@ func (s *Service) createTagHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, "cannot create tag")
 		return
 	}
+
+	if err := s.recordTenantTag(tenant.GetFromContext(r.Context()), tag.TagID); err != nil {
+		logger.Debug("record tenant tag failed", "tag_id", tag.TagID, "error", err)
+		logger.Error(nil, "record tenant tag failed")
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
 	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
 	jsonhttp.Created(w, newTagResponse(tag))
 }
@@ -0,0 +1,142 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
+	"github.com/gorilla/mux"
+)
+
+type tenantUsageResponse struct {
+	Tenant       string            `json:"tenant"`
+	Chunks       uint64            `json:"chunks"`
+	UniqueChunks uint64            `json:"uniqueChunks"`
+	Tags         uint64            `json:"tags"`
+	Pins         uint64            `json:"pins"`
+	BucketSlots  map[string]uint64 `json:"bucketSlots"`
+}
+
+type tenantsResponse struct {
+	Tenants []tenantUsageResponse `json:"tenants"`
+}
+
+func newTenantUsageResponse(u tenant.Usage) tenantUsageResponse {
+	return tenantUsageResponse{
+		Tenant:       u.Tenant,
+		Chunks:       u.Chunks,
+		UniqueChunks: u.UniqueChunks,
+		Tags:         u.Tags,
+		Pins:         u.Pins,
+		BucketSlots:  u.BucketSlots,
+	}
+}
+
+// tenantContextHandler resolves the tenant of the request from the bearer
+// token or the Swarm-Tenant header and sets it in the request context. A
+// header that does not match the tenant of the token is rejected.
+func (s *Service) tenantContextHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.tenants == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		token, _ := strings.CutPrefix(r.Header.Get(AuthorizationHeader), "Bearer ")
+		label, err := s.tenants.Resolve(r.Header.Get(SwarmTenantHeader), token)
+		if err != nil {
+			s.logger.Debug("tenant: resolve failed", "error", err)
+			jsonhttp.BadRequest(w, jsonhttp.StatusResponse{
+				Message: "invalid header params",
+				Code:    http.StatusBadRequest,
+				Reasons: []jsonhttp.Reason{{
+					Field: strings.ToLower(SwarmTenantHeader),
+					Error: err.Error(),
+				}},
+			})
+			return
+		}
+		if label == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		h.ServeHTTP(w, r.WithContext(tenant.SetInContext(r.Context(), label)))
+	})
+}
+
+func (s *Service) tenantsGetHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_tenants").Build()
+
+	usages, err := s.tenants.Usages()
+	if err != nil {
+		logger.Debug("get tenants failed", "error", err)
+		logger.Error(nil, "get tenants failed")
+		jsonhttp.InternalServerError(w, "cannot get tenants")
+		return
+	}
+
+	resp := tenantsResponse{Tenants: make([]tenantUsageResponse, 0, len(usages))}
+	for _, u := range usages {
+		resp.Tenants = append(resp.Tenants, newTenantUsageResponse(u))
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) tenantGetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_tenant").Build()
+
+	paths := struct {
+		Tenant string `map:"tenant" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	u, err := s.tenants.Usage(paths.Tenant)
+	if err != nil {
+		if errors.Is(err, tenant.ErrNotFound) {
+			jsonhttp.NotFound(w, "tenant not found")
+			return
+		}
+		logger.Debug("get tenant failed", "tenant", paths.Tenant, "error", err)
+		logger.Error(nil, "get tenant failed", "tenant", paths.Tenant)
+		jsonhttp.InternalServerError(w, "cannot get tenant")
+		return
+	}
+
+	jsonhttp.OK(w, newTenantUsageResponse(u))
+}
+
+// recordTenantTag attributes the upload session to the tenant of the request.
+func (s *Service) recordTenantTag(label string, tagID uint64) error {
+	if s.tenants == nil {
+		return nil
+	}
+	return s.tenants.RecordTag(label, tagID)
+}
+
+// tenantSession returns the session counting the chunks of an upload of the
+// tenant, or nil if the tenants are not accounted.
+func (s *Service) tenantSession(label string) *tenant.Session {
+	if s.tenants == nil {
+		return nil
+	}
+	return s.tenants.NewSession(label)
+}
+
+// recordTenantPin attributes the pinned reference to the tenant of the request.
+func (s *Service) recordTenantPin(label string, ref swarm.Address) error {
+	if s.tenants == nil {
+		return nil
+	}
+	return s.tenants.RecordPin(label, ref)
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	mockpost "github.com/ethersphere/bee/v2/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
+	"github.com/google/go-cmp/cmp"
+)
+
+// nolint:paralleltest,tparallel
+func TestTenants(t *testing.T) {
+	t.Parallel()
+
+	client, _, _, _ := newTestServer(t, testServerOptions{
+		Storer:  mockstorer.New(),
+		Post:    mockpost.New(mockpost.WithAcceptAll()),
+		Tenants: tenant.New(statestore.NewStateStore(), map[string]string{"secret": "team-b"}),
+	})
+
+	content := bytes.Repeat([]byte{1}, swarm.ChunkSize)
+
+	t.Run("upload with header", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated,
+				jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithRequestHeader(api.SwarmPinHeader, "true"),
+				jsonhttptest.WithRequestHeader(api.SwarmTenantHeader, "team-a"),
+				jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+			)
+		}
+	})
+
+	t.Run("upload with token", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmDeferredUploadHeader, "true"),
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.AuthorizationHeader, "Bearer secret"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+		)
+	})
+
+	t.Run("header mismatching token", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.AuthorizationHeader, "Bearer secret"),
+			jsonhttptest.WithRequestHeader(api.SwarmTenantHeader, "team-a"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid header params",
+				Reasons: []jsonhttp.Reason{{
+					Field: "swarm-tenant",
+					Error: tenant.ErrLabelMismatch.Error(),
+				}},
+			}),
+		)
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestHeader(api.SwarmTenantHeader, "team a"),
+			jsonhttptest.WithRequestBody(bytes.NewReader(content)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid header params",
+				Reasons: []jsonhttp.Reason{{
+					Field: "swarm-tenant",
+					Error: tenant.ErrInvalidLabel.Error(),
+				}},
+			}),
+		)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var resp api.TenantsResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/tenants", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		want := []api.TenantUsageResponse{
+			{
+				Tenant:       "team-a",
+				Chunks:       2,
+				UniqueChunks: 1,
+				Tags:         2,
+				Pins:         1,
+				BucketSlots:  map[string]uint64{batchOkStr: 1},
+			},
+			{
+				Tenant:       "team-b",
+				Chunks:       1,
+				UniqueChunks: 1,
+				Tags:         1,
+				BucketSlots:  map[string]uint64{batchOkStr: 1},
+			},
+		}
+		if diff := cmp.Diff(want, resp.Tenants); diff != "" {
+			t.Fatalf("tenants mismatch (-want +have):\n%s", diff)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/tenants/team-b", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.TenantUsageResponse{
+				Tenant:       "team-b",
+				Chunks:       1,
+				UniqueChunks: 1,
+				Tags:         1,
+				BucketSlots:  map[string]uint64{batchOkStr: 1},
+			}),
+		)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/tenants/unknown", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "tenant not found",
+			}),
+		)
+	})
+}
@@ -9,8 +9,11 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
 )
 
 func (s *Service) topologyHandler(w http.ResponseWriter, _ *http.Request) {
@@ -29,3 +32,29 @@ func (s *Service) topologyHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set(ContentTypeHeader, jsonhttp.DefaultContentTypeHeader)
 	_, _ = io.Copy(w, bytes.NewBuffer(b))
 }
+
+// topologyHistoryHandler returns the recorded topology events and snapshots,
+// optionally filtered by the unix time range and the peer.
+func (s *Service) topologyHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_topology_history").Build()
+
+	queries := struct {
+		From int64         `map:"from"`
+		To   int64         `map:"to"`
+		Peer swarm.Address `map:"peer"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	filter := topology.HistoryFilter{Peer: queries.Peer}
+	if queries.From > 0 {
+		filter.From = time.Unix(queries.From, 0)
+	}
+	if queries.To > 0 {
+		filter.To = time.Unix(queries.To, 0)
+	}
+
+	jsonhttp.OK(w, s.topologyDriver.History(filter))
+}
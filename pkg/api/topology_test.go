@@ -7,8 +7,13 @@ package api_test
 import (
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+	topologymock "github.com/ethersphere/bee/v2/pkg/topology/mock"
 )
 
 func TestTopologyOK(t *testing.T) {
@@ -24,3 +29,46 @@ func TestTopologyOK(t *testing.T) {
 		t.Error("empty response")
 	}
 }
+
+func TestTopologyHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		peer    = swarm.RandAddress(t)
+		event   = topology.Event{Timestamp: time.Unix(1700000000, 0).UTC(), Type: topology.EventDisconnect, Peer: &peer, Reason: "blocklisted"}
+		filters = make(chan topology.HistoryFilter, 1)
+	)
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		TopologyOpts: []topologymock.Option{topologymock.WithHistoryFunc(func(f topology.HistoryFilter) *topology.KadHistory {
+			filters <- f
+			return &topology.KadHistory{Events: []topology.Event{event}, Snapshots: []topology.HistorySnapshot{}}
+		})},
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/topology/history?from=1600000000&to=1800000000&peer="+peer.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(topology.KadHistory{Events: []topology.Event{event}, Snapshots: []topology.HistorySnapshot{}}),
+		)
+
+		f := <-filters
+		if !f.From.Equal(time.Unix(1600000000, 0)) || !f.To.Equal(time.Unix(1800000000, 0)) || !f.Peer.Equal(peer) {
+			t.Fatalf("got filter %+v", f)
+		}
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer, http.MethodGet, "/topology/history?from=yesterday", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid query params",
+				Reasons: []jsonhttp.Reason{
+					{
+						Field: "from",
+						Error: "invalid syntax",
+					},
+				},
+			}),
+		)
+	})
+}
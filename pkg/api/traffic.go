@@ -0,0 +1,61 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+)
+
+type protocolTrafficResponse struct {
+	Protocol          string `json:"protocol"`
+	Priority          string `json:"priority"`
+	UploadRate        int64  `json:"uploadRate"`
+	DownloadRate      int64  `json:"downloadRate"`
+	Uploaded          uint64 `json:"uploaded"`
+	Downloaded        uint64 `json:"downloaded"`
+	UploadThrottled   string `json:"uploadThrottled"`
+	DownloadThrottled string `json:"downloadThrottled"`
+}
+
+type trafficResponse struct {
+	UploadRate   int64                     `json:"uploadRate"`
+	DownloadRate int64                     `json:"downloadRate"`
+	Protocols    []protocolTrafficResponse `json:"protocols"`
+}
+
+// trafficHandler returns the bandwidth budgets of the protocols and the
+// traffic they have used.
+func (s *Service) trafficHandler(w http.ResponseWriter, _ *http.Request) {
+	reporter, ok := s.p2p.(p2p.TrafficReporter)
+	if !ok {
+		jsonhttp.ServiceUnavailable(w, "traffic usage unavailable")
+		return
+	}
+
+	usage := reporter.TrafficUsage()
+
+	resp := trafficResponse{
+		UploadRate:   usage.UploadRate,
+		DownloadRate: usage.DownloadRate,
+		Protocols:    make([]protocolTrafficResponse, 0, len(usage.Protocols)),
+	}
+	for _, p := range usage.Protocols {
+		resp.Protocols = append(resp.Protocols, protocolTrafficResponse{
+			Protocol:          p.Protocol,
+			Priority:          p.Priority,
+			UploadRate:        p.UploadRate,
+			DownloadRate:      p.DownloadRate,
+			Uploaded:          p.Uploaded,
+			Downloaded:        p.Downloaded,
+			UploadThrottled:   p.UploadThrottled.String(),
+			DownloadThrottled: p.DownloadThrottled.String(),
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
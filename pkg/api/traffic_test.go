@@ -0,0 +1,51 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/p2p/mock"
+)
+
+func TestTraffic(t *testing.T) {
+	t.Parallel()
+
+	testServer, _, _, _ := newTestServer(t, testServerOptions{
+		P2P: mock.New(mock.WithTrafficUsageFunc(func() p2p.TrafficUsage {
+			return p2p.TrafficUsage{
+				UploadRate: 1 << 20,
+				Protocols: []p2p.ProtocolTrafficUsage{{
+					Protocol:          "pullsync",
+					Priority:          "low",
+					DownloadRate:      1 << 19,
+					Uploaded:          100,
+					Downloaded:        200,
+					DownloadThrottled: 1500 * time.Millisecond,
+				}},
+			}
+		})),
+	})
+
+	jsonhttptest.Request(t, testServer, http.MethodGet, "/traffic", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.TrafficResponse{
+			UploadRate: 1 << 20,
+			Protocols: []api.ProtocolTraffic{{
+				Protocol:          "pullsync",
+				Priority:          "low",
+				DownloadRate:      1 << 19,
+				Uploaded:          100,
+				Downloaded:        200,
+				UploadThrottled:   "0s",
+				DownloadThrottled: "1.5s",
+			}},
+		}),
+	)
+}
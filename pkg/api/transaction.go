@@ -39,10 +39,19 @@ type transactionInfo struct {
 	Created         time.Time       `json:"created"`
 	Description     string          `json:"description"`
 	Value           *bigint.BigInt  `json:"value"`
+	Priority        string          `json:"priority"`
+	Bumps           int             `json:"bumps"`
+}
+
+type queuedTransactionInfo struct {
+	Priority    string    `json:"priority"`
+	Description string    `json:"description"`
+	Queued      time.Time `json:"queued"`
 }
 
 type transactionPendingList struct {
-	PendingTransactions []transactionInfo `json:"pendingTransactions"`
+	PendingTransactions []transactionInfo       `json:"pendingTransactions"`
+	QueuedTransactions  []queuedTransactionInfo `json:"queuedTransactions"`
 }
 
 func (s *Service) transactionListHandler(w http.ResponseWriter, _ *http.Request) {
@@ -79,12 +88,25 @@ func (s *Service) transactionListHandler(w http.ResponseWriter, _ *http.Request)
 			Created:         time.Unix(storedTransaction.Created, 0),
 			Description:     storedTransaction.Description,
 			Value:           bigint.Wrap(storedTransaction.Value),
+			Priority:        storedTransaction.Priority.String(),
+			Bumps:           storedTransaction.Bumps,
 		})
 
 	}
 
+	queued := s.transaction.QueuedTransactions()
+	queuedInfos := make([]queuedTransactionInfo, 0, len(queued))
+	for _, q := range queued {
+		queuedInfos = append(queuedInfos, queuedTransactionInfo{
+			Priority:    q.Priority.String(),
+			Description: q.Description,
+			Queued:      q.Queued,
+		})
+	}
+
 	jsonhttp.OK(w, transactionPendingList{
 		PendingTransactions: transactionInfos,
+		QueuedTransactions:  queuedInfos,
 	})
 }
 
@@ -124,6 +146,8 @@ func (s *Service) transactionDetailHandler(w http.ResponseWriter, r *http.Reques
 		Created:         time.Unix(storedTransaction.Created, 0),
 		Description:     storedTransaction.Description,
 		Value:           bigint.Wrap(storedTransaction.Value),
+		Priority:        storedTransaction.Priority.String(),
+		Bumps:           storedTransaction.Bumps,
 	})
 }
 
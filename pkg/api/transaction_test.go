@@ -75,6 +75,7 @@ func TestTransactionStoredTransaction(t *testing.T) {
 				Value:           bigint.Wrap(value),
 				Nonce:           nonce,
 				Description:     description,
+				Priority:        "chequebook",
 			}),
 		)
 	})
@@ -148,8 +149,11 @@ func TestTransactionList(t *testing.T) {
 			Value:       big.NewInt(41),
 			Nonce:       32,
 			Description: "test2",
+			Priority:    transaction.PriorityPostage,
+			Bumps:       1,
 		},
 	}
+	queued := time.Unix(3, 0).UTC()
 
 	testServer, _, _, _ := newTestServer(t, testServerOptions{
 		TransactionOpts: []mock.Option{
@@ -159,6 +163,13 @@ func TestTransactionList(t *testing.T) {
 			mock.WithStoredTransactionFunc(func(txHash common.Hash) (*transaction.StoredTransaction, error) {
 				return storedTransactions[txHash], nil
 			}),
+			mock.WithQueuedTransactionsFunc(func() []transaction.QueuedTransaction {
+				return []transaction.QueuedTransaction{{
+					Priority:    transaction.PriorityRedistribution,
+					Description: "commit transaction",
+					Queued:      queued,
+				}}
+			}),
 		},
 	})
 
@@ -178,6 +189,7 @@ func TestTransactionList(t *testing.T) {
 					Created:         time.Unix(storedTransactions[txHash1].Created, 0),
 					Description:     storedTransactions[txHash1].Description,
 					Value:           bigint.Wrap(storedTransactions[txHash1].Value),
+					Priority:        "chequebook",
 				},
 				{
 					TransactionHash: txHash2,
@@ -192,8 +204,15 @@ func TestTransactionList(t *testing.T) {
 					Created:         time.Unix(storedTransactions[txHash2].Created, 0),
 					Description:     storedTransactions[txHash2].Description,
 					Value:           bigint.Wrap(storedTransactions[txHash2].Value),
+					Priority:        "postage",
+					Bumps:           1,
 				},
 			},
+			QueuedTransactions: []api.QueuedTransactionInfo{{
+				Priority:    "redistribution",
+				Description: "commit transaction",
+				Queued:      queued,
+			}},
 		}),
 	)
 }
@@ -5,17 +5,21 @@
 package api
 
 import (
+	"errors"
 	"math/big"
 	"net/http"
 	"strings"
+	"time"
 
 	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
+	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/gorilla/mux"
 )
 
@@ -59,12 +63,72 @@ type walletTxResponse struct {
 	TransactionHash common.Hash `json:"transactionHash"`
 }
 
+type walletAddressResponse struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+}
+
+type walletAddressesResponse struct {
+	Addresses        []walletAddressResponse `json:"addresses"`
+	RequiresApproval bool                    `json:"requiresApproval"`
+}
+
+type walletWithdrawalResponse struct {
+	ID              string                `json:"id"`
+	Kind            string                `json:"kind"`
+	Coin            string                `json:"coin"`
+	To              walletAddressResponse `json:"to"`
+	Amount          *bigint.BigInt        `json:"amount"`
+	Status          string                `json:"status"`
+	TransactionHash common.Hash           `json:"transactionHash"`
+	Error           string                `json:"error,omitempty"`
+	Approved        bool                  `json:"approved"`
+	Created         time.Time             `json:"created"`
+	Updated         time.Time             `json:"updated"`
+}
+
+type walletWithdrawalsResponse struct {
+	Withdrawals []walletWithdrawalResponse `json:"withdrawals"`
+}
+
+type walletAuditRecordResponse struct {
+	Time            time.Time      `json:"time"`
+	Kind            string         `json:"kind"`
+	Coin            string         `json:"coin"`
+	Amount          *bigint.BigInt `json:"amount"`
+	To              common.Address `json:"to"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	Reference       string         `json:"reference,omitempty"`
+	Withdrawal      string         `json:"withdrawal,omitempty"`
+	Approved        bool           `json:"approved"`
+}
+
+type walletAuditResponse struct {
+	Records []walletAuditRecordResponse `json:"records"`
+}
+
+func newWalletWithdrawalResponse(wd wallet.Withdrawal) walletWithdrawalResponse {
+	return walletWithdrawalResponse{
+		ID:              wd.ID,
+		Kind:            string(wd.Kind),
+		Coin:            wd.Coin,
+		To:              walletAddressResponse{Name: wd.To.Name, Address: wd.To.Address},
+		Amount:          bigint.Wrap(wd.Amount),
+		Status:          wd.Status,
+		TransactionHash: wd.TxHash,
+		Error:           wd.Error,
+		Approved:        wd.Approved,
+		Created:         wd.Created,
+		Updated:         wd.Updated,
+	}
+}
+
 func (s *Service) walletWithdrawHandler(w http.ResponseWriter, r *http.Request) {
 	logger := s.logger.WithName("post_wallet_withdraw").Build()
 
 	queries := struct {
-		Amount  *big.Int        `map:"amount" validate:"required"`
-		Address *common.Address `map:"address" validate:"required"`
+		Amount  *big.Int `map:"amount" validate:"required"`
+		Address string   `map:"address" validate:"required"`
 	}{}
 
 	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
@@ -81,61 +145,91 @@ func (s *Service) walletWithdrawHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var bzz bool
+	var coin string
 
-	if strings.EqualFold("BZZ", *path.Coin) {
-		bzz = true
-	} else if !strings.EqualFold("NativeToken", *path.Coin) {
+	if strings.EqualFold(wallet.CoinBZZ, *path.Coin) {
+		coin = wallet.CoinBZZ
+	} else if strings.EqualFold(wallet.CoinNativeToken, *path.Coin) {
+		coin = wallet.CoinNativeToken
+	} else {
 		jsonhttp.BadRequest(w, "only BZZ or NativeToken options are accepted")
 		return
 	}
 
-	if !slices.Contains(s.whitelistedWithdrawalAddress, *queries.Address) {
+	if s.wallet == nil {
+		to := common.HexToAddress(queries.Address)
+		if !slices.Contains(s.whitelistedWithdrawalAddress, to) {
+			jsonhttp.BadRequest(w, "provided address not whitelisted")
+			return
+		}
+		txHash, err := s.walletTransfer(w, r, logger, coin, to, queries.Amount)
+		if err != nil {
+			return
+		}
+		jsonhttp.OK(w, walletTxResponse{TransactionHash: txHash})
+		return
+	}
+
+	to, err := s.wallet.Resolve(queries.Address)
+	if err != nil {
 		jsonhttp.BadRequest(w, "provided address not whitelisted")
 		return
 	}
 
-	if bzz {
+	wd, ok := s.requestWithdrawal(w, logger, wallet.KindWalletWithdraw, coin, to, queries.Amount)
+	if !ok {
+		return
+	}
+	if wd.Status == wallet.StatusPending {
+		jsonhttp.Accepted(w, newWalletWithdrawalResponse(wd))
+		return
+	}
+	s.sendWithdrawal(w, r, logger, wd)
+}
+
+// walletTransfer transfers the amount of the coin from the node wallet to
+// the address. The failures are responded to and returned.
+func (s *Service) walletTransfer(w http.ResponseWriter, r *http.Request, logger log.Logger, coin string, to common.Address, amount *big.Int) (common.Hash, error) {
+	if coin == wallet.CoinBZZ {
 		currentBalance, err := s.erc20Service.BalanceOf(r.Context(), s.ethereumAddress)
 		if err != nil {
 			logger.Error(err, "unable to get balance")
 			jsonhttp.InternalServerError(w, "unable to get balance")
-			return
+			return common.Hash{}, err
 		}
 
-		if queries.Amount.Cmp(currentBalance) > 0 {
-			logger.Error(err, "not enough balance")
+		if amount.Cmp(currentBalance) > 0 {
+			logger.Error(nil, "not enough balance")
 			jsonhttp.BadRequest(w, "not enough balance")
-			return
+			return common.Hash{}, errors.New("not enough balance")
 		}
 
-		txHash, err := s.erc20Service.Transfer(r.Context(), *queries.Address, queries.Amount)
+		txHash, err := s.erc20Service.Transfer(r.Context(), to, amount)
 		if err != nil {
 			logger.Error(err, "unable to transfer")
 			jsonhttp.InternalServerError(w, "unable to transfer amount")
-			return
+			return common.Hash{}, err
 		}
-		jsonhttp.OK(w, walletTxResponse{TransactionHash: txHash})
-		return
+		return txHash, nil
 	}
 
 	nativeToken, err := s.chainBackend.BalanceAt(r.Context(), s.ethereumAddress, nil)
 	if err != nil {
 		logger.Error(err, "unable to acquire balance from the chain backend")
 		jsonhttp.InternalServerError(w, "unable to acquire balance from the chain backend")
-		return
+		return common.Hash{}, err
 	}
 
-	if queries.Amount.Cmp(nativeToken) > 0 {
+	if amount.Cmp(nativeToken) > 0 {
 		jsonhttp.BadRequest(w, "not enough balance")
-		return
+		return common.Hash{}, errors.New("not enough balance")
 	}
 
 	req := &transaction.TxRequest{
-		To:          queries.Address,
+		To:          &to,
 		GasPrice:    sctx.GetGasPrice(r.Context()),
 		GasLimit:    sctx.GetGasLimitWithDefault(r.Context(), 300_000),
-		Value:       queries.Amount,
+		Value:       amount,
 		Description: "native token withdraw",
 	}
 
@@ -143,8 +237,189 @@ func (s *Service) walletWithdrawHandler(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		logger.Error(err, "unable to transfer")
 		jsonhttp.InternalServerError(w, "unable to transfer")
-		return
+		return common.Hash{}, err
 	}
+	return txHash, nil
+}
 
+// requestWithdrawal requests the withdrawal from the wallet policy. The
+// failures are responded to.
+func (s *Service) requestWithdrawal(w http.ResponseWriter, logger log.Logger, kind wallet.Kind, coin string, to wallet.Address, amount *big.Int) (wallet.Withdrawal, bool) {
+	wd, err := s.wallet.Request(kind, coin, to, amount)
+	if errors.Is(err, wallet.ErrDailyCapExceeded) {
+		logger.Debug("withdrawal request failed", "kind", kind, "coin", coin, "amount", amount, "error", err)
+		jsonhttp.BadRequest(w, "daily spend cap exceeded")
+		return wallet.Withdrawal{}, false
+	}
+	if err != nil {
+		logger.Debug("withdrawal request failed", "kind", kind, "coin", coin, "amount", amount, "error", err)
+		logger.Error(nil, "withdrawal request failed")
+		jsonhttp.InternalServerError(w, "unable to request withdrawal")
+		return wallet.Withdrawal{}, false
+	}
+	return wd, true
+}
+
+// sendWithdrawal sends the approved withdrawal and records its outcome.
+func (s *Service) sendWithdrawal(w http.ResponseWriter, r *http.Request, logger log.Logger, wd wallet.Withdrawal) {
+	var (
+		txHash common.Hash
+		err    error
+	)
+	switch wd.Kind {
+	case wallet.KindChequebookWithdraw:
+		txHash, err = s.chequebookWithdraw(w, r, logger, wd.Amount)
+	default:
+		txHash, err = s.walletTransfer(w, r, logger, wd.Coin, wd.To.Address, wd.Amount)
+	}
+
+	if _, cerr := s.wallet.Complete(wd.ID, txHash, err); cerr != nil {
+		logger.Error(cerr, "unable to record the withdrawal outcome", "id", wd.ID, "tx", txHash)
+	}
+	if err != nil {
+		return
+	}
 	jsonhttp.OK(w, walletTxResponse{TransactionHash: txHash})
 }
+
+// recordTransfer adds the outgoing value transfer to the audit log of the
+// wallet policy, if there is one.
+func (s *Service) recordTransfer(logger log.Logger, kind wallet.Kind, to common.Address, amount *big.Int, txHash common.Hash, reference string) {
+	if s.wallet == nil {
+		return
+	}
+	if err := s.wallet.Record(kind, wallet.CoinBZZ, to, amount, txHash, reference); err != nil {
+		logger.Error(err, "unable to record the transfer", "kind", kind, "tx", txHash)
+	}
+}
+
+func (s *Service) walletAddressesHandler(w http.ResponseWriter, _ *http.Request) {
+	resp := walletAddressesResponse{
+		Addresses:        make([]walletAddressResponse, 0),
+		RequiresApproval: s.wallet.RequiresApproval(),
+	}
+	for _, a := range s.wallet.Addresses() {
+		resp.Addresses = append(resp.Addresses, walletAddressResponse{Name: a.Name, Address: a.Address})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) walletWithdrawalsHandler(w http.ResponseWriter, _ *http.Request) {
+	logger := s.logger.WithName("get_wallet_withdrawals").Build()
+
+	withdrawals, err := s.wallet.Withdrawals()
+	if err != nil {
+		logger.Debug("get withdrawals failed", "error", err)
+		logger.Error(nil, "get withdrawals failed")
+		jsonhttp.InternalServerError(w, "unable to get withdrawals")
+		return
+	}
+
+	resp := walletWithdrawalsResponse{Withdrawals: make([]walletWithdrawalResponse, 0, len(withdrawals))}
+	for _, wd := range withdrawals {
+		resp.Withdrawals = append(resp.Withdrawals, newWalletWithdrawalResponse(wd))
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *Service) walletApproveHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("post_wallet_withdrawal_approve").Build()
+
+	paths := struct {
+		ID string `map:"id" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	headers := struct {
+		Token string `map:"Swarm-Approval-Token" validate:"required"`
+	}{}
+	if response := s.mapStructure(r.Header, &headers); response != nil {
+		response("invalid header params", logger, w)
+		return
+	}
+
+	wd, err := s.wallet.Approve(paths.ID, headers.Token)
+	if !s.respondWithdrawalError(w, logger, err) {
+		return
+	}
+	s.sendWithdrawal(w, r, logger, wd)
+}
+
+func (s *Service) walletRejectHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("delete_wallet_withdrawal").Build()
+
+	paths := struct {
+		ID string `map:"id" validate:"required"`
+	}{}
+	if response := s.mapStructure(mux.Vars(r), &paths); response != nil {
+		response("invalid path params", logger, w)
+		return
+	}
+
+	wd, err := s.wallet.Reject(paths.ID)
+	if !s.respondWithdrawalError(w, logger, err) {
+		return
+	}
+	jsonhttp.OK(w, newWalletWithdrawalResponse(wd))
+}
+
+// respondWithdrawalError responds to the error of a withdrawal update and
+// reports whether there was none.
+func (s *Service) respondWithdrawalError(w http.ResponseWriter, logger log.Logger, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, wallet.ErrInvalidApprovalToken):
+		jsonhttp.Forbidden(w, "invalid approval token")
+	case errors.Is(err, wallet.ErrNotFound):
+		jsonhttp.NotFound(w, "withdrawal not found")
+	case errors.Is(err, wallet.ErrNotPending):
+		jsonhttp.BadRequest(w, "withdrawal not pending")
+	default:
+		logger.Debug("withdrawal update failed", "error", err)
+		logger.Error(nil, "withdrawal update failed")
+		jsonhttp.InternalServerError(w, "unable to update withdrawal")
+	}
+	return false
+}
+
+func (s *Service) walletAuditHandler(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.WithName("get_wallet_audit").Build()
+
+	queries := struct {
+		From int64 `map:"from"`
+		To   int64 `map:"to"`
+	}{}
+	if response := s.mapStructure(r.URL.Query(), &queries); response != nil {
+		response("invalid query params", logger, w)
+		return
+	}
+
+	from, to := ledgerTimeRange(queries.From, queries.To)
+	records, err := s.wallet.Audit(from, to)
+	if err != nil {
+		logger.Debug("get audit log failed", "error", err)
+		logger.Error(nil, "get audit log failed")
+		jsonhttp.InternalServerError(w, "unable to get audit log")
+		return
+	}
+
+	resp := walletAuditResponse{Records: make([]walletAuditRecordResponse, 0, len(records))}
+	for _, rec := range records {
+		resp.Records = append(resp.Records, walletAuditRecordResponse{
+			Time:            rec.Time,
+			Kind:            string(rec.Kind),
+			Coin:            rec.Coin,
+			Amount:          bigint.Wrap(rec.Amount),
+			To:              rec.To,
+			TransactionHash: rec.TxHash,
+			Reference:       rec.Reference,
+			Withdrawal:      rec.Withdrawal,
+			Approved:        rec.Approved,
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
@@ -15,10 +15,14 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/bigint"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp"
 	"github.com/ethersphere/bee/v2/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/v2/pkg/log"
 	erc20mock "github.com/ethersphere/bee/v2/pkg/settlement/swap/erc20/mock"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	stakingContractMock "github.com/ethersphere/bee/v2/pkg/storageincentives/staking/mock"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
 	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
 	transactionmock "github.com/ethersphere/bee/v2/pkg/transaction/mock"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 )
 
 func TestWallet(t *testing.T) {
@@ -277,3 +281,114 @@ func TestWalletWithdraw(t *testing.T) {
 			}))
 	})
 }
+
+func TestWalletPolicy(t *testing.T) {
+	t.Parallel()
+
+	treasury := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	stakingAddress := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	txHash := common.HexToHash("0x00f")
+	stakeTxHash := common.HexToHash("0x0ff")
+
+	w := wallet.New(log.Noop, statestore.NewStateStore(), wallet.Options{
+		Addresses:     []wallet.Address{{Name: "treasury", Address: treasury}},
+		DailyCaps:     map[string]*big.Int{wallet.CoinBZZ: big.NewInt(100)},
+		ApprovalToken: "secret",
+	})
+	srv, _, _, _ := newTestServer(t, testServerOptions{
+		Wallet: w,
+		StakingContract: stakingContractMock.New(
+			stakingContractMock.WithAddress(stakingAddress),
+			stakingContractMock.WithDepositStake(func(context.Context, *big.Int) (common.Hash, error) {
+				return stakeTxHash, nil
+			}),
+		),
+		Erc20Opts: []erc20mock.Option{
+			erc20mock.WithBalanceOfFunc(func(ctx context.Context, address common.Address) (*big.Int, error) {
+				return big.NewInt(1000), nil
+			}),
+			erc20mock.WithTransferFunc(func(ctx context.Context, address common.Address, value *big.Int) (common.Hash, error) {
+				if address != treasury {
+					t.Errorf("want addr %s, got %s", treasury, address)
+				}
+				return txHash, nil
+			}),
+		},
+	})
+
+	jsonhttptest.Request(t, srv, http.MethodGet, "/wallet/addresses", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.WalletAddressesResponse{
+			Addresses:        []api.WalletAddressResponse{{Name: "treasury", Address: treasury}},
+			RequiresApproval: true,
+		}))
+
+	jsonhttptest.Request(t, srv, http.MethodPost, "/wallet/withdraw/BZZ?address=exchange&amount=60", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "provided address not whitelisted",
+			Code:    400,
+		}))
+
+	jsonhttptest.Request(t, srv, http.MethodPost, "/wallet/withdraw/BZZ?address=treasury&amount=101", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "daily spend cap exceeded",
+			Code:    400,
+		}))
+
+	var pending api.WalletWithdrawalResponse
+	jsonhttptest.Request(t, srv, http.MethodPost, "/wallet/withdraw/BZZ?address=treasury&amount=60", http.StatusAccepted,
+		jsonhttptest.WithUnmarshalJSONResponse(&pending),
+	)
+	if pending.Status != wallet.StatusPending || pending.To.Name != "treasury" {
+		t.Fatalf("got withdrawal %+v", pending)
+	}
+
+	jsonhttptest.Request(t, srv, http.MethodPost, "/wallet/withdrawals/"+pending.ID+"/approve", http.StatusForbidden,
+		jsonhttptest.WithRequestHeader(api.SwarmApprovalTokenHeader, "wrong"),
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "invalid approval token",
+			Code:    403,
+		}))
+
+	jsonhttptest.Request(t, srv, http.MethodPost, "/wallet/withdrawals/"+pending.ID+"/approve", http.StatusOK,
+		jsonhttptest.WithRequestHeader(api.SwarmApprovalTokenHeader, "secret"),
+		jsonhttptest.WithExpectedJSONResponse(api.WalletTxResponse{
+			TransactionHash: txHash,
+		}))
+
+	jsonhttptest.Request(t, srv, http.MethodDelete, "/wallet/withdrawals/"+pending.ID, http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "withdrawal not pending",
+			Code:    400,
+		}))
+
+	var withdrawals api.WalletWithdrawalsResponse
+	jsonhttptest.Request(t, srv, http.MethodGet, "/wallet/withdrawals", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&withdrawals),
+	)
+	if len(withdrawals.Withdrawals) != 1 || withdrawals.Withdrawals[0].Status != wallet.StatusSent || !withdrawals.Withdrawals[0].Approved {
+		t.Fatalf("got withdrawals %+v", withdrawals.Withdrawals)
+	}
+
+	var audit api.WalletAuditResponse
+	jsonhttptest.Request(t, srv, http.MethodGet, "/wallet/audit", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&audit),
+	)
+	if len(audit.Records) != 1 {
+		t.Fatalf("got %d audit records, want 1", len(audit.Records))
+	}
+	if r := audit.Records[0]; r.Withdrawal != pending.ID || r.TransactionHash != txHash || r.Amount.Int64() != 60 || !r.Approved {
+		t.Fatalf("got audit record %+v", r)
+	}
+
+	// the stake deposits are recorded with the address of the staking contract
+	jsonhttptest.Request(t, srv, http.MethodPost, "/stake/100000000000000000", http.StatusOK)
+	jsonhttptest.Request(t, srv, http.MethodGet, "/wallet/audit", http.StatusOK,
+		jsonhttptest.WithUnmarshalJSONResponse(&audit),
+	)
+	if len(audit.Records) != 2 {
+		t.Fatalf("got %d audit records, want 2", len(audit.Records))
+	}
+	if r := audit.Records[1]; r.Kind != string(wallet.KindStakeDeposit) || r.To != stakingAddress || r.TransactionHash != stakeTxHash {
+		t.Fatalf("got audit record %+v", r)
+	}
+}
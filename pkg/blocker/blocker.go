@@ -11,6 +11,7 @@ import (
 
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"go.uber.org/atomic"
 )
@@ -39,6 +40,7 @@ type Blocker struct {
 	quit              chan struct{}
 	closeWg           sync.WaitGroup
 	blocklistCallback func(swarm.Address)
+	reputation        reputation.Recorder
 }
 
 func New(blocklister p2p.Blocklister, flagTimeout, blockDuration, wakeUpTime time.Duration, callback func(swarm.Address), logger log.Logger) *Blocker {
@@ -115,6 +117,14 @@ func (b *Blocker) block() {
 	}
 }
 
+// SetReputation makes the blocker record the flagged peers.
+func (b *Blocker) SetReputation(r reputation.Recorder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reputation = r
+}
+
 func (b *Blocker) Flag(addr swarm.Address) {
 	if b.blocklister.NetworkStatus() != p2p.NetworkStatusAvailable {
 		return
@@ -123,6 +133,10 @@ func (b *Blocker) Flag(addr swarm.Address) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.reputation != nil {
+		b.reputation.Record(addr, reputation.SignalFlag)
+	}
+
 	if _, ok := b.peers[addr.ByteString()]; !ok {
 		b.peers[addr.ByteString()] = &peer{
 			blockAfter: b.sequence.Load() + uint64(b.flagTimeout/sequencerResolution),
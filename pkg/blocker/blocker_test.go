@@ -14,6 +14,8 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/blocker"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
+	mockstate "github.com/ethersphere/bee/v2/pkg/statestore/mock"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/util/testutil"
 )
@@ -130,6 +132,31 @@ func TestPruneBeforeBlock(t *testing.T) {
 	time.Sleep(flagTime * 3)
 }
 
+func TestFlagRecordsReputation(t *testing.T) {
+	t.Parallel()
+
+	addr := swarm.RandAddress(t)
+	mock := mockBlockLister(func(a swarm.Address, d time.Duration, r string) error {
+		return nil
+	})
+
+	rep, err := reputation.New(mockstate.NewStateStore(), log.Noop, reputation.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, rep)
+
+	b := blocker.New(mock, flagTime, blockTime, time.Millisecond, nil, log.Noop)
+	testutil.CleanupCloser(t, b)
+	b.SetReputation(rep)
+
+	b.Flag(addr)
+
+	if score := rep.Score(addr); score >= 0 {
+		t.Fatalf("got score %v of flagged peer, want negative", score)
+	}
+}
+
 type blocklister struct {
 	blocklistFunc func(swarm.Address, time.Duration, string) error
 }
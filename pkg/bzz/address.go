@@ -24,26 +24,40 @@ import (
 
 var ErrInvalidAddress = errors.New("invalid address")
 
+// underlayListPrefix marks the serialized lists of multiple underlays. It is
+// not a valid start of a binary multiaddr, so a single underlay keeps being
+// serialized as the plain multiaddr. The lists travel in the optional fields
+// of the handshake and hive messages, next to the first underlay which the
+// older peers read.
+const underlayListPrefix byte = 0x99
+
 // Address represents the bzz address in swarm.
-// It consists of a peers underlay (physical) address, overlay (topology) address and signature.
-// Signature is used to verify the `Overlay/Underlay` pair, as it is based on `underlay|networkID`, signed with the public key of Overlay address
+// It consists of a peers underlay (physical) addresses, overlay (topology) address and signature.
+// Signature is used to verify the `Overlay/Underlay` pair of the first underlay, as it is based on `underlay|networkID`, signed with the public key of Overlay address
+// UnderlaysSignature verifies the list of all the underlays in the same way if there are more of them.
 type Address struct {
-	Underlay        ma.Multiaddr
-	Overlay         swarm.Address
-	Signature       []byte
-	Nonce           []byte
-	EthereumAddress []byte
+	Underlays          []ma.Multiaddr
+	Overlay            swarm.Address
+	Signature          []byte
+	UnderlaysSignature []byte
+	Nonce              []byte
+	EthereumAddress    []byte
 }
 
 type addressJSON struct {
-	Overlay   string `json:"overlay"`
-	Underlay  string `json:"underlay"`
-	Signature string `json:"signature"`
-	Nonce     string `json:"transaction"`
+	Overlay            string   `json:"overlay"`
+	Underlay           string   `json:"underlay"`
+	Underlays          []string `json:"underlays,omitempty"`
+	Signature          string   `json:"signature"`
+	UnderlaysSignature string   `json:"underlaysSignature,omitempty"`
+	Nonce              string   `json:"transaction"`
 }
 
-func NewAddress(signer crypto.Signer, underlay ma.Multiaddr, overlay swarm.Address, networkID uint64, nonce []byte) (*Address, error) {
-	underlayBinary, err := underlay.MarshalBinary()
+func NewAddress(signer crypto.Signer, underlays []ma.Multiaddr, overlay swarm.Address, networkID uint64, nonce []byte) (*Address, error) {
+	if len(underlays) == 0 {
+		return nil, ErrInvalidAddress
+	}
+	underlayBinary, err := underlays[0].MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -53,11 +67,24 @@ func NewAddress(signer crypto.Signer, underlay ma.Multiaddr, overlay swarm.Addre
 		return nil, err
 	}
 
+	var underlaysSignature []byte
+	if len(underlays) > 1 {
+		underlaysBinary, err := SerializeUnderlays(underlays)
+		if err != nil {
+			return nil, err
+		}
+		underlaysSignature, err = signer.Sign(generateSignData(underlaysBinary, overlay.Bytes(), networkID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Address{
-		Underlay:  underlay,
-		Overlay:   overlay,
-		Signature: signature,
-		Nonce:     nonce,
+		Underlays:          underlays,
+		Overlay:            overlay,
+		Signature:          signature,
+		UnderlaysSignature: underlaysSignature,
+		Nonce:              nonce,
 	}, nil
 }
 
@@ -77,7 +104,7 @@ func ParseAddress(underlay, overlay, signature, nonce []byte, validateOverlay bo
 		}
 	}
 
-	multiUnderlay, err := ma.NewMultiaddrBytes(underlay)
+	underlays, err := DeserializeUnderlays(underlay)
 	if err != nil {
 		return nil, ErrInvalidAddress
 	}
@@ -88,7 +115,7 @@ func ParseAddress(underlay, overlay, signature, nonce []byte, validateOverlay bo
 	}
 
 	return &Address{
-		Underlay:        multiUnderlay,
+		Underlays:       underlays,
 		Overlay:         swarm.NewAddress(overlay),
 		Signature:       signature,
 		Nonce:           nonce,
@@ -96,6 +123,47 @@ func ParseAddress(underlay, overlay, signature, nonce []byte, validateOverlay bo
 	}, nil
 }
 
+// ParseUnderlays verifies the signed list of the underlays of the parsed
+// address and replaces the underlays of the address with it. The list must
+// start with the first underlay of the address.
+func (a *Address) ParseUnderlays(underlays, signature []byte, networkID uint64) error {
+	recoveredPK, err := crypto.Recover(signature, generateSignData(underlays, a.Overlay.Bytes(), networkID))
+	if err != nil {
+		return ErrInvalidAddress
+	}
+	ethAddress, err := crypto.NewEthereumAddress(*recoveredPK)
+	if err != nil || !bytes.Equal(ethAddress, a.EthereumAddress) {
+		return ErrInvalidAddress
+	}
+
+	list, err := DeserializeUnderlays(underlays)
+	if err != nil || len(a.Underlays) == 0 || !multiaddrEqual(list[0], a.Underlays[0]) {
+		return ErrInvalidAddress
+	}
+
+	a.Underlays = list
+	a.UnderlaysSignature = signature
+	return nil
+}
+
+// WireUnderlays returns the first underlay of the address, which is signed
+// by the signature, and the serialized list of the underlays, which is signed
+// by the underlays signature. The list is nil if it is not signed.
+func (a *Address) WireUnderlays() (underlay, underlays []byte, err error) {
+	if len(a.Underlays) == 0 {
+		return nil, nil, ErrInvalidAddress
+	}
+	if underlay, err = a.Underlays[0].MarshalBinary(); err != nil {
+		return nil, nil, err
+	}
+	if len(a.Underlays) > 1 && len(a.UnderlaysSignature) > 0 {
+		if underlays, err = SerializeUnderlays(a.Underlays); err != nil {
+			return nil, nil, err
+		}
+	}
+	return underlay, underlays, nil
+}
+
 func generateSignData(underlay, overlay []byte, networkID uint64) []byte {
 	networkIDBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(networkIDBytes, networkID)
@@ -109,7 +177,20 @@ func (a *Address) Equal(b *Address) bool {
 		return a == b
 	}
 
-	return a.Overlay.Equal(b.Overlay) && multiaddrEqual(a.Underlay, b.Underlay) && bytes.Equal(a.Signature, b.Signature) && bytes.Equal(a.Nonce, b.Nonce)
+	return a.Overlay.Equal(b.Overlay) && UnderlaysEqual(a.Underlays, b.Underlays) && bytes.Equal(a.Signature, b.Signature) && bytes.Equal(a.UnderlaysSignature, b.UnderlaysSignature) && bytes.Equal(a.Nonce, b.Nonce)
+}
+
+// UnderlaysEqual reports whether the underlay lists hold the same addresses in the same order.
+func UnderlaysEqual(a, b []ma.Multiaddr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !multiaddrEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func multiaddrEqual(a, b ma.Multiaddr) bool {
@@ -120,13 +201,78 @@ func multiaddrEqual(a, b ma.Multiaddr) bool {
 	return a.Equal(b)
 }
 
+// SerializeUnderlays returns the binary form of the underlays which is signed
+// and transmitted in the handshake and hive messages.
+func SerializeUnderlays(underlays []ma.Multiaddr) ([]byte, error) {
+	if len(underlays) == 0 {
+		return nil, ErrInvalidAddress
+	}
+	if len(underlays) == 1 {
+		return underlays[0].MarshalBinary()
+	}
+
+	b := []byte{underlayListPrefix}
+	for _, u := range underlays {
+		ub, err := u.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = binary.AppendUvarint(b, uint64(len(ub)))
+		b = append(b, ub...)
+	}
+	return b, nil
+}
+
+// DeserializeUnderlays parses the binary form of the underlays.
+func DeserializeUnderlays(b []byte) ([]ma.Multiaddr, error) {
+	if len(b) == 0 {
+		return nil, ErrInvalidAddress
+	}
+	if b[0] != underlayListPrefix {
+		u, err := ma.NewMultiaddrBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		return []ma.Multiaddr{u}, nil
+	}
+
+	var underlays []ma.Multiaddr
+	for b = b[1:]; len(b) > 0; {
+		l, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < l {
+			return nil, ErrInvalidAddress
+		}
+		u, err := ma.NewMultiaddrBytes(b[n : n+int(l)])
+		if err != nil {
+			return nil, err
+		}
+		underlays = append(underlays, u)
+		b = b[n+int(l):]
+	}
+	if len(underlays) == 0 {
+		return nil, ErrInvalidAddress
+	}
+	return underlays, nil
+}
+
 func (a *Address) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&addressJSON{
+	v := &addressJSON{
 		Overlay:   a.Overlay.String(),
-		Underlay:  a.Underlay.String(),
 		Signature: base64.StdEncoding.EncodeToString(a.Signature),
 		Nonce:     common.Bytes2Hex(a.Nonce),
-	})
+	}
+	if len(a.Underlays) > 0 {
+		v.Underlay = a.Underlays[0].String()
+	}
+	if len(a.Underlays) > 1 {
+		for _, u := range a.Underlays {
+			v.Underlays = append(v.Underlays, u.String())
+		}
+	}
+	if len(a.UnderlaysSignature) > 0 {
+		v.UnderlaysSignature = base64.StdEncoding.EncodeToString(a.UnderlaysSignature)
+	}
+	return json.Marshal(v)
 }
 
 func (a *Address) UnmarshalJSON(b []byte) error {
@@ -143,23 +289,38 @@ func (a *Address) UnmarshalJSON(b []byte) error {
 
 	a.Overlay = addr
 
-	m, err := ma.NewMultiaddr(v.Underlay)
-	if err != nil {
-		return err
+	underlays := v.Underlays
+	if len(underlays) == 0 {
+		underlays = []string{v.Underlay}
+	}
+	a.Underlays = make([]ma.Multiaddr, 0, len(underlays))
+	for _, u := range underlays {
+		m, err := ma.NewMultiaddr(u)
+		if err != nil {
+			return err
+		}
+		a.Underlays = append(a.Underlays, m)
 	}
 
-	a.Underlay = m
 	a.Signature, err = base64.StdEncoding.DecodeString(v.Signature)
+	if err != nil {
+		return err
+	}
+	if v.UnderlaysSignature != "" {
+		if a.UnderlaysSignature, err = base64.StdEncoding.DecodeString(v.UnderlaysSignature); err != nil {
+			return err
+		}
+	}
 	a.Nonce = common.Hex2Bytes(v.Nonce)
-	return err
+	return nil
 }
 
 func (a *Address) String() string {
-	return fmt.Sprintf("[Underlay: %v, Overlay %v, Signature %x, Transaction %x]", a.Underlay, a.Overlay, a.Signature, a.Nonce)
+	return fmt.Sprintf("[Underlays: %v, Overlay %v, Signature %x, Transaction %x]", a.Underlays, a.Overlay, a.Signature, a.Nonce)
 }
 
-// ShortString returns shortened versions of bzz address in a format: [Overlay, Underlay]
+// ShortString returns shortened versions of bzz address in a format: [Overlay, Underlays]
 // It can be used for logging
 func (a *Address) ShortString() string {
-	return fmt.Sprintf("[Overlay: %s, Underlay: %s]", a.Overlay.String(), a.Underlay.String())
+	return fmt.Sprintf("[Overlay: %s, Underlays: %v]", a.Overlay.String(), a.Underlays)
 }
@@ -5,6 +5,8 @@
 package bzz_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -35,7 +37,7 @@ func TestBzzAddress(t *testing.T) {
 	}
 	signer1 := crypto.NewDefaultSigner(privateKey1)
 
-	bzzAddress, err := bzz.NewAddress(signer1, node1ma, overlay, 3, nonce)
+	bzzAddress, err := bzz.NewAddress(signer1, []ma.Multiaddr{node1ma}, overlay, 3, nonce)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -63,3 +65,133 @@ func TestBzzAddress(t *testing.T) {
 		t.Fatalf("got %s expected %s", newbzz, bzzAddress)
 	}
 }
+
+func TestBzzAddressUnderlays(t *testing.T) {
+	t.Parallel()
+
+	var underlays []ma.Multiaddr
+	for _, s := range []string{
+		"/ip4/127.0.0.1/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA",
+		"/ip6/::1/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA",
+		"/dns4/bee.example.com/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA",
+	} {
+		u, err := ma.NewMultiaddr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		underlays = append(underlays, u)
+	}
+
+	t.Run("serialization", func(t *testing.T) {
+		t.Parallel()
+
+		single, err := bzz.SerializeUnderlays(underlays[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(single, underlays[0].Bytes()) {
+			t.Fatal("single underlay is not serialized as a plain multiaddr")
+		}
+
+		for _, want := range [][]ma.Multiaddr{underlays[:1], underlays} {
+			b, err := bzz.SerializeUnderlays(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bzz.DeserializeUnderlays(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bzz.UnderlaysEqual(got, want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		}
+
+		if _, err := bzz.DeserializeUnderlays([]byte{0x99, 0x10, 0x04}); err == nil {
+			t.Fatal("expected error for a truncated underlay list")
+		}
+		if _, err := bzz.SerializeUnderlays(nil); !errors.Is(err, bzz.ErrInvalidAddress) {
+			t.Fatalf("got error %v, want %v", err, bzz.ErrInvalidAddress)
+		}
+	})
+
+	t.Run("signature and json", func(t *testing.T) {
+		t.Parallel()
+
+		nonce := common.HexToHash("0x2").Bytes()
+		privateKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		overlay, err := crypto.NewOverlayAddress(privateKey.PublicKey, 3, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bzzAddress, err := bzz.NewAddress(crypto.NewDefaultSigner(privateKey), underlays, overlay, 3, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the older peers read the first underlay only
+		underlay, list, err := bzzAddress.WireUnderlays()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(underlay, underlays[0].Bytes()) {
+			t.Fatal("first underlay is not sent as a plain multiaddr")
+		}
+		parsed, err := bzz.ParseAddress(underlay, overlay.Bytes(), bzzAddress.Signature, nonce, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bzz.UnderlaysEqual(parsed.Underlays, underlays[:1]) {
+			t.Fatalf("got underlays %v, want %v", parsed.Underlays, underlays[:1])
+		}
+
+		if err := parsed.ParseUnderlays(list, bzzAddress.UnderlaysSignature, 3); err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equal(bzzAddress) {
+			t.Fatalf("got %s expected %s", parsed, bzzAddress)
+		}
+
+		// the list signature covers the whole list
+		parsed, err = bzz.ParseAddress(underlay, overlay.Bytes(), bzzAddress.Signature, nonce, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partial, err := bzz.SerializeUnderlays(underlays[:2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parsed.ParseUnderlays(partial, bzzAddress.UnderlaysSignature, 3); !errors.Is(err, bzz.ErrInvalidAddress) {
+			t.Fatalf("got error %v, want %v", err, bzz.ErrInvalidAddress)
+		}
+
+		// the list must be signed by the owner of the address
+		otherKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := bzz.NewAddress(crypto.NewDefaultSigner(otherKey), underlays, overlay, 3, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parsed.ParseUnderlays(list, other.UnderlaysSignature, 3); !errors.Is(err, bzz.ErrInvalidAddress) {
+			t.Fatalf("got error %v, want %v", err, bzz.ErrInvalidAddress)
+		}
+
+		b, err := bzzAddress.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded bzz.Address
+		if err := decoded.UnmarshalJSON(b); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(bzzAddress) {
+			t.Fatalf("got %s expected %s", &decoded, bzzAddress)
+		}
+	})
+}
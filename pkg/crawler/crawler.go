@@ -0,0 +1,514 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package crawler implements the network crawler of the bootnodes. It walks
+// the network through the peers learned from hive, visits them with pingpong
+// and status requests and keeps a census of the visited peers.
+package crawler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/pingpong"
+	"github.com/ethersphere/bee/v2/pkg/status"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "crawler"
+
+const (
+	defaultInterval        = time.Minute
+	defaultRevisitInterval = 30 * time.Minute
+	defaultExpiry          = 24 * time.Hour
+	defaultParallelism     = 16
+	defaultVisitTimeout    = 15 * time.Second
+	defaultHoldTime        = 2 * time.Second
+
+	// fullMode is the bee mode reported in the status snapshots of full nodes.
+	fullMode = "full"
+	// disconnectReason is reported to the peers dialed only for the census.
+	disconnectReason = "census crawl done"
+)
+
+// Network is the subset of the p2p service used to visit the peers.
+type Network interface {
+	Connect(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error)
+	Disconnect(overlay swarm.Address, reason string) error
+	Peers() []p2p.Peer
+}
+
+// UserAgenter reports the user agents of the connected peers, which carry
+// the version of their software.
+type UserAgenter interface {
+	PeerUserAgent(ctx context.Context, overlay swarm.Address) string
+}
+
+// StatusSnapshotter fetches the status snapshots of the peers.
+type StatusSnapshotter interface {
+	PeerSnapshot(ctx context.Context, peer swarm.Address) (*status.Snapshot, error)
+}
+
+// Options configures the crawler.
+type Options struct {
+	// Interval is the period of the crawl rounds.
+	Interval time.Duration
+	// RevisitInterval is the minimal duration between two visits of a peer.
+	RevisitInterval time.Duration
+	// Expiry is the duration after which the peers that could not be
+	// visited are dropped from the census.
+	Expiry time.Duration
+	// Parallelism is the number of the peers visited concurrently.
+	Parallelism int
+	// VisitTimeout bounds the duration of a single visit.
+	VisitTimeout time.Duration
+	// HoldTime is how long the peers dialed for the census are kept
+	// connected so that their hive broadcasts reach the bootnode.
+	HoldTime time.Duration
+}
+
+// Peer is a census record of a peer.
+type Peer struct {
+	Overlay       swarm.Address
+	Underlays     []string
+	UserAgent     string
+	Version       string
+	FullNode      bool
+	Reachable     bool
+	StorageRadius uint8
+	Latency       time.Duration
+	// Online reports whether the last visit of the peer succeeded.
+	Online    bool
+	FirstSeen time.Time
+	LastSeen  time.Time
+	LastVisit time.Time
+	Failures  int
+}
+
+// Summary aggregates the census.
+type Summary struct {
+	Peers           int
+	Online          int
+	FullNodes       int
+	LightNodes      int
+	Reachable       int
+	Versions        map[string]int
+	StorageRadiuses map[uint8]int
+}
+
+// Census is a snapshot of the census.
+type Census struct {
+	Summary Summary
+	Peers   []Peer
+}
+
+// Crawler walks the network and keeps the census of the peers.
+type Crawler struct {
+	addressbook addressbook.Interface
+	network     Network
+	userAgenter UserAgenter
+	pinger      pingpong.Interface
+	status      StatusSnapshotter
+	logger      log.Logger
+	opts        Options
+	metrics     metrics
+	now         func() time.Time
+
+	mu     sync.RWMutex
+	census map[string]*Peer
+	// failed holds the last visits of the peers that have never been
+	// visited successfully, so they are not redialed in every round.
+	failed map[string]time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates the crawler of the network.
+func New(
+	addressbook addressbook.Interface,
+	network Network,
+	userAgenter UserAgenter,
+	pinger pingpong.Interface,
+	status StatusSnapshotter,
+	logger log.Logger,
+	o Options,
+) *Crawler {
+	if o.Interval <= 0 {
+		o.Interval = defaultInterval
+	}
+	if o.RevisitInterval <= 0 {
+		o.RevisitInterval = defaultRevisitInterval
+	}
+	if o.Expiry <= 0 {
+		o.Expiry = defaultExpiry
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = defaultParallelism
+	}
+	if o.VisitTimeout <= 0 {
+		o.VisitTimeout = defaultVisitTimeout
+	}
+	if o.HoldTime < 0 {
+		o.HoldTime = 0
+	} else if o.HoldTime == 0 {
+		o.HoldTime = defaultHoldTime
+	}
+
+	return &Crawler{
+		addressbook: addressbook,
+		network:     network,
+		userAgenter: userAgenter,
+		pinger:      pinger,
+		status:      status,
+		logger:      logger.WithName(loggerName).Register(),
+		opts:        o,
+		metrics:     newMetrics(),
+		now:         time.Now,
+		census:      make(map[string]*Peer),
+		failed:      make(map[string]time.Time),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Start starts the crawl rounds.
+func (c *Crawler) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			c.crawl()
+			select {
+			case <-c.quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Census returns the current census sorted by the overlay addresses.
+func (c *Crawler) Census() Census {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	census := Census{
+		Summary: summarize(c.census),
+		Peers:   make([]Peer, 0, len(c.census)),
+	}
+	for _, p := range c.census {
+		peer := *p
+		peer.Underlays = append([]string(nil), p.Underlays...)
+		census.Peers = append(census.Peers, peer)
+	}
+	sort.Slice(census.Peers, func(i, j int) bool {
+		return census.Peers[i].Overlay.Compare(census.Peers[j].Overlay) < 0
+	})
+	return census
+}
+
+// Close stops the crawler.
+func (c *Crawler) Close() error {
+	close(c.quit)
+	c.wg.Wait()
+	return nil
+}
+
+// crawl visits the peers that are due for a visit and updates the metrics.
+func (c *Crawler) crawl() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	due := c.duePeers()
+
+	sem := make(chan struct{}, c.opts.Parallelism)
+	var wg sync.WaitGroup
+	for _, target := range due {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(target visitTarget) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.visit(ctx, target)
+		}(target)
+	}
+	wg.Wait()
+
+	c.expire()
+	c.updateMetrics()
+}
+
+type visitTarget struct {
+	overlay   swarm.Address
+	address   *bzz.Address // nil for the connected peers unknown to the address book
+	connected bool
+	fullNode  bool
+}
+
+// duePeers returns the peers known from the address book and the connected
+// peers which were not visited within the revisit interval.
+func (c *Crawler) duePeers() []visitTarget {
+	targets := make(map[string]*visitTarget)
+
+	for _, p := range c.network.Peers() {
+		targets[p.Address.ByteString()] = &visitTarget{overlay: p.Address, connected: true, fullNode: p.FullNode}
+	}
+
+	err := c.addressbook.IterateOverlays(func(overlay swarm.Address) (bool, error) {
+		addr, err := c.addressbook.Get(overlay)
+		if err != nil {
+			return false, nil
+		}
+		if t, ok := targets[overlay.ByteString()]; ok {
+			t.address = addr
+			return false, nil
+		}
+		targets[overlay.ByteString()] = &visitTarget{overlay: overlay, address: addr, fullNode: true}
+		return false, nil
+	})
+	if err != nil {
+		c.logger.Debug("iterate address book failed", "error", err)
+	}
+
+	now := c.now()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	due := make([]visitTarget, 0, len(targets))
+	for key, t := range targets {
+		if p, ok := c.census[key]; ok && now.Sub(p.LastVisit) < c.opts.RevisitInterval {
+			continue
+		}
+		if last, ok := c.failed[key]; ok && now.Sub(last) < c.opts.RevisitInterval {
+			continue
+		}
+		due = append(due, *t)
+	}
+	return due
+}
+
+// visit dials the peer if it is not connected and records its ping latency,
+// status snapshot and user agent in the census.
+func (c *Crawler) visit(ctx context.Context, t visitTarget) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.VisitTimeout)
+	defer cancel()
+
+	c.metrics.Visits.Inc()
+
+	record := Peer{Overlay: t.overlay, FullNode: t.fullNode}
+	if t.address != nil {
+		for _, u := range t.address.Underlays {
+			record.Underlays = append(record.Underlays, u.String())
+		}
+	}
+
+	err := c.inspect(ctx, t, &record)
+	if err != nil {
+		c.metrics.VisitFailures.Inc()
+		c.logger.Debug("census visit failed", "peer_address", t.overlay, "error", err)
+	}
+	c.update(record, err == nil)
+}
+
+// dial connects the peer through the first of its underlays that is reachable.
+func (c *Crawler) dial(ctx context.Context, underlays []ma.Multiaddr) (err error) {
+	err = errors.New("no underlay address")
+	for _, u := range underlays {
+		if _, err = c.network.Connect(ctx, u); err == nil || errors.Is(err, p2p.ErrAlreadyConnected) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Crawler) inspect(ctx context.Context, t visitTarget, record *Peer) error {
+	if !t.connected {
+		if t.address == nil {
+			return errors.New("no underlay address")
+		}
+		err := c.dial(ctx, t.address.Underlays)
+		switch {
+		case errors.Is(err, p2p.ErrAlreadyConnected):
+		case err != nil:
+			return err
+		default:
+			defer func() {
+				select {
+				case <-time.After(c.opts.HoldTime):
+				case <-ctx.Done():
+				}
+				_ = c.network.Disconnect(t.overlay, disconnectReason)
+			}()
+		}
+	}
+
+	rtt, err := c.pinger.Ping(ctx, t.overlay)
+	if err != nil {
+		return err
+	}
+	record.Latency = rtt
+
+	if c.userAgenter != nil {
+		record.UserAgent = c.userAgenter.PeerUserAgent(ctx, t.overlay)
+		record.Version = version(record.UserAgent)
+	}
+
+	snapshot, err := c.status.PeerSnapshot(ctx, t.overlay)
+	if err != nil {
+		return err
+	}
+	record.FullNode = snapshot.BeeMode == fullMode
+	record.Reachable = snapshot.IsReachable
+	record.StorageRadius = uint8(snapshot.StorageRadius)
+
+	return nil
+}
+
+// update stores the outcome of a visit in the census. The details of a failed
+// visit keep the values from the last successful one.
+func (c *Crawler) update(record Peer, ok bool) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := record.Overlay.ByteString()
+	p, found := c.census[key]
+	if !found {
+		if !ok {
+			c.failed[key] = now
+			return
+		}
+		delete(c.failed, key)
+		p = &Peer{Overlay: record.Overlay, FirstSeen: now}
+		c.census[key] = p
+	}
+
+	p.LastVisit = now
+	p.Online = ok
+	if len(record.Underlays) > 0 {
+		p.Underlays = record.Underlays
+	}
+	if !ok {
+		p.Failures++
+		return
+	}
+
+	p.UserAgent = record.UserAgent
+	p.Version = record.Version
+	p.FullNode = record.FullNode
+	p.Reachable = record.Reachable
+	p.StorageRadius = record.StorageRadius
+	p.Latency = record.Latency
+	p.LastSeen = now
+	p.Failures = 0
+}
+
+// expire drops the peers that were not seen within the expiry.
+func (c *Crawler) expire() {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, p := range c.census {
+		if now.Sub(p.LastSeen) > c.opts.Expiry {
+			delete(c.census, key)
+		}
+	}
+	for key, last := range c.failed {
+		if now.Sub(last) > c.opts.Expiry {
+			delete(c.failed, key)
+		}
+	}
+}
+
+func (c *Crawler) updateMetrics() {
+	c.mu.RLock()
+	s := summarize(c.census)
+	c.mu.RUnlock()
+
+	c.metrics.Peers.Set(float64(s.Peers))
+	c.metrics.OnlinePeers.Set(float64(s.Online))
+	c.metrics.FullNodes.Set(float64(s.FullNodes))
+	c.metrics.LightNodes.Set(float64(s.LightNodes))
+	c.metrics.ReachablePeers.Set(float64(s.Reachable))
+
+	c.metrics.PeersByVersion.Reset()
+	for v, n := range s.Versions {
+		c.metrics.PeersByVersion.WithLabelValues(v).Set(float64(n))
+	}
+	c.metrics.PeersByStorageRadius.Reset()
+	for r, n := range s.StorageRadiuses {
+		c.metrics.PeersByStorageRadius.WithLabelValues(strconv.Itoa(int(r))).Set(float64(n))
+	}
+}
+
+// summarize aggregates the online peers of the census.
+func summarize(census map[string]*Peer) Summary {
+	s := Summary{
+		Peers:           len(census),
+		Versions:        make(map[string]int),
+		StorageRadiuses: make(map[uint8]int),
+	}
+	for _, p := range census {
+		if !p.Online {
+			continue
+		}
+		s.Online++
+		if p.FullNode {
+			s.FullNodes++
+			s.StorageRadiuses[p.StorageRadius]++
+		} else {
+			s.LightNodes++
+		}
+		if p.Reachable {
+			s.Reachable++
+		}
+		s.Versions[p.Version]++
+	}
+	return s
+}
+
+// version extracts the bee version from the user agent of a peer,
+// for example 2.3.0-5a7c1b9d from "bee/2.3.0-5a7c1b9d go1.22.5 linux/amd64".
+func version(userAgent string) string {
+	agent, _, _ := strings.Cut(userAgent, " ")
+	v, ok := strings.CutPrefix(agent, "bee/")
+	if !ok {
+		return "unknown"
+	}
+	return v
+}
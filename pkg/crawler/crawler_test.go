@@ -0,0 +1,222 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/crawler"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	pingpongmock "github.com/ethersphere/bee/v2/pkg/pingpong/mock"
+	statestoremock "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/status"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestCrawler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		full     = swarm.RandAddress(t)
+		full2    = swarm.RandAddress(t)
+		light    = swarm.RandAddress(t)
+		offline  = swarm.RandAddress(t)
+		now      = time.Unix(1_700_000_000, 0)
+		pingFail sync.Map
+	)
+
+	ab := addressbook.New(statestoremock.NewStateStore())
+	for i, overlay := range []swarm.Address{full, full2, offline} {
+		underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/" + string(rune('1'+i)) + "634")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ab.Put(overlay, bzz.Address{Overlay: overlay, Underlays: []ma.Multiaddr{underlay}, Signature: []byte{1}, Nonce: []byte{1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	network := &networkMock{
+		peers: []p2p.Peer{{Address: light}},
+	}
+	pinger := pingpongmock.New(func(_ context.Context, peer swarm.Address, _ ...string) (time.Duration, error) {
+		if _, ok := pingFail.Load(peer.ByteString()); ok {
+			return 0, errors.New("ping failed")
+		}
+		return 20 * time.Millisecond, nil
+	})
+	snapshotter := snapshotterFunc(func(_ context.Context, peer swarm.Address) (*status.Snapshot, error) {
+		if peer.Equal(light) {
+			return &status.Snapshot{BeeMode: "light"}, nil
+		}
+		return &status.Snapshot{BeeMode: "full", IsReachable: true, StorageRadius: 10}, nil
+	})
+	userAgenter := userAgenterFunc(func(_ context.Context, peer swarm.Address) string {
+		if peer.Equal(light) {
+			return "bee/2.2.0 go1.22.5 linux/arm64"
+		}
+		return "bee/2.3.0-5a7c1b9d go1.22.5 linux/amd64"
+	})
+
+	c := crawler.New(ab, network, userAgenter, pinger, snapshotter, log.Noop, crawler.Options{HoldTime: -1})
+	c.SetNow(func() time.Time { return now })
+
+	c.Crawl()
+
+	census := c.Census()
+	if len(census.Peers) != 3 {
+		t.Fatalf("got %d peers, want 3", len(census.Peers))
+	}
+	want := crawler.Summary{
+		Peers:           3,
+		Online:          3,
+		FullNodes:       2,
+		LightNodes:      1,
+		Reachable:       2,
+		Versions:        map[string]int{"2.3.0-5a7c1b9d": 2, "2.2.0": 1},
+		StorageRadiuses: map[uint8]int{10: 2},
+	}
+	assertSummary(t, census.Summary, want)
+
+	for _, p := range census.Peers {
+		if p.Overlay.Equal(offline) {
+			t.Fatal("offline peer in the census")
+		}
+		if p.Latency != 20*time.Millisecond || !p.FirstSeen.Equal(now) || !p.LastSeen.Equal(now) {
+			t.Fatalf("unexpected peer record %+v", p)
+		}
+		if !p.Overlay.Equal(light) && len(p.Underlays) != 1 {
+			t.Fatalf("unexpected underlays %v", p.Underlays)
+		}
+	}
+
+	if got := network.disconnectedPeers(); len(got) != 2 {
+		t.Fatalf("got %d disconnected peers, want the 2 dialed ones", len(got))
+	}
+
+	t.Run("peers are not revisited before the revisit interval", func(t *testing.T) {
+		dials := network.dialCount()
+		c.Crawl()
+		if network.dialCount() != dials {
+			t.Fatal("peers were revisited")
+		}
+	})
+
+	t.Run("failed visits mark the peer offline", func(t *testing.T) {
+		pingFail.Store(full.ByteString(), true)
+		now = now.Add(time.Hour)
+		c.Crawl()
+
+		census := c.Census()
+		want.Online, want.FullNodes, want.Reachable = 2, 1, 1
+		want.Versions = map[string]int{"2.3.0-5a7c1b9d": 1, "2.2.0": 1}
+		want.StorageRadiuses = map[uint8]int{10: 1}
+		assertSummary(t, census.Summary, want)
+
+		for _, p := range census.Peers {
+			if p.Overlay.Equal(full) && (p.Online || p.Failures != 1) {
+				t.Fatalf("unexpected peer record %+v", p)
+			}
+		}
+	})
+
+	t.Run("expired peers are dropped", func(t *testing.T) {
+		now = now.Add(24 * time.Hour)
+		c.Crawl()
+
+		census := c.Census()
+		if len(census.Peers) != 2 {
+			t.Fatalf("got %d peers, want 2", len(census.Peers))
+		}
+		for _, p := range census.Peers {
+			if p.Overlay.Equal(full) {
+				t.Fatal("expired peer in the census")
+			}
+		}
+	})
+}
+
+func assertSummary(t *testing.T, got, want crawler.Summary) {
+	t.Helper()
+
+	if got.Peers != want.Peers || got.Online != want.Online || got.FullNodes != want.FullNodes || got.LightNodes != want.LightNodes || got.Reachable != want.Reachable {
+		t.Fatalf("got summary %+v, want %+v", got, want)
+	}
+	if len(got.Versions) != len(want.Versions) || len(got.StorageRadiuses) != len(want.StorageRadiuses) {
+		t.Fatalf("got summary %+v, want %+v", got, want)
+	}
+	for v, n := range want.Versions {
+		if got.Versions[v] != n {
+			t.Fatalf("got versions %v, want %v", got.Versions, want.Versions)
+		}
+	}
+	for r, n := range want.StorageRadiuses {
+		if got.StorageRadiuses[r] != n {
+			t.Fatalf("got storage radiuses %v, want %v", got.StorageRadiuses, want.StorageRadiuses)
+		}
+	}
+}
+
+type networkMock struct {
+	mu           sync.Mutex
+	peers        []p2p.Peer
+	dials        int
+	disconnected []swarm.Address
+}
+
+func (n *networkMock) Connect(_ context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.dials++
+	if addr.String() == "/ip4/127.0.0.1/tcp/3634" {
+		return nil, errors.New("unreachable")
+	}
+	return &bzz.Address{Underlays: []ma.Multiaddr{addr}}, nil
+}
+
+func (n *networkMock) Disconnect(overlay swarm.Address, _ string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.disconnected = append(n.disconnected, overlay)
+	return nil
+}
+
+func (n *networkMock) Peers() []p2p.Peer {
+	return n.peers
+}
+
+func (n *networkMock) dialCount() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.dials
+}
+
+func (n *networkMock) disconnectedPeers() []swarm.Address {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.disconnected
+}
+
+type snapshotterFunc func(ctx context.Context, peer swarm.Address) (*status.Snapshot, error)
+
+func (f snapshotterFunc) PeerSnapshot(ctx context.Context, peer swarm.Address) (*status.Snapshot, error) {
+	return f(ctx, peer)
+}
+
+type userAgenterFunc func(ctx context.Context, peer swarm.Address) string
+
+func (f userAgenterFunc) PeerUserAgent(ctx context.Context, peer swarm.Address) string {
+	return f(ctx, peer)
+}
@@ -0,0 +1,11 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawler
+
+import "time"
+
+func (c *Crawler) Crawl() { c.crawl() }
+
+func (c *Crawler) SetNow(f func() time.Time) { c.now = f }
@@ -0,0 +1,96 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawler
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics groups crawler related prometheus counters.
+type metrics struct {
+	Visits               prometheus.Counter
+	VisitFailures        prometheus.Counter
+	Peers                prometheus.Gauge
+	OnlinePeers          prometheus.Gauge
+	FullNodes            prometheus.Gauge
+	LightNodes           prometheus.Gauge
+	ReachablePeers       prometheus.Gauge
+	PeersByVersion       *prometheus.GaugeVec
+	PeersByStorageRadius *prometheus.GaugeVec
+}
+
+// newMetrics is a convenient constructor for creating new metrics.
+func newMetrics() metrics {
+	const subsystem = "crawler"
+
+	return metrics{
+		Visits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "visits",
+			Help:      "Number of peer visits.",
+		}),
+		VisitFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "visit_failures",
+			Help:      "Number of failed peer visits.",
+		}),
+		Peers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "census_peers",
+			Help:      "Number of peers in the census.",
+		}),
+		OnlinePeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "census_online_peers",
+			Help:      "Number of peers in the census whose last visit succeeded.",
+		}),
+		FullNodes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "census_full_nodes",
+			Help:      "Number of online full nodes in the census.",
+		}),
+		LightNodes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "census_light_nodes",
+			Help:      "Number of online light nodes in the census.",
+		}),
+		ReachablePeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "census_reachable_peers",
+			Help:      "Number of online peers in the census reporting themselves reachable.",
+		}),
+		PeersByVersion: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "census_peers_by_version",
+				Help:      "Number of online peers in the census by the bee version.",
+			},
+			[]string{"version"},
+		),
+		PeersByStorageRadius: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "census_peers_by_storage_radius",
+				Help:      "Number of online full nodes in the census by the storage radius.",
+			},
+			[]string{"radius"},
+		),
+	}
+}
+
+// Metrics returns set of prometheus collectors.
+func (c *Crawler) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(c.metrics)
+}
@@ -16,6 +16,13 @@ type DH interface {
 	SharedKey(public *ecdsa.PublicKey, salt []byte) ([]byte, error)
 }
 
+// BatchDH is implemented by the shared key generations that generate the
+// shared keys for many salts at once, more cheaply than one by one.
+type BatchDH interface {
+	DH
+	SharedKeys(public *ecdsa.PublicKey, salts [][]byte) ([][]byte, error)
+}
+
 type defaultDH struct {
 	key *ecdsa.PrivateKey
 }
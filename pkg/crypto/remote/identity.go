@@ -0,0 +1,53 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remote
+
+import (
+	"crypto/sha256"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/crypto/pb"
+)
+
+var _ libp2pcrypto.PrivKey = (*identity)(nil)
+
+// identity is the libp2p ECDSA private key held by the remote signer.
+// The signatures are compatible with the libp2p ECDSA keys.
+type identity struct {
+	client *Client
+	name   string
+	pub    libp2pcrypto.PubKey
+}
+
+// Sign implements the libp2p crypto.PrivKey interface.
+func (k *identity) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return k.client.sign(k.name, Secp256r1, digest[:])
+}
+
+// GetPublic implements the libp2p crypto.PrivKey interface.
+func (k *identity) GetPublic() libp2pcrypto.PubKey {
+	return k.pub
+}
+
+// Raw implements the libp2p crypto.Key interface. The raw key
+// never leaves the signer, so an error is always returned.
+func (k *identity) Raw() ([]byte, error) {
+	return nil, ErrRawKey
+}
+
+// Type implements the libp2p crypto.Key interface.
+func (k *identity) Type() pb.KeyType {
+	return pb.KeyType_ECDSA
+}
+
+// Equals implements the libp2p crypto.Key interface.
+func (k *identity) Equals(o libp2pcrypto.Key) bool {
+	other, ok := o.(libp2pcrypto.PrivKey)
+	if !ok {
+		return false
+	}
+	return k.pub.Equals(other.GetPublic())
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package remote
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenSocket creates the unix socket with the permissions that allow only
+// the owner to connect to it, so that there is no window in which other users
+// are able to connect.
+func listenSocket(path string) (net.Listener, error) {
+	mask := syscall.Umask(0o177)
+	defer syscall.Umask(mask)
+
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,13 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remote
+
+import "net"
+
+// listenSocket creates the unix socket, which is protected by the access
+// control list of the directory it is created in.
+func listenSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,275 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package remote provides a client and a server for keeping the node keys
+// in an external signer process.
+//
+// The signer holds the private keys and serves only signing and shared key
+// generation over a unix socket restricted to its owner. The client
+// implements keystore.Plugin, so the node never has a raw private key in
+// its memory.
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// serviceName is the name under which the signer is registered on the RPC server.
+const serviceName = "Signer"
+
+// Curve is the elliptic curve of a key held by the signer.
+type Curve string
+
+const (
+	// Secp256k1 is the curve of the swarm, pss and access control keys.
+	Secp256k1 Curve = "secp256k1"
+	// Secp256r1 is the curve of the libp2p identity key.
+	Secp256r1 Curve = "secp256r1"
+)
+
+var (
+	// ErrUnsupportedCurve is returned for a curve the signer does not support.
+	ErrUnsupportedCurve = errors.New("remote signer: unsupported curve")
+	// ErrRawKey is returned when the raw private key is requested.
+	ErrRawKey = errors.New("remote signer: raw private key is not available")
+	// ErrInvalidEndpoint is returned for an endpoint that is not a unix socket.
+	ErrInvalidEndpoint = errors.New("remote signer: endpoint must be a unix socket")
+)
+
+// KeyRequest is the request for the public key of a named key.
+type KeyRequest struct {
+	Name  string
+	Curve Curve
+}
+
+// KeyResponse holds the encoded public key.
+type KeyResponse struct {
+	PublicKey []byte
+}
+
+// SignRequest is the request to sign a digest with a named key.
+type SignRequest struct {
+	Name   string
+	Curve  Curve
+	Digest []byte
+}
+
+// SignResponse holds the signature. Secp256k1 signatures are in the ethereum
+// (r,s,v) format, secp256r1 signatures are ASN.1 encoded.
+type SignResponse struct {
+	Signature []byte
+}
+
+// SharedKeyRequest is the request to generate a shared key of a named key
+// and a secp256k1 public key, hashed with the salt.
+type SharedKeyRequest struct {
+	Name      string
+	PublicKey []byte
+	Salt      []byte
+}
+
+// SharedKeyResponse holds the shared key.
+type SharedKeyResponse struct {
+	Key []byte
+}
+
+// SharedKeysRequest is the request to generate the shared keys of a named
+// key and a secp256k1 public key, hashed with each of the salts.
+type SharedKeysRequest struct {
+	Name      string
+	PublicKey []byte
+	Salts     [][]byte
+}
+
+// SharedKeysResponse holds the shared keys in the order of the salts.
+type SharedKeysResponse struct {
+	Keys [][]byte
+}
+
+func encodePublicKey(curve Curve, pub *ecdsa.PublicKey) ([]byte, error) {
+	switch curve {
+	case Secp256k1:
+		return crypto.EncodeSecp256k1PublicKey(pub), nil
+	case Secp256r1:
+		return x509.MarshalPKIXPublicKey(pub)
+	default:
+		return nil, ErrUnsupportedCurve
+	}
+}
+
+func decodePublicKey(curve Curve, data []byte) (*ecdsa.PublicKey, error) {
+	switch curve {
+	case Secp256k1:
+		return decodeSecp256k1PublicKey(data)
+	case Secp256r1:
+		pub, err := x509.ParsePKIXPublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, ErrUnsupportedCurve
+		}
+		return k, nil
+	default:
+		return nil, ErrUnsupportedCurve
+	}
+}
+
+func decodeSecp256k1PublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	pub, err := btcec.ParsePubKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return pub.ToECDSA(), nil
+}
+
+var (
+	_ keystore.Plugin = (*Client)(nil)
+	_ crypto.BatchDH  = (*dh)(nil)
+)
+
+// Client uses the keys held by a remote signer.
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial connects to the signer listening on the endpoint. The endpoint is a
+// path to a unix socket, optionally prefixed with unix://.
+func Dial(endpoint string) (*Client, error) {
+	path, err := SocketPath(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("dial remote signer: %w", err)
+	}
+	return &Client{rpc: rpc.NewClient(conn)}, nil
+}
+
+// Listen listens for the clients on the endpoint, which has the format
+// accepted by Dial. The socket is created accessible only by the owner of
+// the process, as the requests are not authenticated otherwise.
+func Listen(endpoint string) (net.Listener, error) {
+	path, err := SocketPath(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return listenSocket(path)
+}
+
+// SocketPath returns the path of the unix socket of the endpoint.
+func SocketPath(endpoint string) (string, error) {
+	if path, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		return path, nil
+	}
+	if strings.Contains(endpoint, "://") {
+		return "", ErrInvalidEndpoint
+	}
+	return endpoint, nil
+}
+
+// Close closes the connection to the signer.
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// PublicKey returns the public key of the named key, which is created
+// by the signer if it does not exist.
+func (c *Client) PublicKey(name string, curve Curve) (*ecdsa.PublicKey, error) {
+	var resp KeyResponse
+	if err := c.rpc.Call(serviceName+".PublicKey", KeyRequest{Name: name, Curve: curve}, &resp); err != nil {
+		return nil, fmt.Errorf("remote signer: public key %s: %w", name, err)
+	}
+	return decodePublicKey(curve, resp.PublicKey)
+}
+
+// Signer returns the signer backed by the named secp256k1 key.
+func (c *Client) Signer(name string) (crypto.Signer, error) {
+	pub, err := c.PublicKey(name, Secp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewDigestSigner(pub, func(digest []byte) ([]byte, error) {
+		return c.sign(name, Secp256k1, digest)
+	}), nil
+}
+
+// DH returns the shared key generation backed by the named secp256k1 key.
+func (c *Client) DH(name string) (crypto.DH, error) {
+	if _, err := c.PublicKey(name, Secp256k1); err != nil {
+		return nil, err
+	}
+	return &dh{client: c, name: name}, nil
+}
+
+// Identity returns the libp2p identity backed by the named secp256r1 key.
+func (c *Client) Identity(name string) (libp2pcrypto.PrivKey, error) {
+	pub, err := c.PublicKey(name, Secp256r1)
+	if err != nil {
+		return nil, err
+	}
+	libp2pPub, err := libp2pcrypto.ECDSAPublicKeyFromPubKey(*pub)
+	if err != nil {
+		return nil, err
+	}
+	return &identity{client: c, name: name, pub: libp2pPub}, nil
+}
+
+func (c *Client) sign(name string, curve Curve, digest []byte) ([]byte, error) {
+	var resp SignResponse
+	if err := c.rpc.Call(serviceName+".Sign", SignRequest{Name: name, Curve: curve, Digest: digest}, &resp); err != nil {
+		return nil, fmt.Errorf("remote signer: sign with %s: %w", name, err)
+	}
+	return resp.Signature, nil
+}
+
+type dh struct {
+	client *Client
+	name   string
+}
+
+// SharedKey implements the crypto.DH interface.
+func (d *dh) SharedKey(pub *ecdsa.PublicKey, salt []byte) ([]byte, error) {
+	var resp SharedKeyResponse
+	req := SharedKeyRequest{
+		Name:      d.name,
+		PublicKey: crypto.EncodeSecp256k1PublicKey(pub),
+		Salt:      salt,
+	}
+	if err := d.client.rpc.Call(serviceName+".SharedKey", req, &resp); err != nil {
+		return nil, fmt.Errorf("remote signer: shared key with %s: %w", d.name, err)
+	}
+	return resp.Key, nil
+}
+
+// SharedKeys implements the crypto.BatchDH interface. The keys for all salts
+// are generated with a single request to the signer.
+func (d *dh) SharedKeys(pub *ecdsa.PublicKey, salts [][]byte) ([][]byte, error) {
+	var resp SharedKeysResponse
+	req := SharedKeysRequest{
+		Name:      d.name,
+		PublicKey: crypto.EncodeSecp256k1PublicKey(pub),
+		Salts:     salts,
+	}
+	if err := d.client.rpc.Call(serviceName+".SharedKeys", req, &resp); err != nil {
+		return nil, fmt.Errorf("remote signer: shared keys with %s: %w", d.name, err)
+	}
+	if len(resp.Keys) != len(salts) {
+		return nil, fmt.Errorf("remote signer: shared keys with %s: got %d keys for %d salts", d.name, len(resp.Keys), len(salts))
+	}
+	return resp.Keys, nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remote_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/crypto/remote"
+	memkeystore "github.com/ethersphere/bee/v2/pkg/keystore/mem"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+)
+
+const password = "secret"
+
+// newSigner starts a mock signer backed by the in-memory keystore.
+func newSigner(t *testing.T) (*remote.Client, *memkeystore.Service) {
+	t.Helper()
+
+	ks := memkeystore.New()
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "signer.sock")
+	l, err := remote.Listen(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := remote.NewServer(ks, password)
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(l) }()
+
+	c, err := remote.Dial(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			t.Error(err)
+		}
+		if err := l.Close(); err != nil {
+			t.Error(err)
+		}
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+	return c, ks
+}
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+
+	c, ks := newSigner(t)
+
+	signer, err := c.Signer("swarm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, created, err := ks.Key("swarm", password, crypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("key not created by the signer")
+	}
+
+	pub, err := signer.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&key.PublicKey) {
+		t.Fatal("public key mismatch")
+	}
+
+	data := []byte("data")
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := crypto.NewDefaultSigner(key).Sign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Fatalf("got signature %x, want %x", sig, want)
+	}
+
+	chainID := big.NewInt(100)
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		To:        &common.Address{},
+		Value:     big.NewInt(1),
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	}), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := types.Sender(types.NewLondonSigner(chainID), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethAddress, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != ethAddress {
+		t.Fatalf("got sender %s, want %s", sender, ethAddress)
+	}
+}
+
+func TestDH(t *testing.T) {
+	t.Parallel()
+
+	c, ks := newSigner(t)
+
+	dh, err := c.DH("pss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _, err := ks.Key("pss", password, crypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := []byte("salt")
+
+	got, err := dh.SharedKey(&other.PublicKey, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := crypto.NewDH(key).SharedKey(&other.PublicKey, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got shared key %x, want %x", got, want)
+	}
+
+	batch, ok := dh.(crypto.BatchDH)
+	if !ok {
+		t.Fatal("shared keys are not generated in batches")
+	}
+	salts := [][]byte{[]byte("salt0"), salt}
+	keys, err := batch.SharedKeys(&other.PublicKey, salts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != len(salts) || !bytes.Equal(keys[1], want) {
+		t.Fatalf("got shared keys %x, want %x as the second", keys, want)
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Parallel()
+
+	t.Run("permissions", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS == "windows" {
+			t.Skip("no unix permissions")
+		}
+		path := filepath.Join(t.TempDir(), "signer.sock")
+		l, err := remote.Listen(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = l.Close() })
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0o600 {
+			t.Fatalf("got socket permissions %o, want %o", perm, 0o600)
+		}
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := remote.Listen("tcp://127.0.0.1:0"); !errors.Is(err, remote.ErrInvalidEndpoint) {
+			t.Fatalf("got error %v, want %v", err, remote.ErrInvalidEndpoint)
+		}
+		if _, err := remote.Dial("tcp://127.0.0.1:1"); !errors.Is(err, remote.ErrInvalidEndpoint) {
+			t.Fatalf("got error %v, want %v", err, remote.ErrInvalidEndpoint)
+		}
+	})
+}
+
+func TestIdentity(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newSigner(t)
+
+	newHost := func(name string) (peer.AddrInfo, func(context.Context, peer.AddrInfo) error) {
+		t.Helper()
+
+		id, err := c.Identity(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := id.Raw(); err == nil {
+			t.Fatal("expected raw key to be unavailable")
+		}
+
+		h, err := libp2p.New(
+			libp2p.Identity(id),
+			libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+			libp2p.Transport(tcp.NewTCPTransport),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = h.Close() })
+
+		wantID, err := peer.IDFromPublicKey(id.GetPublic())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.ID() != wantID {
+			t.Fatalf("got peer id %s, want %s", h.ID(), wantID)
+		}
+		return peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}, h.Connect
+	}
+
+	a, _ := newHost("libp2p_a")
+	_, connect := newHost("libp2p_b")
+
+	if err := connect(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+)
+
+// Server is the signer process side holding the private keys. The keys are
+// loaded from the keystore, or created in it if they do not exist.
+type Server struct {
+	keystore keystore.Service
+	password string
+
+	mu   sync.Mutex
+	keys map[string]*ecdsa.PrivateKey
+}
+
+// NewServer returns a signer server using the keys from the keystore
+// decrypted with the password. A server backed by the in-memory keystore
+// can be used as a local mock signer.
+func NewServer(ks keystore.Service, password string) *Server {
+	return &Server{
+		keystore: ks,
+		password: password,
+		keys:     make(map[string]*ecdsa.PrivateKey),
+	}
+}
+
+// Serve accepts connections on the listener and serves the signer requests
+// until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &service{server: s}); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go srv.ServeConn(conn)
+	}
+}
+
+func (s *Server) key(name string, curve Curve) (*ecdsa.PrivateKey, error) {
+	var edg keystore.EDG
+	switch curve {
+	case Secp256k1:
+		edg = crypto.EDGSecp256_K1
+	case Secp256r1:
+		edg = crypto.EDGSecp256_R1
+	default:
+		return nil, ErrUnsupportedCurve
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := string(curve) + "/" + name
+	if k, ok := s.keys[id]; ok {
+		return k, nil
+	}
+	k, _, err := s.keystore.Key(name, s.password, edg)
+	if err != nil {
+		return nil, err
+	}
+	s.keys[id] = k
+	return k, nil
+}
+
+// service holds the methods exposed over RPC.
+type service struct {
+	server *Server
+}
+
+func (s *service) PublicKey(req KeyRequest, resp *KeyResponse) error {
+	k, err := s.server.key(req.Name, req.Curve)
+	if err != nil {
+		return err
+	}
+	resp.PublicKey, err = encodePublicKey(req.Curve, &k.PublicKey)
+	return err
+}
+
+func (s *service) Sign(req SignRequest, resp *SignResponse) error {
+	k, err := s.server.key(req.Name, req.Curve)
+	if err != nil {
+		return err
+	}
+	if req.Curve == Secp256k1 {
+		resp.Signature, err = crypto.SignDigest(k, req.Digest)
+	} else {
+		resp.Signature, err = ecdsa.SignASN1(rand.Reader, k, req.Digest)
+	}
+	return err
+}
+
+func (s *service) SharedKey(req SharedKeyRequest, resp *SharedKeyResponse) error {
+	k, err := s.server.key(req.Name, Secp256k1)
+	if err != nil {
+		return err
+	}
+	pub, err := decodeSecp256k1PublicKey(req.PublicKey)
+	if err != nil {
+		return err
+	}
+	resp.Key, err = crypto.NewDH(k).SharedKey(pub, req.Salt)
+	return err
+}
+
+func (s *service) SharedKeys(req SharedKeysRequest, resp *SharedKeysResponse) error {
+	k, err := s.server.key(req.Name, Secp256k1)
+	if err != nil {
+		return err
+	}
+	pub, err := decodeSecp256k1PublicKey(req.PublicKey)
+	if err != nil {
+		return err
+	}
+	dh := crypto.NewDH(k)
+	resp.Keys = make([][]byte, len(req.Salts))
+	for i, salt := range req.Salts {
+		if resp.Keys[i], err = dh.SharedKey(pub, salt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -67,19 +67,37 @@ func Recover(signature, data []byte) (*ecdsa.PublicKey, error) {
 	return pbk.ToECDSA(), err
 }
 
+// SignDigestFunc signs the 32 byte digest and returns the signature
+// in the ethereum (r,s,v) format with v being 27 or 28.
+type SignDigestFunc func(digest []byte) ([]byte, error)
+
 type defaultSigner struct {
-	key *ecdsa.PrivateKey
+	publicKey  *ecdsa.PublicKey
+	signDigest SignDigestFunc
 }
 
 func NewDefaultSigner(key *ecdsa.PrivateKey) Signer {
 	return &defaultSigner{
-		key: key,
+		publicKey: &key.PublicKey,
+		signDigest: func(digest []byte) ([]byte, error) {
+			return SignDigest(key, digest)
+		},
+	}
+}
+
+// NewDigestSigner returns a signer for the given public key that delegates
+// the signing of digests to signDigest. It allows the private key to be kept
+// outside of the process, e.g. by an external signer.
+func NewDigestSigner(publicKey *ecdsa.PublicKey, signDigest SignDigestFunc) Signer {
+	return &defaultSigner{
+		publicKey:  publicKey,
+		signDigest: signDigest,
 	}
 }
 
 // PublicKey returns the public key this signer uses.
 func (d *defaultSigner) PublicKey() (*ecdsa.PublicKey, error) {
-	return &d.key.PublicKey, nil
+	return d.publicKey, nil
 }
 
 // Sign signs data with ethereum prefix (eip191 type 0x45).
@@ -89,15 +107,14 @@ func (d *defaultSigner) Sign(data []byte) (signature []byte, err error) {
 		return nil, err
 	}
 
-	return d.sign(hash, true)
+	return d.signDigest(hash)
 }
 
 // SignTx signs an ethereum transaction.
 func (d *defaultSigner) SignTx(transaction *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
 	txSigner := types.NewLondonSigner(chainID)
 	hash := txSigner.Hash(transaction).Bytes()
-	// isCompressedKey is false here so we get the expected v value (27 or 28)
-	signature, err := d.sign(hash, false)
+	signature, err := d.signDigest(hash)
 	if err != nil {
 		return nil, err
 	}
@@ -134,12 +151,13 @@ func (d *defaultSigner) SignTypedData(typedData *eip712.TypedData) ([]byte, erro
 		return nil, err
 	}
 
-	return d.sign(sighash, false)
+	return d.signDigest(sighash)
 }
 
-// sign the provided hash and convert it to the ethereum (r,s,v) format.
-func (d *defaultSigner) sign(sighash []byte, isCompressedKey bool) ([]byte, error) {
-	pvk, _ := btcec.PrivKeyFromBytes(d.key.D.Bytes())
+// SignDigest signs the provided hash and converts it to the ethereum (r,s,v) format.
+// The signature is created for the uncompressed key so that v is 27 or 28.
+func SignDigest(key *ecdsa.PrivateKey, sighash []byte) ([]byte, error) {
+	pvk, _ := btcec.PrivKeyFromBytes(key.D.Bytes())
 	signature, err := btcecdsa.SignCompact(pvk, sighash, false)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,92 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package atrest provides authenticated encryption of data
+// kept on the local disk of the node.
+//
+// Every sealed blob is prefixed with a random nonce, so the same plaintext
+// never results in the same ciphertext and storage slots can be safely reused.
+package atrest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// KeyLength is the length of the key used by the cipher.
+const KeyLength = 32
+
+var (
+	// ErrInvalidKeyLength is returned when the key is not KeyLength bytes long.
+	ErrInvalidKeyLength = errors.New("atrest: invalid key length")
+	// ErrDecrypt is returned when the ciphertext can not be authenticated.
+	ErrDecrypt = errors.New("atrest: decryption failed")
+)
+
+// Cipher seals and opens blobs using AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+	key  []byte
+}
+
+// New creates a new cipher with the given key.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeyLength {
+		return nil, ErrInvalidKeyLength
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new aes cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("new gcm: %w", err)
+	}
+	return &Cipher{aead: aead, key: key}, nil
+}
+
+// Overhead returns the difference between the length
+// of a sealed blob and the length of its plaintext.
+func (c *Cipher) Overhead() int {
+	return c.aead.NonceSize() + c.aead.Overhead()
+}
+
+// Seal encrypts and authenticates the plaintext and appends
+// the nonce and the resulting ciphertext to dst.
+func (c *Cipher) Seal(dst, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("read nonce: %w", err)
+	}
+	dst = append(dst, nonce...)
+	return c.aead.Seal(dst, nonce, plaintext, nil), nil
+}
+
+// Open authenticates and decrypts the sealed blob
+// and appends the resulting plaintext to dst.
+func (c *Cipher) Open(dst, sealed []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(sealed) < c.Overhead() {
+		return nil, ErrDecrypt
+	}
+	out, err := c.aead.Open(dst, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return out, nil
+}
+
+// KeyCheck returns a value that identifies the key without revealing it.
+// It is persisted alongside encrypted data in order to detect
+// an attempt to open the data with a wrong key.
+func (c *Cipher) KeyCheck() []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte("bee-atrest-key-check"))
+	_, _ = h.Write(c.key)
+	return h.Sum(nil)
+}
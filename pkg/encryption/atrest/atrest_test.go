@@ -0,0 +1,82 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atrest_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{1}, atrest.KeyLength)
+	c, err := atrest.New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("swarm chunk data")
+
+	sealed, err := c.Seal(nil, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != len(plaintext)+c.Overhead() {
+		t.Fatalf("got sealed length %d, want %d", len(sealed), len(plaintext)+c.Overhead())
+	}
+
+	again, err := c.Seal(nil, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Fatal("sealing the same plaintext twice resulted in the same ciphertext")
+	}
+
+	opened, err := c.Open(nil, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("got plaintext %q, want %q", opened, plaintext)
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		t.Parallel()
+
+		tampered := bytes.Clone(sealed)
+		tampered[len(tampered)-1] ^= 0xff
+		if _, err := c.Open(nil, tampered); !errors.Is(err, atrest.ErrDecrypt) {
+			t.Fatalf("got error %v, want %v", err, atrest.ErrDecrypt)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Parallel()
+
+		other, err := atrest.New(bytes.Repeat([]byte{2}, atrest.KeyLength))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.Open(nil, sealed); !errors.Is(err, atrest.ErrDecrypt) {
+			t.Fatalf("got error %v, want %v", err, atrest.ErrDecrypt)
+		}
+		if bytes.Equal(c.KeyCheck(), other.KeyCheck()) {
+			t.Fatal("key checks of different keys are equal")
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := atrest.New([]byte{1}); !errors.Is(err, atrest.ErrInvalidKeyLength) {
+			t.Fatalf("got error %v, want %v", err, atrest.ErrInvalidKeyLength)
+		}
+	})
+}
@@ -15,7 +15,12 @@ import (
 // New constructs an encryption interface (the modified blockcipher) with a base key derived from
 // a shared secret (using a private key and the counterparty's public key) hashed with  a salt
 func New(key *ecdsa.PrivateKey, pub *ecdsa.PublicKey, salt []byte, padding int, hashfunc func() hash.Hash) (encryption.Interface, error) {
-	dh := crypto.NewDH(key)
+	return NewWithDH(crypto.NewDH(key), pub, salt, padding, hashfunc)
+}
+
+// NewWithDH constructs an encryption interface with a base key derived from the shared
+// secret generated by dh and the counterparty's public key, hashed with a salt
+func NewWithDH(dh crypto.DH, pub *ecdsa.PublicKey, salt []byte, padding int, hashfunc func() hash.Hash) (encryption.Interface, error) {
 	sk, err := dh.SharedKey(pub, salt)
 	if err != nil {
 		return nil, err
@@ -40,6 +40,11 @@ type index struct {
 	index uint64
 }
 
+// NewIndex returns the sequence index i.
+func NewIndex(i uint64) feeds.Index {
+	return &index{i}
+}
+
 func (i *index) String() string {
 	return strconv.FormatUint(i.index, 10)
 }
@@ -0,0 +1,120 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gsoc provides the subscriptions to graffiti single-owner chunks.
+//
+// A graffiti single-owner chunk (GSOC) is written under a well-known
+// identifier by an owner whose private key is public, so that anyone can
+// update it. The nodes in the neighbourhood of its address store every
+// update that arrives through pushsync and deliver its payload to the
+// subscribers, which makes it a cheap channel for group messaging.
+package gsoc
+
+import (
+	"io"
+	"sync"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "gsoc"
+
+// Handler defines code to be executed upon the arrival of a single-owner
+// chunk, with the payload of its wrapped chunk.
+type Handler func([]byte)
+
+type Listener interface {
+	// Subscribe registers a Handler for the single-owner chunk address.
+	Subscribe(address swarm.Address, handler Handler) (cleanup func())
+	// Handle delivers the single-owner chunk to its subscribers.
+	Handle(*soc.SOC)
+	io.Closer
+}
+
+var _ Listener = (*listener)(nil)
+
+type listener struct {
+	handlers   map[string][]*Handler
+	handlersMu sync.Mutex
+	quit       chan struct{}
+	logger     log.Logger
+}
+
+// New returns a new GSOC listener service.
+func New(logger log.Logger) Listener {
+	return &listener{
+		logger:   logger.WithName(loggerName).Register(),
+		handlers: make(map[string][]*Handler),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Subscribe allows the definition of a Handler func on a specific single-owner chunk address.
+func (l *listener) Subscribe(address swarm.Address, handler Handler) (cleanup func()) {
+	l.handlersMu.Lock()
+	defer l.handlersMu.Unlock()
+
+	l.handlers[address.ByteString()] = append(l.handlers[address.ByteString()], &handler)
+
+	return func() {
+		l.handlersMu.Lock()
+		defer l.handlersMu.Unlock()
+
+		h := l.handlers[address.ByteString()]
+		for i := 0; i < len(h); i++ {
+			if h[i] == &handler {
+				l.handlers[address.ByteString()] = append(h[:i], h[i+1:]...)
+				break
+			}
+		}
+		if len(l.handlers[address.ByteString()]) == 0 {
+			delete(l.handlers, address.ByteString())
+		}
+	}
+}
+
+// Handle is called by the storer when a single-owner chunk arrives
+// through pushsync and calls its handlers with the payload.
+func (l *listener) Handle(c *soc.SOC) {
+	addr, err := c.Address()
+	if err != nil {
+		return // no handler
+	}
+	h := l.getHandlers(addr)
+	if h == nil {
+		return // no handler
+	}
+	l.logger.Debug("new incoming GSOC message", "address", addr, "wrapped_chunk_size", len(c.WrappedChunk().Data()))
+
+	payload := c.WrappedChunk().Data()[swarm.SpanSize:]
+	for _, hh := range h {
+		go func(hh Handler) {
+			select {
+			case <-l.quit:
+			default:
+				hh(payload)
+			}
+		}(*hh)
+	}
+}
+
+func (l *listener) getHandlers(address swarm.Address) []*Handler {
+	l.handlersMu.Lock()
+	defer l.handlersMu.Unlock()
+
+	return l.handlers[address.ByteString()]
+}
+
+func (l *listener) Close() error {
+	close(l.quit)
+	l.handlersMu.Lock()
+	defer l.handlersMu.Unlock()
+
+	l.handlers = make(map[string][]*Handler) // unset handlers on shutdown
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsoc_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	soctesting "github.com/ethersphere/bee/v2/pkg/soc/testing"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+// TestListener tests that the payloads of the updates of a graffiti
+// single-owner chunk are delivered to its subscribers only.
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	// the owner key of a graffiti chunk is public
+	privKey, err := soc.GraffitiKey(make([]byte, swarm.HashSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := gsoc.New(log.Noop)
+	testutil.CleanupCloser(t, l)
+
+	first := mockSOC(t, []byte("first"), privKey)
+	update := mockSOC(t, []byte("update"), privKey)
+	other := mockSOC(t, []byte("other"), nil)
+
+	addr, err := first.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgC := make(chan []byte, 3)
+	cleanup := l.Subscribe(addr, func(m []byte) { msgC <- m })
+
+	l.Handle(other)
+	l.Handle(first)
+	expectMessage(t, msgC, []byte("first"))
+	l.Handle(update)
+	expectMessage(t, msgC, []byte("update"))
+
+	cleanup()
+	l.Handle(update)
+	select {
+	case m := <-msgC:
+		t.Fatalf("got message %q after the cleanup", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func mockSOC(t *testing.T, payload []byte, privKey *ecdsa.PrivateKey) *soc.SOC {
+	t.Helper()
+
+	ms := soctesting.GenerateMockSOC(t, payload)
+	if privKey != nil {
+		ms = soctesting.GenerateMockSOCWithKey(t, payload, privKey)
+	}
+	s, err := soc.FromChunk(ms.Chunk())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func expectMessage(t *testing.T, msgC <-chan []byte, want []byte) {
+	t.Helper()
+
+	select {
+	case m := <-msgC:
+		if !bytes.Equal(m, want) {
+			t.Fatalf("got message %q, want %q", m, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not delivered")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/v2/pkg/ratelimit"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	ma "github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr/net"
@@ -56,6 +57,8 @@ type Service struct {
 	streamer          p2p.StreamerPinger
 	addressBook       addressbook.GetPutter
 	addPeersHandler   func(...swarm.Address)
+	reputation        reputation.Scorer
+	membership        Membership
 	networkID         uint64
 	logger            log.Logger
 	metrics           metrics
@@ -145,6 +148,25 @@ func (s *Service) SetAddPeersHandler(h func(addr ...swarm.Address)) {
 	s.addPeersHandler = h
 }
 
+// Membership restricts the gossip to the members of a private network.
+type Membership interface {
+	// Certificate returns the verified membership certificate of the peer.
+	Certificate(overlay swarm.Address) ([]byte, bool)
+	// Verify checks the membership certificate of the peer.
+	Verify(overlay swarm.Address, certificate []byte) error
+}
+
+// SetMembership makes the service gossip only the peers with valid membership
+// certificates and accept only the gossiped peers with valid certificates.
+func (s *Service) SetMembership(m Membership) {
+	s.membership = m
+}
+
+// SetReputation makes the service reject the gossiped peers with bad reputation.
+func (s *Service) SetReputation(r reputation.Scorer) {
+	s.reputation = r
+}
+
 func (s *Service) Close() error {
 	close(s.quit)
 
@@ -187,15 +209,32 @@ func (s *Service) sendPeers(ctx context.Context, peer swarm.Address, peers []swa
 			return err
 		}
 
-		if !s.allowPrivateCIDRs && manet.IsPrivateAddr(addr.Underlay) {
+		if !s.allowPrivateCIDRs && allPrivate(addr.Underlays) {
 			continue // Don't advertise private CIDRs to the public network.
 		}
 
+		var certificate []byte
+		if s.membership != nil {
+			var ok bool
+			if certificate, ok = s.membership.Certificate(p); !ok {
+				continue // Don't advertise the peers not permitted in the private network.
+			}
+		}
+
+		underlay, underlays, err := addr.WireUnderlays()
+		if err != nil {
+			s.logger.Debug("broadcast peers; invalid peer underlays, skipping...", "peer_address", p, "error", err)
+			continue
+		}
+
 		peersRequest.Peers = append(peersRequest.Peers, &pb.BzzAddress{
-			Overlay:   addr.Overlay.Bytes(),
-			Underlay:  addr.Underlay.Bytes(),
-			Signature: addr.Signature,
-			Nonce:     addr.Nonce,
+			Overlay:            addr.Overlay.Bytes(),
+			Underlay:           underlay,
+			Signature:          addr.Signature,
+			Underlays:          underlays,
+			UnderlaysSignature: addr.UnderlaysSignature,
+			Nonce:              addr.Nonce,
+			Certificate:        certificate,
 		})
 	}
 
@@ -206,6 +245,83 @@ func (s *Service) sendPeers(ctx context.Context, peer swarm.Address, peers []swa
 	return nil
 }
 
+// AnnounceAddress sends the address of the node to the addressee, so that
+// the peer learns the changed underlays of the node. The peers of a private
+// network also receive the membership certificate of the node.
+func (s *Service) AnnounceAddress(ctx context.Context, addressee swarm.Address, addr *bzz.Address) (err error) {
+	select {
+	case <-s.quit:
+		return ErrShutdownInProgress
+	default:
+	}
+
+	underlay, underlays, err := addr.WireUnderlays()
+	if err != nil {
+		return err
+	}
+
+	var certificate []byte
+	if s.membership != nil {
+		certificate, _ = s.membership.Certificate(addr.Overlay)
+	}
+
+	s.metrics.AnnounceAddress.Inc()
+	stream, err := s.streamer.NewStream(ctx, addressee, nil, protocolName, protocolVersion, peersStreamName)
+	if err != nil {
+		return fmt.Errorf("new stream: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.Close()
+		}
+	}()
+
+	w, _ := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.Peers{Peers: []*pb.BzzAddress{{
+		Overlay:            addr.Overlay.Bytes(),
+		Underlay:           underlay,
+		Signature:          addr.Signature,
+		Underlays:          underlays,
+		UnderlaysSignature: addr.UnderlaysSignature,
+		Nonce:              addr.Nonce,
+		Certificate:        certificate,
+	}}}); err != nil {
+		return fmt.Errorf("write Peers message: %w", err)
+	}
+
+	return nil
+}
+
+// peerUnderlays returns the underlays of the gossiped peer. The list of all
+// the underlays is optional, the older peers gossip only the first one.
+func peerUnderlays(p *pb.BzzAddress) ([]ma.Multiaddr, error) {
+	underlays, err := bzz.DeserializeUnderlays(p.Underlay)
+	if err != nil || len(p.Underlays) == 0 {
+		return underlays, err
+	}
+
+	list, err := bzz.DeserializeUnderlays(p.Underlays)
+	if err != nil {
+		return nil, err
+	}
+	if !bzz.UnderlaysEqual(list[:1], underlays[:1]) {
+		return nil, bzz.ErrInvalidAddress
+	}
+	return list, nil
+}
+
+// allPrivate reports whether none of the underlays is a public address.
+func allPrivate(underlays []ma.Multiaddr) bool {
+	for _, u := range underlays {
+		if !manet.IsPrivateAddr(u) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Service) peersHandler(ctx context.Context, peer p2p.Peer, stream p2p.Stream) error {
 	s.metrics.PeersHandler.Inc()
 	_, r := protobuf.NewWriterAndReader(stream)
@@ -288,7 +404,7 @@ func (s *Service) checkAndAddPeers(ctx context.Context, peers pb.Peers) {
 	mtx := sync.Mutex{}
 	wg := sync.WaitGroup{}
 
-	addPeer := func(newPeer *pb.BzzAddress, multiUnderlay ma.Multiaddr) {
+	addPeer := func(newPeer *pb.BzzAddress, underlays []ma.Multiaddr) {
 
 		err := s.sem.Acquire(ctx, 1)
 		if err != nil {
@@ -309,11 +425,18 @@ func (s *Service) checkAndAddPeers(ctx context.Context, peers pb.Peers) {
 
 			start := time.Now()
 
-			// check if the underlay is usable by doing a raw ping using libp2p
-			if _, err := s.streamer.Ping(ctx, multiUnderlay); err != nil {
+			// check if any of the underlays is usable by doing a raw ping using libp2p
+			reachable := false
+			for _, underlay := range underlays {
+				if _, err := s.streamer.Ping(ctx, underlay); err == nil {
+					reachable = true
+					break
+				}
+			}
+			if !reachable {
 				s.metrics.PingFailureTime.Observe(time.Since(start).Seconds())
 				s.metrics.UnreachablePeers.Inc()
-				s.logger.Debug("unreachable peer underlay", "peer_address", hex.EncodeToString(newPeer.Overlay), "underlay", multiUnderlay)
+				s.logger.Debug("unreachable peer underlays", "peer_address", hex.EncodeToString(newPeer.Overlay), "underlays", underlays)
 				return
 			}
 			s.metrics.PingTime.Observe(time.Since(start).Seconds())
@@ -322,10 +445,13 @@ func (s *Service) checkAndAddPeers(ctx context.Context, peers pb.Peers) {
 
 			bzzAddress := bzz.Address{
 				Overlay:   swarm.NewAddress(newPeer.Overlay),
-				Underlay:  multiUnderlay,
+				Underlays: underlays,
 				Signature: newPeer.Signature,
 				Nonce:     newPeer.Nonce,
 			}
+			if len(underlays) > 1 {
+				bzzAddress.UnderlaysSignature = newPeer.UnderlaysSignature
+			}
 
 			err := s.addressBook.Put(bzzAddress.Overlay, bzzAddress)
 			if err != nil {
@@ -343,22 +469,36 @@ func (s *Service) checkAndAddPeers(ctx context.Context, peers pb.Peers) {
 
 	for _, p := range peers.Peers {
 
-		multiUnderlay, err := ma.NewMultiaddrBytes(p.Underlay)
+		underlays, err := peerUnderlays(p)
 		if err != nil {
 			s.metrics.PeerUnderlayErr.Inc()
 			s.logger.Debug("multi address underlay", "error", err)
 			continue
 		}
 
+		if s.membership != nil {
+			if err := s.membership.Verify(swarm.NewAddress(p.Overlay), p.Certificate); err != nil {
+				s.metrics.NotPermittedPeers.Inc()
+				s.logger.Debug("skipping peer not permitted", "peer_address", hex.EncodeToString(p.Overlay), "error", err)
+				continue
+			}
+		}
+
+		if s.reputation != nil && s.reputation.Bad(swarm.NewAddress(p.Overlay)) {
+			s.metrics.BadReputationPeers.Inc()
+			s.logger.Debug("skipping peer with bad reputation", "peer_address", hex.EncodeToString(p.Overlay))
+			continue
+		}
+
 		// if peer exists already in the addressBook
 		// and if the underlays match, skip
 		addr, err := s.addressBook.Get(swarm.NewAddress(p.Overlay))
-		if err == nil && addr.Underlay.Equal(multiUnderlay) {
+		if err == nil && bzz.UnderlaysEqual(addr.Underlays, underlays) {
 			continue
 		}
 
 		// add peer does not exist in the addressbook
-		addPeer(p, multiUnderlay)
+		addPeer(p, underlays)
 	}
 	wg.Wait()
 
@@ -77,7 +77,7 @@ func TestHandlerRateLimit(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		bzzAddr, err := bzz.NewAddress(signer, underlay, overlay, networkID, nonce)
+		bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{underlay}, overlay, networkID, nonce)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -145,7 +145,7 @@ func TestBroadcastPeers(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		bzzAddr, err := bzz.NewAddress(signer, underlay, overlay, networkID, nonce)
+		bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{underlay}, overlay, networkID, nonce)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -159,7 +159,7 @@ func TestBroadcastPeers(t *testing.T) {
 
 		wantMsgs[i/hive.MaxBatchSize].Peers = append(wantMsgs[i/hive.MaxBatchSize].Peers, &pb.BzzAddress{
 			Overlay:   bzzAddresses[i].Overlay.Bytes(),
-			Underlay:  bzzAddresses[i].Underlay.Bytes(),
+			Underlay:  bzzAddresses[i].Underlays[0].Bytes(),
 			Signature: bzzAddresses[i].Signature,
 			Nonce:     nonce,
 		})
@@ -223,7 +223,7 @@ func TestBroadcastPeers(t *testing.T) {
 			allowPrivateCIDRs: true,
 			pingErr: func(addr ma.Multiaddr) (rtt time.Duration, err error) {
 				for _, v := range bzzAddresses[10:15] {
-					if v.Underlay.Equal(addr) {
+					if v.Underlays[0].Equal(addr) {
 						return rtt, errors.New("ping failure")
 					}
 				}
@@ -297,6 +297,75 @@ func TestBroadcastPeers(t *testing.T) {
 	}
 }
 
+func TestAnnounceAddress(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	networkID := uint64(1)
+
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(pk)
+	overlay, err := crypto.NewOverlayAddress(pk.PublicKey, networkID, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unreachable, err := ma.NewMultiaddr("/ip4/1.1.1.1/tcp/1634")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reachable, err := ma.NewMultiaddr("/ip4/1.1.1.2/tcp/1634")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := ma.NewMultiaddr("/ip4/1.1.1.3/tcp/1634")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addressbook := ab.New(mock.NewStateStore())
+	streamer := streamtest.New(streamtest.WithPingErr(func(addr ma.Multiaddr) (rtt time.Duration, err error) {
+		if addr.Equal(unreachable) {
+			return rtt, errors.New("ping failure")
+		}
+		return rtt, nil
+	}))
+	server := hive.New(streamer, addressbook, networkID, false, false, logger)
+	testutil.CleanupCloser(t, server)
+
+	serverAddress := swarm.RandAddress(t)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+		streamtest.WithBaseAddr(overlay),
+	)
+	client := hive.New(recorder, ab.New(mock.NewStateStore()), networkID, false, false, logger)
+	testutil.CleanupCloser(t, client)
+
+	for _, underlays := range [][]ma.Multiaddr{
+		{unreachable, reachable},
+		{changed, reachable},
+	} {
+		addr, err := bzz.NewAddress(signer, underlays, overlay, networkID, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.AnnounceAddress(context.Background(), serverAddress, addr); err != nil {
+			t.Fatal(err)
+		}
+
+		err = spinlock.Wait(spinTimeout, func() bool {
+			got, err := addressbook.Get(overlay)
+			return err == nil && bzz.UnderlaysEqual(got.Underlays, underlays)
+		})
+		if err != nil {
+			t.Fatalf("timed out waiting for underlays %v", underlays)
+		}
+	}
+}
+
 func expectOverlaysEventually(t *testing.T, exporter ab.Interface, wantOverlays []swarm.Address) {
 	t.Helper()
 
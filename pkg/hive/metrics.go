@@ -13,6 +13,7 @@ type metrics struct {
 	BroadcastPeers      prometheus.Counter
 	BroadcastPeersPeers prometheus.Counter
 	BroadcastPeersSends prometheus.Counter
+	AnnounceAddress     prometheus.Counter
 
 	PeersHandler      prometheus.Counter
 	PeersHandlerPeers prometheus.Counter
@@ -25,6 +26,8 @@ type metrics struct {
 	PeerUnderlayErr     prometheus.Counter
 	StorePeerErr        prometheus.Counter
 	ReachablePeers      prometheus.Counter
+	BadReputationPeers  prometheus.Counter
+	NotPermittedPeers   prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -49,6 +52,12 @@ func newMetrics() metrics {
 			Name:      "broadcast_peers_message_count",
 			Help:      "Number of individual peer gossip messages sent.",
 		}),
+		AnnounceAddress: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "announce_address_count",
+			Help:      "Number of own address announcements sent.",
+		}),
 		PeersHandler: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -103,6 +112,18 @@ func newMetrics() metrics {
 			Name:      "reachable_peers_count",
 			Help:      "Number of peers that are reachable.",
 		}),
+		BadReputationPeers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "bad_reputation_peers_count",
+			Help:      "Number of received peers rejected because of bad reputation.",
+		}),
+		NotPermittedPeers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "not_permitted_peers_count",
+			Help:      "Number of received peers rejected because of missing private network membership.",
+		}),
 	}
 }
 
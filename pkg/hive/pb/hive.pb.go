@@ -67,10 +67,13 @@ func (m *Peers) GetPeers() []*BzzAddress {
 }
 
 type BzzAddress struct {
-	Underlay  []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
-	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
-	Overlay   []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
-	Nonce     []byte `protobuf:"bytes,4,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	Underlay           []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
+	Signature          []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	Overlay            []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
+	Nonce              []byte `protobuf:"bytes,4,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	Certificate        []byte `protobuf:"bytes,5,opt,name=Certificate,proto3" json:"Certificate,omitempty"`
+	Underlays          []byte `protobuf:"bytes,6,opt,name=Underlays,proto3" json:"Underlays,omitempty"`
+	UnderlaysSignature []byte `protobuf:"bytes,7,opt,name=UnderlaysSignature,proto3" json:"UnderlaysSignature,omitempty"`
 }
 
 func (m *BzzAddress) Reset()         { *m = BzzAddress{} }
@@ -134,6 +137,27 @@ func (m *BzzAddress) GetNonce() []byte {
 	return nil
 }
 
+func (m *BzzAddress) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
+func (m *BzzAddress) GetUnderlays() []byte {
+	if m != nil {
+		return m.Underlays
+	}
+	return nil
+}
+
+func (m *BzzAddress) GetUnderlaysSignature() []byte {
+	if m != nil {
+		return m.UnderlaysSignature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Peers)(nil), "hive.Peers")
 	proto.RegisterType((*BzzAddress)(nil), "hive.BzzAddress")
@@ -142,19 +166,22 @@ func init() {
 func init() { proto.RegisterFile("hive.proto", fileDescriptor_d635d1ead41ba02c) }
 
 var fileDescriptor_d635d1ead41ba02c = []byte{
-	// 189 bytes of a gzipped FileDescriptorProto
+	// 232 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0xc8, 0x2c, 0x4b,
 	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xb1, 0x95, 0xf4, 0xb9, 0x58, 0x03, 0x52,
 	0x53, 0x8b, 0x8a, 0x85, 0xd4, 0xb8, 0x58, 0x0b, 0x40, 0x0c, 0x09, 0x46, 0x05, 0x66, 0x0d, 0x6e,
 	0x23, 0x01, 0x3d, 0xb0, 0x52, 0xa7, 0xaa, 0x2a, 0xc7, 0x94, 0x94, 0xa2, 0xd4, 0xe2, 0xe2, 0x20,
-	0x88, 0xb4, 0x52, 0x19, 0x17, 0x17, 0x42, 0x50, 0x48, 0x8a, 0x8b, 0x23, 0x34, 0x2f, 0x25, 0xb5,
-	0x28, 0x27, 0xb1, 0x52, 0x82, 0x51, 0x81, 0x51, 0x83, 0x27, 0x08, 0xce, 0x17, 0x92, 0xe1, 0xe2,
-	0x0c, 0xce, 0x4c, 0xcf, 0x4b, 0x2c, 0x29, 0x2d, 0x4a, 0x95, 0x60, 0x02, 0x4b, 0x22, 0x04, 0x84,
-	0x24, 0xb8, 0xd8, 0xfd, 0xcb, 0x20, 0x1a, 0x99, 0xc1, 0x72, 0x30, 0xae, 0x90, 0x08, 0x17, 0xab,
-	0x5f, 0x7e, 0x5e, 0x72, 0xaa, 0x04, 0x0b, 0x58, 0x1c, 0xc2, 0x71, 0x92, 0x39, 0xf1, 0x48, 0x8e,
-	0xf1, 0xc2, 0x23, 0x39, 0xc6, 0x07, 0x8f, 0xe4, 0x18, 0x27, 0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58,
-	0x8e, 0xe1, 0xc6, 0x63, 0x39, 0x86, 0x28, 0xa6, 0x82, 0xa4, 0x24, 0x36, 0xb0, 0x9f, 0x8c, 0x01,
-	0x01, 0x00, 0x00, 0xff, 0xff, 0x40, 0x7e, 0xf9, 0x4f, 0xe1, 0x00, 0x00, 0x00,
+	0x88, 0xb4, 0xd2, 0x33, 0x46, 0x2e, 0x2e, 0x84, 0xa8, 0x90, 0x14, 0x17, 0x47, 0x68, 0x5e, 0x4a,
+	0x6a, 0x51, 0x4e, 0x62, 0xa5, 0x04, 0xa3, 0x02, 0xa3, 0x06, 0x4f, 0x10, 0x9c, 0x2f, 0x24, 0xc3,
+	0xc5, 0x19, 0x9c, 0x99, 0x9e, 0x97, 0x58, 0x52, 0x5a, 0x94, 0x2a, 0xc1, 0x04, 0x96, 0x44, 0x08,
+	0x08, 0x49, 0x70, 0xb1, 0xfb, 0x97, 0x41, 0x34, 0x32, 0x83, 0xe5, 0x60, 0x5c, 0x21, 0x11, 0x2e,
+	0x56, 0xbf, 0xfc, 0xbc, 0xe4, 0x54, 0x09, 0x16, 0xb0, 0x38, 0x84, 0x23, 0xa4, 0xc0, 0xc5, 0xed,
+	0x9c, 0x5a, 0x54, 0x92, 0x99, 0x96, 0x99, 0x9c, 0x58, 0x92, 0x2a, 0xc1, 0x0a, 0x96, 0x43, 0x16,
+	0x02, 0xd9, 0x07, 0xb3, 0xbb, 0x58, 0x82, 0x0d, 0x62, 0x1f, 0x5c, 0x40, 0x48, 0x8f, 0x4b, 0x08,
+	0xce, 0x41, 0x38, 0x8b, 0x1d, 0xac, 0x0c, 0x8b, 0x8c, 0x93, 0xcc, 0x89, 0x47, 0x72, 0x8c, 0x17,
+	0x1e, 0xc9, 0x31, 0x3e, 0x78, 0x24, 0xc7, 0x38, 0xe1, 0xb1, 0x1c, 0xc3, 0x85, 0xc7, 0x72, 0x0c,
+	0x37, 0x1e, 0xcb, 0x31, 0x44, 0x31, 0x15, 0x24, 0x25, 0xb1, 0x81, 0x03, 0xd1, 0x18, 0x30, 0x00,
+	0x3d, 0xc8, 0x5f, 0x04, 0x52, 0x01, 0x00, 0x00,
 }
 
 func (m *Peers) Marshal() (dAtA []byte, err error) {
@@ -214,6 +241,27 @@ func (m *BzzAddress) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.UnderlaysSignature) > 0 {
+		i -= len(m.UnderlaysSignature)
+		copy(dAtA[i:], m.UnderlaysSignature)
+		i = encodeVarintHive(dAtA, i, uint64(len(m.UnderlaysSignature)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.Underlays) > 0 {
+		i -= len(m.Underlays)
+		copy(dAtA[i:], m.Underlays)
+		i = encodeVarintHive(dAtA, i, uint64(len(m.Underlays)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Certificate) > 0 {
+		i -= len(m.Certificate)
+		copy(dAtA[i:], m.Certificate)
+		i = encodeVarintHive(dAtA, i, uint64(len(m.Certificate)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if len(m.Nonce) > 0 {
 		i -= len(m.Nonce)
 		copy(dAtA[i:], m.Nonce)
@@ -293,6 +341,18 @@ func (m *BzzAddress) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHive(uint64(l))
 	}
+	l = len(m.Certificate)
+	if l > 0 {
+		n += 1 + l + sovHive(uint64(l))
+	}
+	l = len(m.Underlays)
+	if l > 0 {
+		n += 1 + l + sovHive(uint64(l))
+	}
+	l = len(m.UnderlaysSignature)
+	if l > 0 {
+		n += 1 + l + sovHive(uint64(l))
+	}
 	return n
 }
 
@@ -371,10 +431,7 @@ func (m *Peers) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHive
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHive
 			}
 			if (iNdEx + skippy) > l {
@@ -554,16 +611,115 @@ func (m *BzzAddress) Unmarshal(dAtA []byte) error {
 				m.Nonce = []byte{}
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Certificate", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHive
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHive
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHive
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Certificate = append(m.Certificate[:0], dAtA[iNdEx:postIndex]...)
+			if m.Certificate == nil {
+				m.Certificate = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Underlays", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHive
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHive
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHive
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Underlays = append(m.Underlays[:0], dAtA[iNdEx:postIndex]...)
+			if m.Underlays == nil {
+				m.Underlays = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnderlaysSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHive
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHive
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHive
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UnderlaysSignature = append(m.UnderlaysSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.UnderlaysSignature == nil {
+				m.UnderlaysSignature = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHive(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHive
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHive
 			}
 			if (iNdEx + skippy) > l {
@@ -7,6 +7,9 @@ package keystore
 import (
 	"crypto/ecdsa"
 	"errors"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 )
 
 // ErrInvalidPassword is returned when the password for decrypting content where
@@ -32,3 +35,17 @@ type Service interface {
 	// SetKey generates and persists a new private key
 	SetKey(name, password string, edg EDG) (*ecdsa.PrivateKey, error)
 }
+
+// Plugin provides the operations with the node keys without exposing the
+// private keys to the node. It is implemented by key backends that keep the
+// keys in another process or device, such as an external signer, an OS
+// keyring agent or a hardware security module. The keys are created by the
+// backend if they do not exist.
+type Plugin interface {
+	// Signer returns the signer backed by the secp256k1 key with the given name.
+	Signer(name string) (crypto.Signer, error)
+	// DH returns the shared key generation backed by the secp256k1 key with the given name.
+	DH(name string) (crypto.DH, error)
+	// Identity returns the libp2p identity backed by the secp256r1 key with the given name.
+	Identity(name string) (libp2pcrypto.PrivKey, error)
+}
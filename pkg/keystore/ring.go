@@ -0,0 +1,103 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+)
+
+// VersionedKey is a private key of a Ring together with its version.
+type VersionedKey struct {
+	Version uint32
+	Key     *ecdsa.PrivateKey
+}
+
+// Ring manages the versions of a rotatable key.
+//
+// The version zero is the key with the base name, so that a key that was used
+// before the rotation was introduced becomes the first version of the ring.
+// Every rotation adds a new key named after the ring and the version, and the
+// previous versions remain in the keystore for decrypting the content that
+// was shared with them.
+type Ring struct {
+	ks   Service
+	name string
+	base string
+	edg  EDG
+}
+
+// NewRing returns the ring of keys with the given name and the name of the
+// key that is the version zero of the ring.
+func NewRing(ks Service, name, base string, edg EDG) *Ring {
+	return &Ring{
+		ks:   ks,
+		name: name,
+		base: base,
+		edg:  edg,
+	}
+}
+
+// Version returns the current version of the ring.
+func (r *Ring) Version() (uint32, error) {
+	var v uint32
+	for {
+		exists, err := r.ks.Exists(r.KeyName(v + 1))
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return v, nil
+		}
+		v++
+	}
+}
+
+// Keys returns all versions of the key, the current version first. The
+// version zero key is created if it does not exist.
+func (r *Ring) Keys(password string) ([]VersionedKey, error) {
+	current, err := r.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]VersionedKey, 0, current+1)
+	for v := int64(current); v >= 0; v-- {
+		pk, _, err := r.ks.Key(r.KeyName(uint32(v)), password, r.edg)
+		if err != nil {
+			return nil, fmt.Errorf("%s key version %d: %w", r.name, v, err)
+		}
+		keys = append(keys, VersionedKey{Version: uint32(v), Key: pk})
+	}
+	return keys, nil
+}
+
+// Rotate creates the next version of the key and returns it.
+func (r *Ring) Rotate(password string) (VersionedKey, error) {
+	current, err := r.Version()
+	if err != nil {
+		return VersionedKey{}, err
+	}
+
+	// make sure that the password unlocks the current key,
+	// so that all versions are encrypted with the same password
+	if _, _, err := r.ks.Key(r.KeyName(current), password, r.edg); err != nil {
+		return VersionedKey{}, fmt.Errorf("%s key version %d: %w", r.name, current, err)
+	}
+
+	pk, err := r.ks.SetKey(r.KeyName(current+1), password, r.edg)
+	if err != nil {
+		return VersionedKey{}, err
+	}
+	return VersionedKey{Version: current + 1, Key: pk}, nil
+}
+
+// KeyName returns the name of the key with the given version in the keystore.
+func (r *Ring) KeyName(version uint32) string {
+	if version == 0 {
+		return r.base
+	}
+	return fmt.Sprintf("%s_v%d", r.name, version)
+}
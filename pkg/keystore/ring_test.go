@@ -0,0 +1,78 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+	"github.com/ethersphere/bee/v2/pkg/keystore/mem"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	const password = "pass123456"
+
+	ks := mem.New()
+	base, _, err := ks.Key("pss", password, crypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ring := keystore.NewRing(ks, "pss", "pss", crypto.EDGSecp256_K1)
+
+	keys, err := ring.Keys(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Version != 0 || !keys[0].Key.Equal(base) {
+		t.Fatalf("got %v, want the base key as version 0", keys)
+	}
+
+	if _, err := ring.Rotate("invalid password"); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+
+	v1, err := ring.Rotate(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := ring.Rotate(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.Version != 1 || v2.Version != 2 {
+		t.Fatalf("got versions %d and %d, want 1 and 2", v1.Version, v2.Version)
+	}
+
+	version, err := ring.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 {
+		t.Fatalf("got version %d, want 2", version)
+	}
+
+	keys, err = ring.Keys(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []keystore.VersionedKey{v2, v1, {Version: 0, Key: base}}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(want))
+	}
+	for i := range want {
+		if keys[i].Version != want[i].Version || !keys[i].Key.Equal(want[i].Key) {
+			t.Fatalf("key %d: got version %d, want %d", i, keys[i].Version, want[i].Version)
+		}
+	}
+
+	if name := ring.KeyName(2); name != "pss_v2" {
+		t.Fatalf("got key name %q, want %q", name, "pss_v2")
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package membership
+
+var UpdateRevocations = (*Service).updateRevocations
@@ -0,0 +1,236 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package membership implements the access control of private overlay
+// networks. Every member of a private network presents a certificate
+// issued by the network certificate authority (CA) for its overlay address
+// during the handshake, and only the members are gossiped to the peers.
+// The membership is revoked by listing the overlay addresses in the
+// revocation feed of the CA.
+package membership
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "membership"
+
+const (
+	signatureSize   = 65
+	certificateSize = swarm.HashSize + 8 + signatureSize
+)
+
+// certificatePrefix separates the certificate signatures from the other
+// signatures made with the CA key.
+var certificatePrefix = []byte("swarm-membership-certificate")
+
+var (
+	// ErrNoCertificate is returned when the peer did not present a certificate.
+	ErrNoCertificate = errors.New("no membership certificate")
+	// ErrInvalidCertificate is returned when the certificate is malformed,
+	// is issued for another overlay address or is not issued by the CA.
+	ErrInvalidCertificate = errors.New("invalid membership certificate")
+	// ErrCertificateExpired is returned when the certificate is expired.
+	ErrCertificateExpired = errors.New("membership certificate expired")
+	// ErrRevoked is returned when the membership of the peer is revoked.
+	ErrRevoked = errors.New("membership revoked")
+)
+
+// Certificate permits the overlay address to join the private network
+// until the expiry.
+type Certificate struct {
+	Overlay   swarm.Address
+	Expiry    time.Time
+	Signature []byte
+}
+
+func certificateData(overlay swarm.Address, expiry time.Time) []byte {
+	b := make([]byte, 0, len(certificatePrefix)+swarm.HashSize+8)
+	b = append(b, certificatePrefix...)
+	b = append(b, overlay.Bytes()...)
+	return binary.BigEndian.AppendUint64(b, uint64(expiry.Unix()))
+}
+
+// Issue signs the certificate of the overlay address with the CA key.
+func Issue(ca crypto.Signer, overlay swarm.Address, expiry time.Time) (*Certificate, error) {
+	expiry = time.Unix(expiry.Unix(), 0)
+	sig, err := ca.Sign(certificateData(overlay, expiry))
+	if err != nil {
+		return nil, fmt.Errorf("sign certificate: %w", err)
+	}
+	return &Certificate{Overlay: overlay, Expiry: expiry, Signature: sig}, nil
+}
+
+// MarshalBinary serialises the certificate as overlay | expiry | signature.
+func (c *Certificate) MarshalBinary() ([]byte, error) {
+	if len(c.Overlay.Bytes()) != swarm.HashSize || len(c.Signature) != signatureSize {
+		return nil, ErrInvalidCertificate
+	}
+	b := make([]byte, 0, certificateSize)
+	b = append(b, c.Overlay.Bytes()...)
+	b = binary.BigEndian.AppendUint64(b, uint64(c.Expiry.Unix()))
+	return append(b, c.Signature...), nil
+}
+
+// UnmarshalBinary parses the certificate serialised by MarshalBinary.
+func (c *Certificate) UnmarshalBinary(b []byte) error {
+	if len(b) != certificateSize {
+		return ErrInvalidCertificate
+	}
+	c.Overlay = swarm.NewAddress(append([]byte(nil), b[:swarm.HashSize]...))
+	c.Expiry = time.Unix(int64(binary.BigEndian.Uint64(b[swarm.HashSize:swarm.HashSize+8])), 0)
+	c.Signature = append([]byte(nil), b[swarm.HashSize+8:]...)
+	return nil
+}
+
+// Issuer recovers the Ethereum address of the certificate issuer.
+func (c *Certificate) Issuer() (common.Address, error) {
+	pub, err := crypto.Recover(c.Signature, certificateData(c.Overlay, c.Expiry))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+	addr, err := crypto.NewEthereumAddress(*pub)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+	return common.BytesToAddress(addr), nil
+}
+
+var _ p2p.CertificateVerifier = (*Service)(nil)
+
+// Service verifies the certificates of the peers against the CA and keeps
+// the certificates of the verified peers to be gossiped with their addresses.
+type Service struct {
+	ca     common.Address
+	logger log.Logger
+	now    func() time.Time
+
+	mu            sync.RWMutex
+	certificates  map[string][]byte   // verified certificates by overlay
+	revoked       map[string]struct{} // revoked overlays
+	revokeHandler func(swarm.Address)
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns the membership service of the private network of the CA.
+func New(ca common.Address, logger log.Logger) *Service {
+	return &Service{
+		ca:           ca,
+		logger:       logger.WithName(loggerName).Register(),
+		now:          time.Now,
+		certificates: make(map[string][]byte),
+		revoked:      make(map[string]struct{}),
+		quit:         make(chan struct{}),
+	}
+}
+
+// Verify checks that the certificate permits the overlay address to join
+// the network. It implements the p2p.CertificateVerifier interface.
+func (s *Service) Verify(overlay swarm.Address, certificate []byte) error {
+	if len(certificate) == 0 {
+		return ErrNoCertificate
+	}
+
+	var c Certificate
+	if err := c.UnmarshalBinary(certificate); err != nil {
+		return err
+	}
+	if !c.Overlay.Equal(overlay) {
+		return ErrInvalidCertificate
+	}
+	issuer, err := c.Issuer()
+	if err != nil {
+		return err
+	}
+	if issuer != s.ca {
+		return ErrInvalidCertificate
+	}
+	if !s.now().Before(c.Expiry) {
+		return ErrCertificateExpired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.revoked[overlay.ByteString()]; ok {
+		return ErrRevoked
+	}
+	s.certificates[overlay.ByteString()] = certificate
+	return nil
+}
+
+// Certificate returns the verified certificate of the permitted peer.
+func (s *Service) Certificate(overlay swarm.Address) ([]byte, bool) {
+	s.mu.RLock()
+	certificate, ok := s.certificates[overlay.ByteString()]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	var c Certificate
+	if err := c.UnmarshalBinary(certificate); err != nil || !s.now().Before(c.Expiry) {
+		return nil, false
+	}
+	return certificate, true
+}
+
+// Permitted reports whether the peer presented a valid certificate
+// and its membership is not revoked.
+func (s *Service) Permitted(overlay swarm.Address) bool {
+	_, ok := s.Certificate(overlay)
+	return ok
+}
+
+// SetRevokeHandler sets the function called with the overlay addresses
+// of the peers whose membership got revoked.
+func (s *Service) SetRevokeHandler(f func(swarm.Address)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokeHandler = f
+}
+
+// Revoke revokes the membership of the overlay addresses.
+func (s *Service) Revoke(overlays ...swarm.Address) {
+	var revoked []swarm.Address
+
+	s.mu.Lock()
+	for _, o := range overlays {
+		if _, ok := s.revoked[o.ByteString()]; ok {
+			continue
+		}
+		s.revoked[o.ByteString()] = struct{}{}
+		delete(s.certificates, o.ByteString())
+		revoked = append(revoked, o)
+	}
+	handler := s.revokeHandler
+	s.mu.Unlock()
+
+	for _, o := range revoked {
+		s.logger.Info("membership revoked", "peer_address", o)
+		if handler != nil {
+			handler(o)
+		}
+	}
+}
+
+// Close stops watching the revocation feed.
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
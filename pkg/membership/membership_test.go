@@ -0,0 +1,197 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package membership_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/membership"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func newCA(t *testing.T) (crypto.Signer, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	addr, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, addr
+}
+
+func issue(t *testing.T, ca crypto.Signer, overlay swarm.Address, expiry time.Time) []byte {
+	t.Helper()
+
+	c, err := membership.Issue(ca, overlay, expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ca, caAddr = newCA(t)
+		other, _   = newCA(t)
+		overlay    = swarm.RandAddress(t)
+		expiry     = time.Now().Add(time.Hour)
+	)
+
+	for _, tc := range []struct {
+		name        string
+		overlay     swarm.Address
+		certificate []byte
+		want        error
+	}{
+		{
+			name:        "valid",
+			overlay:     overlay,
+			certificate: issue(t, ca, overlay, expiry),
+		},
+		{
+			name:    "missing",
+			overlay: overlay,
+			want:    membership.ErrNoCertificate,
+		},
+		{
+			name:        "malformed",
+			overlay:     overlay,
+			certificate: []byte{1, 2, 3},
+			want:        membership.ErrInvalidCertificate,
+		},
+		{
+			name:        "other overlay",
+			overlay:     swarm.RandAddress(t),
+			certificate: issue(t, ca, overlay, expiry),
+			want:        membership.ErrInvalidCertificate,
+		},
+		{
+			name:        "other issuer",
+			overlay:     overlay,
+			certificate: issue(t, other, overlay, expiry),
+			want:        membership.ErrInvalidCertificate,
+		},
+		{
+			name:        "expired",
+			overlay:     overlay,
+			certificate: issue(t, ca, overlay, time.Now().Add(-time.Hour)),
+			want:        membership.ErrCertificateExpired,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := membership.New(caAddr, log.Noop)
+			err := s.Verify(tc.overlay, tc.certificate)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+			if permitted := s.Permitted(tc.overlay); permitted != (tc.want == nil) {
+				t.Fatalf("got permitted %t, want %t", permitted, tc.want == nil)
+			}
+		})
+	}
+}
+
+func TestRevocation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ca, caAddr = newCA(t)
+		store      = inmemchunkstore.New()
+		revoked    = swarm.RandAddress(t)
+		member     = swarm.RandAddress(t)
+		expiry     = time.Now().Add(time.Hour)
+		ctx        = context.Background()
+	)
+
+	s := membership.New(caAddr, log.Noop)
+	var disconnected []swarm.Address
+	s.SetRevokeHandler(func(overlay swarm.Address) {
+		disconnected = append(disconnected, overlay)
+	})
+
+	for _, o := range []swarm.Address{revoked, member} {
+		if err := s.Verify(o, issue(t, ca, o, expiry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no revocation feed yet
+	if err := membership.UpdateRevocations(s, ctx, store, store); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := cac.New([]byte("# revoked members\n" + revoked.String() + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, list); err != nil {
+		t.Fatal(err)
+	}
+	putter, err := feeds.NewPutter(store, ca, membership.RevocationTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := putter.Put(ctx, sequence.NewIndex(0), time.Now().Unix(), list.Address().Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := membership.UpdateRevocations(s, ctx, store, store); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(disconnected) != 1 || !disconnected[0].Equal(revoked) {
+		t.Fatalf("got revoked peers %v, want %v", disconnected, revoked)
+	}
+	if s.Permitted(revoked) {
+		t.Fatal("revoked peer permitted")
+	}
+	if !s.Permitted(member) {
+		t.Fatal("member not permitted")
+	}
+	if err := s.Verify(revoked, issue(t, ca, revoked, expiry)); !errors.Is(err, membership.ErrRevoked) {
+		t.Fatalf("got error %v, want %v", err, membership.ErrRevoked)
+	}
+}
+
+func TestParseRevocationList(t *testing.T) {
+	t.Parallel()
+
+	overlay := swarm.RandAddress(t)
+	got, err := membership.ParseRevocationList([]byte("\n# comment\n  " + overlay.String() + "  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Equal(overlay) {
+		t.Fatalf("got %v, want %v", got, overlay)
+	}
+
+	_, err = membership.ParseRevocationList([]byte("not an address"))
+	if !errors.Is(err, membership.ErrInvalidRevocationList) {
+		t.Fatalf("got error %v, want %v", err, membership.ErrInvalidRevocationList)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package membership
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	// DefaultRevocationInterval is the default interval of reading the revocation feed.
+	DefaultRevocationInterval = 5 * time.Minute
+
+	revocationTimeout = time.Minute
+	// maxRevocationListSize limits the size of the revocation list read from the feed.
+	maxRevocationListSize = 4 * 1024 * 1024
+)
+
+// RevocationTopic is the topic of the sequence feed of the CA which refers
+// to the current revocation list. Being a feed of the CA, the updates are
+// signed by the CA key.
+var RevocationTopic = mustKeccak256("swarm-membership-revocations")
+
+// ErrInvalidRevocationList is returned when the revocation list cannot be parsed.
+var ErrInvalidRevocationList = errors.New("invalid revocation list")
+
+func mustKeccak256(s string) []byte {
+	h, err := crypto.LegacyKeccak256([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// ParseRevocationList parses the hex encoded overlay addresses of the
+// revocation list, one per line. Empty lines and lines starting with
+// # are ignored.
+func ParseRevocationList(data []byte) ([]swarm.Address, error) {
+	var overlays []swarm.Address
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		overlay, err := swarm.ParseHexAddress(line)
+		if err != nil || len(overlay.Bytes()) != swarm.HashSize {
+			return nil, fmt.Errorf("%w: line %q", ErrInvalidRevocationList, line)
+		}
+		overlays = append(overlays, overlay)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRevocationList, err)
+	}
+	return overlays, nil
+}
+
+// WatchRevocations periodically reads the latest revocation list referred
+// by the revocation feed of the CA and revokes the listed memberships.
+// The revocations are never lifted, a revoked member needs a new overlay.
+func (s *Service) WatchRevocations(getter storage.Getter, putter storage.Putter, interval time.Duration) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), revocationTimeout)
+			if err := s.updateRevocations(ctx, getter, putter); err != nil {
+				s.logger.Debug("revocation list update failed", "error", err)
+			}
+			cancel()
+
+			select {
+			case <-s.quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Service) updateRevocations(ctx context.Context, getter storage.Getter, putter storage.Putter) error {
+	ch, _, _, err := sequence.NewFinder(getter, feeds.New(RevocationTopic, s.ca)).At(ctx, time.Now().Unix(), 0)
+	if err != nil {
+		return fmt.Errorf("lookup revocation feed: %w", err)
+	}
+	if ch == nil {
+		return nil // no revocations
+	}
+
+	_, ref, err := feeds.FromChunk(ch)
+	if err != nil {
+		return fmt.Errorf("parse revocation feed update: %w", err)
+	}
+	if len(ref) != swarm.HashSize {
+		return fmt.Errorf("%w: unsupported reference length %d", ErrInvalidRevocationList, len(ref))
+	}
+
+	j, size, err := joiner.New(ctx, getter, putter, swarm.NewAddress(ref))
+	if err != nil {
+		return fmt.Errorf("join revocation list: %w", err)
+	}
+	if size > maxRevocationListSize {
+		return fmt.Errorf("%w: size %d", ErrInvalidRevocationList, size)
+	}
+	data, err := io.ReadAll(j)
+	if err != nil {
+		return fmt.Errorf("read revocation list: %w", err)
+	}
+
+	overlays, err := ParseRevocationList(data)
+	if err != nil {
+		return err
+	}
+	s.Revoke(overlays...)
+	return nil
+}
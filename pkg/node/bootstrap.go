@@ -96,7 +96,8 @@ func bootstrapNode(
 
 	p2ps, err := libp2p.New(p2pCtx, signer, networkID, swarmAddress, addr, addressbook, stateStore, lightNodes, logger, tracer, libp2p.Options{
 		PrivateKey:     libp2pPrivateKey,
-		NATAddr:        o.NATAddr,
+		Identity:       o.Libp2pIdentity,
+		NATAddrs:       o.NATAddrs,
 		EnableWS:       o.EnableWS,
 		WelcomeMessage: o.WelcomeMessage,
 		FullNode:       false,
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
 	"strings"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/swapprotocol"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/transaction/failover"
 	"github.com/ethersphere/bee/v2/pkg/transaction/wrapped"
 	"github.com/ethersphere/go-sw3-abi/sw3abi"
 	"github.com/prometheus/client_golang/prometheus"
@@ -43,13 +45,18 @@ const (
 	additionalConfirmations = 2
 )
 
-// InitChain will initialize the Ethereum backend at the given endpoint and
+// InitChain will initialize the Ethereum backend at the given endpoints and
 // set up the Transaction Service to interact with it using the provided signer.
+// With several endpoints the calls fail over between them and quorum sets the
+// number of endpoints cross-checked for the block number, logs and receipts.
+// The transaction options set the replacement policies of the stuck transactions.
 func InitChain(
 	ctx context.Context,
 	logger log.Logger,
 	stateStore storage.StateStorer,
-	endpoint string,
+	endpoints []string,
+	quorum int,
+	txOptions transaction.Options,
 	oChainID int64,
 	signer crypto.Signer,
 	pollingInterval time.Duration,
@@ -61,21 +68,11 @@ func InitChain(
 
 	if chainEnabled {
 		// connect to the real one
-		rpcClient, err := rpc.DialContext(ctx, endpoint)
+		b, err := dialChainBackend(ctx, logger, endpoints, quorum)
 		if err != nil {
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("dial blockchain client: %w", err)
+			return nil, common.Address{}, 0, nil, nil, err
 		}
-
-		var versionString string
-		err = rpcClient.CallContext(ctx, &versionString, "web3_clientVersion")
-		if err != nil {
-			logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, sepolia in testnet) is required; check your node or specify another node using --swap-endpoint.", "backend_endpoint", endpoint)
-			return nil, common.Address{}, 0, nil, nil, fmt.Errorf("blockchain client get version: %w", err)
-		}
-
-		logger.Info("connected to blockchain backend", "version", versionString)
-
-		backend = wrapped.NewBackend(ethclient.NewClient(rpcClient))
+		backend = wrapped.NewBackend(b)
 	}
 
 	chainID, err := backend.ChainID(ctx)
@@ -90,7 +87,7 @@ func InitChain(
 
 	transactionMonitor := transaction.NewMonitor(logger, backend, overlayEthAddress, pollingInterval, cancellationDepth)
 
-	transactionService, err := transaction.NewService(logger, overlayEthAddress, backend, signer, stateStore, chainID, transactionMonitor)
+	transactionService, err := transaction.NewService(logger, overlayEthAddress, backend, signer, stateStore, chainID, transactionMonitor, txOptions)
 	if err != nil {
 		return nil, common.Address{}, 0, nil, nil, fmt.Errorf("new transaction service: %w", err)
 	}
@@ -98,6 +95,78 @@ func InitChain(
 	return backend, overlayEthAddress, chainID.Int64(), transactionMonitor, transactionService, nil
 }
 
+// dialChainBackend connects to the endpoints. A single endpoint is used
+// directly, several endpoints are combined into a failover backend that
+// requires at least one of them to respond.
+func dialChainBackend(ctx context.Context, logger log.Logger, endpoints []string, quorum int) (transaction.Backend, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no blockchain rpc endpoint")
+	}
+
+	var (
+		failoverEndpoints []failover.Endpoint
+		names             = make(map[string]int)
+		connected         bool
+	)
+	for i, endpoint := range endpoints {
+		name := endpointName(endpoint, i)
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, names[name])
+		}
+
+		rpcClient, err := rpc.DialContext(ctx, endpoint)
+		if err != nil {
+			if len(endpoints) == 1 {
+				return nil, fmt.Errorf("dial blockchain client: %w", err)
+			}
+			logger.Warning("could not dial blockchain backend", "backend_endpoint", name, "error", err)
+			continue
+		}
+
+		var versionString string
+		err = rpcClient.CallContext(ctx, &versionString, "web3_clientVersion")
+		if err != nil {
+			if len(endpoints) == 1 {
+				logger.Info("could not connect to backend; in a swap-enabled network a working blockchain node (for xdai network in production, sepolia in testnet) is required; check your node or specify another node using --swap-endpoint.", "backend_endpoint", endpoint)
+				rpcClient.Close()
+				return nil, fmt.Errorf("blockchain client get version: %w", err)
+			}
+			logger.Warning("could not connect to blockchain backend", "backend_endpoint", name, "error", err)
+		} else {
+			connected = true
+			logger.Info("connected to blockchain backend", "backend_endpoint", name, "version", versionString)
+		}
+
+		failoverEndpoints = append(failoverEndpoints, failover.Endpoint{
+			Name:    name,
+			Backend: ethclient.NewClient(rpcClient),
+		})
+	}
+
+	if !connected {
+		for _, e := range failoverEndpoints {
+			e.Backend.Close()
+		}
+		return nil, errors.New("blockchain client get version: no blockchain rpc endpoint is reachable")
+	}
+
+	if len(failoverEndpoints) == 1 {
+		return failoverEndpoints[0].Backend, nil
+	}
+
+	return failover.New(failoverEndpoints, logger, failover.Options{Quorum: quorum})
+}
+
+// endpointName returns the host of the endpoint, so that the credentials
+// in the URL do not end up in the logs and the metrics.
+func endpointName(endpoint string, i int) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("endpoint-%d", i)
+	}
+	return u.Host
+}
+
 // InitChequebookFactory will initialize the chequebook factory with the given
 // chain backend.
 func InitChequebookFactory(logger log.Logger, backend transaction.Backend, chainID int64, transactionService transaction.Service, factoryAddress string) (chequebook.Factory, error) {
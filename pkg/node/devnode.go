@@ -23,6 +23,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/bzz"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	mockP2P "github.com/ethersphere/bee/v2/pkg/p2p/mock"
 	mockPingPong "github.com/ethersphere/bee/v2/pkg/pingpong/mock"
@@ -33,6 +34,7 @@ import (
 	mockPostContract "github.com/ethersphere/bee/v2/pkg/postage/postagecontract/mock"
 	postagetesting "github.com/ethersphere/bee/v2/pkg/postage/testing"
 	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
 	"github.com/ethersphere/bee/v2/pkg/pushsync"
 	mockPushsync "github.com/ethersphere/bee/v2/pkg/pushsync/mock"
 	resolverMock "github.com/ethersphere/bee/v2/pkg/resolver/mock"
@@ -66,6 +68,7 @@ type DevBee struct {
 	localstoreCloser    io.Closer
 	apiCloser           io.Closer
 	pssCloser           io.Closer
+	gsocCloser          io.Closer
 	accesscontrolCloser io.Closer
 	errorLogWriter      io.Writer
 	apiServer           *http.Server
@@ -198,6 +201,9 @@ func NewDevBee(logger log.Logger, o *DevOptions) (b *DevBee, err error) {
 	pssService := pss.New(mockKey, logger)
 	b.pssCloser = pssService
 
+	gsocListener := gsoc.New(logger)
+	b.gsocCloser = gsocListener
+
 	pssService.SetPushSyncer(mockPushsync.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
 		pssService.TryUnwrap(chunk)
 		return &pushsync.Receipt{}, nil
@@ -342,6 +348,8 @@ func NewDevBee(logger log.Logger, o *DevOptions) (b *DevBee, err error) {
 		Storer:          localStore,
 		Resolver:        mockResolver,
 		Pss:             pssService,
+		PssMailbox:      mailbox.New(localStore.Download(true), stateStore, &mockKey.PublicKey, crypto.NewDH(mockKey), logger),
+		Gsoc:            gsocListener,
 		FeedFactory:     mockFeeds,
 		Post:            post,
 		AccessControl:   accesscontrol,
@@ -431,6 +439,7 @@ func (b *DevBee) Shutdown() error {
 	}
 
 	tryClose(b.pssCloser, "pss")
+	tryClose(b.gsocCloser, "gsoc")
 	tryClose(b.accesscontrolCloser, "accesscontrol")
 	tryClose(b.tracerCloser, "tracer")
 	tryClose(b.stateStoreCloser, "statestore")
@@ -26,13 +26,18 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/accesscontrol"
 	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
 	"github.com/ethersphere/bee/v2/pkg/addressbook"
 	"github.com/ethersphere/bee/v2/pkg/api"
 	"github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/crawler"
 	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
 	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
+	"github.com/ethersphere/bee/v2/pkg/gsoc"
 	"github.com/ethersphere/bee/v2/pkg/hive"
 	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/membership"
 	"github.com/ethersphere/bee/v2/pkg/metrics"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p"
@@ -45,15 +50,22 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/pricer"
 	"github.com/ethersphere/bee/v2/pkg/pricing"
 	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
 	"github.com/ethersphere/bee/v2/pkg/puller"
 	"github.com/ethersphere/bee/v2/pkg/pullsync"
 	"github.com/ethersphere/bee/v2/pkg/pusher"
 	"github.com/ethersphere/bee/v2/pkg/pushsync"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	resolvercache "github.com/ethersphere/bee/v2/pkg/resolver/cache"
+	"github.com/ethersphere/bee/v2/pkg/resolver/dnslink"
+	feedresolver "github.com/ethersphere/bee/v2/pkg/resolver/feed"
 	"github.com/ethersphere/bee/v2/pkg/resolver/multiresolver"
 	"github.com/ethersphere/bee/v2/pkg/retrieval"
 	"github.com/ethersphere/bee/v2/pkg/salud"
 	"github.com/ethersphere/bee/v2/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/erc20"
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/priceoracle"
@@ -64,6 +76,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
 	"github.com/ethersphere/bee/v2/pkg/storer"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
 	"github.com/ethersphere/bee/v2/pkg/topology"
 	"github.com/ethersphere/bee/v2/pkg/topology/kademlia"
 	"github.com/ethersphere/bee/v2/pkg/topology/lightnode"
@@ -73,7 +86,9 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/util/ioutil"
 	"github.com/ethersphere/bee/v2/pkg/util/nbhdutil"
 	"github.com/ethersphere/bee/v2/pkg/util/syncutil"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 	"github.com/hashicorp/go-multierror"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	ma "github.com/multiformats/go-multiaddr"
 	promc "github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/crypto/sha3"
@@ -100,23 +115,32 @@ type Bee struct {
 	pusherCloser             io.Closer
 	pullerCloser             io.Closer
 	accountingCloser         io.Closer
+	ledgerCloser             io.Closer
 	pullSyncCloser           io.Closer
 	pssCloser                io.Closer
+	gsocCloser               io.Closer
 	ethClientCloser          func()
 	transactionMonitorCloser io.Closer
 	transactionCloser        io.Closer
 	listenerCloser           io.Closer
 	postageServiceCloser     io.Closer
 	priceOracleCloser        io.Closer
+	autoCashoutCloser        io.Closer
+	pricerCloser             io.Closer
 	hiveCloser               io.Closer
 	saludCloser              io.Closer
 	storageIncetivesCloser   io.Closer
+	stakingWatcherCloser     io.Closer
 	pushSyncCloser           io.Closer
 	retrievalCloser          io.Closer
 	shutdownInProgress       bool
 	shutdownMutex            sync.Mutex
 	syncingStopped           *syncutil.Signaler
 	accesscontrolCloser      io.Closer
+	reputationCloser         io.Closer
+	upstreamCloser           io.Closer
+	crawlerCloser            io.Closer
+	membershipCloser         io.Closer
 }
 
 type Options struct {
@@ -128,7 +152,7 @@ type Options struct {
 	DBDisableSeeksCompaction      bool
 	APIAddr                       string
 	Addr                          string
-	NATAddr                       string
+	NATAddrs                      []string
 	EnableWS                      bool
 	WelcomeMessage                string
 	Bootnodes                     []string
@@ -141,9 +165,21 @@ type Options struct {
 	PaymentTolerance              int64
 	PaymentEarly                  int64
 	ResolverConnectionCfgs        []multiresolver.ConnectionConfig
+	ResolverFeedCfgs              []multiresolver.FeedConfig
+	ResolverDNSLink               bool
+	ResolverCacheOptions          resolvercache.Options
 	RetrievalCaching              bool
 	BootnodeMode                  bool
-	BlockchainRpcEndpoint         string
+	BlockchainRpcEndpoints        []string
+	BlockchainRpcQuorum           int
+	TransactionOptions            transaction.Options
+	CashoutOptions                autocashout.Options
+	AccountingLedgerEnable        bool
+	AccountingLedgerRetention     time.Duration
+	DynamicPricingEnable          bool
+	DynamicPricingOptions         pricer.DynamicOptions
+	DynamicPricingQueueCapacity   int64
+	DynamicPricingBandwidth       int64
 	SwapFactoryAddress            string
 	SwapInitialDeposit            string
 	SwapEnable                    bool
@@ -162,15 +198,41 @@ type Options struct {
 	BlockProfile                  bool
 	MutexProfile                  bool
 	StaticNodes                   []swarm.Address
+	UpstreamPeers                 []string
+	P2PUploadRate                 int64
+	P2PDownloadRate               int64
+	P2PProtocolTraffic            []string
 	AllowPrivateCIDRs             bool
 	UsePostageSnapshot            bool
 	EnableStorageIncentives       bool
 	StatestoreCacheCapacity       uint64
 	TargetNeighborhood            string
 	NeighborhoodSuggester         string
-	WhitelistedWithdrawalAddress  []string
+	WalletOptions                 wallet.Options
+	StakingWebhookURL             string
 	TrxDebugMode                  bool
 	ReserveMinimumRadius          uint
+	TenantTokens                  map[string]string
+	LocalstoreEncryptionKey       []byte
+	PssAckBatchID                 []byte
+	// NetworkCA enables the private network mode, in which only the peers
+	// with the membership certificates issued by the CA are accepted.
+	NetworkCA          *common.Address
+	NetworkCertificate []byte
+	// Libp2pIdentity, PssDH and PssPublicKey are used in place of the libp2p
+	// and pss private keys when the keys are kept by an external signer.
+	Libp2pIdentity libp2pcrypto.PrivKey
+	PssDH          crypto.DH
+	PssPublicKey   *ecdsa.PublicKey
+	// PssPreviousKeys and ActPreviousSessions give access to the messages and
+	// the content shared with the previous versions of the rotated pss and
+	// ACT keys. ActPublicKey is the current ACT key used as the publisher,
+	// the swarm public key is used if it is not set.
+	PssPreviousKeys     []pss.RotatedKey
+	PssKeyVersion       uint32
+	ActPublicKey        *ecdsa.PublicKey
+	ActPreviousSessions []accesscontrol.RotatedSession
+	ActKeyVersion       uint32
 }
 
 const (
@@ -188,6 +250,8 @@ const (
 	reserveTreshold               = ReserveCapacity * 5 / 10
 	reserveMinEvictCount          = 1_000
 	cacheMinEvictCount            = 10_000
+	resolverTimeout               = 30 * time.Second // timeout of the feed and DNSLink name resolutions
+	stakingWatchBlocks            = 12               // number of blocks between the checks of the staking contract events
 )
 
 func NewBee(
@@ -248,11 +312,24 @@ func NewBee(
 		}
 	}(b)
 
+	pssDH, pssPublicKey := o.PssDH, o.PssPublicKey
+	if pssDH == nil {
+		pssDH = crypto.NewDH(pssPrivateKey)
+		pssPublicKey = &pssPrivateKey.PublicKey
+	}
+
 	stateStore, stateStoreMetrics, err := InitStateStore(logger, o.DataDir, o.StatestoreCacheCapacity)
 	if err != nil {
 		return nil, err
 	}
 
+	if o.LocalstoreEncryptionKey != nil {
+		stateStore, err = EncryptStateStore(stateStore, o.LocalstoreEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("statestore encryption: %w", err)
+		}
+	}
+
 	pubKey, err := signer.PublicKey()
 	if err != nil {
 		return nil, err
@@ -277,7 +354,7 @@ func NewBee(
 		}
 	}
 
-	var changedOverlay bool
+	previousOverlay := swarmAddress
 	if targetNeighborhood != "" {
 		neighborhood, err := swarm.ParseBitStrAddress(targetNeighborhood)
 		if err != nil {
@@ -310,13 +387,17 @@ func NewBee(
 				}
 			}
 
+			err = staking.SavePendingMigration(stateStore, previousOverlay, newSwarmAddress, common.BytesToHash(newNonce))
+			if err != nil {
+				return nil, fmt.Errorf("statestore: save pending stake migration: %w", err)
+			}
+
 			swarmAddress = newSwarmAddress
 			nonce = newNonce
 			err = setOverlay(stateStore, swarmAddress, nonce)
 			if err != nil {
 				return nil, fmt.Errorf("statestore: save new overlay: %w", err)
 			}
-			changedOverlay = true
 		}
 	}
 
@@ -350,7 +431,7 @@ func NewBee(
 		erc20Service       erc20.Service
 	)
 
-	chainEnabled := isChainEnabled(o, o.BlockchainRpcEndpoint, logger)
+	chainEnabled := isChainEnabled(o, o.BlockchainRpcEndpoints, logger)
 
 	var batchStore postage.Storer = new(postage.NoOpBatchStore)
 	var evictFn func([]byte) error
@@ -369,11 +450,17 @@ func NewBee(
 		}
 	}
 
+	walletService := wallet.New(logger, stateStore, o.WalletOptions)
+	txOptions := o.TransactionOptions
+	txOptions.AuditLog = walletService
+
 	chainBackend, overlayEthAddress, chainID, transactionMonitor, transactionService, err = InitChain(
 		ctx,
 		logger,
 		stateStore,
-		o.BlockchainRpcEndpoint,
+		o.BlockchainRpcEndpoints,
+		o.BlockchainRpcQuorum,
+		txOptions,
 		o.ChainID,
 		signer,
 		o.BlockTime,
@@ -433,9 +520,9 @@ func NewBee(
 
 		apiService = api.New(
 			*publicKey,
-			pssPrivateKey.PublicKey,
+			*pssPublicKey,
 			overlayEthAddress,
-			o.WhitelistedWithdrawalAddress,
+			whitelistedWithdrawalAddresses(o.WalletOptions.Addresses),
 			logger,
 			transactionService,
 			batchStore,
@@ -446,6 +533,11 @@ func NewBee(
 			o.CORSAllowedOrigins,
 			stamperStore,
 		)
+		actPublicKey := publicKey
+		if o.ActPublicKey != nil {
+			actPublicKey = o.ActPublicKey
+		}
+		apiService.SetRotatedKeys(*actPublicKey, o.ActKeyVersion, o.PssKeyVersion)
 		apiService.MountTechnicalDebug()
 		apiService.SetProbe(probe)
 
@@ -541,6 +633,31 @@ func NewBee(
 		bootnodes = append(bootnodes, addr)
 	}
 
+	upstreamPeers := make([]ma.Multiaddr, 0, len(o.UpstreamPeers))
+	for _, a := range o.UpstreamPeers {
+		addr, err := ma.NewMultiaddr(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream peer multiaddress %q: %w", a, err)
+		}
+		upstreamPeers = append(upstreamPeers, addr)
+	}
+	if o.FullNodeMode && len(upstreamPeers) > 0 {
+		logger.Warning("upstream peers are only used by light nodes, ignoring them")
+	}
+
+	traffic := libp2p.TrafficOptions{
+		UploadRate:   o.P2PUploadRate,
+		DownloadRate: o.P2PDownloadRate,
+		Protocols:    make(map[string]libp2p.ProtocolTraffic, len(o.P2PProtocolTraffic)),
+	}
+	for _, v := range o.P2PProtocolTraffic {
+		name, budget, err := libp2p.ParseProtocolTraffic(v)
+		if err != nil {
+			return nil, err
+		}
+		traffic.Protocols[name] = budget
+	}
+
 	// Perform checks related to payment threshold calculations here to not duplicate
 	// the checks in bootstrap process
 	paymentThreshold, ok := new(big.Int).SetString(o.PaymentThreshold, 10)
@@ -597,16 +714,32 @@ func NewBee(
 		registry = apiService.MetricsRegistry()
 	}
 
-	p2ps, err := libp2p.New(ctx, signer, networkID, swarmAddress, addr, addressbook, stateStore, lightNodes, logger, tracer, libp2p.Options{
+	p2pOpts := libp2p.Options{
 		PrivateKey:      libp2pPrivateKey,
-		NATAddr:         o.NATAddr,
+		Identity:        o.Libp2pIdentity,
+		NATAddrs:        o.NATAddrs,
 		EnableWS:        o.EnableWS,
 		WelcomeMessage:  o.WelcomeMessage,
 		FullNode:        o.FullNodeMode,
 		Nonce:           nonce,
 		ValidateOverlay: chainEnabled,
 		Registry:        registry,
-	})
+		Traffic:         traffic,
+	}
+
+	var membershipService *membership.Service
+	if o.NetworkCA != nil {
+		membershipService = membership.New(*o.NetworkCA, logger)
+		if err := membershipService.Verify(swarmAddress, o.NetworkCertificate); err != nil {
+			return nil, fmt.Errorf("private network certificate: %w", err)
+		}
+		b.membershipCloser = membershipService
+		p2pOpts.Certificate = o.NetworkCertificate
+		p2pOpts.CertificateVerifier = membershipService
+		logger.Info("private network mode enabled", "certificate_authority", *o.NetworkCA)
+	}
+
+	p2ps, err := libp2p.New(ctx, signer, networkID, swarmAddress, addr, addressbook, stateStore, lightNodes, logger, tracer, p2pOpts)
 	if err != nil {
 		return nil, fmt.Errorf("p2p service: %w", err)
 	}
@@ -666,7 +799,7 @@ func NewBee(
 	eventListener = listener.New(b.syncingStopped, logger, chainBackend, postageStampContractAddress, postageStampContractABI, o.BlockTime, postageSyncingStallingTimeout, postageSyncingBackoffTimeout)
 	b.listenerCloser = eventListener
 
-	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync)
+	batchSvc, err = batchservice.New(stateStore, batchStore, logger, eventListener, overlayEthAddress.Bytes(), post, sha3.New256, o.Resync, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -684,11 +817,28 @@ func NewBee(
 		return nil, fmt.Errorf("hive service: %w", err)
 	}
 	b.hiveCloser = hive
+	p2ps.SetAddressChangedHandler(hive.AnnounceAddress)
+	if membershipService != nil {
+		hive.SetMembership(membershipService)
+		membershipService.SetRevokeHandler(func(overlay swarm.Address) {
+			_ = p2ps.Disconnect(overlay, "private network membership revoked")
+		})
+	}
 
-	var swapService *swap.Service
+	var (
+		swapService *swap.Service
+		autoCashout *autocashout.Service
+	)
+
+	peerReputation, err := reputation.New(stateStore, logger, reputation.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("reputation service: %w", err)
+	}
+	b.reputationCloser = peerReputation
+	hive.SetReputation(peerReputation)
 
 	kad, err := kademlia.New(swarmAddress, addressbook, hive, p2ps, logger,
-		kademlia.Options{Bootnodes: bootnodes, BootnodeMode: o.BootnodeMode, StaticNodes: o.StaticNodes, DataDir: o.DataDir})
+		kademlia.Options{Bootnodes: bootnodes, BootnodeMode: o.BootnodeMode, StaticNodes: o.StaticNodes, DataDir: o.DataDir, Reputation: peerReputation})
 	if err != nil {
 		return nil, fmt.Errorf("unable to create kademlia: %w", err)
 	}
@@ -718,6 +868,7 @@ func NewBee(
 		Logger:                    logger,
 		Tracer:                    tracer,
 		CacheMinEvictCount:        cacheMinEvictCount,
+		EncryptionKey:             o.LocalstoreEncryptionKey,
 	}
 
 	if o.FullNodeMode && !o.BootnodeMode {
@@ -735,7 +886,7 @@ func NewBee(
 	b.localstoreCloser = localStore
 	evictFn = func(id []byte) error { return localStore.EvictBatch(context.Background(), id) }
 
-	actLogic := accesscontrol.NewLogic(session)
+	actLogic := accesscontrol.NewLogic(session, o.ActPreviousSessions...)
 	accesscontrol := accesscontrol.NewController(actLogic)
 	b.accesscontrolCloser = accesscontrol
 
@@ -785,8 +936,6 @@ func NewBee(
 
 	lightPaymentThreshold := new(big.Int).Div(paymentThreshold, big.NewInt(lightFactor))
 
-	pricer := pricer.NewFixedPricer(swarmAddress, basePrice)
-
 	if paymentThreshold.Cmp(minThreshold) < 0 {
 		return nil, fmt.Errorf("payment threshold below minimum generally accepted value, need at least %s", minThreshold)
 	}
@@ -801,6 +950,35 @@ func NewBee(
 		return nil, fmt.Errorf("pricing service: %w", err)
 	}
 
+	var (
+		chunkPricer   pricer.Interface = pricer.NewFixedPricer(swarmAddress, basePrice)
+		dynamicPricer *pricer.DynamicPricer
+	)
+	if o.DynamicPricingEnable {
+		defaults := pricer.DefaultDynamicOptions(basePrice)
+		pricingOptions := o.DynamicPricingOptions
+		pricingOptions.BasePrice = basePrice
+		if pricingOptions.MinPrice == 0 {
+			pricingOptions.MinPrice = defaults.MinPrice
+		}
+		if pricingOptions.MaxPrice == 0 {
+			pricingOptions.MaxPrice = defaults.MaxPrice
+		}
+		if err := pricingOptions.Validate(); err != nil {
+			return nil, fmt.Errorf("dynamic pricing: %w", err)
+		}
+		// the most expensive chunk has to fit into the payment threshold of the light nodes
+		maxChunkPrice := new(big.Int).SetUint64(uint64(swarm.MaxPO+1) * pricingOptions.MaxPrice)
+		if maxChunkPrice.Cmp(lightPaymentThreshold) > 0 {
+			return nil, fmt.Errorf("dynamic pricing: max chunk price %s above the light payment threshold %s", maxChunkPrice, lightPaymentThreshold)
+		}
+
+		dynamicPricer = pricer.NewDynamicPricer(logger, swarmAddress, pricing, pricingOptions)
+		b.pricerCloser = dynamicPricer
+		pricing.SetPriceObserver(dynamicPricer)
+		chunkPricer = dynamicPricer
+	}
+
 	addrs, err := p2ps.Addresses()
 	if err != nil {
 		return nil, fmt.Errorf("get server addresses: %w", err)
@@ -834,6 +1012,15 @@ func NewBee(
 	}
 	b.accountingCloser = acc
 
+	var accountingLedger *ledger.Ledger
+	if o.AccountingLedgerEnable {
+		ledgerOptions := ledger.DefaultOptions()
+		ledgerOptions.Retention = o.AccountingLedgerRetention
+		accountingLedger = ledger.New(logger, stateStore, ledgerOptions)
+		b.ledgerCloser = accountingLedger
+		acc.SetLedger(accountingLedger)
+	}
+
 	pseudosettleService := pseudosettle.New(p2ps, logger, stateStore, acc, new(big.Int).Set(enforcedRefreshRate), big.NewInt(lightRefreshRate), p2ps)
 	if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
 		return nil, fmt.Errorf("pseudosettle service: %w", err)
@@ -862,27 +1049,80 @@ func NewBee(
 		}
 		b.priceOracleCloser = priceOracle
 
+		autoCashout = autocashout.New(logger, stateStore, swapService, chainBackend, overlayEthAddress, o.CashoutOptions)
+		b.autoCashoutCloser = autoCashout
+
 		if o.ChequebookEnable {
 			acc.SetPayFunc(swapService.Pay)
 		}
+		swapService.SetAuditLog(walletService)
+	}
+
+	if accountingLedger != nil {
+		var payments ledger.SettlementSource
+		if swapService != nil {
+			swapService.SetLedger(accountingLedger)
+			payments = swapService
+		}
+		if err := accountingLedger.Open(acc, payments, pseudosettleService); err != nil {
+			return nil, fmt.Errorf("accounting ledger: %w", err)
+		}
 	}
 
 	pricing.SetPaymentThresholdObserver(acc)
 
-	pssService := pss.New(pssPrivateKey, logger)
+	pssPreviousDH := make([]crypto.DH, 0, len(o.PssPreviousKeys))
+	for _, k := range o.PssPreviousKeys {
+		pssPreviousDH = append(pssPreviousDH, k.DH)
+	}
+	pssService := pss.NewWithDH(pssDH, logger, pssPreviousDH...)
 	b.pssCloser = pssService
 
+	gsocListener := gsoc.New(logger)
+	b.gsocCloser = gsocListener
+
 	validStamp := postage.ValidStamp(batchStore)
 
-	pushSyncProtocol := pushsync.New(swarmAddress, nonce, p2ps, localStore, kad, o.FullNodeMode, pssService.TryUnwrap, validStamp, logger, acc, pricer, signer, tracer, warmupTime)
+	nodeStatus := status.NewService(logger, p2ps, kad, beeNodeMode.String(), batchStore, localStore)
+	if err = p2ps.AddProtocol(nodeStatus.Protocol()); err != nil {
+		return nil, fmt.Errorf("status service: %w", err)
+	}
+
+	// light nodes route the pushsync and retrieval requests through the
+	// preferred upstream full nodes, falling back to the topology
+	var (
+		peerSuggester topology.Driver = kad
+		upstream      *lightnode.Upstream
+	)
+	if !o.FullNodeMode && len(upstreamPeers) > 0 {
+		upstream = lightnode.NewUpstream(kad, p2ps, pingPong, nodeStatus, acc, logger, lightnode.UpstreamOptions{Peers: upstreamPeers})
+		b.upstreamCloser = upstream
+		peerSuggester = upstream
+	}
+
+	// bootnodes crawl the network and keep its census
+	var networkCrawler *crawler.Crawler
+	if o.BootnodeMode {
+		networkCrawler = crawler.New(addressbook, p2ps, p2ps, pingPong, nodeStatus, logger, crawler.Options{})
+		b.crawlerCloser = networkCrawler
+	}
+
+	pushSyncProtocol := pushsync.New(swarmAddress, nonce, p2ps, localStore, peerSuggester, o.FullNodeMode, pssService.TryUnwrap, gsocListener.Handle, validStamp, logger, acc, chunkPricer, signer, tracer, warmupTime)
 	b.pushSyncCloser = pushSyncProtocol
+	pushSyncProtocol.SetReputation(peerReputation)
 
 	// set the pushSyncer in the PSS
 	pssService.SetPushSyncer(pushSyncProtocol)
 
-	nodeStatus := status.NewService(logger, p2ps, kad, beeNodeMode.String(), batchStore, localStore)
-	if err = p2ps.AddProtocol(nodeStatus.Protocol()); err != nil {
-		return nil, fmt.Errorf("status service: %w", err)
+	if len(o.PssAckBatchID) > 0 {
+		pssAckBatchID := o.PssAckBatchID
+		pssService.SetAckStamper(func() (postage.Stamper, func() error, error) {
+			issuer, save, err := post.GetStampIssuer(pssAckBatchID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("pss ack stamp issuer: %w", err)
+			}
+			return postage.NewStamper(stamperStore, issuer, signer), save, nil
+		})
 	}
 
 	saludService := salud.New(nodeStatus, kad, localStore, logger, warmupTime, api.FullMode.String(), salud.DefaultMinPeersPerBin, salud.DefaultDurPercentile, salud.DefaultConnsPercentile)
@@ -929,9 +1169,21 @@ func NewBee(
 		}
 	}
 
-	retrieval := retrieval.New(swarmAddress, waitNetworkRFunc, localStore, p2ps, kad, logger, acc, pricer, tracer, o.RetrievalCaching)
+	retrieval := retrieval.New(swarmAddress, waitNetworkRFunc, localStore, p2ps, peerSuggester, logger, acc, chunkPricer, tracer, o.RetrievalCaching)
+	if dynamicPricer != nil {
+		dynamicPricer.Start(
+			pricer.QueueLoad(retrieval.ActiveRequests, o.DynamicPricingQueueCapacity),
+			pricer.BandwidthLoad(p2ps, o.DynamicPricingBandwidth),
+		)
+	}
+	retrieval.SetReputation(peerReputation)
+	acc.SetReputation(peerReputation)
 	localStore.SetRetrievalService(retrieval)
 
+	if membershipService != nil {
+		membershipService.WatchRevocations(localStore.Download(true), localStore.Cache(), membership.DefaultRevocationInterval)
+	}
+
 	pusherService := pusher.New(networkID, localStore, waitNetworkRFunc, pushSyncProtocol, validStamp, logger, warmupTime, pusher.DefaultRetryCount)
 	b.pusherCloser = pusherService
 
@@ -975,19 +1227,25 @@ func NewBee(
 		stakingContractAddress = common.HexToAddress(o.StakingContractAddress)
 	}
 
-	stakingContract := staking.New(overlayEthAddress, stakingContractAddress, abiutil.MustParseABI(chainCfg.StakingABI), bzzTokenAddress, transactionService, common.BytesToHash(nonce), o.TrxDebugMode)
+	stakingContractABI := abiutil.MustParseABI(chainCfg.StakingABI)
+	stakingContract := staking.New(overlayEthAddress, stakingContractAddress, stakingContractABI, bzzTokenAddress, transactionService, common.BytesToHash(nonce), o.TrxDebugMode)
 
-	if chainEnabled && changedOverlay {
-		stake, err := stakingContract.GetPotentialStake(ctx)
+	var stakingWatcher *staking.Watcher
+	if chainEnabled {
+		stakingWatcher, err = staking.NewWatcher(logger, stateStore, chainBackend, stakingContract, stakingContractAddress, stakingContractABI, overlayEthAddress, swarmAddress, staking.WatcherOptions{
+			PollInterval: stakingWatchBlocks * o.BlockTime,
+			WebhookURL:   o.StakingWebhookURL,
+		})
 		if err != nil {
-			return nil, errors.New("getting stake balance")
+			return nil, fmt.Errorf("staking watcher: %w", err)
 		}
-		if stake.Cmp(big.NewInt(0)) > 0 {
-			logger.Debug("changing overlay address in staking contract")
-			tx, err := stakingContract.ChangeStakeOverlay(ctx, common.BytesToHash(nonce))
-			if err != nil {
-				return nil, fmt.Errorf("cannot change staking overlay address: %v", err.Error())
-			}
+		b.stakingWatcherCloser = stakingWatcher
+
+		// a failed migration stays pending and is retried by the watcher
+		tx, err := stakingWatcher.MigratePending(ctx)
+		if err != nil {
+			logger.Warning("cannot change staking overlay address, retrying later", "error", err)
+		} else if tx != (common.Hash{}) {
 			logger.Info("overlay address changed in staking contract", "transaction", tx)
 		}
 	}
@@ -1045,14 +1303,35 @@ func NewBee(
 		}
 
 	}
+	feedFactory := factory.New(localStore.Download(true))
+
 	multiResolver := multiresolver.NewMultiResolver(
 		multiresolver.WithConnectionConfigs(o.ResolverConnectionCfgs),
 		multiresolver.WithLogger(o.Logger),
 		multiresolver.WithDefaultCIDResolver(),
 	)
-	b.resolverCloser = multiResolver
+	for _, c := range o.ResolverFeedCfgs {
+		r, err := feedresolver.New(feedFactory, localStore.Download(true), localStore.Cache(), feeds.New(c.Topic, c.Owner), resolverTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("feed resolver: %w", err)
+		}
+		multiResolver.PushResolver(c.TLD, r)
+	}
+	if o.ResolverDNSLink {
+		multiResolver.PushResolver("", dnslink.New(resolverTimeout))
+	}
+
+	var nameResolver resolver.Interface = multiResolver
+	var resolverCache *resolvercache.Resolver
+	if o.ResolverCacheOptions.TTL > 0 {
+		resolverCache, err = resolvercache.New(multiResolver, o.ResolverCacheOptions)
+		if err != nil {
+			return nil, fmt.Errorf("resolver cache: %w", err)
+		}
+		nameResolver = resolverCache
+	}
+	b.resolverCloser = nameResolver
 
-	feedFactory := factory.New(localStore.Download(true))
 	steward := steward.New(localStore, retrieval, localStore.Cache())
 
 	extraOpts := api.ExtraOptions{
@@ -1060,22 +1339,32 @@ func NewBee(
 		TopologyDriver:  kad,
 		LightNodes:      lightNodes,
 		Accounting:      acc,
+		Ledger:          accountingLedger,
 		Pseudosettle:    pseudosettleService,
 		Swap:            swapService,
 		Chequebook:      chequebookService,
 		BlockTime:       o.BlockTime,
 		Storer:          localStore,
-		Resolver:        multiResolver,
+		Resolver:        nameResolver,
 		Pss:             pssService,
+		PssMailbox:      mailbox.New(localStore.Download(true), stateStore, pssPublicKey, pssDH, logger, o.PssPreviousKeys...),
+		Crawler:         networkCrawler,
+		Gsoc:            gsocListener,
 		FeedFactory:     feedFactory,
 		Post:            post,
 		AccessControl:   accesscontrol,
 		PostageContract: postageStampContractService,
 		Staking:         stakingContract,
+		StakingWatcher:  stakingWatcher,
 		Steward:         steward,
 		SyncStatus:      syncStatusFn,
 		NodeStatus:      nodeStatus,
 		PinIntegrity:    localStore.PinIntegrity(),
+		Tenants:         tenant.New(stateStore, o.TenantTokens),
+		Wallet:          walletService,
+	}
+	if autoCashout != nil {
+		extraOpts.Cashouts = autoCashout
 	}
 
 	if o.APIAddr != "" {
@@ -1101,6 +1390,12 @@ func NewBee(
 		apiService.MustRegisterMetrics(pullSyncProtocol.Metrics()...)
 		apiService.MustRegisterMetrics(retrieval.Metrics()...)
 		apiService.MustRegisterMetrics(lightNodes.Metrics()...)
+		if upstream != nil {
+			apiService.MustRegisterMetrics(upstream.Metrics()...)
+		}
+		if networkCrawler != nil {
+			apiService.MustRegisterMetrics(networkCrawler.Metrics()...)
+		}
 		apiService.MustRegisterMetrics(hive.Metrics()...)
 
 		if bs, ok := batchStore.(metrics.Collector); ok {
@@ -1122,6 +1417,16 @@ func NewBee(
 		apiService.MustRegisterMetrics(pseudosettleService.Metrics()...)
 		if swapService != nil {
 			apiService.MustRegisterMetrics(swapService.Metrics()...)
+			apiService.MustRegisterMetrics(autoCashout.Metrics()...)
+		}
+		if dynamicPricer != nil {
+			apiService.MustRegisterMetrics(dynamicPricer.Metrics()...)
+		}
+		if resolverCache != nil {
+			apiService.MustRegisterMetrics(resolverCache.Metrics()...)
+		}
+		if stakingWatcher != nil {
+			apiService.MustRegisterMetrics(stakingWatcher.Metrics()...)
 		}
 
 		apiService.Configure(signer, tracer, api.Options{
@@ -1140,6 +1445,14 @@ func NewBee(
 		return nil, err
 	}
 
+	if upstream != nil {
+		upstream.Start()
+	}
+
+	if networkCrawler != nil {
+		networkCrawler.Start()
+	}
+
 	if err := p2ps.Ready(); err != nil {
 		return nil, err
 	}
@@ -1204,11 +1517,19 @@ func (b *Bee) Shutdown() error {
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(7)
+	wg.Add(9)
 	go func() {
 		defer wg.Done()
 		tryClose(b.pssCloser, "pss")
 	}()
+	go func() {
+		defer wg.Done()
+		tryClose(b.pricerCloser, "pricer")
+	}()
+	go func() {
+		defer wg.Done()
+		tryClose(b.gsocCloser, "gsoc")
+	}()
 	go func() {
 		defer wg.Done()
 		tryClose(b.pusherCloser, "pusher")
@@ -1239,6 +1560,9 @@ func (b *Bee) Shutdown() error {
 	wg.Wait()
 
 	tryClose(b.p2pService, "p2p server")
+	tryClose(b.ledgerCloser, "accounting ledger")
+	tryClose(b.autoCashoutCloser, "automatic cashout service")
+	tryClose(b.stakingWatcherCloser, "staking watcher")
 	tryClose(b.priceOracleCloser, "price oracle service")
 
 	wg.Add(3)
@@ -1264,7 +1588,11 @@ func (b *Bee) Shutdown() error {
 
 	tryClose(b.accesscontrolCloser, "accesscontrol")
 	tryClose(b.tracerCloser, "tracer")
+	tryClose(b.crawlerCloser, "crawler")
+	tryClose(b.upstreamCloser, "upstream peers")
 	tryClose(b.topologyCloser, "topology driver")
+	tryClose(b.reputationCloser, "reputation")
+	tryClose(b.membershipCloser, "membership")
 	tryClose(b.storageIncetivesCloser, "storage incentives agent")
 	tryClose(b.stateStoreCloser, "statestore")
 	tryClose(b.stamperStoreCloser, "stamperstore")
@@ -1276,8 +1604,8 @@ func (b *Bee) Shutdown() error {
 
 var ErrShutdownInProgress error = errors.New("shutdown in progress")
 
-func isChainEnabled(o *Options, swapEndpoint string, logger log.Logger) bool {
-	chainDisabled := swapEndpoint == ""
+func isChainEnabled(o *Options, swapEndpoints []string, logger log.Logger) bool {
+	chainDisabled := len(swapEndpoints) == 0
 	lightMode := !o.FullNodeMode
 
 	if lightMode && chainDisabled { // ultra light mode is LightNode mode with chain disabled
@@ -1288,3 +1616,13 @@ func isChainEnabled(o *Options, swapEndpoint string, logger log.Logger) bool {
 	logger.Info("starting with an enabled chain backend")
 	return true // all other modes operate require chain enabled
 }
+
+// whitelistedWithdrawalAddresses returns the hex form of the whitelisted
+// withdrawal addresses.
+func whitelistedWithdrawalAddresses(addresses []wallet.Address) []string {
+	hexes := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		hexes = append(hexes, a.Address.Hex())
+	}
+	return hexes
+}
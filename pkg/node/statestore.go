@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/ethersphere/bee/v2/pkg/statestore/encrypted"
 	"github.com/ethersphere/bee/v2/pkg/statestore/storeadapter"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/storage/cache"
@@ -42,6 +44,31 @@ func InitStateStore(logger log.Logger, dataDir string, cacheCapacity uint64) (st
 	return stateStore, caching, err
 }
 
+// sensitiveStatePrefixes lists the key prefixes of the statestore entries that
+// reveal what the node stores on behalf of its users and whom they exchange
+// messages and payments with. They are encrypted at rest together with the
+// localstore.
+var sensitiveStatePrefixes = []string{
+	"tenant_",             // pins, tags and usage of the tenants
+	"pss_mailbox_cursor_", // mailbox topics and senders
+	"wallet_",             // withdrawals and audit log, with the batch ids of the postage purchases
+}
+
+// EncryptStateStore wraps the state store so that the sensitive entries are
+// encrypted at rest with the given key. Existing sensitive entries are
+// encrypted in place when the encryption is enabled for the first time.
+func EncryptStateStore(stateStore storage.StateStorerManager, key []byte) (storage.StateStorerManager, error) {
+	c, err := atrest.New(key)
+	if err != nil {
+		return nil, err
+	}
+	s, err := encrypted.New(stateStore, c, sensitiveStatePrefixes...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 // InitStamperStore will create new stamper store with the given path to the
 // data directory. When given an empty directory path, the function will instead
 // initialize an in-memory state store that will not be persisted.
@@ -0,0 +1,132 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// announcedAddressResolver resolves the list of the configured NAT addresses
+// into the underlays announced to the peers. The domain names are resolved
+// into the IP addresses they currently point to, so that refresh can detect
+// the changes of dynamic DNS records.
+type announcedAddressResolver struct {
+	addrs    []string
+	lookupIP func(host string) ([]net.IP, error)
+
+	mu        sync.RWMutex
+	resolvers []*staticAddressResolver
+	key       string // the resolved addresses used to detect the changes
+}
+
+func newAnnouncedAddressResolver(addrs []string, lookupIP func(host string) ([]net.IP, error)) (*announcedAddressResolver, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses")
+	}
+
+	r := &announcedAddressResolver{
+		addrs:    addrs,
+		lookupIP: lookupIP,
+	}
+	if _, err := r.refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// refresh resolves the configured addresses again and reports whether the
+// resolved set has changed. The previous set is kept if the resolution fails.
+func (r *announcedAddressResolver) refresh() (changed bool, err error) {
+	var (
+		resolvers []*staticAddressResolver
+		keys      []string
+	)
+	for _, addr := range r.addrs {
+		hostports, err := r.resolve(addr)
+		if err != nil {
+			return false, err
+		}
+		for _, hostport := range hostports {
+			resolver, err := newStaticAddressResolver(hostport, r.lookupIP)
+			if err != nil {
+				return false, err
+			}
+			resolvers = append(resolvers, resolver)
+			keys = append(keys, resolver.multiProto+"/"+resolver.port)
+		}
+	}
+	key := strings.Join(keys, ",")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed = r.key != key
+	r.resolvers, r.key = resolvers, key
+	return changed, nil
+}
+
+// resolve returns the host:port pairs of the IP addresses of a domain name
+// ordered by the IP address. The IP addresses and the missing hosts are
+// returned as they are.
+func (r *announcedAddressResolver) resolve(addr string) ([]string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" || net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+
+	ips, err := r.lookupIP(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP or Domain Name %q", host)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("domain name %q has no IP addresses", host)
+	}
+
+	hostports := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		hostports = append(hostports, net.JoinHostPort(ip.String(), port))
+	}
+	sort.Strings(hostports)
+	return hostports, nil
+}
+
+// Resolve returns the announced underlays for the address under which the
+// node is observed by a peer.
+func (r *announcedAddressResolver) Resolve(observedAddress ma.Multiaddr) ([]ma.Multiaddr, error) {
+	r.mu.RLock()
+	resolvers := r.resolvers
+	r.mu.RUnlock()
+
+	var underlays []ma.Multiaddr
+	for _, resolver := range resolvers {
+		a, err := resolver.Resolve(observedAddress)
+		if err != nil {
+			return nil, err
+		}
+		if !containsMultiaddr(underlays, a) {
+			underlays = append(underlays, a)
+		}
+	}
+	return underlays, nil
+}
+
+func containsMultiaddr(addrs []ma.Multiaddr, addr ma.Multiaddr) bool {
+	for _, a := range addrs {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
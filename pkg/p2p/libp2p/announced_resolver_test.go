@@ -0,0 +1,121 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestAnnouncedAddressResolver(t *testing.T) {
+	t.Parallel()
+
+	const observed = "/ip4/127.0.0.1/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd"
+
+	var (
+		mu  sync.Mutex
+		ips = []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")}
+	)
+	lookupIP := func(host string) ([]net.IP, error) {
+		if host != "node.example.com" {
+			return nil, errors.New("no such host")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return ips, nil
+	}
+
+	r, err := libp2p.NewAnnouncedAddressResolver([]string{"node.example.com:1634", "192.168.1.34:", "192.168.1.34:"}, lookupIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observableAddress, err := ma.NewMultiaddr(observed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertResolved := func(t *testing.T, want ...string) {
+		t.Helper()
+
+		got, err := r.Resolve(observableAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i].String() != want[i] {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		}
+	}
+
+	assertResolved(t,
+		"/ip4/10.0.0.1/tcp/1634/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		"/ip4/10.0.0.2/tcp/1634/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		"/ip4/192.168.1.34/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+	)
+
+	t.Run("unchanged", func(t *testing.T) {
+		mu.Lock()
+		ips = []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}
+		mu.Unlock()
+
+		changed, err := r.Refresh()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed {
+			t.Fatal("want unchanged addresses")
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		mu.Lock()
+		ips = []net.IP{net.ParseIP("2001:db8::1")}
+		mu.Unlock()
+
+		changed, err := r.Refresh()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !changed {
+			t.Fatal("want changed addresses")
+		}
+		assertResolved(t,
+			"/ip6/2001:db8::1/tcp/1634/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+			"/ip4/192.168.1.34/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		)
+	})
+
+	t.Run("resolution failure keeps addresses", func(t *testing.T) {
+		mu.Lock()
+		ips = nil
+		mu.Unlock()
+
+		if _, err := r.Refresh(); err == nil {
+			t.Fatal("want error")
+		}
+		assertResolved(t,
+			"/ip6/2001:db8::1/tcp/1634/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+			"/ip4/192.168.1.34/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := libp2p.NewAnnouncedAddressResolver([]string{"unknown.example.com:1634"}, lookupIP); err == nil {
+			t.Fatal("want error")
+		}
+	})
+}
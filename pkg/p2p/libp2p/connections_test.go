@@ -1253,8 +1253,8 @@ func checkAddressbook(t *testing.T, ab addressbook.Getter, overlay swarm.Address
 		t.Fatalf("overlay mismatch. got %s want %s", addr.Overlay, overlay)
 	}
 
-	if !addr.Underlay.Equal(underlay) {
-		t.Fatalf("underlay mismatch. got %s, want %s", addr.Underlay, underlay)
+	if len(addr.Underlays) != 1 || !addr.Underlays[0].Equal(underlay) {
+		t.Fatalf("underlay mismatch. got %s, want %s", addr.Underlays, underlay)
 	}
 }
 
@@ -6,7 +6,9 @@ package libp2p
 
 import (
 	"context"
+	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/p2p"
 	handshake "github.com/ethersphere/bee/v2/pkg/p2p/libp2p/internal/handshake"
 	libp2pm "github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/host"
@@ -31,12 +33,43 @@ func (s *Service) Host() host.Host {
 }
 
 type StaticAddressResolver = staticAddressResolver
+type TrafficController = trafficController
 
 var (
-	NewStaticAddressResolver = newStaticAddressResolver
-	UserAgent                = userAgent
+	NewStaticAddressResolver    = newStaticAddressResolver
+	NewAnnouncedAddressResolver = newAnnouncedAddressResolver
+	UserAgent                   = userAgent
 )
 
+const (
+	DirectionUpload   = directionUpload
+	DirectionDownload = directionDownload
+)
+
+func NewTrafficController(o TrafficOptions, now func() time.Time) *trafficController {
+	return newTrafficController(o, newMetrics(), now)
+}
+
+func (c *trafficController) Delay(protocol, direction string, n int) time.Duration {
+	return c.delay(c.protocol(protocol), direction, n)
+}
+
+func (c *trafficController) Wait(ctx context.Context, deadline time.Time, protocol, direction string, n int) error {
+	return c.wait(ctx, deadline, c.protocol(protocol), direction, n)
+}
+
+func (c *trafficController) Enabled() bool {
+	return c.enabled()
+}
+
+func (c *trafficController) Usage() p2p.TrafficUsage {
+	return c.usage()
+}
+
+func (r *announcedAddressResolver) Refresh() (bool, error) {
+	return r.refresh()
+}
+
 func WithHostFactory(factory func(...libp2pm.Option) (host.Host, error)) Options {
 	return Options{
 		hostFactory: factory,
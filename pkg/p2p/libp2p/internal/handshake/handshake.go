@@ -53,11 +53,15 @@ var (
 
 	// ErrPicker is returned if the picker (kademlia) rejects the peer
 	ErrPicker = errors.New("picker rejection")
+
+	// ErrNotPermitted is returned if the peer is not a member of the private network.
+	ErrNotPermitted = errors.New("peer not permitted")
 )
 
-// AdvertisableAddressResolver can Resolve a Multiaddress.
+// AdvertisableAddressResolver resolves the underlays announced to a peer
+// from the address under which the peer observes the node.
 type AdvertisableAddressResolver interface {
-	Resolve(observedAddress ma.Multiaddr) (ma.Multiaddr, error)
+	Resolve(observedAddress ma.Multiaddr) ([]ma.Multiaddr, error)
 }
 
 // Service can perform initiate or handle a handshake between peers.
@@ -74,6 +78,8 @@ type Service struct {
 	libp2pID              libp2ppeer.ID
 	metrics               metrics
 	picker                p2p.Picker
+	certificate           []byte                  // membership certificate of this node
+	verifier              p2p.CertificateVerifier // verifies the certificates of the peers
 }
 
 // Info contains the information received from the handshake.
@@ -117,6 +123,13 @@ func (s *Service) SetPicker(n p2p.Picker) {
 	s.picker = n
 }
 
+// SetMembership makes the handshake present the certificate of this node
+// and accept only the peers with the certificates accepted by the verifier.
+func (s *Service) SetMembership(certificate []byte, verifier p2p.CertificateVerifier) {
+	s.certificate = certificate
+	s.verifier = verifier
+}
+
 // Handshake initiates a handshake with a peer.
 func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerID libp2ppeer.ID) (i *Info, err error) {
 	loggerV1 := s.logger.V(1).Register()
@@ -161,17 +174,12 @@ func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiadd
 		s.logger.Warning("received peer ID does not match ours", "their", observedUnderlayAddrInfo.ID, "ours", s.libp2pID)
 	}
 
-	advertisableUnderlay, err := s.advertisableAddresser.Resolve(observedUnderlay)
+	bzzAddress, err := s.Address(observedUnderlay)
 	if err != nil {
 		return nil, err
 	}
 
-	bzzAddress, err := bzz.NewAddress(s.signer, advertisableUnderlay, s.overlay, s.networkID, s.nonce)
-	if err != nil {
-		return nil, err
-	}
-
-	advertisableUnderlayBytes, err := bzzAddress.Underlay.MarshalBinary()
+	advertisableUnderlayBytes, advertisableUnderlaysBytes, err := bzzAddress.WireUnderlays()
 	if err != nil {
 		return nil, err
 	}
@@ -189,13 +197,16 @@ func (s *Service) Handshake(ctx context.Context, stream p2p.Stream, peerMultiadd
 	welcomeMessage := s.GetWelcomeMessage()
 	msg := &pb.Ack{
 		Address: &pb.BzzAddress{
-			Underlay:  advertisableUnderlayBytes,
-			Overlay:   bzzAddress.Overlay.Bytes(),
-			Signature: bzzAddress.Signature,
+			Underlay:           advertisableUnderlayBytes,
+			Overlay:            bzzAddress.Overlay.Bytes(),
+			Signature:          bzzAddress.Signature,
+			Underlays:          advertisableUnderlaysBytes,
+			UnderlaysSignature: bzzAddress.UnderlaysSignature,
 		},
 		NetworkID:      s.networkID,
 		FullNode:       s.fullNode,
 		Nonce:          s.nonce,
+		Certificate:    s.certificate,
 		WelcomeMessage: welcomeMessage,
 	}
 
@@ -244,17 +255,12 @@ func (s *Service) Handle(ctx context.Context, stream p2p.Stream, remoteMultiaddr
 		return nil, ErrInvalidSyn
 	}
 
-	advertisableUnderlay, err := s.advertisableAddresser.Resolve(observedUnderlay)
-	if err != nil {
-		return nil, err
-	}
-
-	bzzAddress, err := bzz.NewAddress(s.signer, advertisableUnderlay, s.overlay, s.networkID, s.nonce)
+	bzzAddress, err := s.Address(observedUnderlay)
 	if err != nil {
 		return nil, err
 	}
 
-	advertisableUnderlayBytes, err := bzzAddress.Underlay.MarshalBinary()
+	advertisableUnderlayBytes, advertisableUnderlaysBytes, err := bzzAddress.WireUnderlays()
 	if err != nil {
 		return nil, err
 	}
@@ -267,13 +273,16 @@ func (s *Service) Handle(ctx context.Context, stream p2p.Stream, remoteMultiaddr
 		},
 		Ack: &pb.Ack{
 			Address: &pb.BzzAddress{
-				Underlay:  advertisableUnderlayBytes,
-				Overlay:   bzzAddress.Overlay.Bytes(),
-				Signature: bzzAddress.Signature,
+				Underlay:           advertisableUnderlayBytes,
+				Overlay:            bzzAddress.Overlay.Bytes(),
+				Signature:          bzzAddress.Signature,
+				Underlays:          advertisableUnderlaysBytes,
+				UnderlaysSignature: bzzAddress.UnderlaysSignature,
 			},
 			NetworkID:      s.networkID,
 			FullNode:       s.fullNode,
 			Nonce:          s.nonce,
+			Certificate:    s.certificate,
 			WelcomeMessage: welcomeMessage,
 		},
 	}); err != nil {
@@ -326,6 +335,17 @@ func (s *Service) SetWelcomeMessage(msg string) (err error) {
 	return nil
 }
 
+// Address returns the signed bzz address of the node with the underlays
+// announced to a peer observing the node under the observed underlay.
+func (s *Service) Address(observedUnderlay ma.Multiaddr) (*bzz.Address, error) {
+	advertisableUnderlays, err := s.advertisableAddresser.Resolve(observedUnderlay)
+	if err != nil {
+		return nil, err
+	}
+
+	return bzz.NewAddress(s.signer, advertisableUnderlays, s.overlay, s.networkID, s.nonce)
+}
+
 // GetWelcomeMessage returns the current handshake welcome message.
 func (s *Service) GetWelcomeMessage() string {
 	return s.welcomeMessage.Load().(string)
@@ -340,6 +360,19 @@ func (s *Service) parseCheckAck(ack *pb.Ack) (*bzz.Address, error) {
 	if err != nil {
 		return nil, ErrInvalidAck
 	}
+	// the list of all the underlays is optional, the older peers send only
+	// the first one
+	if len(ack.Address.Underlays) > 0 {
+		if err := bzzAddress.ParseUnderlays(ack.Address.Underlays, ack.Address.UnderlaysSignature, s.networkID); err != nil {
+			return nil, ErrInvalidAck
+		}
+	}
+
+	if s.verifier != nil {
+		if err := s.verifier.Verify(bzzAddress.Overlay, ack.Certificate); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotPermitted, err)
+		}
+	}
 
 	return bzzAddress, nil
 }
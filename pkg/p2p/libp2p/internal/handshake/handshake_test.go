@@ -20,6 +20,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p/internal/handshake/mock"
 	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p/internal/handshake/pb"
 	"github.com/ethersphere/bee/v2/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 
 	libp2ppeer "github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
@@ -76,7 +77,7 @@ func TestHandshake(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	node1BzzAddress, err := bzz.NewAddress(signer1, node1ma, addr, networkID, nonce)
+	node1BzzAddress, err := bzz.NewAddress(signer1, []ma.Multiaddr{node1ma}, addr, networkID, nonce)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,7 +85,7 @@ func TestHandshake(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	node2BzzAddress, err := bzz.NewAddress(signer2, node2ma, addr2, networkID, nonce)
+	node2BzzAddress, err := bzz.NewAddress(signer2, []ma.Multiaddr{node2ma}, addr2, networkID, nonce)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -176,6 +177,64 @@ func TestHandshake(t *testing.T) {
 		}
 	})
 
+	t.Run("Handshake - underlays list", func(t *testing.T) {
+		node2dns, err := ma.NewMultiaddr("/dns4/bee.example.com/tcp/1634/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS")
+		if err != nil {
+			t.Fatal(err)
+		}
+		multiBzzAddress, err := bzz.NewAddress(signer2, []ma.Multiaddr{node2ma, node2dns}, addr2, networkID, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		underlay, underlays, err := multiBzzAddress.WireUnderlays()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			name      string
+			signature []byte
+			wantErr   error
+		}{
+			{name: "valid", signature: multiBzzAddress.UnderlaysSignature},
+			{name: "invalid signature", signature: node2BzzAddress.Signature, wantErr: handshake.ErrInvalidAck},
+		} {
+			var buffer1 bytes.Buffer
+			var buffer2 bytes.Buffer
+			stream1 := mock.NewStream(&buffer1, &buffer2)
+			stream2 := mock.NewStream(&buffer2, &buffer1)
+
+			w := protobuf.NewWriter(stream2)
+			if err := w.WriteMsg(&pb.SynAck{
+				Syn: &pb.Syn{
+					ObservedUnderlay: node1maBinary,
+				},
+				Ack: &pb.Ack{
+					Address: &pb.BzzAddress{
+						Underlay:           underlay,
+						Overlay:            multiBzzAddress.Overlay.Bytes(),
+						Signature:          multiBzzAddress.Signature,
+						Underlays:          underlays,
+						UnderlaysSignature: tc.signature,
+					},
+					NetworkID: networkID,
+					FullNode:  true,
+					Nonce:     nonce,
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := handshakeService.Handshake(context.Background(), stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("%s: got error %v, want %v", tc.name, err, tc.wantErr)
+			}
+			if tc.wantErr == nil && !bzz.UnderlaysEqual(res.BzzAddress.Underlays, multiBzzAddress.Underlays) {
+				t.Fatalf("%s: got underlays %v, want %v", tc.name, res.BzzAddress.Underlays, multiBzzAddress.Underlays)
+			}
+		}
+	})
+
 	t.Run("Handshake - picker error", func(t *testing.T) {
 		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, true, nonce, "", true, node1AddrInfo.ID, logger)
 		if err != nil {
@@ -216,6 +275,69 @@ func TestHandshake(t *testing.T) {
 		}
 	})
 
+	t.Run("Handle - membership", func(t *testing.T) {
+		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, true, nonce, "", true, node1AddrInfo.ID, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ownCertificate := []byte("own certificate")
+		handshakeService.SetMembership(ownCertificate, verifierFunc(func(overlay swarm.Address, certificate []byte) error {
+			if !overlay.Equal(node2BzzAddress.Overlay) || string(certificate) != "valid" {
+				return errors.New("invalid certificate")
+			}
+			return nil
+		}))
+
+		for _, tc := range []struct {
+			certificate string
+			wantErr     error
+		}{
+			{certificate: "invalid", wantErr: handshake.ErrNotPermitted},
+			{certificate: "", wantErr: handshake.ErrNotPermitted},
+			{certificate: "valid"},
+		} {
+			var buffer1 bytes.Buffer
+			var buffer2 bytes.Buffer
+			stream1 := mock.NewStream(&buffer1, &buffer2)
+			stream2 := mock.NewStream(&buffer2, &buffer1)
+
+			w, r := protobuf.NewWriterAndReader(stream2)
+			if err := w.WriteMsg(&pb.Syn{
+				ObservedUnderlay: node1maBinary,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.WriteMsg(&pb.Ack{
+				Address: &pb.BzzAddress{
+					Underlay:  node2maBinary,
+					Overlay:   node2BzzAddress.Overlay.Bytes(),
+					Signature: node2BzzAddress.Signature,
+				},
+				NetworkID:   networkID,
+				Nonce:       nonce,
+				FullNode:    true,
+				Certificate: []byte(tc.certificate),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = handshakeService.Handle(context.Background(), stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("certificate %q: expected error %v, got %v", tc.certificate, tc.wantErr, err)
+			}
+
+			var synAck pb.SynAck
+			if err := r.ReadMsg(&synAck); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(synAck.Ack.Certificate, ownCertificate) {
+				t.Fatalf("got certificate %q, want %q", synAck.Ack.Certificate, ownCertificate)
+			}
+		}
+	})
+
 	t.Run("Handshake - welcome message too long", func(t *testing.T) {
 		const LongMessage = "Lorem ipsum dolor sit amet, consectetur adipiscing elit. Morbi consectetur urna ut lorem sollicitudin posuere. Donec sagittis laoreet sapien."
 
@@ -672,6 +794,12 @@ func TestHandshake(t *testing.T) {
 	})
 }
 
+type verifierFunc func(overlay swarm.Address, certificate []byte) error
+
+func (f verifierFunc) Verify(overlay swarm.Address, certificate []byte) error {
+	return f(overlay, certificate)
+}
+
 func mockPicker(f func(p2p.Peer) bool) p2p.Picker {
 	return &picker{pickerFunc: f}
 }
@@ -697,14 +825,14 @@ type AdvertisableAddresserMock struct {
 	err                 error
 }
 
-func (a *AdvertisableAddresserMock) Resolve(observedAddress ma.Multiaddr) (ma.Multiaddr, error) {
+func (a *AdvertisableAddresserMock) Resolve(observedAddress ma.Multiaddr) ([]ma.Multiaddr, error) {
 	if a.err != nil {
 		return nil, a.err
 	}
 
 	if a.advertisableAddress != nil {
-		return a.advertisableAddress, nil
+		return []ma.Multiaddr{a.advertisableAddress}, nil
 	}
 
-	return observedAddress, nil
+	return []ma.Multiaddr{observedAddress}, nil
 }
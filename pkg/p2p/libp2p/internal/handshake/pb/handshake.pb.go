@@ -71,6 +71,7 @@ type Ack struct {
 	NetworkID      uint64      `protobuf:"varint,2,opt,name=NetworkID,proto3" json:"NetworkID,omitempty"`
 	FullNode       bool        `protobuf:"varint,3,opt,name=FullNode,proto3" json:"FullNode,omitempty"`
 	Nonce          []byte      `protobuf:"bytes,4,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	Certificate    []byte      `protobuf:"bytes,5,opt,name=Certificate,proto3" json:"Certificate,omitempty"`
 	WelcomeMessage string      `protobuf:"bytes,99,opt,name=WelcomeMessage,proto3" json:"WelcomeMessage,omitempty"`
 }
 
@@ -135,6 +136,13 @@ func (m *Ack) GetNonce() []byte {
 	return nil
 }
 
+func (m *Ack) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
 func (m *Ack) GetWelcomeMessage() string {
 	if m != nil {
 		return m.WelcomeMessage
@@ -195,9 +203,11 @@ func (m *SynAck) GetAck() *Ack {
 }
 
 type BzzAddress struct {
-	Underlay  []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
-	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
-	Overlay   []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
+	Underlay           []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
+	Signature          []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	Overlay            []byte `protobuf:"bytes,3,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
+	Underlays          []byte `protobuf:"bytes,4,opt,name=Underlays,proto3" json:"Underlays,omitempty"`
+	UnderlaysSignature []byte `protobuf:"bytes,5,opt,name=UnderlaysSignature,proto3" json:"UnderlaysSignature,omitempty"`
 }
 
 func (m *BzzAddress) Reset()         { *m = BzzAddress{} }
@@ -254,6 +264,20 @@ func (m *BzzAddress) GetOverlay() []byte {
 	return nil
 }
 
+func (m *BzzAddress) GetUnderlays() []byte {
+	if m != nil {
+		return m.Underlays
+	}
+	return nil
+}
+
+func (m *BzzAddress) GetUnderlaysSignature() []byte {
+	if m != nil {
+		return m.UnderlaysSignature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Syn)(nil), "handshake.Syn")
 	proto.RegisterType((*Ack)(nil), "handshake.Ack")
@@ -264,27 +288,29 @@ func init() {
 func init() { proto.RegisterFile("handshake.proto", fileDescriptor_a77305914d5d202f) }
 
 var fileDescriptor_a77305914d5d202f = []byte{
-	// 318 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xcd, 0x4a, 0xc3, 0x40,
-	0x14, 0x85, 0x3b, 0x4d, 0xed, 0xcf, 0xb5, 0x54, 0x19, 0x14, 0x82, 0x94, 0x10, 0xb2, 0x90, 0xe0,
-	0xa2, 0xa2, 0x3e, 0x41, 0x8b, 0x08, 0x82, 0xb6, 0x30, 0x41, 0x04, 0x57, 0xa6, 0x99, 0x4b, 0x2b,
-	0x89, 0x33, 0x65, 0xa6, 0xad, 0xa4, 0x4f, 0xe1, 0x93, 0xf8, 0x1c, 0x2e, 0xbb, 0x74, 0x29, 0xed,
-	0x8b, 0x48, 0xa6, 0x3f, 0xd1, 0xba, 0x3c, 0xe7, 0x9e, 0x99, 0xf9, 0xce, 0x1d, 0x38, 0x18, 0x86,
-	0x82, 0xeb, 0x61, 0x18, 0x63, 0x6b, 0xa4, 0xe4, 0x58, 0xd2, 0xda, 0xd6, 0xf0, 0x2e, 0xc0, 0x0a,
-	0x52, 0x41, 0xcf, 0xe0, 0xb0, 0xd7, 0xd7, 0xa8, 0xa6, 0xc8, 0x1f, 0x04, 0x47, 0x95, 0x84, 0xa9,
-	0x4d, 0x5c, 0xe2, 0xd7, 0xd9, 0x3f, 0xdf, 0xfb, 0x20, 0x60, 0xb5, 0xa3, 0x98, 0x9e, 0x43, 0xa5,
-	0xcd, 0xb9, 0x42, 0xad, 0x4d, 0x74, 0xff, 0xf2, 0xb8, 0x95, 0x3f, 0xd4, 0x99, 0xcd, 0xd6, 0x43,
-	0xb6, 0x49, 0xd1, 0x26, 0xd4, 0xba, 0x38, 0x7e, 0x93, 0x2a, 0xbe, 0xbd, 0xb6, 0x8b, 0x2e, 0xf1,
-	0x4b, 0x2c, 0x37, 0xe8, 0x09, 0x54, 0x6f, 0x26, 0x49, 0xd2, 0x95, 0x1c, 0x6d, 0xcb, 0x25, 0x7e,
-	0x95, 0x6d, 0x35, 0x3d, 0x82, 0xbd, 0xae, 0x14, 0x11, 0xda, 0x25, 0xc3, 0xb4, 0x12, 0xf4, 0x14,
-	0x1a, 0x8f, 0x98, 0x44, 0xf2, 0x15, 0xef, 0x51, 0xeb, 0x70, 0x80, 0x76, 0xe4, 0x12, 0xbf, 0xc6,
-	0x76, 0x5c, 0xef, 0x0e, 0xca, 0x41, 0x2a, 0x32, 0x64, 0xd7, 0xb4, 0x5d, 0xe3, 0x36, 0x7e, 0xe1,
-	0x06, 0xa9, 0x60, 0x66, 0x11, 0xae, 0xe9, 0x66, 0xe8, 0xfe, 0x26, 0xda, 0x51, 0xcc, 0xb2, 0x91,
-	0xf7, 0x0c, 0x90, 0x97, 0xcb, 0xa8, 0x77, 0x16, 0xb6, 0xd5, 0x59, 0xdf, 0xe0, 0x65, 0x20, 0xc2,
-	0xf1, 0x44, 0xa1, 0xb9, 0xb1, 0xce, 0x72, 0x83, 0xda, 0x50, 0xe9, 0x4d, 0x57, 0x07, 0x2d, 0x33,
-	0xdb, 0xc8, 0x4e, 0xf3, 0x73, 0xe1, 0x90, 0xf9, 0xc2, 0x21, 0xdf, 0x0b, 0x87, 0xbc, 0x2f, 0x9d,
-	0xc2, 0x7c, 0xe9, 0x14, 0xbe, 0x96, 0x4e, 0xe1, 0xa9, 0x38, 0xea, 0xf7, 0xcb, 0xe6, 0x0f, 0xaf,
-	0x7e, 0x02, 0x00, 0x00, 0xff, 0xff, 0x87, 0xa7, 0x49, 0x00, 0xd6, 0x01, 0x00, 0x00,
+	// 351 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xcf, 0x6a, 0xea, 0x40,
+	0x14, 0xc6, 0x1d, 0xe3, 0xdf, 0xa3, 0x78, 0x2f, 0xc3, 0xbd, 0x30, 0x14, 0x09, 0x21, 0x8b, 0x12,
+	0xba, 0xb0, 0xb4, 0x7d, 0x02, 0x6d, 0x29, 0x14, 0x5a, 0x85, 0x09, 0xa5, 0xd0, 0x5d, 0x4c, 0x4e,
+	0x55, 0x92, 0x26, 0x32, 0x13, 0x2d, 0xf1, 0x29, 0xfa, 0x24, 0x7d, 0x8e, 0x2e, 0x5d, 0x76, 0x59,
+	0xf4, 0x45, 0x4a, 0x46, 0x4d, 0x44, 0xbb, 0xfc, 0x7e, 0xdf, 0x39, 0x27, 0xf9, 0x3e, 0x06, 0xfe,
+	0x8c, 0x9d, 0xd0, 0x93, 0x63, 0xc7, 0xc7, 0xce, 0x54, 0x44, 0x71, 0x44, 0xeb, 0x19, 0x30, 0x2f,
+	0x40, 0xb3, 0x93, 0x90, 0x9e, 0xc1, 0xdf, 0xc1, 0x50, 0xa2, 0x98, 0xa3, 0xf7, 0x18, 0x7a, 0x28,
+	0x02, 0x27, 0x61, 0xc4, 0x20, 0x56, 0x93, 0x1f, 0x71, 0x73, 0x49, 0x40, 0xeb, 0xba, 0x3e, 0x3d,
+	0x87, 0x6a, 0xd7, 0xf3, 0x04, 0x4a, 0xa9, 0x46, 0x1b, 0x97, 0xff, 0x3b, 0xf9, 0x87, 0x7a, 0x8b,
+	0xc5, 0xd6, 0xe4, 0xbb, 0x29, 0xda, 0x86, 0x7a, 0x1f, 0xe3, 0xb7, 0x48, 0xf8, 0x77, 0x37, 0xac,
+	0x68, 0x10, 0xab, 0xc4, 0x73, 0x40, 0x4f, 0xa0, 0x76, 0x3b, 0x0b, 0x82, 0x7e, 0xe4, 0x21, 0xd3,
+	0x0c, 0x62, 0xd5, 0x78, 0xa6, 0xe9, 0x3f, 0x28, 0xf7, 0xa3, 0xd0, 0x45, 0x56, 0x52, 0xff, 0xb4,
+	0x11, 0xd4, 0x80, 0xc6, 0x35, 0x8a, 0x78, 0xf2, 0x32, 0x71, 0x9d, 0x18, 0x59, 0x59, 0x79, 0xfb,
+	0x88, 0x9e, 0x42, 0xeb, 0x09, 0x03, 0x37, 0x7a, 0xc5, 0x07, 0x94, 0xd2, 0x19, 0x21, 0x73, 0x0d,
+	0x62, 0xd5, 0xf9, 0x01, 0x35, 0xef, 0xa1, 0x62, 0x27, 0x61, 0x1a, 0xca, 0x50, 0x7d, 0x6c, 0x03,
+	0xb5, 0xf6, 0x02, 0xd9, 0x49, 0xc8, 0x55, 0x55, 0x86, 0x4a, 0xcf, 0x8a, 0x47, 0x13, 0x5d, 0xd7,
+	0xe7, 0xa9, 0x65, 0x7e, 0x10, 0x80, 0x3c, 0x7f, 0x1a, 0xec, 0xa0, 0xd3, 0x4c, 0xa7, 0x95, 0xd8,
+	0x93, 0x51, 0xe8, 0xc4, 0x33, 0x81, 0xea, 0x64, 0x93, 0xe7, 0x80, 0x32, 0xa8, 0x0e, 0xe6, 0x9b,
+	0x45, 0x4d, 0x79, 0x3b, 0x99, 0xee, 0xed, 0x6e, 0xc8, 0x6d, 0x29, 0x39, 0xa0, 0x1d, 0xa0, 0x99,
+	0xc8, 0xcf, 0x6f, 0xfa, 0xf9, 0xc5, 0xe9, 0xb5, 0x3f, 0x57, 0x3a, 0x59, 0xae, 0x74, 0xf2, 0xbd,
+	0xd2, 0xc9, 0xfb, 0x5a, 0x2f, 0x2c, 0xd7, 0x7a, 0xe1, 0x6b, 0xad, 0x17, 0x9e, 0x8b, 0xd3, 0xe1,
+	0xb0, 0xa2, 0x1e, 0xcd, 0xd5, 0xcf, 0x00, 0x4f, 0xcd, 0xa7, 0x26, 0x47, 0x02, 0x00, 0x00,
 }
 
 func (m *Syn) Marshal() (dAtA []byte, err error) {
@@ -346,6 +372,13 @@ func (m *Ack) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x9a
 	}
+	if len(m.Certificate) > 0 {
+		i -= len(m.Certificate)
+		copy(dAtA[i:], m.Certificate)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Certificate)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if len(m.Nonce) > 0 {
 		i -= len(m.Nonce)
 		copy(dAtA[i:], m.Nonce)
@@ -450,6 +483,20 @@ func (m *BzzAddress) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.UnderlaysSignature) > 0 {
+		i -= len(m.UnderlaysSignature)
+		copy(dAtA[i:], m.UnderlaysSignature)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.UnderlaysSignature)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Underlays) > 0 {
+		i -= len(m.Underlays)
+		copy(dAtA[i:], m.Underlays)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Underlays)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.Overlay) > 0 {
 		i -= len(m.Overlay)
 		copy(dAtA[i:], m.Overlay)
@@ -518,6 +565,10 @@ func (m *Ack) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.Certificate)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	l = len(m.WelcomeMessage)
 	if l > 0 {
 		n += 2 + l + sovHandshake(uint64(l))
@@ -560,6 +611,14 @@ func (m *BzzAddress) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.Underlays)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
+	l = len(m.UnderlaysSignature)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	return n
 }
 
@@ -638,10 +697,7 @@ func (m *Syn) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHandshake
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHandshake
 			}
 			if (iNdEx + skippy) > l {
@@ -794,6 +850,40 @@ func (m *Ack) Unmarshal(dAtA []byte) error {
 				m.Nonce = []byte{}
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Certificate", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Certificate = append(m.Certificate[:0], dAtA[iNdEx:postIndex]...)
+			if m.Certificate == nil {
+				m.Certificate = []byte{}
+			}
+			iNdEx = postIndex
 		case 99:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field WelcomeMessage", wireType)
@@ -832,10 +922,7 @@ func (m *Ack) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHandshake
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHandshake
 			}
 			if (iNdEx + skippy) > l {
@@ -957,10 +1044,7 @@ func (m *SynAck) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHandshake
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHandshake
 			}
 			if (iNdEx + skippy) > l {
@@ -1106,16 +1190,81 @@ func (m *BzzAddress) Unmarshal(dAtA []byte) error {
 				m.Overlay = []byte{}
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Underlays", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Underlays = append(m.Underlays[:0], dAtA[iNdEx:postIndex]...)
+			if m.Underlays == nil {
+				m.Underlays = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnderlaysSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UnderlaysSignature = append(m.UnderlaysSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.UnderlaysSignature == nil {
+				m.UnderlaysSignature = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthHandshake
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthHandshake
 			}
 			if (iNdEx + skippy) > l {
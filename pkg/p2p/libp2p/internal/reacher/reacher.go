@@ -8,6 +8,7 @@ package reacher
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -22,9 +23,11 @@ const (
 	retryAfterDuration = time.Minute * 5
 )
 
+var errNoUnderlays = errors.New("no underlays")
+
 type peer struct {
 	overlay    swarm.Address
-	addr       ma.Multiaddr
+	addrs      []ma.Multiaddr
 	retryAfter time.Time
 }
 
@@ -137,13 +140,21 @@ func (r *reacher) ping(c chan *peer, ctx context.Context) {
 
 		r.mu.Lock()
 		overlay := p.overlay
+		addrs := p.addrs
 		r.mu.Unlock()
 
 		now := time.Now()
 
-		ctxt, cancel := context.WithTimeout(ctx, r.options.PingTimeout)
-		_, err := r.pinger.Ping(ctxt, p.addr)
-		cancel()
+		// the peer is reachable if any of its underlays answers the ping
+		var err error = errNoUnderlays
+		for _, addr := range addrs {
+			ctxt, cancel := context.WithTimeout(ctx, r.options.PingTimeout)
+			_, err = r.pinger.Ping(ctxt, addr)
+			cancel()
+			if err == nil {
+				break
+			}
+		}
 
 		// ping was successful
 		if err == nil {
@@ -198,13 +209,14 @@ func (r *reacher) notifyManage() {
 	}
 }
 
-// Connected adds a new peer to the queue for testing reachability.
-func (r *reacher) Connected(overlay swarm.Address, addr ma.Multiaddr) {
+// Connected adds a new peer with its underlays to the queue for testing
+// reachability.
+func (r *reacher) Connected(overlay swarm.Address, addrs []ma.Multiaddr) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if _, ok := r.peers[overlay.ByteString()]; !ok {
-		r.peers[overlay.ByteString()] = &peer{overlay: overlay, addr: addr}
+		r.peers[overlay.ByteString()] = &peer{overlay: overlay, addrs: addrs}
 	}
 
 	r.notifyManage()
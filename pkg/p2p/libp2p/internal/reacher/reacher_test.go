@@ -73,7 +73,7 @@ func TestPingSuccess(t *testing.T) {
 
 			overlay := swarm.RandAddress(t)
 
-			r.Connected(overlay, nil)
+			r.Connected(overlay, []ma.Multiaddr{nil})
 
 			select {
 			case <-time.After(time.Second * 5):
@@ -84,6 +84,45 @@ func TestPingSuccess(t *testing.T) {
 	}
 }
 
+func TestPingAnyUnderlay(t *testing.T) {
+	t.Parallel()
+
+	var (
+		unreachableMa, _ = ma.NewMultiaddr("/ip4/10.0.0.1/tcp/7071/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb")
+		reachableMa, _   = ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7071/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb")
+		done             = make(chan struct{})
+	)
+
+	pingFunc := func(_ context.Context, a ma.Multiaddr) (time.Duration, error) {
+		if a.Equal(reachableMa) {
+			return 0, nil
+		}
+		return 0, errors.New("test error")
+	}
+	reachableFunc := func(addr swarm.Address, got p2p.ReachabilityStatus) {
+		if got != p2p.ReachabilityStatusPublic {
+			t.Errorf("got %v, want %v", got, p2p.ReachabilityStatusPublic)
+		}
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	}
+
+	mock := newMock(pingFunc, reachableFunc)
+
+	r := reacher.New(mock, mock, &defaultOptions)
+	testutil.CleanupCloser(t, r)
+
+	r.Connected(swarm.RandAddress(t), []ma.Multiaddr{unreachableMa, reachableMa})
+
+	select {
+	case <-time.After(time.Second * 5):
+		t.Fatalf("test timed out")
+	case <-done:
+	}
+}
+
 func TestDisconnected(t *testing.T) {
 	t.Parallel()
 
@@ -117,8 +156,8 @@ func TestDisconnected(t *testing.T) {
 	r := reacher.New(mock, mock, &defaultOptions)
 	testutil.CleanupCloser(t, r)
 
-	r.Connected(swarm.RandAddress(t), nil)
-	r.Connected(disconnectedOverlay, disconnectedMa)
+	r.Connected(swarm.RandAddress(t), []ma.Multiaddr{nil})
+	r.Connected(disconnectedOverlay, []ma.Multiaddr{disconnectedMa})
 	r.Disconnected(disconnectedOverlay)
 }
 
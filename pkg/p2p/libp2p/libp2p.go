@@ -75,6 +75,9 @@ const (
 	defaultLightNodeLimit = 100
 	peerUserAgentTimeout  = time.Second
 
+	natAddrRefreshInterval = 5 * time.Minute
+	addressAnnounceTimeout = 30 * time.Second
+
 	defaultHeadersRWTimeout = 10 * time.Second
 
 	IncomingStreamCountLimit = 5_000
@@ -85,7 +88,9 @@ type Service struct {
 	ctx               context.Context
 	host              host.Host
 	natManager        basichost.NATManager
-	natAddrResolver   *staticAddressResolver
+	natAddrResolver   *announcedAddressResolver
+	addressChanged    AddressChangedFunc
+	traffic           *trafficController
 	autonatDialer     host.Host
 	pingDialer        host.Host
 	libp2pPeerstore   peerstore.Peerstore
@@ -121,7 +126,8 @@ type lightnodes interface {
 
 type Options struct {
 	PrivateKey       *ecdsa.PrivateKey
-	NATAddr          string
+	Identity         crypto.PrivKey // used instead of PrivateKey if set, e.g. when the key is kept by an external signer
+	NATAddrs         []string       // announced host:port addresses, the domain names are re-resolved periodically
 	EnableWS         bool
 	FullNode         bool
 	LightNodeLimit   int
@@ -131,6 +137,13 @@ type Options struct {
 	hostFactory      func(...libp2p.Option) (host.Host, error)
 	HeadersRWTimeout time.Duration
 	Registry         *prometheus.Registry
+	// Certificate is the membership certificate presented in the handshake
+	// and CertificateVerifier verifies the certificates of the peers.
+	// The private network membership is enforced only if the verifier is set.
+	Certificate         []byte
+	CertificateVerifier p2p.CertificateVerifier
+	// Traffic configures the bandwidth budgets of the protocols.
+	Traffic TrafficOptions
 }
 
 func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay swarm.Address, addr string, ab addressbook.Putter, storer storage.StateStorer, lightNodes *lightnode.Container, logger log.Logger, tracer *tracing.Tracer, o Options) (*Service, error) {
@@ -222,7 +235,7 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		libp2p.ResourceManager(rm),
 	}
 
-	if o.NATAddr == "" {
+	if len(o.NATAddrs) == 0 {
 		opts = append(opts,
 			libp2p.NATManager(func(n network.Network) basichost.NATManager {
 				natManager = basichost.NewNATManager(n)
@@ -231,7 +244,11 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		)
 	}
 
-	if o.PrivateKey != nil {
+	if o.Identity != nil {
+		opts = append(opts,
+			libp2p.Identity(o.Identity),
+		)
+	} else if o.PrivateKey != nil {
 		myKey, _, err := crypto.ECDSAKeyPairFromKey(o.PrivateKey)
 		if err != nil {
 			return nil, err
@@ -291,13 +308,13 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 	}
 
 	var advertisableAddresser handshake.AdvertisableAddressResolver
-	var natAddrResolver *staticAddressResolver
-	if o.NATAddr == "" {
+	var natAddrResolver *announcedAddressResolver
+	if len(o.NATAddrs) == 0 {
 		advertisableAddresser = &UpnpAddressResolver{
 			host: h,
 		}
 	} else {
-		natAddrResolver, err = newStaticAddressResolver(o.NATAddr, net.LookupIP)
+		natAddrResolver, err = newAnnouncedAddressResolver(o.NATAddrs, net.LookupIP)
 		if err != nil {
 			return nil, fmt.Errorf("static nat: %w", err)
 		}
@@ -308,6 +325,9 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 	if err != nil {
 		return nil, fmt.Errorf("handshake service: %w", err)
 	}
+	if o.CertificateVerifier != nil {
+		handshakeService.SetMembership(o.Certificate, o.CertificateVerifier)
+	}
 
 	// Create a new dialer for libp2p ping protocol. This ensures that the protocol
 	// uses a different set of keys to do ping. It prevents inconsistencies in peerstore as
@@ -343,6 +363,7 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		HeadersRWTimeout:  o.HeadersRWTimeout,
 		autoNAT:           autoNAT,
 	}
+	s.traffic = newTrafficController(o.Traffic, s.metrics, time.Now)
 
 	peerRegistry.setDisconnecter(s)
 
@@ -395,6 +416,73 @@ func (s *Service) reachabilityWorker() error {
 	return nil
 }
 
+// AddressChangedFunc announces the changed address of the node to a peer.
+type AddressChangedFunc func(ctx context.Context, addressee swarm.Address, addr *bzz.Address) error
+
+// SetAddressChangedHandler sets the function called for every connected peer
+// when the announced underlays of the node change.
+func (s *Service) SetAddressChangedHandler(f AddressChangedFunc) {
+	s.addressChanged = f
+}
+
+// natAddrWorker periodically resolves the NAT addresses again and announces
+// the changed underlays to the connected peers.
+func (s *Service) natAddrWorker() {
+	ticker := time.NewTicker(natAddrRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.halt:
+			return
+		case <-ticker.C:
+			changed, err := s.natAddrResolver.refresh()
+			if err != nil {
+				s.logger.Warning("nat addresses resolution failed", "error", err)
+				continue
+			}
+			if changed {
+				s.announceAddress()
+			}
+		}
+	}
+}
+
+func (s *Service) announceAddress() {
+	hostAddrs := s.host.Addrs()
+	if len(hostAddrs) == 0 {
+		return
+	}
+	observed, err := buildUnderlayAddress(hostAddrs[0], s.host.ID())
+	if err != nil {
+		s.logger.Debug("announce address: build underlay address failed", "error", err)
+		return
+	}
+	addr, err := s.handshakeService.Address(observed)
+	if err != nil {
+		s.logger.Debug("announce address: address failed", "error", err)
+		return
+	}
+
+	s.logger.Info("announced underlays changed", "underlays", addr.Underlays)
+	s.metrics.AddressAnnouncements.Inc()
+
+	if s.addressChanged == nil {
+		return
+	}
+	for _, peer := range s.peers.peers() {
+		go func(addressee swarm.Address) {
+			ctx, cancel := context.WithTimeout(s.ctx, addressAnnounceTimeout)
+			defer cancel()
+			if err := s.addressChanged(ctx, addressee, addr); err != nil {
+				s.logger.Debug("announce address failed", "peer_address", addressee, "error", err)
+			}
+		}(peer.Address)
+	}
+}
+
 func (s *Service) handleIncoming(stream network.Stream) {
 	loggerV1 := s.logger.V(1).Register()
 
@@ -538,7 +626,7 @@ func (s *Service) handleIncoming(stream network.Stream) {
 	}
 
 	if s.reacher != nil {
-		s.reacher.Connected(overlay, i.BzzAddress.Underlay)
+		s.reacher.Connected(overlay, i.BzzAddress.Underlays)
 	}
 
 	peerUserAgent := appendSpace(s.peerUserAgent(s.ctx, peerID))
@@ -591,6 +679,7 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 			s.metrics.HeadersExchangeDuration.Observe(time.Since(start).Seconds())
 
 			ctx, cancel = context.WithCancel(s.ctx)
+			stream.shape(ctx, s.traffic, p.Name)
 
 			s.peers.addStream(peerID, streamlibp2p, cancel)
 			defer s.peers.removeStream(peerID, streamlibp2p)
@@ -658,7 +747,7 @@ func (s *Service) Addresses() (addreses []ma.Multiaddr, err error) {
 		if err != nil {
 			return nil, err
 		}
-		addreses = append(addreses, a)
+		addreses = append(addreses, a...)
 	}
 
 	return addreses, nil
@@ -689,6 +778,9 @@ func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration, reaso
 		return fmt.Errorf("blocklist peer %s: %w", overlay, err)
 	}
 	s.metrics.BlocklistedPeerCount.Inc()
+	if r, ok := s.notifier.(p2p.EventRecorder); ok {
+		r.RecordBlocklist(overlay, duration, reason)
+	}
 
 	_ = s.Disconnect(overlay, reason)
 	return nil
@@ -730,8 +822,8 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 
 	if overlay, found := s.peers.isConnected(info.ID, remoteAddr); found {
 		address = &bzz.Address{
-			Overlay:  overlay,
-			Underlay: addr,
+			Overlay:   overlay,
+			Underlays: []ma.Multiaddr{addr},
 		}
 		return address, p2p.ErrAlreadyConnected
 	}
@@ -825,7 +917,7 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 	s.metrics.CreatedConnectionCount.Inc()
 
 	if s.reacher != nil {
-		s.reacher.Connected(overlay, i.BzzAddress.Underlay)
+		s.reacher.Connected(overlay, i.BzzAddress.Underlays)
 	}
 
 	peerUserAgent := appendSpace(s.peerUserAgent(ctx, info.ID))
@@ -858,6 +950,9 @@ func (s *Service) Disconnect(overlay swarm.Address, reason string) (err error) {
 	s.protocolsmu.RUnlock()
 
 	if s.notifier != nil {
+		if r, ok := s.notifier.(p2p.EventRecorder); ok {
+			r.RecordDisconnectReason(overlay, reason)
+		}
 		s.notifier.Disconnected(peer)
 	}
 	if s.lightNodes != nil {
@@ -912,6 +1007,12 @@ func (s *Service) Peers() []p2p.Peer {
 	return s.peers.peers()
 }
 
+// TrafficUsage returns the bandwidth budgets and the usage of the protocols.
+// The usage is recorded only if a bandwidth limit is configured.
+func (s *Service) TrafficUsage() p2p.TrafficUsage {
+	return s.traffic.usage()
+}
+
 func (s *Service) Blocklisted(overlay swarm.Address) (bool, error) {
 	return s.blocklist.Exists(overlay)
 }
@@ -949,12 +1050,13 @@ func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers
 	}
 
 	// exchange headers
-	ctx, cancel := context.WithTimeout(ctx, s.HeadersRWTimeout)
+	headersCtx, cancel := context.WithTimeout(ctx, s.HeadersRWTimeout)
 	defer cancel()
-	if err := sendHeaders(ctx, headers, stream); err != nil {
+	if err := sendHeaders(headersCtx, headers, stream); err != nil {
 		_ = stream.Reset()
 		return nil, fmt.Errorf("send headers: %w", err)
 	}
+	stream.shape(ctx, s.traffic, protocolName)
 
 	return stream, nil
 }
@@ -981,6 +1083,7 @@ func (s *Service) newStreamForPeerID(ctx context.Context, peerID libp2ppeer.ID,
 }
 
 func (s *Service) Close() error {
+	s.traffic.close()
 	if err := s.libp2pPeerstore.Close(); err != nil {
 		return err
 	}
@@ -1024,6 +1127,10 @@ func (s *Service) Ready() error {
 		return fmt.Errorf("reachability worker: %w", err)
 	}
 
+	if s.natAddrResolver != nil {
+		go s.natAddrWorker()
+	}
+
 	close(s.ready)
 	return nil
 }
@@ -1054,6 +1161,16 @@ func (s *Service) Ping(ctx context.Context, addr ma.Multiaddr) (rtt time.Duratio
 	}
 }
 
+// PeerUserAgent returns the user agent of the connected peer, which carries
+// the version of its software, or an empty string if it is not known.
+func (s *Service) PeerUserAgent(ctx context.Context, overlay swarm.Address) string {
+	peerID, found := s.peers.peerID(overlay)
+	if !found {
+		return ""
+	}
+	return s.peerUserAgent(ctx, peerID)
+}
+
 // peerUserAgent returns User Agent string of the connected peer if the peer
 // provides it. It ignores the default libp2p user agent string
 // "github.com/libp2p/go-libp2p" and returns empty string in that case.
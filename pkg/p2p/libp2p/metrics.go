@@ -27,6 +27,9 @@ type metrics struct {
 	KickedOutPeersCount        prometheus.Counter
 	StreamHandlerErrResetCount prometheus.Counter
 	HeadersExchangeDuration    prometheus.Histogram
+	AddressAnnouncements       prometheus.Counter
+	TrafficBytes               *prometheus.CounterVec
+	TrafficThrottledSeconds    *prometheus.CounterVec
 }
 
 func newMetrics() metrics {
@@ -117,6 +120,24 @@ func newMetrics() metrics {
 			Name:      "headers_exchange_duration",
 			Help:      "The duration spent exchanging the headers.",
 		}),
+		AddressAnnouncements: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "address_announcements_count",
+			Help:      "Number of times the changed underlays were announced to the peers.",
+		}),
+		TrafficBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "traffic_bytes",
+			Help:      "Number of bytes transferred by the protocol streams.",
+		}, []string{"protocol", "direction"}),
+		TrafficThrottledSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "traffic_throttled_seconds",
+			Help:      "Time the protocol streams waited for the bandwidth budget.",
+		}, []string{"protocol", "direction"}),
 	}
 }
 
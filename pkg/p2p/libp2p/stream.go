@@ -5,8 +5,10 @@
 package libp2p
 
 import (
+	"context"
 	"errors"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/p2p"
@@ -24,11 +26,100 @@ type stream struct {
 	headers         map[string][]byte
 	responseHeaders map[string][]byte
 	metrics         metrics
+
+	// traffic is set only if the protocol of the stream is subject to a
+	// bandwidth limit in any direction.
+	traffic       *trafficController
+	protocol      *protocolTraffic
+	ctx           context.Context
+	readDeadline  atomic.Int64 // unix nanoseconds, zero if not set
+	writeDeadline atomic.Int64 // unix nanoseconds, zero if not set
 }
 
 func newStream(s network.Stream, metrics metrics) *stream {
 	return &stream{Stream: s, metrics: metrics}
 }
+
+// shape makes the stream subject to the bandwidth budget of the protocol.
+// The throttled reads and writes are released when the context is done.
+func (s *stream) shape(ctx context.Context, c *trafficController, protocol string) {
+	if !c.enabled() {
+		return
+	}
+	p := c.protocol(protocol)
+	if !c.limited(p, directionUpload) && !c.limited(p, directionDownload) {
+		return
+	}
+	s.traffic = c
+	s.protocol = p
+	s.ctx = ctx
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	if s.traffic == nil || !s.traffic.limited(s.protocol, directionDownload) {
+		return s.Stream.Read(p)
+	}
+	if len(p) > trafficChunkSize {
+		p = p[:trafficChunkSize]
+	}
+	n, err := s.Stream.Read(p)
+	if werr := s.traffic.wait(s.ctx, deadline(&s.readDeadline), s.protocol, directionDownload, n); werr != nil && err == nil {
+		err = werr
+	}
+	return n, err
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	if s.traffic == nil || !s.traffic.limited(s.protocol, directionUpload) {
+		return s.Stream.Write(p)
+	}
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(len(p), trafficChunkSize)]
+		if err := s.traffic.wait(s.ctx, deadline(&s.writeDeadline), s.protocol, directionUpload, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := s.Stream.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (s *stream) SetDeadline(t time.Time) error {
+	setDeadline(&s.readDeadline, t)
+	setDeadline(&s.writeDeadline, t)
+	return s.Stream.SetDeadline(t)
+}
+
+func (s *stream) SetReadDeadline(t time.Time) error {
+	setDeadline(&s.readDeadline, t)
+	return s.Stream.SetReadDeadline(t)
+}
+
+func (s *stream) SetWriteDeadline(t time.Time) error {
+	setDeadline(&s.writeDeadline, t)
+	return s.Stream.SetWriteDeadline(t)
+}
+
+func setDeadline(d *atomic.Int64, t time.Time) {
+	if t.IsZero() {
+		d.Store(0)
+		return
+	}
+	d.Store(t.UnixNano())
+}
+
+func deadline(d *atomic.Int64) time.Time {
+	if n := d.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
 func (s *stream) Headers() p2p.Headers {
 	return s.headers
 }
@@ -0,0 +1,401 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// trafficChunkSize is the maximal number of bytes accounted at once,
+	// so that the large reads and writes are spread over time.
+	trafficChunkSize = 32 * 1024
+	// minTrafficBurst bounds the bucket size of the small rates.
+	minTrafficBurst = 2 * trafficChunkSize
+
+	directionUpload   = "upload"
+	directionDownload = "download"
+)
+
+// TrafficPriority is the priority class of a protocol. When the global
+// bandwidth is exhausted, the protocols of the higher classes are served
+// first.
+type TrafficPriority int
+
+const (
+	TrafficPriorityHigh TrafficPriority = iota
+	TrafficPriorityNormal
+	TrafficPriorityLow
+)
+
+// trafficReserves are the fractions of the global buckets that the
+// priority classes leave to the higher classes.
+var trafficReserves = [...]float64{
+	TrafficPriorityHigh:   0,
+	TrafficPriorityNormal: 0.25,
+	TrafficPriorityLow:    0.5,
+}
+
+// defaultTrafficPriorities are the priority classes of the protocols that
+// are not configured explicitly. The remaining protocols are normal.
+var defaultTrafficPriorities = map[string]TrafficPriority{
+	"retrieval": TrafficPriorityHigh,
+	"pushsync":  TrafficPriorityHigh,
+	"pullsync":  TrafficPriorityLow,
+}
+
+func (p TrafficPriority) String() string {
+	switch p {
+	case TrafficPriorityHigh:
+		return "high"
+	case TrafficPriorityNormal:
+		return "normal"
+	case TrafficPriorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseTrafficPriority parses the name of a priority class.
+func ParseTrafficPriority(s string) (TrafficPriority, error) {
+	switch s {
+	case "high":
+		return TrafficPriorityHigh, nil
+	case "normal", "":
+		return TrafficPriorityNormal, nil
+	case "low":
+		return TrafficPriorityLow, nil
+	default:
+		return 0, fmt.Errorf("invalid traffic priority %q", s)
+	}
+}
+
+// ProtocolTraffic is the traffic budget of a protocol.
+type ProtocolTraffic struct {
+	// UploadRate and DownloadRate are the bandwidth limits in bytes per
+	// second. Zero means unlimited.
+	UploadRate   int64
+	DownloadRate int64
+	Priority     TrafficPriority
+}
+
+// TrafficOptions configures the bandwidth shaping of the protocol streams.
+type TrafficOptions struct {
+	// UploadRate and DownloadRate are the limits of the total bandwidth
+	// of all protocols in bytes per second. Zero means unlimited.
+	UploadRate   int64
+	DownloadRate int64
+	// Protocols are the budgets of the protocols by the protocol name.
+	Protocols map[string]ProtocolTraffic
+}
+
+// ParseProtocolTraffic parses the budget of a protocol in the form
+// name:upload-rate:download-rate[:priority] with the rates in bytes per
+// second where zero or an empty rate means unlimited.
+func ParseProtocolTraffic(s string) (string, ProtocolTraffic, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] == "" {
+		return "", ProtocolTraffic{}, fmt.Errorf("invalid protocol traffic %q", s)
+	}
+
+	var (
+		t   ProtocolTraffic
+		err error
+	)
+	for i, rate := range []*int64{&t.UploadRate, &t.DownloadRate} {
+		if parts[i+1] == "" {
+			continue
+		}
+		if *rate, err = strconv.ParseInt(parts[i+1], 10, 64); err != nil || *rate < 0 {
+			return "", ProtocolTraffic{}, fmt.Errorf("invalid protocol traffic rate %q", parts[i+1])
+		}
+	}
+
+	t.Priority = defaultTrafficPriority(parts[0])
+	if len(parts) == 4 {
+		if t.Priority, err = ParseTrafficPriority(parts[3]); err != nil {
+			return "", ProtocolTraffic{}, err
+		}
+	}
+	return parts[0], t, nil
+}
+
+func defaultTrafficPriority(protocol string) TrafficPriority {
+	if p, ok := defaultTrafficPriorities[protocol]; ok {
+		return p
+	}
+	return TrafficPriorityNormal
+}
+
+// trafficBucket is a token bucket of bytes.
+type trafficBucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTrafficBucket returns nil for the unlimited rates.
+func newTrafficBucket(rate int64, now time.Time) *trafficBucket {
+	if rate <= 0 {
+		return nil
+	}
+	burst := max(float64(rate), minTrafficBurst)
+	return &trafficBucket{
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// take consumes n tokens if the bucket holds them on top of the reserved
+// fraction of its size. Otherwise it returns the time after which the
+// tokens are expected to be available.
+func (b *trafficBucket) take(now time.Time, n int, reserve float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+
+	need := min(float64(n)+reserve*b.burst, b.burst)
+	if b.tokens >= need {
+		b.tokens -= float64(n)
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+// refund returns the n tokens taken from the bucket.
+func (b *trafficBucket) refund(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+float64(n))
+}
+
+// protocolTraffic holds the buckets and the usage of a protocol.
+type protocolTraffic struct {
+	name     string
+	budget   ProtocolTraffic
+	upload   *trafficBucket
+	download *trafficBucket
+
+	uploaded          atomic.Uint64
+	downloaded        atomic.Uint64
+	uploadThrottled   atomic.Int64
+	downloadThrottled atomic.Int64
+
+	uploadBytes              prometheus.Counter
+	downloadBytes            prometheus.Counter
+	uploadThrottledSeconds   prometheus.Counter
+	downloadThrottledSeconds prometheus.Counter
+}
+
+// trafficController shapes the bandwidth of the protocol streams with the
+// token buckets of the protocols and the global buckets shared by them.
+type trafficController struct {
+	opts     TrafficOptions
+	upload   *trafficBucket
+	download *trafficBucket
+	metrics  metrics
+	now      func() time.Time
+	limits   bool
+
+	mu        sync.Mutex
+	protocols map[string]*protocolTraffic
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newTrafficController(o TrafficOptions, m metrics, now func() time.Time) *trafficController {
+	ctx, cancel := context.WithCancel(context.Background())
+	limits := o.UploadRate > 0 || o.DownloadRate > 0
+	for _, t := range o.Protocols {
+		limits = limits || t.UploadRate > 0 || t.DownloadRate > 0
+	}
+	return &trafficController{
+		opts:      o,
+		limits:    limits,
+		upload:    newTrafficBucket(o.UploadRate, now()),
+		download:  newTrafficBucket(o.DownloadRate, now()),
+		metrics:   m,
+		now:       now,
+		protocols: make(map[string]*protocolTraffic),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// enabled reports whether any bandwidth limit is configured. Otherwise the
+// streams are not shaped and their usage is not recorded.
+func (c *trafficController) enabled() bool {
+	return c.limits
+}
+
+// limited reports whether the traffic of the protocol in the direction is
+// subject to a protocol or a global bandwidth limit.
+func (c *trafficController) limited(p *protocolTraffic, direction string) bool {
+	if direction == directionDownload {
+		return p.download != nil || c.download != nil
+	}
+	return p.upload != nil || c.upload != nil
+}
+
+// protocol returns the traffic state of the protocol.
+func (c *trafficController) protocol(name string) *protocolTraffic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p, ok := c.protocols[name]; ok {
+		return p
+	}
+
+	budget, ok := c.opts.Protocols[name]
+	if !ok {
+		budget = ProtocolTraffic{Priority: defaultTrafficPriority(name)}
+	}
+	now := c.now()
+	p := &protocolTraffic{
+		name:                     name,
+		budget:                   budget,
+		upload:                   newTrafficBucket(budget.UploadRate, now),
+		download:                 newTrafficBucket(budget.DownloadRate, now),
+		uploadBytes:              c.metrics.TrafficBytes.WithLabelValues(name, directionUpload),
+		downloadBytes:            c.metrics.TrafficBytes.WithLabelValues(name, directionDownload),
+		uploadThrottledSeconds:   c.metrics.TrafficThrottledSeconds.WithLabelValues(name, directionUpload),
+		downloadThrottledSeconds: c.metrics.TrafficThrottledSeconds.WithLabelValues(name, directionDownload),
+	}
+	c.protocols[name] = p
+	return p
+}
+
+// delay returns the time to wait before the n bytes of the protocol can be
+// transferred in the direction or zero if the tokens have been taken.
+func (c *trafficController) delay(p *protocolTraffic, direction string, n int) time.Duration {
+	local, global := p.upload, c.upload
+	if direction == directionDownload {
+		local, global = p.download, c.download
+	}
+
+	now := c.now()
+	if local != nil {
+		if d := local.take(now, n, 0); d > 0 {
+			return d
+		}
+	}
+	if global != nil {
+		if d := global.take(now, n, trafficReserves[p.budget.Priority]); d > 0 {
+			if local != nil {
+				local.refund(n)
+			}
+			return d
+		}
+	}
+	return 0
+}
+
+// wait blocks until the n bytes of the protocol can be transferred in the
+// direction and records the usage. It returns early with the context error
+// if the context is done and with os.ErrDeadlineExceeded if the tokens are
+// not expected to be available before the non-zero deadline.
+func (c *trafficController) wait(ctx context.Context, deadline time.Time, p *protocolTraffic, direction string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	var throttled time.Duration
+	for {
+		d := c.delay(p, direction, n)
+		if d == 0 {
+			break
+		}
+		if !deadline.IsZero() && c.now().Add(d).After(deadline) {
+			return os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-c.ctx.Done():
+			timer.Stop()
+			return net.ErrClosed
+		}
+		throttled += d
+	}
+
+	if direction == directionUpload {
+		p.uploaded.Add(uint64(n))
+		p.uploadBytes.Add(float64(n))
+		if throttled > 0 {
+			p.uploadThrottled.Add(int64(throttled))
+			p.uploadThrottledSeconds.Add(throttled.Seconds())
+		}
+	} else {
+		p.downloaded.Add(uint64(n))
+		p.downloadBytes.Add(float64(n))
+		if throttled > 0 {
+			p.downloadThrottled.Add(int64(throttled))
+			p.downloadThrottledSeconds.Add(throttled.Seconds())
+		}
+	}
+	return nil
+}
+
+// usage returns the budgets and the usage of the protocols ordered by name.
+func (c *trafficController) usage() p2p.TrafficUsage {
+	c.mu.Lock()
+	protocols := make([]*protocolTraffic, 0, len(c.protocols))
+	for _, p := range c.protocols {
+		protocols = append(protocols, p)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(protocols, func(i, j int) bool { return protocols[i].name < protocols[j].name })
+
+	u := p2p.TrafficUsage{
+		UploadRate:   c.opts.UploadRate,
+		DownloadRate: c.opts.DownloadRate,
+		Protocols:    make([]p2p.ProtocolTrafficUsage, 0, len(protocols)),
+	}
+	for _, p := range protocols {
+		u.Protocols = append(u.Protocols, p2p.ProtocolTrafficUsage{
+			Protocol:          p.name,
+			Priority:          p.budget.Priority.String(),
+			UploadRate:        p.budget.UploadRate,
+			DownloadRate:      p.budget.DownloadRate,
+			Uploaded:          p.uploaded.Load(),
+			Downloaded:        p.downloaded.Load(),
+			UploadThrottled:   time.Duration(p.uploadThrottled.Load()),
+			DownloadThrottled: time.Duration(p.downloadThrottled.Load()),
+		})
+	}
+	return u
+}
+
+// close releases the blocked streams.
+func (c *trafficController) close() {
+	c.cancel()
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/p2p/libp2p"
+)
+
+func TestStreamTraffic(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		FullNode: true,
+	}})
+	s2, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		Traffic: libp2p.TrafficOptions{UploadRate: 1 << 20},
+	}})
+
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, stream p2p.Stream) error {
+		_, err := io.Copy(io.Discard, stream)
+		return err
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s2.Connect(ctx, serviceUnderlayAddress(t, s1)); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Write(make([]byte, 100*1024)); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := s2.TrafficUsage()
+	if usage.UploadRate != 1<<20 {
+		t.Fatalf("got upload rate %d, want %d", usage.UploadRate, 1<<20)
+	}
+	if len(usage.Protocols) != 1 || usage.Protocols[0].Protocol != testProtocolName || usage.Protocols[0].Uploaded != 100*1024 {
+		t.Fatalf("unexpected usage %+v", usage.Protocols)
+	}
+
+	// the streams of the node without limits are not shaped
+	if u := s1.TrafficUsage(); len(u.Protocols) != 0 {
+		t.Fatalf("unexpected usage %+v", u.Protocols)
+	}
+}
+
+func TestTrafficController(t *testing.T) {
+	t.Parallel()
+
+	const chunk = 32 * 1024
+
+	t.Run("priority", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		c := libp2p.NewTrafficController(libp2p.TrafficOptions{DownloadRate: 100 * 1024}, func() time.Time { return now })
+
+		if d := c.Delay("pullsync", libp2p.DirectionDownload, chunk); d != 0 {
+			t.Fatalf("got delay %v, want none", d)
+		}
+		// low priority protocols leave half of the bucket to the others
+		if d := c.Delay("pullsync", libp2p.DirectionDownload, chunk); d == 0 {
+			t.Fatal("want low priority delay")
+		}
+		for i := 0; i < 2; i++ {
+			if d := c.Delay("retrieval", libp2p.DirectionDownload, chunk); d != 0 {
+				t.Fatalf("got delay %v, want none", d)
+			}
+		}
+		if d := c.Delay("retrieval", libp2p.DirectionDownload, chunk); d == 0 {
+			t.Fatal("want delay of exhausted bucket")
+		}
+		// the upload is not limited
+		if d := c.Delay("pullsync", libp2p.DirectionUpload, 10*chunk); d != 0 {
+			t.Fatalf("got delay %v, want none", d)
+		}
+
+		now = now.Add(time.Second)
+		if d := c.Delay("pullsync", libp2p.DirectionDownload, chunk); d != 0 {
+			t.Fatalf("got delay %v, want none", d)
+		}
+	})
+
+	t.Run("protocol budget", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		c := libp2p.NewTrafficController(libp2p.TrafficOptions{
+			Protocols: map[string]libp2p.ProtocolTraffic{
+				"pullsync": {UploadRate: 64 * 1024},
+			},
+		}, func() time.Time { return now })
+
+		for i := 0; i < 2; i++ {
+			if d := c.Delay("pullsync", libp2p.DirectionUpload, chunk); d != 0 {
+				t.Fatalf("got delay %v, want none", d)
+			}
+		}
+		if d := c.Delay("pullsync", libp2p.DirectionUpload, chunk); d != 500*time.Millisecond {
+			t.Fatalf("got delay %v, want %v", d, 500*time.Millisecond)
+		}
+		if d := c.Delay("retrieval", libp2p.DirectionUpload, chunk); d != 0 {
+			t.Fatalf("got delay %v, want none", d)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		t.Parallel()
+
+		c := libp2p.NewTrafficController(libp2p.TrafficOptions{UploadRate: 1 << 20}, time.Now)
+		for _, w := range []struct {
+			protocol, direction string
+			n                   int
+		}{
+			{"retrieval", libp2p.DirectionUpload, 100},
+			{"retrieval", libp2p.DirectionDownload, 200},
+			{"hive", libp2p.DirectionDownload, 300},
+		} {
+			if err := c.Wait(context.Background(), time.Time{}, w.protocol, w.direction, w.n); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		u := c.Usage()
+		if u.UploadRate != 1<<20 || u.DownloadRate != 0 {
+			t.Fatalf("got rates %d/%d", u.UploadRate, u.DownloadRate)
+		}
+		if len(u.Protocols) != 2 {
+			t.Fatalf("got %d protocols, want 2", len(u.Protocols))
+		}
+		hive, retrieval := u.Protocols[0], u.Protocols[1]
+		if hive.Protocol != "hive" || hive.Priority != "normal" || hive.Downloaded != 300 {
+			t.Fatalf("unexpected hive usage %+v", hive)
+		}
+		if retrieval.Protocol != "retrieval" || retrieval.Priority != "high" || retrieval.Uploaded != 100 || retrieval.Downloaded != 200 {
+			t.Fatalf("unexpected retrieval usage %+v", retrieval)
+		}
+	})
+}
+
+func TestTrafficWait(t *testing.T) {
+	t.Parallel()
+
+	const chunk = 32 * 1024
+
+	newExhausted := func(t *testing.T) *libp2p.TrafficController {
+		t.Helper()
+
+		c := libp2p.NewTrafficController(libp2p.TrafficOptions{UploadRate: 2 * chunk}, time.Now)
+		if err := c.Wait(context.Background(), time.Time{}, "hive", libp2p.DirectionUpload, 2*chunk); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	t.Run("context", func(t *testing.T) {
+		t.Parallel()
+
+		c := newExhausted(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := c.Wait(ctx, time.Time{}, "hive", libp2p.DirectionUpload, chunk); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		c := newExhausted(t)
+		if err := c.Wait(context.Background(), time.Now().Add(time.Millisecond), "hive", libp2p.DirectionUpload, chunk); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("got error %v, want %v", err, os.ErrDeadlineExceeded)
+		}
+		if u := c.Usage(); u.Protocols[0].Uploaded != 2*chunk {
+			t.Fatalf("got uploaded %d, want %d", u.Protocols[0].Uploaded, 2*chunk)
+		}
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+
+		c := libp2p.NewTrafficController(libp2p.TrafficOptions{
+			Protocols: map[string]libp2p.ProtocolTraffic{"pullsync": {Priority: libp2p.TrafficPriorityLow}},
+		}, time.Now)
+		if c.Enabled() {
+			t.Fatal("want shaping disabled")
+		}
+	})
+}
+
+func TestParseProtocolTraffic(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in      string
+		name    string
+		want    libp2p.ProtocolTraffic
+		wantErr bool
+	}{
+		{in: "pullsync:1024:2048", name: "pullsync", want: libp2p.ProtocolTraffic{UploadRate: 1024, DownloadRate: 2048, Priority: libp2p.TrafficPriorityLow}},
+		{in: "retrieval::4096:normal", name: "retrieval", want: libp2p.ProtocolTraffic{DownloadRate: 4096, Priority: libp2p.TrafficPriorityNormal}},
+		{in: "hive:0:0:high", name: "hive", want: libp2p.ProtocolTraffic{Priority: libp2p.TrafficPriorityHigh}},
+		{in: "hive:1", wantErr: true},
+		{in: "hive:-1:0", wantErr: true},
+		{in: "hive:1:1:urgent", wantErr: true},
+		{in: ":1:1", wantErr: true},
+	} {
+		name, got, err := libp2p.ParseProtocolTraffic(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: want error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		if name != tc.name || got != tc.want {
+			t.Errorf("%s: got %s %+v, want %s %+v", tc.in, name, got, tc.name, tc.want)
+		}
+	}
+}
@@ -21,7 +21,7 @@ type UpnpAddressResolver struct {
 // In some NAT situations, for example in the case when nodes are behind upnp, observer might send the observed address with a wrong port.
 // In this case, observed address is compared to addresses provided by host, and if there is a same address but with different port, that one is used as advertisable address instead of provided observed one.
 // TODO: this is a quickfix and it will be improved in the future
-func (r *UpnpAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multiaddr, error) {
+func (r *UpnpAddressResolver) Resolve(observedAddress ma.Multiaddr) ([]ma.Multiaddr, error) {
 	observableAddrInfo, err := libp2ppeer.AddrInfoFromP2pAddr(observedAddress)
 	if err != nil {
 		return nil, err
@@ -35,7 +35,7 @@ func (r *UpnpAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multiadd
 
 	// if address is not in a form of '/ipversion/ip/protocol/port/...` don't compare to addresses and return it
 	if len(observedAddrSplit) < 5 {
-		return observedAddress, nil
+		return []ma.Multiaddr{observedAddress}, nil
 	}
 
 	observedAddressPort := observedAddrSplit[4]
@@ -60,9 +60,9 @@ func (r *UpnpAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multiadd
 				continue
 			}
 
-			return aaddress, nil
+			return []ma.Multiaddr{aaddress}, nil
 		}
 	}
 
-	return observedAddress, nil
+	return []ma.Multiaddr{observedAddress}, nil
 }
@@ -27,6 +27,7 @@ type Service struct {
 	setWelcomeMessageFunc func(string) error
 	getWelcomeMessageFunc func() string
 	blocklistFunc         func(swarm.Address, time.Duration, string) error
+	trafficUsageFunc      func() p2p.TrafficUsage
 	welcomeMessage        string
 }
 
@@ -65,6 +66,13 @@ func WithBlocklistedPeersFunc(f func() ([]p2p.BlockListedPeer, error)) Option {
 	})
 }
 
+// WithTrafficUsageFunc sets the mock implementation of the TrafficUsage function
+func WithTrafficUsageFunc(f func() p2p.TrafficUsage) Option {
+	return optionFunc(func(s *Service) {
+		s.trafficUsageFunc = f
+	})
+}
+
 // WithAddressesFunc sets the mock implementation of the Adresses function
 func WithAddressesFunc(f func() ([]ma.Multiaddr, error)) Option {
 	return optionFunc(func(s *Service) {
@@ -153,6 +161,14 @@ func (s *Service) BlocklistedPeers() ([]p2p.BlockListedPeer, error) {
 	return s.blocklistedPeersFunc()
 }
 
+func (s *Service) TrafficUsage() p2p.TrafficUsage {
+	if s.trafficUsageFunc == nil {
+		return p2p.TrafficUsage{}
+	}
+
+	return s.trafficUsageFunc()
+}
+
 func (s *Service) SetWelcomeMessage(val string) error {
 	if s.setWelcomeMessageFunc != nil {
 		return s.setWelcomeMessageFunc(val)
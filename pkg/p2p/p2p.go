@@ -113,7 +113,7 @@ type ReachableNotifier interface {
 }
 
 type Reacher interface {
-	Connected(swarm.Address, ma.Multiaddr)
+	Connected(swarm.Address, []ma.Multiaddr)
 	Disconnected(swarm.Address)
 	Close() error
 }
@@ -186,6 +186,20 @@ type StreamSpec struct {
 	Headler HeadlerFunc
 }
 
+// EventRecorder is implemented by the notifiers that record the reasons of
+// the disconnects and the blocklistings initiated by the node. The reason of
+// a disconnect is recorded before the notifier is told about the disconnect.
+type EventRecorder interface {
+	RecordDisconnectReason(overlay swarm.Address, reason string)
+	RecordBlocklist(overlay swarm.Address, duration time.Duration, reason string)
+}
+
+// CertificateVerifier verifies the membership certificates presented by
+// the peers in the handshake of a private network.
+type CertificateVerifier interface {
+	Verify(overlay swarm.Address, certificate []byte) error
+}
+
 // Peer holds information about a Peer.
 type Peer struct {
 	Address         swarm.Address
@@ -200,6 +214,33 @@ type BlockListedPeer struct {
 	Duration time.Duration
 }
 
+// TrafficReporter is implemented by the services that shape the bandwidth
+// of the protocol streams.
+type TrafficReporter interface {
+	TrafficUsage() TrafficUsage
+}
+
+// TrafficUsage holds the bandwidth limits and the usage of the protocols.
+// The rates are in bytes per second, zero means unlimited.
+type TrafficUsage struct {
+	UploadRate   int64
+	DownloadRate int64
+	Protocols    []ProtocolTrafficUsage
+}
+
+// ProtocolTrafficUsage holds the bandwidth budget of a protocol, the number
+// of bytes transferred and the time spent waiting for the budget.
+type ProtocolTrafficUsage struct {
+	Protocol          string
+	Priority          string
+	UploadRate        int64
+	DownloadRate      int64
+	Uploaded          uint64
+	Downloaded        uint64
+	UploadThrottled   time.Duration
+	DownloadThrottled time.Duration
+}
+
 // HandlerFunc handles a received Stream from a Peer.
 type HandlerFunc func(context.Context, Peer, Stream) error
 
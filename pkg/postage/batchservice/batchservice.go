@@ -14,6 +14,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/storage"
@@ -40,6 +41,11 @@ type batchService struct {
 
 	checksum hash.Hash // checksum hasher
 	resync   bool
+	signer   crypto.Signer // signs the checkpoints
+
+	// replayUntil is the last block processed before a rollback. The batch
+	// listener is not notified again about the events up to this block.
+	replayUntil uint64
 }
 
 type Interface interface {
@@ -56,6 +62,7 @@ func New(
 	batchListener postage.BatchEventListener,
 	checksumFunc func() hash.Hash,
 	resync bool,
+	signer crypto.Signer,
 ) (Interface, error) {
 	if checksumFunc == nil {
 		checksumFunc = sha3.New256
@@ -95,7 +102,17 @@ func New(
 		}
 	}
 
-	return &batchService{stateStore, storer, logger.WithName(loggerName).Register(), listener, owner, batchListener, sum, resync}, nil
+	return &batchService{
+		stateStore:    stateStore,
+		storer:        storer,
+		logger:        logger.WithName(loggerName).Register(),
+		listener:      listener,
+		owner:         owner,
+		batchListener: batchListener,
+		checksum:      sum,
+		resync:        resync,
+		signer:        signer,
+	}, nil
 }
 
 // Create will create a new batch with the given ID, owner value and depth and
@@ -125,7 +142,7 @@ func (svc *batchService) Create(id, owner []byte, totalAmout, normalisedBalance
 
 	amount := big.NewInt(0).Div(totalAmout, big.NewInt(int64(1<<(batch.Depth))))
 
-	if bytes.Equal(svc.owner, owner) && svc.batchListener != nil {
+	if bytes.Equal(svc.owner, owner) && svc.notify() {
 		if err := svc.batchListener.HandleCreate(batch, amount); err != nil {
 			return fmt.Errorf("create batch: %w", err)
 		}
@@ -155,7 +172,7 @@ func (svc *batchService) TopUp(id []byte, totalAmout, normalisedBalance *big.Int
 
 	topUpAmount := big.NewInt(0).Div(totalAmout, big.NewInt(int64(1<<(b.Depth))))
 
-	if bytes.Equal(svc.owner, b.Owner) && svc.batchListener != nil {
+	if bytes.Equal(svc.owner, b.Owner) && svc.notify() {
 		svc.batchListener.HandleTopUp(id, topUpAmount)
 	}
 
@@ -180,7 +197,7 @@ func (svc *batchService) UpdateDepth(id []byte, depth uint8, normalisedBalance *
 		return fmt.Errorf("put: %w", err)
 	}
 
-	if bytes.Equal(svc.owner, b.Owner) && svc.batchListener != nil {
+	if bytes.Equal(svc.owner, b.Owner) && svc.notify() {
 		svc.batchListener.HandleDepthIncrease(id, depth)
 	}
 
@@ -246,6 +263,19 @@ func (svc *batchService) Start(ctx context.Context, startBlock uint64, initState
 		return err
 	}
 
+	if dirty && !svc.resync && initState == nil {
+		rolledBack, err := svc.rollbackLatest()
+		if err != nil {
+			return err
+		}
+		if rolledBack {
+			if err := svc.stateStore.Delete(dirtyDBKey); err != nil {
+				return err
+			}
+			dirty = false
+		}
+	}
+
 	if dirty || svc.resync || initState != nil {
 
 		if dirty {
@@ -257,6 +287,9 @@ func (svc *batchService) Start(ctx context.Context, startBlock uint64, initState
 		if err := svc.storer.Reset(); err != nil {
 			return err
 		}
+		if err := svc.deleteCheckpoints(); err != nil {
+			return err
+		}
 		if err := svc.stateStore.Delete(dirtyDBKey); err != nil {
 			return err
 		}
@@ -277,6 +310,15 @@ func (svc *batchService) Start(ctx context.Context, startBlock uint64, initState
 	return <-syncedChan
 }
 
+// notify reports whether the batch listener should be notified about the
+// event being processed.
+func (svc *batchService) notify() bool {
+	if svc.batchListener == nil {
+		return false
+	}
+	return svc.replayUntil == 0 || svc.storer.GetChainState().Block > svc.replayUntil
+}
+
 // updateChecksum updates the batchservice checksum once an event gets
 // processed. It swaps the existing checksum which is in the hasher
 // with the new checksum and persists it in the statestore.
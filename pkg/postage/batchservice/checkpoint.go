@@ -0,0 +1,312 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batchservice
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+)
+
+const (
+	checkpointKeyPrefix = "batchservice_checkpoint_"
+	trackedBlockKey     = "batchservice_tracked_block"
+
+	// maxCheckpoints is the number of the newest checkpoints that are kept.
+	maxCheckpoints = 4
+)
+
+var _ postage.Checkpointer = (*batchService)(nil)
+
+// checkpoint is the state of the batch store after processing the events
+// up to the block.
+type checkpoint struct {
+	Block        uint64      `json:"block"`
+	BlockHash    common.Hash `json:"blockHash"`
+	TotalAmount  *big.Int    `json:"totalAmount"`
+	CurrentPrice *big.Int    `json:"currentPrice"`
+	Batches      [][]byte    `json:"batches"`
+	Checksum     string      `json:"checksum"`
+	Signature    []byte      `json:"signature,omitempty"`
+}
+
+// digest returns the signed data of the checkpoint.
+func (c *checkpoint) digest() ([]byte, error) {
+	unsigned := *c
+	unsigned.Signature = nil
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.LegacyKeccak256(data)
+}
+
+type trackedBlock struct {
+	Block uint64      `json:"block"`
+	Hash  common.Hash `json:"hash"`
+}
+
+func checkpointKey(block uint64) string {
+	// the block number is padded so that the keys iterate in the block order
+	return fmt.Sprintf("%s%020d", checkpointKeyPrefix, block)
+}
+
+// TrackBlock implements the postage.Checkpointer interface.
+func (svc *batchService) TrackBlock(block uint64, hash common.Hash) error {
+	return svc.stateStore.Put(trackedBlockKey, trackedBlock{Block: block, Hash: hash})
+}
+
+// TrackedBlock implements the postage.Checkpointer interface.
+func (svc *batchService) TrackedBlock() (uint64, common.Hash, error) {
+	var tb trackedBlock
+	if err := svc.stateStore.Get(trackedBlockKey, &tb); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, common.Hash{}, nil
+		}
+		return 0, common.Hash{}, err
+	}
+	return tb.Block, tb.Hash, nil
+}
+
+// Checkpoint implements the postage.Checkpointer interface. The checkpoint
+// is signed by the node, so that a tampered or corrupted one is not used.
+func (svc *batchService) Checkpoint(block uint64, hash common.Hash) error {
+	cs := svc.storer.GetChainState()
+	c := &checkpoint{
+		Block:        block,
+		BlockHash:    hash,
+		TotalAmount:  new(big.Int).Set(cs.TotalAmount),
+		CurrentPrice: new(big.Int).Set(cs.CurrentPrice),
+		Checksum:     hex.EncodeToString(svc.checksum.Sum(nil)),
+	}
+	err := svc.storer.Iterate(func(b *postage.Batch) (bool, error) {
+		data, err := b.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		c.Batches = append(c.Batches, data)
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("checkpoint batches: %w", err)
+	}
+
+	if svc.signer != nil {
+		digest, err := c.digest()
+		if err != nil {
+			return err
+		}
+		if c.Signature, err = svc.signer.Sign(digest); err != nil {
+			return fmt.Errorf("sign checkpoint: %w", err)
+		}
+	}
+
+	if err := svc.stateStore.Put(checkpointKey(block), c); err != nil {
+		return err
+	}
+
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		return err
+	}
+	for i := maxCheckpoints; i < len(checkpoints); i++ {
+		if err := svc.stateStore.Delete(checkpointKey(checkpoints[i].Block)); err != nil {
+			return err
+		}
+	}
+
+	svc.logger.Debug("postage checkpoint saved", "block", block, "block_hash", hash, "batches", len(c.Batches))
+	return nil
+}
+
+// Checkpoints implements the postage.Checkpointer interface.
+func (svc *batchService) Checkpoints() ([]postage.CheckpointInfo, error) {
+	var checkpoints []postage.CheckpointInfo
+	err := svc.stateStore.Iterate(checkpointKeyPrefix, func(key, value []byte) (bool, error) {
+		if !strings.HasPrefix(string(key), checkpointKeyPrefix) {
+			return true, nil
+		}
+		var c checkpoint
+		if err := json.Unmarshal(value, &c); err != nil {
+			return false, err
+		}
+		checkpoints = append(checkpoints, postage.CheckpointInfo{Block: c.Block, BlockHash: c.BlockHash})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first
+	for i, j := 0, len(checkpoints)-1; i < j; i, j = i+1, j-1 {
+		checkpoints[i], checkpoints[j] = checkpoints[j], checkpoints[i]
+	}
+	return checkpoints, nil
+}
+
+// Rollback implements the postage.Checkpointer interface. The callbacks of
+// the batch listener are not called for the replayed events that have been
+// already processed before the rollback.
+func (svc *batchService) Rollback(block uint64) error {
+	var c checkpoint
+	if err := svc.stateStore.Get(checkpointKey(block), &c); err != nil {
+		return fmt.Errorf("get checkpoint at block %d: %w", block, err)
+	}
+	if err := svc.verifyCheckpoint(&c); err != nil {
+		return err
+	}
+
+	batches := make([]*postage.Batch, 0, len(c.Batches))
+	for _, data := range c.Batches {
+		b := new(postage.Batch)
+		if err := b.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("checkpoint batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+
+	checksum, err := hex.DecodeString(c.Checksum)
+	if err != nil {
+		return fmt.Errorf("checkpoint checksum: %w", err)
+	}
+
+	// an interrupted rollback is repeated on the next start
+	if err := svc.TransactionStart(); err != nil {
+		return err
+	}
+
+	processed := svc.storer.GetChainState().Block
+	cs := &postage.ChainState{
+		Block:        c.Block,
+		TotalAmount:  c.TotalAmount,
+		CurrentPrice: c.CurrentPrice,
+	}
+	if r, ok := svc.storer.(postage.BatchRestorer); ok {
+		err = r.Restore(cs, batches)
+	} else {
+		err = svc.restore(cs, batches)
+	}
+	if err != nil {
+		return fmt.Errorf("restore checkpoint at block %d: %w", block, err)
+	}
+
+	svc.checksum.Reset()
+	if _, err := svc.checksum.Write(checksum); err != nil {
+		return err
+	}
+	if err := svc.stateStore.Put(checksumDBKey, c.Checksum); err != nil {
+		return err
+	}
+
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		return err
+	}
+	for _, info := range checkpoints {
+		if info.Block > block {
+			if err := svc.stateStore.Delete(checkpointKey(info.Block)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := svc.TrackBlock(c.Block, c.BlockHash); err != nil {
+		return err
+	}
+
+	if err := svc.TransactionEnd(); err != nil {
+		return err
+	}
+
+	svc.replayUntil = max(svc.replayUntil, processed)
+	svc.logger.Warning("postage state rolled back to checkpoint", "block", c.Block, "block_hash", c.BlockHash, "processed_block", processed)
+	return nil
+}
+
+// restore replaces the content of the storers that cannot restore at once.
+func (svc *batchService) restore(cs *postage.ChainState, batches []*postage.Batch) error {
+	if err := svc.storer.Reset(); err != nil {
+		return err
+	}
+	if err := svc.storer.PutChainState(cs); err != nil {
+		return err
+	}
+	for _, b := range batches {
+		if err := svc.storer.Save(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (svc *batchService) verifyCheckpoint(c *checkpoint) error {
+	if svc.signer == nil {
+		return nil
+	}
+
+	digest, err := c.digest()
+	if err != nil {
+		return err
+	}
+	pubKey, err := crypto.Recover(c.Signature, digest)
+	if err != nil {
+		return fmt.Errorf("%w: %w", postage.ErrInvalidCheckpoint, err)
+	}
+	signer, err := crypto.NewEthereumAddress(*pubKey)
+	if err != nil {
+		return err
+	}
+	own, err := svc.signer.EthereumAddress()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signer, own.Bytes()) {
+		return postage.ErrInvalidCheckpoint
+	}
+	return nil
+}
+
+// deleteCheckpoints removes the checkpoints and the tracked block.
+func (svc *batchService) deleteCheckpoints() error {
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		return err
+	}
+	for _, info := range checkpoints {
+		if err := svc.stateStore.Delete(checkpointKey(info.Block)); err != nil {
+			return err
+		}
+	}
+	return svc.stateStore.Delete(trackedBlockKey)
+}
+
+// rollbackLatest rolls back to the newest valid checkpoint. It reports
+// whether there was a checkpoint to roll back to.
+func (svc *batchService) rollbackLatest() (bool, error) {
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		return false, err
+	}
+	for _, info := range checkpoints {
+		err := svc.Rollback(info.Block)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, postage.ErrInvalidCheckpoint) {
+			return false, err
+		}
+		svc.logger.Warning("batch service: skipping invalid checkpoint", "block", info.Block, "error", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batchservice_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/v2/pkg/postage/batchstore"
+	postagetesting "github.com/ethersphere/bee/v2/pkg/postage/testing"
+	"github.com/ethersphere/bee/v2/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+func TestCheckpointRollback(t *testing.T) {
+	t.Parallel()
+
+	s, store := newTestBatchStore(t)
+	svc := newTestCheckpointer(t, s, store, newTestSigner(t))
+
+	batch1 := postagetesting.MustNewBatch(postagetesting.WithValue(100))
+	createBatch(t, store, batch1)
+	putChainState(t, store, &postage.ChainState{Block: 10, TotalAmount: big.NewInt(10), CurrentPrice: big.NewInt(1)})
+
+	hash10 := common.HexToHash("0a")
+	if err := svc.Checkpoint(10, hash10); err != nil {
+		t.Fatal(err)
+	}
+
+	batch2 := postagetesting.MustNewBatch(postagetesting.WithValue(200))
+	createBatch(t, store, batch2)
+	putChainState(t, store, &postage.ChainState{Block: 20, TotalAmount: big.NewInt(20), CurrentPrice: big.NewInt(2)})
+	if err := svc.Checkpoint(20, common.HexToHash("14")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.Rollback(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := store.Exists(batch1.ID); !ok {
+		t.Fatal("expected the batch of the checkpoint to exist")
+	}
+	if ok, _ := store.Exists(batch2.ID); ok {
+		t.Fatal("expected the batch created after the checkpoint to be removed")
+	}
+	if cs := store.GetChainState(); cs.Block != 10 || cs.TotalAmount.Cmp(big.NewInt(10)) != 0 || cs.CurrentPrice.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("got chain state %+v", cs)
+	}
+
+	block, hash, err := svc.TrackedBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block != 10 || hash != hash10 {
+		t.Fatalf("got tracked block %d %s, want %d %s", block, hash, 10, hash10)
+	}
+
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 1 || checkpoints[0].Block != 10 {
+		t.Fatalf("got checkpoints %v, want only the one at block 10", checkpoints)
+	}
+}
+
+func TestCheckpointPrune(t *testing.T) {
+	t.Parallel()
+
+	s, store := newTestBatchStore(t)
+	svc := newTestCheckpointer(t, s, store, nil)
+	putChainState(t, store, postagetesting.NewChainState())
+
+	for block := uint64(1); block <= 6; block++ {
+		if err := svc.Checkpoint(block, common.Hash{byte(block)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkpoints, err := svc.Checkpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint64{6, 5, 4, 3}
+	if len(checkpoints) != len(want) {
+		t.Fatalf("got %d checkpoints, want %d", len(checkpoints), len(want))
+	}
+	for i, c := range checkpoints {
+		if c.Block != want[i] {
+			t.Fatalf("got checkpoint at block %d, want %d", c.Block, want[i])
+		}
+	}
+}
+
+func TestCheckpointSignature(t *testing.T) {
+	t.Parallel()
+
+	s, store := newTestBatchStore(t)
+	putChainState(t, store, postagetesting.NewChainState())
+
+	svc := newTestCheckpointer(t, s, store, newTestSigner(t))
+	if err := svc.Checkpoint(10, common.HexToHash("0a")); err != nil {
+		t.Fatal(err)
+	}
+
+	other := newTestCheckpointer(t, s, store, newTestSigner(t))
+	if err := other.Rollback(10); !errors.Is(err, postage.ErrInvalidCheckpoint) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrInvalidCheckpoint)
+	}
+}
+
+func newTestBatchStore(t *testing.T) (storage.StateStorer, postage.Storer) {
+	t.Helper()
+
+	// the real statestore is used since the batch store deletes the keys
+	// while iterating over them on restore
+	s, err := leveldb.NewStateStore(t.TempDir(), log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, s)
+
+	store, err := batchstore.New(s, func([]byte) error { return nil }, 1<<22, log.Noop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, store
+}
+
+func newTestCheckpointer(t *testing.T, s storage.StateStorer, store postage.Storer, signer crypto.Signer) postage.Checkpointer {
+	t.Helper()
+
+	svc, err := batchservice.New(s, store, testLog, newMockListener(), nil, nil, nil, false, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc.(postage.Checkpointer)
+}
+
+func newTestSigner(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(key)
+}
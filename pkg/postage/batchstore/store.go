@@ -254,6 +254,44 @@ func (s *store) Reset() error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
+	return s.reset()
+}
+
+// Restore is implementation of postage.BatchRestorer interface Restore method.
+// Unlike saving the batches one by one, it cleans up the expired batches and
+// computes the radius only once.
+func (s *store) Restore(cs *postage.ChainState, batches []*postage.Batch) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := s.reset(); err != nil {
+		return err
+	}
+
+	for _, b := range batches {
+		if err := s.store.Put(batchKey(b.ID), b); err != nil {
+			return err
+		}
+		if err := s.store.Put(valueKey(b.Value, b.ID), nil); err != nil {
+			return fmt.Errorf("batchstore: allocate batch %x: %w", b.ID, err)
+		}
+	}
+
+	s.cs.Store(cs)
+
+	if err := s.cleanup(); err != nil {
+		return fmt.Errorf("batchstore: restore clean up: %w", err)
+	}
+	if err := s.computeRadius(); err != nil {
+		return fmt.Errorf("batchstore: restore adjust radius: %w", err)
+	}
+
+	return s.store.Put(chainStateKey, cs)
+}
+
+// reset removes all the batches and the chain state.
+// Must be called under lock.
+func (s *store) reset() error {
 	const prefix = "batchstore_"
 	if err := s.store.Iterate(prefix, func(k, _ []byte) (bool, error) {
 		return false, s.store.Delete(string(k))
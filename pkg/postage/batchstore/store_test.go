@@ -227,6 +227,48 @@ func TestBatchStore_Reset(t *testing.T) {
 	}
 }
 
+func TestBatchStore_Restore(t *testing.T) {
+	t.Parallel()
+
+	store := setupBatchStore(t, 16)
+	old := addBatch(t, store, 4, 5)
+
+	restorer, ok := store.(postage.BatchRestorer)
+	if !ok {
+		t.Fatal("expected the batch store to implement the batch restorer")
+	}
+
+	cs := &postage.ChainState{Block: 10, TotalAmount: big.NewInt(6), CurrentPrice: big.NewInt(1)}
+	live := postagetest.MustNewBatch(postagetest.WithValue(20), postagetest.WithDepth(8))
+	expired := postagetest.MustNewBatch(postagetest.WithValue(3), postagetest.WithDepth(8))
+	if err := restorer.Restore(cs, []*postage.Batch{live, expired}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		batch  *postage.Batch
+		exists bool
+	}{
+		{"replaced", old, false},
+		{"restored", live, true},
+		{"expired", expired, false},
+	} {
+		exists, err := store.Exists(tc.batch.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != tc.exists {
+			t.Fatalf("%s batch: got exists %t, want %t", tc.name, exists, tc.exists)
+		}
+	}
+
+	if got := store.GetChainState(); got.Block != cs.Block || got.TotalAmount.Cmp(cs.TotalAmount) != 0 {
+		t.Fatalf("got chain state %+v, want %+v", got, cs)
+	}
+	checkState(t, "restore", store, 4)
+}
+
 type testBatch struct {
 	depth         uint8
 	value         int
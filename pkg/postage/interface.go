@@ -6,6 +6,7 @@ package postage
 
 import (
 	"context"
+	"errors"
 	"io"
 	"math/big"
 
@@ -84,6 +85,40 @@ type ChainStateGetter interface {
 	GetChainState() *ChainState
 }
 
+// CheckpointInfo identifies a checkpoint of the postage state.
+type CheckpointInfo struct {
+	Block     uint64
+	BlockHash common.Hash
+}
+
+// ErrInvalidCheckpoint is returned by the Checkpointer when the checkpoint
+// was not signed by the node.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint signature")
+
+// Checkpointer is implemented by the event updaters that can save their
+// state at a block and roll it back after a reorg of the chain.
+type Checkpointer interface {
+	// TrackBlock records the hash of the last processed block.
+	TrackBlock(block uint64, hash common.Hash) error
+	// TrackedBlock returns the last recorded block and its hash.
+	// The block is zero if no block has been recorded.
+	TrackedBlock() (uint64, common.Hash, error)
+	// Checkpoint saves the current state as processed up to the block.
+	Checkpoint(block uint64, hash common.Hash) error
+	// Checkpoints returns the saved checkpoints, the newest first.
+	Checkpoints() ([]CheckpointInfo, error)
+	// Rollback restores the state saved by the checkpoint at the block and
+	// drops the newer checkpoints.
+	Rollback(block uint64) error
+}
+
+// BatchRestorer is implemented by the storers that can replace their whole
+// content at once.
+type BatchRestorer interface {
+	// Restore replaces the chain state and the batches of the storer.
+	Restore(cs *ChainState, batches []*Batch) error
+}
+
 // Listener provides a blockchain event iterator.
 type Listener interface {
 	io.Closer
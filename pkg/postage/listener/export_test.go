@@ -4,7 +4,13 @@
 
 package listener
 
+import "github.com/ethersphere/bee/v2/pkg/postage"
+
 var (
 	TailSize    = tailSize
 	BatchFactor = defaultBatchFactor
 )
+
+func SetCheckpointInterval(l postage.Listener, interval uint64) {
+	l.(*listener).checkpointInterval = interval
+}
@@ -7,6 +7,7 @@ package listener
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 	"sync"
@@ -31,6 +32,8 @@ const (
 	blockPage          = 5000      // how many blocks to sync every time we page
 	tailSize           = 4         // how many blocks to tail from the tip of the chain
 	defaultBatchFactor = uint64(5) // // minimal number of blocks to sync at once
+
+	defaultCheckpointInterval = 10000 // how many blocks between the checkpoints of the batch store
 )
 
 var (
@@ -40,6 +43,9 @@ var (
 
 var (
 	ErrPostageSyncingStalled = errors.New("postage syncing stalled")
+	ErrPostageReorg          = errors.New("postage chain reorg is deeper than the oldest checkpoint, resync is required")
+
+	errInconsistentLogs = errors.New("inconsistent logs")
 )
 
 type BlockHeightContractFilterer interface {
@@ -47,6 +53,12 @@ type BlockHeightContractFilterer interface {
 	BlockNumber(context.Context) (uint64, error)
 }
 
+// blockHeaderGetter is implemented by the chain backends that return block
+// headers. Chain reorgs are detected only with such backends.
+type blockHeaderGetter interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
 type listener struct {
 	logger    log.Logger
 	ev        BlockHeightContractFilterer
@@ -60,6 +72,7 @@ type listener struct {
 	stallingTimeout             time.Duration
 	backoffTime                 time.Duration
 	syncingStopped              *syncutil.Signaler
+	checkpointInterval          uint64
 
 	// Cached postage stamp contract event topics.
 	batchCreatedTopic       common.Hash
@@ -89,6 +102,7 @@ func New(
 		metrics:                     newMetrics(),
 		stallingTimeout:             stallingTimeout,
 		backoffTime:                 backoffTime,
+		checkpointInterval:          defaultCheckpointInterval,
 
 		batchCreatedTopic:       postageStampContractABI.Events["BatchCreated"].ID,
 		batchTopUpTopic:         postageStampContractABI.Events["BatchTopUp"].ID,
@@ -178,48 +192,53 @@ func (l *listener) processEvent(e types.Log, updater postage.EventUpdater) error
 	}
 }
 
-func (l *listener) Listen(ctx context.Context, from uint64, updater postage.EventUpdater, initState *postage.ChainSnapshot) <-chan error {
-	ctx, cancel := context.WithCancel(ctx)
-	go func() {
-		<-l.quit
-		cancel()
-	}()
+// processEvents applies the events to the updater and advances it to the
+// block to.
+func (l *listener) processEvents(events []types.Log, to uint64, updater postage.EventUpdater) error {
+	if err := updater.TransactionStart(); err != nil {
+		return err
+	}
 
-	processEvents := func(events []types.Log, to uint64) error {
-		if err := updater.TransactionStart(); err != nil {
+	for _, e := range events {
+		startEv := time.Now()
+		err := updater.UpdateBlockNumber(e.BlockNumber)
+		if err != nil {
 			return err
 		}
-
-		for _, e := range events {
-			startEv := time.Now()
-			err := updater.UpdateBlockNumber(e.BlockNumber)
-			if err != nil {
+		if err = l.processEvent(e, updater); err != nil {
+			// if we have a zero value batch - silence & log then move on
+			if !errors.Is(err, batchservice.ErrZeroValueBatch) {
 				return err
 			}
-			if err = l.processEvent(e, updater); err != nil {
-				// if we have a zero value batch - silence & log then move on
-				if !errors.Is(err, batchservice.ErrZeroValueBatch) {
-					return err
-				}
-				l.logger.Debug("failed processing event", "error", err)
-			}
-			totalTimeMetric(l.metrics.EventProcessDuration, startEv)
+			l.logger.Debug("failed processing event", "error", err)
 		}
+		totalTimeMetric(l.metrics.EventProcessDuration, startEv)
+	}
 
-		err := updater.UpdateBlockNumber(to)
-		if err != nil {
-			return err
-		}
+	err := updater.UpdateBlockNumber(to)
+	if err != nil {
+		return err
+	}
 
-		if err := updater.TransactionEnd(); err != nil {
-			return err
-		}
+	return updater.TransactionEnd()
+}
 
-		return nil
-	}
+// Replay applies the postage stamp contract events to the updater in the
+// same way as the listener does while syncing, without a chain backend.
+func Replay(logger log.Logger, postageStampContractABI abi.ABI, events []types.Log, to uint64, updater postage.EventUpdater) error {
+	l := New(nil, logger, nil, common.Address{}, postageStampContractABI, 0, 0, 0).(*listener)
+	return l.processEvents(events, to, updater)
+}
+
+func (l *listener) Listen(ctx context.Context, from uint64, updater postage.EventUpdater, initState *postage.ChainSnapshot) <-chan error {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		<-l.quit
+		cancel()
+	}()
 
 	if initState != nil {
-		err := processEvents(initState.Events, initState.LastBlockNumber+1)
+		err := l.processEvents(initState.Events, initState.LastBlockNumber+1, updater)
 		if err != nil {
 			l.logger.Error(err, "failed bootstrapping from initial state")
 		}
@@ -233,6 +252,23 @@ func (l *listener) Listen(ctx context.Context, from uint64, updater postage.Even
 
 	l.logger.Debug("batch factor", "value", batchFactor)
 
+	// the state of the batch store is checkpointed and verified against
+	// the chain only if both the updater and the backend support it
+	checkpointer, _ := updater.(postage.Checkpointer)
+	headers, _ := l.ev.(blockHeaderGetter)
+	if headers == nil {
+		checkpointer = nil
+	}
+	var lastCheckpoint uint64
+	if checkpointer != nil {
+		checkpoints, err := checkpointer.Checkpoints()
+		if err != nil {
+			l.logger.Warning("could not get postage checkpoints", "error", err)
+		} else if len(checkpoints) > 0 {
+			lastCheckpoint = checkpoints[0].Block
+		}
+	}
+
 	synced := make(chan error)
 	closeOnce := new(sync.Once)
 	paged := true
@@ -310,6 +346,27 @@ func (l *listener) Listen(ctx context.Context, from uint64, updater postage.Even
 				continue
 			}
 
+			if checkpointer != nil {
+				reorged, err := l.isReorged(ctx, checkpointer, headers)
+				if err != nil {
+					l.metrics.BackendErrors.Inc()
+					l.logger.Warning("could not verify the last processed block", "error", err)
+					lastConfirmedBlock = 0
+					continue
+				}
+				if reorged {
+					l.metrics.Reorgs.Inc()
+					block, err := l.rollback(ctx, checkpointer, headers)
+					if err != nil {
+						return err
+					}
+					lastCheckpoint = block
+					from = block + 1
+					paged = true
+					continue
+				}
+			}
+
 			// do some paging (sub-optimal)
 			if to-from >= blockPage {
 				paged = true
@@ -317,6 +374,20 @@ func (l *listener) Listen(ctx context.Context, from uint64, updater postage.Even
 			} else {
 				closeOnce.Do(func() { synced <- nil })
 			}
+
+			var toHash common.Hash
+			if checkpointer != nil {
+				l.metrics.BackendCalls.Inc()
+				header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+				if err != nil {
+					l.metrics.BackendErrors.Inc()
+					l.logger.Warning("could not get block header", "block", to, "error", err)
+					lastConfirmedBlock = 0
+					continue
+				}
+				toHash = header.Hash()
+			}
+
 			l.metrics.BackendCalls.Inc()
 
 			events, err := l.ev.FilterLogs(ctx, l.filterQuery(big.NewInt(int64(from)), big.NewInt(int64(to))))
@@ -327,10 +398,33 @@ func (l *listener) Listen(ctx context.Context, from uint64, updater postage.Even
 				continue
 			}
 
-			if err := processEvents(events, to); err != nil {
+			if checkpointer != nil {
+				if err := verifyLogs(events, from, to, toHash); err != nil {
+					l.metrics.InconsistentLogs.Inc()
+					l.logger.Warning("could not get logs", "error", err)
+					lastConfirmedBlock = 0
+					continue
+				}
+			}
+
+			if err := l.processEvents(events, to, updater); err != nil {
 				return err
 			}
 
+			if checkpointer != nil {
+				if err := checkpointer.TrackBlock(to, toHash); err != nil {
+					return err
+				}
+				if to/l.checkpointInterval > lastCheckpoint/l.checkpointInterval {
+					if err := checkpointer.Checkpoint(to, toHash); err != nil {
+						l.logger.Warning("could not checkpoint postage state", "block", to, "error", err)
+					} else {
+						l.metrics.Checkpoints.Inc()
+						lastCheckpoint = to
+					}
+				}
+			}
+
 			from = to + 1
 			lastProgress = time.Now()
 			totalTimeMetric(l.metrics.PageProcessDuration, start)
@@ -357,6 +451,79 @@ func (l *listener) Listen(ctx context.Context, from uint64, updater postage.Even
 	return synced
 }
 
+// isReorged reports whether the last processed block is no longer part of
+// the canonical chain.
+func (l *listener) isReorged(ctx context.Context, checkpointer postage.Checkpointer, headers blockHeaderGetter) (bool, error) {
+	block, hash, err := checkpointer.TrackedBlock()
+	if err != nil {
+		return false, err
+	}
+	if hash == (common.Hash{}) {
+		return false, nil
+	}
+
+	l.metrics.BackendCalls.Inc()
+	header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return false, err
+	}
+	if header.Hash() == hash {
+		return false, nil
+	}
+
+	l.logger.Warning("postage chain reorg detected", "block", block, "processed_hash", hash, "canonical_hash", header.Hash())
+	return true, nil
+}
+
+// rollback rolls the batch store back to the newest checkpoint that is still
+// part of the canonical chain and returns its block.
+func (l *listener) rollback(ctx context.Context, checkpointer postage.Checkpointer, headers blockHeaderGetter) (uint64, error) {
+	checkpoints, err := checkpointer.Checkpoints()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range checkpoints {
+		l.metrics.BackendCalls.Inc()
+		header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(c.Block))
+		if err != nil {
+			l.metrics.BackendErrors.Inc()
+			return 0, err
+		}
+		if header.Hash() != c.BlockHash {
+			continue
+		}
+
+		if err := checkpointer.Rollback(c.Block); err != nil {
+			if errors.Is(err, postage.ErrInvalidCheckpoint) {
+				l.logger.Warning("skipping postage checkpoint", "block", c.Block, "error", err)
+				continue
+			}
+			return 0, err
+		}
+		l.metrics.Rollbacks.Inc()
+		return c.Block, nil
+	}
+
+	return 0, ErrPostageReorg
+}
+
+// verifyLogs checks that the logs belong to the range of blocks on the chain
+// of the block hash at the end of the range.
+func verifyLogs(logs []types.Log, from, to uint64, toHash common.Hash) error {
+	for _, e := range logs {
+		switch {
+		case e.Removed:
+			return fmt.Errorf("%w: removed log in block %d", errInconsistentLogs, e.BlockNumber)
+		case e.BlockNumber < from || e.BlockNumber > to:
+			return fmt.Errorf("%w: log in block %d out of range %d-%d", errInconsistentLogs, e.BlockNumber, from, to)
+		case e.BlockNumber == to && e.BlockHash != toHash:
+			return fmt.Errorf("%w: log in block %d with hash %s, want %s", errInconsistentLogs, e.BlockNumber, e.BlockHash, toHash)
+		}
+	}
+	return nil
+}
+
 func (l *listener) Close() error {
 	close(l.quit)
 
@@ -28,6 +28,12 @@ type metrics struct {
 	// processing durations
 	PageProcessDuration  prometheus.Counter
 	EventProcessDuration prometheus.Counter
+
+	// chain reorg handling
+	Reorgs           prometheus.Counter
+	Rollbacks        prometheus.Counter
+	Checkpoints      prometheus.Counter
+	InconsistentLogs prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -111,6 +117,32 @@ func newMetrics() metrics {
 			Name:      "event_duration",
 			Help:      "how long it took to process a single event",
 		}),
+
+		// chain reorg handling
+		Reorgs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reorgs",
+			Help:      "total chain reorgs detected",
+		}),
+		Rollbacks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rollbacks",
+			Help:      "total rollbacks of the batch store to a checkpoint",
+		}),
+		Checkpoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "checkpoints",
+			Help:      "total checkpoints of the batch store",
+		}),
+		InconsistentLogs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "inconsistent_logs",
+			Help:      "total log queries rejected as inconsistent with the chain",
+		}),
 	}
 }
 
@@ -0,0 +1,219 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package listener_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/postage/listener"
+	"github.com/ethersphere/bee/v2/pkg/spinlock"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+func TestListenerReorg(t *testing.T) {
+	t.Parallel()
+
+	chain := newReorgFilterer(9)
+	ev := newCheckpointUpdater()
+
+	l := listener.New(
+		nil,
+		log.Noop,
+		chain,
+		postageStampContractAddress,
+		postageStampContractABI,
+		time.Millisecond,
+		stallingTimeout,
+		time.Millisecond,
+	)
+	listener.SetCheckpointInterval(l, listener.BatchFactor)
+	testutil.CleanupCloser(t, l)
+	<-l.Listen(context.Background(), 0, ev, nil)
+
+	waitForCheckpoint(t, ev, 5)
+	chain.setBlockNumber(14)
+	waitForCheckpoint(t, ev, 10)
+
+	// replace the chain after the block 5
+	chain.reorg(6)
+	chain.setBlockNumber(19)
+
+	select {
+	case block := <-ev.rollbackC:
+		if block != 5 {
+			t.Fatalf("got rollback to block %d, want %d", block, 5)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rollback")
+	}
+
+	waitForCheckpoint(t, ev, 15)
+	if from := chain.lastFrom(); from != 6 {
+		t.Fatalf("got logs queried from block %d, want %d", from, 6)
+	}
+
+	block, hash, err := ev.TrackedBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := chain.hash(15); block != 15 || hash != want {
+		t.Fatalf("got tracked block %d %s, want %d %s", block, hash, 15, want)
+	}
+}
+
+func waitForCheckpoint(t *testing.T, ev *checkpointUpdater, block uint64) {
+	t.Helper()
+
+	err := spinlock.Wait(5*time.Second, func() bool { return ev.hasCheckpoint(block) })
+	if err != nil {
+		t.Fatalf("checkpoint at block %d: %v", block, err)
+	}
+}
+
+// reorgFilterer is a chain backend that returns block headers whose hash
+// depends on the fork that the block belongs to.
+type reorgFilterer struct {
+	mu          sync.Mutex
+	blockNumber uint64
+	forks       map[uint64]byte
+	from        uint64
+}
+
+func newReorgFilterer(blockNumber uint64) *reorgFilterer {
+	return &reorgFilterer{
+		blockNumber: blockNumber,
+		forks:       make(map[uint64]byte),
+	}
+}
+
+func (f *reorgFilterer) setBlockNumber(blockNumber uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blockNumber = blockNumber
+}
+
+// reorg replaces the blocks starting from the given one.
+func (f *reorgFilterer) reorg(from uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for block := from; block <= f.blockNumber; block++ {
+		f.forks[block]++
+	}
+}
+
+func (f *reorgFilterer) lastFrom() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.from
+}
+
+func (f *reorgFilterer) hash(block uint64) common.Hash {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.header(block).Hash()
+}
+
+func (f *reorgFilterer) header(block uint64) *types.Header {
+	return &types.Header{
+		Number: new(big.Int).SetUint64(block),
+		Extra:  []byte{f.forks[block]},
+	}
+}
+
+func (f *reorgFilterer) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.from = query.FromBlock.Uint64()
+	return nil, nil
+}
+
+func (f *reorgFilterer) BlockNumber(context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.blockNumber, nil
+}
+
+func (f *reorgFilterer) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.header(number.Uint64()), nil
+}
+
+// checkpointUpdater is an event updater that keeps the checkpoints in memory.
+type checkpointUpdater struct {
+	updater
+
+	mu          sync.Mutex
+	checkpoints []postage.CheckpointInfo
+	tracked     postage.CheckpointInfo
+	rollbackC   chan uint64
+}
+
+func newCheckpointUpdater() *checkpointUpdater {
+	return &checkpointUpdater{
+		updater:   updater{eventC: make(chan interface{}, 1)},
+		rollbackC: make(chan uint64, 1),
+	}
+}
+
+func (u *checkpointUpdater) UpdateBlockNumber(uint64) error { return nil }
+
+func (u *checkpointUpdater) TrackBlock(block uint64, hash common.Hash) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.tracked = postage.CheckpointInfo{Block: block, BlockHash: hash}
+	return nil
+}
+
+func (u *checkpointUpdater) TrackedBlock() (uint64, common.Hash, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.tracked.Block, u.tracked.BlockHash, nil
+}
+
+func (u *checkpointUpdater) Checkpoint(block uint64, hash common.Hash) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.checkpoints = append([]postage.CheckpointInfo{{Block: block, BlockHash: hash}}, u.checkpoints...)
+	return nil
+}
+
+func (u *checkpointUpdater) Checkpoints() ([]postage.CheckpointInfo, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]postage.CheckpointInfo(nil), u.checkpoints...), nil
+}
+
+func (u *checkpointUpdater) Rollback(block uint64) error {
+	u.mu.Lock()
+	for len(u.checkpoints) > 0 && u.checkpoints[0].Block > block {
+		u.checkpoints = u.checkpoints[1:]
+	}
+	u.tracked = u.checkpoints[0]
+	u.mu.Unlock()
+
+	u.rollbackC <- block
+	return nil
+}
+
+func (u *checkpointUpdater) hasCheckpoint(block uint64) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, c := range u.checkpoints {
+		if c.Block == block {
+			return true
+		}
+	}
+	return false
+}
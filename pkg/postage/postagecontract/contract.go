@@ -45,6 +45,8 @@ type Interface interface {
 	CreateBatch(ctx context.Context, initialBalance *big.Int, depth uint8, immutable bool, label string) (common.Hash, []byte, error)
 	TopUpBatch(ctx context.Context, batchID []byte, topupBalance *big.Int) (common.Hash, error)
 	DiluteBatch(ctx context.Context, batchID []byte, newDepth uint8) (common.Hash, error)
+	// Address returns the address of the postage stamp contract.
+	Address() common.Address
 	PostageBatchExpirer
 }
 
@@ -106,6 +108,10 @@ func New(
 	}
 }
 
+func (c *postageContract) Address() common.Address {
+	return c.postageStampContractAddress
+}
+
 func (c *postageContract) ExpireBatches(ctx context.Context) error {
 	for {
 		exists, err := c.expiredBatchesExists(ctx)
@@ -172,6 +178,7 @@ func (c *postageContract) sendApproveTransaction(ctx context.Context, amount *bi
 		GasLimit:    65000,
 		Value:       big.NewInt(0),
 		Description: approveDescription,
+		Priority:    transaction.PriorityPostage,
 	}
 
 	defer func() {
@@ -208,6 +215,7 @@ func (c *postageContract) sendTransaction(ctx context.Context, callData []byte,
 		GasLimit:    max(sctx.GetGasLimit(ctx), c.gasLimit),
 		Value:       big.NewInt(0),
 		Description: desc,
+		Priority:    transaction.PriorityPostage,
 	}
 
 	defer func() {
@@ -514,6 +522,10 @@ func (m *noOpPostageContract) DiluteBatch(context.Context, []byte, uint8) (commo
 	return common.Hash{}, ErrChainDisabled
 }
 
+func (m *noOpPostageContract) Address() common.Address {
+	return common.Address{}
+}
+
 func (m *noOpPostageContract) ExpireBatches(context.Context) error {
 	return ErrChainDisabled
 }
@@ -17,6 +17,7 @@ type contractMock struct {
 	topupBatch    func(ctx context.Context, id []byte, amount *big.Int) (common.Hash, error)
 	diluteBatch   func(ctx context.Context, id []byte, newDepth uint8) (common.Hash, error)
 	expireBatches func(ctx context.Context) error
+	address       common.Address
 }
 
 func (c *contractMock) CreateBatch(ctx context.Context, initialBalance *big.Int, depth uint8, immutable bool, label string) (common.Hash, []byte, error) {
@@ -31,6 +32,10 @@ func (c *contractMock) DiluteBatch(ctx context.Context, batchID []byte, newDepth
 	return c.diluteBatch(ctx, batchID, newDepth)
 }
 
+func (c *contractMock) Address() common.Address {
+	return c.address
+}
+
 func (c *contractMock) ExpireBatches(ctx context.Context) error {
 	return c.expireBatches(ctx)
 }
@@ -72,3 +77,9 @@ func WithExpiresBatchesFunc(f func(ctx context.Context) error) Option {
 		m.expireBatches = f
 	}
 }
+
+func WithAddress(address common.Address) Option {
+	return func(m *contractMock) {
+		m.address = address
+	}
+}
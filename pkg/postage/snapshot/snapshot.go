@@ -0,0 +1,275 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package snapshot creates and verifies the postage snapshots that the nodes
+// bootstrap the batch store from.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/v2/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/v2/pkg/postage/listener"
+	"github.com/ethersphere/bee/v2/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	pageSize = 5000 // how many blocks are filtered at once
+
+	// batchStoreCapacity is the capacity of the batch store that the events
+	// are replayed into. It affects only the radius which is not verified.
+	batchStoreCapacity = 1 << 22
+)
+
+var (
+	ErrInvalidRange  = errors.New("invalid block range")
+	ErrInvalidEvent  = errors.New("event out of the snapshot block range")
+	ErrStateMismatch = errors.New("snapshot state does not match the chain state")
+)
+
+// Backend is the chain backend that the postage stamp contract events are
+// read from.
+type Backend interface {
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Contract is the postage stamp contract whose events are snapshotted.
+type Contract struct {
+	Address common.Address
+	ABI     abi.ABI
+}
+
+func (c Contract) filterQuery(from, to uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{c.Address},
+		Topics: [][]common.Hash{
+			{
+				c.ABI.Events["BatchCreated"].ID,
+				c.ABI.Events["BatchTopUp"].ID,
+				c.ABI.Events["BatchDepthIncrease"].ID,
+				c.ABI.Events["PriceUpdate"].ID,
+			},
+		},
+	}
+}
+
+// Create reads the postage stamp contract events between the blocks from and
+// to. The timestamp of the snapshot is the time of the block to, so that the
+// same snapshot is created every time for the same range.
+func Create(ctx context.Context, backend Backend, contract Contract, from, to uint64) (*postage.ChainSnapshot, error) {
+	if from > to {
+		return nil, fmt.Errorf("%w: %d-%d", ErrInvalidRange, from, to)
+	}
+
+	events, err := filterEvents(ctx, backend, contract, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+	if err != nil {
+		return nil, fmt.Errorf("get header of block %d: %w", to, err)
+	}
+
+	return &postage.ChainSnapshot{
+		Events:           events,
+		FirstBlockNumber: from,
+		LastBlockNumber:  to,
+		Timestamp:        int64(header.Time),
+	}, nil
+}
+
+func filterEvents(ctx context.Context, backend Backend, contract Contract, from, to uint64) ([]types.Log, error) {
+	events := make([]types.Log, 0)
+	for start := from; start <= to; start += pageSize {
+		end := min(start+pageSize-1, to)
+		logs, err := backend.FilterLogs(ctx, contract.filterQuery(start, end))
+		if err != nil {
+			return nil, fmt.Errorf("filter logs in blocks %d-%d: %w", start, end, err)
+		}
+		events = append(events, logs...)
+	}
+	return events, nil
+}
+
+// Write writes the gzip compressed snapshot and returns its reference.
+func Write(w io.Writer, s *postage.ChainSnapshot) (swarm.Address, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(data); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if err := zw.Close(); err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	return Reference(data)
+}
+
+// Read reads the snapshot written by Write or the uncompressed snapshot as
+// it is downloaded from swarm and returns it with its reference.
+func Read(r io.Reader) (*postage.ChainSnapshot, swarm.Address, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, swarm.ZeroAddress, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	var src io.Reader = br
+	if header[0] == 0x1f && header[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, swarm.ZeroAddress, err
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, swarm.ZeroAddress, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	s := new(postage.ChainSnapshot)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, swarm.ZeroAddress, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	ref, err := Reference(data)
+	if err != nil {
+		return nil, swarm.ZeroAddress, err
+	}
+	return s, ref, nil
+}
+
+// Reference returns the swarm address of the uncompressed snapshot. It is
+// the reference that the snapshot feed points to when the snapshot is
+// uploaded for the nodes to bootstrap from.
+func Reference(data []byte) (swarm.Address, error) {
+	ctx := context.Background()
+	discard := storage.PutterFunc(func(context.Context, swarm.Chunk) error { return nil })
+	pipe := builder.NewPipelineBuilder(ctx, discard, false, redundancy.NONE)
+	return builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+}
+
+// State replays the events of the snapshot into an empty batch store in the
+// same way as a bootstrapping node and returns the digest of its content.
+func State(s *postage.ChainSnapshot, contract Contract) (common.Hash, error) {
+	for _, e := range s.Events {
+		if e.BlockNumber < s.FirstBlockNumber || e.BlockNumber > s.LastBlockNumber {
+			return common.Hash{}, fmt.Errorf("%w: block %d", ErrInvalidEvent, e.BlockNumber)
+		}
+	}
+
+	stateStore, err := leveldb.NewInMemoryStateStore(log.Noop)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer stateStore.Close()
+
+	store, err := batchstore.New(stateStore, func([]byte) error { return nil }, batchStoreCapacity, log.Noop)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	svc, err := batchservice.New(stateStore, store, log.Noop, nil, nil, nil, nil, false, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := listener.Replay(log.Noop, contract.ABI, s.Events, s.LastBlockNumber+1, svc); err != nil {
+		return common.Hash{}, fmt.Errorf("replay events: %w", err)
+	}
+
+	return digest(store)
+}
+
+// digest hashes the chain state and the batches of the store.
+func digest(store postage.Storer) (common.Hash, error) {
+	var batches [][]byte
+	err := store.Iterate(func(b *postage.Batch) (bool, error) {
+		data, err := b.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		batches = append(batches, data)
+		return false, nil
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// the batch id is at the start of the encoded batch
+	sort.Slice(batches, func(i, j int) bool { return bytes.Compare(batches[i], batches[j]) < 0 })
+
+	cs := store.GetChainState()
+	data := [][]byte{
+		new(big.Int).SetUint64(cs.Block).Bytes(),
+		cs.TotalAmount.Bytes(),
+		cs.CurrentPrice.Bytes(),
+	}
+	sum, err := crypto.LegacyKeccak256(bytes.Join(append(data, batches...), nil))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(sum), nil
+}
+
+// Verify checks that the snapshot yields the same batch store state as the
+// postage stamp contract events on the chain in the block range of the
+// snapshot.
+func Verify(ctx context.Context, backend Backend, contract Contract, s *postage.ChainSnapshot) error {
+	if s.FirstBlockNumber > s.LastBlockNumber {
+		return fmt.Errorf("%w: %d-%d", ErrInvalidRange, s.FirstBlockNumber, s.LastBlockNumber)
+	}
+
+	want, err := State(s, contract)
+	if err != nil {
+		return fmt.Errorf("snapshot state: %w", err)
+	}
+
+	events, err := filterEvents(ctx, backend, contract, s.FirstBlockNumber, s.LastBlockNumber)
+	if err != nil {
+		return err
+	}
+	got, err := State(&postage.ChainSnapshot{
+		Events:           events,
+		FirstBlockNumber: s.FirstBlockNumber,
+		LastBlockNumber:  s.LastBlockNumber,
+	}, contract)
+	if err != nil {
+		return fmt.Errorf("chain state: %w", err)
+	}
+
+	if got != want {
+		return fmt.Errorf("%w: %d snapshot events with state %s, %d chain events with state %s", ErrStateMismatch, len(s.Events), want, len(events), got)
+	}
+	return nil
+}
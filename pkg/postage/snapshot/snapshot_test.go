@@ -0,0 +1,192 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/postage/snapshot"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
+)
+
+var contract = snapshot.Contract{
+	Address: common.HexToAddress("eeee"),
+	ABI:     abiutil.MustParseABI(chaincfg.Testnet.PostageStampABI),
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	events := testEvents(t)
+	var queries int
+	backend := newTestBackend(events, &queries)
+
+	s, err := snapshot.Create(context.Background(), backend, contract, 0, 12000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if queries != 3 {
+		t.Fatalf("got %d log queries, want %d", queries, 3)
+	}
+	if len(s.Events) != len(events) {
+		t.Fatalf("got %d events, want %d", len(s.Events), len(events))
+	}
+	if s.FirstBlockNumber != 0 || s.LastBlockNumber != 12000 {
+		t.Fatalf("got block range %d-%d, want %d-%d", s.FirstBlockNumber, s.LastBlockNumber, 0, 12000)
+	}
+	if s.Timestamp != 12000*5 {
+		t.Fatalf("got timestamp %d, want the time of the last block %d", s.Timestamp, 12000*5)
+	}
+
+	if _, err := snapshot.Create(context.Background(), backend, contract, 10, 5); !errors.Is(err, snapshot.ErrInvalidRange) {
+		t.Fatalf("got error %v, want %v", err, snapshot.ErrInvalidRange)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	t.Parallel()
+
+	s, err := snapshot.Create(context.Background(), newTestBackend(testEvents(t), nil), contract, 0, 12000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	ref, err := snapshot.Write(&buf, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, gotRef, err := snapshot.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotRef.Equal(ref) {
+		t.Fatalf("got reference %s, want %s", gotRef, ref)
+	}
+	if len(got.Events) != len(s.Events) {
+		t.Fatalf("got %d events, want %d", len(got.Events), len(s.Events))
+	}
+
+	// the snapshot as it is downloaded from swarm is not compressed
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rawRef, err := snapshot.Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rawRef.Equal(ref) {
+		t.Fatalf("got reference %s of the uncompressed snapshot, want %s", rawRef, ref)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	events := testEvents(t)
+	s, err := snapshot.Create(context.Background(), newTestBackend(events, nil), contract, 0, 12000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("same state", func(t *testing.T) {
+		t.Parallel()
+
+		if err := snapshot.Verify(context.Background(), newTestBackend(events, nil), contract, s); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("different state", func(t *testing.T) {
+		t.Parallel()
+
+		chain := append(events[:len(events):len(events)], priceLog(t, 11000, 3))
+		err := snapshot.Verify(context.Background(), newTestBackend(chain, nil), contract, s)
+		if !errors.Is(err, snapshot.ErrStateMismatch) {
+			t.Fatalf("got error %v, want %v", err, snapshot.ErrStateMismatch)
+		}
+	})
+
+	t.Run("event out of range", func(t *testing.T) {
+		t.Parallel()
+
+		invalid := *s
+		invalid.Events = append(s.Events[:len(s.Events):len(s.Events)], priceLog(t, 13000, 3))
+		err := snapshot.Verify(context.Background(), newTestBackend(events, nil), contract, &invalid)
+		if !errors.Is(err, snapshot.ErrInvalidEvent) {
+			t.Fatalf("got error %v, want %v", err, snapshot.ErrInvalidEvent)
+		}
+	})
+}
+
+// newTestBackend returns a backend with the events where the time of a block
+// is five times its number.
+func newTestBackend(events []types.Log, queries *int) snapshot.Backend {
+	return backendmock.New(
+		backendmock.WithFilterLogsFunc(func(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+			if queries != nil {
+				*queries++
+			}
+			var logs []types.Log
+			for _, e := range events {
+				if e.BlockNumber >= query.FromBlock.Uint64() && e.BlockNumber <= query.ToBlock.Uint64() {
+					logs = append(logs, e)
+				}
+			}
+			return logs, nil
+		}),
+		backendmock.WithHeaderbyNumberFunc(func(_ context.Context, number *big.Int) (*types.Header, error) {
+			return &types.Header{Number: number, Time: number.Uint64() * 5}, nil
+		}),
+	)
+}
+
+func testEvents(t *testing.T) []types.Log {
+	t.Helper()
+
+	batchID := common.HexToHash("ba7c")
+	return []types.Log{
+		priceLog(t, 10, 1),
+		eventLog(t, "BatchCreated", 12, 1, []common.Hash{batchID},
+			big.NewInt(1_000_000), big.NewInt(1_000_000), common.HexToAddress("0abc"), uint8(16), uint8(20), false),
+		eventLog(t, "BatchTopUp", 6000, 2, []common.Hash{batchID}, big.NewInt(1000), big.NewInt(1_001_000)),
+		eventLog(t, "BatchDepthIncrease", 10500, 3, []common.Hash{batchID}, uint8(21), big.NewInt(500_500)),
+	}
+}
+
+func priceLog(t *testing.T, block uint64, price int64) types.Log {
+	t.Helper()
+	return eventLog(t, "PriceUpdate", block, byte(price), nil, big.NewInt(price))
+}
+
+func eventLog(t *testing.T, name string, block uint64, tx byte, topics []common.Hash, args ...interface{}) types.Log {
+	t.Helper()
+
+	event := contract.ABI.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{
+		Address:     contract.Address,
+		Topics:      append([]common.Hash{event.ID}, topics...),
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.Hash{byte(block), tx},
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "pricer"
+
+// announceTimeout bounds the announcement of a new price to the peers.
+const announceTimeout = 30 * time.Second
+
+var _ Interface = (*DynamicPricer)(nil)
+
+// ErrPriceOutOfRange is returned for a peer price outside of the min and max prices.
+var ErrPriceOutOfRange = errors.New("peer price out of range")
+
+// LoadFunc returns the utilization of a local resource, where zero is idle
+// and one is the full capacity. Overloaded resources report values above one.
+type LoadFunc func() float64
+
+// PriceAnnouncer announces the price per proximity order to the peers.
+type PriceAnnouncer interface {
+	AnnouncePrice(ctx context.Context, poPrice uint64) error
+}
+
+// DynamicOptions are the parameters of the price adjustments. The prices
+// are per proximity order, like the price of the FixedPricer.
+type DynamicOptions struct {
+	BasePrice   uint64        // price at the target load and the assumed price of the peers that do not announce one
+	MinPrice    uint64        // lower bound of the price
+	MaxPrice    uint64        // upper bound of the price
+	MaxChange   float64       // maximal relative change of the price per adjustment
+	TargetLoad  float64       // load at which the base price is charged
+	Sensitivity float64       // relative change of the price per unit of load above or below the target
+	Interval    time.Duration // time between the adjustments
+}
+
+// DefaultDynamicOptions returns the options for the given base price.
+func DefaultDynamicOptions(basePrice uint64) DynamicOptions {
+	return DynamicOptions{
+		BasePrice:   basePrice,
+		MinPrice:    basePrice,
+		MaxPrice:    basePrice * 4,
+		MaxChange:   0.1,
+		TargetLoad:  0.5,
+		Sensitivity: 2,
+		Interval:    time.Minute,
+	}
+}
+
+// Validate checks the consistency of the options.
+func (o DynamicOptions) Validate() error {
+	switch {
+	case o.BasePrice == 0:
+		return errors.New("base price must be positive")
+	case o.MinPrice == 0 || o.MinPrice > o.BasePrice:
+		return errors.New("min price must be positive and at most the base price")
+	case o.MaxPrice < o.BasePrice:
+		return errors.New("max price must be at least the base price")
+	case o.MaxPrice > math.MaxUint64/(uint64(swarm.MaxPO)+1):
+		return errors.New("max price too large")
+	case o.MaxChange <= 0 || o.MaxChange >= 1:
+		return errors.New("max price change must be between 0 and 1")
+	case o.TargetLoad <= 0:
+		return errors.New("target load must be positive")
+	case o.Sensitivity < 0:
+		return errors.New("sensitivity must not be negative")
+	case o.Interval <= 0:
+		return errors.New("adjustment interval must be positive")
+	}
+	return nil
+}
+
+// DynamicPricer is a Pricer that adjusts its price to the local load and
+// keeps the prices announced by the peers. The price moves towards the
+// target price of the current load, by at most the maximal change per
+// adjustment and within the min and max prices.
+//
+// A price is charged to a peer only after the peer acknowledged its
+// announcement, while a decrease is charged to all the peers at once, so
+// that the peers never credit less than what is debited from them. The peers
+// that have not announced a price of their own may not follow the
+// announcements, so they are charged the base price.
+//
+// The accounting thresholds are not adjusted to the price.
+type DynamicPricer struct {
+	logger    log.Logger
+	overlay   swarm.Address
+	announcer PriceAnnouncer
+	opts      DynamicOptions
+	metrics   metrics
+	loads     []LoadFunc
+
+	mu         sync.RWMutex
+	poPrice    uint64
+	peerPrices map[string]uint64
+	// announced holds the prices acknowledged by the peers, which are
+	// charged to the peers that announce prices of their own.
+	announced map[string]uint64
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDynamicPricer returns a new DynamicPricer charging the base price.
+// The adjustments start with Start.
+func NewDynamicPricer(logger log.Logger, overlay swarm.Address, announcer PriceAnnouncer, o DynamicOptions) *DynamicPricer {
+	p := &DynamicPricer{
+		logger:     logger.WithName(loggerName).Register(),
+		overlay:    overlay,
+		announcer:  announcer,
+		opts:       o,
+		metrics:    newMetrics(),
+		poPrice:    o.BasePrice,
+		peerPrices: make(map[string]uint64),
+		announced:  make(map[string]uint64),
+		quit:       make(chan struct{}),
+	}
+	p.metrics.PoPrice.Set(float64(o.BasePrice))
+	return p
+}
+
+// Start announces the base price and adjusts the price to the highest of
+// the loads periodically.
+func (p *DynamicPricer) Start(loads ...LoadFunc) {
+	p.loads = loads
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-p.quit
+			cancel()
+		}()
+
+		p.announce(ctx, p.PoPrice())
+
+		ticker := time.NewTicker(p.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.quit:
+				return
+			case <-ticker.C:
+				p.adjust(ctx, p.load())
+			}
+		}
+	}()
+}
+
+// PoPrice returns the current price per proximity order.
+func (p *DynamicPricer) PoPrice() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.poPrice
+}
+
+// PeerPrice implements Pricer.
+func (p *DynamicPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
+	p.mu.RLock()
+	poPrice, ok := p.peerPrices[peer.ByteString()]
+	p.mu.RUnlock()
+	if !ok {
+		poPrice = p.opts.BasePrice
+	}
+	return chunkPrice(peer, chunk, poPrice)
+}
+
+// Price implements Pricer.
+func (p *DynamicPricer) Price(peer, chunk swarm.Address) uint64 {
+	poPrice := p.opts.BasePrice
+	p.mu.RLock()
+	if _, ok := p.peerPrices[peer.ByteString()]; ok {
+		if announced, ok := p.announced[peer.ByteString()]; ok {
+			poPrice = announced
+		}
+	}
+	p.mu.RUnlock()
+	return chunkPrice(p.overlay, chunk, poPrice)
+}
+
+// NotifyAnnouncedPrice records that the peer acknowledged the announced
+// price, which is charged to the peer from then on. The price is capped
+// at the current price, so that a late acknowledgement of a previous
+// price does not undo a decrease.
+func (p *DynamicPricer) NotifyAnnouncedPrice(peer swarm.Address, poPrice uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.announced[peer.ByteString()] = min(poPrice, p.poPrice)
+}
+
+// NotifyPeerPrice keeps the price announced by the peer. A zero price
+// reverts the peer to the base price. ErrPriceOutOfRange is returned for
+// a price outside of the min and max prices, which is not kept.
+func (p *DynamicPricer) NotifyPeerPrice(peer swarm.Address, poPrice uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if poPrice == 0 {
+		delete(p.peerPrices, peer.ByteString())
+		delete(p.announced, peer.ByteString())
+		return nil
+	}
+	if poPrice < p.opts.MinPrice || poPrice > p.opts.MaxPrice {
+		return ErrPriceOutOfRange
+	}
+	p.peerPrices[peer.ByteString()] = poPrice
+	return nil
+}
+
+func (p *DynamicPricer) Close() error {
+	close(p.quit)
+	p.wg.Wait()
+	return nil
+}
+
+// load returns the highest of the loads, as the most utilized resource
+// limits the node.
+func (p *DynamicPricer) load() float64 {
+	var load float64
+	for _, f := range p.loads {
+		load = math.Max(load, f())
+	}
+	p.metrics.Load.Set(load)
+	return load
+}
+
+// nextPrice returns the price after an adjustment to the load. The price
+// moves halfway towards the target price of the load, which damps the
+// oscillations of a demand that follows the price, and by at most the max
+// change.
+func (p *DynamicPricer) nextPrice(current uint64, load float64) uint64 {
+	o := p.opts
+
+	target := float64(o.BasePrice) * (1 + o.Sensitivity*(load-o.TargetLoad))
+	target = math.Max(float64(o.MinPrice), math.Min(float64(o.MaxPrice), target))
+
+	delta := (target - float64(current)) / 2
+	if math.Abs(delta) < 1 {
+		delta = target - float64(current)
+	}
+	step := math.Max(1, math.Floor(float64(current)*o.MaxChange))
+	delta = math.Max(-step, math.Min(step, delta))
+
+	return uint64(math.Round(float64(current) + delta))
+}
+
+// adjust moves the price towards the target price of the load.
+func (p *DynamicPricer) adjust(ctx context.Context, load float64) uint64 {
+	current := p.PoPrice()
+	next := p.nextPrice(current, load)
+
+	if next == current {
+		return current
+	}
+	p.setPrice(next)
+	p.announce(ctx, next)
+
+	p.logger.Debug("price adjusted", "load", load, "old_po_price", current, "new_po_price", next)
+	p.metrics.Adjustments.Inc()
+	return next
+}
+
+// setPrice sets the price that is announced. A decrease is charged to all
+// the peers at once, an increase only after the peers acknowledged it.
+func (p *DynamicPricer) setPrice(poPrice uint64) {
+	p.mu.Lock()
+	p.poPrice = poPrice
+	for peer, announced := range p.announced {
+		if announced > poPrice {
+			p.announced[peer] = poPrice
+		}
+	}
+	p.mu.Unlock()
+	p.metrics.PoPrice.Set(float64(poPrice))
+}
+
+func (p *DynamicPricer) announce(ctx context.Context, poPrice uint64) {
+	ctx, cancel := context.WithTimeout(ctx, announceTimeout)
+	defer cancel()
+
+	if err := p.announcer.AnnouncePrice(ctx, poPrice); err != nil {
+		p.logger.Debug("price announcement failed", "po_price", poPrice, "error", err)
+	}
+}
+
+func (p *DynamicPricer) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(p.metrics)
+}
+
+// QueueLoad returns the load of a queue of the given capacity.
+func QueueLoad(depth func() int64, capacity int64) LoadFunc {
+	return func() float64 {
+		if capacity <= 0 {
+			return 0
+		}
+		return float64(depth()) / float64(capacity)
+	}
+}
+
+// BandwidthLoad returns the load of the upload bandwidth, measured as the
+// upload rate since the previous call relative to the upload limit of the
+// reporter. The capacity in bytes per second is used when the upload is not
+// limited, the load is zero when neither is set.
+func BandwidthLoad(reporter p2p.TrafficReporter, capacity int64) LoadFunc {
+	var (
+		last     uint64
+		lastTime time.Time
+	)
+	return func() float64 {
+		usage := reporter.TrafficUsage()
+		var uploaded uint64
+		for _, p := range usage.Protocols {
+			uploaded += p.Uploaded
+		}
+		now := time.Now()
+		defer func() { last, lastTime = uploaded, now }()
+
+		limit := usage.UploadRate
+		if limit <= 0 {
+			limit = capacity
+		}
+		if limit <= 0 || lastTime.IsZero() || uploaded < last {
+			return 0
+		}
+		elapsed := now.Sub(lastTime).Seconds()
+		if elapsed <= 0 {
+			return 0
+		}
+		return float64(uploaded-last) / elapsed / float64(limit)
+	}
+}
+
+func chunkPrice(peer, chunk swarm.Address, poPrice uint64) uint64 {
+	return uint64(swarm.MaxPO-swarm.Proximity(peer.Bytes(), chunk.Bytes())+1) * poPrice
+}
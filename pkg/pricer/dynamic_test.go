@@ -0,0 +1,275 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/pricer"
+	"github.com/ethersphere/bee/v2/pkg/spinlock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+var (
+	overlay = swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	peer    = swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")
+	chunk   = swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000")
+)
+
+func testOptions() pricer.DynamicOptions {
+	o := pricer.DefaultDynamicOptions(100)
+	o.Interval = time.Hour
+	return o
+}
+
+// recordingAnnouncer records the announced prices.
+type recordingAnnouncer struct {
+	mu     sync.Mutex
+	prices []uint64
+}
+
+func (a *recordingAnnouncer) AnnouncePrice(_ context.Context, poPrice uint64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prices = append(a.prices, poPrice)
+	return nil
+}
+
+func (a *recordingAnnouncer) announced() []uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]uint64(nil), a.prices...)
+}
+
+func TestDynamicPricerGuardrails(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	announcer := new(recordingAnnouncer)
+	p := pricer.NewDynamicPricer(log.Noop, overlay, announcer, o)
+	testutil.CleanupCloser(t, p)
+
+	// the price rises by at most the max change until it reaches the max price
+	price := p.PoPrice()
+	for i := 0; i < 30; i++ {
+		next := p.Adjust(context.Background(), 5)
+		if next < price || float64(next-price) > float64(price)*o.MaxChange {
+			t.Fatalf("price changed from %d to %d above the max change", price, next)
+		}
+		if next > o.MaxPrice {
+			t.Fatalf("price %d above the max price %d", next, o.MaxPrice)
+		}
+		price = next
+	}
+	if price != o.MaxPrice {
+		t.Fatalf("got price %d, want the max price %d", price, o.MaxPrice)
+	}
+
+	// the price falls to the min price when idle
+	for i := 0; i < 30; i++ {
+		next := p.Adjust(context.Background(), 0)
+		if next > price || float64(price-next) > float64(price)*o.MaxChange {
+			t.Fatalf("price changed from %d to %d above the max change", price, next)
+		}
+		price = next
+	}
+	if price != o.MinPrice {
+		t.Fatalf("got price %d, want the min price %d", price, o.MinPrice)
+	}
+
+	// the price settles at the target price of a steady load
+	for i := 0; i < 30; i++ {
+		price = p.Adjust(context.Background(), 1)
+	}
+	if price != 200 {
+		t.Fatalf("got price %d, want %d", price, 200)
+	}
+
+	// only the changes are announced
+	announced := announcer.announced()
+	if last := announced[len(announced)-1]; last != price {
+		t.Fatalf("got last announced price %d, want %d", last, price)
+	}
+	if n := len(announced); n >= 90 {
+		t.Fatalf("got %d announcements for the unchanged prices", n)
+	}
+}
+
+func TestDynamicPricerPeerPrice(t *testing.T) {
+	t.Parallel()
+
+	p := pricer.NewDynamicPricer(log.Noop, overlay, new(recordingAnnouncer), testOptions())
+	testutil.CleanupCloser(t, p)
+
+	// the chunk is at proximity 1 from the overlay and 0 from the peer
+	if got, want := p.Price(peer, chunk), uint64(swarm.MaxPO)*100; got != want {
+		t.Fatalf("got price %d, want %d", got, want)
+	}
+	if got, want := p.PeerPrice(peer, chunk), uint64(swarm.MaxPO+1)*100; got != want {
+		t.Fatalf("got peer price %d of a peer without an announcement, want %d", got, want)
+	}
+
+	if err := p.NotifyPeerPrice(peer, 150); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.PeerPrice(peer, chunk), uint64(swarm.MaxPO+1)*150; got != want {
+		t.Fatalf("got peer price %d, want %d", got, want)
+	}
+
+	for _, price := range []uint64{99, 401, ^uint64(0)} {
+		if err := p.NotifyPeerPrice(peer, price); !errors.Is(err, pricer.ErrPriceOutOfRange) {
+			t.Fatalf("got error %v for price %d, want %v", err, price, pricer.ErrPriceOutOfRange)
+		}
+	}
+	if got, want := p.PeerPrice(peer, chunk), uint64(swarm.MaxPO+1)*150; got != want {
+		t.Fatalf("got peer price %d after the rejected announcements, want %d", got, want)
+	}
+
+	if err := p.NotifyPeerPrice(peer, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.PeerPrice(peer, chunk), uint64(swarm.MaxPO+1)*100; got != want {
+		t.Fatalf("got peer price %d after the peer was forgotten, want %d", got, want)
+	}
+}
+
+// TestDynamicPricerPriceForPeers verifies that a raised price is charged
+// only to the peers that announce prices of their own and acknowledged the
+// raised price.
+func TestDynamicPricerPriceForPeers(t *testing.T) {
+	t.Parallel()
+
+	p := pricer.NewDynamicPricer(log.Noop, overlay, new(recordingAnnouncer), testOptions())
+	testutil.CleanupCloser(t, p)
+
+	if err := p.NotifyPeerPrice(peer, 100); err != nil {
+		t.Fatal(err)
+	}
+	price := p.Adjust(context.Background(), 1)
+	if price <= 100 {
+		t.Fatalf("got price %d, want a raised price", price)
+	}
+
+	if got, want := p.Price(peer, chunk), uint64(swarm.MaxPO)*100; got != want {
+		t.Fatalf("got price %d before the acknowledgement, want %d", got, want)
+	}
+	p.NotifyAnnouncedPrice(peer, price)
+	if got, want := p.Price(peer, chunk), uint64(swarm.MaxPO)*price; got != want {
+		t.Fatalf("got price %d for the announcing peer, want %d", got, want)
+	}
+	legacy := swarm.RandAddress(t)
+	p.NotifyAnnouncedPrice(legacy, price)
+	if got, want := p.Price(legacy, chunk), uint64(swarm.MaxPO)*100; got != want {
+		t.Fatalf("got price %d for the peer without an announcement, want %d", got, want)
+	}
+
+	// a decrease is charged before it is acknowledged
+	lowered := p.Adjust(context.Background(), 0)
+	if lowered >= price {
+		t.Fatalf("got price %d, want a lowered price", lowered)
+	}
+	if got, want := p.Price(peer, chunk), uint64(swarm.MaxPO)*lowered; got != want {
+		t.Fatalf("got price %d after the decrease, want %d", got, want)
+	}
+
+	// a late acknowledgement does not undo the decrease
+	p.NotifyAnnouncedPrice(peer, price)
+	if got, want := p.Price(peer, chunk), uint64(swarm.MaxPO)*lowered; got != want {
+		t.Fatalf("got price %d after a late acknowledgement, want %d", got, want)
+	}
+}
+
+func TestDynamicPricerStart(t *testing.T) {
+	t.Parallel()
+
+	o := testOptions()
+	o.Interval = 10 * time.Millisecond
+	announcer := new(recordingAnnouncer)
+	p := pricer.NewDynamicPricer(log.Noop, overlay, announcer, o)
+	testutil.CleanupCloser(t, p)
+
+	p.Start(pricer.QueueLoad(func() int64 { return 100 }, 100))
+
+	err := spinlock.Wait(time.Second, func() bool {
+		return p.PoPrice() > o.BasePrice
+	})
+	if err != nil {
+		t.Fatal("price did not rise with the full queue")
+	}
+	if announced := announcer.announced(); announced[0] != o.BasePrice {
+		t.Fatalf("got first announced price %d, want the base price %d", announced[0], o.BasePrice)
+	}
+}
+
+func TestDynamicOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := testOptions().Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*pricer.DynamicOptions)
+	}{
+		{"zero base price", func(o *pricer.DynamicOptions) { o.BasePrice = 0 }},
+		{"min price above base price", func(o *pricer.DynamicOptions) { o.MinPrice = o.BasePrice + 1 }},
+		{"max price below base price", func(o *pricer.DynamicOptions) { o.MaxPrice = o.BasePrice - 1 }},
+		{"max change too high", func(o *pricer.DynamicOptions) { o.MaxChange = 1 }},
+		{"zero interval", func(o *pricer.DynamicOptions) { o.Interval = 0 }},
+	} {
+		o := testOptions()
+		tc.modify(&o)
+		if err := o.Validate(); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestBandwidthLoad(t *testing.T) {
+	t.Parallel()
+
+	reporter := &testTrafficReporter{usage: p2p.TrafficUsage{UploadRate: 1000}}
+	load := pricer.BandwidthLoad(reporter, 0)
+
+	if l := load(); l != 0 {
+		t.Fatalf("got load %v of the first measurement, want 0", l)
+	}
+	time.Sleep(50 * time.Millisecond)
+	reporter.setUploaded(1_000_000)
+	if l := load(); l <= 1 {
+		t.Fatalf("got load %v, want an overloaded upload", l)
+	}
+
+	unlimited := pricer.BandwidthLoad(&testTrafficReporter{}, 0)
+	_ = unlimited()
+	if l := unlimited(); l != 0 {
+		t.Fatalf("got load %v of an unlimited upload, want 0", l)
+	}
+}
+
+type testTrafficReporter struct {
+	mu    sync.Mutex
+	usage p2p.TrafficUsage
+}
+
+func (r *testTrafficReporter) setUploaded(n uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usage.Protocols = []p2p.ProtocolTrafficUsage{{Protocol: "retrieval", Uploaded: n}}
+}
+
+func (r *testTrafficReporter) TrafficUsage() p2p.TrafficUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.usage
+}
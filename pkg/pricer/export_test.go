@@ -0,0 +1,11 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer
+
+import "context"
+
+func (p *DynamicPricer) Adjust(ctx context.Context, load float64) uint64 {
+	return p.adjust(ctx, load)
+}
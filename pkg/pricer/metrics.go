@@ -0,0 +1,41 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	PoPrice     prometheus.Gauge
+	Load        prometheus.Gauge
+	Adjustments prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "pricer"
+
+	return metrics{
+		PoPrice: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "po_price",
+			Help:      "Current price per proximity order charged by the node.",
+		}),
+		Load: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "load",
+			Help:      "Highest utilization of the local resources at the last adjustment.",
+		}),
+		Adjustments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "adjustments",
+			Help:      "Number of the price changes.",
+		}),
+	}
+}
@@ -12,8 +12,8 @@ import (
 type Interface interface {
 	// PeerPrice is the price the peer charges for a given chunk hash.
 	PeerPrice(peer, chunk swarm.Address) uint64
-	// Price is the price we charge for a given chunk hash.
-	Price(chunk swarm.Address) uint64
+	// Price is the price we charge the peer for a given chunk hash.
+	Price(peer, chunk swarm.Address) uint64
 }
 
 // FixedPricer is a Pricer that has a fixed price for chunks.
@@ -32,10 +32,10 @@ func NewFixedPricer(overlay swarm.Address, poPrice uint64) *FixedPricer {
 
 // PeerPrice implements Pricer.
 func (pricer *FixedPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
-	return uint64(swarm.MaxPO-swarm.Proximity(peer.Bytes(), chunk.Bytes())+1) * pricer.poPrice
+	return chunkPrice(peer, chunk, pricer.poPrice)
 }
 
 // Price implements Pricer.
-func (pricer *FixedPricer) Price(chunk swarm.Address) uint64 {
+func (pricer *FixedPricer) Price(_, chunk swarm.Address) uint64 {
 	return pricer.PeerPrice(pricer.overlay, chunk)
 }
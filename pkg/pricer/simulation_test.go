@@ -0,0 +1,178 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pricer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/pricer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+// simulation is a harness of a server node with a dynamic price and the
+// clients that retrieve chunks from it. The clients learn the price of the
+// server from its announcements, and the retrievals may run concurrently
+// with an announcement. The demand of the clients drops as the price rises.
+type simulation struct {
+	t       *testing.T
+	opts    pricer.DynamicOptions
+	server  *pricer.DynamicPricer
+	clients []*pricer.DynamicPricer
+	peers   []swarm.Address // overlays of the clients
+
+	capacity  float64 // retrievals per step at full load
+	demand    float64 // retrievals per step at the base price
+	credited  uint64  // total credited by the clients
+	debited   uint64  // total debited by the server
+	undercuts int     // retrievals where a client credited less than was debited
+}
+
+func newSimulation(t *testing.T, o pricer.DynamicOptions, clients int) *simulation {
+	t.Helper()
+
+	s := &simulation{t: t, opts: o, capacity: 100}
+	s.server = pricer.NewDynamicPricer(log.Noop, overlay, s, o)
+	testutil.CleanupCloser(t, s.server)
+	for i := 0; i < clients; i++ {
+		address := swarm.RandAddress(t)
+		c := pricer.NewDynamicPricer(log.Noop, address, new(recordingAnnouncer), o)
+		testutil.CleanupCloser(t, c)
+		s.clients = append(s.clients, c)
+		s.peers = append(s.peers, address)
+		// the clients announce their own prices, so the server charges them its price
+		if err := s.server.NotifyPeerPrice(address, o.BasePrice); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// AnnouncePrice delivers the price to the clients one by one, with a round
+// of retrievals between the deliveries, and acknowledges the deliveries to
+// the server.
+func (s *simulation) AnnouncePrice(_ context.Context, poPrice uint64) error {
+	for i, c := range s.clients {
+		s.retrieve()
+		if err := c.NotifyPeerPrice(overlay, poPrice); err != nil {
+			s.t.Fatal(err)
+		}
+		s.server.NotifyAnnouncedPrice(s.peers[i], poPrice)
+	}
+	return nil
+}
+
+// retrieve makes every client retrieve a chunk from the server.
+func (s *simulation) retrieve() {
+	for i, c := range s.clients {
+		credit := c.PeerPrice(overlay, chunk)
+		debit := s.server.Price(s.peers[i], chunk)
+		if credit < debit {
+			s.undercuts++
+		}
+		s.credited += credit
+		s.debited += debit
+	}
+}
+
+// load returns the load of the server caused by the demand at the current
+// price.
+func (s *simulation) load() float64 {
+	return s.demand * float64(s.opts.BasePrice) / float64(s.server.PoPrice()) / s.capacity
+}
+
+// run adjusts the price of the server to the demand for the number of steps
+// and checks the guardrails of every step.
+func (s *simulation) run(demand float64, steps int) []uint64 {
+	s.t.Helper()
+
+	s.demand = demand
+	prices := make([]uint64, 0, steps)
+	for i := 0; i < steps; i++ {
+		s.retrieve()
+		before := s.server.PoPrice()
+		price := s.server.Adjust(context.Background(), s.load())
+
+		if price < s.opts.MinPrice || price > s.opts.MaxPrice {
+			s.t.Fatalf("demand %v step %d: price %d out of the range [%d, %d]", demand, i, price, s.opts.MinPrice, s.opts.MaxPrice)
+		}
+		if diff := absDiff(price, before); diff > 1 && float64(diff) > float64(before)*s.opts.MaxChange {
+			s.t.Fatalf("demand %v step %d: price changed from %d to %d", demand, i, before, price)
+		}
+		for j, c := range s.clients {
+			if got, want := c.PeerPrice(overlay, chunk), s.server.Price(s.peers[j], chunk); got != want {
+				s.t.Fatalf("demand %v step %d: client price %d differs from the server price %d after the announcement", demand, i, got, want)
+			}
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+func TestSimulation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		maxChange  float64
+		phases     []float64 // demand of the consecutive phases
+		wantSettle uint64    // price settled at in the last phase
+	}{
+		{
+			name:       "spike",
+			maxChange:  0.1,
+			phases:     []float64{20, 300, 80},
+			wantSettle: 126, // the fixed point of p = 100 * (1 + 2 * (80 * 100 / p / 100 - 0.5))
+		},
+		{
+			name:       "slow",
+			maxChange:  0.02,
+			phases:     []float64{50, 1000, 10},
+			wantSettle: 100,
+		},
+		{
+			name:       "steady",
+			maxChange:  0.1,
+			phases:     []float64{50, 50, 50},
+			wantSettle: 100,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := testOptions()
+			o.MaxChange = tc.maxChange
+			s := newSimulation(t, o, 5)
+
+			var prices []uint64
+			for _, demand := range tc.phases {
+				prices = s.run(demand, 200)
+			}
+
+			last := prices[len(prices)-20:]
+			for _, p := range last {
+				if absDiff(p, tc.wantSettle) > 1 {
+					t.Fatalf("prices %v did not settle at %d", last, tc.wantSettle)
+				}
+			}
+			if s.undercuts > 0 {
+				t.Fatalf("clients credited less than was debited in %d retrievals", s.undercuts)
+			}
+			if s.credited < s.debited {
+				t.Fatalf("credited %d less than debited %d", s.credited, s.debited)
+			}
+			t.Logf("credited %d, debited %d", s.credited, s.debited)
+		})
+	}
+}
+
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
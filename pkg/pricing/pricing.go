@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/log"
@@ -44,6 +45,15 @@ type PaymentThresholdObserver interface {
 	NotifyPaymentThreshold(peer swarm.Address, paymentThreshold *big.Int) error
 }
 
+// PriceObserver is used for being notified of the prices announced by the peers
+// and of the peers that acknowledged the announced price. A zero price means
+// that the peer does not announce a price. The announcements of a price that
+// is not accepted are ignored and the previous price of the peer is kept.
+type PriceObserver interface {
+	NotifyPeerPrice(peer swarm.Address, poPrice uint64) error
+	NotifyAnnouncedPrice(peer swarm.Address, poPrice uint64)
+}
+
 type Service struct {
 	streamer                 p2p.Streamer
 	logger                   log.Logger
@@ -51,6 +61,18 @@ type Service struct {
 	lightPaymentThreshold    *big.Int
 	minPaymentThreshold      *big.Int
 	paymentThresholdObserver PaymentThresholdObserver
+	priceObserver            PriceObserver
+
+	mu      sync.Mutex
+	poPrice uint64
+	// peers holds the payment thresholds last announced to the connected
+	// peers, which are repeated with every price announcement.
+	peers map[string]announcedPeer
+}
+
+type announcedPeer struct {
+	address          swarm.Address
+	paymentThreshold *big.Int
 }
 
 func New(streamer p2p.Streamer, logger log.Logger, paymentThreshold, lightPaymentThreshold, minThreshold *big.Int) *Service {
@@ -60,6 +82,7 @@ func New(streamer p2p.Streamer, logger log.Logger, paymentThreshold, lightPaymen
 		paymentThreshold:      paymentThreshold,
 		lightPaymentThreshold: lightPaymentThreshold,
 		minPaymentThreshold:   minThreshold,
+		peers:                 make(map[string]announcedPeer),
 	}
 }
 
@@ -73,8 +96,10 @@ func (s *Service) Protocol() p2p.ProtocolSpec {
 				Handler: s.handler,
 			},
 		},
-		ConnectIn:  s.init,
-		ConnectOut: s.init,
+		ConnectIn:     s.init,
+		ConnectOut:    s.init,
+		DisconnectIn:  s.disconnect,
+		DisconnectOut: s.disconnect,
 	}
 }
 
@@ -104,10 +129,22 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 		return p2p.NewDisconnectError(ErrThresholdTooLow)
 	}
 
-	if paymentThreshold.Cmp(big.NewInt(0)) == 0 {
-		return err
+	if paymentThreshold.Cmp(big.NewInt(0)) != 0 {
+		if err := s.paymentThresholdObserver.NotifyPaymentThreshold(p.Address, paymentThreshold); err != nil {
+			return err
+		}
 	}
-	return s.paymentThresholdObserver.NotifyPaymentThreshold(p.Address, paymentThreshold)
+
+	if req.PoPrice > 0 && s.priceObserver != nil {
+		loggerV1.Debug("received price announcement from peer", "peer_address", p.Address, "po_price", req.PoPrice)
+		// the stream is reset so that the peer does not charge the rejected price
+		if err := s.priceObserver.NotifyPeerPrice(p.Address, req.PoPrice); err != nil {
+			s.logger.Debug("price announcement ignored", "peer_address", p.Address, "po_price", req.PoPrice, "error", err)
+			return fmt.Errorf("price announcement from peer %v: %w", p.Address, err)
+		}
+	}
+
+	return nil
 }
 
 func (s *Service) init(ctx context.Context, p p2p.Peer) error {
@@ -117,6 +154,10 @@ func (s *Service) init(ctx context.Context, p p2p.Peer) error {
 		threshold = s.lightPaymentThreshold
 	}
 
+	s.mu.Lock()
+	s.peers[p.Address.ByteString()] = announcedPeer{address: p.Address, paymentThreshold: threshold}
+	s.mu.Unlock()
+
 	err := s.AnnouncePaymentThreshold(ctx, p.Address, threshold)
 	if err != nil {
 		s.logger.Warning("could not send payment threshold announcement to peer", "peer_address", p.Address)
@@ -124,10 +165,29 @@ func (s *Service) init(ctx context.Context, p p2p.Peer) error {
 	return err
 }
 
+func (s *Service) disconnect(p p2p.Peer) error {
+	s.mu.Lock()
+	delete(s.peers, p.Address.ByteString())
+	s.mu.Unlock()
+
+	if s.priceObserver != nil {
+		_ = s.priceObserver.NotifyPeerPrice(p.Address, 0)
+	}
+	return nil
+}
+
 // AnnouncePaymentThreshold announces the payment threshold to per
 func (s *Service) AnnouncePaymentThreshold(ctx context.Context, peer swarm.Address, paymentThreshold *big.Int) error {
 	loggerV1 := s.logger.V(1).Register()
 
+	s.mu.Lock()
+	poPrice := s.poPrice
+	if p, ok := s.peers[peer.ByteString()]; ok {
+		p.paymentThreshold = new(big.Int).Set(paymentThreshold)
+		s.peers[peer.ByteString()] = p
+	}
+	s.mu.Unlock()
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -138,21 +198,70 @@ func (s *Service) AnnouncePaymentThreshold(ctx context.Context, peer swarm.Addre
 	defer func() {
 		if err != nil {
 			_ = stream.Reset()
-		} else {
-			stream.FullClose()
 		}
 	}()
 
-	loggerV1.Debug("sending payment threshold announcement to peer", "peer_address", peer, "payment_threshold", paymentThreshold)
+	loggerV1.Debug("sending payment threshold announcement to peer", "peer_address", peer, "payment_threshold", paymentThreshold, "po_price", poPrice)
 	w := protobuf.NewWriter(stream)
 	err = w.WriteMsgWithContext(ctx, &pb.AnnouncePaymentThreshold{
 		PaymentThreshold: paymentThreshold.Bytes(),
+		PoPrice:          poPrice,
 	})
+	if err != nil {
+		return err
+	}
+	if poPrice == 0 || s.priceObserver == nil {
+		_ = stream.FullClose()
+		return nil
+	}
 
-	return err
+	// the peer closes the stream once it accepted the price
+	if err = stream.FullClose(); err != nil {
+		return fmt.Errorf("price not acknowledged: %w", err)
+	}
+	s.priceObserver.NotifyAnnouncedPrice(peer, poPrice)
+	return nil
+}
+
+// AnnouncePrice sets the price per proximity order that is announced along
+// with the payment threshold and announces it to all connected peers. It
+// returns once every peer was either notified or failed to be.
+func (s *Service) AnnouncePrice(ctx context.Context, poPrice uint64) error {
+	s.mu.Lock()
+	s.poPrice = poPrice
+	peers := make([]announcedPeer, 0, len(s.peers))
+	for _, p := range s.peers {
+		peers = append(peers, p)
+	}
+	s.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p announcedPeer) {
+			defer wg.Done()
+			if err := s.AnnouncePaymentThreshold(ctx, p.address, p.paymentThreshold); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("announce price to peer %s: %w", p.address, err))
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 // SetPaymentThresholdObserver sets the PaymentThresholdObserver to be used when receiving a new payment threshold
 func (s *Service) SetPaymentThresholdObserver(observer PaymentThresholdObserver) {
 	s.paymentThresholdObserver = observer
 }
+
+// SetPriceObserver sets the PriceObserver to be used when receiving a price announcement
+func (s *Service) SetPriceObserver(observer PriceObserver) {
+	s.priceObserver = observer
+}
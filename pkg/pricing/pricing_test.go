@@ -9,12 +9,14 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/v2/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/v2/pkg/pricer"
 	"github.com/ethersphere/bee/v2/pkg/pricing"
 	"github.com/ethersphere/bee/v2/pkg/pricing/pb"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
@@ -296,3 +298,153 @@ func TestInitialPaymentThresholdLightNode(t *testing.T) {
 		t.Fatalf("observer called with wrong peer, got %v, want %v", observer.peer, peerID)
 	}
 }
+
+type testPriceObserver struct {
+	mu        sync.Mutex
+	prices    map[string]uint64
+	announced map[string]uint64
+	reject    error
+}
+
+func newTestPriceObserver() *testPriceObserver {
+	return &testPriceObserver{prices: make(map[string]uint64), announced: make(map[string]uint64)}
+}
+
+func (t *testPriceObserver) NotifyPeerPrice(peer swarm.Address, poPrice uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reject != nil && poPrice > 0 {
+		return t.reject
+	}
+	t.prices[peer.String()] = poPrice
+	return nil
+}
+
+func (t *testPriceObserver) NotifyAnnouncedPrice(peer swarm.Address, poPrice uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.announced[peer.String()] = poPrice
+}
+
+func (t *testPriceObserver) setReject(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reject = err
+}
+
+func (t *testPriceObserver) announcedPrice(peer swarm.Address) (uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	poPrice, ok := t.announced[peer.String()]
+	return poPrice, ok
+}
+
+func (t *testPriceObserver) price(peer swarm.Address) (uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	poPrice, ok := t.prices[peer.String()]
+	return poPrice, ok
+}
+
+type noopThresholdObserver struct{}
+
+func (noopThresholdObserver) NotifyPaymentThreshold(swarm.Address, *big.Int) error { return nil }
+
+func TestAnnouncePrice(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Noop
+	testThreshold := big.NewInt(100000)
+	testLightThreshold := big.NewInt(10000)
+
+	observer := newTestPriceObserver()
+
+	recipient := pricing.New(nil, logger, testThreshold, testLightThreshold, big.NewInt(1000))
+	recipient.SetPaymentThresholdObserver(noopThresholdObserver{})
+	recipient.SetPriceObserver(observer)
+
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	peer := p2p.Peer{Address: peerID, FullNode: false}
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol()),
+		streamtest.WithBaseAddr(peerID),
+	)
+
+	payer := pricing.New(recorder, logger, testThreshold, testLightThreshold, big.NewInt(1000))
+	payerObserver := newTestPriceObserver()
+	payer.SetPriceObserver(payerObserver)
+
+	// no price is announced until it is set
+	if err := payer.Init(context.Background(), peer); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := observer.price(peerID); ok {
+		t.Fatal("unexpected price announcement")
+	}
+
+	if err := payer.AnnouncePrice(context.Background(), 2000); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := recorder.Records(peerID, "pricing", "1.0.0", "pricing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := len(records); l != 2 {
+		t.Fatalf("got %v records, want %v", l, 2)
+	}
+
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(records[1].In()),
+		func() protobuf.Message { return new(pb.AnnouncePaymentThreshold) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the price is announced with the payment threshold of the peer
+	msg := messages[0].(*pb.AnnouncePaymentThreshold)
+	if got := big.NewInt(0).SetBytes(msg.PaymentThreshold); got.Cmp(testLightThreshold) != 0 {
+		t.Fatalf("got payment threshold %v, want %v", got, testLightThreshold)
+	}
+	if msg.PoPrice != 2000 {
+		t.Fatalf("got price %v, want %v", msg.PoPrice, 2000)
+	}
+
+	if got, _ := observer.price(peerID); got != 2000 {
+		t.Fatalf("observer called with price %v, want %v", got, 2000)
+	}
+	if got, _ := payerObserver.announcedPrice(peerID); got != 2000 {
+		t.Fatalf("got acknowledged price %v, want %v", got, 2000)
+	}
+
+	// a rejected price is ignored without a disconnect
+	observer.setReject(pricer.ErrPriceOutOfRange)
+	_ = payer.AnnouncePrice(context.Background(), 3000)
+
+	records, err = recorder.Records(peerID, "pricing", "1.0.0", "pricing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = records[2].Err()
+	if err == nil {
+		t.Fatal("expected an error for the rejected price")
+	}
+	var disconnectErr *p2p.DisconnectError
+	if errors.As(err, &disconnectErr) {
+		t.Fatalf("got disconnect error %v for the rejected price", err)
+	}
+	if got, _ := observer.price(peerID); got != 2000 {
+		t.Fatalf("got price %v after the rejected announcement, want %v", got, 2000)
+	}
+
+	// the price of a disconnected peer is reset
+	if err := recipient.Protocol().DisconnectIn(peer); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := observer.price(peerID); got != 0 {
+		t.Fatalf("got price %v of a disconnected peer, want %v", got, 0)
+	}
+}
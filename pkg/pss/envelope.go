@@ -0,0 +1,196 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+)
+
+// AckTopic is the topic of the acknowledgements sent back to the senders of
+// the messages that requested one.
+var AckTopic = NewTopic("swarm-pss-ack")
+
+// envelopeMagic prefixes the enveloped messages so that they can be told
+// apart from the raw payloads. The last byte is the envelope version.
+var envelopeMagic = []byte{0x00, 'p', 's', 'e', 1}
+
+const (
+	flagAck     byte = 1 << iota // the sender waits for an acknowledgement
+	flagReplyTo                  // the envelope carries a reply-to address
+
+	envelopeHeaderSize = 5 + 1 + MessageIDSize
+	publicKeySize      = 33
+
+	// MaxTargetLength is the maximal length of the reply-to targets in
+	// bytes, the same as the one of the targets accepted by the API, so
+	// that mining an acknowledgement does not take excessive computation.
+	MaxTargetLength = 3
+	// MaxReplyToTargets is the maximal number of the reply-to targets.
+	MaxReplyToTargets = 16
+)
+
+var (
+	// ErrInvalidEnvelope is returned when an envelope cannot be encoded or decoded.
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	// ErrAckTimeout is returned by SendAndWait when no acknowledgement
+	// arrived before the timeout.
+	ErrAckTimeout = errors.New("acknowledgement timeout")
+)
+
+// MessageIDSize is the size of the message identifiers in bytes.
+const MessageIDSize = 32
+
+// MessageID identifies an enveloped message and its acknowledgement.
+type MessageID [MessageIDSize]byte
+
+// NewMessageID returns a random message identifier.
+func NewMessageID() (MessageID, error) {
+	var id MessageID
+	_, err := rand.Read(id[:])
+	return id, err
+}
+
+// String returns the hex encoded message identifier.
+func (id MessageID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// ReplyTo addresses the replies and the acknowledgements of a message
+// to its sender.
+type ReplyTo struct {
+	PublicKey *ecdsa.PublicKey
+	Targets   Targets
+}
+
+// Envelope is the optional header of a message. It identifies the message
+// and lets the recipient reply to the sender.
+type Envelope struct {
+	ID MessageID
+	// Ack requests an acknowledgement to be sent to ReplyTo on the receipt
+	// of the message.
+	Ack     bool
+	ReplyTo *ReplyTo
+}
+
+// marshal serialises the envelope followed by the payload:
+// magic | version | flags | id | [public key | target count | target length | targets] | payload
+func (e Envelope) marshal(payload []byte) ([]byte, error) {
+	if e.Ack && e.ReplyTo == nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	b := make([]byte, 0, envelopeHeaderSize+len(payload))
+	b = append(b, envelopeMagic...)
+	var flags byte
+	if e.Ack {
+		flags |= flagAck
+	}
+	if e.ReplyTo != nil {
+		flags |= flagReplyTo
+	}
+	b = append(b, flags)
+	b = append(b, e.ID[:]...)
+
+	if e.ReplyTo != nil {
+		if e.ReplyTo.PublicKey == nil {
+			return nil, ErrInvalidEnvelope
+		}
+		if err := checkTargets(e.ReplyTo.Targets); err != nil {
+			return nil, err
+		}
+		if !validReplyToTargets(len(e.ReplyTo.Targets), len(e.ReplyTo.Targets[0])) {
+			return nil, ErrInvalidEnvelope
+		}
+		b = append(b, crypto.EncodeSecp256k1PublicKey(e.ReplyTo.PublicKey)...)
+		b = append(b, byte(len(e.ReplyTo.Targets)), byte(len(e.ReplyTo.Targets[0])))
+		for _, t := range e.ReplyTo.Targets {
+			b = append(b, t...)
+		}
+	}
+
+	return append(b, payload...), nil
+}
+
+// unmarshalEnvelope splits the message to the envelope and the payload.
+// It reports false if the message is not enveloped.
+func unmarshalEnvelope(msg []byte) (Envelope, []byte, bool) {
+	var e Envelope
+	if len(msg) < envelopeHeaderSize || !bytes.Equal(msg[:len(envelopeMagic)], envelopeMagic) {
+		return e, nil, false
+	}
+	msg = msg[len(envelopeMagic):]
+	flags := msg[0]
+	copy(e.ID[:], msg[1:1+MessageIDSize])
+	msg = msg[1+MessageIDSize:]
+	e.Ack = flags&flagAck != 0
+
+	if flags&flagReplyTo != 0 {
+		if len(msg) < publicKeySize+2 {
+			return e, nil, false
+		}
+		pubkey, err := btcec.ParsePubKey(msg[:publicKeySize])
+		if err != nil {
+			return e, nil, false
+		}
+		count, length := int(msg[publicKeySize]), int(msg[publicKeySize+1])
+		msg = msg[publicKeySize+2:]
+		if !validReplyToTargets(count, length) || len(msg) < count*length {
+			return e, nil, false
+		}
+		targets := make(Targets, count)
+		for i := range targets {
+			targets[i] = Target(msg[i*length : (i+1)*length])
+		}
+		msg = msg[count*length:]
+		e.ReplyTo = &ReplyTo{PublicKey: pubkey.ToECDSA(), Targets: targets}
+	} else if e.Ack {
+		return e, nil, false
+	}
+
+	return e, msg, true
+}
+
+// validReplyToTargets reports whether the number and the length of the
+// reply-to targets are within the limits.
+func validReplyToTargets(count, length int) bool {
+	return count > 0 && count <= MaxReplyToTargets && length > 0 && length <= MaxTargetLength
+}
+
+type envelopeKey struct{}
+
+// EnvelopeFromContext returns the envelope of the message passed to
+// a Handler with the context, if the message was enveloped.
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	e, ok := ctx.Value(envelopeKey{}).(Envelope)
+	return e, ok
+}
+
+func withEnvelope(ctx context.Context, e Envelope) context.Context {
+	return context.WithValue(ctx, envelopeKey{}, e)
+}
+
+type rejectKey struct{}
+
+// Reject marks the message passed to a Handler with the context as not
+// accepted, so that its receipt is not acknowledged to the sender.
+func Reject(ctx context.Context) {
+	if rejected, ok := ctx.Value(rejectKey{}).(*atomic.Bool); ok {
+		rejected.Store(true)
+	}
+}
+
+func withReject(ctx context.Context) (context.Context, *atomic.Bool) {
+	rejected := new(atomic.Bool)
+	return context.WithValue(ctx, rejectKey{}, rejected), rejected
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := pss.NewMessageID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("payload")
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		want := pss.Envelope{
+			ID:      id,
+			Ack:     true,
+			ReplyTo: &pss.ReplyTo{PublicKey: &key.PublicKey, Targets: pss.Targets{{1, 2}, {3, 4}}},
+		}
+		msg, err := pss.MarshalEnvelope(want, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotPayload, ok := pss.UnmarshalEnvelope(msg)
+		if !ok {
+			t.Fatal("envelope not found")
+		}
+		if !bytes.Equal(gotPayload, payload) {
+			t.Fatalf("payload mismatch: expected %x, got %x", payload, gotPayload)
+		}
+		if got.ID != want.ID || !got.Ack || !got.ReplyTo.PublicKey.Equal(want.ReplyTo.PublicKey) {
+			t.Fatalf("envelope mismatch: expected %+v, got %+v", want, got)
+		}
+		if len(got.ReplyTo.Targets) != 2 || !bytes.Equal(got.ReplyTo.Targets[1], want.ReplyTo.Targets[1]) {
+			t.Fatalf("targets mismatch: expected %v, got %v", want.ReplyTo.Targets, got.ReplyTo.Targets)
+		}
+	})
+
+	t.Run("without reply-to", func(t *testing.T) {
+		t.Parallel()
+
+		msg, err := pss.MarshalEnvelope(pss.Envelope{ID: id}, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotPayload, ok := pss.UnmarshalEnvelope(msg)
+		if !ok || got.ID != id || got.Ack || got.ReplyTo != nil || !bytes.Equal(gotPayload, payload) {
+			t.Fatalf("unexpected envelope %+v with payload %x", got, gotPayload)
+		}
+	})
+
+	t.Run("ack without reply-to", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pss.MarshalEnvelope(pss.Envelope{ID: id, Ack: true}, payload)
+		if !errors.Is(err, pss.ErrInvalidEnvelope) {
+			t.Fatalf("got error %v, want %v", err, pss.ErrInvalidEnvelope)
+		}
+	})
+
+	t.Run("reply-to limits", func(t *testing.T) {
+		t.Parallel()
+
+		long := pss.Targets{make(pss.Target, pss.MaxTargetLength+1)}
+		many := make(pss.Targets, pss.MaxReplyToTargets+1)
+		for i := range many {
+			many[i] = pss.Target{byte(i)}
+		}
+		for _, targets := range []pss.Targets{long, many} {
+			_, err := pss.MarshalEnvelope(pss.Envelope{ID: id, ReplyTo: &pss.ReplyTo{PublicKey: &key.PublicKey, Targets: targets}}, payload)
+			if !errors.Is(err, pss.ErrInvalidEnvelope) {
+				t.Fatalf("got error %v, want %v", err, pss.ErrInvalidEnvelope)
+			}
+		}
+
+		msg, err := pss.MarshalEnvelope(pss.Envelope{ID: id, ReplyTo: &pss.ReplyTo{PublicKey: &key.PublicKey, Targets: pss.Targets{{1}}}}, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// overwrite the target length in the encoded envelope
+		msg[len(msg)-len(payload)-2] = pss.MaxTargetLength + 1
+		if _, _, ok := pss.UnmarshalEnvelope(msg); ok {
+			t.Fatal("envelope with too long reply-to targets decoded")
+		}
+	})
+
+	t.Run("raw payload", func(t *testing.T) {
+		t.Parallel()
+
+		if _, _, ok := pss.UnmarshalEnvelope(payload); ok {
+			t.Fatal("raw payload should not be enveloped")
+		}
+	})
+}
@@ -5,5 +5,10 @@
 package pss
 
 var (
-	Contains = contains
+	Contains          = contains
+	UnmarshalEnvelope = unmarshalEnvelope
 )
+
+func MarshalEnvelope(e Envelope, payload []byte) ([]byte, error) {
+	return e.marshal(payload)
+}
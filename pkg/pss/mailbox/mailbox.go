@@ -0,0 +1,325 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mailbox provides store-and-forward pss messaging for the recipients
+// that are not online in their neighbourhood when the message is sent.
+//
+// Every sender has its own mailbox feed for each recipient and topic. The
+// owner key of the feed is derived from the ECDH shared secret of the sender
+// and the recipient, so only the sender can append to it, and the messages
+// are sealed with the pss encryption so that only the recipient can read
+// them. The recipient drains the mailbox of a sender from the last
+// acknowledged index when it reconnects, and acknowledges the messages once
+// they are processed. The mailboxes addressed to the previous versions of a
+// rotated pss key are drained too.
+package mailbox
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "pss-mailbox"
+
+// DrainLimit is the maximum number of messages returned by one Drain call.
+const DrainLimit = 128
+
+const (
+	cursorKeyPrefix     = "pss_mailbox_cursor_"
+	sendCursorKeyPrefix = "pss_mailbox_send_cursor_"
+)
+
+// feedKeySalt is the ECDH salt of the owner keys of the mailbox feeds.
+var feedKeySalt = []byte("swarm-pss-mailbox")
+
+// ErrUnknownIndex is returned when a message that is not in the mailbox is
+// acknowledged.
+var ErrUnknownIndex = errors.New("mailbox: unknown message index")
+
+// ErrUnknownRecipient is returned when the messages addressed to a public key
+// that is not a key of the node are acknowledged.
+var ErrUnknownRecipient = errors.New("mailbox: unknown recipient key")
+
+// Message is a message drained from a mailbox. Recipient is the public key
+// of the node the message is addressed to, which is a previous key if the
+// pss key was rotated after the message was sent.
+type Message struct {
+	Index     uint64
+	Timestamp uint64
+	Payload   []byte
+	Recipient *ecdsa.PublicKey
+}
+
+// Service appends messages to the mailboxes of the recipients and drains the
+// mailboxes of the node.
+type Service struct {
+	getter storage.Getter
+	store  storage.StateStorer
+	keys   []pss.RotatedKey // the current key followed by the previous ones
+	logger log.Logger
+	mu     sync.Mutex // serialises the updates of the cursors
+
+	feedsMu sync.Mutex
+	feeds   map[string]*sync.Mutex // serialise the sends to a mailbox feed
+}
+
+// New returns a new mailbox service. The mailboxes of the node are addressed
+// to the public key, and the shared keys generated by dh sign the messages
+// sent by the node and open the messages received by it. The mailboxes
+// addressed to the previous keys are drained as well.
+func New(getter storage.Getter, store storage.StateStorer, publicKey *ecdsa.PublicKey, dh crypto.DH, logger log.Logger, previous ...pss.RotatedKey) *Service {
+	return &Service{
+		getter: getter,
+		store:  store,
+		keys:   append([]pss.RotatedKey{{DH: dh, PublicKey: publicKey}}, previous...),
+		logger: logger.WithName(loggerName).Register(),
+		feeds:  make(map[string]*sync.Mutex),
+	}
+}
+
+// Feed returns the mailbox feed of a sender for the recipient on the topic
+// and the signer of its updates. The dh holds the private key of one of the
+// parties and peer is the public key of the other one, so that the sender and
+// the recipient derive the same feed.
+func Feed(dh crypto.DH, peer, recipient *ecdsa.PublicKey, topic pss.Topic) (*feeds.Feed, crypto.Signer, error) {
+	seed, err := dh.SharedKey(peer, feedKeySalt)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer := crypto.NewDefaultSigner(crypto.Secp256k1PrivateKeyFromBytes(seed))
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+	// the shared secret is symmetric, so the feed topic includes the recipient
+	// to keep the mailboxes of the two directions apart
+	feedTopic, err := crypto.LegacyKeccak256(append(crypto.EncodeSecp256k1PublicKey(recipient), topic[:]...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return feeds.New(feedTopic, owner), signer, nil
+}
+
+// Send seals the message for the recipient and appends it to the mailbox of
+// the node for the recipient on the topic. It returns the address of the feed
+// update and the index of the message in the mailbox. The sends to the same
+// mailbox are serialised, and the next index is kept locally as well, so
+// that a message is not appended at the index of a message that cannot be
+// looked up yet.
+func (s *Service) Send(ctx context.Context, putter storage.Putter, topic pss.Topic, recipient *ecdsa.PublicKey, msg []byte) (swarm.Address, uint64, error) {
+	sealed, err := pss.Seal(topic, msg, recipient)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+
+	feed, signer, err := Feed(s.keys[0].DH, recipient, recipient, topic)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+
+	unlock := s.lockFeed(feed)
+	defer unlock()
+
+	_, _, next, err := sequence.NewAsyncFinder(s.getter, feed).At(ctx, time.Now().Unix(), 0)
+	if err != nil {
+		return swarm.ZeroAddress, 0, fmt.Errorf("mailbox lookup: %w", err)
+	}
+	b, err := next.MarshalBinary()
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	index := binary.BigEndian.Uint64(b)
+
+	var sent uint64
+	if err := s.store.Get(sendCursorKey(feed), &sent); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return swarm.ZeroAddress, 0, fmt.Errorf("mailbox send cursor: %w", err)
+	}
+	if sent > index {
+		index = sent
+		next = sequence.NewIndex(index)
+	}
+
+	p, err := feeds.NewPutter(putter, signer, feed.Topic)
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	if err := p.Put(ctx, next, time.Now().Unix(), sealed); err != nil {
+		return swarm.ZeroAddress, 0, fmt.Errorf("mailbox put: %w", err)
+	}
+	if err := s.store.Put(sendCursorKey(feed), index+1); err != nil {
+		return swarm.ZeroAddress, 0, fmt.Errorf("mailbox send cursor: %w", err)
+	}
+	addr, err := feed.Update(next).Address()
+	if err != nil {
+		return swarm.ZeroAddress, 0, err
+	}
+	return addr, index, nil
+}
+
+// Drain returns the messages of the sender in the mailboxes of the node on
+// the topic from the last acknowledged ones, at most DrainLimit of them. The
+// mailboxes addressed to the previous keys of the node are drained first.
+// The messages that cannot be opened are skipped. Drain does not move the
+// cursors, so the messages are returned again until they are acknowledged
+// with Ack.
+func (s *Service) Drain(ctx context.Context, topic pss.Topic, sender *ecdsa.PublicKey) ([]Message, error) {
+	messages := make([]Message, 0)
+	for i := len(s.keys) - 1; i >= 0 && len(messages) < DrainLimit; i-- {
+		drained, err := s.drain(ctx, s.keys[i], topic, sender, DrainLimit-len(messages))
+		if err != nil {
+			if len(messages) == 0 {
+				return nil, err
+			}
+			break
+		}
+		messages = append(messages, drained...)
+	}
+	return messages, nil
+}
+
+// drain returns at most limit messages of the sender in the mailbox
+// addressed to the key.
+func (s *Service) drain(ctx context.Context, key pss.RotatedKey, topic pss.Topic, sender *ecdsa.PublicKey, limit int) ([]Message, error) {
+	feed, _, err := Feed(key.DH, sender, key.PublicKey, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.cursor(topic, sender, key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		getter   = feeds.NewGetter(s.getter, feed)
+		messages = make([]Message, 0)
+	)
+	for i := cursor; i < cursor+uint64(limit); i++ {
+		ch, err := getter.Get(ctx, sequence.NewIndex(i))
+		if errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			if len(messages) == 0 {
+				return nil, fmt.Errorf("mailbox get: %w", err)
+			}
+			break
+		}
+		at, sealed, err := feeds.FromChunk(ch)
+		if err != nil {
+			s.logger.Debug("invalid mailbox message", "index", i, "error", err)
+			continue
+		}
+		payload, err := pss.OpenDH(key.DH, topic, sealed)
+		if err != nil {
+			s.logger.Debug("open mailbox message failed", "index", i, "error", err)
+			continue
+		}
+		messages = append(messages, Message{Index: i, Timestamp: at, Payload: payload, Recipient: key.PublicKey})
+	}
+	return messages, nil
+}
+
+// Ack acknowledges the messages of the sender in the mailbox of the node on
+// the topic up to and including the one at the index, so that the following
+// drains start after it. The recipient is the key of the node the messages
+// are addressed to, the current key if it is nil. Acknowledging an already
+// acknowledged index is a no-op, and ErrUnknownIndex is returned if there is
+// no message at the index.
+func (s *Service) Ack(ctx context.Context, topic pss.Topic, sender, recipient *ecdsa.PublicKey, index uint64) error {
+	key, ok := s.key(recipient)
+	if !ok {
+		return ErrUnknownRecipient
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursor, err := s.cursor(topic, sender, key.PublicKey)
+	if err != nil {
+		return err
+	}
+	if index < cursor {
+		return nil
+	}
+
+	feed, _, err := Feed(key.DH, sender, key.PublicKey, topic)
+	if err != nil {
+		return err
+	}
+	if _, err := feeds.NewGetter(s.getter, feed).Get(ctx, sequence.NewIndex(index)); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrUnknownIndex
+		}
+		return fmt.Errorf("mailbox get: %w", err)
+	}
+
+	if err := s.store.Put(cursorKey(topic, sender, key.PublicKey), index+1); err != nil {
+		return fmt.Errorf("mailbox cursor: %w", err)
+	}
+	return nil
+}
+
+// key returns the key of the node with the public key, the current key if
+// it is nil.
+func (s *Service) key(publicKey *ecdsa.PublicKey) (pss.RotatedKey, bool) {
+	if publicKey == nil {
+		return s.keys[0], true
+	}
+	for _, k := range s.keys {
+		if k.PublicKey.Equal(publicKey) {
+			return k, true
+		}
+	}
+	return pss.RotatedKey{}, false
+}
+
+// lockFeed locks the sends to the mailbox feed and returns the unlock
+// function.
+func (s *Service) lockFeed(feed *feeds.Feed) func() {
+	key := string(feed.Owner.Bytes()) + string(feed.Topic)
+
+	s.feedsMu.Lock()
+	mu, ok := s.feeds[key]
+	if !ok {
+		mu = new(sync.Mutex)
+		s.feeds[key] = mu
+	}
+	s.feedsMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (s *Service) cursor(topic pss.Topic, sender, recipient *ecdsa.PublicKey) (uint64, error) {
+	var cursor uint64
+	if err := s.store.Get(cursorKey(topic, sender, recipient), &cursor); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("mailbox cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+func cursorKey(topic pss.Topic, sender, recipient *ecdsa.PublicKey) string {
+	return cursorKeyPrefix + hex.EncodeToString(topic[:]) + "_" + hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(sender)) +
+		"_" + hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(recipient))
+}
+
+func sendCursorKey(feed *feeds.Feed) string {
+	return sendCursorKeyPrefix + hex.EncodeToString(feed.Owner.Bytes()) + "_" + hex.EncodeToString(feed.Topic)
+}
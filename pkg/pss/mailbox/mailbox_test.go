@@ -0,0 +1,307 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mailbox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/pss"
+	"github.com/ethersphere/bee/v2/pkg/pss/mailbox"
+	mockstatestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+)
+
+func TestMailbox(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		chunkStore = inmemchunkstore.New()
+		topic      = pss.NewTopic("mailbox")
+	)
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &senderKey.PublicKey, crypto.NewDH(senderKey), log.Noop)
+	recipient := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &recipientKey.PublicKey, crypto.NewDH(recipientKey), log.Noop)
+
+	send := func(t *testing.T, payload []byte, wantIndex uint64) {
+		t.Helper()
+
+		_, index, err := sender.Send(ctx, chunkStore, topic, &recipientKey.PublicKey, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != wantIndex {
+			t.Fatalf("got index %d, want %d", index, wantIndex)
+		}
+	}
+	drain := func(t *testing.T, want ...[]byte) []mailbox.Message {
+		t.Helper()
+
+		messages, err := recipient.Drain(ctx, topic, &senderKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != len(want) {
+			t.Fatalf("got %d messages, want %d", len(messages), len(want))
+		}
+		for i, m := range messages {
+			if !bytes.Equal(m.Payload, want[i]) {
+				t.Fatalf("message %d mismatch: expected %q, got %q", i, want[i], m.Payload)
+			}
+		}
+		return messages
+	}
+	ack := func(t *testing.T, index uint64) {
+		t.Helper()
+
+		if err := recipient.Ack(ctx, topic, &senderKey.PublicKey, nil, index); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	drain(t)
+
+	var payloads [][]byte
+	for i := 0; i < 3; i++ {
+		payload := []byte(fmt.Sprintf("message %d", i))
+		payloads = append(payloads, payload)
+		send(t, payload, uint64(i))
+	}
+
+	// the messages are drained again until they are acknowledged
+	drain(t, payloads...)
+	drain(t, payloads...)
+	ack(t, 0)
+	drain(t, payloads[1:]...)
+	messages := drain(t, payloads[1:]...)
+	ack(t, messages[len(messages)-1].Index)
+	drain(t)
+
+	// acknowledging an acknowledged message is a no-op
+	ack(t, 1)
+	drain(t)
+
+	if err := recipient.Ack(ctx, topic, &senderKey.PublicKey, nil, 3); !errors.Is(err, mailbox.ErrUnknownIndex) {
+		t.Fatalf("got error %v, want %v", err, mailbox.ErrUnknownIndex)
+	}
+
+	// the messages to the mailbox of another recipient are not drained
+	if _, _, err := sender.Send(ctx, chunkStore, topic, &senderKey.PublicKey, []byte("not for the recipient")); err != nil {
+		t.Fatal(err)
+	}
+	drain(t)
+
+	send(t, []byte("message 3"), 3)
+	drain(t, []byte("message 3"))
+
+	// the mailbox of another sender is separate
+	otherKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &otherKey.PublicKey, crypto.NewDH(otherKey), log.Noop)
+	if _, index, err := other.Send(ctx, chunkStore, topic, &recipientKey.PublicKey, []byte("other sender")); err != nil || index != 0 {
+		t.Fatalf("got index %d and error %v, want index 0", index, err)
+	}
+	drain(t, []byte("message 3"))
+	messages, err = recipient.Drain(ctx, topic, &otherKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || !bytes.Equal(messages[0].Payload, []byte("other sender")) {
+		t.Fatalf("unexpected messages of the other sender: %v", messages)
+	}
+
+	// the mailbox on another topic is separate
+	messages, err = recipient.Drain(ctx, pss.NewTopic("other"), &senderKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("got %d messages on other topic, want none", len(messages))
+	}
+}
+
+func TestFeedOwner(t *testing.T) {
+	t.Parallel()
+
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic := pss.NewTopic("mailbox")
+
+	sent, _, err := mailbox.Feed(crypto.NewDH(senderKey), &recipientKey.PublicKey, &recipientKey.PublicKey, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, _, err := mailbox.Feed(crypto.NewDH(recipientKey), &senderKey.PublicKey, &recipientKey.PublicKey, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Owner != received.Owner || !bytes.Equal(sent.Topic, received.Topic) {
+		t.Fatal("sender and recipient derive different feeds")
+	}
+
+	// the reverse direction between the same peers uses another feed
+	reverse, _, err := mailbox.Feed(crypto.NewDH(recipientKey), &senderKey.PublicKey, &senderKey.PublicKey, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sent.Topic, reverse.Topic) {
+		t.Fatal("mailboxes of the two directions share a feed")
+	}
+
+	// a third party cannot derive the feed from the public keys
+	otherKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, _, err := mailbox.Feed(crypto.NewDH(otherKey), &recipientKey.PublicKey, &recipientKey.PublicKey, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forged.Owner == sent.Owner {
+		t.Fatal("third party derived the owner of the feed")
+	}
+}
+
+func TestConcurrentSend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		chunkStore = inmemchunkstore.New()
+		topic      = pss.NewTopic("mailbox")
+		sends      = 16
+	)
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &senderKey.PublicKey, crypto.NewDH(senderKey), log.Noop)
+	recipient := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &recipientKey.PublicKey, crypto.NewDH(recipientKey), log.Noop)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		indexes = make(map[uint64]struct{})
+	)
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, index, err := sender.Send(ctx, chunkStore, topic, &recipientKey.PublicKey, []byte(fmt.Sprintf("message %d", i)))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			indexes[index] = struct{}{}
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if len(indexes) != sends {
+		t.Fatalf("got %d distinct indexes, want %d", len(indexes), sends)
+	}
+	messages, err := recipient.Drain(ctx, topic, &senderKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != sends {
+		t.Fatalf("got %d messages, want %d", len(messages), sends)
+	}
+}
+
+func TestRotatedKey(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = context.Background()
+		chunkStore = inmemchunkstore.New()
+		stateStore = mockstatestore.NewStateStore()
+		topic      = pss.NewTopic("mailbox")
+	)
+	previousKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := mailbox.New(chunkStore, mockstatestore.NewStateStore(), &senderKey.PublicKey, crypto.NewDH(senderKey), log.Noop)
+
+	// the sender did not learn the rotated key yet
+	if _, _, err := sender.Send(ctx, chunkStore, topic, &previousKey.PublicKey, []byte("to the previous key")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sender.Send(ctx, chunkStore, topic, &currentKey.PublicKey, []byte("to the current key")); err != nil {
+		t.Fatal(err)
+	}
+
+	recipient := mailbox.New(chunkStore, stateStore, &currentKey.PublicKey, crypto.NewDH(currentKey), log.Noop, pss.RotatedKey{
+		DH:        crypto.NewDH(previousKey),
+		PublicKey: &previousKey.PublicKey,
+	})
+
+	messages, err := recipient.Drain(ctx, topic, &senderKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	if !bytes.Equal(messages[0].Payload, []byte("to the previous key")) || !messages[0].Recipient.Equal(&previousKey.PublicKey) {
+		t.Fatalf("unexpected message of the previous key: %q", messages[0].Payload)
+	}
+	if !bytes.Equal(messages[1].Payload, []byte("to the current key")) || !messages[1].Recipient.Equal(&currentKey.PublicKey) {
+		t.Fatalf("unexpected message of the current key: %q", messages[1].Payload)
+	}
+
+	// the mailboxes of the keys are acknowledged separately
+	if err := recipient.Ack(ctx, topic, &senderKey.PublicKey, &previousKey.PublicKey, messages[0].Index); err != nil {
+		t.Fatal(err)
+	}
+	messages, err = recipient.Drain(ctx, topic, &senderKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || !bytes.Equal(messages[0].Payload, []byte("to the current key")) {
+		t.Fatalf("unexpected messages after the acknowledgement: %v", messages)
+	}
+
+	if err := recipient.Ack(ctx, topic, &senderKey.PublicKey, &senderKey.PublicKey, 0); !errors.Is(err, mailbox.ErrUnknownRecipient) {
+		t.Fatalf("got error %v, want %v", err, mailbox.ErrUnknownRecipient)
+	}
+}
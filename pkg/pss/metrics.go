@@ -12,6 +12,8 @@ import (
 type metrics struct {
 	TotalMessagesSentCounter prometheus.Counter
 	MessageMiningDuration    prometheus.Gauge
+	TotalAcksSentCounter     prometheus.Counter
+	TotalAcksReceivedCounter prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -30,6 +32,18 @@ func newMetrics() metrics {
 			Name:      "mining_duration",
 			Help:      "Time duration to mine a message.",
 		}),
+		TotalAcksSentCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_acks_sent",
+			Help:      "Total acknowledgements sent.",
+		}),
+		TotalAcksReceivedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_acks_received",
+			Help:      "Total acknowledgements received by the waiting senders.",
+		}),
 	}
 }
 
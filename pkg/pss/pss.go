@@ -17,11 +17,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/pushsync"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/topology"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/time/rate"
 )
 
 // loggerName is the tree path name of the logger for this package.
@@ -39,20 +42,54 @@ type Sender interface {
 
 type Interface interface {
 	Sender
+	// SendEnvelope sends the payload in the given envelope.
+	SendEnvelope(context.Context, Topic, Envelope, []byte, postage.Stamper, *ecdsa.PublicKey, Targets) error
+	// SendAndWait sends the payload in an envelope requesting an acknowledgement
+	// to the reply-to address and waits for it until the timeout.
+	SendAndWait(context.Context, Topic, []byte, postage.Stamper, *ecdsa.PublicKey, Targets, ReplyTo, time.Duration) (MessageID, error)
 	// Register a Handler for a given Topic.
 	Register(Topic, Handler) func()
 	// TryUnwrap tries to unwrap a wrapped trojan message.
 	TryUnwrap(swarm.Chunk)
 
 	SetPushSyncer(pushSyncer pushsync.PushSyncer)
+	// SetAckStamper sets the source of the stampers of the acknowledgements.
+	SetAckStamper(AckStamperFunc)
 	io.Closer
 }
 
+// AckStamperFunc returns the stamper of an acknowledgement and the function
+// that saves the stamper state after the acknowledgement is sent.
+type AckStamperFunc func() (stamper postage.Stamper, save func() error, err error)
+
+const (
+	// ackSendTimeout limits the time spent on sending an acknowledgement.
+	ackSendTimeout = time.Minute
+	// ackedCapacity is the number of the acknowledged message IDs that are
+	// remembered so that redelivered messages are not acknowledged again.
+	ackedCapacity = 1024
+	// ackInterval and ackBurst limit the rate of the acknowledgements, as
+	// each of them is mined and stamped by the node.
+	ackInterval = time.Second
+	ackBurst    = 16
+)
+
+// RotatedKey is a previous version of the rotated pss key.
+type RotatedKey struct {
+	crypto.DH
+	PublicKey *ecdsa.PublicKey
+}
+
 type pss struct {
-	key        *ecdsa.PrivateKey
+	dhs        []crypto.DH
 	pusher     pushsync.PushSyncer
 	handlers   map[Topic][]*Handler
 	handlersMu sync.Mutex
+	ackStamper AckStamperFunc
+	acks       map[MessageID]chan struct{}
+	acked      *lru.Cache[MessageID, struct{}]
+	ackLimiter *rate.Limiter
+	acksMu     sync.Mutex
 	metrics    metrics
 	logger     log.Logger
 	quit       chan struct{}
@@ -60,12 +97,25 @@ type pss struct {
 
 // New returns a new pss service.
 func New(key *ecdsa.PrivateKey, logger log.Logger) Interface {
+	return NewWithDH(crypto.NewDH(key), logger)
+}
+
+// NewWithDH returns a new pss service which unwraps the messages
+// with the shared keys generated by dh. The messages that are not
+// addressed to dh are tried with the previous keys, so that a rotated
+// key still receives the messages of the senders that did not learn
+// the new public key yet.
+func NewWithDH(dh crypto.DH, logger log.Logger, previous ...crypto.DH) Interface {
+	acked, _ := lru.New[MessageID, struct{}](ackedCapacity)
 	return &pss{
-		key:      key,
-		logger:   logger.WithName(loggerName).Register(),
-		handlers: make(map[Topic][]*Handler),
-		metrics:  newMetrics(),
-		quit:     make(chan struct{}),
+		dhs:        append([]crypto.DH{dh}, previous...),
+		logger:     logger.WithName(loggerName).Register(),
+		handlers:   make(map[Topic][]*Handler),
+		acks:       make(map[MessageID]chan struct{}),
+		acked:      acked,
+		ackLimiter: rate.NewLimiter(rate.Every(ackInterval), ackBurst),
+		metrics:    newMetrics(),
+		quit:       make(chan struct{}),
 	}
 }
 
@@ -83,13 +133,79 @@ func (ps *pss) SetPushSyncer(pushSyncer pushsync.PushSyncer) {
 	ps.pusher = pushSyncer
 }
 
+// SetAckStamper sets the source of the stampers of the acknowledgements.
+// The acknowledgements are not sent until it is set.
+func (ps *pss) SetAckStamper(f AckStamperFunc) {
+	ps.acksMu.Lock()
+	defer ps.acksMu.Unlock()
+
+	ps.ackStamper = f
+}
+
 // Handler defines code to be executed upon reception of a trojan message.
+// The envelope of the message, if any, is available with EnvelopeFromContext.
+// The receipt of the message is acknowledged if the sender requested it and
+// no handler called Reject with the context.
 type Handler func(context.Context, []byte)
 
 // Send constructs a padded message with topic and payload,
 // wraps it in a trojan chunk such that one of the targets is a prefix of the chunk address.
 // Uses push-sync to deliver message.
 func (p *pss) Send(ctx context.Context, topic Topic, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) error {
+	return p.send(ctx, topic, payload, stamper, recipient, targets)
+}
+
+// SendEnvelope is like Send, but the payload is prefixed with the envelope.
+func (p *pss) SendEnvelope(ctx context.Context, topic Topic, envelope Envelope, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) error {
+	msg, err := envelope.marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.send(ctx, topic, msg, stamper, recipient, targets)
+}
+
+// SendAndWait sends the payload in an envelope with a new message ID, and
+// blocks until the recipient acknowledges the receipt of the message to the
+// reply-to address. ErrAckTimeout is returned if the acknowledgement does not
+// arrive in the timeout after the message is sent.
+func (p *pss) SendAndWait(ctx context.Context, topic Topic, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets, replyTo ReplyTo, timeout time.Duration) (MessageID, error) {
+	id, err := NewMessageID()
+	if err != nil {
+		return id, err
+	}
+
+	ackC := make(chan struct{})
+	p.acksMu.Lock()
+	p.acks[id] = ackC
+	p.acksMu.Unlock()
+	defer func() {
+		p.acksMu.Lock()
+		delete(p.acks, id)
+		p.acksMu.Unlock()
+	}()
+
+	envelope := Envelope{ID: id, Ack: true, ReplyTo: &replyTo}
+	if err := p.SendEnvelope(ctx, topic, envelope, payload, stamper, recipient, targets); err != nil {
+		return id, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ackC:
+		p.metrics.TotalAcksReceivedCounter.Inc()
+		return id, nil
+	case <-timer.C:
+		return id, ErrAckTimeout
+	case <-ctx.Done():
+		return id, ctx.Err()
+	case <-p.quit:
+		return id, ErrAckTimeout
+	}
+}
+
+func (p *pss) send(ctx context.Context, topic Topic, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) error {
 	p.metrics.TotalMessagesSentCounter.Inc()
 
 	tStart := time.Now()
@@ -143,29 +259,122 @@ func (p *pss) topics() []Topic {
 	p.handlersMu.Lock()
 	defer p.handlersMu.Unlock()
 
-	ts := make([]Topic, 0, len(p.handlers))
+	ts := make([]Topic, 0, len(p.handlers)+1)
 	for t := range p.handlers {
 		ts = append(ts, t)
 	}
 
+	// the acknowledgements are only looked for while they are waited for
+	if _, ok := p.handlers[AckTopic]; !ok && p.waitingForAcks() {
+		ts = append(ts, AckTopic)
+	}
+
 	return ts
 }
 
+func (p *pss) waitingForAcks() bool {
+	p.acksMu.Lock()
+	defer p.acksMu.Unlock()
+
+	return len(p.acks) > 0
+}
+
+// receiveAck notifies the sender waiting for the acknowledgement of the
+// message with the given ID.
+func (p *pss) receiveAck(msg []byte) {
+	if len(msg) != MessageIDSize {
+		return
+	}
+	var id MessageID
+	copy(id[:], msg)
+
+	p.acksMu.Lock()
+	defer p.acksMu.Unlock()
+
+	if ackC, ok := p.acks[id]; ok {
+		close(ackC)
+		delete(p.acks, id)
+	}
+}
+
+// sendAck sends the acknowledgement of the enveloped message to its sender.
+func (p *pss) sendAck(envelope Envelope) {
+	p.acksMu.Lock()
+	ackStamper := p.ackStamper
+	p.acksMu.Unlock()
+
+	if ackStamper == nil {
+		p.logger.Debug("acknowledgement not sent, no stamper", "message_id", envelope.ID)
+		return
+	}
+
+	stamper, save, err := ackStamper()
+	if err != nil {
+		p.logger.Debug("acknowledgement stamper failed", "message_id", envelope.ID, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ackSendTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := p.send(ctx, AckTopic, envelope.ID[:], stamper, envelope.ReplyTo.PublicKey, envelope.ReplyTo.Targets); err != nil {
+		p.logger.Debug("send acknowledgement failed", "message_id", envelope.ID, "error", err)
+		return
+	}
+	if err := save(); err != nil {
+		p.logger.Debug("save acknowledgement stamper failed", "message_id", envelope.ID, "error", err)
+		return
+	}
+	p.metrics.TotalAcksSentCounter.Inc()
+}
+
 // TryUnwrap allows unwrapping a chunk as a trojan message and calling its handlers based on the topic.
 func (p *pss) TryUnwrap(c swarm.Chunk) {
 	if len(c.Data()) < swarm.ChunkWithSpanSize {
 		return // chunk not full
 	}
 	ctx := context.Background()
-	topic, msg, err := Unwrap(ctx, p.key, c, p.topics())
-	if err != nil {
-		return // cannot unwrap
+	topics := p.topics()
+	var (
+		topic Topic
+		msg   []byte
+	)
+	for _, dh := range p.dhs {
+		var err error
+		topic, msg, err = UnwrapDH(ctx, dh, c, topics)
+		if err != nil {
+			return // cannot unwrap
+		}
+		if msg != nil {
+			break
+		}
+	}
+	if msg == nil {
+		return // not addressed to us
+	}
+	if topic == AckTopic {
+		p.receiveAck(msg)
+	}
+
+	envelope, payload, enveloped := unmarshalEnvelope(msg)
+	if enveloped {
+		msg = payload
+		ctx = withEnvelope(ctx, envelope)
 	}
+
 	h := p.getHandlers(topic)
 	if h == nil {
 		return // no handler
 	}
 
+	ctx, rejected := withReject(ctx)
 	ctx, cancel := context.WithCancel(ctx)
 	done := make(chan struct{})
 	var wg sync.WaitGroup
@@ -186,9 +395,28 @@ func (p *pss) TryUnwrap(c swarm.Chunk) {
 	go func() {
 		wg.Wait()
 		close(done)
+
+		// only the messages accepted by the handlers are acknowledged
+		if enveloped && envelope.Ack && !rejected.Load() {
+			p.ack(envelope)
+		}
 	}()
 }
 
+// ack sends the acknowledgement of the message, unless it was already
+// acknowledged or the rate of the acknowledgements is exceeded.
+func (p *pss) ack(envelope Envelope) {
+	if acked, _ := p.acked.ContainsOrAdd(envelope.ID, struct{}{}); acked {
+		return
+	}
+	if !p.ackLimiter.Allow() {
+		p.acked.Remove(envelope.ID)
+		p.logger.Debug("acknowledgement not sent, rate exceeded", "message_id", envelope.ID)
+		return
+	}
+	p.sendAck(envelope)
+}
+
 func (p *pss) getHandlers(topic Topic) []*Handler {
 	p.handlersMu.Lock()
 	defer p.handlersMu.Unlock()
@@ -7,6 +7,10 @@ package pss_test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -130,6 +134,49 @@ func TestDeliver(t *testing.T) {
 	}
 }
 
+// TestDeliverPreviousKey verifies that the messages sent to a previous version
+// of a rotated key are delivered.
+func TestDeliverPreviousKey(t *testing.T) {
+	t.Parallel()
+
+	previous, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := pss.NewWithDH(crypto.NewDH(current), log.Noop, crypto.NewDH(previous))
+
+	targets := pss.Targets([]pss.Target{[]byte{1}})
+	topic := pss.NewTopic("topic")
+
+	msgChan := make(chan []byte, 2)
+	p.Register(topic, func(_ context.Context, m []byte) {
+		msgChan <- m
+	})
+
+	for _, recipient := range []*ecdsa.PublicKey{&current.PublicKey, &previous.PublicKey} {
+		payload := []byte("payload to " + hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(recipient)))
+		chunk, err := pss.Wrap(context.Background(), topic, payload, recipient, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p.TryUnwrap(chunk)
+
+		select {
+		case msg := <-msgChan:
+			if !bytes.Equal(payload, msg) {
+				t.Fatalf("message mismatch: expected %x, got %x", payload, msg)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("reached timeout while waiting for message")
+		}
+	}
+}
+
 // TestRegister verifies that handler funcs are able to be registered correctly in pss
 func TestRegister(t *testing.T) {
 	t.Parallel()
@@ -216,6 +263,176 @@ func TestRegister(t *testing.T) {
 	ensureCalls(t, &h3Calls, 1)
 }
 
+// TestSendAndWait verifies that the recipient of an enveloped message gets
+// the envelope and acknowledges the receipt to the waiting sender.
+func TestSendAndWait(t *testing.T) {
+	t.Parallel()
+
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := pss.New(senderKey, log.Noop)
+	recipient := pss.New(recipientKey, log.Noop)
+	t.Cleanup(func() {
+		_ = sender.Close()
+		_ = recipient.Close()
+	})
+
+	sender.SetPushSyncer(pushsyncmock.New(func(_ context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		recipient.TryUnwrap(chunk)
+		return nil, nil
+	}))
+	recipient.SetPushSyncer(pushsyncmock.New(func(_ context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		sender.TryUnwrap(chunk)
+		return nil, nil
+	}))
+	recipient.SetAckStamper(func() (postage.Stamper, func() error, error) {
+		return &stamper{}, func() error { return nil }, nil
+	})
+
+	var (
+		topic    = pss.NewTopic("topic")
+		payload  = []byte("some payload")
+		targets  = pss.Targets{pss.Target{1}}
+		replyTo  = pss.ReplyTo{PublicKey: &senderKey.PublicKey, Targets: pss.Targets{pss.Target{2}}}
+		received = make(chan pss.Envelope, 1)
+	)
+	recipient.Register(topic, func(ctx context.Context, m []byte) {
+		if !bytes.Equal(m, payload) {
+			t.Errorf("message mismatch: expected %x, got %x", payload, m)
+		}
+		envelope, ok := pss.EnvelopeFromContext(ctx)
+		if !ok {
+			t.Error("envelope not found")
+		}
+		received <- envelope
+	})
+
+	id, err := sender.SendAndWait(context.Background(), topic, payload, &stamper{}, &recipientKey.PublicKey, targets, replyTo, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case envelope := <-received:
+		if envelope.ID != id || !envelope.Ack {
+			t.Fatalf("unexpected envelope: %+v", envelope)
+		}
+		if !envelope.ReplyTo.PublicKey.Equal(replyTo.PublicKey) || !bytes.Equal(envelope.ReplyTo.Targets[0], replyTo.Targets[0]) {
+			t.Fatalf("reply-to mismatch: expected %+v, got %+v", replyTo, envelope.ReplyTo)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reached timeout while waiting for message")
+	}
+}
+
+// TestSendAndWaitTimeout verifies that SendAndWait times out if the recipient
+// does not acknowledge the message.
+func TestSendAndWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := pss.New(senderKey, log.Noop)
+	recipient := pss.New(recipientKey, log.Noop)
+	t.Cleanup(func() {
+		_ = sender.Close()
+		_ = recipient.Close()
+	})
+
+	// the recipient has no stamper for the acknowledgements
+	sender.SetPushSyncer(pushsyncmock.New(func(_ context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		recipient.TryUnwrap(chunk)
+		return nil, nil
+	}))
+
+	topic := pss.NewTopic("topic")
+	received := make(chan struct{}, 1)
+	recipient.Register(topic, func(context.Context, []byte) {
+		received <- struct{}{}
+	})
+
+	replyTo := pss.ReplyTo{PublicKey: &senderKey.PublicKey, Targets: pss.Targets{pss.Target{2}}}
+	_, err = sender.SendAndWait(context.Background(), topic, []byte("payload"), &stamper{}, &recipientKey.PublicKey, pss.Targets{pss.Target{1}}, replyTo, 100*time.Millisecond)
+	if !errors.Is(err, pss.ErrAckTimeout) {
+		t.Fatalf("got error %v, want %v", err, pss.ErrAckTimeout)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("reached timeout while waiting for message")
+	}
+}
+
+// TestSendAndWaitRejected verifies that the messages rejected by the handler
+// of the recipient are not acknowledged.
+func TestSendAndWaitRejected(t *testing.T) {
+	t.Parallel()
+
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := pss.New(senderKey, log.Noop)
+	recipient := pss.New(recipientKey, log.Noop)
+	t.Cleanup(func() {
+		_ = sender.Close()
+		_ = recipient.Close()
+	})
+
+	sender.SetPushSyncer(pushsyncmock.New(func(_ context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		recipient.TryUnwrap(chunk)
+		return nil, nil
+	}))
+	var acks atomic.Int32
+	recipient.SetPushSyncer(pushsyncmock.New(func(_ context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		acks.Add(1)
+		sender.TryUnwrap(chunk)
+		return nil, nil
+	}))
+	recipient.SetAckStamper(func() (postage.Stamper, func() error, error) {
+		return &stamper{}, func() error { return nil }, nil
+	})
+
+	topic := pss.NewTopic("topic")
+	received := make(chan struct{}, 1)
+	recipient.Register(topic, func(ctx context.Context, _ []byte) {
+		pss.Reject(ctx)
+		received <- struct{}{}
+	})
+
+	replyTo := pss.ReplyTo{PublicKey: &senderKey.PublicKey, Targets: pss.Targets{pss.Target{2}}}
+	_, err = sender.SendAndWait(context.Background(), topic, []byte("payload"), &stamper{}, &recipientKey.PublicKey, pss.Targets{pss.Target{1}}, replyTo, 200*time.Millisecond)
+	if !errors.Is(err, pss.ErrAckTimeout) {
+		t.Fatalf("got error %v, want %v", err, pss.ErrAckTimeout)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("reached timeout while waiting for message")
+	}
+	if n := acks.Load(); n != 0 {
+		t.Fatalf("got %d acknowledgements, want none", n)
+	}
+}
+
 func waitHandlerCallback(t *testing.T, msgChan *chan struct{}, count int) {
 	t.Helper()
 
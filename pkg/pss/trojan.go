@@ -33,6 +33,9 @@ var (
 
 	// ErrVarLenTargets is returned when the given target list for a trojan chunk has addresses of different lengths
 	ErrVarLenTargets = errors.New("target list cannot have targets of different length")
+
+	// ErrInvalidSealedMessage is returned when a sealed message cannot be opened
+	ErrInvalidSealedMessage = errors.New("invalid sealed message")
 )
 
 // Topic is the type that classifies messages, allows client applications to subscribe to
@@ -55,6 +58,8 @@ type Targets []Target
 const (
 	// MaxPayloadSize is the maximum allowed payload size for the Message type, in bytes
 	MaxPayloadSize = swarm.ChunkSize - 3*swarm.HashSize
+	// SealedSize is the size of the messages sealed with Seal, in bytes
+	SealedSize = 33 + 4032
 )
 
 // Wrap creates a new serialised message with the given topic, payload and recipient public key used
@@ -69,25 +74,7 @@ const (
 // - integrity protection
 // message:
 func Wrap(ctx context.Context, topic Topic, msg []byte, recipient *ecdsa.PublicKey, targets Targets) (swarm.Chunk, error) {
-	if len(msg) > MaxPayloadSize {
-		return nil, ErrPayloadTooBig
-	}
-
-	// integrity protection and plaintext msg length encoding
-	integrity, err := crypto.LegacyKeccak256(msg)
-	if err != nil {
-		return nil, err
-	}
-	binary.BigEndian.PutUint16(integrity[:2], uint16(len(msg)))
-
-	// integrity segment prepended to msg
-	plaintext := append(integrity, msg...)
-	// use el-Gamal with ECDH on an ephemeral key, recipient public key and topic as salt
-	enc, ephpub, err := elgamal.NewEncryptor(recipient, topic[:], 4032, swarm.NewHasher)
-	if err != nil {
-		return nil, err
-	}
-	ciphertext, err := enc.Encrypt(plaintext)
+	enc, ephpub, ciphertext, err := encrypt(topic, msg, recipient)
 	if err != nil {
 		return nil, err
 	}
@@ -130,25 +117,93 @@ func Wrap(ctx context.Context, topic Topic, msg []byte, recipient *ecdsa.PublicK
 	return mine(ctx, odd, f)
 }
 
+// encrypt encrypts the message for the recipient with el-Gamal using
+// the topic as salt. The plaintext is prefixed with the message length
+// and the integrity protection, and padded to a fixed size.
+func encrypt(topic Topic, msg []byte, recipient *ecdsa.PublicKey) (encryption.Encrypter, *ecdsa.PublicKey, []byte, error) {
+	if len(msg) > MaxPayloadSize {
+		return nil, nil, nil, ErrPayloadTooBig
+	}
+
+	// integrity protection and plaintext msg length encoding
+	integrity, err := crypto.LegacyKeccak256(msg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	binary.BigEndian.PutUint16(integrity[:2], uint16(len(msg)))
+
+	// integrity segment prepended to msg
+	plaintext := append(integrity, msg...)
+	// use el-Gamal with ECDH on an ephemeral key, recipient public key and topic as salt
+	enc, ephpub, err := elgamal.NewEncryptor(recipient, topic[:], 4032, swarm.NewHasher)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ciphertext, err := enc.Encrypt(plaintext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return enc, ephpub, ciphertext, nil
+}
+
+// Seal encrypts the message for the recipient like Wrap, but without mining
+// a trojan chunk. The sealed message is the compressed ephemeral public key
+// followed by the ciphertext, SealedSize bytes in total.
+func Seal(topic Topic, msg []byte, recipient *ecdsa.PublicKey) ([]byte, error) {
+	_, ephpub, ciphertext, err := encrypt(topic, msg, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return append(crypto.EncodeSecp256k1PublicKey(ephpub), ciphertext...), nil
+}
+
+// OpenDH decrypts a message sealed with Seal, the shared secrets for el-Gamal
+// are generated by dh.
+func OpenDH(dh crypto.DH, topic Topic, sealed []byte) ([]byte, error) {
+	if len(sealed) != SealedSize {
+		return nil, ErrInvalidSealedMessage
+	}
+	pubkey, err := btcec.ParsePubKey(sealed[:33])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSealedMessage, err)
+	}
+	dec, err := elgamal.NewWithDH(dh, pubkey.ToECDSA(), topic[:], 0, swarm.NewHasher)
+	if err != nil {
+		return nil, err
+	}
+	return decryptAndCheck(dec, sealed[33:])
+}
+
 // Unwrap takes a chunk, a topic and a private key, and tries to decrypt the payload
 // using the private key, the prepended ephemeral public key for el-Gamal using the topic as salt
 func Unwrap(ctx context.Context, key *ecdsa.PrivateKey, chunk swarm.Chunk, topics []Topic) (topic Topic, msg []byte, err error) {
+	return UnwrapDH(ctx, crypto.NewDH(key), chunk, topics)
+}
+
+// UnwrapDH is like Unwrap, but the shared secrets for el-Gamal are generated by dh,
+// allowing the private key to be kept outside of the process
+func UnwrapDH(ctx context.Context, dh crypto.DH, chunk swarm.Chunk, topics []Topic) (topic Topic, msg []byte, err error) {
 	chunkData := chunk.Data()
 	pubkey, err := extractPublicKey(chunkData)
 	if err != nil {
 		return Topic{}, nil, err
 	}
 	hint := chunkData[:8]
+	if b, ok := dh.(crypto.BatchDH); ok {
+		if dh, err = sharedKeys(b, pubkey, topics); err != nil {
+			return Topic{}, nil, err
+		}
+	}
 	for _, topic = range topics {
 		select {
 		case <-ctx.Done():
 			return Topic{}, nil, ctx.Err()
 		default:
 		}
-		dec, err := matchTopic(key, pubkey, hint, topic[:])
+		dec, err := matchTopic(dh, pubkey, hint, topic[:])
 		if err != nil {
 			privk := crypto.Secp256k1PrivateKeyFromBytes(topic[:])
-			dec, err = matchTopic(privk, pubkey, hint, topic[:])
+			dec, err = matchTopic(crypto.NewDH(privk), pubkey, hint, topic[:])
 			if err != nil {
 				continue
 			}
@@ -163,6 +218,35 @@ func Unwrap(ctx context.Context, key *ecdsa.PrivateKey, chunk swarm.Chunk, topic
 	return topic, msg, nil
 }
 
+// sharedKeys generates the shared keys of the public key for all topics at
+// once and returns them as the dh serving the generated keys
+func sharedKeys(dh crypto.BatchDH, pubkey *ecdsa.PublicKey, topics []Topic) (crypto.DH, error) {
+	salts := make([][]byte, len(topics))
+	for i := range topics {
+		salts[i] = topics[i][:]
+	}
+	keys, err := dh.SharedKeys(pubkey, salts)
+	if err != nil {
+		return nil, err
+	}
+	generated := make(generatedKeys, len(keys))
+	for i, key := range keys {
+		generated[string(salts[i])] = key
+	}
+	return generated, nil
+}
+
+// generatedKeys is the dh serving the shared keys generated in advance by their salts
+type generatedKeys map[string][]byte
+
+func (g generatedKeys) SharedKey(_ *ecdsa.PublicKey, salt []byte) ([]byte, error) {
+	key, ok := g[string(salt)]
+	if !ok {
+		return nil, errors.New("no shared key for the salt")
+	}
+	return key, nil
+}
+
 // checkTargets verifies that the list of given targets is non empty and with elements of matching size
 func checkTargets(targets Targets) error {
 	if len(targets) == 0 {
@@ -270,8 +354,8 @@ func extractPublicKey(chunkData []byte) (*ecdsa.PublicKey, error) {
 // instead the hash of the secret key and the topic is matched against a hint (64 bit meta info)q
 // proper integrity check will disambiguate any potential collisions (false positives)
 // if the topic matches the hint, it returns the el-Gamal decryptor, otherwise an error
-func matchTopic(key *ecdsa.PrivateKey, pubkey *ecdsa.PublicKey, hint, topic []byte) (encryption.Decrypter, error) {
-	dec, err := elgamal.NewDecrypter(key, pubkey, topic, swarm.NewHasher)
+func matchTopic(dh crypto.DH, pubkey *ecdsa.PublicKey, hint, topic []byte) (encryption.Decrypter, error) {
+	dec, err := elgamal.NewWithDH(dh, pubkey, topic, 0, swarm.NewHasher)
 	if err != nil {
 		return nil, err
 	}
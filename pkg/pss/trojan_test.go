@@ -7,6 +7,7 @@ package pss_test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"testing"
 
 	"github.com/ethersphere/bee/v2/pkg/crypto"
@@ -42,6 +43,37 @@ func TestWrap(t *testing.T) {
 	}
 }
 
+func TestSeal(t *testing.T) {
+	t.Parallel()
+
+	topic := pss.NewTopic("topic")
+	msg := []byte("some payload")
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := pss.Seal(topic, msg, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != pss.SealedSize {
+		t.Fatalf("got sealed size %d, want %d", len(sealed), pss.SealedSize)
+	}
+
+	opened, err := pss.OpenDH(crypto.NewDH(key), topic, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, msg) {
+		t.Fatalf("message mismatch: expected %x, got %x", msg, opened)
+	}
+
+	if _, err := pss.OpenDH(crypto.NewDH(key), pss.NewTopic("other"), sealed); err == nil {
+		t.Fatal("message sealed on another topic should not be opened")
+	}
+}
+
 func TestUnwrap(t *testing.T) {
 	t.Parallel()
 
@@ -77,6 +109,58 @@ func TestUnwrap(t *testing.T) {
 	}
 }
 
+// batchDH counts the shared key generations of a batch DH.
+type batchDH struct {
+	crypto.DH
+	single, batches int
+}
+
+func (d *batchDH) SharedKey(pub *ecdsa.PublicKey, salt []byte) ([]byte, error) {
+	d.single++
+	return d.DH.SharedKey(pub, salt)
+}
+
+func (d *batchDH) SharedKeys(pub *ecdsa.PublicKey, salts [][]byte) ([][]byte, error) {
+	d.batches++
+	keys := make([][]byte, len(salts))
+	for i, salt := range salts {
+		key, err := d.DH.SharedKey(pub, salt)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+func TestUnwrapBatchDH(t *testing.T) {
+	t.Parallel()
+
+	topic := pss.NewTopic("topic")
+	msg := []byte("some payload")
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk, err := pss.Wrap(context.Background(), topic, msg, &key.PublicKey, newTargets(4, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dh := &batchDH{DH: crypto.NewDH(key)}
+	topics := []pss.Topic{pss.NewTopic("topic-1"), pss.NewTopic("topic-2"), topic}
+	unwrapTopic, unwrapMsg, err := pss.UnwrapDH(context.Background(), dh, chunk, topics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg, unwrapMsg) || unwrapTopic != topic {
+		t.Fatalf("got topic %x message %x, want topic %x message %x", unwrapTopic, unwrapMsg, topic, msg)
+	}
+	if dh.batches != 1 || dh.single != 0 {
+		t.Fatalf("got %d batches and %d single generations, want 1 batch", dh.batches, dh.single)
+	}
+}
+
 func TestUnwrapTopicEncrypted(t *testing.T) {
 	t.Parallel()
 
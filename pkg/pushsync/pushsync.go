@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/accounting"
@@ -21,6 +22,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	"github.com/ethersphere/bee/v2/pkg/pricer"
 	"github.com/ethersphere/bee/v2/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/skippeers"
 	"github.com/ethersphere/bee/v2/pkg/soc"
 	storage "github.com/ethersphere/bee/v2/pkg/storage"
@@ -57,6 +59,8 @@ var (
 	ErrNoPush            = errors.New("could not push chunk")
 	ErrOutOfDepthStoring = errors.New("storing outside of the neighborhood")
 	ErrWarmup            = errors.New("node warmup time not complete")
+
+	errInvalidReceipt = errors.New("invalid receipt")
 )
 
 type PushSyncer interface {
@@ -83,6 +87,7 @@ type PushSync struct {
 	store          Storer
 	topologyDriver topology.Driver
 	unwrap         func(swarm.Chunk)
+	gsocHandler    func(*soc.SOC)
 	logger         log.Logger
 	accounting     accounting.Interface
 	pricer         pricer.Interface
@@ -93,6 +98,7 @@ type PushSync struct {
 	fullNode       bool
 	skipList       *skippeers.List
 	warmupPeriod   time.Time
+	reputation     reputation.Recorder
 }
 
 type receiptResult struct {
@@ -110,6 +116,7 @@ func New(
 	topology topology.Driver,
 	fullNode bool,
 	unwrap func(swarm.Chunk),
+	gsocHandler func(*soc.SOC),
 	validStamp postage.ValidStampFn,
 	logger log.Logger,
 	accounting accounting.Interface,
@@ -126,6 +133,7 @@ func New(
 		topologyDriver: topology,
 		fullNode:       fullNode,
 		unwrap:         unwrap,
+		gsocHandler:    gsocHandler,
 		logger:         logger.WithName(loggerName).Register(),
 		accounting:     accounting,
 		pricer:         pricer,
@@ -217,13 +225,22 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 	chunk.WithStamp(stamp)
 
+	var sch *soc.SOC
 	if cac.Valid(chunk) {
 		go ps.unwrap(chunk)
-	} else if !soc.Valid(chunk) {
+	} else if soc.Valid(chunk) {
+		s, err := soc.FromChunk(chunk)
+		if err != nil {
+			return swarm.ErrInvalidChunk
+		}
+		if s.IsGraffiti() {
+			sch = s
+		}
+	} else {
 		return swarm.ErrInvalidChunk
 	}
 
-	price := ps.pricer.Price(chunkAddress)
+	price := ps.pricer.Price(p.Address, chunkAddress)
 
 	store := func(ctx context.Context) error {
 		ps.metrics.Storer.Inc()
@@ -238,6 +255,12 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 			return fmt.Errorf("reserve put: %w", err)
 		}
 
+		if sch != nil {
+			// the single-owner chunk arrived to its neighbourhood,
+			// deliver it to the graffiti subscribers
+			go ps.gsocHandler(sch)
+		}
+
 		signature, err := ps.signer.Sign(chunkToPut.Address().Bytes())
 		if err != nil {
 			return fmt.Errorf("receipt signature: %w", err)
@@ -427,9 +450,20 @@ func (ps *PushSync) pushToClosest(ctx context.Context, ch swarm.Chunk, origin bo
 			ps.measurePushPeer(result.pushTime, result.err)
 
 			if result.err == nil {
+				ps.recordSignal(result.peer, reputation.SignalSuccess)
 				return result.receipt, nil
 			}
 
+			switch {
+			case errors.Is(result.err, errInvalidReceipt):
+				ps.recordSignal(result.peer, reputation.SignalFlag)
+			case errors.Is(result.err, context.Canceled),
+				errors.Is(result.err, context.DeadlineExceeded),
+				errors.Is(result.err, os.ErrDeadlineExceeded):
+				// the peer is not penalised for the timeouts
+			default:
+				ps.recordSignal(result.peer, reputation.SignalFailure)
+			}
 			ps.metrics.TotalFailedSendAttempts.Inc()
 			ps.logger.Debug("could not push to peer", "chunk_address", ch.Address(), "peer_address", result.peer, "error", result.err)
 
@@ -543,7 +577,7 @@ func (ps *PushSync) pushChunkToPeer(ctx context.Context, peer swarm.Address, ch
 	}
 
 	if !ch.Address().Equal(swarm.NewAddress(rec.Address)) {
-		return nil, fmt.Errorf("invalid receipt. chunk %s, peer %s", ch.Address(), peer)
+		return nil, fmt.Errorf("%w: chunk %s, peer %s", errInvalidReceipt, ch.Address(), peer)
 	}
 
 	return &rec, nil
@@ -586,6 +620,18 @@ func (s *PushSync) Close() error {
 	return s.skipList.Close()
 }
 
+// SetReputation makes the service record the outcome of the pushes
+// as peer quality signals.
+func (ps *PushSync) SetReputation(r reputation.Recorder) {
+	ps.reputation = r
+}
+
+func (ps *PushSync) recordSignal(peer swarm.Address, sig reputation.Signal) {
+	if ps.reputation != nil {
+		ps.reputation.Record(peer, sig)
+	}
+}
+
 func (ps *PushSync) warmedUp() bool {
 	return time.Now().After(ps.warmupPeriod)
 }
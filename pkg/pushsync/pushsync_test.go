@@ -22,9 +22,12 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/v2/pkg/p2p/streamtest"
+	postagetesting "github.com/ethersphere/bee/v2/pkg/postage/testing"
 	pricermock "github.com/ethersphere/bee/v2/pkg/pricer/mock"
 	"github.com/ethersphere/bee/v2/pkg/pushsync"
 	"github.com/ethersphere/bee/v2/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	soctesting "github.com/ethersphere/bee/v2/pkg/soc/testing"
 	storage "github.com/ethersphere/bee/v2/pkg/storage"
 	testingc "github.com/ethersphere/bee/v2/pkg/storage/testing"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
@@ -276,6 +279,44 @@ func TestPushChunkToClosest(t *testing.T) {
 	}
 }
 
+// TestPushChunkToClosestGsoc tests that a single-owner chunk that arrives to
+// the node which stores it is delivered to the graffiti subscribers.
+func TestPushChunkToClosestGsoc(t *testing.T) {
+	t.Parallel()
+
+	sch := soctesting.GenerateMockGraffitiSOC(t, []byte("graffiti"))
+	chunk := sch.Chunk().WithStamp(postagetesting.MustNewStamp())
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	gsocC := make(chan *soc.SOC, 1)
+	psPeer, _ := createPushSyncNodeWithGsoc(t, closestPeer, defaultPrices, nil, nil, func(s *soc.SOC) { gsocC <- s }, defaultSigner, accountingmock.NewAccounting(), log.Noop, mock.WithClosestPeerErr(topology.ErrWantSelf))
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()), streamtest.WithBaseAddr(pivotNode))
+	psPivot, _, _ := createPushSyncNode(t, pivotNode, defaultPrices, recorder, nil, defaultSigner, mock.WithClosestPeer(closestPeer))
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-gsocC:
+		addr, err := got.Address()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !addr.Equal(chunk.Address()) {
+			t.Fatalf("got gsoc address %s, want %s", addr, chunk.Address())
+		}
+		if !bytes.Equal(got.WrappedChunk().Data(), sch.WrappedChunk.Data()) {
+			t.Fatal("unexpected wrapped chunk")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("gsoc handler was not called")
+	}
+}
+
 func TestPushChunkToNextClosest(t *testing.T) {
 	t.Parallel()
 	t.Skip("flaky test")
@@ -822,6 +863,22 @@ func createPushSyncNodeWithAccounting(
 	acct accounting.Interface,
 	logger log.Logger,
 	mockOpts ...mock.Option,
+) (*pushsync.PushSync, *testStorer) {
+	t.Helper()
+	return createPushSyncNodeWithGsoc(t, addr, prices, recorder, unwrap, nil, signer, acct, logger, mockOpts...)
+}
+
+func createPushSyncNodeWithGsoc(
+	t *testing.T,
+	addr swarm.Address,
+	prices pricerParameters,
+	recorder *streamtest.Recorder,
+	unwrap func(swarm.Chunk),
+	gsocHandler func(*soc.SOC),
+	signer crypto.Signer,
+	acct accounting.Interface,
+	logger log.Logger,
+	mockOpts ...mock.Option,
 ) (*pushsync.PushSync, *testStorer) {
 	t.Helper()
 	storer := &testStorer{
@@ -836,12 +893,15 @@ func createPushSyncNodeWithAccounting(
 	if unwrap == nil {
 		unwrap = func(swarm.Chunk) {}
 	}
+	if gsocHandler == nil {
+		gsocHandler = func(*soc.SOC) {}
+	}
 
 	validStamp := func(ch swarm.Chunk) (swarm.Chunk, error) {
 		return ch, nil
 	}
 
-	ps := pushsync.New(addr, blockHash.Bytes(), recorderDisconnecter, storer, mockTopology, true, unwrap, validStamp, logger, acct, mockPricer, signer, nil, -1)
+	ps := pushsync.New(addr, blockHash.Bytes(), recorderDisconnecter, storer, mockTopology, true, unwrap, gsocHandler, validStamp, logger, acct, mockPricer, signer, nil, -1)
 	t.Cleanup(func() { ps.Close() })
 
 	return ps, storer
@@ -0,0 +1,17 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation
+
+import "time"
+
+func (s *Service) SetNow(f func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = f
+}
+
+func (s *Service) Flush() error {
+	return s.flush()
+}
@@ -0,0 +1,300 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reputation merges the peer quality signals of the node, such as
+// failed retrievals and pushes, blocklistings and health checks, into a
+// single score per peer. The scores decay exponentially towards zero and
+// are persisted in the state store so that they survive restarts.
+package reputation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "reputation"
+
+const (
+	keyPrefix = "reputation_"
+
+	defaultHalfLife      = 6 * time.Hour
+	defaultBadThreshold  = -10
+	defaultFlushInterval = time.Minute
+
+	maxScore = 100
+	// minScore is the absolute score under which the peer entry is dropped.
+	minScore = 0.01
+)
+
+// Signal is a peer quality observation.
+type Signal int
+
+const (
+	// SignalSuccess is recorded when the peer served a request.
+	SignalSuccess Signal = iota
+	// SignalFailure is recorded when a request to the peer failed.
+	SignalFailure
+	// SignalHealthy is recorded when the peer passed a health check.
+	SignalHealthy
+	// SignalUnhealthy is recorded when the peer failed a health check.
+	SignalUnhealthy
+	// SignalFlag is recorded when the peer is flagged for misbehaviour.
+	SignalFlag
+	// SignalBlocklist is recorded when the peer is blocklisted.
+	SignalBlocklist
+)
+
+var weights = map[Signal]float64{
+	SignalSuccess:   1,
+	SignalFailure:   -1,
+	SignalHealthy:   1,
+	SignalUnhealthy: -2,
+	SignalFlag:      -5,
+	SignalBlocklist: -20,
+}
+
+func (s Signal) String() string {
+	switch s {
+	case SignalSuccess:
+		return "success"
+	case SignalFailure:
+		return "failure"
+	case SignalHealthy:
+		return "healthy"
+	case SignalUnhealthy:
+		return "unhealthy"
+	case SignalFlag:
+		return "flag"
+	case SignalBlocklist:
+		return "blocklist"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// Recorder records the peer quality signals.
+type Recorder interface {
+	Record(peer swarm.Address, s Signal)
+}
+
+// Scorer reports the reputation of the peers.
+type Scorer interface {
+	// Score returns the decayed score of the peer. Unknown peers score zero.
+	Score(peer swarm.Address) float64
+	// Bad reports whether the score of the peer is below the bad peer threshold.
+	Bad(peer swarm.Address) bool
+}
+
+// Interface is the reputation service.
+type Interface interface {
+	Recorder
+	Scorer
+}
+
+// Options configure the reputation service.
+type Options struct {
+	// HalfLife is the time in which a score decays to half of its value.
+	HalfLife time.Duration
+	// BadThreshold is the score under which a peer is considered bad.
+	BadThreshold float64
+	// FlushInterval is the interval of persisting the changed scores.
+	FlushInterval time.Duration
+}
+
+type entry struct {
+	Score   float64 `json:"score"`
+	Updated int64   `json:"updated"` // unix nanoseconds
+}
+
+var _ Interface = (*Service)(nil)
+
+// Service keeps the scores of the peers.
+type Service struct {
+	store  storage.StateStorer
+	logger log.Logger
+	opt    Options
+	now    func() time.Time
+
+	mu    sync.Mutex
+	peers map[string]*entry
+	dirty map[string]struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New loads the persisted scores and starts persisting the changes.
+func New(store storage.StateStorer, logger log.Logger, o Options) (*Service, error) {
+	if o.HalfLife <= 0 {
+		o.HalfLife = defaultHalfLife
+	}
+	if o.BadThreshold == 0 {
+		o.BadThreshold = defaultBadThreshold
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaultFlushInterval
+	}
+
+	s := &Service{
+		store:  store,
+		logger: logger.WithName(loggerName).Register(),
+		opt:    o,
+		now:    time.Now,
+		peers:  make(map[string]*entry),
+		dirty:  make(map[string]struct{}),
+		quit:   make(chan struct{}),
+	}
+
+	err := store.Iterate(keyPrefix, func(key, val []byte) (bool, error) {
+		addr, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), keyPrefix))
+		if err != nil {
+			return true, fmt.Errorf("parse key %q: %w", key, err)
+		}
+		e := new(entry)
+		if err := json.Unmarshal(val, e); err != nil {
+			return true, fmt.Errorf("unmarshal score of %s: %w", addr, err)
+		}
+		s.peers[addr.ByteString()] = e
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load scores: %w", err)
+	}
+
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	return s, nil
+}
+
+// Record adds the weight of the signal to the decayed score of the peer.
+func (s *Service) Record(peer swarm.Address, sig Signal) {
+	w, ok := weights[sig]
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.peers[peer.ByteString()]
+	if !ok {
+		e = new(entry)
+		s.peers[peer.ByteString()] = e
+	}
+	e.Score = math.Max(-maxScore, math.Min(maxScore, s.decay(e, now)+w))
+	e.Updated = now.UnixNano()
+	s.dirty[peer.ByteString()] = struct{}{}
+}
+
+// Score implements the Scorer interface.
+func (s *Service) Score(peer swarm.Address) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.peers[peer.ByteString()]
+	if !ok {
+		return 0
+	}
+	return s.decay(e, s.now())
+}
+
+// Bad implements the Scorer interface.
+func (s *Service) Bad(peer swarm.Address) bool {
+	return s.Score(peer) < s.opt.BadThreshold
+}
+
+// decay returns the score of the entry decayed until now.
+func (s *Service) decay(e *entry, now time.Time) float64 {
+	elapsed := now.Sub(time.Unix(0, e.Updated))
+	if elapsed <= 0 {
+		return e.Score
+	}
+	return e.Score * math.Exp2(-float64(elapsed)/float64(s.opt.HalfLife))
+}
+
+func (s *Service) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opt.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				s.logger.Warning("persisting peer scores failed", "error", err)
+			}
+		}
+	}
+}
+
+// flush persists the changed scores and drops the decayed ones. The
+// changes are taken under the lock and written to the store outside of it,
+// the failed writes of the kept scores are retried on the next flush.
+func (s *Service) flush() error {
+	type change struct {
+		key   string
+		entry *entry // nil if the score is dropped
+	}
+
+	s.mu.Lock()
+	var changes []change
+	now := s.now()
+	for k, e := range s.peers {
+		decayed := math.Abs(s.decay(e, now)) < minScore
+		if _, ok := s.dirty[k]; !ok && !decayed {
+			continue
+		}
+		delete(s.dirty, k)
+		if decayed {
+			delete(s.peers, k)
+			changes = append(changes, change{key: k})
+			continue
+		}
+		c := *e
+		changes = append(changes, change{key: k, entry: &c})
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, c := range changes {
+		key := keyPrefix + swarm.NewAddress([]byte(c.key)).String()
+		var err error
+		if c.entry == nil {
+			err = s.store.Delete(key)
+		} else {
+			err = s.store.Put(key, c.entry)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			s.mu.Lock()
+			if _, ok := s.peers[c.key]; ok {
+				s.dirty[c.key] = struct{}{}
+			}
+			s.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close persists the changed scores.
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return s.flush()
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
+	"github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestReputation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		store = mock.NewStateStore()
+		now   = time.Unix(1700000000, 0)
+		peer  = swarm.RandAddress(t)
+		other = swarm.RandAddress(t)
+		opts  = reputation.Options{HalfLife: time.Hour, BadThreshold: -10}
+	)
+
+	s, err := reputation.New(store, log.Noop, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetNow(func() time.Time { return now })
+
+	if score := s.Score(peer); score != 0 {
+		t.Fatalf("got score %v of unknown peer, want 0", score)
+	}
+
+	s.Record(peer, reputation.SignalSuccess)
+	s.Record(peer, reputation.SignalBlocklist)
+	s.Record(other, reputation.SignalHealthy)
+
+	if score := s.Score(peer); score != -19 {
+		t.Fatalf("got score %v, want -19", score)
+	}
+	if !s.Bad(peer) {
+		t.Fatal("want bad peer")
+	}
+	if s.Bad(other) {
+		t.Fatal("want good peer")
+	}
+
+	t.Run("decay", func(t *testing.T) {
+		s.SetNow(func() time.Time { return now.Add(time.Hour) })
+
+		if score := s.Score(peer); score != -9.5 {
+			t.Fatalf("got score %v, want -9.5", score)
+		}
+		if s.Bad(peer) {
+			t.Fatal("want decayed score above the bad threshold")
+		}
+	})
+
+	t.Run("persistence", func(t *testing.T) {
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := reputation.New(store, log.Noop, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		s.SetNow(func() time.Time { return now.Add(time.Hour) })
+
+		if score := s.Score(peer); score != -9.5 {
+			t.Fatalf("got score %v after restart, want -9.5", score)
+		}
+		if score := s.Score(other); score != 0.5 {
+			t.Fatalf("got score %v after restart, want 0.5", score)
+		}
+
+		// decayed scores are dropped
+		s.SetNow(func() time.Time { return now.Add(24 * time.Hour) })
+		if err := s.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if score := s.Score(other); math.Abs(score) > 0 {
+			t.Fatalf("got score %v of dropped peer, want 0", score)
+		}
+	})
+}
+
+type failingStore struct {
+	storage.StateStorer
+	fail bool
+}
+
+func (s *failingStore) Put(key string, i interface{}) error {
+	if s.fail {
+		return errors.New("put failed")
+	}
+	return s.StateStorer.Put(key, i)
+}
+
+func TestFlushRetry(t *testing.T) {
+	t.Parallel()
+
+	store := &failingStore{StateStorer: mock.NewStateStore(), fail: true}
+	peer := swarm.RandAddress(t)
+
+	s, err := reputation.New(store, log.Noop, reputation.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	s.Record(peer, reputation.SignalFlag)
+	if err := s.Flush(); err == nil {
+		t.Fatal("want flush error")
+	}
+
+	store.fail = false
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var e struct {
+		Score float64 `json:"score"`
+	}
+	if err := store.Get("reputation_"+peer.String(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Score != -5 {
+		t.Fatalf("got persisted score %v, want -5", e.Score)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cache provides a resolver that keeps the results of another
+// resolver for a limited time, so that the repeated resolutions of a name
+// do not reach the name resolution services.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"resenje.org/singleflight"
+)
+
+// Make sure Resolver implements the resolver interfaces.
+var (
+	_ resolver.Interface       = (*Resolver)(nil)
+	_ resolver.ReverseResolver = (*Resolver)(nil)
+)
+
+// Options are the parameters of the cache.
+type Options struct {
+	TTL         time.Duration // lifetime of the resolved names
+	NegativeTTL time.Duration // lifetime of the names that were not found, zero disables the negative caching
+	Capacity    int           // maximal number of the cached names
+}
+
+// DefaultOptions returns the default parameters of the cache.
+func DefaultOptions() Options {
+	return Options{
+		TTL:         5 * time.Minute,
+		NegativeTTL: time.Minute,
+		Capacity:    10_000,
+	}
+}
+
+type entry struct {
+	addr    swarm.Address
+	err     error
+	expires time.Time
+}
+
+// Resolver caches the resolutions of the wrapped resolver. Only the names
+// that were not found are cached negatively, the other failures, like an
+// unavailable service, are retried on the next resolution.
+type Resolver struct {
+	resolver resolver.Interface
+	opts     Options
+	entries  *lru.Cache[string, entry]
+	group    singleflight.Group[string, swarm.Address]
+	metrics  metrics
+	now      func() time.Time
+}
+
+// New returns a new Resolver that caches the resolutions of the resolver.
+func New(r resolver.Interface, o Options) (*Resolver, error) {
+	entries, err := lru.New[string, entry](o.Capacity)
+	if err != nil {
+		return nil, fmt.Errorf("resolver cache: %w", err)
+	}
+	return &Resolver{
+		resolver: r,
+		opts:     o,
+		entries:  entries,
+		metrics:  newMetrics(),
+		now:      time.Now,
+	}, nil
+}
+
+// Resolve implements the resolver.Interface interface. The concurrent
+// resolutions of a name that is not cached share a single resolution.
+func (r *Resolver) Resolve(name string) (swarm.Address, error) {
+	if e, ok := r.entries.Get(name); ok && r.now().Before(e.expires) {
+		if e.err != nil {
+			r.metrics.NegativeHits.Inc()
+			return swarm.ZeroAddress, e.err
+		}
+		r.metrics.Hits.Inc()
+		return e.addr, nil
+	}
+	r.metrics.Misses.Inc()
+
+	addr, _, err := r.group.Do(context.Background(), name, func(context.Context) (swarm.Address, error) {
+		addr, err := r.resolver.Resolve(name)
+		switch {
+		case err == nil:
+			r.entries.Add(name, entry{addr: addr, expires: r.now().Add(r.opts.TTL)})
+		case errors.Is(err, resolver.ErrNotFound) && r.opts.NegativeTTL > 0:
+			r.entries.Add(name, entry{err: err, expires: r.now().Add(r.opts.NegativeTTL)})
+		default:
+			r.entries.Remove(name)
+		}
+		return addr, err
+	})
+	return addr, err
+}
+
+// ReverseResolve implements the resolver.ReverseResolver interface. The
+// reverse resolutions are not cached and are passed to the wrapped resolver.
+func (r *Resolver) ReverseResolve(addr swarm.Address) ([]string, error) {
+	rr, ok := r.resolver.(resolver.ReverseResolver)
+	if !ok {
+		return nil, resolver.ErrNotFound
+	}
+	return rr.ReverseResolve(addr)
+}
+
+// Close closes the wrapped resolver.
+func (r *Resolver) Close() error {
+	return r.resolver.Close()
+}
+
+func (r *Resolver) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(r.metrics)
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/resolver/cache"
+	"github.com/ethersphere/bee/v2/pkg/resolver/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+var (
+	addr  = swarm.MustParseHexAddress("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	start = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+)
+
+// countingResolver counts the resolutions of the names.
+type countingResolver struct {
+	mu    sync.Mutex
+	calls map[string]int
+	names map[string]swarm.Address
+	err   error
+}
+
+func (r *countingResolver) resolve(name string) (swarm.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls[name]++
+	if r.err != nil {
+		return swarm.ZeroAddress, r.err
+	}
+	a, ok := r.names[name]
+	if !ok {
+		return swarm.ZeroAddress, fmt.Errorf("name %s: %w", name, resolver.ErrNotFound)
+	}
+	return a, nil
+}
+
+func (r *countingResolver) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[name]
+}
+
+func (r *countingResolver) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func newTestResolver(t *testing.T, now *time.Time, opts ...mock.Option) (*cache.Resolver, *countingResolver) {
+	t.Helper()
+
+	counting := &countingResolver{
+		calls: make(map[string]int),
+		names: map[string]swarm.Address{"site.eth": addr},
+	}
+	r, err := cache.New(mock.NewResolver(append(opts, mock.WithResolveFunc(counting.resolve))...), cache.Options{
+		TTL:         time.Hour,
+		NegativeTTL: time.Minute,
+		Capacity:    10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetTimeNow(func() time.Time { return *now })
+	return r, counting
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	r, counting := newTestResolver(t, &now)
+
+	for i := 0; i < 3; i++ {
+		got, err := r.Resolve("site.eth")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(addr) {
+			t.Fatalf("got address %s, want %s", got, addr)
+		}
+	}
+	if n := counting.count("site.eth"); n != 1 {
+		t.Fatalf("got %d resolutions of a cached name, want 1", n)
+	}
+
+	// the names expire after the ttl
+	now = start.Add(time.Hour)
+	if _, err := r.Resolve("site.eth"); err != nil {
+		t.Fatal(err)
+	}
+	if n := counting.count("site.eth"); n != 2 {
+		t.Fatalf("got %d resolutions of an expired name, want 2", n)
+	}
+}
+
+func TestNegativeCaching(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	r, counting := newTestResolver(t, &now)
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Resolve("missing.eth"); !errors.Is(err, resolver.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+		}
+	}
+	if n := counting.count("missing.eth"); n != 1 {
+		t.Fatalf("got %d resolutions of a missing name, want 1", n)
+	}
+
+	// the missing names expire after the negative ttl
+	now = start.Add(time.Minute)
+	if _, err := r.Resolve("missing.eth"); !errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+	}
+	if n := counting.count("missing.eth"); n != 2 {
+		t.Fatalf("got %d resolutions of an expired missing name, want 2", n)
+	}
+
+	// the unavailable services are not cached
+	counting.setErr(resolver.ErrServiceNotAvailable)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Resolve("other.eth"); !errors.Is(err, resolver.ErrServiceNotAvailable) {
+			t.Fatalf("got error %v, want %v", err, resolver.ErrServiceNotAvailable)
+		}
+	}
+	if n := counting.count("other.eth"); n != 2 {
+		t.Fatalf("got %d resolutions with an unavailable service, want 2", n)
+	}
+}
+
+func TestReverseResolve(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	r, _ := newTestResolver(t, &now, mock.WithReverseResolveFunc(func(a swarm.Address) ([]string, error) {
+		if a.Equal(addr) {
+			return []string{"site.feed"}, nil
+		}
+		return nil, resolver.ErrNotFound
+	}))
+
+	// the resolved names are not reverse resolved from the cache
+	if _, err := r.Resolve("site.eth"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.ReverseResolve(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"site.feed"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got names %v, want %v", got, want)
+	}
+
+	if _, err := r.ReverseResolve(swarm.RandAddress(t)); !errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Hits         prometheus.Counter
+	NegativeHits prometheus.Counter
+	Misses       prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "resolver_cache"
+
+	return metrics{
+		Hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hits",
+			Help:      "Number of the names resolved from the cache.",
+		}),
+		NegativeHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "negative_hits",
+			Help:      "Number of the names found in the cache as not found.",
+		}),
+		Misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "misses",
+			Help:      "Number of the names resolved by the name resolution services.",
+		}),
+	}
+}
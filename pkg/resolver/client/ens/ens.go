@@ -27,8 +27,12 @@ const (
 // Address is the swarm bzz address.
 type Address = swarm.Address
 
-// Make sure Client implements the resolver.Client interface.
-var _ client.Interface = (*Client)(nil)
+// Make sure Client implements the resolver.Client and the
+// resolver.ReverseResolver interfaces.
+var (
+	_ client.Interface         = (*Client)(nil)
+	_ resolver.ReverseResolver = (*Client)(nil)
+)
 
 var (
 	// ErrFailedToConnect denotes that the resolver failed to connect to the
@@ -50,6 +54,7 @@ type Client struct {
 	ethCl        *ethclient.Client
 	connectFn    func(string, string) (*ethclient.Client, *goens.Registry, error)
 	resolveFn    func(*goens.Registry, common.Address, string) (string, error)
+	reverseFn    func(*goens.Registry, common.Address) (string, error)
 	registry     *goens.Registry
 }
 
@@ -62,6 +67,7 @@ func NewClient(endpoint string, opts ...Option) (client.Interface, error) {
 		endpoint:  endpoint,
 		connectFn: wrapDial,
 		resolveFn: wrapResolve,
+		reverseFn: wrapReverseResolve,
 	}
 
 	// Apply all options to the Client.
@@ -132,6 +138,28 @@ func (c *Client) Resolve(name string) (Address, error) {
 	return addr, nil
 }
 
+// ReverseResolve implements the resolver.ReverseResolver interface. ENS
+// keeps the reverse records only for the Ethereum accounts, so the address
+// must be an Ethereum address. Its primary name is read from the
+// addr.reverse record and returned only when the name resolves back to the
+// address.
+func (c *Client) ReverseResolve(addr Address) ([]string, error) {
+	if c.reverseFn == nil {
+		return nil, fmt.Errorf("reverseFn: %w", ErrNotImplemented)
+	}
+
+	if len(addr.Bytes()) != common.AddressLength {
+		return nil, fmt.Errorf("%s is not an ethereum address: %w", addr, resolver.ErrNotFound)
+	}
+
+	name, err := c.reverseFn(c.registry, common.BytesToAddress(addr.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrResolveFailed)
+	}
+
+	return []string{name}, nil
+}
+
 // Close closes the RPC connection with the client, terminating all unfinished
 // requests. If the connection is already closed, this call is a noop.
 func (c *Client) Close() error {
@@ -197,3 +225,48 @@ func wrapResolve(registry *goens.Registry, _ common.Address, name string) (strin
 
 	return addr, nil
 }
+
+func wrapReverseResolve(registry *goens.Registry, addr common.Address) (string, error) {
+	// Obtain the resolver of the reverse record of the address.
+	node := fmt.Sprintf("%x.addr.reverse", addr.Bytes())
+	resolverAddr, err := registry.ResolverAddress(node)
+	if err != nil {
+		return "", fmt.Errorf("reverse resolver address: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	if bytes.Equal(resolverAddr.Bytes(), goens.UnknownAddress.Bytes()) {
+		return "", fmt.Errorf("reverse record of %s: %w", addr, resolver.ErrNotFound)
+	}
+	reverseR, err := registry.Resolver(node)
+	if err != nil {
+		return "", fmt.Errorf("reverse resolver: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+
+	// Read out the primary name of the address.
+	nameHash, err := goens.NameHash(node)
+	if err != nil {
+		return "", fmt.Errorf("name hash: %w", err)
+	}
+	name, err := reverseR.Contract.Name(nil, nameHash)
+	if err != nil {
+		return "", fmt.Errorf("name: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	if name == "" {
+		return "", fmt.Errorf("reverse record of %s: %w", addr, resolver.ErrNotFound)
+	}
+
+	// Anyone can claim any name in a reverse record, so the name is valid
+	// only when it resolves back to the address.
+	ensR, err := registry.Resolver(name)
+	if err != nil {
+		return "", fmt.Errorf("resolver: %w: %w", err, resolver.ErrNotFound)
+	}
+	resolved, err := ensR.Address()
+	if err != nil {
+		return "", fmt.Errorf("address: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	if resolved != addr {
+		return "", fmt.Errorf("name %s resolves to %s: %w", name, resolved, resolver.ErrNotFound)
+	}
+
+	return name, nil
+}
@@ -6,6 +6,7 @@ package ens_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -210,3 +211,74 @@ func TestResolve(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseResolve(t *testing.T) {
+	t.Parallel()
+
+	testEthAddr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testAddr := swarm.NewAddress(testEthAddr.Bytes())
+
+	testCases := []struct {
+		desc      string
+		addr      swarm.Address
+		reverseFn func(*goens.Registry, common.Address) (string, error)
+		wantNames []string
+		wantErr   error
+	}{
+		{
+			desc:    "nil reverse resolve function",
+			addr:    testAddr,
+			wantErr: ens.ErrNotImplemented,
+		},
+		{
+			desc: "swarm reference",
+			addr: swarm.MustParseHexAddress("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+			reverseFn: func(*goens.Registry, common.Address) (string, error) {
+				return "site.eth", nil
+			},
+			wantErr: resolver.ErrNotFound,
+		},
+		{
+			desc: "no reverse record",
+			addr: testAddr,
+			reverseFn: func(*goens.Registry, common.Address) (string, error) {
+				return "", resolver.ErrNotFound
+			},
+			wantErr: resolver.ErrNotFound,
+		},
+		{
+			desc: "primary name",
+			addr: testAddr,
+			reverseFn: func(_ *goens.Registry, a common.Address) (string, error) {
+				if a != testEthAddr {
+					return "", errors.New("invalid address")
+				}
+				return "site.eth", nil
+			},
+			wantNames: []string{"site.eth"},
+		},
+	}
+	for _, tC := range testCases {
+		tC := tC
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cl, err := ens.NewClient("example.com",
+				ens.WithConnectFunc(func(endpoint, contractAddr string) (*ethclient.Client, *goens.Registry, error) {
+					return nil, nil, nil
+				}),
+				ens.WithReverseResolveFunc(tC.reverseFn),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names, err := cl.(resolver.ReverseResolver).ReverseResolve(tC.addr)
+			if !errors.Is(err, tC.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tC.wantErr)
+			}
+			if !reflect.DeepEqual(names, tC.wantNames) {
+				t.Fatalf("got names %v, want %v", names, tC.wantNames)
+			}
+		})
+	}
+}
@@ -25,3 +25,10 @@ func WithResolveFunc(fn func(registry *goens.Registry, addr common.Address, inpu
 		c.resolveFn = fn
 	}
 }
+
+// WithReverseResolveFunc will set the ReverseResolve function implementation.
+func WithReverseResolveFunc(fn func(registry *goens.Registry, addr common.Address) (string, error)) Option {
+	return func(c *Client) {
+		c.reverseFn = fn
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dnslink resolves the domain names with their DNSLink TXT records,
+// which serves the names without an Ethereum endpoint.
+package dnslink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	subdomain    = "_dnslink."
+	recordPrefix = "dnslink="
+)
+
+// namespaces are the DNSLink namespaces of the swarm references.
+var namespaces = []string{"/swarm/", "/bzz/"}
+
+// Make sure Resolver implements the resolver.Interface interface.
+var _ resolver.Interface = (*Resolver)(nil)
+
+// Resolver resolves the names with the TXT records in the form
+// dnslink=/swarm/<reference>. The record is looked up on the _dnslink
+// subdomain of the name first and on the name itself second.
+type Resolver struct {
+	timeout   time.Duration
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+}
+
+// New returns a new Resolver with the timeout of the DNS lookups.
+func New(timeout time.Duration) *Resolver {
+	return &Resolver{
+		timeout:   timeout,
+		lookupTXT: net.DefaultResolver.LookupTXT,
+	}
+}
+
+// Resolve implements the resolver.Interface interface.
+func (r *Resolver) Resolve(name string) (swarm.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	name = strings.TrimSuffix(name, ".")
+	for _, domain := range []string{subdomain + name, name} {
+		records, err := r.lookupTXT(ctx, domain)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return swarm.ZeroAddress, fmt.Errorf("lookup %s: %w: %w", domain, err, resolver.ErrServiceNotAvailable)
+		}
+		for _, record := range records {
+			if addr, ok, err := parseRecord(record); ok {
+				return addr, err
+			}
+		}
+	}
+	return swarm.ZeroAddress, fmt.Errorf("dnslink of %s: %w", name, resolver.ErrNotFound)
+}
+
+// Close implements the resolver.Interface interface.
+func (r *Resolver) Close() error {
+	return nil
+}
+
+// parseRecord returns the reference of the DNSLink record and reports
+// whether the record links to swarm. The reference may be followed by a
+// path, which is ignored.
+func parseRecord(record string) (swarm.Address, bool, error) {
+	link, ok := strings.CutPrefix(strings.TrimSpace(record), recordPrefix)
+	if !ok {
+		return swarm.ZeroAddress, false, nil
+	}
+	for _, ns := range namespaces {
+		ref, ok := strings.CutPrefix(link, ns)
+		if !ok {
+			continue
+		}
+		ref, _, _ = strings.Cut(ref, "/")
+		addr, err := swarm.ParseHexAddress(ref)
+		if err != nil {
+			return swarm.ZeroAddress, true, fmt.Errorf("parse dnslink %s: %w", record, resolver.ErrInvalidContentHash)
+		}
+		return addr, true, nil
+	}
+	return swarm.ZeroAddress, false, nil
+}
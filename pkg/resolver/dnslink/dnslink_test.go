@@ -0,0 +1,68 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnslink_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/resolver/dnslink"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const ref = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	records := map[string][]string{
+		"_dnslink.sub.example.org": {"v=spf1 -all", "dnslink=/swarm/" + ref},
+		"root.example.org":         {"dnslink=/bzz/" + ref + "/index.html"},
+		"ipfs.example.org":         {"dnslink=/ipfs/QmXoypizjW3WknFiJnKLwHCnL72vedxjQkDDP1mXWo6uco"},
+		"_dnslink.bad.example.org": {"dnslink=/swarm/notahash"},
+	}
+	r := dnslink.New(time.Second)
+	r.SetLookupTXT(func(_ context.Context, name string) ([]string, error) {
+		if name == "_dnslink.down.example.org" {
+			return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+		}
+		txt, ok := records[name]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return txt, nil
+	})
+
+	want := swarm.MustParseHexAddress(ref)
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{name: "sub.example.org"},
+		{name: "root.example.org."},
+		{name: "ipfs.example.org", err: resolver.ErrNotFound},
+		{name: "missing.example.org", err: resolver.ErrNotFound},
+		{name: "bad.example.org", err: resolver.ErrInvalidContentHash},
+		{name: "down.example.org", err: resolver.ErrServiceNotAvailable},
+	} {
+		got, err := r.Resolve(tc.name)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%s: got error %v, want %v", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s: got address %s, want %s", tc.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnslink
+
+import "context"
+
+func (r *Resolver) SetLookupTXT(f func(ctx context.Context, name string) ([]string, error)) {
+	r.lookupTXT = f
+}
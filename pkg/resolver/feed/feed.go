@@ -0,0 +1,158 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package feed resolves the names with a mapping of the names to the
+// references that is published on a swarm feed, which serves the names
+// without an Ethereum endpoint.
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	storage "github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// maxMappingSize bounds the size of the mapping document.
+const maxMappingSize = 1 << 20
+
+// Make sure Resolver implements the resolver interfaces.
+var (
+	_ resolver.Interface       = (*Resolver)(nil)
+	_ resolver.ReverseResolver = (*Resolver)(nil)
+)
+
+// Resolver resolves the names with the latest update of a sequence feed.
+// The update either contains the mapping directly or refers to it, where
+// the mapping is a JSON object of the names and the hex references, for
+// example {"site.swarm": "<reference>"}. The names are case insensitive.
+type Resolver struct {
+	lookup  feeds.Lookup
+	getter  storage.Getter
+	putter  storage.Putter
+	timeout time.Duration
+}
+
+// New returns a new Resolver of the feed. The getter and the putter are
+// used to read the mapping that the feed refers to.
+func New(factory feeds.Factory, getter storage.Getter, putter storage.Putter, feed *feeds.Feed, timeout time.Duration) (*Resolver, error) {
+	lookup, err := factory.NewLookup(feeds.Sequence, feed)
+	if err != nil {
+		return nil, fmt.Errorf("feed lookup: %w", err)
+	}
+	return &Resolver{
+		lookup:  lookup,
+		getter:  getter,
+		putter:  putter,
+		timeout: timeout,
+	}, nil
+}
+
+// Resolve implements the resolver.Interface interface.
+func (r *Resolver) Resolve(name string) (swarm.Address, error) {
+	mapping, err := r.mapping()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	addr, ok := mapping[strings.ToLower(name)]
+	if !ok {
+		return swarm.ZeroAddress, fmt.Errorf("name %s: %w", name, resolver.ErrNotFound)
+	}
+	return addr, nil
+}
+
+// ReverseResolve implements the resolver.ReverseResolver interface.
+func (r *Resolver) ReverseResolve(addr swarm.Address) ([]string, error) {
+	mapping, err := r.mapping()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, a := range mapping {
+		if a.Equal(addr) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("address %s: %w", addr, resolver.ErrNotFound)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Close implements the resolver.Interface interface.
+func (r *Resolver) Close() error {
+	return nil
+}
+
+// mapping returns the mapping of the latest update of the feed. The entries
+// with invalid references are ignored.
+func (r *Resolver) mapping() (map[string]swarm.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	ch, err := feeds.Latest(ctx, r.lookup, 0)
+	if err != nil {
+		return nil, fmt.Errorf("feed lookup: %w: %w", err, resolver.ErrServiceNotAvailable)
+	}
+	// the chunk is nil when the feed was never updated
+	if ch == nil {
+		return nil, fmt.Errorf("feed update: %w", resolver.ErrNotFound)
+	}
+	_, payload, err := feeds.FromChunk(ch)
+	if err != nil {
+		return nil, fmt.Errorf("feed update: %w: %w", err, resolver.ErrInvalidContentHash)
+	}
+
+	data := payload
+	if len(payload) == swarm.HashSize || len(payload) == swarm.HashSize*2 {
+		data, err = r.read(ctx, swarm.NewAddress(payload))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal mapping: %w: %w", err, resolver.ErrParse)
+	}
+	mapping := make(map[string]swarm.Address, len(raw))
+	for name, ref := range raw {
+		addr, err := swarm.ParseHexAddress(ref)
+		if err != nil {
+			continue
+		}
+		mapping[strings.ToLower(name)] = addr
+	}
+	return mapping, nil
+}
+
+// read returns the content of the mapping document at the reference.
+func (r *Resolver) read(ctx context.Context, ref swarm.Address) ([]byte, error) {
+	j, size, err := joiner.New(ctx, r.getter, r.putter, ref)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("mapping %s: %w: %w", ref, err, resolver.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mapping %s: %w: %w", ref, err, resolver.ErrServiceNotAvailable)
+	}
+	if size > maxMappingSize {
+		return nil, fmt.Errorf("mapping %s of %d bytes exceeds %d bytes: %w", ref, size, maxMappingSize, resolver.ErrParse)
+	}
+	data, err := io.ReadAll(j)
+	if err != nil {
+		return nil, fmt.Errorf("read mapping %s: %w: %w", ref, err, resolver.ErrServiceNotAvailable)
+	}
+	return data, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package feed_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/feeds"
+	"github.com/ethersphere/bee/v2/pkg/feeds/factory"
+	"github.com/ethersphere/bee/v2/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/resolver"
+	"github.com/ethersphere/bee/v2/pkg/resolver/feed"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	site  = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	other = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+var topic = []byte("names")
+
+// newTestResolver returns a resolver of a feed that is updated with the
+// payloads.
+func newTestResolver(t *testing.T, payloads ...func(*inmemchunkstore.ChunkStore) []byte) *feed.Resolver {
+	t.Helper()
+
+	ctx := context.Background()
+	store := inmemchunkstore.New()
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(pk)
+	owner, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updater, err := sequence.NewUpdater(store, signer, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, payload := range payloads {
+		if err := updater.Update(ctx, int64(i+1), payload(store)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := feed.New(factory.New(store), store, store, feeds.New(topic, owner), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func inline(mapping string) func(*inmemchunkstore.ChunkStore) []byte {
+	return func(*inmemchunkstore.ChunkStore) []byte { return []byte(mapping) }
+}
+
+func uploaded(t *testing.T, mapping string) func(*inmemchunkstore.ChunkStore) []byte {
+	t.Helper()
+
+	return func(store *inmemchunkstore.ChunkStore) []byte {
+		ctx := context.Background()
+		pipe := builder.NewPipelineBuilder(ctx, store, false, redundancy.NONE)
+		ref, err := builder.FeedPipeline(ctx, pipe, strings.NewReader(mapping))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref.Bytes()
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	mapping := `{"Site.swarm": "` + site + `", "www.site.swarm": "` + site + `", "other.swarm": "` + other + `", "bad.swarm": "zz"}`
+	for _, tc := range []struct {
+		name     string
+		resolver *feed.Resolver
+	}{
+		{"inline", newTestResolver(t, inline(`{}`), inline(mapping))},
+		{"reference", newTestResolver(t, inline(`{}`), uploaded(t, mapping))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := tc.resolver
+			got, err := r.Resolve("site.SWARM")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := swarm.MustParseHexAddress(site); !got.Equal(want) {
+				t.Fatalf("got address %s, want %s", got, want)
+			}
+			for _, name := range []string{"bad.swarm", "missing.swarm"} {
+				if _, err := r.Resolve(name); !errors.Is(err, resolver.ErrNotFound) {
+					t.Fatalf("%s: got error %v, want %v", name, err, resolver.ErrNotFound)
+				}
+			}
+
+			names, err := r.ReverseResolve(swarm.MustParseHexAddress(site))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"site.swarm", "www.site.swarm"}; !reflect.DeepEqual(names, want) {
+				t.Fatalf("got names %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := newTestResolver(t).Resolve("site.swarm"); !errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v from a feed without updates, want %v", err, resolver.ErrNotFound)
+	}
+	if _, err := newTestResolver(t, inline(`not json`)).Resolve("site.swarm"); !errors.Is(err, resolver.ErrParse) {
+		t.Fatalf("got error %v from an invalid mapping, want %v", err, resolver.ErrParse)
+	}
+}
@@ -11,13 +11,17 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/resolver/client/ens"
 )
 
-// Assure mock Resolver implements the Resolver interface.
-var _ resolver.Interface = (*Resolver)(nil)
+// Assure mock Resolver implements the Resolver interfaces.
+var (
+	_ resolver.Interface       = (*Resolver)(nil)
+	_ resolver.ReverseResolver = (*Resolver)(nil)
+)
 
 // Resolver is the mock Resolver implementation.
 type Resolver struct {
-	IsClosed    bool
-	resolveFunc func(string) (resolver.Address, error)
+	IsClosed           bool
+	resolveFunc        func(string) (resolver.Address, error)
+	reverseResolveFunc func(resolver.Address) ([]string, error)
 }
 
 // Option function sets the option on the mock Resolver.
@@ -42,6 +46,14 @@ func WithResolveFunc(f func(string) (resolver.Address, error)) Option {
 	}
 }
 
+// WithReverseResolveFunc will override the ReverseResolve function
+// implementation.
+func WithReverseResolveFunc(f func(resolver.Address) ([]string, error)) Option {
+	return func(r *Resolver) {
+		r.reverseResolveFunc = f
+	}
+}
+
 // Resolve implements the Resolver interface.
 func (r *Resolver) Resolve(name string) (resolver.Address, error) {
 	if r.resolveFunc != nil {
@@ -50,6 +62,14 @@ func (r *Resolver) Resolve(name string) (resolver.Address, error) {
 	return resolver.Address{}, fmt.Errorf("resolveFunc: %w", ens.ErrNotImplemented)
 }
 
+// ReverseResolve implements the ReverseResolver interface.
+func (r *Resolver) ReverseResolve(addr resolver.Address) ([]string, error) {
+	if r.reverseResolveFunc != nil {
+		return r.reverseResolveFunc(addr)
+	}
+	return nil, fmt.Errorf("reverseResolveFunc: %w", ens.ErrNotImplemented)
+}
+
 // Close implements the Resolver interface.
 func (r *Resolver) Close() error {
 	r.IsClosed = true
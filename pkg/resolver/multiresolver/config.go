@@ -5,6 +5,8 @@
 package multiresolver
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -12,6 +14,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// ErrInvalidFeed denotes passing an invalid feed string.
+var ErrInvalidFeed = errors.New("invalid feed")
+
 // Defined as per RFC 1034. For reference, see:
 // https://en.wikipedia.org/wiki/Domain_Name_System#cite_note-rfc1034-1
 const maxTLDLength = 63
@@ -82,3 +87,41 @@ func ParseConnectionStrings(cstrs []string) ([]ConnectionConfig, error) {
 
 	return res, nil
 }
+
+// FeedConfig contains the TLD, the owner and the topic of a feed that maps
+// the names to the references.
+type FeedConfig struct {
+	TLD   string
+	Owner common.Address
+	Topic []byte
+}
+
+// ParseFeedStrings will parse the feed strings in the form
+// [tld:]owner@topic, where the owner is an Ethereum address and the topic is
+// hex encoded. Returns first error found.
+func ParseFeedStrings(fstrs []string) ([]FeedConfig, error) {
+	res := make([]FeedConfig, 0, len(fstrs))
+
+	for _, fs := range fstrs {
+		var cfg FeedConfig
+		feed := fs
+		if tld, rest, ok := strings.Cut(fs, ":"); ok {
+			if len(tld) > maxTLDLength {
+				return nil, fmt.Errorf("tld %s: %w", tld, ErrTLDTooLong)
+			}
+			cfg.TLD, feed = tld, rest
+		}
+		owner, topic, ok := strings.Cut(feed, "@")
+		if !ok || !common.IsHexAddress(owner) {
+			return nil, fmt.Errorf("feed %s: owner: %w", fs, ErrInvalidFeed)
+		}
+		t, err := hex.DecodeString(strings.TrimPrefix(topic, "0x"))
+		if err != nil || len(t) == 0 {
+			return nil, fmt.Errorf("feed %s: topic: %w", fs, ErrInvalidFeed)
+		}
+		cfg.Owner, cfg.Topic = common.HexToAddress(owner), t
+		res = append(res, cfg)
+	}
+
+	return res, nil
+}
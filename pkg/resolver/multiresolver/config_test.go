@@ -6,8 +6,10 @@ package multiresolver_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/resolver/multiresolver"
 )
 
@@ -146,3 +148,32 @@ func TestParseConnectionStrings(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFeedStrings(t *testing.T) {
+	t.Parallel()
+
+	owner := "0x314159265dD8dbb310642f98f50C066173C1259b"
+
+	got, err := multiresolver.ParseFeedStrings([]string{owner + "@6e616d6573", "swarm:" + owner + "@0x0102"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []multiresolver.FeedConfig{
+		{Owner: common.HexToAddress(owner), Topic: []byte("names")},
+		{TLD: "swarm", Owner: common.HexToAddress(owner), Topic: []byte{1, 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	for _, fs := range []string{
+		owner,
+		"0x31@0102",
+		owner + "@names",
+		owner + "@",
+	} {
+		if _, err := multiresolver.ParseFeedStrings([]string{fs}); !errors.Is(err, multiresolver.ErrInvalidFeed) {
+			t.Errorf("%s: got error %v, want %v", fs, err, multiresolver.ErrInvalidFeed)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/ethersphere/bee/v2/pkg/log"
@@ -20,8 +21,11 @@ import (
 // loggerName is the tree path name of the logger for this package.
 const loggerName = "multiresolver"
 
-// Ensure MultiResolver implements Resolver interface.
-var _ resolver.Interface = (*MultiResolver)(nil)
+// Ensure MultiResolver implements the Resolver interfaces.
+var (
+	_ resolver.Interface       = (*MultiResolver)(nil)
+	_ resolver.ReverseResolver = (*MultiResolver)(nil)
+)
 
 var (
 	// ErrTLDTooLong denotes when a TLD in a name exceeds maximum length.
@@ -113,14 +117,17 @@ func WithDefaultCIDResolver() Option {
 }
 
 // PushResolver will push a new Resolver to the name resolution chain for the
-// given TLD. An empty TLD will push to the default resolver chain.
+// given TLD. An empty TLD will push to the default resolver chain. The TLD
+// may be given with or without the leading dot.
 func (mr *MultiResolver) PushResolver(tld string, r resolver.Interface) {
+	tld = chainKey(tld)
 	mr.resolvers[tld] = append(mr.resolvers[tld], r)
 }
 
 // PopResolver will pop the last reslover from the name resolution chain for the
 // given TLD. An empty TLD will pop from the default resolver chain.
 func (mr *MultiResolver) PopResolver(tld string) error {
+	tld = chainKey(tld)
 	l := len(mr.resolvers[tld])
 	if l == 0 {
 		return fmt.Errorf("tld %s: %w", tld, ErrResolverChainEmpty)
@@ -134,14 +141,14 @@ func (mr *MultiResolver) PopResolver(tld string) error {
 // TLD names should be prepended with a dot (eg ".tld"). An empty TLD will
 // return the number of resolvers in the default resolver chain.
 func (mr *MultiResolver) ChainCount(tld string) int {
-	return len(mr.resolvers[tld])
+	return len(mr.resolvers[chainKey(tld)])
 }
 
 // GetChain will return the resolution chain for a given TLD.
 // TLD names should be prepended with a dot (eg ".tld"). An empty TLD will
 // return all resolvers in the default resolver chain.
 func (mr *MultiResolver) GetChain(tld string) []resolver.Interface {
-	return mr.resolvers[tld]
+	return mr.resolvers[chainKey(tld)]
 }
 
 // Resolve will attempt to resolve a name to an address.
@@ -174,6 +181,45 @@ func (mr *MultiResolver) Resolve(name string) (addr resolver.Address, err error)
 	return addr, errs.ErrorOrNil()
 }
 
+// ReverseResolve will return the names of the address that are known to the
+// resolvers of all resolution chains which support the reverse resolution.
+// If none of them knows a name, the function will return resolver.ErrNotFound.
+func (mr *MultiResolver) ReverseResolve(addr resolver.Address) ([]string, error) {
+	names := make(map[string]struct{})
+	var errs *multierror.Error
+	for _, chain := range mr.resolvers {
+		for _, r := range chain {
+			rr, ok := r.(resolver.ReverseResolver)
+			if !ok {
+				continue
+			}
+			ns, err := rr.ReverseResolve(addr)
+			if err != nil {
+				if !errors.Is(err, resolver.ErrNotFound) {
+					errs = multierror.Append(errs, err)
+				}
+				continue
+			}
+			for _, n := range ns {
+				names[n] = struct{}{}
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		if err := errs.ErrorOrNil(); err != nil {
+			return nil, err
+		}
+		return nil, resolver.ErrNotFound
+	}
+	result := make([]string, 0, len(names))
+	for n := range names {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 // Close all will call Close on all resolvers in all resolver chains.
 func (mr *MultiResolver) Close() error {
 	var errs *multierror.Error
@@ -193,6 +239,15 @@ func getTLD(name string) string {
 	return path.Ext(strings.ToLower(name))
 }
 
+// chainKey returns the key of the resolution chain of the TLD, which is the
+// TLD with the leading dot as returned by getTLD.
+func chainKey(tld string) string {
+	if tld == "" || strings.HasPrefix(tld, ".") {
+		return strings.ToLower(tld)
+	}
+	return "." + strings.ToLower(tld)
+}
+
 func (mr *MultiResolver) connectENSClient(tld, address, endpoint string) {
 	log := mr.logger
 
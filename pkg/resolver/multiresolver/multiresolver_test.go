@@ -189,6 +189,13 @@ func TestResolve(t *testing.T) {
 				newUnregisteredNameResolver(),
 			},
 		},
+		{
+			// The TLD without the leading dot:
+			tld: "plain",
+			res: []resolver.Interface{
+				newOKResolver(addrAlt),
+			},
+		},
 	}
 
 	testCases := []struct {
@@ -230,6 +237,10 @@ func TestResolve(t *testing.T) {
 			wantAdr: swarm.ZeroAddress,
 			wantErr: errUnregisteredName,
 		},
+		{
+			name:    "name.plain",
+			wantAdr: addrAlt,
+		},
 	}
 
 	// Load the test fixture.
@@ -273,3 +284,38 @@ func TestResolve(t *testing.T) {
 		}
 	})
 }
+
+func TestReverseResolve(t *testing.T) {
+	t.Parallel()
+
+	addr := newAddr("aaaabbbbccccdddd")
+	reverse := func(names ...string) resolver.Interface {
+		return mock.NewResolver(mock.WithReverseResolveFunc(func(a Address) ([]string, error) {
+			if !a.Equal(addr) {
+				return nil, resolver.ErrNotFound
+			}
+			return names, nil
+		}))
+	}
+
+	mr := multiresolver.NewMultiResolver()
+	mr.PushResolver("", reverse("b.swarm"))
+	mr.PushResolver(".eth", reverse("a.eth", "b.swarm"))
+
+	got, err := mr.ReverseResolve(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.eth", "b.swarm"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got names %v, want %v", got, want)
+	}
+	if _, err := mr.ReverseResolve(newAddr("eeeeffff")); !errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+	}
+
+	// the failures are reported when no name is found
+	mr.PushResolver("", mock.NewResolver())
+	if _, err := mr.ReverseResolve(newAddr("eeeeffff")); err == nil || errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v, want the resolver failure", err)
+	}
+}
@@ -30,3 +30,10 @@ type Interface interface {
 	Resolve(url string) (Address, error)
 	io.Closer
 }
+
+// ReverseResolver can look up the names that resolve to an address.
+type ReverseResolver interface {
+	// ReverseResolve returns the known names of the address or ErrNotFound
+	// when there are none.
+	ReverseResolve(addr Address) ([]string, error)
+}
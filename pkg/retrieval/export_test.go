@@ -18,3 +18,8 @@ func (s *Service) Handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) er
 func (s *Service) ClosestPeer(addr swarm.Address, skipPeers []swarm.Address, allowUpstream bool) (swarm.Address, error) {
 	return s.closestPeer(addr, skipPeers, allowUpstream)
 }
+
+var (
+	ErrProtocol  = errProtocol
+	Misbehaviour = misbehaviour
+)
@@ -12,6 +12,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/accounting"
@@ -20,6 +22,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	"github.com/ethersphere/bee/v2/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/v2/pkg/pricer"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	pb "github.com/ethersphere/bee/v2/pkg/retrieval/pb"
 	"github.com/ethersphere/bee/v2/pkg/skippeers"
 	"github.com/ethersphere/bee/v2/pkg/soc"
@@ -44,6 +47,9 @@ const (
 
 var _ Interface = (*Service)(nil)
 
+// errProtocol is returned when the peer violates the retrieval protocol.
+var errProtocol = errors.New("protocol violation")
+
 type Interface interface {
 	// RetrieveChunk retrieves a chunk from the network using the retrieval protocol.
 	// it takes as parameters a context, a chunk address to retrieve (content-addressed or single-owner) and
@@ -78,6 +84,8 @@ type Service struct {
 	tracer        *tracing.Tracer
 	caching       bool
 	errSkip       *skippeers.List
+	reputation    reputation.Recorder
+	active        atomic.Int64 // number of the requests being served
 }
 
 func New(
@@ -271,6 +279,7 @@ func (s *Service) RetrieveChunk(ctx context.Context, chunkAddr, sourcePeerAddr s
 				inflight--
 
 				if res.err == nil {
+					s.recordSignal(res.peer, reputation.SignalSuccess)
 					loggerV1.Debug("retrieved chunk", "chunk_address", chunkAddr, "peer_address", res.peer, "peer_proximity", swarm.Proximity(res.peer.Bytes(), chunkAddr.Bytes()))
 					return res.chunk, nil
 				}
@@ -278,6 +287,10 @@ func (s *Service) RetrieveChunk(ctx context.Context, chunkAddr, sourcePeerAddr s
 				loggerV1.Debug("failed to get chunk", "chunk_address", chunkAddr, "peer_address", res.peer,
 					"peer_proximity", swarm.Proximity(res.peer.Bytes(), chunkAddr.Bytes()), "error", res.err)
 
+				if signal, ok := misbehaviour(res.err); ok {
+					s.recordSignal(res.peer, signal)
+				}
+
 				errorsLeft--
 				s.errSkip.Add(chunkAddr, res.peer, skiplistDur)
 				retry()
@@ -346,6 +359,9 @@ func (s *Service) retrieveChunk(ctx context.Context, quit chan struct{}, chunkAd
 
 	var d pb.Delivery
 	if err = r.ReadMsgWithContext(ctx, &d); err != nil {
+		if errors.Is(err, io.ErrShortBuffer) || errors.Is(err, pb.ErrInvalidLengthRetrieval) || errors.Is(err, pb.ErrIntOverflowRetrieval) {
+			err = fmt.Errorf("%w: %w", errProtocol, err)
+		}
 		err = fmt.Errorf("read delivery: %w peer %s", err, peer.String())
 		return
 	}
@@ -353,6 +369,10 @@ func (s *Service) retrieveChunk(ctx context.Context, quit chan struct{}, chunkAd
 		err = p2p.NewChunkDeliveryError(d.Err)
 		return
 	}
+	if len(d.Data) == 0 {
+		err = fmt.Errorf("%w: empty delivery peer %s", errProtocol, peer.String())
+		return
+	}
 
 	s.metrics.ChunkRetrieveTime.Observe(time.Since(startTime).Seconds())
 	s.metrics.TotalRetrieved.Inc()
@@ -369,6 +389,19 @@ func (s *Service) retrieveChunk(ctx context.Context, quit chan struct{}, chunkAd
 	err = action.Apply()
 }
 
+// misbehaviour returns the reputation signal of a failed retrieval
+// if the error is caused by the peer misbehaving. The peers that
+// do not have the chunk or do not respond in time are not penalised.
+func misbehaviour(err error) (reputation.Signal, bool) {
+	switch {
+	case errors.Is(err, swarm.ErrInvalidChunk):
+		return reputation.SignalFlag, true
+	case errors.Is(err, errProtocol):
+		return reputation.SignalFailure, true
+	}
+	return 0, false
+}
+
 func (s *Service) prepareCredit(ctx context.Context, peer, chunk swarm.Address, origin bool) (accounting.Action, error) {
 
 	price := s.pricer.PeerPrice(peer, chunk)
@@ -421,7 +454,16 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address, all
 	return closest, nil
 }
 
+// ActiveRequests returns the number of the retrieval requests that are
+// being served to the peers.
+func (s *Service) ActiveRequests() int64 {
+	return s.active.Load()
+}
+
 func (s *Service) handler(p2pctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
 	ctx, cancel := context.WithTimeout(p2pctx, RetrieveChunkTimeout)
 	defer cancel()
 
@@ -476,7 +518,7 @@ func (s *Service) handler(p2pctx context.Context, p p2p.Peer, stream p2p.Stream)
 		}
 	}
 
-	chunkPrice := s.pricer.Price(chunk.Address())
+	chunkPrice := s.pricer.Price(p.Address, chunk.Address())
 	debit, err := s.accounting.PrepareDebit(ctx, p.Address, chunkPrice)
 	if err != nil {
 		return fmt.Errorf("prepare debit to peer %s before writeback: %w", p.Address.String(), err)
@@ -506,6 +548,18 @@ func (s *Service) handler(p2pctx context.Context, p p2p.Peer, stream p2p.Stream)
 	return nil
 }
 
+// SetReputation makes the service record the outcome of the retrievals
+// as peer quality signals.
+func (s *Service) SetReputation(r reputation.Recorder) {
+	s.reputation = r
+}
+
+func (s *Service) recordSignal(peer swarm.Address, sig reputation.Signal) {
+	if s.reputation != nil {
+		s.reputation.Record(peer, sig)
+	}
+}
+
 func (s *Service) Close() error {
 	return s.errSkip.Close()
 }
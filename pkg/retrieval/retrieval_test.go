@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/v2/pkg/pricer"
 	pricermock "github.com/ethersphere/bee/v2/pkg/pricer/mock"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/retrieval"
 	pb "github.com/ethersphere/bee/v2/pkg/retrieval/pb"
 	"github.com/ethersphere/bee/v2/pkg/spinlock"
@@ -699,6 +701,36 @@ func TestClosestPeer(t *testing.T) {
 	})
 }
 
+func TestMisbehaviour(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		signal reputation.Signal
+		ok     bool
+	}{
+		{name: "invalid chunk", err: fmt.Errorf("wrapped: %w", swarm.ErrInvalidChunk), signal: reputation.SignalFlag, ok: true},
+		{name: "protocol violation", err: fmt.Errorf("read delivery: %w", retrieval.ErrProtocol), signal: reputation.SignalFailure, ok: true},
+		{name: "not found", err: p2p.NewChunkDeliveryError(storage.ErrNotFound.Error())},
+		{name: "deadline", err: fmt.Errorf("read delivery: %w", context.DeadlineExceeded)},
+		{name: "stream deadline", err: fmt.Errorf("read delivery: %w", os.ErrDeadlineExceeded)},
+		{name: "canceled", err: context.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			signal, ok := retrieval.Misbehaviour(tc.err)
+			if ok != tc.ok {
+				t.Fatalf("got misbehaviour %v, want %v", ok, tc.ok)
+			}
+			if ok && signal != tc.signal {
+				t.Fatalf("got signal %v, want %v", signal, tc.signal)
+			}
+		})
+	}
+}
+
 func createRetrieval(
 	t *testing.T,
 	addr swarm.Address,
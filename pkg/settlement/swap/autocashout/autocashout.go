@@ -0,0 +1,547 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package autocashout cashes the received cheques according to a policy of
+// amount thresholds and gas costs and keeps the history of the cashouts.
+package autocashout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/sctx"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "autocashout"
+
+const historyKeyPrefix = "swap_autocashout_history_"
+
+// tokenToNativeUnits converts the amount in the smallest units of the token
+// (16 decimals) to the smallest units of the native token (18 decimals).
+var tokenToNativeUnits = big.NewFloat(100)
+
+// Trigger is the reason of a cashout.
+type Trigger string
+
+const (
+	TriggerManual    Trigger = "manual"
+	TriggerThreshold Trigger = "threshold"
+	TriggerGasCost   Trigger = "gas-cost"
+)
+
+// Cashout status values.
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusReverted  = "reverted"
+	StatusDropped   = "dropped" // the transaction is known neither to the chain nor to the pool
+	StatusExpired   = "expired" // the transaction stayed pending for longer than the timeout
+)
+
+// ErrCashoutInProgress is returned when a cashout of the cheques of the peer
+// is already being sent.
+var ErrCashoutInProgress = errors.New("cashout in progress")
+
+// Interface is the cashout service.
+type Interface interface {
+	// CashCheque cashes the cheque of the peer on request.
+	CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error)
+	// History returns the recorded cashouts, the newest first.
+	History() ([]Cashout, error)
+	// Status returns the state of the automatic cashouts.
+	Status() Status
+}
+
+// Swap is the part of the swap service that the cheques are cashed with.
+type Swap interface {
+	LastReceivedCheques() (map[string]*chequebook.SignedCheque, error)
+	CashoutStatus(ctx context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error)
+	CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error)
+}
+
+// Options are the cashout policy. The cheques of a peer are cashed when the
+// uncashed amount reaches the threshold or when the gas cost of the cashout
+// is at most the percentage of the uncashed value. Zero values disable the
+// rules.
+type Options struct {
+	CheckInterval     time.Duration
+	Threshold         *big.Int   // uncashed amount in the smallest token units
+	MaxGasCostPercent float64    // maximal gas cost in percents of the uncashed value
+	TokenPrice        *big.Float // price of the token in the native tokens, required by the gas cost rule
+	BatchWindow       time.Duration
+	MinNativeBalance  *big.Int      // native balance in wei below which the cashouts are paused
+	PendingTimeout    time.Duration // time after which a pending cashout expires, zero disables the expiry
+}
+
+// DefaultOptions returns the options with the automatic cashouts disabled.
+func DefaultOptions() Options {
+	return Options{
+		CheckInterval:  15 * time.Minute,
+		BatchWindow:    10 * time.Minute,
+		PendingTimeout: time.Hour,
+	}
+}
+
+func (o Options) automatic() bool {
+	return o.Threshold != nil && o.Threshold.Sign() > 0 ||
+		o.MaxGasCostPercent > 0 && o.TokenPrice != nil && o.TokenPrice.Sign() > 0
+}
+
+// Cashout is a record in the cashout history.
+type Cashout struct {
+	Peer     swarm.Address `json:"peer"`
+	TxHash   common.Hash   `json:"txHash"`
+	Amount   *big.Int      `json:"amount"`
+	Trigger  Trigger       `json:"trigger"`
+	GasPrice *big.Int      `json:"gasPrice"`
+	Fee      *big.Int      `json:"fee"` // paid fee, nil while pending
+	Status   string        `json:"status"`
+	Created  time.Time     `json:"created"`
+}
+
+// Status is the state of the automatic cashouts.
+type Status struct {
+	Automatic   bool
+	Paused      bool
+	PauseReason string
+	Eligible    []swarm.Address // peers waiting for the end of the batch window
+	BatchDue    time.Time       // when the waiting peers are cashed
+}
+
+type candidate struct {
+	peer    swarm.Address
+	amount  *big.Int
+	trigger Trigger
+}
+
+var _ Interface = (*Service)(nil)
+
+// Service cashes the received cheques according to the policy.
+type Service struct {
+	logger  log.Logger
+	store   storage.StateStorer
+	swap    Swap
+	backend transaction.Backend
+	owner   common.Address
+	opts    Options
+	metrics metrics
+
+	mu      sync.Mutex
+	status  Status
+	cashing map[string]struct{} // peers whose cashouts are being sent
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates the service and starts the periodic checks of the received
+// cheques. The owner is the account that pays the fees of the cashouts.
+func New(logger log.Logger, store storage.StateStorer, swap Swap, backend transaction.Backend, owner common.Address, o Options) *Service {
+	s := &Service{
+		logger:  logger.WithName(loggerName).Register(),
+		store:   store,
+		swap:    swap,
+		backend: backend,
+		owner:   owner,
+		opts:    o,
+		metrics: newMetrics(),
+		status:  Status{Automatic: o.automatic()},
+		cashing: make(map[string]struct{}),
+		quit:    make(chan struct{}),
+	}
+
+	if o.CheckInterval > 0 {
+		s.wg.Add(1)
+		go s.manage()
+	}
+	return s
+}
+
+func (s *Service) manage() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-s.quit
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			if err := s.check(ctx, time.Now()); err != nil {
+				s.logger.Debug("cashout check failed", "error", err)
+			}
+		}
+	}
+}
+
+// check updates the history with the fees of the confirmed cashouts and
+// cashes the cheques according to the policy.
+func (s *Service) check(ctx context.Context, now time.Time) error {
+	abandoned, err := s.updateHistory(ctx, now)
+	if err != nil {
+		return fmt.Errorf("update history: %w", err)
+	}
+	if !s.opts.automatic() {
+		return nil
+	}
+
+	gasPrice, err := s.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("suggest gas price: %w", err)
+	}
+	fee := new(big.Int).Mul(gasPrice, big.NewInt(chequebook.CashoutGasLimit))
+
+	candidates, err := s.candidates(ctx, fee, abandoned)
+	if err != nil {
+		return err
+	}
+
+	// the batch window starts when the first peer becomes eligible
+	eligible := make([]swarm.Address, 0, len(candidates))
+	for _, c := range candidates {
+		eligible = append(eligible, c.peer)
+	}
+	s.mu.Lock()
+	s.status.Eligible = eligible
+	if len(candidates) == 0 {
+		s.status.BatchDue = time.Time{}
+	} else if s.status.BatchDue.IsZero() {
+		s.status.BatchDue = now.Add(s.opts.BatchWindow)
+	}
+	due := len(candidates) > 0 && !now.Before(s.status.BatchDue)
+	s.mu.Unlock()
+	s.metrics.EligiblePeers.Set(float64(len(candidates)))
+
+	if !due {
+		return nil
+	}
+
+	paused, err := s.checkBalance(ctx, new(big.Int).Mul(fee, big.NewInt(int64(len(candidates)))))
+	if err != nil || paused {
+		return err
+	}
+
+	for _, c := range candidates {
+		release, err := s.acquire(c.peer)
+		if err != nil {
+			s.logger.Debug("automatic cashout skipped", "peer_address", c.peer, "error", err)
+			continue
+		}
+		_, err = s.cash(ctx, c.peer, c.amount, c.trigger, gasPrice)
+		release()
+		if err != nil {
+			s.logger.Warning("automatic cashout failed", "peer_address", c.peer, "error", err)
+			continue
+		}
+		s.logger.Info("cheque cashed out", "peer_address", c.peer, "amount", c.amount, "trigger", c.trigger)
+	}
+
+	s.mu.Lock()
+	s.status.BatchDue = time.Time{}
+	s.status.Eligible = nil
+	s.mu.Unlock()
+	s.metrics.EligiblePeers.Set(0)
+	return nil
+}
+
+// candidates returns the peers whose cheques should be cashed. The peers
+// whose last cashout is pending are skipped, unless the cashout was dropped
+// or expired.
+func (s *Service) candidates(ctx context.Context, fee *big.Int, abandoned map[common.Hash]struct{}) ([]candidate, error) {
+	cheques, err := s.swap.LastReceivedCheques()
+	if err != nil {
+		return nil, fmt.Errorf("last received cheques: %w", err)
+	}
+
+	var candidates []candidate
+	for p := range cheques {
+		peer, err := swarm.ParseHexAddress(p)
+		if err != nil {
+			continue
+		}
+		status, err := s.swap.CashoutStatus(ctx, peer)
+		if err != nil {
+			s.logger.Debug("cashout status failed", "peer_address", peer, "error", err)
+			continue
+		}
+		if status.Last != nil && status.Last.Result == nil && !status.Last.Reverted {
+			if _, ok := abandoned[status.Last.TxHash]; !ok {
+				// the previous cashout is still pending
+				continue
+			}
+		}
+		if status.UncashedAmount == nil || status.UncashedAmount.Sign() <= 0 {
+			continue
+		}
+		if trigger, ok := s.eligible(status.UncashedAmount, fee); ok {
+			candidates = append(candidates, candidate{peer: peer, amount: status.UncashedAmount, trigger: trigger})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].amount.Cmp(candidates[j].amount) > 0
+	})
+	return candidates, nil
+}
+
+// eligible applies the policy to the uncashed amount.
+func (s *Service) eligible(amount, fee *big.Int) (Trigger, bool) {
+	if s.opts.Threshold != nil && s.opts.Threshold.Sign() > 0 && amount.Cmp(s.opts.Threshold) >= 0 {
+		return TriggerThreshold, true
+	}
+	if s.opts.MaxGasCostPercent > 0 && s.opts.TokenPrice != nil && s.opts.TokenPrice.Sign() > 0 {
+		value := new(big.Float).SetInt(amount)
+		value.Mul(value, s.opts.TokenPrice)
+		value.Mul(value, tokenToNativeUnits)
+		limit := value.Mul(value, big.NewFloat(s.opts.MaxGasCostPercent/100))
+		if new(big.Float).SetInt(fee).Cmp(limit) <= 0 {
+			return TriggerGasCost, true
+		}
+	}
+	return "", false
+}
+
+// checkBalance pauses the cashouts if the native balance does not cover the
+// fees or is below the configured minimum.
+func (s *Service) checkBalance(ctx context.Context, fees *big.Int) (bool, error) {
+	balance, err := s.backend.BalanceAt(ctx, s.owner, nil)
+	if err != nil {
+		return false, fmt.Errorf("native balance: %w", err)
+	}
+
+	var reason string
+	switch {
+	case balance.Cmp(fees) < 0:
+		reason = fmt.Sprintf("native balance %s does not cover the estimated fees %s", balance, fees)
+	case s.opts.MinNativeBalance != nil && balance.Cmp(s.opts.MinNativeBalance) < 0:
+		reason = fmt.Sprintf("native balance %s is below the minimum %s", balance, s.opts.MinNativeBalance)
+	}
+
+	s.mu.Lock()
+	wasPaused := s.status.Paused
+	s.status.Paused = reason != ""
+	s.status.PauseReason = reason
+	s.mu.Unlock()
+
+	if reason == "" {
+		if wasPaused {
+			s.logger.Info("automatic cashouts resumed")
+		}
+		s.metrics.Paused.Set(0)
+		return false, nil
+	}
+	if !wasPaused {
+		s.logger.Warning("automatic cashouts paused", "reason", reason)
+	}
+	s.metrics.Paused.Set(1)
+	return true, nil
+}
+
+// CashCheque cashes the cheque of the peer on request and records it in the
+// history. It returns ErrCashoutInProgress if a cashout of the peer is being
+// sent.
+func (s *Service) CashCheque(ctx context.Context, peer swarm.Address) (common.Hash, error) {
+	release, err := s.acquire(peer)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer release()
+
+	status, err := s.swap.CashoutStatus(ctx, peer)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	gasPrice := sctx.GetGasPrice(ctx)
+	if gasPrice == nil {
+		if gasPrice, err = s.backend.SuggestGasPrice(ctx); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return s.cash(ctx, peer, status.UncashedAmount, TriggerManual, gasPrice)
+}
+
+// acquire guards the cashouts of the peer against the concurrent manual and
+// automatic cashouts. The returned function releases the guard.
+func (s *Service) acquire(peer swarm.Address) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cashing[peer.ByteString()]; ok {
+		return nil, ErrCashoutInProgress
+	}
+	s.cashing[peer.ByteString()] = struct{}{}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.cashing, peer.ByteString())
+	}, nil
+}
+
+func (s *Service) cash(ctx context.Context, peer swarm.Address, amount *big.Int, trigger Trigger, gasPrice *big.Int) (common.Hash, error) {
+	txHash, err := s.swap.CashCheque(ctx, peer)
+	if err != nil {
+		s.metrics.CashoutErrors.Inc()
+		return common.Hash{}, err
+	}
+	s.metrics.Cashouts.WithLabelValues(string(trigger)).Inc()
+
+	c := &Cashout{
+		Peer:     peer,
+		TxHash:   txHash,
+		Amount:   amount,
+		Trigger:  trigger,
+		GasPrice: gasPrice,
+		Status:   StatusPending,
+		Created:  time.Now(),
+	}
+	if err := s.store.Put(historyKey(c), c); err != nil {
+		return txHash, fmt.Errorf("record cashout: %w", err)
+	}
+	return txHash, nil
+}
+
+func historyKey(c *Cashout) string {
+	return fmt.Sprintf("%s%020d_%x", historyKeyPrefix, c.Created.UnixNano(), c.TxHash)
+}
+
+// updateHistory sets the fees of the cashouts that have been confirmed and
+// marks the pending cashouts that were dropped from the pool or that are
+// pending for longer than the timeout. It returns the transactions of the
+// dropped and expired cashouts, which no longer block the following
+// cashouts of their peers.
+func (s *Service) updateHistory(ctx context.Context, now time.Time) (map[common.Hash]struct{}, error) {
+	history, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+
+	abandoned := make(map[common.Hash]struct{})
+	for _, c := range history {
+		switch c.Status {
+		case StatusDropped:
+			abandoned[c.TxHash] = struct{}{}
+			continue
+		case StatusPending, StatusExpired:
+			// an expired cashout may still be confirmed
+		default:
+			continue
+		}
+
+		receipt, err := s.backend.TransactionReceipt(ctx, c.TxHash)
+		if err != nil {
+			if !errors.Is(err, ethereum.NotFound) {
+				return nil, err
+			}
+			status, err := s.unconfirmedStatus(ctx, &c, now)
+			if err != nil {
+				return nil, err
+			}
+			if status != StatusPending {
+				abandoned[c.TxHash] = struct{}{}
+			}
+			if status == c.Status {
+				continue
+			}
+			s.logger.Warning("pending cashout abandoned", "peer_address", c.Peer, "tx", c.TxHash, "status", status)
+			c.Status = status
+			if err := s.store.Put(historyKey(&c), &c); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.Status = StatusConfirmed
+		if receipt.Status == types.ReceiptStatusFailed {
+			c.Status = StatusReverted
+		}
+		gasPrice := c.GasPrice
+		if receipt.EffectiveGasPrice != nil {
+			gasPrice = receipt.EffectiveGasPrice
+		}
+		if gasPrice != nil {
+			c.Fee = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+			feeFloat, _ := new(big.Float).SetInt(c.Fee).Float64()
+			s.metrics.Fees.Add(feeFloat)
+		}
+		if err := s.store.Put(historyKey(&c), &c); err != nil {
+			return nil, err
+		}
+	}
+	return abandoned, nil
+}
+
+// unconfirmedStatus returns the status of the cashout without a receipt.
+func (s *Service) unconfirmedStatus(ctx context.Context, c *Cashout, now time.Time) (string, error) {
+	_, _, err := s.backend.TransactionByHash(ctx, c.TxHash)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		return StatusDropped, nil
+	case err != nil:
+		return "", err
+	case c.Status == StatusExpired:
+		return StatusExpired, nil
+	case s.opts.PendingTimeout > 0 && now.Sub(c.Created) >= s.opts.PendingTimeout:
+		return StatusExpired, nil
+	}
+	return StatusPending, nil
+}
+
+// History returns the recorded cashouts, the newest first.
+func (s *Service) History() ([]Cashout, error) {
+	var history []Cashout
+	err := s.store.Iterate(historyKeyPrefix, func(_, value []byte) (bool, error) {
+		var c Cashout
+		if err := json.Unmarshal(value, &c); err != nil {
+			return false, err
+		}
+		history = append(history, c)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Created.After(history[j].Created)
+	})
+	return history, nil
+}
+
+// Status returns the state of the automatic cashouts.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.status
+	status.Eligible = append([]swarm.Address(nil), s.status.Eligible...)
+	return status
+}
+
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
+}
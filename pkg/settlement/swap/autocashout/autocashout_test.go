@@ -0,0 +1,429 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocashout_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/autocashout"
+	"github.com/ethersphere/bee/v2/pkg/settlement/swap/chequebook"
+	swapmock "github.com/ethersphere/bee/v2/pkg/settlement/swap/mock"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+)
+
+var (
+	peerA    = swarm.MustParseHexAddress("aa")
+	peerB    = swarm.MustParseHexAddress("bb")
+	gasPrice = big.NewInt(1_000_000_000)
+)
+
+func TestThresholdBatchWindow(t *testing.T) {
+	t.Parallel()
+
+	chain := newTestChain(big.NewInt(1e18))
+	swap := newTestSwap(map[string]int64{peerA.String(): 100, peerB.String(): 10})
+
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	o.Threshold = big.NewInt(50)
+	svc := newTestService(t, swap, chain, o)
+
+	now := time.Now()
+	if err := svc.Check(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	if cashed := swap.cashedPeers(); len(cashed) != 0 {
+		t.Fatalf("got cashouts %v before the end of the batch window", cashed)
+	}
+	status := svc.Status()
+	if len(status.Eligible) != 1 || !status.Eligible[0].Equal(peerA) {
+		t.Fatalf("got eligible peers %v, want %v", status.Eligible, []swarm.Address{peerA})
+	}
+	if !status.BatchDue.Equal(now.Add(o.BatchWindow)) {
+		t.Fatalf("got batch due %v, want %v", status.BatchDue, now.Add(o.BatchWindow))
+	}
+
+	if err := svc.Check(context.Background(), now.Add(o.BatchWindow)); err != nil {
+		t.Fatal(err)
+	}
+	if cashed := swap.cashedPeers(); len(cashed) != 1 || !cashed[0].Equal(peerA) {
+		t.Fatalf("got cashouts %v, want %v", cashed, []swarm.Address{peerA})
+	}
+
+	history, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("got %d cashouts in history, want %d", len(history), 1)
+	}
+	c := history[0]
+	if !c.Peer.Equal(peerA) || c.Trigger != autocashout.TriggerThreshold || c.Status != autocashout.StatusPending || c.Amount.Int64() != 100 || c.Fee != nil {
+		t.Fatalf("got cashout %+v", c)
+	}
+
+	// the fee is recorded once the transaction is confirmed
+	chain.confirm(c.TxHash, 50_000, big.NewInt(2_000_000_000))
+	if err := svc.Check(context.Background(), now.Add(2*o.BatchWindow)); err != nil {
+		t.Fatal(err)
+	}
+	history, err = svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := history[0]; c.Status != autocashout.StatusConfirmed || c.Fee == nil || c.Fee.Cmp(big.NewInt(100_000_000_000_000)) != 0 {
+		t.Fatalf("got confirmed cashout %+v", c)
+	}
+}
+
+func TestGasCostRule(t *testing.T) {
+	t.Parallel()
+
+	// the fee of a cashout is 3e14 wei and one token unit is worth 50 wei,
+	// so 1% of the value covers the fee from 6e14 token units
+	chain := newTestChain(big.NewInt(1e18))
+	swap := newTestSwap(map[string]int64{peerA.String(): 1e15, peerB.String(): 1e14})
+
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	o.BatchWindow = 0
+	o.MaxGasCostPercent = 1
+	o.TokenPrice = big.NewFloat(0.5)
+	svc := newTestService(t, swap, chain, o)
+
+	if err := svc.Check(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if cashed := swap.cashedPeers(); len(cashed) != 1 || !cashed[0].Equal(peerA) {
+		t.Fatalf("got cashouts %v, want %v", cashed, []swarm.Address{peerA})
+	}
+	history, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Trigger != autocashout.TriggerGasCost {
+		t.Fatalf("got history %+v", history)
+	}
+}
+
+func TestPauseOnLowBalance(t *testing.T) {
+	t.Parallel()
+
+	chain := newTestChain(big.NewInt(1000))
+	swap := newTestSwap(map[string]int64{peerA.String(): 100})
+
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	o.BatchWindow = 0
+	o.Threshold = big.NewInt(50)
+	svc := newTestService(t, swap, chain, o)
+
+	if err := svc.Check(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if cashed := swap.cashedPeers(); len(cashed) != 0 {
+		t.Fatalf("got cashouts %v while the balance does not cover the fees", cashed)
+	}
+	if status := svc.Status(); !status.Paused || status.PauseReason == "" {
+		t.Fatalf("got status %+v, want paused", status)
+	}
+
+	chain.setBalance(big.NewInt(1e18))
+	if err := svc.Check(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if cashed := swap.cashedPeers(); len(cashed) != 1 {
+		t.Fatalf("got %d cashouts after the balance was topped up, want %d", len(cashed), 1)
+	}
+	if status := svc.Status(); status.Paused {
+		t.Fatal("expected the cashouts to be resumed")
+	}
+}
+
+func TestAbandonedCashout(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		abandon func(*testChain, common.Hash) time.Time
+		status  string
+	}{
+		{
+			name: "dropped",
+			abandon: func(chain *testChain, txHash common.Hash) time.Time {
+				chain.drop(txHash)
+				return time.Now()
+			},
+			status: autocashout.StatusDropped,
+		},
+		{
+			name: "expired",
+			abandon: func(_ *testChain, _ common.Hash) time.Time {
+				return time.Now().Add(autocashout.DefaultOptions().PendingTimeout)
+			},
+			status: autocashout.StatusExpired,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			chain := newTestChain(big.NewInt(1e18))
+			swap := newTestSwap(map[string]int64{peerA.String(): 100})
+
+			o := autocashout.DefaultOptions()
+			o.CheckInterval = 0
+			o.BatchWindow = 0
+			o.Threshold = big.NewInt(50)
+			svc := newTestService(t, swap, chain, o)
+
+			if err := svc.Check(context.Background(), time.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			// the pending cashout blocks the cashouts of the new cheques
+			swap.setUncashed(peerA, 100)
+			if err := svc.Check(context.Background(), time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			if cashed := swap.cashedPeers(); len(cashed) != 1 {
+				t.Fatalf("got %d cashouts while the last one is pending, want %d", len(cashed), 1)
+			}
+			history, err := svc.History()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 1 || history[0].Status != autocashout.StatusPending {
+				t.Fatalf("got history %+v", history)
+			}
+
+			if err := svc.Check(context.Background(), tc.abandon(chain, history[0].TxHash)); err != nil {
+				t.Fatal(err)
+			}
+			if cashed := swap.cashedPeers(); len(cashed) != 2 {
+				t.Fatalf("got %d cashouts after the last one was abandoned, want %d", len(cashed), 2)
+			}
+			history, err = svc.History()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 2 || history[0].Status != autocashout.StatusPending || history[1].Status != tc.status {
+				t.Fatalf("got history %+v", history)
+			}
+		})
+	}
+}
+
+func TestManualCashout(t *testing.T) {
+	t.Parallel()
+
+	chain := newTestChain(big.NewInt(1e18))
+	swap := newTestSwap(map[string]int64{peerA.String(): 10})
+
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	svc := newTestService(t, swap, chain, o)
+
+	if status := svc.Status(); status.Automatic {
+		t.Fatal("expected the automatic cashouts to be disabled without a policy")
+	}
+
+	txHash, err := svc.CashCheque(context.Background(), peerA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := svc.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].TxHash != txHash || history[0].Trigger != autocashout.TriggerManual || history[0].GasPrice.Cmp(gasPrice) != 0 {
+		t.Fatalf("got history %+v", history)
+	}
+}
+
+func TestConcurrentCashout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+	)
+	swap := swapmock.New(
+		swapmock.WithCashoutStatusFunc(func(context.Context, swarm.Address) (*chequebook.CashoutStatus, error) {
+			return &chequebook.CashoutStatus{UncashedAmount: big.NewInt(10)}, nil
+		}),
+		swapmock.WithCashChequeFunc(func(_ context.Context, peer swarm.Address) (common.Hash, error) {
+			close(started)
+			<-unblock
+			return common.BytesToHash(peer.Bytes()), nil
+		}),
+	)
+
+	o := autocashout.DefaultOptions()
+	o.CheckInterval = 0
+	svc := autocashout.New(log.Noop, statestore.NewStateStore(), swap, newTestChain(big.NewInt(1e18)).mock(), common.HexToAddress("0xab"), o)
+	testutil.CleanupCloser(t, svc)
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := svc.CashCheque(context.Background(), peerA)
+		errC <- err
+	}()
+	<-started
+
+	if _, err := svc.CashCheque(context.Background(), peerA); !errors.Is(err, autocashout.ErrCashoutInProgress) {
+		t.Fatalf("got error %v, want %v", err, autocashout.ErrCashoutInProgress)
+	}
+
+	close(unblock)
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestService(t *testing.T, swap *testSwap, chain *testChain, o autocashout.Options) *autocashout.Service {
+	t.Helper()
+
+	svc := autocashout.New(log.Noop, statestore.NewStateStore(), swap.mock(), chain.mock(), common.HexToAddress("0xab"), o)
+	testutil.CleanupCloser(t, svc)
+	return svc
+}
+
+// testSwap keeps the uncashed amounts of the peers and records the cashouts.
+// The last cashouts of the peers are reported as pending.
+type testSwap struct {
+	mu       sync.Mutex
+	uncashed map[string]int64
+	cashed   []swarm.Address
+	last     map[string]common.Hash
+}
+
+func newTestSwap(uncashed map[string]int64) *testSwap {
+	return &testSwap{uncashed: uncashed, last: make(map[string]common.Hash)}
+}
+
+func (s *testSwap) setUncashed(peer swarm.Address, amount int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uncashed[peer.String()] = amount
+}
+
+func (s *testSwap) mock() autocashout.Swap {
+	return swapmock.New(
+		swapmock.WithLastReceivedChequesFunc(func() (map[string]*chequebook.SignedCheque, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			cheques := make(map[string]*chequebook.SignedCheque)
+			for peer, amount := range s.uncashed {
+				cheques[peer] = &chequebook.SignedCheque{Cheque: chequebook.Cheque{CumulativePayout: big.NewInt(amount)}}
+			}
+			return cheques, nil
+		}),
+		swapmock.WithCashoutStatusFunc(func(_ context.Context, peer swarm.Address) (*chequebook.CashoutStatus, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			status := &chequebook.CashoutStatus{UncashedAmount: big.NewInt(s.uncashed[peer.String()])}
+			if txHash, ok := s.last[peer.String()]; ok {
+				status.Last = &chequebook.LastCashout{TxHash: txHash}
+			}
+			return status, nil
+		}),
+		swapmock.WithCashChequeFunc(func(_ context.Context, peer swarm.Address) (common.Hash, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.cashed = append(s.cashed, peer)
+			s.uncashed[peer.String()] = 0
+			txHash := common.BytesToHash(append(peer.Bytes(), byte(len(s.cashed))))
+			s.last[peer.String()] = txHash
+			return txHash, nil
+		}),
+	)
+}
+
+func (s *testSwap) cashedPeers() []swarm.Address {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]swarm.Address(nil), s.cashed...)
+}
+
+// testChain is a chain backend with the native balance and the receipts of
+// the confirmed transactions. The other transactions are pending unless they
+// were dropped.
+type testChain struct {
+	mu       sync.Mutex
+	balance  *big.Int
+	receipts map[common.Hash]*types.Receipt
+	dropped  map[common.Hash]struct{}
+}
+
+func newTestChain(balance *big.Int) *testChain {
+	return &testChain{balance: balance, receipts: make(map[common.Hash]*types.Receipt), dropped: make(map[common.Hash]struct{})}
+}
+
+func (c *testChain) drop(txHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropped[txHash] = struct{}{}
+}
+
+func (c *testChain) setBalance(balance *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balance = balance
+}
+
+func (c *testChain) confirm(txHash common.Hash, gasUsed uint64, effectiveGasPrice *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[txHash] = &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		GasUsed:           gasUsed,
+		EffectiveGasPrice: effectiveGasPrice,
+	}
+}
+
+func (c *testChain) mock() transaction.Backend {
+	return backendmock.New(
+		backendmock.WithSuggestGasPriceFunc(func(context.Context) (*big.Int, error) {
+			return gasPrice, nil
+		}),
+		backendmock.WithBalanceAt(func(context.Context, common.Address, *big.Int) (*big.Int, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.balance, nil
+		}),
+		backendmock.WithTransactionReceiptFunc(func(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			receipt, ok := c.receipts[txHash]
+			if !ok {
+				return nil, ethereum.NotFound
+			}
+			return receipt, nil
+		}),
+		backendmock.WithTransactionByHashFunc(func(_ context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if _, ok := c.dropped[txHash]; ok {
+				return nil, false, ethereum.NotFound
+			}
+			_, confirmed := c.receipts[txHash]
+			return &types.Transaction{}, !confirmed, nil
+		}),
+	)
+}
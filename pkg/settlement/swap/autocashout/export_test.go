@@ -0,0 +1,14 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocashout
+
+import (
+	"context"
+	"time"
+)
+
+func (s *Service) Check(ctx context.Context, now time.Time) error {
+	return s.check(ctx, now)
+}
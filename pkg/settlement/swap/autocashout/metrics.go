@@ -0,0 +1,62 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autocashout
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Cashouts      *prometheus.CounterVec
+	CashoutErrors prometheus.Counter
+	Fees          prometheus.Counter
+	EligiblePeers prometheus.Gauge
+	Paused        prometheus.Gauge
+}
+
+func newMetrics() metrics {
+	subsystem := "swap_autocashout"
+
+	return metrics{
+		Cashouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "cashouts",
+				Help:      "Number of the cashout transactions sent by trigger.",
+			},
+			[]string{"trigger"},
+		),
+		CashoutErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cashout_errors",
+			Help:      "Number of the cashouts that could not be sent.",
+		}),
+		Fees: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "fees",
+			Help:      "Fees in wei paid for the confirmed cashouts.",
+		}),
+		EligiblePeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "eligible_peers",
+			Help:      "Number of the peers whose cheques wait for the end of the batch window.",
+		}),
+		Paused: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "paused",
+			Help:      "Whether the automatic cashouts are paused for the lack of native tokens.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -19,6 +19,9 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/transaction"
 )
 
+// CashoutGasLimit is the default gas limit of a cashout transaction.
+const CashoutGasLimit = 300_000
+
 var (
 	// ErrNoCashout is the error if there has not been any cashout action for the chequebook
 	ErrNoCashout = errors.New("no prior cashout")
@@ -144,7 +147,7 @@ func (s *cashoutService) CashCheque(ctx context.Context, chequebook, recipient c
 		To:          &chequebook,
 		Data:        callData,
 		GasPrice:    sctx.GetGasPrice(ctx),
-		GasLimit:    sctx.GetGasLimitWithDefault(ctx, 300_000),
+		GasLimit:    sctx.GetGasLimitWithDefault(ctx, CashoutGasLimit),
 		Value:       big.NewInt(0),
 		Description: "cheque cashout",
 	}
@@ -11,6 +11,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/accounting/ledger"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/v2/pkg/settlement"
@@ -18,6 +19,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/swapprotocol"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 )
 
 // loggerName is the tree path name of the logger for this package.
@@ -62,6 +64,8 @@ type Service struct {
 	addressbook    Addressbook
 	networkID      uint64
 	cashoutAddress common.Address
+	ledger         *ledger.Ledger
+	auditLog       wallet.Recorder
 }
 
 // New creates a new swap Service.
@@ -119,6 +123,10 @@ func (s *Service) ReceiveCheque(ctx context.Context, peer swarm.Address, cheque
 	s.metrics.TotalReceived.Add(tot)
 	s.metrics.ChequesReceived.Inc()
 
+	if s.ledger != nil {
+		s.ledger.Record(peer, ledger.KindPaymentReceived, amount, receivedAmount)
+	}
+
 	return s.accounting.NotifyPaymentReceived(peer, amount)
 }
 
@@ -143,12 +151,29 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 		return
 	}
 
-	balance, err := s.proto.EmitCheque(ctx, peer, beneficiary, amount, s.chequebook.Issue)
+	var value *big.Int
+	issue := func(ctx context.Context, beneficiary common.Address, amount *big.Int, sendChequeFunc chequebook.SendChequeFunc) (*big.Int, error) {
+		balance, err := s.chequebook.Issue(ctx, beneficiary, amount, sendChequeFunc)
+		if err == nil {
+			value = amount
+		}
+		return balance, err
+	}
+	balance, err := s.proto.EmitCheque(ctx, peer, beneficiary, amount, issue)
 
 	if err != nil {
 		return
 	}
 
+	if s.ledger != nil {
+		s.ledger.Record(peer, ledger.KindPaymentSent, amount, value)
+	}
+	if s.auditLog != nil && value != nil {
+		if err := s.auditLog.Record(wallet.KindSwapCheque, wallet.CoinBZZ, beneficiary, value, common.Hash{}, peer.String()); err != nil {
+			s.logger.Error(err, "unable to record the issued cheque", "peer_address", peer)
+		}
+	}
+
 	bal, _ := big.NewFloat(0).SetInt(balance).Float64()
 	s.metrics.AvailableBalance.Set(bal)
 	s.accounting.NotifyPaymentSent(peer, amount, nil)
@@ -157,6 +182,16 @@ func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount *big.Int)
 	s.metrics.ChequesSent.Inc()
 }
 
+// SetLedger sets the ledger the swap payments are recorded in.
+func (s *Service) SetLedger(l *ledger.Ledger) {
+	s.ledger = l
+}
+
+// SetAuditLog sets the audit log the issued cheques are recorded in.
+func (s *Service) SetAuditLog(r wallet.Recorder) {
+	s.auditLog = r
+}
+
 func (s *Service) SetAccounting(accounting settlement.Accounting) {
 	s.accounting = accounting
 }
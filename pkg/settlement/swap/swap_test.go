@@ -21,6 +21,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/settlement/swap/swapprotocol"
 	mockstore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
 )
 
 type swapProtocolMock struct {
@@ -438,6 +439,51 @@ func TestPay(t *testing.T) {
 	}
 }
 
+func TestPayAuditLog(t *testing.T) {
+	t.Parallel()
+
+	amount := big.NewInt(50)
+	beneficiary := common.HexToAddress("0xcd")
+	peer := swarm.MustParseHexAddress("abcd")
+	addressbook := &addressbookMock{
+		beneficiary: func(swarm.Address) (common.Address, bool, error) {
+			return beneficiary, true, nil
+		},
+	}
+
+	swap := swap.New(
+		&swapProtocolMock{
+			emitCheque: func(ctx context.Context, _ swarm.Address, b common.Address, a *big.Int, issueFunc swapprotocol.IssueFunc) (*big.Int, error) {
+				return issueFunc(ctx, b, a, nil)
+			},
+		},
+		log.Noop,
+		mockstore.NewStateStore(),
+		mockchequebook.NewChequebook(),
+		mockchequestore.NewChequeStore(),
+		addressbook,
+		1,
+		&cashoutMock{},
+		newTestObserver(),
+		common.Address{},
+	)
+	auditLog := wallet.New(log.Noop, mockstore.NewStateStore(), wallet.Options{})
+	swap.SetAuditLog(auditLog)
+
+	swap.Pay(context.Background(), peer, amount)
+
+	records, err := auditLog.Audit(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d audit records, want 1", len(records))
+	}
+	if r := records[0]; r.Kind != wallet.KindSwapCheque || r.To != beneficiary || r.Amount.Cmp(amount) != 0 || r.Reference != peer.String() {
+		t.Fatalf("got audit record %+v", r)
+	}
+}
+
 func TestPayIssueError(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,44 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharky
+
+// Cipher seals blobs before they are written to the shard files and opens
+// them after they are read back. Sealed blobs are Overhead bytes longer than
+// the plaintext, the slot size of the shards is extended accordingly.
+type Cipher interface {
+	Overhead() int
+	Seal(dst, plaintext []byte) ([]byte, error)
+	Open(dst, sealed []byte) ([]byte, error)
+}
+
+// Option configures the Store and the Recovery.
+type Option func(*options)
+
+type options struct {
+	cipher Cipher
+}
+
+// WithCipher makes the blobs encrypted at rest using the given cipher.
+func WithCipher(c Cipher) Option {
+	return func(o *options) {
+		o.cipher = c
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// overhead returns the number of bytes a sealed blob takes in addition to the plaintext.
+func (o *options) overhead() int {
+	if o.cipher == nil {
+		return 0
+	}
+	return o.cipher.Overhead()
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharky_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
+	"github.com/ethersphere/bee/v2/pkg/sharky"
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	datasize := 16
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	c, err := atrest.New(bytes.Repeat([]byte{1}, atrest.KeyLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := sharky.New(&dirFS{basedir: dir}, 1, datasize, sharky.WithCipher(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte("plaintext")
+	loc, err := s.Write(ctx, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(loc.Length) != len(want) {
+		t.Fatalf("got length %d, want %d", loc.Length, len(want))
+	}
+	full := bytes.Repeat([]byte{2}, datasize)
+	fullLoc, err := s.Write(ctx, full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write(ctx, make([]byte, datasize+1)); err == nil {
+		t.Fatal("expected error writing too long data")
+	}
+
+	buf := make([]byte, datasize)
+	if err := s.Read(ctx, loc, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf[:loc.Length], want) {
+		t.Fatalf("got %q, want %q", buf[:loc.Length], want)
+	}
+	if err := s.Read(ctx, fullLoc, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, full) {
+		t.Fatalf("got %x, want %x", buf, full)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "shard_000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, want) {
+		t.Fatal("plaintext found in the shard file")
+	}
+
+	t.Run("recovery", func(t *testing.T) {
+		r, err := sharky.NewRecovery(dir, 1, datasize, sharky.WithCipher(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		buf := make([]byte, fullLoc.Length)
+		if err := r.Read(ctx, fullLoc, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, full) {
+			t.Fatalf("got %x, want %x", buf, full)
+		}
+
+		to := sharky.Location{Shard: 0, Slot: 5, Length: loc.Length}
+		if err := r.Move(ctx, loc, to); err != nil {
+			t.Fatal(err)
+		}
+		buf = make([]byte, to.Length)
+		if err := r.Read(ctx, to, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("got %q, want %q", buf, want)
+		}
+	})
+}
@@ -22,11 +22,18 @@ type Recovery struct {
 	shards     []*slots
 	shardFiles []*os.File
 	datasize   int
+	cipher     Cipher
+	overhead   int
 }
 
 var ErrShardNotFound = errors.New("shard not found")
 
-func NewRecovery(dir string, shardCnt int, datasize int) (*Recovery, error) {
+// NewRecovery opens the shards in the given directory for recovery. The options
+// must match the ones the shards were written with by the Store.
+func NewRecovery(dir string, shardCnt int, datasize int, opts ...Option) (*Recovery, error) {
+	o := newOptions(opts)
+	datasize += o.overhead()
+
 	shards := make([]*slots, shardCnt)
 	shardFiles := make([]*os.File, shardCnt)
 
@@ -52,7 +59,13 @@ func NewRecovery(dir string, shardCnt int, datasize int) (*Recovery, error) {
 		shards[i] = sl
 		shardFiles[i] = file
 	}
-	return &Recovery{shards: shards, shardFiles: shardFiles, datasize: datasize}, nil
+	return &Recovery{
+		shards:     shards,
+		shardFiles: shardFiles,
+		datasize:   datasize,
+		cipher:     o.cipher,
+		overhead:   o.overhead(),
+	}, nil
 }
 
 // Add marks a location as used (not free).
@@ -72,18 +85,34 @@ func (r *Recovery) Add(loc Location) error {
 	return nil
 }
 
+// Read reads the blob found at location into the byte buffer given.
+// If the recovery was created with a cipher, the blob is decrypted.
 func (r *Recovery) Read(ctx context.Context, loc Location, buf []byte) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
-	_, err := r.shardFiles[loc.Shard].ReadAt(buf, int64(loc.Slot)*int64(r.datasize))
-	return err
+
+	if r.cipher == nil {
+		_, err := r.shardFiles[loc.Shard].ReadAt(buf, int64(loc.Slot)*int64(r.datasize))
+		return err
+	}
+
+	sealed := make([]byte, int(loc.Length)+r.overhead)
+	if _, err := r.shardFiles[loc.Shard].ReadAt(sealed, int64(loc.Slot)*int64(r.datasize)); err != nil {
+		return err
+	}
+	plain, err := r.cipher.Open(nil, sealed)
+	if err != nil {
+		return fmt.Errorf("open blob at %s: %w", loc, err)
+	}
+	copy(buf, plain)
+	return nil
 }
 
 func (r *Recovery) Move(ctx context.Context, from Location, to Location) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
-	chData := make([]byte, from.Length)
+	chData := make([]byte, int(from.Length)+r.overhead)
 	_, err := r.shardFiles[from.Shard].ReadAt(chData, int64(from.Slot)*int64(r.datasize))
 	if err != nil {
 		return err
@@ -29,6 +29,8 @@ var (
 // - free slots allow write
 type Store struct {
 	maxDataSize int             // max length of blobs
+	cipher      Cipher          // optional cipher sealing the blobs at rest
+	overhead    int             // length of sealed blob in excess of the plaintext
 	writes      chan write      // shared write operations channel
 	shards      []*shard        // shards
 	wg          *sync.WaitGroup // count started operations
@@ -42,9 +44,13 @@ type Store struct {
 // - shard count - positive integer < 256 - cannot be zero or expect panic
 // - shard size - positive integer multiple of 8 - for others expect undefined behaviour
 // - maxDataSize - positive integer representing the maximum blob size to be stored
-func New(basedir fs.FS, shardCnt int, maxDataSize int) (*Store, error) {
+// - opts - optional settings, e.g. a cipher to encrypt the blobs at rest
+func New(basedir fs.FS, shardCnt int, maxDataSize int, opts ...Option) (*Store, error) {
+	o := newOptions(opts)
 	store := &Store{
 		maxDataSize: maxDataSize,
+		cipher:      o.cipher,
+		overhead:    o.overhead(),
 		writes:      make(chan write),
 		shards:      make([]*shard, shardCnt),
 		wg:          &sync.WaitGroup{},
@@ -52,7 +58,7 @@ func New(basedir fs.FS, shardCnt int, maxDataSize int) (*Store, error) {
 		metrics:     newMetrics(),
 	}
 	for i := range store.shards {
-		s, err := store.create(uint8(i), maxDataSize, basedir)
+		s, err := store.create(uint8(i), maxDataSize+store.overhead, basedir)
 		if err != nil {
 			return nil, err
 		}
@@ -117,9 +123,31 @@ func (s *Store) create(index uint8, maxDataSize int, basedir fs.FS) (*shard, err
 // Read reads the content of the blob found at location into the byte buffer given
 // The location is assumed to be obtained by an earlier Write call storing the blob
 func (s *Store) Read(ctx context.Context, loc Location, buf []byte) (err error) {
+	if s.cipher == nil {
+		return s.read(ctx, loc, buf[:loc.Length])
+	}
+
+	sealed := make([]byte, int(loc.Length)+s.overhead)
+	if err := s.read(ctx, loc, sealed); err != nil {
+		return err
+	}
+	plain, err := s.cipher.Open(buf[:0], sealed)
+	if err != nil {
+		s.metrics.TotalReadCallsErr.Inc()
+		return fmt.Errorf("open blob at %s: %w", loc, err)
+	}
+	if len(plain) != int(loc.Length) {
+		s.metrics.TotalReadCallsErr.Inc()
+		return fmt.Errorf("open blob at %s: unexpected length %d", loc, len(plain))
+	}
+	return nil
+}
+
+// read reads the raw content of the slot found at location into the byte buffer given.
+func (s *Store) read(ctx context.Context, loc Location, buf []byte) (err error) {
 	sh := s.shards[loc.Shard]
 	select {
-	case sh.reads <- read{ctx: ctx, buf: buf[:loc.Length], slot: loc.Slot}:
+	case sh.reads <- read{ctx: ctx, buf: buf, slot: loc.Slot}:
 		s.metrics.TotalReadCalls.Inc()
 	case <-ctx.Done():
 		return ctx.Err()
@@ -150,6 +178,12 @@ func (s *Store) Write(ctx context.Context, data []byte) (loc Location, err error
 	if len(data) > s.maxDataSize {
 		return loc, ErrTooLong
 	}
+	if s.cipher != nil {
+		data, err = s.cipher.Seal(nil, data)
+		if err != nil {
+			return loc, fmt.Errorf("seal blob: %w", err)
+		}
+	}
 	s.wg.Add(1)
 	defer s.wg.Done()
 
@@ -167,6 +201,7 @@ func (s *Store) Write(ctx context.Context, data []byte) (loc Location, err error
 	select {
 	case e := <-c:
 		if e.err == nil {
+			e.loc.Length -= uint16(s.overhead)
 			shard := strconv.Itoa(int(e.loc.Shard))
 			s.metrics.CurrentShardSize.WithLabelValues(shard).Inc()
 			s.metrics.ShardFragmentation.WithLabelValues(shard).Add(float64(s.maxDataSize - int(e.loc.Length)))
@@ -106,3 +106,15 @@ func GenerateMockSOCWithKey(t *testing.T, data []byte, privKey *ecdsa.PrivateKey
 		WrappedChunk: ch,
 	}
 }
+
+// GenerateMockGraffitiSOC generates a valid mocked graffiti SOC from given
+// data, signed with the graffiti key of its identifier.
+func GenerateMockGraffitiSOC(t *testing.T, data []byte) *MockSOC {
+	t.Helper()
+
+	privKey, err := soc.GraffitiKey(make([]byte, swarm.HashSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return GenerateMockSOCWithKey(t, data, privKey)
+}
@@ -6,10 +6,27 @@ package soc
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
+// graffitiKeyPrefix separates the graffiti owner keys from other keys that
+// are derived from the identifiers.
+var graffitiKeyPrefix = []byte("swarm-graffiti-soc")
+
+// GraffitiKey returns the owner key of the graffiti single-owner chunk (GSOC)
+// with the identifier. The key is derived from the identifier only, so that
+// anyone who knows the identifier can write the chunk.
+func GraffitiKey(id ID) (*ecdsa.PrivateKey, error) {
+	h, err := crypto.LegacyKeccak256(append(append([]byte{}, graffitiKeyPrefix...), id...))
+	if err != nil {
+		return nil, err
+	}
+	return crypto.DecodeSecp256k1PrivateKey(h)
+}
+
 // Valid checks if the chunk is a valid single-owner chunk.
 func Valid(ch swarm.Chunk) bool {
 	s, err := FromChunk(ch)
@@ -28,3 +45,31 @@ func Valid(ch swarm.Chunk) bool {
 	}
 	return ch.Address().Equal(address)
 }
+
+// ValidGraffiti checks if the chunk is a valid graffiti single-owner chunk,
+// whose owner key is the GraffitiKey of its identifier. Only the graffiti
+// chunks are overwritable by their updates.
+func ValidGraffiti(ch swarm.Chunk) bool {
+	if !Valid(ch) {
+		return false
+	}
+	s, err := FromChunk(ch)
+	if err != nil {
+		return false
+	}
+	return s.IsGraffiti()
+}
+
+// IsGraffiti reports whether the owner key of the single-owner chunk is the
+// GraffitiKey of its identifier.
+func (s *SOC) IsGraffiti() bool {
+	key, err := GraffitiKey(s.id)
+	if err != nil {
+		return false
+	}
+	owner, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(owner, s.owner)
+}
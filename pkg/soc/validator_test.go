@@ -5,6 +5,7 @@
 package soc_test
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
 	"io"
 	"strings"
@@ -35,6 +36,47 @@ func TestValid(t *testing.T) {
 	}
 }
 
+// TestValidGraffiti verifies that only the single-owner chunks signed with
+// the graffiti key of their identifier are graffiti chunks.
+func TestValidGraffiti(t *testing.T) {
+	t.Parallel()
+
+	id := make([]byte, swarm.HashSize)
+	ch, err := cac.New([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graffitiKey, err := soc.GraffitiKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		key  *ecdsa.PrivateKey
+		want bool
+	}{
+		{name: "graffiti", key: graffitiKey, want: true},
+		{name: "other", key: otherKey, want: false},
+	} {
+		sch, err := soc.New(id, ch).Sign(crypto.NewDefaultSigner(tc.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !soc.Valid(sch) {
+			t.Fatalf("%s: valid chunk evaluates to invalid", tc.name)
+		}
+		if got := soc.ValidGraffiti(sch); got != tc.want {
+			t.Fatalf("%s: got graffiti %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
 // TestValidDispersedReplica verifies that the validator can detect
 // valid dispersed replicas chunks.
 func TestValidDispersedReplica(t *testing.T) {
@@ -0,0 +1,196 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package encrypted provides a state store wrapper that keeps the values
+// of sensitive entries encrypted at rest.
+package encrypted
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+)
+
+// keyCheckKey is the key under which the key check value of the cipher is stored.
+const keyCheckKey = "encrypted_state_key_check"
+
+// ErrKeyMismatch is returned when the sensitive entries were encrypted with a different key.
+var ErrKeyMismatch = errors.New("encrypted statestore: key mismatch")
+
+var (
+	_ storage.StateStorer        = (*Store)(nil)
+	_ storage.StateStorerCleaner = (*Store)(nil)
+)
+
+// Store encrypts the values of the entries whose keys have one of the
+// configured prefixes. Other entries are passed to the wrapped store as is.
+type Store struct {
+	storage.StateStorer
+	cipher   *atrest.Cipher
+	prefixes []string
+}
+
+// New wraps the given state store so that the values of entries with the
+// given key prefixes are encrypted with the cipher. Sensitive entries that
+// were stored before the encryption was enabled, or before their prefix was
+// added, are encrypted in place.
+func New(store storage.StateStorer, c *atrest.Cipher, prefixes ...string) (*Store, error) {
+	s := &Store{
+		StateStorer: store,
+		cipher:      c,
+		prefixes:    prefixes,
+	}
+
+	var check []byte
+	err := store.Get(keyCheckKey, &check)
+	switch {
+	case err == nil:
+		if !bytes.Equal(check, c.KeyCheck()) {
+			return nil, ErrKeyMismatch
+		}
+	case errors.Is(err, storage.ErrNotFound):
+		if err := store.Put(keyCheckKey, c.KeyCheck()); err != nil {
+			return nil, fmt.Errorf("put key check: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("get key check: %w", err)
+	}
+
+	if err := s.migrate(); err != nil {
+		return nil, fmt.Errorf("encrypt existing entries: %w", err)
+	}
+	return s, nil
+}
+
+// migrate encrypts the plain values of the sensitive entries. The sealed
+// values are authenticated by the cipher, so the entries that it opens are
+// already encrypted.
+func (s *Store) migrate() error {
+	for _, prefix := range s.prefixes {
+		plain := make(map[string][]byte)
+		err := s.StateStorer.Iterate(prefix, func(key, val []byte) (bool, error) {
+			if _, err := s.cipher.Open(nil, val); err != nil {
+				plain[string(key)] = bytes.Clone(val)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+		for key, val := range plain {
+			if err := s.put(key, val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get implements the storage.StateStorer interface.
+func (s *Store) Get(key string, obj interface{}) error {
+	if !s.sensitive(key) {
+		return s.StateStorer.Get(key, obj)
+	}
+
+	var v sealedValue
+	if err := s.StateStorer.Get(key, &v); err != nil {
+		return err
+	}
+	data, err := s.cipher.Open(nil, v)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", key, err)
+	}
+
+	if unmarshaler, ok := obj.(encoding.BinaryUnmarshaler); ok {
+		return unmarshaler.UnmarshalBinary(data)
+	}
+	return json.Unmarshal(data, obj)
+}
+
+// Put implements the storage.StateStorer interface.
+func (s *Store) Put(key string, obj interface{}) error {
+	if !s.sensitive(key) {
+		return s.StateStorer.Put(key, obj)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if marshaler, ok := obj.(encoding.BinaryMarshaler); ok {
+		data, err = marshaler.MarshalBinary()
+	} else {
+		data, err = json.Marshal(obj)
+	}
+	if err != nil {
+		return err
+	}
+	return s.put(key, data)
+}
+
+// Iterate implements the storage.StateStorer interface.
+// The values of sensitive entries are passed to iterFunc decrypted.
+func (s *Store) Iterate(prefix string, iterFunc storage.StateIterFunc) error {
+	return s.StateStorer.Iterate(prefix, func(key, val []byte) (bool, error) {
+		if s.sensitive(string(key)) {
+			data, err := s.cipher.Open(nil, val)
+			if err != nil {
+				return true, fmt.Errorf("open %s: %w", key, err)
+			}
+			val = data
+		}
+		return iterFunc(key, val)
+	})
+}
+
+// Nuke implements the storage.StateStorerCleaner interface.
+func (s *Store) Nuke() error {
+	if c, ok := s.StateStorer.(storage.StateStorerCleaner); ok {
+		return c.Nuke()
+	}
+	return nil
+}
+
+// ClearForHopping implements the storage.StateStorerCleaner interface.
+func (s *Store) ClearForHopping() error {
+	if c, ok := s.StateStorer.(storage.StateStorerCleaner); ok {
+		return c.ClearForHopping()
+	}
+	return nil
+}
+
+func (s *Store) put(key string, data []byte) error {
+	sealed, err := s.cipher.Seal(nil, data)
+	if err != nil {
+		return err
+	}
+	return s.StateStorer.Put(key, sealedValue(sealed))
+}
+
+func (s *Store) sensitive(key string) bool {
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sealedValue is stored in the wrapped store as is, without any encoding.
+type sealedValue []byte
+
+func (v sealedValue) MarshalBinary() ([]byte, error) {
+	return v, nil
+}
+
+func (v *sealedValue) UnmarshalBinary(data []byte) error {
+	*v = bytes.Clone(data)
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encrypted_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
+	"github.com/ethersphere/bee/v2/pkg/statestore/encrypted"
+	"github.com/ethersphere/bee/v2/pkg/statestore/mock"
+)
+
+type value struct {
+	Name string `json:"name"`
+}
+
+func newCipher(t *testing.T, b byte) *atrest.Cipher {
+	t.Helper()
+	c, err := atrest.New(bytes.Repeat([]byte{b}, atrest.KeyLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	inner := mock.NewStateStore()
+	if err := inner.Put("secret_old", value{Name: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := encrypted.New(inner, newCipher(t, 1), "secret_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Put("secret_new", value{Name: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put("public", value{Name: "public"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{"secret_old": "old", "secret_new": "new", "public": "public"} {
+		var got value
+		if err := s.Get(key, &got); err != nil {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		if got.Name != want {
+			t.Fatalf("got %q under %s, want %q", got.Name, key, want)
+		}
+	}
+
+	// the wrapped store must not hold the sensitive values in plain form
+	var raw value
+	if err := inner.Get("secret_new", &raw); err == nil && raw.Name == "new" {
+		t.Fatal("sensitive value stored in plain form")
+	}
+	if err := inner.Get("public", &raw); err != nil || raw.Name != "public" {
+		t.Fatalf("got %v, %v, want public value in plain form", raw, err)
+	}
+
+	var names []string
+	err = s.Iterate("secret_", func(_, val []byte) (bool, error) {
+		names = append(names, string(val))
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("got %d entries, want 2", len(names))
+	}
+	for _, n := range names {
+		if !bytes.Contains([]byte(n), []byte(`"name"`)) {
+			t.Fatalf("got undecrypted value %q", n)
+		}
+	}
+
+	t.Run("reopen", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := encrypted.New(inner, newCipher(t, 1), "secret_")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got value
+		if err := s.Get("secret_old", &got); err != nil || got.Name != "old" {
+			t.Fatalf("got %v, %v, want old value", got, err)
+		}
+	})
+
+	t.Run("added prefix", func(t *testing.T) {
+		t.Parallel()
+
+		inner := mock.NewStateStore()
+		if _, err := encrypted.New(inner, newCipher(t, 1), "secret_"); err != nil {
+			t.Fatal(err)
+		}
+		if err := inner.Put("private_old", value{Name: "old"}); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := encrypted.New(inner, newCipher(t, 1), "secret_", "private_")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var raw value
+		if err := inner.Get("private_old", &raw); err == nil && raw.Name == "old" {
+			t.Fatal("entry of the added prefix stored in plain form")
+		}
+		var got value
+		if err := s.Get("private_old", &got); err != nil || got.Name != "old" {
+			t.Fatalf("got %v, %v, want old value", got, err)
+		}
+
+		// the encrypted entries are not encrypted again
+		if _, err := encrypted.New(inner, newCipher(t, 1), "secret_", "private_"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Get("private_old", &got); err != nil || got.Name != "old" {
+			t.Fatalf("got %v, %v, want old value", got, err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := encrypted.New(inner, newCipher(t, 2), "secret_"); !errors.Is(err, encrypted.ErrKeyMismatch) {
+			t.Fatalf("got error %v, want %v", err, encrypted.ErrKeyMismatch)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/steward"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
@@ -29,6 +30,13 @@ func (s *Steward) IsRetrievable(_ context.Context, addr swarm.Address) (bool, er
 	return addr.Equal(s.addr), nil
 }
 
+// Repair implements steward.Interface Repair method.
+// The given address is recorded and an empty report is returned.
+func (s *Steward) Repair(_ context.Context, addr swarm.Address, _ postage.Stamper) (*steward.Report, error) {
+	s.addr = addr
+	return new(steward.Report), nil
+}
+
 // LastAddress returns the last address given to the Reupload method call.
 func (s *Steward) LastAddress() swarm.Address {
 	return s.addr
@@ -0,0 +1,383 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package steward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/v2/pkg/bmt"
+	"github.com/ethersphere/bee/v2/pkg/cac"
+	"github.com/ethersphere/bee/v2/pkg/encryption"
+	encstore "github.com/ethersphere/bee/v2/pkg/encryption/store"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/loadsave"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	"github.com/ethersphere/bee/v2/pkg/manifest"
+	"github.com/ethersphere/bee/v2/pkg/manifest/mantaray"
+	"github.com/ethersphere/bee/v2/pkg/postage"
+	"github.com/ethersphere/bee/v2/pkg/replicas"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	storer "github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/klauspost/reedsolomon"
+	"golang.org/x/sync/errgroup"
+)
+
+// repairFetchConcurrency is the number of children of an intermediate chunk
+// that are retrieved concurrently during the repair.
+const repairFetchConcurrency = 16
+
+// NodeHealth is the health of the children of an intermediate chunk.
+type NodeHealth struct {
+	// Address is the address of the intermediate chunk.
+	Address swarm.Address
+	// Level is the redundancy level of the intermediate chunk.
+	Level redundancy.Level
+	// Shards is the number of the data chunks of the intermediate chunk.
+	Shards int
+	// Parities is the number of the parity chunks of the intermediate chunk.
+	Parities int
+	// MissingShards is the number of the data chunks that were not retrievable.
+	MissingShards int
+	// MissingParities is the number of the parity chunks that were not retrievable.
+	MissingParities int
+	// Repaired is the number of the missing chunks that were rebuilt and pushed.
+	Repaired int
+}
+
+// Healthy reports whether all the children of the node are retrievable
+// after the repair.
+func (n NodeHealth) Healthy() bool {
+	return n.MissingShards+n.MissingParities == n.Repaired
+}
+
+// Report is the result of the repair of the content.
+type Report struct {
+	// Nodes is the health of every intermediate chunk that was reached,
+	// parents before their children.
+	Nodes []NodeHealth
+	// Missing is the number of the chunks that were not retrievable.
+	Missing int
+	// Repaired is the number of the missing chunks that were rebuilt and pushed.
+	Repaired int
+}
+
+// Healthy reports whether all the reached chunks of the content are
+// retrievable after the repair.
+func (r *Report) Healthy() bool {
+	return r.Missing == r.Repaired
+}
+
+// repairer walks the file trees of the content and repairs their
+// intermediate chunks one by one.
+type repairer struct {
+	steward *steward
+	stamper postage.Stamper
+	session storer.PutterSession
+	report  *Report
+	roots   map[string]swarm.Chunk // root chunks that were already retrieved
+	trees   map[string]struct{}    // references of the repaired file trees
+}
+
+// Repair implements Interface.Repair method.
+// Every intermediate chunk of the content is checked for the retrievability
+// of its data and parity chunks. The missing ones are rebuilt from the
+// retrieved ones with the erasure code, if the content was uploaded with
+// redundancy, and only those are stamped and pushed to the network.
+func (s *steward) Repair(ctx context.Context, root swarm.Address, stamper postage.Stamper) (*Report, error) {
+	r := &repairer{
+		steward: s,
+		stamper: stamper,
+		session: s.netStore.DirectUpload(),
+		report:  new(Report),
+		roots:   make(map[string]swarm.Chunk),
+		trees:   make(map[string]struct{}),
+	}
+
+	if err := r.repair(ctx, root); err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("repair of %s failed: %w", root, err),
+			r.session.Cleanup(),
+		)
+	}
+
+	if err := r.session.Done(root); err != nil {
+		return nil, err
+	}
+	return r.report, nil
+}
+
+// repair repairs the file tree of the given address and, if it is a
+// manifest, the file trees of all its nodes and entries.
+func (r *repairer) repair(ctx context.Context, root swarm.Address) error {
+	// skip SOC check for encrypted references
+	if root.IsValidLength() {
+		ch, err := r.root(ctx, root)
+		if err != nil {
+			return err
+		}
+		if soc.Valid(ch) {
+			// a SOC is a single chunk that has no redundancy
+			return nil
+		}
+	}
+	if err := r.repairTree(ctx, root); err != nil {
+		return err
+	}
+	if !r.report.Healthy() {
+		// the content can not be loaded as a manifest
+		return nil
+	}
+
+	// the chunks that the joiner recovers while loading the manifest
+	// are kept locally, they were pushed to the network by the repair
+	ls := loadsave.New(r.steward.netStore.Download(true), r.steward.joinerPutter, nil)
+	switch mf, err := manifest.NewDefaultManifestReference(root, ls); {
+	case errors.Is(err, manifest.ErrInvalidManifestType):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to create manifest reference for %q: %w", root, err)
+	default:
+		err := mf.IterateAddresses(ctx, func(ref swarm.Address) error {
+			return r.repairTree(ctx, ref)
+		})
+		if errors.Is(err, mantaray.ErrTooShort) || errors.Is(err, mantaray.ErrInvalidVersionHash) {
+			// not a manifest, the bytes were already repaired
+			return nil
+		}
+		return err
+	}
+}
+
+// repairTree repairs the file tree of the given reference once.
+func (r *repairer) repairTree(ctx context.Context, ref swarm.Address) error {
+	if _, ok := r.trees[ref.ByteString()]; ok {
+		return nil
+	}
+	r.trees[ref.ByteString()] = struct{}{}
+
+	ch, err := r.root(ctx, swarm.NewAddress(ref.Bytes()[:swarm.HashSize]))
+	if err != nil {
+		return err
+	}
+	return r.repairNode(ctx, ref, ch.Data())
+}
+
+// root retrieves the root chunk of a file tree from the network. A missing
+// root chunk is retrieved from its dispersed replicas and pushed again.
+func (r *repairer) root(ctx context.Context, addr swarm.Address) (swarm.Chunk, error) {
+	if ch, ok := r.roots[addr.ByteString()]; ok {
+		return ch, nil
+	}
+	ch, err := r.steward.netGetter.RetrieveChunk(ctx, addr, swarm.ZeroAddress)
+	if err == nil {
+		r.roots[addr.ByteString()] = ch
+		return ch, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	level := redundancy.GetLevelFromContext(ctx)
+	if level == redundancy.NONE {
+		return nil, fmt.Errorf("root chunk %s: %w", addr, err)
+	}
+	ch, err = replicas.NewGetter(&netGetter{r.steward.netGetter}, level).Get(ctx, addr)
+	if err != nil {
+		r.record(1, 0)
+		return nil, fmt.Errorf("root chunk %s: %w", addr, err)
+	}
+	ch = swarm.NewChunk(addr, ch.Data())
+	if !cac.Valid(ch) {
+		r.record(1, 0)
+		return nil, fmt.Errorf("root chunk %s: invalid replica", addr)
+	}
+	if err := r.push(ctx, ch); err != nil {
+		return nil, err
+	}
+	r.record(1, 1)
+	r.roots[addr.ByteString()] = ch
+	return ch, nil
+}
+
+// repairNode repairs the children of the chunk with the given reference and
+// raw data, then the subtrees of its data chunks. Leaf chunks are skipped.
+func (r *repairer) repairNode(ctx context.Context, ref swarm.Address, raw []byte) error {
+	var (
+		refLen    = len(ref.Bytes())
+		encrypted = refLen == encryption.ReferenceSize
+		data      = raw
+		err       error
+	)
+	if encrypted {
+		data, err = encstore.DecryptChunkData(raw, ref.Bytes()[swarm.HashSize:])
+		if err != nil {
+			return fmt.Errorf("decrypt chunk %s: %w", ref, err)
+		}
+	}
+
+	level, span := chunkToSpan(data)
+	if span <= swarm.ChunkSize {
+		return nil
+	}
+	parities := 0
+	if level != redundancy.NONE {
+		_, parities = file.ReferenceCount(span, level, encrypted)
+	}
+
+	payload := data[swarm.SpanSize:]
+	pSize, err := file.ChunkPayloadSize(payload)
+	if err != nil {
+		return fmt.Errorf("chunk %s: %w", ref, err)
+	}
+	addrs, shardCnt := file.ChunkAddresses(payload[:pSize], parities, refLen)
+
+	chunks, err := r.fetch(ctx, addrs)
+	if err != nil {
+		return err
+	}
+
+	health := NodeHealth{
+		Address:  swarm.NewAddress(ref.Bytes()[:swarm.HashSize]),
+		Level:    level,
+		Shards:   shardCnt,
+		Parities: parities,
+	}
+	var missing []int
+	for i, c := range chunks {
+		if c != nil {
+			continue
+		}
+		missing = append(missing, i)
+		if i < shardCnt {
+			health.MissingShards++
+		} else {
+			health.MissingParities++
+		}
+	}
+
+	if len(missing) > 0 && len(missing) <= parities {
+		health.Repaired, err = r.reconstruct(ctx, addrs, chunks, missing, shardCnt, parities, encrypted)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.report.Nodes = append(r.report.Nodes, health)
+	r.record(len(missing), health.Repaired)
+
+	for i := 0; i < shardCnt; i++ {
+		if chunks[i] == nil {
+			continue
+		}
+		childRef := swarm.NewAddress(payload[i*refLen : (i+1)*refLen])
+		if err := r.repairNode(ctx, childRef, chunks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch retrieves the chunks with the given addresses from the network.
+// The data of the chunks that are not retrievable is nil.
+func (r *repairer) fetch(ctx context.Context, addrs []swarm.Address) ([][]byte, error) {
+	chunks := make([][]byte, len(addrs))
+
+	eg, ectx := errgroup.WithContext(ctx)
+	eg.SetLimit(repairFetchConcurrency)
+	for i, addr := range addrs {
+		eg.Go(func() error {
+			ch, err := r.steward.netGetter.RetrieveChunk(ectx, addr, swarm.ZeroAddress)
+			if err != nil {
+				// the chunk is missing, unless the repair was canceled
+				return ctx.Err()
+			}
+			chunks[i] = ch.Data()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// reconstruct rebuilds the missing chunks of an intermediate chunk with the
+// erasure code and pushes them. The rebuilt data is set in chunks and the
+// number of the pushed chunks is returned.
+func (r *repairer) reconstruct(ctx context.Context, addrs []swarm.Address, chunks [][]byte, missing []int, shardCnt, parities int, encrypted bool) (int, error) {
+	shards := make([][]byte, len(chunks))
+	for i, c := range chunks {
+		if c == nil {
+			continue
+		}
+		// the data chunks are padded to the full chunk size for the encoding
+		shards[i] = make([]byte, swarm.ChunkWithSpanSize)
+		copy(shards[i], c)
+	}
+
+	enc, err := reedsolomon.New(shardCnt, parities)
+	if err != nil {
+		return 0, err
+	}
+	if err := enc.Reconstruct(shards); err != nil {
+		// the node is reported as not healthy
+		return 0, nil
+	}
+
+	repaired := 0
+	for _, i := range missing {
+		data := shards[i]
+		if i < shardCnt && !encrypted {
+			data = trimShard(data)
+		}
+		ch := swarm.NewChunk(addrs[i], data)
+		if !cac.Valid(ch) {
+			continue
+		}
+		if err := r.push(ctx, ch); err != nil {
+			return repaired, err
+		}
+		chunks[i] = data
+		repaired++
+	}
+	return repaired, nil
+}
+
+// push stamps the chunk and pushes it to the network.
+func (r *repairer) push(ctx context.Context, ch swarm.Chunk) error {
+	stamp, err := r.stamper.Stamp(ch.Address())
+	if err != nil {
+		return fmt.Errorf("stamping chunk %s: %w", ch.Address(), err)
+	}
+	return r.session.Put(ctx, ch.WithStamp(stamp))
+}
+
+// record adds the missing and repaired chunk counts to the report.
+func (r *repairer) record(missing, repaired int) {
+	r.report.Missing += missing
+	r.report.Repaired += repaired
+}
+
+// trimShard removes the padding of a rebuilt unencrypted data chunk.
+func trimShard(data []byte) []byte {
+	_, span := chunkToSpan(data)
+	if span <= swarm.ChunkSize {
+		return data[:swarm.SpanSize+span]
+	}
+	pSize, err := file.ChunkPayloadSize(data[swarm.SpanSize:])
+	if err != nil {
+		return data
+	}
+	return data[:swarm.SpanSize+pSize]
+}
+
+// chunkToSpan returns the redundancy level and the span of the chunk data.
+func chunkToSpan(data []byte) (redundancy.Level, uint64) {
+	level, span := redundancy.DecodeSpan(data[:swarm.SpanSize])
+	return level, bmt.LengthFromSpan(span)
+}
@@ -0,0 +1,234 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package steward_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/bmt"
+	"github.com/ethersphere/bee/v2/pkg/file"
+	"github.com/ethersphere/bee/v2/pkg/file/joiner"
+	"github.com/ethersphere/bee/v2/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/v2/pkg/file/redundancy"
+	postagetesting "github.com/ethersphere/bee/v2/pkg/postage/mock"
+	"github.com/ethersphere/bee/v2/pkg/pusher"
+	"github.com/ethersphere/bee/v2/pkg/steward"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storage/inmemchunkstore"
+	mockstorer "github.com/ethersphere/bee/v2/pkg/storer/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestRepair(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = redundancy.SetLevelInContext(context.Background(), redundancy.NONE)
+		chunkStore = inmemchunkstore.New()
+		store      = mockstorer.NewWithChunkStore(chunkStore)
+		s          = steward.New(store, &localRetriever{ChunkStore: chunkStore}, chunkStore)
+		data       = make([]byte, 300*swarm.ChunkSize+100)
+	)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	pipe := builder.NewPipelineBuilder(ctx, chunkStore, false, redundancy.MEDIUM)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// remove two data chunks and a parity chunk of the first
+	// intermediate chunk below the root
+	rootChildren, rootShardCnt := children(t, chunkStore, root)
+	nodeChildren, shardCnt := children(t, chunkStore, rootChildren[0])
+	deleted := []swarm.Address{nodeChildren[0], nodeChildren[shardCnt-1], nodeChildren[shardCnt]}
+	for _, addr := range deleted {
+		if err := chunkStore.Delete(ctx, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pushed := pushToStore(t, store, chunkStore, func() {
+		report, err := s.Repair(ctx, root, postagetesting.NewStamper())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Healthy() {
+			t.Fatalf("content should be healthy after the repair: %+v", report)
+		}
+		if report.Missing != len(deleted) || report.Repaired != len(deleted) {
+			t.Fatalf("got %d missing and %d repaired chunks, want %d", report.Missing, report.Repaired, len(deleted))
+		}
+		if len(report.Nodes) != 1+rootShardCnt {
+			t.Fatalf("got %d nodes, want %d", len(report.Nodes), 1+rootShardCnt)
+		}
+		node := report.Nodes[1]
+		if !node.Address.Equal(rootChildren[0]) || node.MissingShards != 2 || node.MissingParities != 1 || node.Repaired != 3 {
+			t.Fatalf("unexpected health of the repaired node: %+v", node)
+		}
+	})
+
+	if len(pushed) != len(deleted) {
+		t.Fatalf("got %d pushed chunks, want %d", len(pushed), len(deleted))
+	}
+	for _, addr := range deleted {
+		if _, ok := pushed[addr.ByteString()]; !ok {
+			t.Fatalf("chunk %s was not pushed", addr)
+		}
+	}
+
+	// the content is readable without the recovery
+	j, _, err := joiner.New(ctx, chunkStore, chunkStore, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("repaired content does not match the uploaded data")
+	}
+}
+
+func TestRepairEncrypted(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = redundancy.SetLevelInContext(context.Background(), redundancy.NONE)
+		chunkStore = inmemchunkstore.New()
+		store      = mockstorer.NewWithChunkStore(chunkStore)
+		s          = steward.New(store, &localRetriever{ChunkStore: chunkStore}, chunkStore)
+		data       = make([]byte, 100*swarm.ChunkSize)
+	)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	pipe := builder.NewPipelineBuilder(ctx, chunkStore, true, redundancy.MEDIUM)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushed := pushToStore(t, store, chunkStore, func() {
+		report, err := s.Repair(ctx, root, postagetesting.NewStamper())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.Healthy() || report.Missing != 0 {
+			t.Fatalf("intact content should be healthy: %+v", report)
+		}
+		// the encrypted references halve the branching of the tree
+		if len(report.Nodes) != 3 {
+			t.Fatalf("got %d nodes, want 3", len(report.Nodes))
+		}
+	})
+	if len(pushed) != 0 {
+		t.Fatalf("got %d pushed chunks, want none", len(pushed))
+	}
+}
+
+func TestRepairUnrecoverable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = redundancy.SetLevelInContext(context.Background(), redundancy.NONE)
+		chunkStore = inmemchunkstore.New()
+		store      = mockstorer.NewWithChunkStore(chunkStore)
+		s          = steward.New(store, &localRetriever{ChunkStore: chunkStore}, chunkStore)
+		data       = make([]byte, 10*swarm.ChunkSize)
+	)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	pipe := builder.NewPipelineBuilder(ctx, chunkStore, false, redundancy.NONE)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootChildren, _ := children(t, chunkStore, root)
+	if err := chunkStore.Delete(ctx, rootChildren[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	pushed := pushToStore(t, store, chunkStore, func() {
+		report, err := s.Repair(ctx, root, postagetesting.NewStamper())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Healthy() {
+			t.Fatal("content without redundancy should not be healthy")
+		}
+		if len(report.Nodes) != 1 {
+			t.Fatalf("got %d nodes, want 1", len(report.Nodes))
+		}
+		if node := report.Nodes[0]; node.MissingShards != 1 || node.Repaired != 0 || node.Healthy() {
+			t.Fatalf("unexpected health of the root node: %+v", node)
+		}
+	})
+	if len(pushed) != 0 {
+		t.Fatalf("got %d pushed chunks, want none", len(pushed))
+	}
+}
+
+// children returns the addresses of the data and parity chunks below the
+// intermediate chunk with the given address and the number of data chunks.
+func children(t *testing.T, store storage.Getter, addr swarm.Address) ([]swarm.Address, int) {
+	t.Helper()
+
+	ch, err := store.Get(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, span := redundancy.DecodeSpan(ch.Data()[:swarm.SpanSize])
+	parities := 0
+	if level != redundancy.NONE {
+		_, parities = file.ReferenceCount(bmt.LengthFromSpan(span), level, false)
+	}
+	payload := ch.Data()[swarm.SpanSize:]
+	return file.ChunkAddresses(payload, parities, swarm.HashSize)
+}
+
+// pushToStore calls fn while storing the chunks pushed by the storer in the
+// chunk store, and returns the pushed chunks by address.
+func pushToStore(t *testing.T, store interface {
+	PusherFeed() <-chan *pusher.Op
+}, chunkStore storage.Putter, fn func()) map[string]swarm.Chunk {
+	t.Helper()
+
+	var (
+		pushed = make(map[string]swarm.Chunk)
+		quit   = make(chan struct{})
+		done   = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case op := <-store.PusherFeed():
+				pushed[op.Chunk.Address().ByteString()] = op.Chunk
+				if err := chunkStore.Put(context.Background(), op.Chunk); err != nil {
+					t.Error(err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	fn()
+	close(quit)
+	<-done
+	return pushed
+}
@@ -28,6 +28,11 @@ type Interface interface {
 	// IsRetrievable checks whether the content
 	// on the given address is retrievable.
 	IsRetrievable(context.Context, swarm.Address) (bool, error)
+
+	// Repair checks the retrievability of every chunk of the content on the
+	// given address, rebuilds the missing ones from the erasure code and
+	// pushes only those to the network.
+	Repair(context.Context, swarm.Address, postage.Stamper) (*Report, error)
 }
 
 type steward struct {
@@ -35,6 +40,7 @@ type steward struct {
 	traverser    traversal.Traverser
 	netTraverser traversal.Traverser
 	netGetter    retrieval.Interface
+	joinerPutter storage.Putter
 }
 
 func New(ns storer.NetStore, r retrieval.Interface, joinerPutter storage.Putter) Interface {
@@ -43,6 +49,7 @@ func New(ns storer.NetStore, r retrieval.Interface, joinerPutter storage.Putter)
 		traverser:    traversal.New(ns.Download(true), joinerPutter),
 		netTraverser: traversal.New(&netGetter{r}, joinerPutter),
 		netGetter:    r,
+		joinerPutter: joinerPutter,
 	}
 }
 
@@ -53,6 +53,20 @@ func (c *ChunkStore) Put(_ context.Context, ch swarm.Chunk) error {
 	return nil
 }
 
+func (c *ChunkStore) Replace(_ context.Context, ch swarm.Chunk) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	chunkCount, ok := c.chunks[ch.Address().ByteString()]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	chunkCount.chunk = swarm.NewChunk(ch.Address(), ch.Data()).WithStamp(ch.Stamp())
+	c.chunks[ch.Address().ByteString()] = chunkCount
+
+	return nil
+}
+
 func (c *ChunkStore) Has(_ context.Context, addr swarm.Address) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -113,6 +113,7 @@ func (c *contract) Claim(ctx context.Context, proofs ChunkInclusionProofs) (comm
 		MinEstimatedGasLimit: 500_000,
 		Value:                big.NewInt(0),
 		Description:          "claim win transaction",
+		Priority:             transaction.PriorityRedistribution,
 	}
 	txHash, err := c.sendAndWait(ctx, request, 50)
 	if err != nil {
@@ -136,6 +137,7 @@ func (c *contract) Commit(ctx context.Context, obfusHash []byte, round uint64) (
 		MinEstimatedGasLimit: 500_000,
 		Value:                big.NewInt(0),
 		Description:          "commit transaction",
+		Priority:             transaction.PriorityRedistribution,
 	}
 	txHash, err := c.sendAndWait(ctx, request, 50)
 	if err != nil {
@@ -159,6 +161,7 @@ func (c *contract) Reveal(ctx context.Context, storageDepth uint8, reserveCommit
 		MinEstimatedGasLimit: 500_000,
 		Value:                big.NewInt(0),
 		Description:          "reveal transaction",
+		Priority:             transaction.PriorityRedistribution,
 	}
 	txHash, err := c.sendAndWait(ctx, request, 50)
 	if err != nil {
@@ -14,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
 	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
 	"github.com/ethersphere/go-sw3-abi/sw3abi"
@@ -29,6 +30,9 @@ var (
 	ErrInsufficientStake       = errors.New("insufficient stake")
 	ErrNotImplemented          = errors.New("not implemented")
 	ErrNotPaused               = errors.New("contract is not paused")
+	ErrStakeFrozen             = errors.New("stake is frozen")
+	ErrWithdrawAmountTooHigh   = errors.New("withdraw amount exceeds the withdrawable stake")
+	ErrInvalidWithdrawAmount   = errors.New("withdraw amount must be positive")
 
 	approveDescription       = "Approve tokens for stake deposit operations"
 	depositStakeDescription  = "Deposit Stake"
@@ -40,9 +44,15 @@ type Contract interface {
 	DepositStake(ctx context.Context, stakedAmount *big.Int) (common.Hash, error)
 	ChangeStakeOverlay(ctx context.Context, nonce common.Hash) (common.Hash, error)
 	GetPotentialStake(ctx context.Context) (*big.Int, error)
+	// GetStakedOverlay returns the overlay the stake is registered with.
+	GetStakedOverlay(ctx context.Context) (swarm.Address, error)
 	GetWithdrawableStake(ctx context.Context) (*big.Int, error)
 	WithdrawStake(ctx context.Context) (common.Hash, error)
+	// WithdrawStakeAmount withdraws the amount of the withdrawable stake.
+	WithdrawStakeAmount(ctx context.Context, amount *big.Int) (common.Hash, error)
 	MigrateStake(ctx context.Context) (common.Hash, error)
+	// Address returns the address of the staking contract.
+	Address() common.Address
 	RedistributionStatuser
 }
 
@@ -139,6 +149,15 @@ func (c *contract) GetPotentialStake(ctx context.Context) (*big.Int, error) {
 	return stakedAmount, nil
 }
 
+func (c *contract) GetStakedOverlay(ctx context.Context) (swarm.Address, error) {
+	results, err := c.stakes(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("staking contract: failed to get staked overlay: %w", err)
+	}
+	overlay := abi.ConvertType(results[0], new([32]byte)).(*[32]byte)
+	return swarm.NewAddress(overlay[:]), nil
+}
+
 func (c *contract) GetWithdrawableStake(ctx context.Context) (*big.Int, error) {
 	stakedAmount, err := c.getwithdrawableStake(ctx)
 	if err != nil {
@@ -167,6 +186,49 @@ func (c *contract) WithdrawStake(ctx context.Context) (txHash common.Hash, err e
 	return txHash, nil
 }
 
+// WithdrawStakeAmount withdraws the amount of the withdrawable stake. The
+// staking contract withdraws the whole withdrawable stake, so the rest of it
+// is deposited again with a second transaction, which updates the stake like
+// any other deposit. The hash of the withdrawal is returned also when the
+// deposit of the rest fails.
+func (c *contract) WithdrawStakeAmount(ctx context.Context, amount *big.Int) (txHash common.Hash, err error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return common.Hash{}, ErrInvalidWithdrawAmount
+	}
+
+	withdrawable, err := c.getwithdrawableStake(ctx)
+	if err != nil {
+		return
+	}
+
+	if withdrawable.Sign() <= 0 {
+		return common.Hash{}, ErrInsufficientStake
+	}
+	if amount.Cmp(withdrawable) > 0 {
+		return common.Hash{}, ErrWithdrawAmountTooHigh
+	}
+
+	receipt, err := c.withdrawFromStake(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt != nil {
+		txHash = receipt.TxHash
+	}
+
+	rest := new(big.Int).Sub(withdrawable, amount)
+	if rest.Sign() == 0 {
+		return txHash, nil
+	}
+	if _, err := c.sendApproveTransaction(ctx, rest); err != nil {
+		return txHash, fmt.Errorf("deposit the rest of the withdrawn stake: %w", err)
+	}
+	if _, err := c.sendDepositStakeTransaction(ctx, rest, c.overlayNonce); err != nil {
+		return txHash, fmt.Errorf("deposit the rest of the withdrawn stake: %w", err)
+	}
+	return txHash, nil
+}
+
 func (c *contract) MigrateStake(ctx context.Context) (txHash common.Hash, err error) {
 	isPaused, err := c.paused(ctx)
 	if err != nil {
@@ -186,6 +248,10 @@ func (c *contract) MigrateStake(ctx context.Context) (txHash common.Hash, err er
 	return txHash, nil
 }
 
+func (c *contract) Address() common.Address {
+	return c.stakingContractAddress
+}
+
 func (c *contract) IsOverlayFrozen(ctx context.Context, block uint64) (bool, error) {
 	callData, err := c.stakingContractABI.Pack("lastUpdatedBlockNumberOfAddress", c.owner)
 	if err != nil {
@@ -227,6 +293,7 @@ func (c *contract) sendApproveTransaction(ctx context.Context, amount *big.Int)
 		GasLimit:    65000,
 		Value:       big.NewInt(0),
 		Description: approveDescription,
+		Priority:    transaction.PriorityStake,
 	}
 
 	defer func() {
@@ -263,6 +330,7 @@ func (c *contract) sendTransaction(ctx context.Context, callData []byte, desc st
 		GasLimit:    max(sctx.GetGasLimit(ctx), c.gasLimit),
 		Value:       big.NewInt(0),
 		Description: desc,
+		Priority:    transaction.PriorityStake,
 	}
 
 	defer func() {
@@ -306,6 +374,15 @@ func (c *contract) sendDepositStakeTransaction(ctx context.Context, stakedAmount
 }
 
 func (c *contract) getPotentialStake(ctx context.Context) (*big.Int, error) {
+	results, err := c.stakes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get potential stake: %w", err)
+	}
+	return abi.ConvertType(results[2], new(big.Int)).(*big.Int), nil
+}
+
+// stakes returns the stake of the owner.
+func (c *contract) stakes(ctx context.Context) ([]interface{}, error) {
 	callData, err := c.stakingContractABI.Pack("stakes", c.owner)
 	if err != nil {
 		return nil, err
@@ -315,7 +392,7 @@ func (c *contract) getPotentialStake(ctx context.Context) (*big.Int, error) {
 		Data: callData,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("get potential stake: %w", err)
+		return nil, err
 	}
 
 	// overlay bytes32,
@@ -331,8 +408,7 @@ func (c *contract) getPotentialStake(ctx context.Context) (*big.Int, error) {
 	if len(results) < 5 {
 		return nil, errors.New("unexpected empty results")
 	}
-
-	return abi.ConvertType(results[2], new(big.Int)).(*big.Int), nil
+	return results, nil
 }
 
 func (c *contract) getwithdrawableStake(ctx context.Context) (*big.Int, error) {
@@ -1246,6 +1246,130 @@ func TestWithdrawStake(t *testing.T) {
 	})
 }
 
+func TestWithdrawStakeAmount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	owner := common.HexToAddress("abcd")
+	stakingContractAddress := common.HexToAddress("ffff")
+	bzzTokenAddress := common.HexToAddress("eeee")
+	nonce := common.BytesToHash(make([]byte, 32))
+	withdrawable := big.NewInt(1000)
+
+	expectedCallDataForWithdraw, err := stakingContractABI.Pack("withdrawFromStake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCallDataForGetStake, err := stakingContractABI.Pack("withdrawableStake")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newContract returns a staking contract with the withdrawable stake
+	// that records the call data of the sent transactions.
+	newContract := func(t *testing.T) (staking.Contract, func() [][]byte) {
+		t.Helper()
+
+		var sent [][]byte
+		contract := staking.New(
+			owner,
+			stakingContractAddress,
+			stakingContractABI,
+			bzzTokenAddress,
+			transactionMock.New(
+				transactionMock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest, boost int) (txHash common.Hash, err error) {
+					sent = append(sent, request.Data)
+					return common.BigToHash(big.NewInt(int64(len(sent)))), nil
+				}),
+				transactionMock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+					return &types.Receipt{Status: 1, TxHash: txHash}, nil
+				}),
+				transactionMock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) (result []byte, err error) {
+					if *request.To == stakingContractAddress && bytes.Equal(expectedCallDataForGetStake[:4], request.Data[:4]) {
+						return withdrawable.FillBytes(make([]byte, 32)), nil
+					}
+					return nil, errors.New("unexpected call")
+				}),
+			),
+			nonce,
+			false,
+		)
+		return contract, func() [][]byte { return sent }
+	}
+
+	t.Run("whole withdrawable stake", func(t *testing.T) {
+		t.Parallel()
+
+		contract, sent := newContract(t)
+		txHash, err := contract.WithdrawStakeAmount(ctx, withdrawable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txHash != common.BigToHash(big.NewInt(1)) {
+			t.Fatalf("got tx hash %s, want the hash of the withdrawal", txHash)
+		}
+		if got := sent(); len(got) != 1 || !bytes.Equal(got[0], expectedCallDataForWithdraw) {
+			t.Fatalf("got transactions %x, want the withdrawal only", got)
+		}
+	})
+
+	t.Run("part of the withdrawable stake", func(t *testing.T) {
+		t.Parallel()
+
+		rest := big.NewInt(600)
+		expectedCallDataForApprove, err := staking.Erc20ABI.Pack("approve", stakingContractAddress, rest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedCallDataForDeposit, err := stakingContractABI.Pack("manageStake", nonce, rest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contract, sent := newContract(t)
+		txHash, err := contract.WithdrawStakeAmount(ctx, big.NewInt(400))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txHash != common.BigToHash(big.NewInt(1)) {
+			t.Fatalf("got tx hash %s, want the hash of the withdrawal", txHash)
+		}
+		want := [][]byte{expectedCallDataForWithdraw, expectedCallDataForApprove, expectedCallDataForDeposit}
+		got := sent()
+		if len(got) != len(want) {
+			t.Fatalf("got %d transactions, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if !bytes.Equal(got[i], want[i]) {
+				t.Fatalf("transaction %d: got call data %x, want %x", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("amount too high", func(t *testing.T) {
+		t.Parallel()
+
+		contract, sent := newContract(t)
+		_, err := contract.WithdrawStakeAmount(ctx, big.NewInt(1001))
+		if !errors.Is(err, staking.ErrWithdrawAmountTooHigh) {
+			t.Fatalf("got error %v, want %v", err, staking.ErrWithdrawAmountTooHigh)
+		}
+		if len(sent()) != 0 {
+			t.Fatal("unexpected transaction")
+		}
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		t.Parallel()
+
+		contract, _ := newContract(t)
+		_, err := contract.WithdrawStakeAmount(ctx, big.NewInt(0))
+		if !errors.Is(err, staking.ErrInvalidWithdrawAmount) {
+			t.Fatalf("got error %v, want %v", err, staking.ErrInvalidWithdrawAmount)
+		}
+	})
+}
+
 func TestMigrateStake(t *testing.T) {
 	t.Parallel()
 
@@ -4,6 +4,12 @@
 
 package staking
 
+import "context"
+
 var (
 	Erc20ABI = erc20ABI
 )
+
+func (w *Watcher) Check(ctx context.Context) error {
+	return w.check(ctx)
+}
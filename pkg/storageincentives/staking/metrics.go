@@ -0,0 +1,72 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package staking
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	Events         *prometheus.CounterVec
+	Frozen         prometheus.Gauge
+	FrozenUntil    prometheus.Gauge
+	SlashedAmount  prometheus.Counter
+	CommittedStake prometheus.Gauge
+	PotentialStake prometheus.Gauge
+	WebhookErrors  prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "staking"
+
+	return metrics{
+		Events: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "events",
+				Help:      "Number of the recorded stake events by kind.",
+			},
+			[]string{"kind"},
+		),
+		Frozen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "frozen",
+			Help:      "Whether the stake is frozen at the last processed block.",
+		}),
+		FrozenUntil: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "frozen_until_block",
+			Help:      "Block until which the stake was frozen by the last freeze.",
+		}),
+		SlashedAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "slashed_amount",
+			Help:      "Amount of the slashed stake.",
+		}),
+		CommittedStake: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "committed_stake",
+			Help:      "Committed stake of the last stake update.",
+		}),
+		PotentialStake: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "potential_stake",
+			Help:      "Potential stake of the last stake update.",
+		}),
+		WebhookErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "webhook_errors",
+			Help:      "Number of the failed alert webhook requests.",
+		}),
+	}
+}
@@ -10,21 +10,29 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
 
 type stakingContractMock struct {
 	depositStake     func(ctx context.Context, stakedAmount *big.Int) (common.Hash, error)
 	getStake         func(ctx context.Context) (*big.Int, error)
+	getOverlay       func(ctx context.Context) (swarm.Address, error)
+	changeOverlay    func(ctx context.Context, nonce common.Hash) (common.Hash, error)
 	withdrawAllStake func(ctx context.Context) (common.Hash, error)
+	withdrawStake    func(ctx context.Context, amount *big.Int) (common.Hash, error)
 	migrateStake     func(ctx context.Context) (common.Hash, error)
 	isFrozen         func(ctx context.Context, block uint64) (bool, error)
+	address          common.Address
 }
 
 func (s *stakingContractMock) DepositStake(ctx context.Context, stakedAmount *big.Int) (common.Hash, error) {
 	return s.depositStake(ctx, stakedAmount)
 }
 
-func (s *stakingContractMock) ChangeStakeOverlay(_ context.Context, h common.Hash) (common.Hash, error) {
+func (s *stakingContractMock) ChangeStakeOverlay(ctx context.Context, h common.Hash) (common.Hash, error) {
+	if s.changeOverlay != nil {
+		return s.changeOverlay(ctx, h)
+	}
 	return h, nil
 }
 
@@ -32,6 +40,10 @@ func (s *stakingContractMock) GetPotentialStake(ctx context.Context) (*big.Int,
 	return s.getStake(ctx)
 }
 
+func (s *stakingContractMock) GetStakedOverlay(ctx context.Context) (swarm.Address, error) {
+	return s.getOverlay(ctx)
+}
+
 func (s *stakingContractMock) GetWithdrawableStake(ctx context.Context) (*big.Int, error) {
 	return s.getStake(ctx)
 }
@@ -40,10 +52,18 @@ func (s *stakingContractMock) WithdrawStake(ctx context.Context) (common.Hash, e
 	return s.withdrawAllStake(ctx)
 }
 
+func (s *stakingContractMock) WithdrawStakeAmount(ctx context.Context, amount *big.Int) (common.Hash, error) {
+	return s.withdrawStake(ctx, amount)
+}
+
 func (s *stakingContractMock) MigrateStake(ctx context.Context) (common.Hash, error) {
 	return s.migrateStake(ctx)
 }
 
+func (s *stakingContractMock) Address() common.Address {
+	return s.address
+}
+
 func (s *stakingContractMock) IsOverlayFrozen(ctx context.Context, block uint64) (bool, error) {
 	return s.isFrozen(ctx, block)
 }
@@ -74,12 +94,30 @@ func WithGetStake(f func(ctx context.Context) (*big.Int, error)) Option {
 	}
 }
 
+func WithGetStakedOverlay(f func(ctx context.Context) (swarm.Address, error)) Option {
+	return func(mock *stakingContractMock) {
+		mock.getOverlay = f
+	}
+}
+
+func WithChangeStakeOverlay(f func(ctx context.Context, nonce common.Hash) (common.Hash, error)) Option {
+	return func(mock *stakingContractMock) {
+		mock.changeOverlay = f
+	}
+}
+
 func WithWithdrawStake(f func(ctx context.Context) (common.Hash, error)) Option {
 	return func(mock *stakingContractMock) {
 		mock.withdrawAllStake = f
 	}
 }
 
+func WithWithdrawStakeAmount(f func(ctx context.Context, amount *big.Int) (common.Hash, error)) Option {
+	return func(mock *stakingContractMock) {
+		mock.withdrawStake = f
+	}
+}
+
 func WithMigrateStake(f func(ctx context.Context) (common.Hash, error)) Option {
 	return func(mock *stakingContractMock) {
 		mock.migrateStake = f
@@ -91,3 +129,9 @@ func WithIsFrozen(f func(ctx context.Context, block uint64) (bool, error)) Optio
 		mock.isFrozen = f
 	}
 }
+
+func WithAddress(address common.Address) Option {
+	return func(mock *stakingContractMock) {
+		mock.address = address
+	}
+}
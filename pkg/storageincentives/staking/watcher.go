@@ -0,0 +1,635 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package staking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "staking"
+
+const (
+	historyKeyPrefix    = "staking_history_"
+	lastBlockKey        = "staking_watcher_last_block"
+	pendingMigrationKey = "staking_watcher_pending_migration"
+
+	blockPage      = 5000 // maximal number of blocks filtered at once
+	tailSize       = 4    // number of the blocks behind the tip of the chain that are not processed yet
+	webhookTimeout = 10 * time.Second
+)
+
+// EventKind is the type of a record in the stake history.
+type EventKind string
+
+const (
+	EventStakeUpdated    EventKind = "stakeUpdated"
+	EventStakeFrozen     EventKind = "stakeFrozen"
+	EventStakeSlashed    EventKind = "stakeSlashed"
+	EventStakeWithdrawn  EventKind = "stakeWithdrawn"
+	EventOverlayChanged  EventKind = "overlayChanged"
+	EventOverlayMigrated EventKind = "overlayMigrated"
+)
+
+// Event is a record in the stake history. The fields that do not apply to
+// the kind of the event are left empty.
+type Event struct {
+	Kind            EventKind     `json:"kind"`
+	Block           uint64        `json:"block"`
+	TxHash          common.Hash   `json:"txHash"`
+	LogIndex        uint          `json:"logIndex"`
+	Overlay         swarm.Address `json:"overlay"`
+	PreviousOverlay swarm.Address `json:"previousOverlay"`
+	CommittedStake  *big.Int      `json:"committedStake,omitempty"`
+	PotentialStake  *big.Int      `json:"potentialStake,omitempty"`
+	Amount          *big.Int      `json:"amount,omitempty"`
+	FrozenUntil     uint64        `json:"frozenUntil,omitempty"` // block until which the stake is frozen
+	Created         time.Time     `json:"created"`
+}
+
+// FreezeStatus is the freeze state of the stake at the last processed block.
+type FreezeStatus struct {
+	Frozen      bool
+	FrozenUntil uint64
+	Block       uint64
+}
+
+// ChainBackend is the part of the chain backend that the watcher needs.
+type ChainBackend interface {
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// WatcherOptions are the parameters of the watcher.
+type WatcherOptions struct {
+	PollInterval time.Duration // interval of the checks of the staking contract events, zero disables the checks
+	WebhookURL   string        // URL that the freeze and slash alerts are posted to, empty disables the alerts
+}
+
+// Watcher follows the staking contract events of the node, keeps the stake
+// history and alerts on the freezes and the slashes of the stake.
+type Watcher struct {
+	logger   log.Logger
+	store    storage.StateStorer
+	backend  ChainBackend
+	contract Contract
+	address  common.Address
+	abi      abi.ABI
+	owner    common.Address
+	overlay  swarm.Address
+	opts     WatcherOptions
+	client   *http.Client
+	metrics  metrics
+
+	topics map[common.Hash]EventKind
+
+	mu     sync.Mutex
+	status FreezeStatus
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWatcher creates the watcher of the events of the owner and the overlay
+// in the staking contract and starts the periodic checks. On the first start
+// the events are followed from the current block.
+func NewWatcher(
+	logger log.Logger,
+	store storage.StateStorer,
+	backend ChainBackend,
+	contract Contract,
+	stakingContractAddress common.Address,
+	stakingContractABI abi.ABI,
+	owner common.Address,
+	overlay swarm.Address,
+	o WatcherOptions,
+) (*Watcher, error) {
+	w := &Watcher{
+		logger:   logger.WithName(loggerName).Register(),
+		store:    store,
+		backend:  backend,
+		contract: contract,
+		address:  stakingContractAddress,
+		abi:      stakingContractABI,
+		owner:    owner,
+		overlay:  overlay,
+		opts:     o,
+		client:   &http.Client{Timeout: webhookTimeout},
+		metrics:  newMetrics(),
+		topics:   make(map[common.Hash]EventKind),
+		quit:     make(chan struct{}),
+	}
+
+	for name, kind := range map[string]EventKind{
+		"StakeUpdated":   EventStakeUpdated,
+		"StakeFrozen":    EventStakeFrozen,
+		"StakeSlashed":   EventStakeSlashed,
+		"StakeWithdrawn": EventStakeWithdrawn,
+		"OverlayChanged": EventOverlayChanged,
+	} {
+		// the events that the contract does not emit are not followed
+		if e, ok := stakingContractABI.Events[name]; ok {
+			w.topics[e.ID] = kind
+		}
+	}
+
+	history, err := w.History()
+	if err != nil {
+		return nil, fmt.Errorf("stake history: %w", err)
+	}
+	for _, e := range history {
+		if e.Kind == EventStakeFrozen {
+			w.status.FrozenUntil = e.FrozenUntil
+			break
+		}
+	}
+
+	if o.PollInterval > 0 {
+		w.wg.Add(1)
+		go w.watch()
+	}
+	return w, nil
+}
+
+func (w *Watcher) watch() {
+	defer w.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-w.quit
+		cancel()
+	}()
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+			if err := w.check(ctx); err != nil {
+				w.logger.Debug("staking events check failed", "error", err)
+			}
+		}
+	}
+}
+
+// check retries the pending overlay migration and processes the events of
+// the blocks since the last check.
+func (w *Watcher) check(ctx context.Context) error {
+	if _, err := w.MigratePending(ctx); err != nil {
+		w.logger.Warning("pending stake overlay migration failed", "error", err)
+	}
+
+	head, err := w.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("block number: %w", err)
+	}
+	if head < tailSize {
+		return nil
+	}
+	to := head - tailSize
+
+	var last uint64
+	switch err := w.store.Get(lastBlockKey, &last); {
+	case errors.Is(err, storage.ErrNotFound):
+		last = to
+		if err := w.store.Put(lastBlockKey, last); err != nil {
+			return fmt.Errorf("save last processed block: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("last processed block: %w", err)
+	}
+
+	for from := last + 1; from <= to; from += blockPage {
+		end := min(from+blockPage-1, to)
+		var logs []types.Log
+		for _, q := range w.filterQueries(from, end) {
+			l, err := w.backend.FilterLogs(ctx, q)
+			if err != nil {
+				return fmt.Errorf("filter logs: %w", err)
+			}
+			logs = append(logs, l...)
+		}
+		sort.Slice(logs, func(i, j int) bool {
+			if logs[i].BlockNumber != logs[j].BlockNumber {
+				return logs[i].BlockNumber < logs[j].BlockNumber
+			}
+			return logs[i].Index < logs[j].Index
+		})
+		for _, l := range logs {
+			if err := w.processLog(ctx, l); err != nil {
+				return fmt.Errorf("process log %s/%d: %w", l.TxHash, l.Index, err)
+			}
+		}
+		if err := w.store.Put(lastBlockKey, end); err != nil {
+			return fmt.Errorf("save last processed block: %w", err)
+		}
+		last = end
+	}
+
+	w.mu.Lock()
+	w.status.Block = last
+	w.status.Frozen = last < w.status.FrozenUntil
+	frozen := w.status.Frozen
+	w.mu.Unlock()
+
+	if frozen {
+		w.metrics.Frozen.Set(1)
+	} else {
+		w.metrics.Frozen.Set(0)
+	}
+	return nil
+}
+
+// filterQueries returns the queries of the logs of the followed events in
+// the block range. The events with an indexed owner or overlay are filtered
+// by it, so that the logs of the other nodes are not fetched.
+func (w *Watcher) filterQueries(from, to uint64) []ethereum.FilterQuery {
+	query := func(topics ...[]common.Hash) ethereum.FilterQuery {
+		return ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{w.address},
+			Topics:    topics,
+		}
+	}
+
+	var (
+		queries []ethereum.FilterQuery
+		rest    []common.Hash
+	)
+	for id := range w.topics {
+		event, err := w.abi.EventByID(id)
+		if err != nil {
+			continue
+		}
+		var topic *common.Hash
+		for _, arg := range event.Inputs {
+			if !arg.Indexed {
+				continue
+			}
+			switch arg.Type.T {
+			case abi.AddressTy:
+				t := common.BytesToHash(w.owner.Bytes())
+				topic = &t
+			case abi.FixedBytesTy:
+				t := common.BytesToHash(w.overlay.Bytes())
+				topic = &t
+			}
+			break
+		}
+		if topic == nil {
+			rest = append(rest, id)
+			continue
+		}
+		queries = append(queries, query([]common.Hash{id}, []common.Hash{*topic}))
+	}
+	if len(rest) > 0 {
+		queries = append(queries, query(rest))
+	}
+	return queries
+}
+
+// processLog records the event of the log if it concerns the node. The logs
+// that cannot be decoded are skipped, so that they do not stop the
+// processing of the following blocks.
+func (w *Watcher) processLog(ctx context.Context, l types.Log) error {
+	if l.Removed || len(l.Topics) == 0 {
+		return nil
+	}
+	kind, ok := w.topics[l.Topics[0]]
+	if !ok {
+		return nil
+	}
+
+	values, err := w.unpackLog(l)
+	if err != nil {
+		w.logger.Debug("staking event skipped", "kind", kind, "transaction", l.TxHash, "index", l.Index, "error", err)
+		return nil
+	}
+
+	e := &Event{
+		Kind:     kind,
+		Block:    l.BlockNumber,
+		TxHash:   l.TxHash,
+		LogIndex: l.Index,
+		Created:  time.Now(),
+	}
+	if !w.fillEvent(e, values) {
+		return nil
+	}
+
+	if err := w.store.Put(historyKey(e), e); err != nil {
+		return fmt.Errorf("record event: %w", err)
+	}
+	w.metrics.Events.WithLabelValues(string(kind)).Inc()
+
+	switch kind {
+	case EventStakeUpdated:
+		if e.CommittedStake != nil {
+			committed, _ := new(big.Float).SetInt(e.CommittedStake).Float64()
+			w.metrics.CommittedStake.Set(committed)
+		}
+		if e.PotentialStake != nil {
+			potential, _ := new(big.Float).SetInt(e.PotentialStake).Float64()
+			w.metrics.PotentialStake.Set(potential)
+		}
+	case EventStakeFrozen:
+		w.mu.Lock()
+		w.status.FrozenUntil = max(w.status.FrozenUntil, e.FrozenUntil)
+		w.mu.Unlock()
+		w.metrics.FrozenUntil.Set(float64(e.FrozenUntil))
+		w.logger.Warning("stake frozen", "overlay", e.Overlay, "block", e.Block, "frozen_until", e.FrozenUntil)
+		w.alert(ctx, e)
+	case EventStakeSlashed:
+		if e.Amount != nil {
+			amount, _ := new(big.Float).SetInt(e.Amount).Float64()
+			w.metrics.SlashedAmount.Add(amount)
+		}
+		w.logger.Warning("stake slashed", "overlay", e.Overlay, "block", e.Block, "amount", e.Amount)
+		w.alert(ctx, e)
+	}
+	return nil
+}
+
+// unpackLog returns the indexed and the non-indexed arguments of the event
+// of the log by their names.
+func (w *Watcher) unpackLog(l types.Log) (map[string]interface{}, error) {
+	event, err := w.abi.EventByID(l.Topics[0])
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(values, l.Data); err != nil {
+		return nil, err
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, l.Topics[1:]); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// fillEvent sets the fields of the event from the arguments of the log and
+// reports whether the event concerns the node. The deployments of the
+// staking contract name the arguments alike but differ in their shapes: the
+// events that carry an address are matched by the owner, the others by the
+// overlay of the node.
+func (w *Watcher) fillEvent(e *Event, values map[string]interface{}) bool {
+	var (
+		owner    common.Address
+		hasOwner bool
+	)
+	for name, v := range values {
+		switch v := v.(type) {
+		case common.Address:
+			owner, hasOwner = v, true
+		case [32]byte:
+			e.Overlay = swarm.NewAddress(bytes.Clone(v[:]))
+		case *big.Int:
+			switch name {
+			case "committedStake":
+				e.CommittedStake = v
+			case "potentialStake", "stakeAmount":
+				e.PotentialStake = v
+			case "amount":
+				e.Amount = v
+			case "time":
+				e.FrozenUntil = e.Block + v.Uint64()
+			}
+		}
+	}
+	if hasOwner {
+		return owner == w.owner
+	}
+	return e.Overlay.Equal(w.overlay)
+}
+
+// alert posts the event to the webhook. The failures are only logged, so
+// that an unavailable webhook does not stop the processing of the events.
+func (w *Watcher) alert(ctx context.Context, e *Event) {
+	if w.opts.WebhookURL == "" {
+		return
+	}
+	if err := w.postWebhook(ctx, e); err != nil {
+		w.metrics.WebhookErrors.Inc()
+		w.logger.Warning("staking alert webhook failed", "kind", e.Kind, "error", err)
+	}
+}
+
+func (w *Watcher) postWebhook(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// MigrateOverlay moves the stake of the previous overlay to the overlay with
+// the nonce, when the node moved to another neighborhood. Nothing is done
+// if there is no stake. The migration is recorded in the stake history.
+func (w *Watcher) MigrateOverlay(ctx context.Context, previous, overlay swarm.Address, nonce common.Hash) (common.Hash, error) {
+	return w.migrateOverlay(ctx, previous, overlay, nonce, nil)
+}
+
+// migrateOverlay is MigrateOverlay that calls beforeSend, if it is set,
+// right before the migration transaction is sent.
+func (w *Watcher) migrateOverlay(ctx context.Context, previous, overlay swarm.Address, nonce common.Hash, beforeSend func() error) (common.Hash, error) {
+	stake, err := w.contract.GetPotentialStake(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if stake.Sign() <= 0 {
+		return common.Hash{}, nil
+	}
+
+	block, err := w.backend.BlockNumber(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("block number: %w", err)
+	}
+	frozen, err := w.contract.IsOverlayFrozen(ctx, block)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("stake freeze status: %w", err)
+	}
+	if frozen {
+		return common.Hash{}, ErrStakeFrozen
+	}
+
+	if beforeSend != nil {
+		if err := beforeSend(); err != nil {
+			return common.Hash{}, err
+		}
+	}
+
+	txHash, err := w.contract.ChangeStakeOverlay(ctx, nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	e := &Event{
+		Kind:            EventOverlayMigrated,
+		Block:           block,
+		TxHash:          txHash,
+		Overlay:         overlay,
+		PreviousOverlay: previous,
+		PotentialStake:  stake,
+		Created:         time.Now(),
+	}
+	if err := w.store.Put(historyKey(e), e); err != nil {
+		return txHash, fmt.Errorf("record migration: %w", err)
+	}
+	w.metrics.Events.WithLabelValues(string(EventOverlayMigrated)).Inc()
+	return txHash, nil
+}
+
+// pendingMigration is the overlay migration that is not done yet. Sent is
+// set before the migration transaction is sent, so that a migration whose
+// transaction may have been sent is checked on chain before it is retried.
+type pendingMigration struct {
+	Previous swarm.Address `json:"previous"`
+	Overlay  swarm.Address `json:"overlay"`
+	Nonce    common.Hash   `json:"nonce"`
+	Sent     bool          `json:"sent"`
+}
+
+// SavePendingMigration records that the stake has to be moved from the
+// previous overlay to the overlay with the nonce. It is saved before the
+// node switches to the new overlay, so that the migration is not lost if
+// it fails or the node stops before it is done.
+func SavePendingMigration(store storage.StateStorer, previous, overlay swarm.Address, nonce common.Hash) error {
+	return store.Put(pendingMigrationKey, pendingMigration{
+		Previous: previous,
+		Overlay:  overlay,
+		Nonce:    nonce,
+	})
+}
+
+// MigratePending does the pending overlay migration, if there is one. The
+// migration stays pending if it fails and is retried by the next check.
+// A migration whose transaction was sent before is not sent again if the
+// stake is already registered with the new overlay.
+func (w *Watcher) MigratePending(ctx context.Context) (common.Hash, error) {
+	var p pendingMigration
+	switch err := w.store.Get(pendingMigrationKey, &p); {
+	case errors.Is(err, storage.ErrNotFound):
+		return common.Hash{}, nil
+	case err != nil:
+		return common.Hash{}, fmt.Errorf("pending migration: %w", err)
+	}
+
+	if p.Sent {
+		staked, err := w.contract.GetStakedOverlay(ctx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if staked.Equal(p.Overlay) {
+			if err := w.store.Delete(pendingMigrationKey); err != nil {
+				return common.Hash{}, fmt.Errorf("delete pending migration: %w", err)
+			}
+			return common.Hash{}, nil
+		}
+	}
+
+	txHash, err := w.migrateOverlay(ctx, p.Previous, p.Overlay, p.Nonce, func() error {
+		p.Sent = true
+		if err := w.store.Put(pendingMigrationKey, p); err != nil {
+			return fmt.Errorf("pending migration: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := w.store.Delete(pendingMigrationKey); err != nil {
+		return txHash, fmt.Errorf("delete pending migration: %w", err)
+	}
+	return txHash, nil
+}
+
+func historyKey(e *Event) string {
+	return fmt.Sprintf("%s%020d_%x_%06d", historyKeyPrefix, e.Block, e.TxHash, e.LogIndex)
+}
+
+// History returns the recorded events, the newest first.
+func (w *Watcher) History() ([]Event, error) {
+	var history []Event
+	err := w.store.Iterate(historyKeyPrefix, func(_, value []byte) (bool, error) {
+		var e Event
+		if err := json.Unmarshal(value, &e); err != nil {
+			return false, err
+		}
+		history = append(history, e)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].Block != history[j].Block {
+			return history[i].Block > history[j].Block
+		}
+		return history[i].LogIndex > history[j].LogIndex
+	})
+	return history, nil
+}
+
+// FreezeStatus returns the freeze state of the stake.
+func (w *Watcher) FreezeStatus() FreezeStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.status
+}
+
+func (w *Watcher) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(w.metrics)
+}
+
+func (w *Watcher) Close() error {
+	close(w.quit)
+	w.wg.Wait()
+	return nil
+}
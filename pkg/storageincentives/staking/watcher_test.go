@@ -0,0 +1,451 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package staking_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	chaincfg "github.com/ethersphere/bee/v2/pkg/config"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/storageincentives/staking"
+	stakingMock "github.com/ethersphere/bee/v2/pkg/storageincentives/staking/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/util/abiutil"
+)
+
+var (
+	watcherOwner    = common.HexToAddress("abcd")
+	watcherOverlay  = [32]byte{1}
+	watcherContract = common.HexToAddress("ffff")
+)
+
+// testChain serves the logs of the staking contract up to the head block.
+type testChain struct {
+	mu   sync.Mutex
+	head uint64
+	logs []types.Log
+}
+
+func (c *testChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var logs []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() && matchTopics(l, q.Topics) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func matchTopics(l types.Log, topics [][]common.Hash) bool {
+	for i, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) || !slices.Contains(alternatives, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *testChain) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.head, nil
+}
+
+// add appends the log of the event with the arguments in the order of the
+// inputs of the event in the contract ABI.
+func (c *testChain) add(t *testing.T, contractABI abi.ABI, block uint64, name string, args ...interface{}) {
+	t.Helper()
+
+	event := contractABI.Events[name]
+	var (
+		topics     = []common.Hash{event.ID}
+		nonIndexed []interface{}
+	)
+	for i, arg := range event.Inputs {
+		if !arg.Indexed {
+			nonIndexed = append(nonIndexed, args[i])
+			continue
+		}
+		topic, err := abi.MakeTopics([]interface{}{args[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		topics = append(topics, topic[0][0])
+	}
+	data, err := event.Inputs.NonIndexed().Pack(nonIndexed...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, types.Log{
+		Address:     watcherContract,
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(big.NewInt(int64(len(c.logs) + 1))),
+		Index:       uint(len(c.logs)),
+	})
+}
+
+func (c *testChain) setHead(head uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = head
+}
+
+func newTestWatcher(t *testing.T, store storage.StateStorer, chain *testChain, contract staking.Contract, contractABI abi.ABI, o staking.WatcherOptions) *staking.Watcher {
+	t.Helper()
+
+	w, err := staking.NewWatcher(log.Noop, store, chain, contract, watcherContract, contractABI, watcherOwner, swarm.NewAddress(watcherOverlay[:]), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func TestWatcherEvents(t *testing.T) {
+	t.Parallel()
+
+	var alerts atomic.Int32
+	var frozenAlert staking.Event
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e staking.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if alerts.Add(1) == 1 {
+			frozenAlert = e
+		}
+	}))
+	t.Cleanup(webhook.Close)
+
+	chain := &testChain{head: 100}
+	w := newTestWatcher(t, statestore.NewStateStore(), chain, stakingMock.New(), stakingContractABI, staking.WatcherOptions{WebhookURL: webhook.URL})
+
+	// the events before the first check are not followed
+	chain.add(t, stakingContractABI, 90, "StakeFrozen", watcherOwner, watcherOverlay, big.NewInt(50))
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	overlay := watcherOverlay
+	chain.add(t, stakingContractABI, 101, "StakeUpdated", watcherOwner, big.NewInt(10), big.NewInt(20), overlay, big.NewInt(101))
+	chain.add(t, stakingContractABI, 102, "StakeFrozen", watcherOwner, overlay, big.NewInt(50))
+	chain.add(t, stakingContractABI, 103, "StakeSlashed", common.HexToAddress("beef"), [32]byte{2}, big.NewInt(5))
+	chain.add(t, stakingContractABI, 110, "StakeSlashed", watcherOwner, overlay, big.NewInt(5))
+	chain.setHead(110)
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := w.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last slash is in the tail of the chain
+	if len(history) != 2 {
+		t.Fatalf("got %d events, want %d", len(history), 2)
+	}
+	if e := history[0]; e.Kind != staking.EventStakeFrozen || e.Block != 102 || e.FrozenUntil != 152 || !e.Overlay.Equal(swarm.NewAddress(overlay[:])) {
+		t.Fatalf("got event %+v", e)
+	}
+	if e := history[1]; e.Kind != staking.EventStakeUpdated || e.CommittedStake.Int64() != 10 || e.PotentialStake.Int64() != 20 {
+		t.Fatalf("got event %+v", e)
+	}
+	if status := w.FreezeStatus(); !status.Frozen || status.FrozenUntil != 152 || status.Block != 106 {
+		t.Fatalf("got freeze status %+v", status)
+	}
+	if n := alerts.Load(); n != 1 {
+		t.Fatalf("got %d alerts, want %d", n, 1)
+	}
+	if frozenAlert.Kind != staking.EventStakeFrozen || frozenAlert.FrozenUntil != 152 {
+		t.Fatalf("got alert %+v", frozenAlert)
+	}
+
+	chain.setHead(200)
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	history, err = w.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].Kind != staking.EventStakeSlashed || history[0].Amount.Int64() != 5 {
+		t.Fatalf("got history %+v", history)
+	}
+	if status := w.FreezeStatus(); status.Frozen {
+		t.Fatalf("got freeze status %+v, want not frozen", status)
+	}
+	if n := alerts.Load(); n != 2 {
+		t.Fatalf("got %d alerts, want %d", n, 2)
+	}
+}
+
+func TestWatcherMainnetEvents(t *testing.T) {
+	t.Parallel()
+
+	mainnetABI := abiutil.MustParseABI(chaincfg.Mainnet.StakingABI)
+	chain := &testChain{head: 100}
+	w := newTestWatcher(t, statestore.NewStateStore(), chain, stakingMock.New(), mainnetABI, staking.WatcherOptions{})
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	other := [32]byte{2}
+	chain.add(t, mainnetABI, 101, "StakeUpdated", watcherOverlay, big.NewInt(20), watcherOwner, big.NewInt(101))
+	chain.add(t, mainnetABI, 101, "StakeUpdated", other, big.NewInt(30), common.HexToAddress("beef"), big.NewInt(101))
+	chain.add(t, mainnetABI, 102, "StakeFrozen", watcherOverlay, big.NewInt(50))
+	chain.add(t, mainnetABI, 103, "StakeSlashed", other, big.NewInt(5))
+	chain.add(t, mainnetABI, 104, "StakeSlashed", watcherOverlay, big.NewInt(5))
+	chain.setHead(110)
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := w.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("got %d events, want %d", len(history), 3)
+	}
+	if e := history[0]; e.Kind != staking.EventStakeSlashed || e.Amount.Int64() != 5 || !e.Overlay.Equal(swarm.NewAddress(watcherOverlay[:])) {
+		t.Fatalf("got event %+v", e)
+	}
+	if e := history[1]; e.Kind != staking.EventStakeFrozen || e.FrozenUntil != 152 {
+		t.Fatalf("got event %+v", e)
+	}
+	if e := history[2]; e.Kind != staking.EventStakeUpdated || e.PotentialStake.Int64() != 20 {
+		t.Fatalf("got event %+v", e)
+	}
+	if status := w.FreezeStatus(); !status.Frozen || status.Block != 106 {
+		t.Fatalf("got freeze status %+v", status)
+	}
+}
+
+func TestWatcherSkipsUndecodableLogs(t *testing.T) {
+	t.Parallel()
+
+	chain := &testChain{head: 100}
+	w := newTestWatcher(t, statestore.NewStateStore(), chain, stakingMock.New(), stakingContractABI, staking.WatcherOptions{})
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the data of the log is too short for the event
+	chain.logs = append(chain.logs, types.Log{
+		Address:     watcherContract,
+		Topics:      []common.Hash{stakingContractABI.Events["StakeFrozen"].ID},
+		Data:        watcherOverlay[:],
+		BlockNumber: 101,
+	})
+	chain.add(t, stakingContractABI, 102, "StakeSlashed", watcherOwner, watcherOverlay, big.NewInt(5))
+	chain.setHead(110)
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	history, err := w.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Kind != staking.EventStakeSlashed {
+		t.Fatalf("got history %+v", history)
+	}
+}
+
+func TestMigrateOverlay(t *testing.T) {
+	t.Parallel()
+
+	previous := swarm.RandAddress(t)
+	overlay := swarm.RandAddress(t)
+	nonce := common.HexToHash("01")
+
+	newContract := func(stake int64, frozen bool) staking.Contract {
+		return stakingMock.New(
+			stakingMock.WithGetStake(func(context.Context) (*big.Int, error) {
+				return big.NewInt(stake), nil
+			}),
+			stakingMock.WithIsFrozen(func(context.Context, uint64) (bool, error) {
+				return frozen, nil
+			}),
+		)
+	}
+
+	t.Run("migrated", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWatcher(t, statestore.NewStateStore(), &testChain{head: 100}, newContract(10, false), stakingContractABI, staking.WatcherOptions{})
+		txHash, err := w.MigrateOverlay(context.Background(), previous, overlay, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txHash != nonce {
+			t.Fatalf("got transaction %s, want %s", txHash, nonce)
+		}
+		history, err := w.History()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 {
+			t.Fatalf("got %d events, want %d", len(history), 1)
+		}
+		e := history[0]
+		if e.Kind != staking.EventOverlayMigrated || !e.Overlay.Equal(overlay) || !e.PreviousOverlay.Equal(previous) || e.PotentialStake.Int64() != 10 {
+			t.Fatalf("got event %+v", e)
+		}
+	})
+
+	t.Run("no stake", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWatcher(t, statestore.NewStateStore(), &testChain{head: 100}, newContract(0, false), stakingContractABI, staking.WatcherOptions{})
+		txHash, err := w.MigrateOverlay(context.Background(), previous, overlay, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txHash != (common.Hash{}) {
+			t.Fatalf("got transaction %s, want none", txHash)
+		}
+	})
+
+	t.Run("frozen", func(t *testing.T) {
+		t.Parallel()
+
+		w := newTestWatcher(t, statestore.NewStateStore(), &testChain{head: 100}, newContract(10, true), stakingContractABI, staking.WatcherOptions{})
+		_, err := w.MigrateOverlay(context.Background(), previous, overlay, nonce)
+		if !errors.Is(err, staking.ErrStakeFrozen) {
+			t.Fatalf("got error %v, want %v", err, staking.ErrStakeFrozen)
+		}
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		t.Parallel()
+
+		var frozen atomic.Bool
+		frozen.Store(true)
+		contract := stakingMock.New(
+			stakingMock.WithGetStake(func(context.Context) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+			stakingMock.WithIsFrozen(func(context.Context, uint64) (bool, error) {
+				return frozen.Load(), nil
+			}),
+		)
+		store := statestore.NewStateStore()
+		if err := staking.SavePendingMigration(store, previous, overlay, nonce); err != nil {
+			t.Fatal(err)
+		}
+		w := newTestWatcher(t, store, &testChain{head: 100}, contract, stakingContractABI, staking.WatcherOptions{})
+
+		if _, err := w.MigratePending(context.Background()); !errors.Is(err, staking.ErrStakeFrozen) {
+			t.Fatalf("got error %v, want %v", err, staking.ErrStakeFrozen)
+		}
+
+		// the check retries the migration
+		frozen.Store(false)
+		if err := w.Check(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		history, err := w.History()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 || history[0].Kind != staking.EventOverlayMigrated {
+			t.Fatalf("got history %+v", history)
+		}
+
+		txHash, err := w.MigratePending(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if txHash != (common.Hash{}) {
+			t.Fatalf("got transaction %s, want none", txHash)
+		}
+	})
+
+	t.Run("sent", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			sends  atomic.Int32
+			staked atomic.Value
+		)
+		staked.Store(previous)
+		contract := stakingMock.New(
+			stakingMock.WithGetStake(func(context.Context) (*big.Int, error) {
+				return big.NewInt(10), nil
+			}),
+			stakingMock.WithIsFrozen(func(context.Context, uint64) (bool, error) {
+				return false, nil
+			}),
+			stakingMock.WithGetStakedOverlay(func(context.Context) (swarm.Address, error) {
+				return staked.Load().(swarm.Address), nil
+			}),
+			stakingMock.WithChangeStakeOverlay(func(context.Context, common.Hash) (common.Hash, error) {
+				sends.Add(1)
+				return common.Hash{}, errors.New("receipt not received")
+			}),
+		)
+		store := statestore.NewStateStore()
+		if err := staking.SavePendingMigration(store, previous, overlay, nonce); err != nil {
+			t.Fatal(err)
+		}
+		w := newTestWatcher(t, store, &testChain{head: 100}, contract, stakingContractABI, staking.WatcherOptions{})
+
+		if _, err := w.MigratePending(context.Background()); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		// the transaction is sent again if the stake was not migrated
+		if _, err := w.MigratePending(context.Background()); err == nil {
+			t.Fatal("expected an error")
+		}
+		if n := sends.Load(); n != 2 {
+			t.Fatalf("got %d transactions, want %d", n, 2)
+		}
+
+		// the migration is done once the stake is registered with the overlay
+		staked.Store(overlay)
+		if _, err := w.MigratePending(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.MigratePending(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if n := sends.Load(); n != 2 {
+			t.Fatalf("got %d transactions after the migration, want %d", n, 2)
+		}
+	})
+}
@@ -32,7 +32,13 @@ func Compact(ctx context.Context, basePath string, opts *Options, validate bool)
 		}
 	}()
 
-	sharkyRecover, err := sharky.NewRecovery(path.Join(basePath, sharkyPath), sharkyNoOfShards, swarm.SocMaxChunkSize)
+	sharkyBasePath := path.Join(basePath, sharkyPath)
+	sharkyOpts, err := sharkyOptions(sharkyBasePath, opts.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	sharkyRecover, err := sharky.NewRecovery(sharkyBasePath, sharkyNoOfShards, swarm.SocMaxChunkSize, sharkyOpts...)
 	if err != nil {
 		return err
 	}
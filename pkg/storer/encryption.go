@@ -0,0 +1,278 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
+	"github.com/ethersphere/bee/v2/pkg/sharky"
+	"github.com/ethersphere/bee/v2/pkg/storer/internal/chunkstore"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	// sharkyEncryptedFileName is the name of the marker file that is present
+	// in the sharky directory when the blobs are encrypted at rest. It holds
+	// the key check value of the encryption key.
+	sharkyEncryptedFileName = ".ENCRYPTED"
+
+	sharkyEncryptTmpPath = "sharky.encrypt"
+	sharkyPlainPath      = "sharky.plain"
+)
+
+var (
+	// ErrEncryptionKeyRequired is returned when the localstore is encrypted
+	// but no encryption key was provided.
+	ErrEncryptionKeyRequired = errors.New("localstore is encrypted: encryption key required")
+	// ErrEncryptionKeyMismatch is returned when the localstore is encrypted
+	// with a key different from the one provided.
+	ErrEncryptionKeyMismatch = errors.New("localstore is encrypted with a different key")
+	// ErrNotEncrypted is returned when encryption is requested for a
+	// localstore that already holds unencrypted data.
+	ErrNotEncrypted = errors.New("localstore holds unencrypted data: run the db encrypt command first")
+	// ErrAlreadyEncrypted is returned by Encrypt when the localstore is already encrypted.
+	ErrAlreadyEncrypted = errors.New("localstore is already encrypted")
+)
+
+// IsEncrypted reports whether the localstore at the given base path is encrypted at rest.
+func IsEncrypted(basePath string) (bool, error) {
+	_, err := os.Stat(filepath.Join(basePath, sharkyPath, sharkyEncryptedFileName))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// sharkyOptions returns the sharky options matching the encryption state of
+// the sharky directory and the given key. An empty sharky directory is marked
+// as encrypted when a key is provided.
+func sharkyOptions(sharkyBasePath string, key []byte) ([]sharky.Option, error) {
+	check, err := os.ReadFile(filepath.Join(sharkyBasePath, sharkyEncryptedFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read encryption marker: %w", err)
+	}
+	encrypted := err == nil
+
+	if key == nil {
+		if encrypted {
+			return nil, ErrEncryptionKeyRequired
+		}
+		return nil, nil
+	}
+
+	c, err := atrest.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if encrypted {
+		if !bytes.Equal(check, c.KeyCheck()) {
+			return nil, ErrEncryptionKeyMismatch
+		}
+		return []sharky.Option{sharky.WithCipher(c)}, nil
+	}
+
+	empty, err := sharkyEmpty(sharkyBasePath)
+	if err != nil {
+		return nil, err
+	}
+	if !empty {
+		return nil, ErrNotEncrypted
+	}
+	if err := os.WriteFile(filepath.Join(sharkyBasePath, sharkyEncryptedFileName), c.KeyCheck(), 0644); err != nil {
+		return nil, fmt.Errorf("write encryption marker: %w", err)
+	}
+	return []sharky.Option{sharky.WithCipher(c)}, nil
+}
+
+// sharkyEmpty reports whether none of the shard files in the directory holds any data.
+func sharkyEmpty(sharkyBasePath string) (bool, error) {
+	entries, err := os.ReadDir(sharkyBasePath)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "shard_") {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			return false, err
+		}
+		if fi.Size() > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Encrypt encrypts the blobs of an existing unencrypted localstore with the
+// key from the options. Every blob keeps its shard, slot and length, so the
+// indexes remain valid and only the sharky directory is replaced.
+func Encrypt(ctx context.Context, basePath string, opts *Options) error {
+	logger := opts.Logger
+
+	if opts.EncryptionKey == nil {
+		return ErrEncryptionKeyRequired
+	}
+	encrypted, err := IsEncrypted(basePath)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return ErrAlreadyEncrypted
+	}
+
+	c, err := atrest.New(opts.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	store, err := initStore(basePath, opts)
+	if err != nil {
+		return fmt.Errorf("failed creating levelDB index store: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error(err, "failed closing store")
+		}
+	}()
+
+	sharkyBasePath := path.Join(basePath, sharkyPath)
+	sharkyRecover, err := sharky.NewRecovery(sharkyBasePath, sharkyNoOfShards, swarm.SocMaxChunkSize)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := sharkyRecover.Close(); err != nil {
+			logger.Error(err, "failed closing sharky recovery")
+		}
+	}()
+
+	tmpPath := path.Join(basePath, sharkyEncryptTmpPath)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+	if err := os.Mkdir(tmpPath, 0777); err != nil {
+		return err
+	}
+
+	shards := make([]*os.File, sharkyNoOfShards)
+	for i := range shards {
+		shards[i], err = os.OpenFile(path.Join(tmpPath, fmt.Sprintf("shard_%03d", i)), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		defer shards[i].Close()
+		if err := copyFile(path.Join(sharkyBasePath, fmt.Sprintf("free_%03d", i)), path.Join(tmpPath, fmt.Sprintf("free_%03d", i))); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("starting encryption")
+	n := time.Now()
+
+	var (
+		count    int
+		slotSize = int64(swarm.SocMaxChunkSize + c.Overhead())
+		buf      = make([]byte, swarm.SocMaxChunkSize)
+	)
+	err = chunkstore.IterateItems(store, func(item *chunkstore.RetrievalIndexItem) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		loc := item.Location
+		data := buf[:loc.Length]
+		if err := sharkyRecover.Read(ctx, loc, data); err != nil {
+			return fmt.Errorf("read %s: %w", item.Address, err)
+		}
+		sealed, err := c.Seal(nil, data)
+		if err != nil {
+			return err
+		}
+		if _, err := shards[loc.Shard].WriteAt(sealed, int64(loc.Slot)*slotSize); err != nil {
+			return fmt.Errorf("write %s: %w", item.Address, err)
+		}
+
+		count++
+		if count%100_000 == 0 {
+			logger.Info("encryption in progress", "chunks", count)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range shards {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path.Join(tmpPath, sharkyEncryptedFileName), c.KeyCheck(), 0644); err != nil {
+		return fmt.Errorf("write encryption marker: %w", err)
+	}
+
+	// keep the dirty marker so that the free slots are recovered on the next start
+	if _, err := os.Stat(path.Join(sharkyBasePath, sharkyDirtyFileName)); err == nil {
+		if err := os.WriteFile(path.Join(tmpPath, sharkyDirtyFileName), []byte{}, 0644); err != nil {
+			return err
+		}
+	}
+
+	plainPath := path.Join(basePath, sharkyPlainPath)
+	if err := os.Rename(sharkyBasePath, plainPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, sharkyBasePath); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(plainPath); err != nil {
+		return err
+	}
+
+	logger.Info("encryption finished", "chunks", count, "duration", time.Since(n))
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
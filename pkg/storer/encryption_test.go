@@ -0,0 +1,117 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	chunk "github.com/ethersphere/bee/v2/pkg/storage/testing"
+	"github.com/ethersphere/bee/v2/pkg/storer"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	basePath := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+
+	opts := dbTestOps(swarm.RandAddress(t), 10_000, nil, nil, time.Minute)
+
+	st, err := storer.New(ctx, basePath, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := chunk.GenerateTestRandomChunks(50)
+	for _, ch := range chunks {
+		if err := st.Cache().Put(ctx, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	withKey := func(key []byte) *storer.Options {
+		o := dbTestOps(opts.Address, 10_000, nil, nil, time.Minute)
+		o.EncryptionKey = key
+		return o
+	}
+
+	if _, err := storer.New(ctx, basePath, withKey(key)); !errors.Is(err, storer.ErrNotEncrypted) {
+		t.Fatalf("got error %v, want %v", err, storer.ErrNotEncrypted)
+	}
+
+	if err := storer.Encrypt(ctx, basePath, withKey(key)); err != nil {
+		t.Fatal(err)
+	}
+	if err := storer.Encrypt(ctx, basePath, withKey(key)); !errors.Is(err, storer.ErrAlreadyEncrypted) {
+		t.Fatalf("got error %v, want %v", err, storer.ErrAlreadyEncrypted)
+	}
+
+	encrypted, err := storer.IsEncrypted(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypted {
+		t.Fatal("expected localstore to be encrypted")
+	}
+
+	shard, err := os.ReadFile(filepath.Join(basePath, "sharky", "shard_000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		if bytes.Contains(shard, ch.Data()) {
+			t.Fatalf("chunk %s stored in plain form", ch.Address())
+		}
+	}
+
+	if _, err := storer.New(ctx, basePath, withKey(nil)); !errors.Is(err, storer.ErrEncryptionKeyRequired) {
+		t.Fatalf("got error %v, want %v", err, storer.ErrEncryptionKeyRequired)
+	}
+	if _, err := storer.New(ctx, basePath, withKey(bytes.Repeat([]byte{2}, 32))); !errors.Is(err, storer.ErrEncryptionKeyMismatch) {
+		t.Fatalf("got error %v, want %v", err, storer.ErrEncryptionKeyMismatch)
+	}
+
+	st, err = storer.New(ctx, basePath, withKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := st.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, ch := range chunks {
+		got, err := st.Lookup().Get(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatalf("chunk %s mismatch", ch.Address())
+		}
+	}
+
+	added := chunk.GenerateTestRandomChunk()
+	if err := st.Cache().Put(ctx, added); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.Lookup().Get(ctx, added.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data(), added.Data()) {
+		t.Fatalf("chunk %s mismatch", added.Address())
+	}
+}
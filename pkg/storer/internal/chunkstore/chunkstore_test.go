@@ -5,6 +5,7 @@
 package chunkstore_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -311,6 +312,34 @@ func TestChunkStore(t *testing.T) {
 		}
 	})
 
+	t.Run("replace chunks", func(t *testing.T) {
+		for idx, ch := range testChunks {
+			if idx%2 != 0 {
+				replacement := swarm.NewChunk(ch.Address(), chunktest.GenerateTestRandomChunk().Data())
+				err := st.Run(context.Background(), func(s transaction.Store) error {
+					return s.ChunkStore().Replace(context.TODO(), replacement)
+				})
+				if err != nil {
+					t.Fatalf("failed replacing chunk: %v", err)
+				}
+				readCh, err := st.ChunkStore().Get(context.TODO(), ch.Address())
+				if err != nil {
+					t.Fatalf("failed getting chunk: %v", err)
+				}
+				if !bytes.Equal(readCh.Data(), replacement.Data()) {
+					t.Fatal("read chunk doesnt match the replacement")
+				}
+			}
+		}
+
+		err := st.Run(context.Background(), func(s transaction.Store) error {
+			return s.ChunkStore().Replace(context.TODO(), chunktest.GenerateTestRandomChunk())
+		})
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("replacing missing chunk: got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
 	t.Run("delete duplicate chunks again", func(t *testing.T) {
 		for idx, ch := range testChunks {
 			if idx%2 != 0 {
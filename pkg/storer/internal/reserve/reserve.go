@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/soc"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	"github.com/ethersphere/bee/v2/pkg/storer/internal/chunkstamp"
 	"github.com/ethersphere/bee/v2/pkg/storer/internal/stampindex"
@@ -111,6 +112,7 @@ func (r *Reserve) Put(ctx context.Context, chunk swarm.Chunk) error {
 	}
 
 	bin := swarm.Proximity(r.baseAddr.Bytes(), chunk.Address().Bytes())
+	chunkType := storage.ChunkType(chunk)
 
 	// bin lock
 	r.multx.Lock(strconv.Itoa(int(bin)))
@@ -179,13 +181,22 @@ func (r *Reserve) Put(ctx context.Context, chunk swarm.Chunk) error {
 			BinID:     binID,
 			Address:   chunk.Address(),
 			BatchID:   chunk.Stamp().BatchID(),
-			ChunkType: storage.ChunkType(chunk),
+			ChunkType: chunkType,
 			StampHash: stampHash,
 		})
 		if err != nil {
 			return err
 		}
 
+		if chunkType == swarm.ChunkTypeSingleOwner && soc.ValidGraffiti(chunk) {
+			// graffiti chunks are overwritable, so that they keep the
+			// data of their latest update
+			err = s.ChunkStore().Replace(ctx, chunk)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return err
+			}
+		}
+
 		err = s.ChunkStore().Put(ctx, chunk)
 		if err != nil {
 			return err
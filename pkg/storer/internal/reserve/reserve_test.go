@@ -7,15 +7,19 @@ package reserve_test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"math"
 	"math/rand"
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/crypto"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/postage"
 	postagetesting "github.com/ethersphere/bee/v2/pkg/postage/testing"
+	"github.com/ethersphere/bee/v2/pkg/soc"
+	soctesting "github.com/ethersphere/bee/v2/pkg/soc/testing"
 	"github.com/ethersphere/bee/v2/pkg/storage"
 	chunk "github.com/ethersphere/bee/v2/pkg/storage/testing"
 	"github.com/ethersphere/bee/v2/pkg/storer/internal"
@@ -193,6 +197,70 @@ func TestReplaceOldIndex(t *testing.T) {
 	}
 }
 
+// TestReserveSOCOverwrite tests that a graffiti single-owner chunk is
+// overwritten by its update that is stamped with a different stamp, and
+// that other single-owner chunks are not.
+func TestReserveSOCOverwrite(t *testing.T) {
+	t.Parallel()
+
+	graffitiKey, err := soc.GraffitiKey(make([]byte, swarm.HashSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("graffiti", func(t *testing.T) {
+		t.Parallel()
+		testReserveSOCOverwrite(t, graffitiKey, true)
+	})
+	t.Run("other", func(t *testing.T) {
+		t.Parallel()
+		testReserveSOCOverwrite(t, privKey, false)
+	})
+}
+
+func testReserveSOCOverwrite(t *testing.T, privKey *ecdsa.PrivateKey, overwritten bool) {
+	t.Helper()
+
+	baseAddr := swarm.RandAddress(t)
+
+	ts := internal.NewInmemStorage()
+
+	r, err := reserve.New(
+		baseAddr,
+		ts,
+		0, kademlia.NewTopologyDriver(),
+		log.Noop,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := postagetesting.MustNewBatch()
+	ch1 := soctesting.GenerateMockSOCWithKey(t, []byte("first"), privKey).Chunk().WithStamp(postagetesting.MustNewFields(batch.ID, 0, 0))
+	ch2 := soctesting.GenerateMockSOCWithKey(t, []byte("update"), privKey).Chunk().WithStamp(postagetesting.MustNewFields(batch.ID, 1, 1))
+	if !ch1.Address().Equal(ch2.Address()) {
+		t.Fatal("the updates should have the same address")
+	}
+
+	for _, ch := range []swarm.Chunk{ch1, ch2} {
+		if err := r.Put(context.Background(), ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ts.ChunkStore().Get(context.Background(), ch2.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bytes.Equal(got.Data(), ch2.Data()); got != overwritten {
+		t.Fatalf("got overwritten %t, want %t", got, overwritten)
+	}
+}
+
 func TestEvict(t *testing.T) {
 	t.Parallel()
 
@@ -22,7 +22,7 @@ const (
 	sharkyDirtyFileName = ".DIRTY"
 )
 
-func sharkyRecovery(ctx context.Context, sharkyBasePath string, store storage.Store, opts *Options) (closerFn, error) {
+func sharkyRecovery(ctx context.Context, sharkyBasePath string, store storage.Store, opts *Options, sharkyOpts ...sharky.Option) (closerFn, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -40,7 +40,7 @@ func sharkyRecovery(ctx context.Context, sharkyBasePath string, store storage.St
 		logger.Info("localstore sharky recovery finished", "time", time.Since(t))
 	}(time.Now())
 
-	sharkyRecover, err := sharky.NewRecovery(sharkyBasePath, sharkyNoOfShards, swarm.SocMaxChunkSize)
+	sharkyRecover, err := sharky.NewRecovery(sharkyBasePath, sharkyNoOfShards, swarm.SocMaxChunkSize, sharkyOpts...)
 	if err != nil {
 		return closer, err
 	}
@@ -18,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ethersphere/bee/v2/pkg/encryption/atrest"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/storer/internal/transaction"
 
@@ -210,16 +211,26 @@ func closer(closers ...io.Closer) io.Closer {
 	})
 }
 
-func initInmemRepository() (transaction.Storage, io.Closer, error) {
+func initInmemRepository(opts *Options) (transaction.Storage, io.Closer, error) {
 	store, err := leveldbstore.New("", nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed creating inmem levelDB index store: %w", err)
 	}
 
+	var sharkyOpts []sharky.Option
+	if opts.EncryptionKey != nil {
+		c, err := atrest.New(opts.EncryptionKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed creating localstore cipher: %w", err)
+		}
+		sharkyOpts = append(sharkyOpts, sharky.WithCipher(c))
+	}
+
 	sharky, err := sharky.New(
 		&memFS{Fs: afero.NewMemMapFs()},
 		sharkyNoOfShards,
 		swarm.SocMaxChunkSize,
+		sharkyOpts...,
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed creating inmem sharky instance: %w", err)
@@ -273,6 +284,20 @@ func initDiskRepository(
 	basePath string,
 	opts *Options,
 ) (transaction.Storage, *PinIntegrity, io.Closer, error) {
+	sharkyBasePath := path.Join(basePath, sharkyPath)
+
+	if _, err := os.Stat(sharkyBasePath); os.IsNotExist(err) {
+		err := os.MkdirAll(sharkyBasePath, 0777)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	sharkyOpts, err := sharkyOptions(sharkyBasePath, opts.EncryptionKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	store, err := initStore(basePath, opts)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed creating levelDB index store: %w", err)
@@ -327,16 +352,7 @@ func initDiskRepository(
 		}()
 	}
 
-	sharkyBasePath := path.Join(basePath, sharkyPath)
-
-	if _, err := os.Stat(sharkyBasePath); os.IsNotExist(err) {
-		err := os.Mkdir(sharkyBasePath, 0777)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-	}
-
-	recoveryCloser, err := sharkyRecovery(ctx, sharkyBasePath, store, opts)
+	recoveryCloser, err := sharkyRecovery(ctx, sharkyBasePath, store, opts, sharkyOpts...)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to recover sharky: %w", err)
 	}
@@ -345,6 +361,7 @@ func initDiskRepository(
 		&dirFS{basedir: sharkyBasePath},
 		sharkyNoOfShards,
 		swarm.SocMaxChunkSize,
+		sharkyOpts...,
 	)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed creating sharky instance: %w", err)
@@ -384,6 +401,9 @@ type Options struct {
 
 	CacheCapacity      uint64
 	CacheMinEvictCount uint64
+
+	// EncryptionKey, when set, makes the chunk payloads encrypted at rest.
+	EncryptionKey []byte
 }
 
 func defaultOptions() *Options {
@@ -468,7 +488,7 @@ func New(ctx context.Context, dirPath string, opts *Options) (*DB, error) {
 	opts.LdbStats.CompareAndSwap(nil, &metrics.LevelDBStats)
 
 	if dirPath == "" {
-		st, dbCloser, err = initInmemRepository()
+		st, dbCloser, err = initInmemRepository(opts)
 		if err != nil {
 			return nil, err
 		}
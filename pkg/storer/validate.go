@@ -39,8 +39,13 @@ func ValidateReserve(ctx context.Context, basePath string, opts *Options) error
 		}
 	}()
 
+	sharkyOpts, err := sharkyOptions(path.Join(basePath, sharkyPath), opts.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
 	sharky, err := sharky.New(&dirFS{basedir: path.Join(basePath, sharkyPath)},
-		sharkyNoOfShards, swarm.SocMaxChunkSize)
+		sharkyNoOfShards, swarm.SocMaxChunkSize, sharkyOpts...)
 	if err != nil {
 		return err
 	}
@@ -72,8 +77,13 @@ func ValidateRetrievalIndex(ctx context.Context, basePath string, opts *Options)
 		}
 	}()
 
+	sharkyOpts, err := sharkyOptions(path.Join(basePath, sharkyPath), opts.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
 	sharky, err := sharky.New(&dirFS{basedir: path.Join(basePath, sharkyPath)},
-		sharkyNoOfShards, swarm.SocMaxChunkSize)
+		sharkyNoOfShards, swarm.SocMaxChunkSize, sharkyOpts...)
 	if err != nil {
 		return err
 	}
@@ -194,8 +204,13 @@ func ValidatePinCollectionChunks(ctx context.Context, basePath, pin, location st
 		}
 	}()
 
+	sharkyOpts, err := sharkyOptions(path.Join(basePath, sharkyPath), opts.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
 	fs := &dirFS{basedir: path.Join(basePath, sharkyPath)}
-	sharky, err := sharky.New(fs, sharkyNoOfShards, swarm.SocMaxChunkSize)
+	sharky, err := sharky.New(fs, sharkyNoOfShards, swarm.SocMaxChunkSize, sharkyOpts...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,392 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tenant provides accounting of uploads per API consumer.
+//
+// A tenant is a short label that identifies the consumer of the API, either
+// derived from the bearer token the request was authorized with or supplied
+// explicitly in a request header. The chunks stamped on behalf of a tenant
+// are counted together with the batches that paid for them in the upload
+// session and added to the usage of the tenant when the upload is done,
+// with the distinct chunks kept per tenant across the sessions, which allows the node operator to attribute storage and postage usage to
+// the consumers of a shared node.
+package tenant
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const (
+	usageKeyPrefix = "tenant_usage_"
+	tagKeyPrefix   = "tenant_tag_"
+	pinKeyPrefix   = "tenant_pin_"
+	chunkKeyPrefix = "tenant_chunk_"
+	slotKeyPrefix  = "tenant_slot_"
+)
+
+var (
+	// ErrInvalidLabel is returned when the tenant label does not satisfy
+	// the naming rules.
+	ErrInvalidLabel = errors.New("tenant: invalid label")
+	// ErrLabelMismatch is returned when the explicit label differs from
+	// the tenant of the bearer token.
+	ErrLabelMismatch = errors.New("tenant: label does not match the token")
+	// ErrNotFound is returned when there is no usage recorded for a tenant.
+	ErrNotFound = errors.New("tenant: not found")
+)
+
+var labelRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// ValidLabel reports whether the given label can be used as a tenant name.
+func ValidLabel(label string) bool {
+	return labelRegexp.MatchString(label)
+}
+
+type tenantKey struct{}
+
+// SetInContext sets the tenant label in the given context.
+func SetInContext(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, label)
+}
+
+// GetFromContext returns the tenant label stored in the context,
+// or an empty string if none was set.
+func GetFromContext(ctx context.Context) string {
+	v, ok := ctx.Value(tenantKey{}).(string)
+	if ok {
+		return v
+	}
+	return ""
+}
+
+// Usage holds the accumulated upload totals of a single tenant.
+type Usage struct {
+	Tenant string `json:"tenant"`
+	// Chunks is the number of chunks stamped on behalf of the tenant,
+	// including repeated uploads of the same content.
+	Chunks uint64 `json:"chunks"`
+	// UniqueChunks is the number of distinct chunk addresses stamped on
+	// behalf of the tenant over all the upload sessions.
+	UniqueChunks uint64 `json:"uniqueChunks"`
+	// Tags is the number of upload sessions opened by the tenant.
+	Tags uint64 `json:"tags"`
+	// Pins is the number of root references pinned by the tenant.
+	Pins uint64 `json:"pins"`
+	// BucketSlots maps the hex encoded batch ID to the number of bucket
+	// slots of the batch consumed by the tenant, which is the number of the
+	// distinct chunks stamped with the batch over all the upload sessions.
+	BucketSlots map[string]uint64 `json:"bucketSlots"`
+}
+
+// Service records and reports the usage of tenants.
+type Service struct {
+	mu     sync.Mutex
+	store  storage.StateStorer
+	tokens map[string]string
+}
+
+// New creates a new tenant accounting service persisting its state in the
+// given state store. The tokens map associates bearer tokens with tenant
+// labels and is used to resolve the tenant of requests that do not carry
+// an explicit label.
+func New(store storage.StateStorer, tokens map[string]string) *Service {
+	return &Service{
+		store:  store,
+		tokens: tokens,
+	}
+}
+
+// Resolve returns the tenant label for a request with the given explicit
+// label and bearer token. The tenant of the token has precedence, an
+// explicit label that differs from it is rejected with ErrLabelMismatch.
+// An empty label is returned if the request can not be attributed to any
+// tenant.
+func (s *Service) Resolve(label, token string) (string, error) {
+	if label != "" && !ValidLabel(label) {
+		return "", ErrInvalidLabel
+	}
+	if t, ok := s.tokens[token]; ok && token != "" {
+		if label != "" && label != t {
+			return "", ErrLabelMismatch
+		}
+		return t, nil
+	}
+	return label, nil
+}
+
+// Session counts the chunks of an upload session of a tenant in memory.
+// The counts are added to the usage of the tenant by Commit, so that the
+// chunks of the failed uploads are not counted.
+type Session struct {
+	service *Service
+	tenant  string
+
+	mu     sync.Mutex
+	chunks uint64
+	unique map[string]struct{}
+	slots  map[string]map[string]struct{} // chunk addresses per hex encoded batch ID
+}
+
+// NewSession returns the session of an upload of the tenant. Nothing is
+// recorded for the session of an empty tenant.
+func (s *Service) NewSession(tenant string) *Session {
+	return &Session{
+		service: s,
+		tenant:  tenant,
+		unique:  make(map[string]struct{}),
+		slots:   make(map[string]map[string]struct{}),
+	}
+}
+
+// RecordChunk counts the chunk with the given address stamped with the
+// given batch in the session.
+func (ss *Session) RecordChunk(batchID []byte, addr swarm.Address) {
+	if ss.tenant == "" {
+		return
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.chunks++
+	ss.unique[addr.ByteString()] = struct{}{}
+	batch := hex.EncodeToString(batchID)
+	slots, ok := ss.slots[batch]
+	if !ok {
+		slots = make(map[string]struct{})
+		ss.slots[batch] = slots
+	}
+	slots[addr.ByteString()] = struct{}{}
+}
+
+// Commit adds the counts of the session to the usage of the tenant and
+// resets the session. The chunks and the bucket slots are recorded per
+// tenant, so that the ones counted by a previous session are not counted
+// again.
+func (ss *Session) Commit() error {
+	if ss.tenant == "" {
+		return nil
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.chunks == 0 {
+		return nil
+	}
+
+	s := ss.service
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.usage(ss.tenant)
+	if err != nil {
+		return err
+	}
+	u.Chunks += ss.chunks
+	for addr := range ss.unique {
+		added, err := s.add(chunkKey(ss.tenant, addr))
+		if err != nil {
+			return err
+		}
+		if added {
+			u.UniqueChunks++
+		}
+	}
+	for batch, slots := range ss.slots {
+		for addr := range slots {
+			added, err := s.add(slotKey(ss.tenant, batch, addr))
+			if err != nil {
+				return err
+			}
+			if added {
+				u.BucketSlots[batch]++
+			}
+		}
+	}
+	if err := s.putUsage(u); err != nil {
+		return err
+	}
+
+	ss.chunks = 0
+	ss.unique = make(map[string]struct{})
+	ss.slots = make(map[string]map[string]struct{})
+	return nil
+}
+
+// RecordTag records that the upload session with the given tag ID
+// was opened by the tenant.
+func (s *Service) RecordTag(tenant string, tagID uint64) error {
+	if tenant == "" || tagID == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := tagKeyPrefix + strconv.FormatUint(tagID, 10)
+	seen, err := s.has(key)
+	if err != nil || seen {
+		return err
+	}
+	if err := s.store.Put(key, tenant); err != nil {
+		return fmt.Errorf("put tag: %w", err)
+	}
+
+	u, err := s.usage(tenant)
+	if err != nil {
+		return err
+	}
+	u.Tags++
+	return s.putUsage(u)
+}
+
+// RecordPin records that the root reference was pinned by the tenant.
+func (s *Service) RecordPin(tenant string, ref swarm.Address) error {
+	if tenant == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pinKeyPrefix + ref.String()
+	seen, err := s.has(key)
+	if err != nil || seen {
+		return err
+	}
+	if err := s.store.Put(key, tenant); err != nil {
+		return fmt.Errorf("put pin: %w", err)
+	}
+
+	u, err := s.usage(tenant)
+	if err != nil {
+		return err
+	}
+	u.Pins++
+	return s.putUsage(u)
+}
+
+// TagOwner returns the tenant that opened the upload session with the given tag ID.
+func (s *Service) TagOwner(tagID uint64) (string, error) {
+	return s.owner(tagKeyPrefix + strconv.FormatUint(tagID, 10))
+}
+
+// PinOwner returns the tenant that pinned the given root reference.
+func (s *Service) PinOwner(ref swarm.Address) (string, error) {
+	return s.owner(pinKeyPrefix + ref.String())
+}
+
+// Usage returns the usage totals of the given tenant.
+func (s *Service) Usage(tenant string) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var u Usage
+	err := s.store.Get(usageKeyPrefix+tenant, &u)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return Usage{}, ErrNotFound
+		}
+		return Usage{}, fmt.Errorf("get usage: %w", err)
+	}
+	return u, nil
+}
+
+// Usages returns the usage totals of all tenants sorted by tenant label.
+func (s *Service) Usages() ([]Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var usages []Usage
+	err := s.store.Iterate(usageKeyPrefix, func(_, val []byte) (bool, error) {
+		var u Usage
+		if err := json.Unmarshal(val, &u); err != nil {
+			return true, err
+		}
+		usages = append(usages, u)
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate usage: %w", err)
+	}
+
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Tenant < usages[j].Tenant })
+	return usages, nil
+}
+
+func (s *Service) usage(tenant string) (*Usage, error) {
+	u := &Usage{Tenant: tenant}
+	err := s.store.Get(usageKeyPrefix+tenant, u)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("get usage: %w", err)
+	}
+	if u.BucketSlots == nil {
+		u.BucketSlots = make(map[string]uint64)
+	}
+	return u, nil
+}
+
+func (s *Service) putUsage(u *Usage) error {
+	if err := s.store.Put(usageKeyPrefix+u.Tenant, u); err != nil {
+		return fmt.Errorf("put usage: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) has(key string) (bool, error) {
+	var v interface{}
+	err := s.store.Get(key, &v)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, storage.ErrNotFound):
+		return false, nil
+	default:
+		return false, fmt.Errorf("get %s: %w", key, err)
+	}
+}
+
+// add records the key and reports whether it was not recorded before.
+func (s *Service) add(key string) (bool, error) {
+	seen, err := s.has(key)
+	if err != nil || seen {
+		return false, err
+	}
+	if err := s.store.Put(key, struct{}{}); err != nil {
+		return false, fmt.Errorf("put %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (s *Service) owner(key string) (string, error) {
+	var tenant string
+	err := s.store.Get(key, &tenant)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return tenant, nil
+}
+
+func chunkKey(tenant, addr string) string {
+	return chunkKeyPrefix + tenant + "_" + hex.EncodeToString([]byte(addr))
+}
+
+func slotKey(tenant, batch, addr string) string {
+	return slotKeyPrefix + tenant + "_" + batch + "_" + hex.EncodeToString([]byte(addr))
+}
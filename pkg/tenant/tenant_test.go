@@ -0,0 +1,168 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tenant_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/tenant"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	s := tenant.New(mock.NewStateStore(), map[string]string{"secret": "team-b"})
+
+	for _, tc := range []struct {
+		name, label, token string
+		want               string
+		wantErr            error
+	}{
+		{name: "explicit label", label: "team-a", want: "team-a"},
+		{name: "label mismatching token", label: "team-a", token: "secret", wantErr: tenant.ErrLabelMismatch},
+		{name: "label matching token", label: "team-b", token: "secret", want: "team-b"},
+		{name: "token", token: "secret", want: "team-b"},
+		{name: "unknown token", token: "other"},
+		{name: "label with unknown token", label: "team-a", token: "other", want: "team-a"},
+		{name: "none"},
+		{name: "invalid label", label: "team a", wantErr: tenant.ErrInvalidLabel},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := s.Resolve(tc.label, tc.token)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Fatalf("got tenant %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	s := tenant.New(mock.NewStateStore(), nil)
+
+	var (
+		batchA = []byte{1}
+		batchB = []byte{2}
+		addr1  = swarm.RandAddress(t)
+		addr2  = swarm.RandAddress(t)
+		addr3  = swarm.RandAddress(t)
+	)
+
+	a := s.NewSession("a")
+	a.RecordChunk(batchA, addr1)
+	a.RecordChunk(batchA, addr1) // same chunk, same batch: no new slot
+	a.RecordChunk(batchB, addr1) // same chunk, other batch: new slot
+	a.RecordChunk(batchA, addr2)
+
+	b := s.NewSession("b")
+	b.RecordChunk(batchA, addr2)
+	b.RecordChunk(batchB, addr2)
+
+	none := s.NewSession("")
+	none.RecordChunk(batchB, addr1) // not attributed
+
+	// the chunks of a failed upload are not committed
+	failed := s.NewSession("b")
+	failed.RecordChunk(batchA, addr1)
+
+	if _, err := s.Usage("a"); !errors.Is(err, tenant.ErrNotFound) {
+		t.Fatalf("got error %v before the commit, want %v", err, tenant.ErrNotFound)
+	}
+
+	for _, ss := range []*tenant.Session{a, b, none} {
+		if err := ss.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// nothing is counted twice
+	if err := a.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunks of a previous session are not distinct again
+	again := s.NewSession("a")
+	again.RecordChunk(batchA, addr1)
+	again.RecordChunk(batchB, addr3)
+	if err := again.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	usages, err := s.Usages()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []tenant.Usage{
+		{
+			Tenant:       "a",
+			Chunks:       6,
+			UniqueChunks: 3,
+			BucketSlots:  map[string]uint64{"01": 2, "02": 2},
+		},
+		{
+			Tenant:       "b",
+			Chunks:       2,
+			UniqueChunks: 1,
+			BucketSlots:  map[string]uint64{"01": 1, "02": 1},
+		},
+	}
+	if diff := cmp.Diff(want, usages); diff != "" {
+		t.Fatalf("usages mismatch (-want +have):\n%s", diff)
+	}
+}
+
+func TestRecordTagAndPin(t *testing.T) {
+	t.Parallel()
+
+	s := tenant.New(mock.NewStateStore(), nil)
+	ref := swarm.RandAddress(t)
+
+	for i := 0; i < 2; i++ {
+		if err := s.RecordTag("a", 7); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RecordPin("a", ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := s.Usage("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Tags != 1 || u.Pins != 1 {
+		t.Fatalf("got tags %d pins %d, want 1 and 1", u.Tags, u.Pins)
+	}
+
+	owner, err := s.TagOwner(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "a" {
+		t.Fatalf("got tag owner %q, want %q", owner, "a")
+	}
+
+	owner, err = s.PinOwner(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "a" {
+		t.Fatalf("got pin owner %q, want %q", owner, "a")
+	}
+
+	if _, err := s.Usage("unknown"); !errors.Is(err, tenant.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, tenant.ErrNotFound)
+	}
+}
@@ -26,6 +26,12 @@ const (
 type PeerFilterFunc = peerFilterFunc
 type FilterFunc = filtersFunc
 
+func (k *Kad) PendingDisconnectReasons() int {
+	k.history.mu.Lock()
+	defer k.history.mu.Unlock()
+	return len(k.history.reasons)
+}
+
 func (k *Kad) IsWithinConnectionDepth(addr swarm.Address) bool {
 	return swarm.Proximity(k.base.Bytes(), addr.Bytes()) >= k.ConnectionDepth()
 }
@@ -0,0 +1,314 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kademlia
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+)
+
+const (
+	defaultHistoryEvents    = 4096
+	defaultHistorySnapshots = 288 // a day of snapshots at the default interval
+	defaultSnapshotInterval = 5 * time.Minute
+
+	// reasonConnectionClosed is recorded for the disconnects
+	// not initiated by this node.
+	reasonConnectionClosed = "connection closed"
+)
+
+// ring is a fixed capacity buffer which overwrites the oldest items.
+type ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRing[T any](capacity int) ring[T] {
+	return ring[T]{items: make([]T, capacity)}
+}
+
+func (r *ring[T]) add(v T) {
+	if len(r.items) == 0 {
+		return
+	}
+	r.items[r.next] = v
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// oldest returns the oldest item, which is overwritten by the next one, if
+// the buffer is full.
+func (r *ring[T]) oldest() (T, bool) {
+	if !r.full {
+		var zero T
+		return zero, false
+	}
+	return r.items[r.next], true
+}
+
+// each calls f for the items, oldest first.
+func (r *ring[T]) each(f func(T)) {
+	if r.full {
+		for _, v := range r.items[r.next:] {
+			f(v)
+		}
+	}
+	for _, v := range r.items[:r.next] {
+		f(v)
+	}
+}
+
+// history records the topology events and the periodic snapshots.
+type history struct {
+	mu        sync.Mutex
+	events    ring[topology.Event]
+	snapshots ring[topology.HistorySnapshot]
+	saturated [swarm.MaxBins]bool
+	reasons   map[string]reason // reasons of the pending disconnects initiated by this node
+}
+
+// reason is the reason of a pending disconnect and the time it was set.
+type reason struct {
+	reason string
+	at     time.Time
+}
+
+func newHistory(events, snapshots int) *history {
+	return &history{
+		events:    newRing[topology.Event](events),
+		snapshots: newRing[topology.HistorySnapshot](snapshots),
+		reasons:   make(map[string]reason),
+	}
+}
+
+func (h *history) record(e topology.Event) {
+	e.Timestamp = time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events.add(e)
+
+	// the reasons set before the oldest event in the history are for the
+	// disconnects that did not happen or that are no longer in the history
+	if oldest, ok := h.events.oldest(); ok {
+		for peer, r := range h.reasons {
+			if r.at.Before(oldest.Timestamp) {
+				delete(h.reasons, peer)
+			}
+		}
+	}
+}
+
+func (h *history) snapshot(s topology.HistorySnapshot) {
+	h.mu.Lock()
+	h.snapshots.add(s)
+	h.mu.Unlock()
+}
+
+// setReason stores the reason of a disconnect until the disconnect is
+// recorded or the reason falls out of the history. There are at most as
+// many reasons as events in the history, the oldest are dropped first.
+func (h *history) setReason(peer swarm.Address, r string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.reasons[peer.ByteString()]; !ok && len(h.reasons) >= len(h.events.items) {
+		var oldest string
+		for p, r := range h.reasons {
+			if oldest == "" || r.at.Before(h.reasons[oldest].at) {
+				oldest = p
+			}
+		}
+		delete(h.reasons, oldest)
+	}
+	h.reasons[peer.ByteString()] = reason{reason: r, at: time.Now()}
+}
+
+func (h *history) popReason(peer swarm.Address) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.reasons[peer.ByteString()]
+	if !ok {
+		return reasonConnectionClosed
+	}
+	delete(h.reasons, peer.ByteString())
+	return r.reason
+}
+
+// setSaturated stores the saturation of the bin and reports whether it changed.
+func (h *history) setSaturated(bin uint8, saturated bool) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.saturated[bin] == saturated {
+		return false
+	}
+	h.saturated[bin] = saturated
+	return true
+}
+
+func (h *history) filter(f topology.HistoryFilter) *topology.KadHistory {
+	inRange := func(t time.Time) bool {
+		return (f.From.IsZero() || !t.Before(f.From)) && (f.To.IsZero() || !t.After(f.To))
+	}
+
+	res := &topology.KadHistory{
+		Events:    []topology.Event{},
+		Snapshots: []topology.HistorySnapshot{},
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events.each(func(e topology.Event) {
+		if !inRange(e.Timestamp) {
+			return
+		}
+		if !f.Peer.IsZero() && (e.Peer == nil || !e.Peer.Equal(f.Peer)) {
+			return
+		}
+		res.Events = append(res.Events, e)
+	})
+	h.snapshots.each(func(s topology.HistorySnapshot) {
+		if inRange(s.Timestamp) {
+			res.Snapshots = append(res.Snapshots, s)
+		}
+	})
+
+	return res
+}
+
+// History returns the recorded topology events and snapshots.
+func (k *Kad) History(f topology.HistoryFilter) *topology.KadHistory {
+	return k.history.filter(f)
+}
+
+// RecordDisconnectReason implements the p2p.EventRecorder interface.
+func (k *Kad) RecordDisconnectReason(peer swarm.Address, reason string) {
+	k.history.setReason(peer, reason)
+}
+
+// RecordBlocklist implements the p2p.EventRecorder interface.
+func (k *Kad) RecordBlocklist(peer swarm.Address, duration time.Duration, reason string) {
+	e := topology.Event{
+		Type:   topology.EventBlocklist,
+		Peer:   &peer,
+		Reason: reason,
+	}
+	if duration > 0 {
+		e.Current = duration.String()
+	}
+	k.history.record(e)
+	k.recordSignal(peer, reputation.SignalBlocklist)
+}
+
+func (k *Kad) recordConnect(peer swarm.Address, direction string) {
+	po := swarm.Proximity(k.base.Bytes(), peer.Bytes())
+	k.history.record(topology.Event{
+		Type:      topology.EventConnect,
+		Peer:      &peer,
+		Bin:       &po,
+		Direction: direction,
+	})
+	k.recordBinSaturation(po)
+}
+
+func (k *Kad) recordDisconnect(peer swarm.Address) {
+	po := swarm.Proximity(k.base.Bytes(), peer.Bytes())
+	k.history.record(topology.Event{
+		Type:   topology.EventDisconnect,
+		Peer:   &peer,
+		Bin:    &po,
+		Reason: k.history.popReason(peer),
+	})
+	k.recordBinSaturation(po)
+}
+
+// recordBinSaturation records the bin crossing the saturation threshold.
+func (k *Kad) recordBinSaturation(bin uint8) {
+	saturated := k.connectedPeers.BinSize(bin) >= k.opt.SaturationPeers
+	if !k.history.setSaturated(bin, saturated) {
+		return
+	}
+	typ := topology.EventBinUnsaturated
+	if saturated {
+		typ = topology.EventBinSaturated
+	}
+	k.history.record(topology.Event{Type: typ, Bin: &bin})
+}
+
+func (k *Kad) recordChange(typ topology.EventType, prev, curr uint8) {
+	k.history.record(topology.Event{
+		Type:     typ,
+		Previous: strconv.Itoa(int(prev)),
+		Current:  strconv.Itoa(int(curr)),
+	})
+}
+
+func (k *Kad) recordReachability(peer *swarm.Address, prev, curr p2p.ReachabilityStatus) {
+	typ := topology.EventReachability
+	if peer != nil {
+		typ = topology.EventPeerReachability
+	}
+	e := topology.Event{
+		Type:    typ,
+		Peer:    peer,
+		Current: curr.String(),
+	}
+	if peer == nil {
+		e.Previous = prev.String()
+	}
+	k.history.record(e)
+}
+
+// takeHistorySnapshot records the summary of the current topology.
+func (k *Kad) takeHistorySnapshot() {
+	k.depthMu.RLock()
+	depth, radius := k.depth, k.storageRadius
+	k.depthMu.RUnlock()
+
+	s := topology.HistorySnapshot{
+		Timestamp:     time.Now(),
+		Depth:         depth,
+		StorageRadius: radius,
+		Population:    k.knownPeers.Length(),
+		Connected:     k.connectedPeers.Length(),
+		Reachability:  k.reachability.String(),
+		BinPopulation: make([]uint, swarm.MaxBins),
+		BinConnected:  make([]uint, swarm.MaxBins),
+	}
+	for i := uint8(0); i < swarm.MaxBins; i++ {
+		s.BinPopulation[i] = uint(k.knownPeers.BinSize(i))
+		s.BinConnected[i] = uint(k.connectedPeers.BinSize(i))
+	}
+	k.history.snapshot(s)
+}
+
+// historySnapshots periodically records the topology snapshots.
+func (k *Kad) historySnapshots() {
+	defer k.wg.Done()
+
+	ticker := time.NewTicker(k.opt.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.quit:
+			return
+		case <-ticker.C:
+			k.takeHistorySnapshot()
+		}
+	}
+}
@@ -17,9 +17,11 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/v2/pkg/addressbook"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
 	"github.com/ethersphere/bee/v2/pkg/discovery"
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/shed"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/topology"
@@ -64,6 +66,7 @@ var (
 	errPruneEntry        = errors.New("prune entry")
 	errEmptyBin          = errors.New("empty bin")
 	errAnnounceLightNode = errors.New("announcing light node")
+	errNoUnderlay        = errors.New("no underlay")
 )
 
 type (
@@ -86,6 +89,7 @@ type Options struct {
 	StaticNodes    []swarm.Address
 	FilterFunc     filtersFunc
 	DataDir        string
+	Reputation     reputation.Interface
 
 	BitSuffixLength             *int
 	TimeToRetry                 *time.Duration
@@ -95,6 +99,9 @@ type Options struct {
 	BootnodeOverSaturationPeers *int
 	BroadcastBinSize            *int
 	LowWaterMark                *int
+	HistoryEvents               *int
+	HistorySnapshots            *int
+	SnapshotInterval            *time.Duration
 }
 
 // kadOptions are made from Options with default values set
@@ -105,6 +112,7 @@ type kadOptions struct {
 	PruneFunc      pruneFunc
 	StaticNodes    []swarm.Address
 	FilterFunc     filtersFunc
+	Reputation     reputation.Interface
 
 	TimeToRetry                 time.Duration
 	ShortRetry                  time.Duration
@@ -114,6 +122,9 @@ type kadOptions struct {
 	BootnodeOverSaturationPeers int
 	BroadcastBinSize            int
 	LowWaterMark                int
+	HistoryEvents               int
+	HistorySnapshots            int
+	SnapshotInterval            time.Duration
 }
 
 func newKadOptions(o Options) kadOptions {
@@ -125,6 +136,7 @@ func newKadOptions(o Options) kadOptions {
 		PruneFunc:      o.PruneFunc,
 		StaticNodes:    o.StaticNodes,
 		FilterFunc:     o.FilterFunc,
+		Reputation:     o.Reputation,
 		// copy or use default
 		TimeToRetry:                 defaultValDuration(o.TimeToRetry, defaultTimeToRetry),
 		ShortRetry:                  defaultValDuration(o.ShortRetry, defaultShortRetry),
@@ -134,6 +146,9 @@ func newKadOptions(o Options) kadOptions {
 		BootnodeOverSaturationPeers: defaultValInt(o.BootnodeOverSaturationPeers, defaultBootNodeOverSaturationPeers),
 		BroadcastBinSize:            defaultValInt(o.BroadcastBinSize, defaultBroadcastBinSize),
 		LowWaterMark:                defaultValInt(o.LowWaterMark, defaultLowWaterMark),
+		HistoryEvents:               defaultValInt(o.HistoryEvents, defaultHistoryEvents),
+		HistorySnapshots:            defaultValInt(o.HistorySnapshots, defaultHistorySnapshots),
+		SnapshotInterval:            defaultValDuration(o.SnapshotInterval, defaultSnapshotInterval),
 	}
 
 	if ko.SaturationFunc == nil {
@@ -194,6 +209,7 @@ type Kad struct {
 	bgBroadcastCtx    context.Context
 	bgBroadcastCancel context.CancelFunc
 	reachability      p2p.ReachabilityStatus
+	history           *history // recorded topology events and snapshots
 }
 
 // New returns a new Kademlia.
@@ -243,6 +259,7 @@ func New(
 		metrics:           newMetrics(),
 		staticPeer:        isStaticPeer(opt.StaticNodes),
 		storageRadius:     swarm.MaxPO,
+		history:           newHistory(opt.HistoryEvents, opt.HistorySnapshots),
 	}
 
 	if k.opt.PruneFunc == nil {
@@ -413,16 +430,16 @@ func (k *Kad) connectionAttemptsHandler(ctx context.Context, wg *sync.WaitGroup,
 			}
 		}
 
-		switch err = k.connect(ctx, peer.addr, bzzAddr.Underlay); {
+		switch err = k.connect(ctx, peer.addr, bzzAddr.Underlays); {
 		case errors.Is(err, p2p.ErrNetworkUnavailable):
-			k.logger.Debug("network unavailable when reaching peer", "peer_overlay_address", peer.addr, "peer_underlay_address", bzzAddr.Underlay)
+			k.logger.Debug("network unavailable when reaching peer", "peer_overlay_address", peer.addr, "peer_underlay_addresses", bzzAddr.Underlays)
 			return
 		case errors.Is(err, errPruneEntry):
-			k.logger.Debug("dial to light node", "peer_overlay_address", peer.addr, "peer_underlay_address", bzzAddr.Underlay)
+			k.logger.Debug("dial to light node", "peer_overlay_address", peer.addr, "peer_underlay_addresses", bzzAddr.Underlays)
 			remove(peer)
 			return
 		case errors.Is(err, errOverlayMismatch):
-			k.logger.Debug("overlay mismatch has occurred", "peer_overlay_address", peer.addr, "peer_underlay_address", bzzAddr.Underlay)
+			k.logger.Debug("overlay mismatch has occurred", "peer_overlay_address", peer.addr, "peer_underlay_addresses", bzzAddr.Underlays)
 			remove(peer)
 			return
 		case errors.Is(err, p2p.ErrPeerBlocklisted):
@@ -432,6 +449,7 @@ func (k *Kad) connectionAttemptsHandler(ctx context.Context, wg *sync.WaitGroup,
 		case err != nil:
 			k.logger.Debug("peer not reachable from kademlia", "peer_address", bzzAddr, "error", err)
 			k.logger.Warning("peer not reachable when attempting to connect")
+			k.recordSignal(peer.addr, reputation.SignalFailure)
 			return
 		}
 
@@ -441,6 +459,7 @@ func (k *Kad) connectionAttemptsHandler(ctx context.Context, wg *sync.WaitGroup,
 
 		k.metrics.TotalOutboundConnections.Inc()
 		k.collector.Record(peer.addr, im.PeerLogIn(time.Now(), im.PeerConnectionDirectionOutbound))
+		k.recordConnect(peer.addr, string(im.PeerConnectionDirectionOutbound))
 
 		k.recalcDepth()
 
@@ -668,6 +687,7 @@ func (k *Kad) pruneOversaturatedBins(depth uint8) {
 
 			var disconnectPeer = swarm.ZeroAddress
 			var unreachablePeer = swarm.ZeroAddress
+			var worstPeer, worstScore = swarm.ZeroAddress, 0.0
 			for _, peer := range peers {
 				if ss := k.collector.Inspect(peer); ss != nil {
 					if !ss.Healthy {
@@ -678,6 +698,13 @@ func (k *Kad) pruneOversaturatedBins(depth uint8) {
 						unreachablePeer = peer
 					}
 				}
+				if score := k.peerScore(peer); score < worstScore {
+					worstPeer, worstScore = peer, score
+				}
+			}
+
+			if disconnectPeer.IsZero() {
+				disconnectPeer = worstPeer // prefer keeping well-behaved peers
 			}
 
 			if disconnectPeer.IsZero() {
@@ -716,6 +743,9 @@ func (k *Kad) Start(_ context.Context) error {
 	k.wg.Add(1)
 	go k.manage()
 
+	k.wg.Add(1)
+	go k.historySnapshots()
+
 	k.AddPeers(k.previouslyConnected()...)
 
 	go func() {
@@ -812,6 +842,7 @@ func (k *Kad) connectBootNodes(ctx context.Context) {
 
 			k.metrics.TotalOutboundConnections.Inc()
 			k.collector.Record(bzzAddress.Overlay, im.PeerLogIn(time.Now(), im.PeerConnectionDirectionOutbound))
+			k.recordConnect(bzzAddress.Overlay, string(im.PeerConnectionDirectionOutbound))
 			loggerV1.Debug("connected to bootnode", "bootnode_address", addr)
 			connected++
 
@@ -848,6 +879,13 @@ func (k *Kad) recalcDepth() {
 	k.depthMu.Lock()
 	defer k.depthMu.Unlock()
 
+	prev := k.depth
+	defer func() {
+		if k.depth != prev {
+			k.recordChange(topology.EventDepth, prev, k.depth)
+		}
+	}()
+
 	var (
 		peers                 = k.connectedPeers
 		filter                = k.opt.FilterFunc(im.Reachability(false))
@@ -915,7 +953,7 @@ func (k *Kad) recalcDepth() {
 
 // connect connects to a peer and gossips its address to our connected peers,
 // as well as sends the peers we are connected to the newly connected peer
-func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr) error {
+func (k *Kad) connect(ctx context.Context, peer swarm.Address, underlays []ma.Multiaddr) error {
 	k.logger.Debug("attempting connect to peer", "peer_address", peer)
 
 	ctx, cancel := context.WithTimeout(ctx, peerConnectionAttemptTimeout)
@@ -923,7 +961,7 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr)
 
 	k.metrics.TotalOutboundConnectionAttempts.Inc()
 
-	switch i, err := k.p2p.Connect(ctx, ma); {
+	switch i, err := k.dial(ctx, underlays); {
 	case errors.Is(err, p2p.ErrNetworkUnavailable):
 		return err
 	case k.p2p.NetworkStatus() == p2p.NetworkStatusUnavailable:
@@ -981,6 +1019,32 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr)
 	return k.Announce(ctx, peer, true)
 }
 
+// dial tries the underlays of a peer in order until one of them connects.
+// The remaining underlays are not tried if the error is not specific to
+// the dialed underlay.
+func (k *Kad) dial(ctx context.Context, underlays []ma.Multiaddr) (i *bzz.Address, err error) {
+	if len(underlays) == 0 {
+		return nil, errNoUnderlay
+	}
+
+	for _, underlay := range underlays {
+		i, err = k.p2p.Connect(ctx, underlay)
+		var e *p2p.ConnectionBackoffError
+		switch {
+		case err == nil,
+			errors.Is(err, p2p.ErrAlreadyConnected),
+			errors.Is(err, p2p.ErrNetworkUnavailable),
+			errors.Is(err, p2p.ErrDialLightNode),
+			errors.Is(err, p2p.ErrPeerBlocklisted),
+			errors.Is(err, context.Canceled),
+			errors.Is(err, context.DeadlineExceeded),
+			errors.As(err, &e):
+			return i, err
+		}
+	}
+	return i, err
+}
+
 // Announce a newly connected peer to our connected peers, but also
 // notify the peer about our already connected peers
 func (k *Kad) Announce(ctx context.Context, peer swarm.Address, fullnode bool) error {
@@ -1126,6 +1190,7 @@ func (k *Kad) Connected(ctx context.Context, peer p2p.Peer, forceConnection bool
 		if err == nil {
 			k.metrics.TotalInboundConnections.Inc()
 			k.collector.Record(peer.Address, im.PeerLogIn(time.Now(), im.PeerConnectionDirectionInbound))
+			k.recordConnect(peer.Address, string(im.PeerConnectionDirectionInbound))
 		}
 	}()
 
@@ -1172,6 +1237,7 @@ func (k *Kad) Disconnected(peer p2p.Peer) {
 	k.logger.Info("disconnected peer", "peer_address", peer.Address)
 
 	k.connectedPeers.Remove(peer.Address)
+	k.recordDisconnect(peer.Address)
 
 	k.waitNext.SetTryAfter(peer.Address, time.Now().Add(k.opt.TimeToRetry))
 
@@ -1239,6 +1305,18 @@ func (k *Kad) ClosestPeer(addr swarm.Address, includeSelf bool, filter topology.
 			return false, false, nil
 		}
 
+		// the reputation only breaks the ties between the peers
+		// in the same proximity order to the address.
+		if k.opt.Reputation != nil && !closest.Equal(k.base) &&
+			swarm.Proximity(peer.Bytes(), addr.Bytes()) == swarm.Proximity(closest.Bytes(), addr.Bytes()) {
+			if ps, cs := k.peerScore(peer), k.peerScore(closest); ps != cs {
+				if ps > cs {
+					closest = peer
+				}
+				return false, false, nil
+			}
+		}
+
 		closer, err := peer.Closer(addr, closest)
 		if closer {
 			closest = peer
@@ -1295,6 +1373,7 @@ func (k *Kad) PeersCount(filter topology.Select) int {
 func (k *Kad) Reachable(addr swarm.Address, status p2p.ReachabilityStatus) {
 	k.collector.Record(addr, im.PeerReachability(status))
 	k.logger.Debug("reachability of peer updated", "peer_address", addr, "reachability", status)
+	k.recordReachability(&addr, p2p.ReachabilityStatusUnknown, status)
 	if status == p2p.ReachabilityStatusPublic {
 		k.recalcDepth()
 		k.notifyManageLoop()
@@ -1309,6 +1388,9 @@ func (k *Kad) UpdateReachability(status p2p.ReachabilityStatus) {
 		return
 	}
 	k.logger.Debug("reachability updated", "reachability", status)
+	if k.reachability != status {
+		k.recordReachability(nil, k.reachability, status)
+	}
 	k.reachability = status
 	k.metrics.ReachabilityStatus.WithLabelValues(status.String()).Set(0)
 }
@@ -1318,6 +1400,11 @@ func (k *Kad) UpdateReachability(status p2p.ReachabilityStatus) {
 // p2p.ReachabilityStatusUnknown are ignored.
 func (k *Kad) UpdatePeerHealth(peer swarm.Address, health bool, dur time.Duration) {
 	k.collector.Record(peer, im.PeerHealth(health), im.PeerLatency(dur))
+	if health {
+		k.recordSignal(peer, reputation.SignalHealthy)
+	} else {
+		k.recordSignal(peer, reputation.SignalUnhealthy)
+	}
 }
 
 // SubscribeTopologyChange returns the channel that signals when the connected peers
@@ -1379,6 +1466,7 @@ func (k *Kad) SetStorageRadius(d uint8) {
 		return
 	}
 
+	k.recordChange(topology.EventStorageRadius, k.storageRadius, d)
 	k.storageRadius = d
 	k.metrics.CurrentStorageDepth.Set(float64(k.storageRadius))
 	k.logger.Debug("kademlia set storage radius", "radius", k.storageRadius)
@@ -1474,6 +1562,22 @@ func (k *Kad) Snapshot() *topology.KadParams {
 	}
 }
 
+// recordSignal records the peer quality signal if the reputation is enabled.
+func (k *Kad) recordSignal(peer swarm.Address, s reputation.Signal) {
+	if k.opt.Reputation != nil {
+		k.opt.Reputation.Record(peer, s)
+	}
+}
+
+// peerScore returns the reputation score of the peer,
+// or zero if the reputation is not enabled.
+func (k *Kad) peerScore(peer swarm.Address) float64 {
+	if k.opt.Reputation == nil {
+		return 0
+	}
+	return k.opt.Reputation.Score(peer)
+}
+
 // String returns a string represenstation of Kademlia.
 func (k *Kad) String() string {
 	j := k.Snapshot()
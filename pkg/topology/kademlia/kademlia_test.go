@@ -25,6 +25,7 @@ import (
 	"github.com/ethersphere/bee/v2/pkg/log"
 	"github.com/ethersphere/bee/v2/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/v2/pkg/p2p/mock"
+	"github.com/ethersphere/bee/v2/pkg/reputation"
 	"github.com/ethersphere/bee/v2/pkg/spinlock"
 	mockstate "github.com/ethersphere/bee/v2/pkg/statestore/mock"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
@@ -824,7 +825,7 @@ func TestAddressBookPrune(t *testing.T) {
 	}
 	testutil.CleanupCloser(t, kad)
 
-	nonConnPeer, err := bzz.NewAddress(signer, nonConnectableAddress, swarm.RandAddressAt(t, base, 1), 0, nil)
+	nonConnPeer, err := bzz.NewAddress(signer, []ma.Multiaddr{nonConnectableAddress}, swarm.RandAddressAt(t, base, 1), 0, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -902,7 +903,7 @@ func TestAddressBookQuickPrune_FLAKY(t *testing.T) {
 
 	time.Sleep(100 * time.Millisecond)
 
-	nonConnPeer, err := bzz.NewAddress(signer, nonConnectableAddress, swarm.RandAddressAt(t, base, 1), 0, nil)
+	nonConnPeer, err := bzz.NewAddress(signer, []ma.Multiaddr{nonConnectableAddress}, swarm.RandAddressAt(t, base, 1), 0, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1028,6 +1029,58 @@ func TestClosestPeer(t *testing.T) {
 	}
 }
 
+func TestClosestPeerReputation(t *testing.T) {
+	t.Parallel()
+
+	rep, err := reputation.New(mockstate.NewStateStore(), log.Noop, reputation.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, rep)
+
+	var (
+		base, kad, ab, _, signer = newTestKademlia(t, nil, nil, kademlia.Options{Reputation: rep})
+		target                   = swarm.RandAddressAt(t, base, 3)
+		// bad and tied are in the same proximity order to the target.
+		bad  = swarm.RandAddressAt(t, target, 8)
+		tied = swarm.RandAddressAt(t, target, 8)
+		far  = swarm.RandAddressAt(t, target, 1)
+	)
+
+	if closer, _ := tied.Closer(target, bad); closer {
+		bad, tied = tied, bad
+	}
+
+	connectOne(t, signer, kad, ab, bad, nil)
+	connectOne(t, signer, kad, ab, tied, nil)
+	connectOne(t, signer, kad, ab, far, nil)
+
+	closest := func(skip ...swarm.Address) swarm.Address {
+		t.Helper()
+		peer, err := kad.ClosestPeer(target, false, topology.Select{}, skip...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return peer
+	}
+
+	if peer := closest(); !peer.Equal(bad) {
+		t.Fatalf("got peer %s, want %s", peer, bad)
+	}
+
+	rep.Record(bad, reputation.SignalBlocklist)
+
+	// the reputation breaks the tie in the same proximity order
+	if peer := closest(); !peer.Equal(tied) {
+		t.Fatalf("got peer %s, want the peer with good reputation %s", peer, tied)
+	}
+
+	// the reputation does not rank a closer peer below a farther one
+	if peer := closest(tied); !peer.Equal(bad) {
+		t.Fatalf("got peer %s, want %s", peer, bad)
+	}
+}
+
 func TestKademlia_SubscribeTopologyChange(t *testing.T) {
 	t.Parallel()
 
@@ -1188,6 +1241,107 @@ func TestSnapshot_FLAKY(t *testing.T) {
 	}
 }
 
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	base, kad, ab, _, signer := newTestKademlia(t, nil, nil, kademlia.Options{
+		SaturationPeers:  ptrInt(2),
+		SnapshotInterval: ptrDuration(10 * time.Millisecond),
+	})
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, kad)
+
+	var (
+		peer1 = swarm.RandAddressAt(t, base, 2)
+		peer2 = swarm.RandAddressAt(t, base, 2)
+	)
+
+	connectOne(t, signer, kad, ab, peer1, nil)
+	connectOne(t, signer, kad, ab, peer2, nil)
+	kad.RecordBlocklist(peer1, time.Minute, "misbehaving")
+	kad.RecordDisconnectReason(peer1, "misbehaving")
+	removeOne(kad, peer1)
+	removeOne(kad, peer2)
+
+	types := func(events []topology.Event) []topology.EventType {
+		var res []topology.EventType
+		for _, e := range events {
+			res = append(res, e.Type)
+		}
+		return res
+	}
+
+	h := kad.History(topology.HistoryFilter{})
+	want := []topology.EventType{
+		topology.EventConnect,
+		topology.EventConnect,
+		topology.EventBinSaturated,
+		topology.EventBlocklist,
+		topology.EventDisconnect,
+		topology.EventBinUnsaturated,
+		topology.EventDisconnect,
+	}
+	if have := types(h.Events); !reflect.DeepEqual(have, want) {
+		t.Fatalf("got events %v, want %v", have, want)
+	}
+
+	h = kad.History(topology.HistoryFilter{Peer: peer1})
+	want = []topology.EventType{
+		topology.EventConnect,
+		topology.EventBlocklist,
+		topology.EventDisconnect,
+	}
+	if have := types(h.Events); !reflect.DeepEqual(have, want) {
+		t.Fatalf("got peer events %v, want %v", have, want)
+	}
+	if h.Events[2].Reason != "misbehaving" {
+		t.Fatalf("got disconnect reason %q, want %q", h.Events[2].Reason, "misbehaving")
+	}
+
+	h = kad.History(topology.HistoryFilter{Peer: peer2})
+	if reason := h.Events[len(h.Events)-1].Reason; reason != "connection closed" {
+		t.Fatalf("got disconnect reason %q, want %q", reason, "connection closed")
+	}
+
+	h = kad.History(topology.HistoryFilter{To: time.Now().Add(-time.Hour)})
+	if len(h.Events) != 0 {
+		t.Fatalf("got %d events, want none", len(h.Events))
+	}
+
+	err := spinlock.Wait(time.Second, func() bool {
+		return len(kad.History(topology.HistoryFilter{}).Snapshots) > 0
+	})
+	if err != nil {
+		t.Fatal("no history snapshot taken")
+	}
+}
+
+func TestHistoryDisconnectReasons(t *testing.T) {
+	t.Parallel()
+
+	base, kad, _, _, _ := newTestKademlia(t, nil, nil, kademlia.Options{
+		HistoryEvents: ptrInt(2),
+	})
+
+	// the reasons are bounded by the size of the history
+	for i := 0; i < 3; i++ {
+		kad.RecordDisconnectReason(swarm.RandAddressAt(t, base, 2), "misbehaving")
+	}
+	if n := kad.PendingDisconnectReasons(); n != 2 {
+		t.Fatalf("got %d pending disconnect reasons, want 2", n)
+	}
+
+	// the reasons older than the history are dropped
+	for i := 0; i < 2; i++ {
+		kad.RecordBlocklist(swarm.RandAddressAt(t, base, 2), time.Minute, "misbehaving")
+	}
+	if n := kad.PendingDisconnectReasons(); n != 0 {
+		t.Fatalf("got %d pending disconnect reasons, want none", n)
+	}
+}
+
 func getBinPopulation(bins *topology.KadBins, po uint8) uint64 {
 	rv := reflect.ValueOf(bins)
 	bin := fmt.Sprintf("Bin%d", po)
@@ -1222,7 +1376,7 @@ func TestStart(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			bzzAddr, err := bzz.NewAddress(signer, multiaddr, peer, 0, nil)
+			bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{multiaddr}, peer, 0, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -1894,13 +2048,15 @@ func p2pMock(t *testing.T, ab addressbook.Interface, signer beeCrypto.Signer, co
 			}
 
 			for _, a := range addresses {
-				if a.Underlay.Equal(addr) {
-					return &a, nil
+				for _, u := range a.Underlays {
+					if u.Equal(addr) {
+						return &a, nil
+					}
 				}
 			}
 
 			address := swarm.RandAddress(t)
-			bzzAddr, err := bzz.NewAddress(signer, addr, address, 0, nil)
+			bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{addr}, address, 0, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -1935,7 +2091,7 @@ func connectOne(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addre
 		t.Fatal(err)
 	}
 
-	bzzAddr, err := bzz.NewAddress(signer, multiaddr, peer, 0, nil)
+	bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{multiaddr}, peer, 0, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1955,7 +2111,7 @@ func addOne(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addressbo
 	if err != nil {
 		t.Fatal(err)
 	}
-	bzzAddr, err := bzz.NewAddress(signer, multiaddr, peer, 0, nil)
+	bzzAddr, err := bzz.NewAddress(signer, []ma.Multiaddr{multiaddr}, peer, 0, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -222,6 +222,10 @@ func (m *Mock) Snapshot() *topology.KadParams {
 	panic("not implemented") // TODO: Implement
 }
 
+func (m *Mock) History(topology.HistoryFilter) *topology.KadHistory {
+	panic("not implemented") // TODO: Implement
+}
+
 type Option interface {
 	apply(*Mock)
 }
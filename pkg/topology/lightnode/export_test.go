@@ -0,0 +1,8 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightnode
+
+func (u *Upstream) Check()            { u.check() }
+func (u *Upstream) RefreshConnected() { u.refreshConnected() }
@@ -38,3 +38,47 @@ func newMetrics() metrics {
 func (c *Container) Metrics() []prometheus.Collector {
 	return m.PrometheusCollectorsFromFields(c.metrics)
 }
+
+// upstreamMetrics groups the prometheus counters of the upstream peers pool.
+type upstreamMetrics struct {
+	HealthyPeers       prometheus.Gauge
+	UpstreamSelections prometheus.Counter
+	FallbackSelections prometheus.Counter
+	Disconnections     prometheus.Counter
+}
+
+func newUpstreamMetrics() upstreamMetrics {
+	const subsystem = "lightnode_upstream"
+
+	return upstreamMetrics{
+		HealthyPeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "healthy_peers",
+			Help:      "Number of connected and healthy upstream peers.",
+		}),
+		UpstreamSelections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "upstream_selections",
+			Help:      "Number of requests routed to an upstream peer.",
+		}),
+		FallbackSelections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "fallback_selections",
+			Help:      "Number of requests routed by the topology because no upstream peer was usable.",
+		}),
+		Disconnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disconnections",
+			Help:      "Number of times a connected upstream peer disappeared.",
+		}),
+	}
+}
+
+// Metrics returns set of prometheus collectors.
+func (u *Upstream) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(u.metrics)
+}
@@ -0,0 +1,407 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightnode
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	"github.com/ethersphere/bee/v2/pkg/pingpong"
+	"github.com/ethersphere/bee/v2/pkg/status"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "lightnode"
+
+const (
+	defaultHealthInterval = 15 * time.Second
+	defaultCheckTimeout   = 5 * time.Second
+
+	// fullMode is the bee mode reported in the status snapshots of full nodes.
+	fullMode = "full"
+	// minLatency bounds the weight of the peers with unmeasurably small latencies.
+	minLatency = time.Millisecond
+)
+
+// Connector dials the upstream peers.
+type Connector interface {
+	Connect(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error)
+}
+
+// StatusSnapshotter fetches the status snapshots of the upstream peers.
+type StatusSnapshotter interface {
+	PeerSnapshot(ctx context.Context, peer swarm.Address) (*status.Snapshot, error)
+}
+
+// PeerAccounting reports the accounting state of the upstream peers.
+type PeerAccounting interface {
+	PeerAccounting() (map[string]accounting.PeerInfo, error)
+}
+
+// UpstreamOptions configures the pool of the upstream peers.
+type UpstreamOptions struct {
+	// Peers are the underlay addresses of the preferred full nodes.
+	Peers []ma.Multiaddr
+	// HealthInterval is the period of the health checks.
+	HealthInterval time.Duration
+	// CheckTimeout bounds the duration of a single peer health check.
+	CheckTimeout time.Duration
+}
+
+type upstreamPeer struct {
+	underlay  ma.Multiaddr
+	overlay   swarm.Address
+	connected bool
+	healthy   bool
+	latency   time.Duration // moving average of the measured round trip times
+	headroom  float64       // fraction of the payment threshold still available
+}
+
+// UpstreamPeer is the state of an upstream peer.
+type UpstreamPeer struct {
+	Underlay  string
+	Overlay   swarm.Address
+	Connected bool
+	Healthy   bool
+	Latency   time.Duration
+	Headroom  float64
+}
+
+// Upstream is the client side of the light node mode. It keeps a pool of
+// preferred full node peers, health checks them with pingpong and status
+// snapshots and spreads the retrieval and pushsync requests across them by
+// the measured latency and the accounting headroom. When no upstream peer
+// is usable, the requests fall back to the wrapped topology driver.
+//
+// Light nodes never store chunks, so the includeSelf argument of ClosestPeer
+// only matters for the fallback.
+type Upstream struct {
+	topology.Driver
+
+	connector  Connector
+	pinger     pingpong.Interface
+	status     StatusSnapshotter
+	accounting PeerAccounting
+	logger     log.Logger
+	opts       UpstreamOptions
+	metrics    upstreamMetrics
+
+	mu    sync.RWMutex
+	peers []*upstreamPeer
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewUpstream creates the pool of the upstream peers.
+func NewUpstream(
+	driver topology.Driver,
+	connector Connector,
+	pinger pingpong.Interface,
+	status StatusSnapshotter,
+	accounting PeerAccounting,
+	logger log.Logger,
+	o UpstreamOptions,
+) *Upstream {
+	if o.HealthInterval <= 0 {
+		o.HealthInterval = defaultHealthInterval
+	}
+	if o.CheckTimeout <= 0 {
+		o.CheckTimeout = defaultCheckTimeout
+	}
+
+	u := &Upstream{
+		Driver:     driver,
+		connector:  connector,
+		pinger:     pinger,
+		status:     status,
+		accounting: accounting,
+		logger:     logger.WithName(loggerName).Register(),
+		opts:       o,
+		metrics:    newUpstreamMetrics(),
+		quit:       make(chan struct{}),
+	}
+	for _, addr := range o.Peers {
+		u.peers = append(u.peers, &upstreamPeer{underlay: addr})
+	}
+
+	return u
+}
+
+// Start starts the health checks of the upstream peers.
+func (u *Upstream) Start() {
+	u.wg.Add(1)
+	go u.manage()
+}
+
+// ClosestPeer returns an upstream peer picked by the latency and the
+// accounting headroom or the closest peer of the wrapped driver if none
+// of the upstream peers is usable.
+func (u *Upstream) ClosestPeer(addr swarm.Address, includeSelf bool, f topology.Select, skipPeers ...swarm.Address) (swarm.Address, error) {
+	if peer, ok := u.pick(skipPeers); ok {
+		u.metrics.UpstreamSelections.Inc()
+		return peer, nil
+	}
+	u.metrics.FallbackSelections.Inc()
+	return u.Driver.ClosestPeer(addr, includeSelf, f, skipPeers...)
+}
+
+// Peers returns the state of the upstream peers.
+func (u *Upstream) Peers() []UpstreamPeer {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	peers := make([]UpstreamPeer, 0, len(u.peers))
+	for _, p := range u.peers {
+		peers = append(peers, UpstreamPeer{
+			Underlay:  p.underlay.String(),
+			Overlay:   p.overlay,
+			Connected: p.connected,
+			Healthy:   p.healthy,
+			Latency:   p.latency,
+			Headroom:  p.headroom,
+		})
+	}
+	return peers
+}
+
+// Close stops the health checks. The wrapped driver is not closed.
+func (u *Upstream) Close() error {
+	close(u.quit)
+	u.wg.Wait()
+	return nil
+}
+
+// pick selects a random usable upstream peer with the probability
+// proportional to its headroom and inversely proportional to its latency.
+func (u *Upstream) pick(skipPeers []swarm.Address) (swarm.Address, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	var (
+		candidates []swarm.Address
+		weights    []float64
+		total      float64
+	)
+	for _, p := range u.peers {
+		if !p.connected || !p.healthy || p.headroom <= 0 || swarm.ContainsAddress(skipPeers, p.overlay) {
+			continue
+		}
+		latency := max(p.latency, minLatency)
+		w := p.headroom / latency.Seconds()
+		candidates = append(candidates, p.overlay)
+		weights = append(weights, w)
+		total += w
+	}
+	if len(candidates) == 0 {
+		return swarm.ZeroAddress, false
+	}
+
+	//nolint:gosec
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return candidates[i], true
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1], true
+}
+
+func (u *Upstream) manage() {
+	defer u.wg.Done()
+
+	topologyChange, unsubscribe := u.Driver.SubscribeTopologyChange()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(u.opts.HealthInterval)
+	defer ticker.Stop()
+
+	u.check()
+	for {
+		select {
+		case <-u.quit:
+			return
+		case <-topologyChange:
+			u.refreshConnected()
+		case <-ticker.C:
+			u.check()
+		}
+	}
+}
+
+// refreshConnected marks the upstream peers that have disappeared from the
+// topology as disconnected, so that the requests fail over to the others
+// before the next health check.
+func (u *Upstream) refreshConnected() {
+	var connected []swarm.Address
+	_ = u.Driver.EachConnectedPeer(func(addr swarm.Address, _ uint8) (bool, bool, error) {
+		connected = append(connected, addr)
+		return false, false, nil
+	}, topology.Select{})
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, p := range u.peers {
+		if !p.connected || swarm.ContainsAddress(connected, p.overlay) {
+			continue
+		}
+		p.connected = false
+		u.metrics.Disconnections.Inc()
+		u.logger.Debug("upstream peer disconnected", "peer_address", p.overlay, "underlay", p.underlay)
+	}
+	u.updateGauges()
+}
+
+// check connects the missing upstream peers and measures the health,
+// latency and accounting headroom of all of them.
+func (u *Upstream) check() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-u.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	u.mu.RLock()
+	peers := make([]upstreamPeer, len(u.peers))
+	wasConnected := make([]bool, len(u.peers))
+	for i, p := range u.peers {
+		peers[i] = *p
+		wasConnected[i] = p.connected
+	}
+	u.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range peers {
+		wg.Add(1)
+		go func(p *upstreamPeer) {
+			defer wg.Done()
+			u.checkPeer(ctx, p)
+		}(&peers[i])
+	}
+	wg.Wait()
+
+	var info map[string]accounting.PeerInfo
+	if u.accounting != nil {
+		var err error
+		if info, err = u.accounting.PeerAccounting(); err != nil {
+			u.logger.Debug("upstream peer accounting failed", "error", err)
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for i, p := range u.peers {
+		checked := peers[i]
+		if wasConnected[i] && !p.connected {
+			// the peer disappeared while it was being checked
+			checked.connected, checked.healthy = false, false
+		}
+		checked.headroom = headroom(info, checked.overlay)
+		if p.healthy && !checked.healthy {
+			u.logger.Info("upstream peer unhealthy", "peer_address", checked.overlay, "underlay", checked.underlay)
+		}
+		*p = checked
+	}
+	u.updateGauges()
+}
+
+func (u *Upstream) checkPeer(ctx context.Context, p *upstreamPeer) {
+	ctx, cancel := context.WithTimeout(ctx, u.opts.CheckTimeout)
+	defer cancel()
+
+	p.healthy = false
+
+	if !p.connected {
+		addr, err := u.connector.Connect(ctx, p.underlay)
+		switch {
+		case errors.Is(err, p2p.ErrAlreadyConnected):
+		case err != nil:
+			u.logger.Debug("upstream peer connect failed", "underlay", p.underlay, "error", err)
+			return
+		default:
+			if err := u.Driver.Connected(ctx, p2p.Peer{Address: addr.Overlay, FullNode: true}, true); err != nil {
+				u.logger.Debug("upstream peer topology connect failed", "peer_address", addr.Overlay, "error", err)
+				return
+			}
+		}
+		p.overlay = addr.Overlay
+		p.connected = true
+	}
+
+	rtt, err := u.pinger.Ping(ctx, p.overlay)
+	if err != nil {
+		// redial on the next check in case the connection is gone
+		p.connected = false
+		u.logger.Debug("upstream peer ping failed", "peer_address", p.overlay, "error", err)
+		return
+	}
+
+	snapshot, err := u.status.PeerSnapshot(ctx, p.overlay)
+	if err != nil {
+		u.logger.Debug("upstream peer status snapshot failed", "peer_address", p.overlay, "error", err)
+		return
+	}
+	if snapshot.BeeMode != fullMode {
+		u.logger.Debug("upstream peer is not a full node", "peer_address", p.overlay, "mode", snapshot.BeeMode)
+		return
+	}
+
+	if p.latency == 0 {
+		p.latency = rtt
+	} else {
+		p.latency = (3*p.latency + rtt) / 4
+	}
+	p.healthy = true
+}
+
+// updateGauges must be called with the lock held.
+func (u *Upstream) updateGauges() {
+	var healthy int
+	for _, p := range u.peers {
+		if p.connected && p.healthy {
+			healthy++
+		}
+	}
+	u.metrics.HealthyPeers.Set(float64(healthy))
+}
+
+// headroom returns the fraction of the payment threshold of the peer that
+// can still be spent before a payment is due. Without accounting information
+// the whole threshold is assumed to be available.
+func headroom(info map[string]accounting.PeerInfo, peer swarm.Address) float64 {
+	i, ok := info[peer.String()]
+	if !ok || i.ThresholdReceived == nil || i.ThresholdReceived.Sign() <= 0 {
+		return 1
+	}
+
+	available := new(big.Int).Add(i.ThresholdReceived, i.Balance)
+	if i.ReservedBalance != nil {
+		available.Sub(available, i.ReservedBalance)
+	}
+	if available.Sign() <= 0 {
+		return 0
+	}
+
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(available), new(big.Float).SetInt(i.ThresholdReceived)).Float64()
+	return min(f, 1)
+}
@@ -0,0 +1,226 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lightnode_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/accounting"
+	"github.com/ethersphere/bee/v2/pkg/bzz"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/p2p"
+	pingpongmock "github.com/ethersphere/bee/v2/pkg/pingpong/mock"
+	"github.com/ethersphere/bee/v2/pkg/status"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/topology"
+	"github.com/ethersphere/bee/v2/pkg/topology/lightnode"
+	topologymock "github.com/ethersphere/bee/v2/pkg/topology/mock"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestUpstream(t *testing.T) {
+	t.Parallel()
+
+	var (
+		underlay1 = mustMultiaddr(t, "/ip4/127.0.0.1/tcp/1634")
+		underlay2 = mustMultiaddr(t, "/ip4/127.0.0.1/tcp/1635")
+		overlay1  = swarm.RandAddress(t)
+		overlay2  = swarm.RandAddress(t)
+		fallback  = swarm.RandAddress(t)
+		chunk     = swarm.RandAddress(t)
+	)
+
+	newUpstream := func(t *testing.T, env *upstreamEnv) (*lightnode.Upstream, topology.Driver) {
+		t.Helper()
+
+		driver := topologymock.NewTopologyDriver(topologymock.WithPeers(fallback), topologymock.WithClosestPeer(fallback))
+		connector := connectorFunc(func(_ context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+			if env.unreachable(addr) {
+				return nil, errors.New("unreachable")
+			}
+			if addr.Equal(underlay1) {
+				return &bzz.Address{Overlay: overlay1}, nil
+			}
+			return &bzz.Address{Overlay: overlay2}, nil
+		})
+		pinger := pingpongmock.New(func(_ context.Context, peer swarm.Address, _ ...string) (time.Duration, error) {
+			return 10 * time.Millisecond, nil
+		})
+		snapshotter := snapshotterFunc(func(_ context.Context, peer swarm.Address) (*status.Snapshot, error) {
+			return &status.Snapshot{BeeMode: env.mode(peer)}, nil
+		})
+
+		u := lightnode.NewUpstream(driver, connector, pinger, snapshotter, env, log.Noop, lightnode.UpstreamOptions{
+			Peers:          []ma.Multiaddr{underlay1, underlay2},
+			HealthInterval: time.Hour,
+		})
+		t.Cleanup(func() { _ = u.Close() })
+		u.Check()
+		return u, driver
+	}
+
+	picked := func(t *testing.T, u *lightnode.Upstream, skip ...swarm.Address) map[string]int {
+		t.Helper()
+
+		counts := make(map[string]int)
+		for i := 0; i < 200; i++ {
+			peer, err := u.ClosestPeer(chunk, false, topology.Select{}, skip...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[peer.String()]++
+		}
+		return counts
+	}
+
+	t.Run("spreads requests across healthy peers", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := newUpstream(t, &upstreamEnv{})
+
+		counts := picked(t, u)
+		if counts[overlay1.String()] == 0 || counts[overlay2.String()] == 0 || counts[fallback.String()] != 0 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+
+		for _, p := range u.Peers() {
+			if !p.Connected || !p.Healthy || p.Latency != 10*time.Millisecond || p.Headroom != 1 {
+				t.Fatalf("unexpected peer state %+v", p)
+			}
+		}
+	})
+
+	t.Run("skips peers and falls back to the topology", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := newUpstream(t, &upstreamEnv{})
+
+		counts := picked(t, u, overlay1)
+		if counts[overlay2.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+
+		counts = picked(t, u, overlay1, overlay2)
+		if counts[fallback.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+	})
+
+	t.Run("excludes unhealthy peers", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := newUpstream(t, &upstreamEnv{lightPeer: overlay1})
+
+		counts := picked(t, u)
+		if counts[overlay2.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+	})
+
+	t.Run("excludes peers without accounting headroom", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := newUpstream(t, &upstreamEnv{exhaustedPeer: overlay2})
+
+		counts := picked(t, u)
+		if counts[overlay1.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+	})
+
+	t.Run("fails over when a peer disappears", func(t *testing.T) {
+		t.Parallel()
+
+		env := &upstreamEnv{}
+		u, driver := newUpstream(t, env)
+
+		env.setUnreachable(underlay1)
+		driver.Disconnected(p2p.Peer{Address: overlay1})
+		u.RefreshConnected()
+
+		counts := picked(t, u)
+		if counts[overlay2.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+
+		u.Check()
+		counts = picked(t, u)
+		if counts[overlay2.String()] != 200 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+
+		env.setUnreachable(nil)
+		u.Check()
+		counts = picked(t, u)
+		if counts[overlay1.String()] == 0 {
+			t.Fatalf("unexpected selections %v", counts)
+		}
+	})
+}
+
+type upstreamEnv struct {
+	mu            sync.Mutex
+	down          ma.Multiaddr
+	lightPeer     swarm.Address
+	exhaustedPeer swarm.Address
+}
+
+func (e *upstreamEnv) setUnreachable(addr ma.Multiaddr) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.down = addr
+}
+
+func (e *upstreamEnv) unreachable(addr ma.Multiaddr) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.down != nil && e.down.Equal(addr)
+}
+
+func (e *upstreamEnv) mode(peer swarm.Address) string {
+	if peer.Equal(e.lightPeer) {
+		return "light"
+	}
+	return "full"
+}
+
+func (e *upstreamEnv) PeerAccounting() (map[string]accounting.PeerInfo, error) {
+	info := make(map[string]accounting.PeerInfo)
+	if !e.exhaustedPeer.IsZero() {
+		info[e.exhaustedPeer.String()] = accounting.PeerInfo{
+			Balance:           big.NewInt(-100),
+			ThresholdReceived: big.NewInt(100),
+			ReservedBalance:   big.NewInt(0),
+		}
+	}
+	return info, nil
+}
+
+type connectorFunc func(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error)
+
+func (f connectorFunc) Connect(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+	return f(ctx, addr)
+}
+
+type snapshotterFunc func(ctx context.Context, peer swarm.Address) (*status.Snapshot, error)
+
+func (f snapshotterFunc) PeerSnapshot(ctx context.Context, peer swarm.Address) (*status.Snapshot, error) {
+	return f(ctx, peer)
+}
+
+func mustMultiaddr(t *testing.T, s string) ma.Multiaddr {
+	t.Helper()
+
+	addr, err := ma.NewMultiaddr(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
@@ -24,6 +24,7 @@ type mock struct {
 	addPeersErr     error
 	isWithinFunc    func(c swarm.Address) bool
 	marshalJSONFunc func() ([]byte, error)
+	historyFunc     func(topology.HistoryFilter) *topology.KadHistory
 	mtx             sync.Mutex
 	health          map[string]bool
 }
@@ -66,6 +67,12 @@ func WithMarshalJSONFunc(f func() ([]byte, error)) Option {
 	})
 }
 
+func WithHistoryFunc(f func(topology.HistoryFilter) *topology.KadHistory) Option {
+	return optionFunc(func(d *mock) {
+		d.historyFunc = f
+	})
+}
+
 func WithIsWithinFunc(f func(swarm.Address) bool) Option {
 	return optionFunc(func(d *mock) {
 		d.isWithinFunc = f
@@ -181,7 +188,7 @@ func (m *mock) IsReachable() bool {
 }
 
 func (d *mock) SubscribeTopologyChange() (c <-chan struct{}, unsubscribe func()) {
-	return c, unsubscribe
+	return c, func() {}
 }
 
 func (m *mock) NeighborhoodDepth() uint8 {
@@ -228,6 +235,16 @@ func (d *mock) Snapshot() *topology.KadParams {
 	return new(topology.KadParams)
 }
 
+func (d *mock) History(f topology.HistoryFilter) *topology.KadHistory {
+	if d.historyFunc != nil {
+		return d.historyFunc(f)
+	}
+	return &topology.KadHistory{
+		Events:    []topology.Event{},
+		Snapshots: []topology.HistorySnapshot{},
+	}
+}
+
 func (d *mock) Halt()        {}
 func (d *mock) Close() error { return nil }
 
@@ -33,6 +33,7 @@ type Driver interface {
 	IsReachable() bool
 	SetStorageRadiuser
 	UpdatePeerHealth(addr swarm.Address, h bool, t time.Duration)
+	History(HistoryFilter) *KadHistory
 }
 
 type PeerAdder interface {
@@ -141,6 +142,61 @@ type KadParams struct {
 	LightNodes          BinInfo   `json:"lightNodes"`          // light nodes bin info
 }
 
+// EventType enumerates the recorded topology events.
+type EventType string
+
+const (
+	EventConnect          EventType = "connect"
+	EventDisconnect       EventType = "disconnect"
+	EventBlocklist        EventType = "blocklist"
+	EventDepth            EventType = "depth"
+	EventStorageRadius    EventType = "storageRadius"
+	EventBinSaturated     EventType = "binSaturated"
+	EventBinUnsaturated   EventType = "binUnsaturated"
+	EventReachability     EventType = "reachability"
+	EventPeerReachability EventType = "peerReachability"
+)
+
+// Event is a recorded change of the topology. Previous and Current hold
+// the old and the new value of the depth, the storage radius or the
+// reachability.
+type Event struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Type      EventType      `json:"type"`
+	Peer      *swarm.Address `json:"peer,omitempty"`
+	Bin       *uint8         `json:"bin,omitempty"`
+	Direction string         `json:"direction,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+	Previous  string         `json:"previous,omitempty"`
+	Current   string         `json:"current,omitempty"`
+}
+
+// HistorySnapshot is a periodically recorded summary of the topology.
+type HistorySnapshot struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Depth         uint8     `json:"depth"`
+	StorageRadius uint8     `json:"storageRadius"`
+	Population    int       `json:"population"`
+	Connected     int       `json:"connected"`
+	Reachability  string    `json:"reachability"`
+	BinPopulation []uint    `json:"binPopulation"`
+	BinConnected  []uint    `json:"binConnected"`
+}
+
+// HistoryFilter selects the recorded events and snapshots. The zero values
+// of the fields do not filter. The snapshots are filtered only by time.
+type HistoryFilter struct {
+	From time.Time
+	To   time.Time
+	Peer swarm.Address
+}
+
+// KadHistory is the recorded history of the topology, oldest first.
+type KadHistory struct {
+	Events    []Event           `json:"events"`
+	Snapshots []HistorySnapshot `json:"snapshots"`
+}
+
 type Halter interface {
 	// Halt the topology from initiating new connections
 	// while allowing it to still run.
@@ -30,6 +30,7 @@ type backendMock struct {
 	headerByNumber     func(ctx context.Context, number *big.Int) (*types.Header, error)
 	balanceAt          func(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error)
 	nonceAt            func(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	filterLogs         func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
 }
 
 func (m *backendMock) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
@@ -78,7 +79,10 @@ func (m *backendMock) SendTransaction(ctx context.Context, tx *types.Transaction
 	return errors.New("not implemented")
 }
 
-func (*backendMock) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+func (m *backendMock) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if m.filterLogs != nil {
+		return m.filterLogs(ctx, query)
+	}
 	return nil, errors.New("not implemented")
 }
 
@@ -248,3 +252,9 @@ func WithNonceAtFunc(f func(ctx context.Context, account common.Address, blockNu
 		s.nonceAt = f
 	})
 }
+
+func WithFilterLogsFunc(f func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)) Option {
+	return optionFunc(func(s *backendMock) {
+		s.filterLogs = f
+	})
+}
@@ -4,6 +4,22 @@
 
 package transaction
 
+import "context"
+
 var (
 	StoredTransactionKey = storedTransactionKey
 )
+
+type TxQueue = txQueue
+
+func (q *txQueue) Acquire(ctx context.Context, priority Priority, description string) error {
+	return q.acquire(ctx, priority, description)
+}
+
+func (q *txQueue) Release() { q.release() }
+
+func (q *txQueue) View() []QueuedTransaction { return q.view() }
+
+func CheckPending(ctx context.Context, s Service) error {
+	return s.(*transactionService).checkPending(ctx)
+}
@@ -0,0 +1,523 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package failover provides a chain backend that spreads the calls over
+// several RPC endpoints. It health checks the endpoints, fails over to the
+// next healthy endpoint when one of them stops responding and optionally
+// cross-checks the block number, the logs and the receipts across several
+// endpoints, so that a single faulty provider can neither stall the node
+// nor feed it with a diverging view of the chain.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "failover"
+
+const (
+	defaultHealthInterval = 15 * time.Second
+	defaultCheckTimeout   = 5 * time.Second
+	defaultMaxBlockLag    = 5
+
+	// errAlreadyKnown and errNonceTooLow are the messages of the transaction
+	// pool errors returned by the endpoints.
+	errAlreadyKnown = "already known"
+	errNonceTooLow  = "nonce too low"
+)
+
+var (
+	_ transaction.Backend = (*Backend)(nil)
+
+	// ErrNoEndpoints is returned when the backend is created without endpoints.
+	ErrNoEndpoints = errors.New("no rpc endpoints")
+	// ErrNoQuorum is returned when the cross-checked endpoints disagree and
+	// no result is backed by the majority of them.
+	ErrNoQuorum = errors.New("rpc endpoints disagree")
+)
+
+// Endpoint is a chain backend connected to a single RPC endpoint.
+type Endpoint struct {
+	// Name identifies the endpoint in the logs and the metrics,
+	// so it must not contain any credentials.
+	Name    string
+	Backend transaction.Backend
+}
+
+// Options configures the failover backend.
+type Options struct {
+	// HealthInterval is the period of the health checks.
+	HealthInterval time.Duration
+	// CheckTimeout bounds the duration of a single health check.
+	CheckTimeout time.Duration
+	// MaxBlockLag is the number of blocks an endpoint can be behind the
+	// most advanced one before it is considered unhealthy.
+	MaxBlockLag uint64
+	// Quorum is the number of endpoints queried for the cross-checked calls.
+	// The cross-checks are disabled if it is lower than two.
+	Quorum int
+}
+
+type endpoint struct {
+	name        string
+	backend     transaction.Backend
+	healthy     atomic.Bool
+	blockNumber atomic.Uint64
+}
+
+// Backend is a chain backend over several RPC endpoints. The calls go to
+// the first healthy endpoint in the configured order and fail over to the
+// next one on the transport errors.
+type Backend struct {
+	endpoints []*endpoint
+	logger    log.Logger
+	opts      Options
+	metrics   metrics
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates the failover backend and starts the health checks of the
+// endpoints. The endpoints are considered healthy until the first check.
+func New(endpoints []Endpoint, logger log.Logger, o Options) (*Backend, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if o.HealthInterval <= 0 {
+		o.HealthInterval = defaultHealthInterval
+	}
+	if o.CheckTimeout <= 0 {
+		o.CheckTimeout = defaultCheckTimeout
+	}
+	if o.MaxBlockLag == 0 {
+		o.MaxBlockLag = defaultMaxBlockLag
+	}
+
+	b := &Backend{
+		logger:  logger.WithName(loggerName).Register(),
+		opts:    o,
+		metrics: newMetrics(),
+		quit:    make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		ep := &endpoint{name: e.Name, backend: e.Backend}
+		ep.healthy.Store(true)
+		b.metrics.EndpointHealthy.WithLabelValues(e.Name).Set(1)
+		b.endpoints = append(b.endpoints, ep)
+	}
+
+	b.wg.Add(1)
+	go b.manage()
+
+	return b, nil
+}
+
+func (b *Backend) manage() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.opts.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.quit:
+			return
+		case <-ticker.C:
+			b.check()
+		}
+	}
+}
+
+// check measures the block numbers of the endpoints and marks the endpoints
+// that fail or lag behind as unhealthy.
+func (b *Backend) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.CheckTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-b.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	numbers := make([]uint64, len(b.endpoints))
+	errs := make([]error, len(b.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range b.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			numbers[i], errs[i] = e.backend.BlockNumber(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range b.endpoints {
+		if errs[i] == nil {
+			best = max(best, numbers[i])
+		}
+	}
+
+	for i, e := range b.endpoints {
+		switch {
+		case errs[i] != nil:
+			b.fail(e, errs[i])
+		case best-numbers[i] > b.opts.MaxBlockLag:
+			e.blockNumber.Store(numbers[i])
+			b.metrics.EndpointBlockNumber.WithLabelValues(e.name).Set(float64(numbers[i]))
+			b.fail(e, fmt.Errorf("block number %d lags behind %d", numbers[i], best))
+		default:
+			e.blockNumber.Store(numbers[i])
+			b.metrics.EndpointBlockNumber.WithLabelValues(e.name).Set(float64(numbers[i]))
+			if !e.healthy.Swap(true) {
+				b.logger.Info("rpc endpoint recovered", "endpoint", e.name, "block_number", numbers[i])
+				b.metrics.EndpointHealthy.WithLabelValues(e.name).Set(1)
+			}
+		}
+	}
+}
+
+// fail marks the endpoint as unhealthy until the next successful check.
+func (b *Backend) fail(e *endpoint, err error) {
+	b.metrics.EndpointErrors.WithLabelValues(e.name).Inc()
+	if e.healthy.Swap(false) {
+		b.logger.Warning("rpc endpoint unhealthy", "endpoint", e.name, "error", err)
+		b.metrics.EndpointHealthy.WithLabelValues(e.name).Set(0)
+	}
+}
+
+// ordered returns the healthy endpoints in the configured order followed by
+// the unhealthy ones, which are still tried as the last resort.
+func (b *Backend) ordered() []*endpoint {
+	endpoints := make([]*endpoint, 0, len(b.endpoints))
+	for _, e := range b.endpoints {
+		if e.healthy.Load() {
+			endpoints = append(endpoints, e)
+		}
+	}
+	for _, e := range b.endpoints {
+		if !e.healthy.Load() {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// isEndpointFailure reports whether the error is caused by the endpoint
+// rather than being a valid response of the chain, so that the call is
+// worth retrying with another endpoint.
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// call calls the endpoints in order until one of them responds.
+func call[T any](ctx context.Context, b *Backend, f func(transaction.Backend) (T, error)) (v T, err error) {
+	for i, e := range b.ordered() {
+		if i > 0 {
+			b.metrics.Failovers.Inc()
+		}
+		v, err = f(e.backend)
+		if !isEndpointFailure(ctx, err) {
+			return v, err
+		}
+		b.fail(e, err)
+	}
+	return v, err
+}
+
+type response[T any] struct {
+	value T
+	err   error
+	key   string
+}
+
+// query calls the first endpoints up to the quorum concurrently and returns
+// the responses of the endpoints that did not fail.
+func query[T any](ctx context.Context, b *Backend, f func(transaction.Backend) (T, error), key func(T, error) string) []response[T] {
+	endpoints := b.ordered()
+	endpoints = endpoints[:min(len(endpoints), b.opts.Quorum)]
+
+	responses := make([]*response[T], len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			v, err := f(e.backend)
+			if isEndpointFailure(ctx, err) {
+				b.fail(e, err)
+				return
+			}
+			responses[i] = &response[T]{value: v, err: err, key: key(v, err)}
+		}(i, e)
+	}
+	wg.Wait()
+
+	var result []response[T]
+	for _, r := range responses {
+		if r != nil {
+			result = append(result, *r)
+		}
+	}
+	return result
+}
+
+// crossCheck returns the response backed by the majority of the queried
+// endpoints and reports the divergences. The responses for which abstain
+// is true, if not nil, do not take part in the vote unless all the
+// endpoints respond so.
+func crossCheck[T any](ctx context.Context, b *Backend, method string, f func(transaction.Backend) (T, error), key func(T, error) string, abstain func(T, error) bool) (T, error) {
+	if b.opts.Quorum < 2 || len(b.endpoints) < 2 {
+		return call(ctx, b, f)
+	}
+
+	responses := query(ctx, b, f, key)
+	if len(responses) == 0 {
+		return call(ctx, b, f)
+	}
+	if abstain != nil {
+		var voting []response[T]
+		for _, r := range responses {
+			if !abstain(r.value, r.err) {
+				voting = append(voting, r)
+			}
+		}
+		if len(voting) == 0 {
+			return responses[0].value, responses[0].err
+		}
+		responses = voting
+	}
+
+	counts := make(map[string]int)
+	best := responses[0]
+	for _, r := range responses {
+		counts[r.key]++
+		if counts[r.key] > counts[best.key] {
+			best = r
+		}
+	}
+	if len(counts) > 1 {
+		b.metrics.Divergences.WithLabelValues(method).Inc()
+		b.logger.Warning("rpc endpoints diverge", "method", method, "responses", len(responses), "agreeing", counts[best.key])
+	}
+	if 2*counts[best.key] <= len(responses) {
+		b.metrics.QuorumFailures.WithLabelValues(method).Inc()
+		var zero T
+		return zero, fmt.Errorf("%s: %w", method, ErrNoQuorum)
+	}
+	return best.value, best.err
+}
+
+func errorKey(err error) string {
+	if errors.Is(err, ethereum.NotFound) {
+		return "not found"
+	}
+	return "error: " + err.Error()
+}
+
+func logsKey(logs []types.Log, err error) string {
+	if err != nil {
+		return errorKey(err)
+	}
+	var sb strings.Builder
+	for _, l := range logs {
+		fmt.Fprintf(&sb, "%s:%s:%d;", l.BlockHash, l.TxHash, l.Index)
+	}
+	return sb.String()
+}
+
+func receiptKey(r *types.Receipt, err error) string {
+	if err != nil {
+		return errorKey(err)
+	}
+	if r == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%d:%s:%s", r.Status, r.BlockHash, r.BlockNumber)
+}
+
+// BlockNumber returns the median of the block numbers of the cross-checked
+// endpoints, so that neither a lagging nor a racing endpoint determines it.
+func (b *Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	f := func(backend transaction.Backend) (uint64, error) { return backend.BlockNumber(ctx) }
+	if b.opts.Quorum < 2 || len(b.endpoints) < 2 {
+		return call(ctx, b, f)
+	}
+
+	responses := query(ctx, b, f, func(uint64, error) string { return "" })
+	var numbers []uint64
+	for _, r := range responses {
+		if r.err == nil {
+			numbers = append(numbers, r.value)
+		}
+	}
+	if len(numbers) == 0 {
+		return call(ctx, b, f)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	if numbers[len(numbers)-1]-numbers[0] > b.opts.MaxBlockLag {
+		b.metrics.Divergences.WithLabelValues("BlockNumber").Inc()
+		b.logger.Warning("rpc endpoints diverge", "method", "BlockNumber", "lowest", numbers[0], "highest", numbers[len(numbers)-1])
+	}
+	return numbers[(len(numbers)-1)/2], nil
+}
+
+func (b *Backend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return crossCheck(ctx, b, "FilterLogs", func(backend transaction.Backend) ([]types.Log, error) {
+		return backend.FilterLogs(ctx, q)
+	}, logsKey, nil)
+}
+
+// TransactionReceipt cross-checks the receipt. The endpoints that do not
+// know the receipt yet, e.g. because they lag behind, do not count as
+// disagreeing with the ones that do.
+func (b *Backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return crossCheck(ctx, b, "TransactionReceipt", func(backend transaction.Backend) (*types.Receipt, error) {
+		return backend.TransactionReceipt(ctx, txHash)
+	}, receiptKey, func(_ *types.Receipt, err error) bool {
+		return errors.Is(err, ethereum.NotFound)
+	})
+}
+
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, b, func(backend transaction.Backend) ([]byte, error) {
+		return backend.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (b *Backend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, b, func(backend transaction.Backend) ([]byte, error) {
+		return backend.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, b, func(backend transaction.Backend) (*types.Header, error) {
+		return backend.HeaderByNumber(ctx, number)
+	})
+}
+
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, b, func(backend transaction.Backend) (uint64, error) {
+		return backend.PendingNonceAt(ctx, account)
+	})
+}
+
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, b, func(backend transaction.Backend) (*big.Int, error) {
+		return backend.SuggestGasPrice(ctx)
+	})
+}
+
+func (b *Backend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, b, func(backend transaction.Backend) (*big.Int, error) {
+		return backend.SuggestGasTipCap(ctx)
+	})
+}
+
+func (b *Backend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, b, func(backend transaction.Backend) (uint64, error) {
+		return backend.EstimateGas(ctx, msg)
+	})
+}
+
+// SendTransaction sends the transaction to the endpoints in order until one
+// of them accepts it. An endpoint that failed may have broadcast the
+// transaction anyway, so the next endpoints rejecting it as already known,
+// or as having a too low nonce while knowing the transaction, count as
+// accepting it.
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	for i, e := range b.ordered() {
+		if i > 0 {
+			b.metrics.Failovers.Inc()
+		}
+		err = e.backend.SendTransaction(ctx, tx)
+		if i > 0 && resent(ctx, e.backend, tx, err) {
+			return nil
+		}
+		if !isEndpointFailure(ctx, err) {
+			return err
+		}
+		b.fail(e, err)
+	}
+	return err
+}
+
+// resent reports whether the error of the repeated send of the transaction
+// shows that the transaction has been sent before.
+func resent(ctx context.Context, backend transaction.Backend, tx *types.Transaction, err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, errAlreadyKnown):
+		return true
+	case strings.Contains(msg, errNonceTooLow):
+		_, _, err := backend.TransactionByHash(ctx, tx.Hash())
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func (b *Backend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	var isPending bool
+	tx, err := call(ctx, b, func(backend transaction.Backend) (tx *types.Transaction, err error) {
+		tx, isPending, err = backend.TransactionByHash(ctx, hash)
+		return tx, err
+	})
+	return tx, isPending, err
+}
+
+func (b *Backend) BalanceAt(ctx context.Context, address common.Address, block *big.Int) (*big.Int, error) {
+	return call(ctx, b, func(backend transaction.Backend) (*big.Int, error) {
+		return backend.BalanceAt(ctx, address, block)
+	})
+}
+
+func (b *Backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, b, func(backend transaction.Backend) (uint64, error) {
+		return backend.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, b, func(backend transaction.Backend) (*big.Int, error) {
+		return backend.ChainID(ctx)
+	})
+}
+
+// Close stops the health checks and closes the endpoints.
+func (b *Backend) Close() {
+	close(b.quit)
+	b.wg.Wait()
+	for _, e := range b.endpoints {
+		e.backend.Close()
+	}
+}
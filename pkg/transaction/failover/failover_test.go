@@ -0,0 +1,316 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package failover_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/transaction/failover"
+)
+
+var errUnavailable = errors.New("connection refused")
+
+type rpcError struct{}
+
+func (rpcError) Error() string  { return "execution reverted" }
+func (rpcError) ErrorCode() int { return 3 }
+
+var _ rpc.Error = rpcError{}
+
+func newBackend(t *testing.T, quorum int, endpoints ...failover.Endpoint) *failover.Backend {
+	t.Helper()
+
+	b, err := failover.New(endpoints, log.Noop, failover.Options{
+		HealthInterval: time.Hour,
+		Quorum:         quorum,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+func blockNumberEndpoint(name string, n uint64, err error, calls *atomic.Int32) failover.Endpoint {
+	return failover.Endpoint{
+		Name: name,
+		Backend: backendmock.New(backendmock.WithBlockNumberFunc(func(context.Context) (uint64, error) {
+			if calls != nil {
+				calls.Add(1)
+			}
+			return n, err
+		})),
+	}
+}
+
+func TestNoEndpoints(t *testing.T) {
+	t.Parallel()
+
+	_, err := failover.New(nil, log.Noop, failover.Options{})
+	if !errors.Is(err, failover.ErrNoEndpoints) {
+		t.Fatalf("got error %v, want %v", err, failover.ErrNoEndpoints)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("endpoint failure", func(t *testing.T) {
+		t.Parallel()
+
+		var first, second atomic.Int32
+		b := newBackend(t, 0,
+			blockNumberEndpoint("a", 0, errUnavailable, &first),
+			blockNumberEndpoint("b", 10, nil, &second),
+		)
+
+		for i := 0; i < 2; i++ {
+			n, err := b.BlockNumber(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 10 {
+				t.Fatalf("got block number %d, want 10", n)
+			}
+		}
+		// the failed endpoint is moved behind the healthy one
+		if got := first.Load(); got != 1 {
+			t.Fatalf("got %d calls of the failed endpoint, want 1", got)
+		}
+		if got := second.Load(); got != 2 {
+			t.Fatalf("got %d calls of the healthy endpoint, want 2", got)
+		}
+	})
+
+	t.Run("chain error", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		estimate := func(err error) failover.Endpoint {
+			return failover.Endpoint{
+				Name: "e",
+				Backend: backendmock.New(backendmock.WithEstimateGasFunc(func(context.Context, ethereum.CallMsg) (uint64, error) {
+					calls.Add(1)
+					return 0, err
+				})),
+			}
+		}
+		b := newBackend(t, 0, estimate(rpcError{}), estimate(nil))
+
+		_, err := b.EstimateGas(context.Background(), ethereum.CallMsg{})
+		if !errors.As(err, new(rpc.Error)) {
+			t.Fatalf("got error %v, want rpc error", err)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Fatalf("got %d calls, want 1", got)
+		}
+	})
+
+	t.Run("all endpoints fail", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackend(t, 0,
+			blockNumberEndpoint("a", 0, errUnavailable, nil),
+			blockNumberEndpoint("b", 0, errUnavailable, nil),
+		)
+
+		if _, err := b.BlockNumber(context.Background()); !errors.Is(err, errUnavailable) {
+			t.Fatalf("got error %v, want %v", err, errUnavailable)
+		}
+	})
+}
+
+func TestHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	var lagging, current atomic.Int32
+	b := newBackend(t, 0,
+		blockNumberEndpoint("lagging", 100, nil, &lagging),
+		blockNumberEndpoint("current", 200, nil, &current),
+	)
+
+	b.Check()
+	lagging.Store(0)
+	current.Store(0)
+
+	n, err := b.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 200 {
+		t.Fatalf("got block number %d, want 200", n)
+	}
+	if got := lagging.Load(); got != 0 {
+		t.Fatalf("got %d calls of the lagging endpoint, want 0", got)
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	t.Parallel()
+
+	receipt := func(status uint64) failover.Endpoint {
+		return failover.Endpoint{
+			Name: "e",
+			Backend: backendmock.New(backendmock.WithTransactionReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return &types.Receipt{Status: status, BlockNumber: big.NewInt(1)}, nil
+			})),
+		}
+	}
+
+	t.Run("majority", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackend(t, 3, receipt(0), receipt(1), receipt(1))
+
+		r, err := b.TransactionReceipt(context.Background(), common.Hash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Status != 1 {
+			t.Fatalf("got status %d, want 1", r.Status)
+		}
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackend(t, 2, receipt(0), receipt(1), receipt(1))
+
+		if _, err := b.TransactionReceipt(context.Background(), common.Hash{}); !errors.Is(err, failover.ErrNoQuorum) {
+			t.Fatalf("got error %v, want %v", err, failover.ErrNoQuorum)
+		}
+	})
+
+	t.Run("receipt not found yet", func(t *testing.T) {
+		t.Parallel()
+
+		notFound := failover.Endpoint{
+			Name: "e",
+			Backend: backendmock.New(backendmock.WithTransactionReceiptFunc(func(context.Context, common.Hash) (*types.Receipt, error) {
+				return nil, ethereum.NotFound
+			})),
+		}
+
+		b := newBackend(t, 2, notFound, receipt(1))
+		r, err := b.TransactionReceipt(context.Background(), common.Hash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Status != 1 {
+			t.Fatalf("got status %d, want 1", r.Status)
+		}
+
+		b = newBackend(t, 2, notFound, notFound)
+		if _, err := b.TransactionReceipt(context.Background(), common.Hash{}); !errors.Is(err, ethereum.NotFound) {
+			t.Fatalf("got error %v, want %v", err, ethereum.NotFound)
+		}
+	})
+
+	t.Run("failed endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		logs := func(logs []types.Log, err error) failover.Endpoint {
+			return failover.Endpoint{
+				Name: "e",
+				Backend: backendmock.New(backendmock.WithFilterLogsFunc(func(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+					return logs, err
+				})),
+			}
+		}
+		want := []types.Log{{Index: 1}, {Index: 2}}
+		b := newBackend(t, 2, logs(nil, errUnavailable), logs(want, nil))
+
+		got, err := b.FilterLogs(context.Background(), ethereum.FilterQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d logs, want %d", len(got), len(want))
+		}
+	})
+}
+
+func TestSendTransaction(t *testing.T) {
+	t.Parallel()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	send := func(err error, known bool) failover.Endpoint {
+		return failover.Endpoint{
+			Name: "e",
+			Backend: backendmock.New(
+				backendmock.WithSendTransactionFunc(func(context.Context, *types.Transaction) error {
+					return err
+				}),
+				backendmock.WithTransactionByHashFunc(func(context.Context, common.Hash) (*types.Transaction, bool, error) {
+					if !known {
+						return nil, false, ethereum.NotFound
+					}
+					return tx, true, nil
+				}),
+			),
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		second  failover.Endpoint
+		wantErr bool
+	}{
+		{name: "accepted", second: send(nil, false)},
+		{name: "already known", second: send(errors.New("already known"), false)},
+		{name: "nonce too low of known transaction", second: send(errors.New("nonce too low: next nonce 2, tx nonce 1"), true)},
+		{name: "nonce too low of unknown transaction", second: send(errors.New("nonce too low: next nonce 2, tx nonce 1"), false), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := newBackend(t, 0, send(errUnavailable, false), tc.second)
+
+			err := b.SendTransaction(context.Background(), tx)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("first endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackend(t, 0, send(errors.New("already known"), true))
+
+		if err := b.SendTransaction(context.Background(), tx); err == nil {
+			t.Fatal("want error of the first send")
+		}
+	})
+}
+
+func TestBlockNumberMedian(t *testing.T) {
+	t.Parallel()
+
+	b := newBackend(t, 3,
+		blockNumberEndpoint("a", 100, nil, nil),
+		blockNumberEndpoint("b", 1000, nil, nil),
+		blockNumberEndpoint("c", 101, nil, nil),
+	)
+
+	n, err := b.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 101 {
+		t.Fatalf("got block number %d, want 101", n)
+	}
+}
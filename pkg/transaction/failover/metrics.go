@@ -0,0 +1,66 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package failover
+
+import (
+	m "github.com/ethersphere/bee/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	EndpointHealthy     *prometheus.GaugeVec
+	EndpointBlockNumber *prometheus.GaugeVec
+	EndpointErrors      *prometheus.CounterVec
+	Failovers           prometheus.Counter
+	Divergences         *prometheus.CounterVec
+	QuorumFailures      *prometheus.CounterVec
+}
+
+func newMetrics() metrics {
+	subsystem := "eth_backend_failover"
+
+	return metrics{
+		EndpointHealthy: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "endpoint_healthy",
+			Help:      "Whether the rpc endpoint is healthy.",
+		}, []string{"endpoint"}),
+		EndpointBlockNumber: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "endpoint_block_number",
+			Help:      "Block number reported by the rpc endpoint in the last health check.",
+		}, []string{"endpoint"}),
+		EndpointErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "endpoint_errors",
+			Help:      "Number of failed calls and health checks of the rpc endpoint.",
+		}, []string{"endpoint"}),
+		Failovers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failovers",
+			Help:      "Number of calls retried with another rpc endpoint.",
+		}),
+		Divergences: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "divergences",
+			Help:      "Number of cross-checked calls with diverging responses of the rpc endpoints.",
+		}, []string{"method"}),
+		QuorumFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "quorum_failures",
+			Help:      "Number of cross-checked calls without a response backed by the majority.",
+		}, []string{"method"}),
+	}
+}
+
+func (b *Backend) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(b.metrics)
+}
@@ -23,6 +23,7 @@ type transactionServiceMock struct {
 	watchSentTransaction func(txHash common.Hash) (chan types.Receipt, chan error, error)
 	call                 func(ctx context.Context, request *transaction.TxRequest) (result []byte, err error)
 	pendingTransactions  func() ([]common.Hash, error)
+	queuedTransactions   func() []transaction.QueuedTransaction
 	resendTransaction    func(ctx context.Context, txHash common.Hash) error
 	storedTransaction    func(txHash common.Hash) (*transaction.StoredTransaction, error)
 	cancelTransaction    func(ctx context.Context, originalTxHash common.Hash) (common.Hash, error)
@@ -64,6 +65,13 @@ func (m *transactionServiceMock) PendingTransactions() ([]common.Hash, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *transactionServiceMock) QueuedTransactions() []transaction.QueuedTransaction {
+	if m.queuedTransactions != nil {
+		return m.queuedTransactions()
+	}
+	return nil
+}
+
 func (m *transactionServiceMock) ResendTransaction(ctx context.Context, txHash common.Hash) error {
 	if m.resendTransaction != nil {
 		return m.resendTransaction(ctx, txHash)
@@ -140,6 +148,12 @@ func WithPendingTransactionsFunc(f func() ([]common.Hash, error)) Option {
 	})
 }
 
+func WithQueuedTransactionsFunc(f func() []transaction.QueuedTransaction) Option {
+	return optionFunc(func(s *transactionServiceMock) {
+		s.queuedTransactions = f
+	})
+}
+
 func WithResendTransactionFunc(f func(ctx context.Context, txHash common.Hash) error) Option {
 	return optionFunc(func(s *transactionServiceMock) {
 		s.resendTransaction = f
@@ -0,0 +1,159 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Priority is the priority class of a transaction. The transactions of the
+// higher classes are sent first when several of them wait for their nonce
+// and they are replaced with higher fees sooner when they get stuck.
+type Priority int
+
+const (
+	// PriorityChequebook is the lowest class, used by the chequebook and by
+	// the transactions that do not set a class.
+	PriorityChequebook Priority = iota
+	PriorityPostage
+	PriorityStake
+	PriorityRedistribution
+)
+
+var priorityNames = map[Priority]string{
+	PriorityChequebook:     "chequebook",
+	PriorityPostage:        "postage",
+	PriorityStake:          "stake",
+	PriorityRedistribution: "redistribution",
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParsePriority parses the name of a priority class.
+func ParsePriority(s string) (Priority, error) {
+	for p, name := range priorityNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid transaction priority %q", s)
+}
+
+// QueuedTransaction is a transaction request waiting for its turn to be sent.
+type QueuedTransaction struct {
+	Priority    Priority
+	Description string
+	Queued      time.Time
+}
+
+type queuedRequest struct {
+	QueuedTransaction
+	seq   uint64
+	ready chan struct{}
+}
+
+// txQueue serializes the sending of the transactions. Unlike a mutex it
+// hands the turn to the waiting request of the highest priority class and
+// to the oldest one within the class.
+type txQueue struct {
+	mu      sync.Mutex
+	busy    bool
+	seq     uint64
+	waiting []*queuedRequest
+}
+
+// acquire blocks until it is the turn of the request or the context is done.
+// A successful acquire must be followed by release.
+func (q *txQueue) acquire(ctx context.Context, priority Priority, description string) error {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return nil
+	}
+	q.seq++
+	r := &queuedRequest{
+		QueuedTransaction: QueuedTransaction{
+			Priority:    priority,
+			Description: description,
+			Queued:      time.Now(),
+		},
+		seq:   q.seq,
+		ready: make(chan struct{}),
+	}
+	q.waiting = append(q.waiting, r)
+	q.mu.Unlock()
+
+	select {
+	case <-r.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	for i, w := range q.waiting {
+		if w == r {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	q.mu.Unlock()
+
+	// the turn was handed over concurrently with the cancellation
+	q.release()
+	return ctx.Err()
+}
+
+// release hands the turn to the next waiting request.
+func (q *txQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) == 0 {
+		q.busy = false
+		return
+	}
+
+	next := 0
+	for i, w := range q.waiting {
+		if w.Priority > q.waiting[next].Priority {
+			next = i
+		}
+	}
+	r := q.waiting[next]
+	q.waiting = append(q.waiting[:next], q.waiting[next+1:]...)
+	close(r.ready)
+}
+
+// view returns the waiting requests in the order in which they are sent.
+func (q *txQueue) view() []QueuedTransaction {
+	q.mu.Lock()
+	waiting := make([]*queuedRequest, len(q.waiting))
+	copy(waiting, q.waiting)
+	q.mu.Unlock()
+
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].Priority != waiting[j].Priority {
+			return waiting[i].Priority > waiting[j].Priority
+		}
+		return waiting[i].seq < waiting[j].seq
+	})
+
+	queued := make([]QueuedTransaction, 0, len(waiting))
+	for _, r := range waiting {
+		queued = append(queued, r.QueuedTransaction)
+	}
+	return queued
+}
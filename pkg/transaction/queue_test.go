@@ -0,0 +1,105 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+func waitQueued(t *testing.T, q *transaction.TxQueue, n int) []transaction.QueuedTransaction {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if queued := q.View(); len(queued) == n {
+			return queued
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("requests not queued, want %d", n)
+	return nil
+}
+
+func TestQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	q := new(transaction.TxQueue)
+	if err := q.Acquire(context.Background(), transaction.PriorityChequebook, "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 3)
+	for _, r := range []struct {
+		priority    transaction.Priority
+		description string
+	}{
+		{transaction.PriorityChequebook, "cashout"},
+		{transaction.PriorityPostage, "topup"},
+		{transaction.PriorityRedistribution, "commit"},
+	} {
+		go func() {
+			if err := q.Acquire(context.Background(), r.priority, r.description); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- r.description
+			q.Release()
+		}()
+		// queue the requests in a deterministic order
+		waitQueued(t, q, len(q.View())+1)
+	}
+
+	queued := q.View()
+	want := []string{"commit", "topup", "cashout"}
+	for i, d := range want {
+		if queued[i].Description != d {
+			t.Fatalf("got queued request %q at %d, want %q", queued[i].Description, i, d)
+		}
+	}
+
+	q.Release()
+	for _, d := range want {
+		if got := <-order; got != d {
+			t.Fatalf("got request %q, want %q", got, d)
+		}
+	}
+	if queued := q.View(); len(queued) != 0 {
+		t.Fatalf("got %d queued requests, want none", len(queued))
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	t.Parallel()
+
+	q := new(transaction.TxQueue)
+	if err := q.Acquire(context.Background(), transaction.PriorityChequebook, "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- q.Acquire(ctx, transaction.PriorityStake, "stake")
+	}()
+	waitQueued(t, q, 1)
+
+	cancel()
+	if err := <-errC; !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if queued := q.View(); len(queued) != 0 {
+		t.Fatalf("got %d queued requests, want none", len(queued))
+	}
+
+	q.Release()
+	if err := q.Acquire(context.Background(), transaction.PriorityChequebook, "next"); err != nil {
+		t.Fatal(err)
+	}
+	q.Release()
+}
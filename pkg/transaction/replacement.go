@@ -0,0 +1,506 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
+)
+
+const (
+	replacedTransactionPrefix = "transaction_replaced_"
+
+	// minBumpPercent is the minimal fee increase of a replacement accepted
+	// by the transaction pools of the nodes.
+	minBumpPercent = 10
+	// fillerGasLimit is the gas limit of the transfers filling the nonce gaps.
+	fillerGasLimit = 21000
+)
+
+var (
+	// ErrMissingMaxGasFeeCap is returned when the fee bumps or the nonce gap
+	// fillers are enabled without a bound of their fees.
+	ErrMissingMaxGasFeeCap = errors.New("maximal gas fee cap is required for the fee bumps and the nonce gap fillers")
+
+	// errBumpCapped is returned when the maximal gas fee cap leaves no room
+	// for a replacement the transaction pools would accept.
+	errBumpCapped = errors.New("capped fees are below the minimal fee increase")
+)
+
+// Options configures the automatic replacement of the stuck transactions
+// and the repair of the nonce gaps.
+type Options struct {
+	// CheckInterval is the period of the checks of the pending transactions.
+	// Zero disables the checks.
+	CheckInterval time.Duration
+	// Bump enables the replacement of the stuck transactions with the same
+	// ones paying higher fees.
+	Bump bool
+	// BumpDeadlines are the durations by priority class after which a pending
+	// transaction is replaced with the same one paying higher fees.
+	BumpDeadlines map[Priority]time.Duration
+	// BumpPercent is the fee increase of a replacement.
+	BumpPercent int
+	// MaxBumps bounds the number of replacements of a transaction.
+	MaxBumps int
+	// FillNonceGaps enables the empty transfers using the nonces that block
+	// the following transactions and that no known transaction uses.
+	FillNonceGaps bool
+	// MaxGasFeeCap bounds the fee cap of the replacements and of the nonce
+	// gap fillers. It is required if either of them is enabled.
+	MaxGasFeeCap *big.Int
+	// AuditLog records the maximal fees of the replacements and of the
+	// nonce gap fillers, if set.
+	AuditLog wallet.Recorder
+}
+
+// DefaultOptions returns the options with the default replacement policies.
+// The fee bumps and the nonce gap fillers spend funds, so they are disabled
+// and only the transactions missing from the pools are broadcast again.
+func DefaultOptions() Options {
+	return Options{
+		CheckInterval: 30 * time.Second,
+		BumpDeadlines: map[Priority]time.Duration{
+			PriorityRedistribution: time.Minute,
+			PriorityStake:          3 * time.Minute,
+			PriorityPostage:        5 * time.Minute,
+			PriorityChequebook:     10 * time.Minute,
+		},
+		BumpPercent: DefaultTipBoostPercent,
+		MaxBumps:    5,
+	}
+}
+
+// Validate checks that the fees of the enabled replacements are bounded.
+func (o Options) Validate() error {
+	if (o.Bump || o.FillNonceGaps) && (o.MaxGasFeeCap == nil || o.MaxGasFeeCap.Sign() <= 0) {
+		return ErrMissingMaxGasFeeCap
+	}
+	return nil
+}
+
+// ParseBumpDeadline parses the bump deadline of a priority class in the
+// form class:duration, for example redistribution:1m.
+func ParseBumpDeadline(s string) (Priority, time.Duration, error) {
+	name, duration, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid bump deadline %q", s)
+	}
+	p, err := ParsePriority(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("invalid bump deadline duration %q", duration)
+	}
+	return p, d, nil
+}
+
+func replacedTransactionKey(txHash common.Hash) string {
+	return fmt.Sprintf("%s%x", replacedTransactionPrefix, txHash)
+}
+
+func (t *transactionService) manage() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.checkPending(t.ctx); err != nil {
+				t.logger.Debug("check pending transactions failed", "error", err)
+			}
+		}
+	}
+}
+
+// checkPending replaces the pending transactions that are stuck for longer
+// than the deadlines of their priority classes and repairs the gaps between
+// the used nonces and the pending nonce of the backend. The state of the
+// service is read under the lock, but the lock is not held during the calls
+// to the backend so that the sending of the transactions is not blocked.
+func (t *transactionService) checkPending(ctx context.Context) error {
+	nonce, pending, err := t.pendingState()
+	if err != nil {
+		return err
+	}
+
+	confirmedNonce, err := t.backend.NonceAt(ctx, t.sender, nil)
+	if err != nil {
+		return err
+	}
+	pendingNonce, err := t.backend.PendingNonceAt(ctx, t.sender)
+	if err != nil {
+		return err
+	}
+
+	byNonce := make(map[uint64]common.Hash)
+	capped := make(map[common.Hash]struct{})
+	now := time.Now()
+	for txHash, stored := range pending {
+		if stored.Nonce < confirmedNonce {
+			continue
+		}
+		byNonce[stored.Nonce] = txHash
+
+		if !t.opts.Bump {
+			continue
+		}
+		deadline, ok := t.opts.BumpDeadlines[stored.Priority]
+		if !ok || now.Sub(time.Unix(stored.Created, 0)) < deadline || stored.Bumps >= t.opts.MaxBumps {
+			continue
+		}
+		newHash, err := t.replace(ctx, txHash, stored)
+		if errors.Is(err, errBumpCapped) {
+			// the transaction is checked again on every tick, but the
+			// skipped bump is logged only once
+			capped[txHash] = struct{}{}
+			if _, ok := t.capped[txHash]; !ok {
+				t.logger.Warning("skipping bump of stuck transaction, the maximal gas fee cap is reached", "tx", txHash, "nonce", stored.Nonce, "gas_fee_cap", stored.GasFeeCap, "max_gas_fee_cap", t.opts.MaxGasFeeCap)
+			}
+			continue
+		}
+		if err != nil {
+			t.logger.Warning("replacing stuck transaction failed", "tx", txHash, "nonce", stored.Nonce, "error", err)
+			continue
+		}
+		byNonce[stored.Nonce] = newHash
+		t.logger.Info("replaced stuck transaction", "tx", txHash, "replacement", newHash, "nonce", stored.Nonce, "priority", stored.Priority)
+	}
+	t.capped = capped
+
+	// The nonces below the next nonce of the service that are not known to
+	// the backend belong to the transactions that vanished from the pools and
+	// block all the following ones. A gap is repaired only if it is observed
+	// in two consecutive checks, to give the fresh transactions time to
+	// propagate.
+	gaps := make(map[uint64]struct{})
+	for n := pendingNonce; n < nonce; n++ {
+		gaps[n] = struct{}{}
+		if _, ok := t.gaps[n]; !ok {
+			continue
+		}
+		switch txHash, ok := byNonce[n]; {
+		case ok:
+			err = t.rebroadcast(ctx, txHash)
+		case t.opts.FillNonceGaps:
+			err = t.fillNonce(ctx, n)
+		default:
+			t.logger.Warning("nonce gap without a known transaction", "nonce", n)
+			continue
+		}
+		if err != nil {
+			t.logger.Warning("repairing nonce gap failed", "nonce", n, "error", err)
+		}
+	}
+	t.gaps = gaps
+
+	return nil
+}
+
+// pendingState returns the next nonce of the service and the pending
+// transactions. It is read under the lock so that it is consistent with the
+// transactions being sent.
+func (t *transactionService) pendingState() (uint64, map[common.Hash]*StoredTransaction, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var nonce uint64
+	if err := t.store.Get(t.nonceKey(), &nonce); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, nil, err
+	}
+
+	txHashes, err := t.PendingTransactions()
+	if err != nil {
+		return 0, nil, err
+	}
+	pending := make(map[common.Hash]*StoredTransaction, len(txHashes))
+	for _, txHash := range txHashes {
+		stored, err := t.StoredTransaction(txHash)
+		if err != nil {
+			return 0, nil, err
+		}
+		pending[txHash] = stored
+	}
+	return nonce, pending, nil
+}
+
+// replace sends the stored transaction again with the fees increased by the
+// bump percentage or to the suggested fees if they are higher. If the fees
+// capped by the maximal gas fee cap are not at least minBumpPercent higher
+// than the fees of the transaction, the pools would reject the replacement,
+// so it is not sent and errBumpCapped is returned.
+func (t *transactionService) replace(ctx context.Context, txHash common.Hash, stored *StoredTransaction) (common.Hash, error) {
+	gasFeeCap, gasTipCap, err := t.suggestedFeeAndTip(ctx, nil, stored.GasTipBoost)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	bumpPercent := max(t.opts.BumpPercent, minBumpPercent)
+	gasFeeCap = bigMax(gasFeeCap, bump(stored.GasFeeCap, bumpPercent))
+	gasTipCap = bigMax(gasTipCap, bump(stored.GasTipCap, bumpPercent))
+	gasFeeCap, gasTipCap = t.capFees(gasFeeCap, gasTipCap)
+	if gasFeeCap.Cmp(bump(stored.GasFeeCap, minBumpPercent)) < 0 || gasTipCap.Cmp(bump(stored.GasTipCap, minBumpPercent)) < 0 {
+		return common.Hash{}, errBumpCapped
+	}
+
+	signedTx, err := t.signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		Nonce:     stored.Nonce,
+		ChainID:   t.chainID,
+		To:        stored.To,
+		Value:     stored.Value,
+		Gas:       stored.GasLimit,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Data:      stored.Data,
+	}), t.chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := t.backend.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, err
+	}
+
+	newHash := signedTx.Hash()
+	err = t.store.Put(storedTransactionKey(newHash), StoredTransaction{
+		To:          signedTx.To(),
+		Data:        signedTx.Data(),
+		GasPrice:    signedTx.GasPrice(),
+		GasLimit:    signedTx.Gas(),
+		GasTipBoost: stored.GasTipBoost,
+		GasTipCap:   signedTx.GasTipCap(),
+		GasFeeCap:   signedTx.GasFeeCap(),
+		Value:       signedTx.Value(),
+		Nonce:       signedTx.Nonce(),
+		Created:     time.Now().Unix(),
+		Description: stored.Description,
+		Priority:    stored.Priority,
+		Bumps:       stored.Bumps + 1,
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := t.store.Put(replacedTransactionKey(txHash), newHash); err != nil {
+		return common.Hash{}, err
+	}
+	if err := t.store.Put(pendingTransactionKey(newHash), struct{}{}); err != nil {
+		return common.Hash{}, err
+	}
+	if err := t.store.Delete(pendingTransactionKey(txHash)); err != nil {
+		return common.Hash{}, err
+	}
+
+	t.replacedMu.Lock()
+	close(t.replaced)
+	t.replaced = make(chan struct{})
+	t.replacedMu.Unlock()
+
+	t.recordFee(wallet.KindTransactionBump, signedTx, txHash.Hex())
+	return newHash, nil
+}
+
+// rebroadcast sends the stored transaction again unchanged.
+func (t *transactionService) rebroadcast(ctx context.Context, txHash common.Hash) error {
+	stored, err := t.StoredTransaction(txHash)
+	if err != nil {
+		return err
+	}
+
+	signedTx, err := t.signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		Nonce:     stored.Nonce,
+		ChainID:   t.chainID,
+		To:        stored.To,
+		Value:     stored.Value,
+		Gas:       stored.GasLimit,
+		GasTipCap: stored.GasTipCap,
+		GasFeeCap: stored.GasFeeCap,
+		Data:      stored.Data,
+	}), t.chainID)
+	if err != nil {
+		return err
+	}
+	if signedTx.Hash() != txHash {
+		return errors.New("transaction hash changed")
+	}
+
+	t.logger.Info("rebroadcasting transaction missing from the pool", "tx", txHash, "nonce", stored.Nonce)
+	return t.backend.SendTransaction(ctx, signedTx)
+}
+
+// fillNonce uses the nonce, which no known transaction uses, for an empty
+// transfer to the sender, so that the following transactions can be mined.
+func (t *transactionService) fillNonce(ctx context.Context, nonce uint64) error {
+	gasFeeCap, gasTipCap, err := t.suggestedFeeAndTip(ctx, nil, DefaultTipBoostPercent)
+	if err != nil {
+		return err
+	}
+	gasFeeCap, gasTipCap = t.capFees(gasFeeCap, gasTipCap)
+
+	signedTx, err := t.signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		Nonce:     nonce,
+		ChainID:   t.chainID,
+		To:        &t.sender,
+		Value:     big.NewInt(0),
+		Gas:       fillerGasLimit,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Data:      []byte{},
+	}), t.chainID)
+	if err != nil {
+		return err
+	}
+
+	if err := t.backend.SendTransaction(ctx, signedTx); err != nil {
+		return err
+	}
+
+	txHash := signedTx.Hash()
+	err = t.store.Put(storedTransactionKey(txHash), StoredTransaction{
+		To:          signedTx.To(),
+		Data:        signedTx.Data(),
+		GasPrice:    signedTx.GasPrice(),
+		GasLimit:    signedTx.Gas(),
+		GasTipBoost: DefaultTipBoostPercent,
+		GasTipCap:   signedTx.GasTipCap(),
+		GasFeeCap:   signedTx.GasFeeCap(),
+		Value:       signedTx.Value(),
+		Nonce:       signedTx.Nonce(),
+		Created:     time.Now().Unix(),
+		Description: "nonce gap filler",
+	})
+	if err != nil {
+		return err
+	}
+	if err := t.store.Put(pendingTransactionKey(txHash), struct{}{}); err != nil {
+		return err
+	}
+
+	t.recordFee(wallet.KindNonceGapFiller, signedTx, "")
+	t.logger.Info("filled nonce gap", "tx", txHash, "nonce", nonce)
+	t.waitForPendingTx(txHash)
+	return nil
+}
+
+// recordFee adds the maximal fee of the transaction the service sent on its
+// own to the audit log.
+func (t *transactionService) recordFee(kind wallet.Kind, tx *types.Transaction, reference string) {
+	if t.opts.AuditLog == nil {
+		return
+	}
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+	fee := new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+	if err := t.opts.AuditLog.Record(kind, wallet.CoinNativeToken, to, fee, tx.Hash(), reference); err != nil {
+		t.logger.Error(err, "unable to record the transaction fee", "kind", kind, "tx", tx.Hash())
+	}
+}
+
+// capFees bounds the fees by the maximal gas fee cap of the options.
+func (t *transactionService) capFees(gasFeeCap, gasTipCap *big.Int) (*big.Int, *big.Int) {
+	if gasFeeCap.Cmp(t.opts.MaxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(t.opts.MaxGasFeeCap)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasFeeCap, gasTipCap
+}
+
+// replacements returns the transaction followed by its replacements.
+func (t *transactionService) replacements(txHash common.Hash) ([]common.Hash, error) {
+	txHashes := []common.Hash{txHash}
+	for {
+		var next common.Hash
+		err := t.store.Get(replacedTransactionKey(txHash), &next)
+		if errors.Is(err, storage.ErrNotFound) {
+			return txHashes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, next)
+		txHash = next
+	}
+}
+
+// waitForReplacements waits until one of the transactions is mined, all of
+// them are cancelled or another replacement is sent, in which case the
+// returned restart flag is set.
+func (t *transactionService) waitForReplacements(ctx context.Context, txHashes []common.Hash) (receipt *types.Receipt, restart bool, err error) {
+	t.replacedMu.Lock()
+	replaced := t.replaced
+	t.replacedMu.Unlock()
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(replaced)},
+	}
+	for _, txHash := range txHashes {
+		receiptC, errC, err := t.WatchSentTransaction(txHash)
+		if err != nil {
+			return nil, false, err
+		}
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(receiptC)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(errC)},
+		)
+	}
+
+	for watching := len(txHashes); ; {
+		chosen, v, _ := reflect.Select(cases)
+		switch {
+		case chosen == 0:
+			return nil, false, ctx.Err()
+		case chosen == 1:
+			return nil, true, nil
+		case chosen%2 == 0:
+			r := v.Interface().(types.Receipt)
+			return &r, false, nil
+		}
+
+		err := v.Interface().(error)
+		if !errors.Is(err, ErrTransactionCancelled) {
+			return nil, false, err
+		}
+		// a receipt of another transaction of the nonce may follow
+		if watching--; watching == 0 {
+			return nil, false, err
+		}
+		cases[chosen-1].Chan = reflect.Value{}
+		cases[chosen].Chan = reflect.Value{}
+	}
+}
+
+func bump(v *big.Int, percent int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(new(big.Int).Mul(big.NewInt(int64(percent)+100), v), big.NewInt(100))
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
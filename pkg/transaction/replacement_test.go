@@ -0,0 +1,392 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transaction_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	storemock "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+	"github.com/ethersphere/bee/v2/pkg/transaction/backendmock"
+	"github.com/ethersphere/bee/v2/pkg/transaction/monitormock"
+	"github.com/ethersphere/bee/v2/pkg/util/testutil"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
+)
+
+func pendingTransactionKey(txHash common.Hash) string {
+	return fmt.Sprintf("transaction_pending_%x", txHash)
+}
+
+func newSigner(t *testing.T) (crypto.Signer, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	sender, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, sender
+}
+
+func TestReplaceStuckTransaction(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	recipient := common.HexToAddress("0xabcd")
+	chainID := big.NewInt(5)
+	txHash := common.HexToHash("0x01")
+	nonce := uint64(3)
+
+	store := storemock.NewStateStore()
+	if err := store.Put(nonceKey(sender), nonce+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(transaction.StoredTransactionKey(txHash), transaction.StoredTransaction{
+		To:        &recipient,
+		Data:      []byte{1, 2, 3},
+		GasLimit:  50000,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+		Value:     big.NewInt(0),
+		Nonce:     nonce,
+		Created:   time.Now().Add(-time.Hour).Unix(),
+		Priority:  transaction.PriorityRedistribution,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(pendingTransactionKey(txHash), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu          sync.Mutex
+		replacement *types.Transaction
+	)
+	o := transaction.DefaultOptions()
+	o.CheckInterval = 0
+	o.Bump = true
+	o.MaxGasFeeCap = big.NewInt(10000)
+	auditLog := wallet.New(log.Noop, storemock.NewStateStore(), wallet.Options{})
+	o.AuditLog = auditLog
+	transactionService, err := transaction.NewService(log.Noop, sender,
+		backendmock.New(
+			backendmock.WithTransactionByHashFunc(func(context.Context, common.Hash) (*types.Transaction, bool, error) {
+				return nil, true, nil
+			}),
+			backendmock.WithNonceAtFunc(func(context.Context, common.Address, *big.Int) (uint64, error) {
+				return nonce, nil
+			}),
+			backendmock.WithPendingNonceAtFunc(func(context.Context, common.Address) (uint64, error) {
+				return nonce + 1, nil
+			}),
+			backendmock.WithSuggestGasPriceFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(500), nil
+			}),
+			backendmock.WithSuggestGasTipCapFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(50), nil
+			}),
+			backendmock.WithSendTransactionFunc(func(_ context.Context, tx *types.Transaction) error {
+				mu.Lock()
+				defer mu.Unlock()
+				replacement = tx
+				return nil
+			}),
+		),
+		signer,
+		store,
+		chainID,
+		monitormock.New(
+			monitormock.WithWatchTransactionFunc(func(h common.Hash, _ uint64) (<-chan types.Receipt, <-chan error, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				receiptC := make(chan types.Receipt, 1)
+				if replacement != nil && h == replacement.Hash() {
+					receiptC <- types.Receipt{TxHash: h, Status: 1}
+				}
+				return receiptC, make(chan error), nil
+			}),
+		),
+		o,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, transactionService)
+
+	if err := transaction.CheckPending(context.Background(), transactionService); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	tx := replacement
+	mu.Unlock()
+	if tx == nil {
+		t.Fatal("transaction not replaced")
+	}
+	if tx.Nonce() != nonce {
+		t.Fatalf("got nonce %d, want %d", tx.Nonce(), nonce)
+	}
+	// the bumped fees are higher than the suggested ones
+	if tx.GasFeeCap().Cmp(big.NewInt(1200)) != 0 {
+		t.Fatalf("got gas fee cap %d, want 1200", tx.GasFeeCap())
+	}
+	if tx.GasTipCap().Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("got gas tip cap %d, want 120", tx.GasTipCap())
+	}
+
+	stored, err := transactionService.StoredTransaction(tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Bumps != 1 || stored.Priority != transaction.PriorityRedistribution {
+		t.Fatalf("got bumps %d and priority %s, want 1 and %s", stored.Bumps, stored.Priority, transaction.PriorityRedistribution)
+	}
+
+	// the maximal fee of the replacement is in the audit log
+	records, err := auditLog.Audit(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d audit records, want 1", len(records))
+	}
+	if r := records[0]; r.Kind != wallet.KindTransactionBump || r.TxHash != tx.Hash() || r.To != recipient || r.Amount.Cmp(big.NewInt(1200*50000)) != 0 || r.Reference != txHash.Hex() {
+		t.Fatalf("got audit record %+v", r)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	receipt, err := transactionService.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TxHash != tx.Hash() {
+		t.Fatalf("got receipt of %s, want %s", receipt.TxHash, tx.Hash())
+	}
+}
+
+func TestReplaceCappedTransaction(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+	recipient := common.HexToAddress("0xabcd")
+	txHash := common.HexToHash("0x01")
+	nonce := uint64(3)
+
+	store := storemock.NewStateStore()
+	if err := store.Put(nonceKey(sender), nonce+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(transaction.StoredTransactionKey(txHash), transaction.StoredTransaction{
+		To:        &recipient,
+		GasLimit:  50000,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+		Value:     big.NewInt(0),
+		Nonce:     nonce,
+		Created:   time.Now().Add(-time.Hour).Unix(),
+		Priority:  transaction.PriorityRedistribution,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(pendingTransactionKey(txHash), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	o := transaction.DefaultOptions()
+	o.CheckInterval = 0
+	o.Bump = true
+	// the cap leaves room only for a 5% increase of the fee cap
+	o.MaxGasFeeCap = big.NewInt(1050)
+	buf := new(bytes.Buffer)
+	logger := log.NewLogger("test", log.WithSink(buf), log.WithVerbosity(log.VerbosityWarning))
+	transactionService, err := transaction.NewService(logger, sender,
+		backendmock.New(
+			backendmock.WithNonceAtFunc(func(context.Context, common.Address, *big.Int) (uint64, error) {
+				return nonce, nil
+			}),
+			backendmock.WithPendingNonceAtFunc(func(context.Context, common.Address) (uint64, error) {
+				return nonce + 1, nil
+			}),
+			backendmock.WithSuggestGasPriceFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(500), nil
+			}),
+			backendmock.WithSuggestGasTipCapFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(50), nil
+			}),
+			backendmock.WithSendTransactionFunc(func(context.Context, *types.Transaction) error {
+				t.Error("replacement sent")
+				return nil
+			}),
+		),
+		signer,
+		store,
+		big.NewInt(5),
+		monitormock.New(),
+		o,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, transactionService)
+
+	for i := 0; i < 3; i++ {
+		if err := transaction.CheckPending(context.Background(), transactionService); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := transactionService.PendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0] != txHash {
+		t.Fatalf("got pending transactions %v, want %v", pending, []common.Hash{txHash})
+	}
+	if n := strings.Count(buf.String(), "skipping bump of stuck transaction"); n != 1 {
+		t.Fatalf("got %d logged skipped bumps, want 1", n)
+	}
+}
+
+func TestReplacementOptions(t *testing.T) {
+	t.Parallel()
+
+	signer, sender := newSigner(t)
+
+	for _, o := range []transaction.Options{
+		{Bump: true},
+		{FillNonceGaps: true},
+		{Bump: true, MaxGasFeeCap: big.NewInt(0)},
+	} {
+		_, err := transaction.NewService(log.Noop, sender, backendmock.New(), signer, storemock.NewStateStore(), big.NewInt(5), monitormock.New(), o)
+		if !errors.Is(err, transaction.ErrMissingMaxGasFeeCap) {
+			t.Fatalf("got error %v, want %v", err, transaction.ErrMissingMaxGasFeeCap)
+		}
+	}
+}
+
+func TestRepairNonceGap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("filler", func(t *testing.T) {
+		t.Parallel()
+
+		testRepairNonceGap(t, transaction.Options{FillNonceGaps: true, MaxGasFeeCap: big.NewInt(400)})
+	})
+	t.Run("disabled filler", func(t *testing.T) {
+		t.Parallel()
+
+		testRepairNonceGap(t, transaction.Options{})
+	})
+}
+
+func testRepairNonceGap(t *testing.T, o transaction.Options) {
+	t.Helper()
+
+	signer, sender := newSigner(t)
+	chainID := big.NewInt(5)
+	nonce := uint64(4)
+
+	store := storemock.NewStateStore()
+	if err := store.Put(nonceKey(sender), nonce+1); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan *types.Transaction, 1)
+	transactionService, err := transaction.NewService(log.Noop, sender,
+		backendmock.New(
+			backendmock.WithNonceAtFunc(func(context.Context, common.Address, *big.Int) (uint64, error) {
+				return nonce, nil
+			}),
+			backendmock.WithPendingNonceAtFunc(func(context.Context, common.Address) (uint64, error) {
+				return nonce, nil
+			}),
+			backendmock.WithSuggestGasPriceFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(500), nil
+			}),
+			backendmock.WithSuggestGasTipCapFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(50), nil
+			}),
+			backendmock.WithSendTransactionFunc(func(_ context.Context, tx *types.Transaction) error {
+				sent <- tx
+				return nil
+			}),
+		),
+		signer,
+		store,
+		chainID,
+		monitormock.New(
+			monitormock.WithWatchTransactionFunc(func(common.Hash, uint64) (<-chan types.Receipt, <-chan error, error) {
+				return make(chan types.Receipt), make(chan error), nil
+			}),
+		),
+		o,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.CleanupCloser(t, transactionService)
+
+	// the gap is repaired only when it persists
+	if err := transaction.CheckPending(context.Background(), transactionService); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case tx := <-sent:
+		t.Fatalf("unexpected transaction with nonce %d", tx.Nonce())
+	default:
+	}
+
+	if err := transaction.CheckPending(context.Background(), transactionService); err != nil {
+		t.Fatal(err)
+	}
+	if !o.FillNonceGaps {
+		select {
+		case tx := <-sent:
+			t.Fatalf("unexpected transaction with nonce %d", tx.Nonce())
+		default:
+		}
+		return
+	}
+	select {
+	case tx := <-sent:
+		// the fees of the filler are capped
+		if tx.GasFeeCap().Cmp(o.MaxGasFeeCap) != 0 {
+			t.Fatalf("got gas fee cap %d, want %d", tx.GasFeeCap(), o.MaxGasFeeCap)
+		}
+		if tx.Nonce() != nonce {
+			t.Fatalf("got nonce %d, want %d", tx.Nonce(), nonce)
+		}
+		if *tx.To() != sender || tx.Value().Sign() != 0 {
+			t.Fatalf("got transfer of %d to %s, want empty transfer to %s", tx.Value(), tx.To(), sender)
+		}
+	default:
+		t.Fatal("nonce gap not filled")
+	}
+
+	pending, err := transactionService.PendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending transactions, want 1", len(pending))
+	}
+}
@@ -58,6 +58,7 @@ type TxRequest struct {
 	GasFeeCap            *big.Int        // adds a cap to maximum fee user is willing to pay
 	Value                *big.Int        // amount of wei to send
 	Description          string          // optional description
+	Priority             Priority        // priority class of the transaction
 }
 
 type StoredTransaction struct {
@@ -72,6 +73,8 @@ type StoredTransaction struct {
 	Nonce       uint64          // used nonce
 	Created     int64           // creation timestamp
 	Description string          // description
+	Priority    Priority        // priority class
+	Bumps       int             // number of replacements with higher fees
 }
 
 // Service is the service to send transactions. It takes care of gas price, gas
@@ -93,6 +96,9 @@ type Service interface {
 	StoredTransaction(txHash common.Hash) (*StoredTransaction, error)
 	// PendingTransactions retrieves the list of all pending transaction hashes
 	PendingTransactions() ([]common.Hash, error)
+	// QueuedTransactions returns the requests waiting to be sent in the order
+	// in which they are going to be sent.
+	QueuedTransactions() []QueuedTransaction
 	// ResendTransaction resends a previously sent transaction
 	// This operation can be useful if for some reason the transaction vanished from the eth networks pending pool
 	ResendTransaction(ctx context.Context, txHash common.Hash) error
@@ -118,10 +124,21 @@ type transactionService struct {
 	store   storage.StateStorer
 	chainID *big.Int
 	monitor Monitor
+	opts    Options
+	queue   txQueue
+	gaps    map[uint64]struct{}      // nonce gaps observed in the last check
+	capped  map[common.Hash]struct{} // transactions whose bumps were skipped in the last check
+
+	replacedMu sync.Mutex
+	replaced   chan struct{} // closed when a transaction is replaced
 }
 
 // NewService creates a new transaction service.
-func NewService(logger log.Logger, overlayEthAddress common.Address, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor) (Service, error) {
+func NewService(logger log.Logger, overlayEthAddress common.Address, backend Backend, signer crypto.Signer, store storage.StateStorer, chainID *big.Int, monitor Monitor, o Options) (Service, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
 	senderAddress, err := signer.EthereumAddress()
 	if err != nil {
 		return nil, err
@@ -130,15 +147,17 @@ func NewService(logger log.Logger, overlayEthAddress common.Address, backend Bac
 	ctx, cancel := context.WithCancel(context.Background())
 
 	t := &transactionService{
-		ctx:     ctx,
-		cancel:  cancel,
-		logger:  logger.WithName(loggerName).WithValues("sender_address", overlayEthAddress).Register(),
-		backend: backend,
-		signer:  signer,
-		sender:  senderAddress,
-		store:   store,
-		chainID: chainID,
-		monitor: monitor,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   logger.WithName(loggerName).WithValues("sender_address", overlayEthAddress).Register(),
+		backend:  backend,
+		signer:   signer,
+		sender:   senderAddress,
+		store:    store,
+		chainID:  chainID,
+		monitor:  monitor,
+		opts:     o,
+		replaced: make(chan struct{}),
 	}
 
 	err = t.waitForAllPendingTx()
@@ -146,6 +165,11 @@ func NewService(logger log.Logger, overlayEthAddress common.Address, backend Bac
 		return nil, err
 	}
 
+	if o.CheckInterval > 0 {
+		t.wg.Add(1)
+		go t.manage()
+	}
+
 	return t, nil
 }
 
@@ -168,6 +192,11 @@ func (t *transactionService) waitForAllPendingTx() error {
 func (t *transactionService) Send(ctx context.Context, request *TxRequest, boostPercent int) (txHash common.Hash, err error) {
 	loggerV1 := t.logger.V(1).Register()
 
+	if err := t.queue.acquire(ctx, request.Priority, request.Description); err != nil {
+		return common.Hash{}, err
+	}
+	defer t.queue.release()
+
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -212,6 +241,7 @@ func (t *transactionService) Send(ctx context.Context, request *TxRequest, boost
 		Nonce:       signedTx.Nonce(),
 		Created:     time.Now().Unix(),
 		Description: request.Description,
+		Priority:    request.Priority,
 	})
 	if err != nil {
 		return common.Hash{}, err
@@ -234,9 +264,16 @@ func (t *transactionService) waitForPendingTx(txHash common.Hash) {
 		switch _, err := t.WaitForReceipt(t.ctx, txHash); {
 		case err == nil:
 			t.logger.Info("pending transaction confirmed", "tx", txHash)
-			err = t.store.Delete(pendingTransactionKey(txHash))
+			txHashes, err := t.replacements(txHash)
 			if err != nil {
-				t.logger.Error(err, "unregistering finished pending transaction failed", "tx", txHash)
+				t.logger.Error(err, "loading transaction replacements failed", "tx", txHash)
+				txHashes = []common.Hash{txHash}
+			}
+			for _, txHash := range txHashes {
+				err = t.store.Delete(pendingTransactionKey(txHash))
+				if err != nil {
+					t.logger.Error(err, "unregistering finished pending transaction failed", "tx", txHash)
+				}
 			}
 		default:
 			if errors.Is(err, ErrTransactionCancelled) {
@@ -394,20 +431,18 @@ func (t *transactionService) putNonce(nonce uint64) error {
 }
 
 // WaitForReceipt waits until either the transaction with the given hash has
-// been mined or the context is cancelled.
+// been mined or the context is cancelled. If the transaction was replaced
+// with higher fees, the receipt of the mined replacement is returned.
 func (t *transactionService) WaitForReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
-	receiptC, errC, err := t.WatchSentTransaction(txHash)
-	if err != nil {
-		return nil, err
-	}
-	select {
-	case receipt := <-receiptC:
-		return &receipt, nil
-	case err := <-errC:
-		return nil, err
-	// don't wait longer than the context that was passed in
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	for {
+		txHashes, err := t.replacements(txHash)
+		if err != nil {
+			return nil, err
+		}
+		receipt, restart, err := t.waitForReplacements(ctx, txHashes)
+		if !restart {
+			return receipt, err
+		}
 	}
 }
 
@@ -438,6 +473,10 @@ func (t *transactionService) PendingTransactions() ([]common.Hash, error) {
 	return txHashes, nil
 }
 
+func (t *transactionService) QueuedTransactions() []QueuedTransaction {
+	return t.queue.view()
+}
+
 // filterPendingTransactions will filter supplied transaction hashes removing those that are not pending anymore.
 // Removed transactions will be also removed from store.
 func (t *transactionService) filterPendingTransactions(ctx context.Context, txHashes []common.Hash) []common.Hash {
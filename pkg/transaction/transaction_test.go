@@ -152,6 +152,7 @@ func TestTransactionSend(t *testing.T) {
 					return nil, nil, nil
 				}),
 			),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -272,6 +273,7 @@ func TestTransactionSend(t *testing.T) {
 					return nil, nil, nil
 				}),
 			),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -403,6 +405,7 @@ func TestTransactionSend(t *testing.T) {
 					return nil, nil, nil
 				}),
 			),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -520,6 +523,7 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -601,6 +605,7 @@ func TestTransactionSend(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -671,6 +676,7 @@ func TestTransactionWaitForReceipt(t *testing.T) {
 				return receiptC, nil, nil
 			}),
 		),
+		transaction.Options{},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -747,6 +753,7 @@ func TestTransactionResend(t *testing.T) {
 		store,
 		chainID,
 		monitormock.New(),
+		transaction.Options{},
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -837,6 +844,7 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -888,6 +896,7 @@ func TestTransactionCancel(t *testing.T) {
 			store,
 			chainID,
 			monitormock.New(),
+			transaction.Options{},
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -973,6 +982,7 @@ func TestTransactionService_UnwrapABIError(t *testing.T) {
 		storemock.NewStateStore(),
 		chainID,
 		monitormock.New(),
+		transaction.Options{},
 	)
 	if err != nil {
 		t.Fatal(err)
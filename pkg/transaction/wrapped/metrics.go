@@ -133,5 +133,9 @@ func newMetrics() metrics {
 }
 
 func (b *wrappedBackend) Metrics() []prometheus.Collector {
-	return m.PrometheusCollectorsFromFields(b.metrics)
+	cs := m.PrometheusCollectorsFromFields(b.metrics)
+	if c, ok := b.backend.(m.Collector); ok {
+		cs = append(cs, c.Metrics()...)
+	}
+	return cs
 }
@@ -0,0 +1,445 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wallet enforces the policy of the withdrawals from the node and
+// keeps the audit log of its outgoing value transfers.
+//
+// The withdrawals go only to the whitelisted addresses, which can be named,
+// and are capped per coin over the last 24 hours. When an approval token is
+// configured, a withdrawal is only requested first and is sent once it is
+// approved with the token.
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	"github.com/ethersphere/bee/v2/pkg/storage"
+)
+
+// loggerName is the tree path name of the logger for this package.
+const loggerName = "wallet"
+
+const (
+	withdrawalKeyPrefix = "wallet_withdrawal_"
+	auditKeyPrefix      = "wallet_audit_"
+)
+
+// capWindow is the period the daily caps apply to.
+const capWindow = 24 * time.Hour
+
+// sendTimeout is the time after which an approved withdrawal that was not
+// completed, because the node stopped while sending it, expires.
+const sendTimeout = 15 * time.Minute
+
+// The coins of the transfers.
+const (
+	CoinBZZ         = "BZZ"
+	CoinNativeToken = "NativeToken"
+)
+
+// Kind is the kind of an outgoing value transfer.
+type Kind string
+
+const (
+	KindWalletWithdraw     Kind = "wallet-withdraw"
+	KindChequebookWithdraw Kind = "chequebook-withdraw"
+	KindChequebookDeposit  Kind = "chequebook-deposit"
+	KindStakeDeposit       Kind = "stake-deposit"
+	KindPostagePurchase    Kind = "postage-purchase"
+	KindPostageTopUp       Kind = "postage-topup"
+	KindSwapCheque         Kind = "swap-cheque"
+	KindTransactionBump    Kind = "transaction-bump"
+	KindNonceGapFiller     Kind = "nonce-gap-filler"
+)
+
+// Withdrawal status values.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+	StatusSent     = "sent"
+	StatusFailed   = "failed"
+)
+
+var (
+	// ErrNotWhitelisted is returned when the target of a withdrawal is not
+	// a whitelisted address.
+	ErrNotWhitelisted = errors.New("wallet: address not whitelisted")
+	// ErrDailyCapExceeded is returned when a withdrawal would exceed the
+	// daily cap of its coin.
+	ErrDailyCapExceeded = errors.New("wallet: daily spend cap exceeded")
+	// ErrInvalidApprovalToken is returned when a withdrawal is approved
+	// with a wrong token.
+	ErrInvalidApprovalToken = errors.New("wallet: invalid approval token")
+	// ErrNotFound is returned when there is no withdrawal with the id.
+	ErrNotFound = errors.New("wallet: withdrawal not found")
+	// ErrNotPending is returned when a withdrawal is not waiting for an
+	// approval.
+	ErrNotPending = errors.New("wallet: withdrawal not pending")
+)
+
+// Recorder adds the outgoing value transfers to the audit log.
+type Recorder interface {
+	// Record adds a transfer that is not a withdrawal to the audit log.
+	Record(kind Kind, coin string, to common.Address, amount *big.Int, txHash common.Hash, reference string) error
+}
+
+// Address is a whitelisted withdrawal address.
+type Address struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+}
+
+// ParseAddress parses a whitelisted address given either as a hex address
+// or as name=address. An unnamed address is named by its hex form.
+func ParseAddress(s string) (Address, error) {
+	name, addr, named := strings.Cut(s, "=")
+	if !named {
+		addr = s
+	}
+	name, addr = strings.TrimSpace(name), strings.TrimSpace(addr)
+	if !common.IsHexAddress(addr) {
+		return Address{}, fmt.Errorf("invalid address %q", addr)
+	}
+	a := common.HexToAddress(addr)
+	if !named {
+		name = a.Hex()
+	}
+	if name == "" {
+		return Address{}, fmt.Errorf("empty name of address %q", addr)
+	}
+	return Address{Name: name, Address: a}, nil
+}
+
+// Options are the withdrawal policy.
+type Options struct {
+	Addresses       []Address
+	DailyCaps       map[string]*big.Int // caps of the coins over the last 24 hours, no cap when missing
+	ApprovalToken   string              // enables the two-step approval when set
+	ApprovalTimeout time.Duration       // time after which the requested withdrawals expire
+}
+
+// Withdrawal is a withdrawal that was requested from the node.
+type Withdrawal struct {
+	ID       string      `json:"id"`
+	Kind     Kind        `json:"kind"`
+	Coin     string      `json:"coin"`
+	To       Address     `json:"to"`
+	Amount   *big.Int    `json:"amount"`
+	Status   string      `json:"status"`
+	TxHash   common.Hash `json:"txHash"`
+	Error    string      `json:"error,omitempty"`
+	Created  time.Time   `json:"created"`
+	Updated  time.Time   `json:"updated"`
+	Approved bool        `json:"approved"` // whether the withdrawal went through the approval
+}
+
+// Record is an entry of the audit log of the outgoing value transfers.
+type Record struct {
+	Time       time.Time      `json:"time"`
+	Kind       Kind           `json:"kind"`
+	Coin       string         `json:"coin"`
+	Amount     *big.Int       `json:"amount"`
+	To         common.Address `json:"to"`
+	TxHash     common.Hash    `json:"txHash"`
+	Reference  string         `json:"reference,omitempty"`  // batch id of the postage purchases, peer of the cheques or replaced transaction of the bumps
+	Withdrawal string         `json:"withdrawal,omitempty"` // id of the withdrawal
+	Approved   bool           `json:"approved"`
+}
+
+// Service enforces the withdrawal policy and keeps the audit log.
+type Service struct {
+	logger log.Logger
+	store  storage.StateStorer
+	opts   Options
+	now    func() time.Time
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// New creates the service with the given policy.
+func New(logger log.Logger, store storage.StateStorer, o Options) *Service {
+	return &Service{
+		logger: logger.WithName(loggerName).Register(),
+		store:  store,
+		opts:   o,
+		now:    time.Now,
+	}
+}
+
+// Addresses returns the whitelisted addresses.
+func (s *Service) Addresses() []Address {
+	return append([]Address(nil), s.opts.Addresses...)
+}
+
+// RequiresApproval reports whether the withdrawals wait for an approval.
+func (s *Service) RequiresApproval() bool {
+	return s.opts.ApprovalToken != ""
+}
+
+// Resolve returns the whitelisted address with the name or the hex address.
+func (s *Service) Resolve(nameOrAddress string) (Address, error) {
+	for _, a := range s.opts.Addresses {
+		if a.Name == nameOrAddress {
+			return a, nil
+		}
+	}
+	if common.IsHexAddress(nameOrAddress) {
+		addr := common.HexToAddress(nameOrAddress)
+		for _, a := range s.opts.Addresses {
+			if a.Address == addr {
+				return a, nil
+			}
+		}
+	}
+	return Address{}, ErrNotWhitelisted
+}
+
+// Request requests a withdrawal of the amount of the coin. The withdrawal is
+// pending if it requires an approval and approved otherwise, in which case
+// it has to be completed with Complete once it is sent.
+func (s *Service) Request(kind Kind, coin string, to Address, amount *big.Int) (Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if limit, ok := s.opts.DailyCaps[coin]; ok {
+		spent, err := s.spent(coin, now)
+		if err != nil {
+			return Withdrawal{}, err
+		}
+		if new(big.Int).Add(spent, amount).Cmp(limit) > 0 {
+			return Withdrawal{}, ErrDailyCapExceeded
+		}
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Withdrawal{}, fmt.Errorf("withdrawal id: %w", err)
+	}
+	w := Withdrawal{
+		ID:      hex.EncodeToString(id),
+		Kind:    kind,
+		Coin:    coin,
+		To:      to,
+		Amount:  new(big.Int).Set(amount),
+		Status:  StatusApproved,
+		Created: now,
+		Updated: now,
+	}
+	if s.RequiresApproval() {
+		w.Status = StatusPending
+	}
+	if err := s.store.Put(withdrawalKey(w.ID), w); err != nil {
+		return Withdrawal{}, fmt.Errorf("put withdrawal: %w", err)
+	}
+
+	s.logger.Info("withdrawal requested", "id", w.ID, "kind", kind, "coin", coin, "to", to.Name, "amount", amount, "status", w.Status)
+	return w, nil
+}
+
+// Approve approves the pending withdrawal with the approval token. The
+// approved withdrawal has to be completed with Complete once it is sent.
+func (s *Service) Approve(id, token string) (Withdrawal, error) {
+	if !s.RequiresApproval() || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.ApprovalToken)) != 1 {
+		return Withdrawal{}, ErrInvalidApprovalToken
+	}
+	return s.transition(id, StatusPending, true, func(w *Withdrawal) {
+		w.Status = StatusApproved
+		w.Approved = true
+	})
+}
+
+// Reject rejects the pending withdrawal.
+func (s *Service) Reject(id string) (Withdrawal, error) {
+	return s.transition(id, StatusPending, true, func(w *Withdrawal) {
+		w.Status = StatusRejected
+	})
+}
+
+// Complete records the outcome of sending the approved withdrawal. The sent
+// withdrawals are recorded in the audit log, also when they were completed
+// after the send timeout.
+func (s *Service) Complete(id string, txHash common.Hash, sendErr error) (Withdrawal, error) {
+	w, err := s.transition(id, StatusApproved, false, func(w *Withdrawal) {
+		if sendErr != nil {
+			w.Status = StatusFailed
+			w.Error = sendErr.Error()
+			return
+		}
+		w.Status = StatusSent
+		w.TxHash = txHash
+	})
+	if err != nil || w.Status != StatusSent {
+		return w, err
+	}
+
+	return w, s.record(Record{
+		Time:       w.Updated,
+		Kind:       w.Kind,
+		Coin:       w.Coin,
+		Amount:     w.Amount,
+		To:         w.To.Address,
+		TxHash:     w.TxHash,
+		Withdrawal: w.ID,
+		Approved:   w.Approved,
+	})
+}
+
+// Withdrawals returns the requested withdrawals, the most recent first.
+func (s *Service) Withdrawals() ([]Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var ws []Withdrawal
+	err := s.store.Iterate(withdrawalKeyPrefix, func(_, value []byte) (bool, error) {
+		var w Withdrawal
+		if err := json.Unmarshal(value, &w); err != nil {
+			return true, fmt.Errorf("unmarshal withdrawal: %w", err)
+		}
+		s.expire(&w, now)
+		ws = append(ws, w)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Created.After(ws[j].Created) })
+	return ws, nil
+}
+
+// Record adds a transfer that is not a withdrawal to the audit log.
+func (s *Service) Record(kind Kind, coin string, to common.Address, amount *big.Int, txHash common.Hash, reference string) error {
+	return s.record(Record{
+		Time:      s.now(),
+		Kind:      kind,
+		Coin:      coin,
+		Amount:    new(big.Int).Set(amount),
+		To:        to,
+		TxHash:    txHash,
+		Reference: reference,
+	})
+}
+
+// Audit returns the audit log records in the time range, where the zero
+// times do not limit the range.
+func (s *Service) Audit(from, to time.Time) ([]Record, error) {
+	var rs []Record
+	err := s.store.Iterate(auditKeyPrefix, func(_, value []byte) (bool, error) {
+		var r Record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return true, fmt.Errorf("unmarshal audit record: %w", err)
+		}
+		if !from.IsZero() && r.Time.Before(from) || !to.IsZero() && !r.Time.Before(to) {
+			return false, nil
+		}
+		rs = append(rs, r)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].Time.Before(rs[j].Time) })
+	return rs, nil
+}
+
+func (s *Service) record(r Record) error {
+	s.mu.Lock()
+	s.seq++
+	key := fmt.Sprintf("%s%020d_%06d", auditKeyPrefix, r.Time.UnixNano(), s.seq%1_000_000)
+	s.mu.Unlock()
+
+	if err := s.store.Put(key, r); err != nil {
+		s.logger.Error(err, "audit record failed", "kind", r.Kind, "tx", r.TxHash)
+		return fmt.Errorf("put audit record: %w", err)
+	}
+	return nil
+}
+
+// transition changes the withdrawal in the given status, which is checked
+// after the expiry of the withdrawal if expire is set.
+func (s *Service) transition(id, status string, expire bool, change func(*Withdrawal)) (Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var w Withdrawal
+	switch err := s.store.Get(withdrawalKey(id), &w); {
+	case errors.Is(err, storage.ErrNotFound):
+		return Withdrawal{}, ErrNotFound
+	case err != nil:
+		return Withdrawal{}, fmt.Errorf("get withdrawal: %w", err)
+	}
+
+	now := s.now()
+	if expire {
+		s.expire(&w, now)
+	}
+	if w.Status != status {
+		return w, ErrNotPending
+	}
+	change(&w)
+	w.Updated = now
+	if err := s.store.Put(withdrawalKey(id), w); err != nil {
+		return Withdrawal{}, fmt.Errorf("put withdrawal: %w", err)
+	}
+
+	s.logger.Info("withdrawal updated", "id", w.ID, "status", w.Status)
+	return w, nil
+}
+
+// spent returns the amount of the coin that was withdrawn or is reserved by
+// the withdrawals within the cap window.
+func (s *Service) spent(coin string, now time.Time) (*big.Int, error) {
+	spent := new(big.Int)
+	err := s.store.Iterate(withdrawalKeyPrefix, func(_, value []byte) (bool, error) {
+		var w Withdrawal
+		if err := json.Unmarshal(value, &w); err != nil {
+			return true, fmt.Errorf("unmarshal withdrawal: %w", err)
+		}
+		s.expire(&w, now)
+		if w.Coin != coin || now.Sub(w.Created) >= capWindow {
+			return false, nil
+		}
+		switch w.Status {
+		case StatusPending, StatusApproved, StatusSent:
+			spent.Add(spent, w.Amount)
+		}
+		return false, nil
+	})
+	return spent, err
+}
+
+// expire marks the pending withdrawal as expired when it waited for an
+// approval longer than the approval timeout, and the approved withdrawal when
+// it was not completed within the send timeout, so that it stops counting
+// against the daily cap.
+func (s *Service) expire(w *Withdrawal, now time.Time) {
+	switch {
+	case w.Status == StatusPending && s.opts.ApprovalTimeout > 0 && now.Sub(w.Created) >= s.opts.ApprovalTimeout:
+		w.Status = StatusExpired
+	case w.Status == StatusApproved && now.Sub(w.Updated) >= sendTimeout:
+		w.Status = StatusExpired
+	}
+}
+
+func withdrawalKey(id string) string {
+	return withdrawalKeyPrefix + id
+}
@@ -0,0 +1,306 @@
+// Copyright 2024 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wallet_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/wallet"
+)
+
+var (
+	treasury = wallet.Address{Name: "treasury", Address: common.HexToAddress("0xaa")}
+	exchange = wallet.Address{Name: "exchange", Address: common.HexToAddress("0xbb")}
+	start    = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	txHash   = common.HexToHash("0x01")
+)
+
+func newTestService(t *testing.T, o wallet.Options, now *time.Time) *wallet.Service {
+	t.Helper()
+
+	o.Addresses = []wallet.Address{treasury, exchange}
+	s := wallet.New(log.Noop, statestore.NewStateStore(), o)
+	s.SetTimeNow(func() time.Time { return *now })
+	return s
+}
+
+func TestParseAddress(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in   string
+		want wallet.Address
+		err  bool
+	}{
+		{in: "treasury=0x00000000000000000000000000000000000000aa", want: treasury},
+		{in: "0x00000000000000000000000000000000000000aa", want: wallet.Address{Name: treasury.Address.Hex(), Address: treasury.Address}},
+		{in: "treasury=0xaa", err: true},
+		{in: "=0x00000000000000000000000000000000000000aa", err: true},
+	} {
+		got, err := wallet.ParseAddress(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	s := newTestService(t, wallet.Options{}, &now)
+
+	for _, in := range []string{"treasury", "0x00000000000000000000000000000000000000aa"} {
+		got, err := s.Resolve(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != treasury {
+			t.Fatalf("%s: got %+v, want %+v", in, got, treasury)
+		}
+	}
+	if _, err := s.Resolve("0x00000000000000000000000000000000000000cc"); !errors.Is(err, wallet.ErrNotWhitelisted) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrNotWhitelisted)
+	}
+}
+
+func TestDailyCap(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	s := newTestService(t, wallet.Options{
+		DailyCaps: map[string]*big.Int{wallet.CoinBZZ: big.NewInt(100)},
+	}, &now)
+
+	w, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != wallet.StatusApproved {
+		t.Fatalf("got status %s, want %s", w.Status, wallet.StatusApproved)
+	}
+	if _, err := s.Complete(w.ID, txHash, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the chequebook withdrawals count against the cap of the coin
+	now = start.Add(time.Hour)
+	if _, err := s.Request(wallet.KindChequebookWithdraw, wallet.CoinBZZ, exchange, big.NewInt(50)); !errors.Is(err, wallet.ErrDailyCapExceeded) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrDailyCapExceeded)
+	}
+
+	// the other coins are not capped
+	if _, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinNativeToken, exchange, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed withdrawal frees its amount
+	w, err = s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, err = s.Complete(w.ID, common.Hash{}, errors.New("send failed")); err != nil || w.Status != wallet.StatusFailed {
+		t.Fatalf("got withdrawal %+v, error %v", w, err)
+	}
+	if _, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(40)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cap applies to the last 24 hours
+	now = start.Add(24 * time.Hour)
+	if _, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(60)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUncompletedWithdrawal(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	s := newTestService(t, wallet.Options{
+		DailyCaps: map[string]*big.Int{wallet.CoinBZZ: big.NewInt(100)},
+	}, &now)
+
+	// the node stopped while sending the withdrawal
+	stuck, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60)); !errors.Is(err, wallet.ErrDailyCapExceeded) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrDailyCapExceeded)
+	}
+
+	// the withdrawal expires and stops counting against the cap
+	now = start.Add(time.Hour)
+	w, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Complete(w.ID, txHash, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := s.Withdrawals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 2 || ws[1].ID != stuck.ID || ws[1].Status != wallet.StatusExpired {
+		t.Fatalf("got withdrawals %+v, want the uncompleted one expired", ws)
+	}
+
+	// a late completion is still recorded
+	w, err = s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if w, err = s.Complete(w.ID, txHash, nil); err != nil || w.Status != wallet.StatusSent {
+		t.Fatalf("got withdrawal %+v, error %v", w, err)
+	}
+}
+
+func TestApproval(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	s := newTestService(t, wallet.Options{
+		DailyCaps:       map[string]*big.Int{wallet.CoinBZZ: big.NewInt(100)},
+		ApprovalToken:   "secret",
+		ApprovalTimeout: time.Hour,
+	}, &now)
+
+	w, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != wallet.StatusPending {
+		t.Fatalf("got status %s, want %s", w.Status, wallet.StatusPending)
+	}
+
+	// the pending withdrawals reserve their amount
+	if _, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, treasury, big.NewInt(60)); !errors.Is(err, wallet.ErrDailyCapExceeded) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrDailyCapExceeded)
+	}
+
+	if _, err := s.Complete(w.ID, txHash, nil); !errors.Is(err, wallet.ErrNotPending) {
+		t.Fatalf("got error %v completing a pending withdrawal, want %v", err, wallet.ErrNotPending)
+	}
+	if _, err := s.Approve(w.ID, "wrong"); !errors.Is(err, wallet.ErrInvalidApprovalToken) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrInvalidApprovalToken)
+	}
+	if _, err := s.Approve("unknown", "secret"); !errors.Is(err, wallet.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, wallet.ErrNotFound)
+	}
+
+	w, err = s.Approve(w.ID, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != wallet.StatusApproved || !w.Approved {
+		t.Fatalf("got withdrawal %+v", w)
+	}
+	if _, err := s.Approve(w.ID, "secret"); !errors.Is(err, wallet.ErrNotPending) {
+		t.Fatalf("got error %v approving twice, want %v", err, wallet.ErrNotPending)
+	}
+	if _, err := s.Complete(w.ID, txHash, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the withdrawals expire without an approval
+	now = start.Add(time.Minute)
+	w, err = s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = start.Add(2 * time.Hour)
+	if _, err := s.Approve(w.ID, "secret"); !errors.Is(err, wallet.ErrNotPending) {
+		t.Fatalf("got error %v approving an expired withdrawal, want %v", err, wallet.ErrNotPending)
+	}
+
+	// the rejected withdrawals are not sent
+	w, err = s.Request(wallet.KindWalletWithdraw, wallet.CoinBZZ, exchange, big.NewInt(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reject(w.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Approve(w.ID, "secret"); !errors.Is(err, wallet.ErrNotPending) {
+		t.Fatalf("got error %v approving a rejected withdrawal, want %v", err, wallet.ErrNotPending)
+	}
+
+	ws, err := s.Withdrawals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, w := range ws {
+		statuses = append(statuses, w.Status)
+	}
+	want := []string{wallet.StatusRejected, wallet.StatusExpired, wallet.StatusSent}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] || statuses[2] != want[2] {
+		t.Fatalf("got statuses %v, want %v", statuses, want)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	now := start
+	s := newTestService(t, wallet.Options{}, &now)
+
+	w, err := s.Request(wallet.KindWalletWithdraw, wallet.CoinNativeToken, treasury, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Complete(w.ID, txHash, nil); err != nil {
+		t.Fatal(err)
+	}
+	now = start.Add(time.Hour)
+	if err := s.Record(wallet.KindPostagePurchase, wallet.CoinBZZ, common.Address{}, big.NewInt(1024), txHash, "batch"); err != nil {
+		t.Fatal(err)
+	}
+	now = start.Add(2 * time.Hour)
+	if err := s.Record(wallet.KindStakeDeposit, wallet.CoinBZZ, common.Address{}, big.NewInt(10), txHash, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := s.Audit(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 3 {
+		t.Fatalf("got %d records, want %d", len(rs), 3)
+	}
+	if r := rs[0]; r.Kind != wallet.KindWalletWithdraw || r.Withdrawal != w.ID || r.To != treasury.Address || r.Amount.Int64() != 5 {
+		t.Fatalf("got record %+v", r)
+	}
+	if r := rs[1]; r.Kind != wallet.KindPostagePurchase || r.Reference != "batch" {
+		t.Fatalf("got record %+v", r)
+	}
+
+	rs, err = s.Audit(start.Add(time.Hour), start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].Kind != wallet.KindPostagePurchase {
+		t.Fatalf("got records %+v in the range", rs)
+	}
+}